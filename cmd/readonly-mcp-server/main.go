@@ -0,0 +1,9 @@
+// Command readonly-mcp-server exposes a SQL database read-only to MCP
+// clients over stdio. See the mcpsqldb package for configuration.
+package main
+
+import "github.com/shakram02/go-readonly-mcp-sql/pkg/mcpsqldb"
+
+func main() {
+	mcpsqldb.Main()
+}
@@ -0,0 +1,95 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ActiveSchemas are the schemas set_active_schema may switch a session to;
+// empty leaves the tool out (overridable via MCP_ACTIVE_SCHEMAS env var,
+// comma-separated)
+var ActiveSchemas []string
+
+// ErrSearchPathUnsupported is returned by DBAdapter.SearchPathStatement for
+// databases whose name resolution cannot be changed per query.
+var ErrSearchPathUnsupported = errors.New("switching the active schema is not supported")
+
+// activeSchema is the schema a session's queries resolve unqualified names
+// to, with the statement selecting it.
+type activeSchema struct {
+	name      string
+	statement string
+}
+
+// setActiveSchemaTool describes set_active_schema, listed only when
+// ActiveSchemas is configured.
+func setActiveSchemaTool() Tool {
+	return Tool{
+		Name: "set_active_schema",
+		Description: "Choose the schema that unqualified table names resolve to in this session's later queries, " +
+			"instead of running SET search_path (allowed: " + strings.Join(ActiveSchemas, ", ") + ")",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"schema": {
+					Type:        "string",
+					Description: "The schema to switch to",
+				},
+			},
+			Required: []string{"schema"},
+		},
+	}
+}
+
+// setActiveSchema switches the session to an allowed schema. Every later
+// query runs the adapter's search path statement in its transaction first,
+// so no setting outlives a query on a pooled connection.
+func (s *Server) setActiveSchema(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	schema, ok := args["schema"].(string)
+	if !ok || schema == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'schema' parameter",
+		}
+	}
+	if !slices.Contains(ActiveSchemas, schema) || isSchemaDenied(DeniedSchemas, schema) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Schema not allowed: %s (allowed: %s)", schema, strings.Join(ActiveSchemas, ", ")),
+		}
+	}
+
+	statement, err := s.adapter.SearchPathStatement(schema)
+	if errors.Is(err, ErrSearchPathUnsupported) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("set_active_schema is not supported for %s", s.adapter.DriverName())}},
+			IsError: true,
+		}, nil
+	}
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+	s.activeSchema.Store(&activeSchema{name: schema, statement: statement})
+	loggerFrom(ctx).Info("Switched active schema", "schema", schema)
+
+	resultJSON, _ := json.Marshal(map[string]string{"active_schema": schema})
+	return &CallToolResult{Content: []Content{{Type: "text", Text: string(resultJSON)}}}, nil
+}
+
+// applyActiveSchema selects the session's active schema in tx, if one is
+// set.
+func (s *Server) applyActiveSchema(ctx context.Context, tx *sql.Tx) error {
+	active := s.activeSchema.Load()
+	if active == nil {
+		return nil
+	}
+	if _, err := tx.ExecContext(ctx, active.statement); err != nil {
+		return fmt.Errorf("failed to switch to schema %s: %w", active.name, err)
+	}
+	return nil
+}
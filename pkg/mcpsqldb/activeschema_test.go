@@ -0,0 +1,102 @@
+package mcpsqldb
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// markerSchemaAdapter selects a schema by reading a table named after it,
+// so a query fails unless the schema is main.
+type markerSchemaAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *markerSchemaAdapter) SearchPathStatement(schema string) (string, error) {
+	if schema == "main" {
+		return "SELECT 1", nil
+	}
+	return "SELECT * FROM " + a.QuoteIdentifier("schema_"+schema), nil
+}
+
+func TestPostgresSearchPathStatement(t *testing.T) {
+	statement, err := (&CockroachAdapter{}).SearchPathStatement(`hr"; RESET ALL; --`)
+	if err != nil || statement != `SET LOCAL search_path TO "hr""; RESET ALL; --"` {
+		t.Errorf("Expected a quoted transaction-local search_path, got %q (err %v)", statement, err)
+	}
+	if _, err := (&MySQLAdapter{}).SearchPathStatement("hr"); err != ErrSearchPathUnsupported {
+		t.Errorf("Expected MySQL to be unsupported, got %v", err)
+	}
+}
+
+func TestSetActiveSchema(t *testing.T) {
+	defer func(active, denied []string) { ActiveSchemas, DeniedSchemas = active, denied }(ActiveSchemas, DeniedSchemas)
+	ActiveSchemas = []string{"main", "analytics", "pg_catalog"}
+	DeniedSchemas = []string{"pg_catalog"}
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, rpcErr := server.callTool(ctx, "set_active_schema", map[string]any{"schema": "analytics"})
+	if rpcErr != nil || !result.IsError || !strings.Contains(result.Content[0].Text, "not supported for sqlite") {
+		t.Errorf("Expected SQLite to report the tool as unsupported, got %v %v", rpcErr, result)
+	}
+
+	server.adapter = &markerSchemaAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	for _, schema := range []string{"staging", "pg_catalog"} {
+		if _, rpcErr := server.callTool(ctx, "set_active_schema", map[string]any{"schema": schema}); rpcErr == nil || rpcErr.Code != InvalidParams {
+			t.Errorf("Expected %s to be refused, got %v", schema, rpcErr)
+		}
+	}
+
+	if _, rpcErr := server.callTool(ctx, "set_active_schema", map[string]any{"schema": "analytics"}); rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr.Message)
+	}
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM users"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "failed to switch to schema analytics") {
+		t.Errorf("Expected the query to run after switching to analytics, got %s", result.Content[0].Text)
+	}
+
+	status, _ := server.serverStatus(ctx)
+	if !strings.Contains(status.Content[0].Text, `"active_schema": "analytics"`) {
+		t.Errorf("Expected server_status to report the active schema, got %s", status.Content[0].Text)
+	}
+
+	server.callTool(ctx, "set_active_schema", map[string]any{"schema": "main"})
+	if result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM users"}); result.IsError {
+		t.Errorf("Expected the query to run in main, got %s", result.Content[0].Text)
+	}
+}
+
+func TestSetActiveSchema_Snapshot(t *testing.T) {
+	defer func(orig []string) { ActiveSchemas = orig }(ActiveSchemas)
+	ActiveSchemas = []string{"main", "analytics"}
+	server := newTestServer(t)
+	server.adapter = &markerSchemaAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	server.snapshot = newSnapshotTx()
+	ctx := context.Background()
+
+	server.callTool(ctx, "set_active_schema", map[string]any{"schema": "analytics"})
+	if result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM users"}); !result.IsError {
+		t.Errorf("Expected the snapshot query to switch schema first, got %s", result.Content[0].Text)
+	}
+
+	// The failed switch is rolled back and leaves the snapshot usable
+	server.callTool(ctx, "set_active_schema", map[string]any{"schema": "main"})
+	if result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM users"}); result.IsError {
+		t.Errorf("Expected the snapshot to survive a failed switch, got %s", result.Content[0].Text)
+	}
+}
+
+func TestSetActiveSchema_Listed(t *testing.T) {
+	defer func(orig []string) { ActiveSchemas = orig }(ActiveSchemas)
+	server := newTestServer(t)
+
+	ActiveSchemas = nil
+	if _, ok := server.toolSchema("set_active_schema"); ok {
+		t.Error("Expected set_active_schema to be left out without MCP_ACTIVE_SCHEMAS")
+	}
+	ActiveSchemas = []string{"analytics"}
+	if _, ok := server.toolSchema("set_active_schema"); !ok {
+		t.Error("Expected set_active_schema to be listed with MCP_ACTIVE_SCHEMAS")
+	}
+}
@@ -0,0 +1,111 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// RunningQueries offers the running_queries tool, which lists the
+// statements running on the database server (overridable via
+// MCP_RUNNING_QUERIES env var)
+var RunningQueries = false
+
+// Redaction modes for other accounts' SQL in running_queries
+const (
+	RedactNone     = "none"
+	RedactLiterals = "literals"
+	RedactHide     = "hide"
+)
+
+// RunningQueriesRedaction is how running_queries shows the SQL of accounts
+// other than the server's: in full, with literals replaced by ?, or not at
+// all (overridable via MCP_RUNNING_QUERIES_REDACT env var)
+var RunningQueriesRedaction = RedactLiterals
+
+// ErrActivityUnsupported is returned by DBAdapter.ActiveQueries for
+// databases without a server to report on.
+var ErrActivityUnsupported = errors.New("listing running queries is not supported")
+
+// restrictedQueryText replaces the SQL of statements naming a denied table
+// or schema.
+const restrictedQueryText = "<restricted>"
+
+// numericLiteralPattern matches numeric literals not part of an identifier.
+var numericLiteralPattern = regexp.MustCompile(`\b[0-9]+(?:\.[0-9]+)?(?:[eE][-+]?[0-9]+)?\b`)
+
+// redactLiterals returns sqlQuery without comments and with its string and
+// numeric literals replaced by ?, as in a statement digest.
+func redactLiterals(adapter DBAdapter, sqlQuery string) string {
+	cleaned := adapter.RemoveStringsAndComments(sqlQuery)
+	cleaned = strings.ReplaceAll(cleaned, "''", "?")
+	return numericLiteralPattern.ReplaceAllString(cleaned, "?")
+}
+
+// runningQueriesTool describes running_queries, listed only when enabled.
+func runningQueriesTool() Tool {
+	return Tool{
+		Name: "running_queries",
+		Description: "List the statements currently running on the database server (MySQL process list, PostgreSQL pg_stat_activity) " +
+			"with their user, state and running time, longest first, to diagnose load",
+		InputSchema: InputSchema{
+			Type:       "object",
+			Properties: map[string]Property{},
+			Required:   []string{},
+		},
+	}
+}
+
+// runningQueries reports the statements running on the server. Other
+// accounts' SQL is redacted as RunningQueriesRedaction says, and any SQL
+// naming a denied table or schema is withheld.
+func (s *Server) runningQueries(ctx context.Context) (*CallToolResult, *Error) {
+	if !RunningQueries {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "running_queries requires MCP_RUNNING_QUERIES=true"}},
+			IsError: true,
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	queries, err := s.adapter.ActiveQueries(ctx, s.db)
+	if errors.Is(err, ErrActivityUnsupported) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("running_queries is not supported for %s", s.adapter.DriverName())}},
+			IsError: true,
+		}, nil
+	}
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	for i := range queries {
+		q := &queries[i]
+		switch {
+		case q.Query == "":
+		case s.statementDenied(q.Query):
+			q.Query = restrictedQueryText
+		case q.Own || RunningQueriesRedaction == RedactNone:
+		case RunningQueriesRedaction == RedactHide:
+			q.Query = ""
+		default:
+			q.Query = redactLiterals(s.adapter, q.Query)
+		}
+	}
+	if queries == nil {
+		queries = []ActiveQuery{}
+	}
+	return statementReportResult(queries)
+}
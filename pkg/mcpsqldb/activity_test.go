@@ -0,0 +1,87 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+// fixedActivityAdapter reports fixed running queries.
+type fixedActivityAdapter struct {
+	*SQLiteAdapter
+	queries []ActiveQuery
+}
+
+func (a *fixedActivityAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	return append([]ActiveQuery(nil), a.queries...), nil
+}
+
+func TestRedactLiterals(t *testing.T) {
+	got := redactLiterals(&PostgresAdapter{}, "SELECT * FROM t2 WHERE email = 'a@b.c' AND id IN (1, 2.5) -- note")
+	if want := "SELECT * FROM t2 WHERE email = ? AND id IN (?, ?)  "; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestRunningQueries_Redaction(t *testing.T) {
+	defer func(enabled bool, mode string, tables []string) {
+		RunningQueries, RunningQueriesRedaction, DeniedTables = enabled, mode, tables
+	}(RunningQueries, RunningQueriesRedaction, DeniedTables)
+	RunningQueries = true
+	DeniedTables = []string{"payroll"}
+
+	server := newTestServer(t)
+	server.adapter = &fixedActivityAdapter{SQLiteAdapter: &SQLiteAdapter{}, queries: []ActiveQuery{
+		{ID: "1", User: "readonly", Query: "SELECT * FROM users WHERE id = 7", Own: true},
+		{ID: "2", User: "app", Query: "SELECT * FROM users WHERE email = 'x@y.z'"},
+		{ID: "3", User: "app", Query: "SELECT salary FROM payroll"},
+	}}
+
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{RedactLiterals, []string{"SELECT * FROM users WHERE id = 7", "SELECT * FROM users WHERE email = ?", restrictedQueryText}},
+		{RedactHide, []string{"SELECT * FROM users WHERE id = 7", "", restrictedQueryText}},
+		{RedactNone, []string{"SELECT * FROM users WHERE id = 7", "SELECT * FROM users WHERE email = 'x@y.z'", restrictedQueryText}},
+	}
+	for _, tc := range tests {
+		RunningQueriesRedaction = tc.mode
+		result, rpcErr := server.runningQueries(context.Background())
+		if rpcErr != nil || result.IsError {
+			t.Fatalf("%s: unexpected error: %+v %+v", tc.mode, rpcErr, result)
+		}
+		var queries []ActiveQuery
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &queries); err != nil || len(queries) != len(tc.want) {
+			t.Fatalf("%s: expected %d queries, got %s", tc.mode, len(tc.want), result.Content[0].Text)
+		}
+		for i, want := range tc.want {
+			if queries[i].Query != want {
+				t.Errorf("%s: expected query %d to be %q, got %q", tc.mode, i, want, queries[i].Query)
+			}
+		}
+	}
+}
+
+func TestRunningQueries_Availability(t *testing.T) {
+	defer func(orig bool) { RunningQueries = orig }(RunningQueries)
+	server := newTestServer(t)
+
+	RunningQueries = false
+	if result, _ := server.runningQueries(context.Background()); !result.IsError {
+		t.Error("Expected an error while disabled")
+	}
+	RunningQueries = true
+	if result, _ := server.runningQueries(context.Background()); !result.IsError {
+		t.Error("Expected SQLite to be unsupported")
+	}
+	tools, _ := server.handleListTools()
+	found := false
+	for _, tool := range tools.Tools {
+		found = found || tool.Name == "running_queries"
+	}
+	if !found {
+		t.Error("Expected running_queries to be listed when enabled")
+	}
+}
@@ -0,0 +1,588 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ConnParams are the individual settings a DSN is built from.
+type ConnParams struct {
+	Host     string
+	Port     string
+	Database string
+	User     string
+	Password string
+}
+
+// TableInfo describes a table for schema summaries.
+type TableInfo struct {
+	Name    string
+	Comment string
+	// Rows is the database's row estimate, or -1 when unknown
+	Rows        int64
+	Columns     []ColumnInfo
+	ForeignKeys []ForeignKey
+}
+
+// ColumnInfo describes a table column.
+type ColumnInfo struct {
+	Name       string
+	Type       string
+	PrimaryKey bool
+	Comment    string
+}
+
+// ForeignKey maps Columns of a table to RefColumns of RefTable.
+type ForeignKey struct {
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+}
+
+// PlanAccess is one table access in a query plan.
+type PlanAccess struct {
+	// Table is the table name or alias as the plan shows it
+	Table string
+	// Index is the index read, or "" when the table itself is read
+	Index string
+	// FullScan is set when every row of the table (or index) is read
+	FullScan bool
+}
+
+// How a plan step reads its table
+const (
+	PlanFullScan      = "full_scan"
+	PlanFullIndexScan = "full_index_scan"
+	PlanIndexLookup   = "index_lookup"
+)
+
+// PlanNode is one step of a query plan, in the shape the query tool
+// returns for every database's EXPLAIN.
+type PlanNode struct {
+	// Operation is the step as the database names it, e.g. "Seq Scan"
+	Operation string `json:"operation"`
+	Table     string `json:"table,omitempty"`
+	Index     string `json:"index,omitempty"`
+	// Access is PlanFullScan, PlanFullIndexScan or PlanIndexLookup for
+	// steps reading a table
+	Access string `json:"access,omitempty"`
+	// EstimatedRows and EstimatedCost are the planner's estimates, when it
+	// reports them; costs are in the database's own units
+	EstimatedRows *float64 `json:"estimated_rows,omitempty"`
+	EstimatedCost *float64 `json:"estimated_cost,omitempty"`
+	// Detail holds the step's conditions or the database's description
+	Detail   string     `json:"detail,omitempty"`
+	Children []PlanNode `json:"children,omitempty"`
+}
+
+// IndexInfo describes an index and its key columns in order.
+type IndexInfo struct {
+	Table   string
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// SequenceInfo describes a sequence or auto-increment counter.
+type SequenceInfo struct {
+	// Name is the sequence's name, or the table's for a table's counter
+	Name string `json:"name"`
+	// Table and Column are the column the sequence numbers, when known
+	Table  string `json:"table,omitempty"`
+	Column string `json:"column,omitempty"`
+	// LastValue is the last value handed out, or nil when unknown
+	LastValue *int64 `json:"last_value"`
+	// NextValue is the value the next row gets, or nil when unknown
+	NextValue *int64 `json:"next_value"`
+	// MaxValue is the largest value the sequence or its column can hold
+	MaxValue uint64 `json:"max_value"`
+}
+
+// StatementStats aggregates the executions of one normalized statement.
+type StatementStats struct {
+	Schema string `json:"schema"`
+	// Digest is the statement with literals replaced by ?
+	Digest         string  `json:"digest"`
+	Calls          int64   `json:"calls"`
+	TotalLatencyMs float64 `json:"total_latency_ms"`
+	AvgLatencyMs   float64 `json:"avg_latency_ms"`
+	MaxLatencyMs   float64 `json:"max_latency_ms"`
+	RowsExamined   int64   `json:"rows_examined"`
+	RowsSent       int64   `json:"rows_sent"`
+	// NoIndexUsed counts executions that read a table without an index
+	NoIndexUsed int64  `json:"no_index_used"`
+	LastSeen    string `json:"last_seen"`
+}
+
+// Statement orders for TopStatements
+const (
+	StatementOrderTotalLatency = "total_latency"
+	StatementOrderAvgLatency   = "avg_latency"
+	StatementOrderCalls        = "calls"
+)
+
+// ActiveQuery is a statement running on the database server.
+type ActiveQuery struct {
+	ID       string  `json:"id"`
+	User     string  `json:"user"`
+	Database string  `json:"database"`
+	State    string  `json:"state"`
+	Seconds  float64 `json:"seconds"`
+	Query    string  `json:"query"`
+	// Own is set for statements of the account the server connects as
+	Own bool `json:"own"`
+}
+
+// IdentifierCase describes how a database matches the case of table and
+// column names.
+type IdentifierCase struct {
+	// UnquotedFolding is what unquoted identifiers are converted to before
+	// lookup: "lower", or "none" when they are used as written
+	UnquotedFolding string `json:"unquoted_folding"`
+	// TablesCaseSensitive reports whether table names differing only in
+	// case name different tables
+	TablesCaseSensitive bool `json:"tables_case_sensitive"`
+	// ColumnsCaseSensitive reports the same for column names
+	ColumnsCaseSensitive bool `json:"columns_case_sensitive"`
+	// Note states the rules for whoever writes the SQL
+	Note string `json:"note"`
+}
+
+// DatabaseSettings describes how a database compares, sorts and displays
+// values.
+type DatabaseSettings struct {
+	// Settings maps setting names (e.g. "collation_database", "timezone")
+	// to their values
+	Settings map[string]string
+	// Collations maps each table to the number of its columns using each
+	// collation
+	Collations map[string]map[string]int
+}
+
+// DialFunc establishes the network connection to a database server.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// DBAdapter defines the contract for database-specific behavior.
+// Each supported database (MySQL, PostgreSQL, SQLite, DuckDB) implements this interface.
+type DBAdapter interface {
+	// DriverName returns the database/sql driver name (e.g., "mysql", "postgres", "sqlite").
+	DriverName() string
+
+	// ServerName returns the MCP server name for this adapter.
+	ServerName() string
+
+	// URIScheme returns the resource URI scheme (e.g., "mysql", "postgres", "sqlite").
+	URIScheme() string
+
+	// Remote reports whether the database is reached over the network, as
+	// opposed to a local file, so dialers and tunnels can apply.
+	Remote() bool
+
+	// BuildDSN constructs a DSN from environment variables.
+	BuildDSN() (string, error)
+
+	// ResolveDSN validates a DSN from any source (environment, argument or
+	// secret) and returns the form to connect with.
+	ResolveDSN(dsn string) (string, error)
+
+	// FormatDSN constructs a DSN from individual connection parameters, e.g.
+	// the fields of a credential secret.
+	FormatDSN(p ConnParams) (string, error)
+
+	// ConnectorWithDialer returns a connector for dsn whose connections are
+	// established through dial instead of the address in the DSN.
+	ConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error)
+
+	// WithAccessToken returns dsn authenticating with an OAuth access token
+	// (e.g. from Azure AD) in place of its password.
+	WithAccessToken(dsn, token string) (string, error)
+
+	// WithConnectionLabel returns dsn naming its connections label where the
+	// database shows it to DBAs (e.g. application_name), unless dsn or the
+	// driver's environment already names them.
+	WithConnectionLabel(dsn, label string) (string, error)
+
+	// TLSVerified reports whether connections made with dsn are encrypted
+	// and verify the server's certificate. Local databases report true.
+	TLSVerified(dsn string) bool
+
+	// DatabaseName extracts the database/file name from a DSN string.
+	DatabaseName(dsn string) string
+
+	// ReadOnlyStatements returns the statements executed on every new pooled
+	// connection to put its session into read-only mode.
+	ReadOnlyStatements() []string
+
+	// ReadOnlyCheckQuery returns a query yielding a single value that is true
+	// ("1" or "on") when the session is in read-only mode.
+	ReadOnlyCheckQuery() string
+
+	// WriteProbe returns a harmless statement creating a scratch table
+	// named name, which a read-only session must refuse, and, where the
+	// table would outlive a rolled-back transaction, the statement dropping
+	// it.
+	WriteProbe(name string) (probe, cleanup string)
+
+	// SnapshotTxOptions returns the options of a read-only transaction whose
+	// queries all see the same snapshot of the data, for MCP_SNAPSHOT_SESSION.
+	SnapshotTxOptions() *sql.TxOptions
+
+	// SearchPathStatement returns the statement that, run in a query's
+	// transaction before the query, makes unqualified names in it resolve
+	// to schema, or ErrSearchPathUnsupported.
+	SearchPathStatement(schema string) (string, error)
+
+	// ListTablesQuery returns the SQL query and arguments to list all tables.
+	ListTablesQuery(databaseName string) (string, []any)
+
+	// ReadSchemaQuery returns the SQL query and arguments to read column info for a table.
+	ReadSchemaQuery(databaseName, tableName string) (string, []any)
+
+	// ScanSchemaRow scans a single row from the schema query result into a column map.
+	ScanSchemaRow(rows *sql.Rows) (map[string]any, error)
+
+	// DecodeValue converts a scanned result value of the given database type
+	// (as reported by sql.ColumnType.DatabaseTypeName) to its JSON form.
+	// Values are already converted to UTF-8 text where they were bytes.
+	DecodeValue(databaseType string, val any) any
+
+	// QuoteIdentifier quotes a table or column name for use in SQL.
+	QuoteIdentifier(name string) string
+
+	// QuoteString quotes a string as an SQL literal.
+	QuoteString(value string) string
+
+	// ValuesQuery returns a SELECT producing rows, each a list of SQL
+	// literals, as a result with the given column names.
+	ValuesQuery(columns []string, rows [][]string) string
+
+	// FoldIdentifier returns name as the database looks it up when written
+	// unquoted (e.g. lowercased by PostgreSQL).
+	FoldIdentifier(name string) string
+
+	// IdentifierCase reports how the database matches identifier case.
+	IdentifierCase(ctx context.Context, db *sql.DB) (*IdentifierCase, error)
+
+	// TimeBucket returns an expression truncating the timestamp expression
+	// column to the start of its unit (one of TimeBucketUnits); weeks start
+	// on Monday.
+	TimeBucket(column, unit string) (string, error)
+
+	// EpochSeconds returns an expression converting the date or timestamp
+	// expression column to seconds since 1970-01-01 00:00:00, reading
+	// values without a time zone as UTC.
+	EpochSeconds(column string) string
+
+	// JSONExtract returns an expression reading the value at keys, a path of
+	// object keys, from the JSON column, as text.
+	JSONExtract(column string, keys []string) string
+
+	// SampleQuery returns SQL selecting about limit random rows of table,
+	// using native sampling (e.g. TABLESAMPLE) where available so large
+	// tables are not read or sorted in full.
+	SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error)
+
+	// AsOfSampleQuery returns SQL selecting about limit random rows of table
+	// as they were at the UTC time at, or ErrAsOfUnsupported when the server
+	// keeps no row history.
+	AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error)
+
+	// DescribeSchema returns every table with its columns, comments and
+	// foreign keys, in a single pass over the catalog.
+	DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error)
+
+	// ViewDefinitions returns the SELECT statement of every view of the
+	// database, keyed by view name; a definition is empty when the account
+	// may not see it.
+	ViewDefinitions(ctx context.Context, db *sql.DB, databaseName string) (map[string]string, error)
+
+	// ExplainAccess plans query without running it and returns how each
+	// table is accessed, in plan order.
+	ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error)
+
+	// ExplainPlan plans query without running it and returns the plan's
+	// top-level steps.
+	ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error)
+
+	// EstimateCost plans query, with args bound to its placeholders, without
+	// running it and returns the planner's total cost estimate, in the
+	// database's own cost units, or ErrCostUnsupported when the database has
+	// no cost model.
+	EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error)
+
+	// ListIndexes returns every index of the database's tables. Key columns
+	// that are expressions have an empty name.
+	ListIndexes(ctx context.Context, db *sql.DB, databaseName string) ([]IndexInfo, error)
+
+	// ListSequences returns the sequences and auto-increment counters of the
+	// database's tables with their current values.
+	ListSequences(ctx context.Context, db *sql.DB, databaseName string) ([]SequenceInfo, error)
+
+	// DescribeSettings returns the character sets, collations, time zone and
+	// name resolution settings (e.g. search_path) in effect for databaseName.
+	DescribeSettings(ctx context.Context, db *sql.DB, databaseName string) (*DatabaseSettings, error)
+
+	// ReadVariables returns the values of the named session or server
+	// variables, leaving out those the database does not have. Names are
+	// lower case and match variableNamePattern; version and time_zone are
+	// mapped to the database's own names.
+	ReadVariables(ctx context.Context, db *sql.DB, names []string) (map[string]string, error)
+
+	// TopStatements returns the limit statements with the highest orderBy
+	// (a StatementOrder constant) from the server's statement statistics, or
+	// ErrStatementStatsUnsupported when the database keeps none.
+	TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error)
+
+	// ActiveQueries returns the statements running on the server, longest
+	// running first, excluding idle connections and the caller's own, or
+	// ErrActivityUnsupported.
+	ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error)
+
+	// AuditPrivileges inspects what the connected account is allowed to do and
+	// returns a description of each write/DDL capability found.
+	AuditPrivileges(ctx context.Context, db *sql.DB, dsn string) ([]string, error)
+
+	// ValidateQuery validates that a SQL query is safe and read-only.
+	ValidateQuery(sql string) error
+
+	// ImplicitSchemaReference returns the system schema that cleanedSQL reads
+	// without naming it (e.g. Postgres's pg_catalog), or "".
+	ImplicitSchemaReference(cleanedSQL string) string
+
+	// IsTransientError reports whether err is a momentary failure (deadlock
+	// victim, serialization failure, lock contention) worth retrying.
+	IsTransientError(err error) bool
+
+	// NormalizeRowLimit rewrites row limiting clauses of other dialects
+	// (TOP, FETCH FIRST, LIMIT m, n) that the database does not accept into
+	// its own syntax, returning the query unchanged otherwise.
+	NormalizeRowLimit(sqlQuery string) string
+
+	// RemoveStringsAndComments strips string literals and comments from SQL
+	// for safe keyword detection.
+	RemoveStringsAndComments(sql string) string
+}
+
+// The interfaces below are optional capabilities: an adapter implements the
+// ones its database supports, and the features they back are left out for
+// adapters that do not.
+
+// ReadOnlyTxOptioner is implemented by adapters whose driver refuses
+// sql.TxOptions{ReadOnly: true}. Queries then run in transactions begun with
+// the options ReadOnlyTxOptions returns.
+type ReadOnlyTxOptioner interface {
+	ReadOnlyTxOptions() *sql.TxOptions
+}
+
+// ExtensionLister is implemented by adapters for databases with installable
+// extensions, which are listed as the extensions resource.
+type ExtensionLister interface {
+	ListExtensions(ctx context.Context, db *sql.DB) ([]ExtensionInfo, error)
+}
+
+// TopQueryReader is implemented by adapters for databases that keep
+// statistics per statement, which top_queries reports. TopQueries returns
+// the limit statements of the current database with the highest orderBy (a
+// StatementOrder constant); allStats is false when other users' statements
+// are reported without their text.
+type TopQueryReader interface {
+	TopQueries(ctx context.Context, db *sql.DB, orderBy string, limit int) (queries []TopQuery, allStats bool, err error)
+}
+
+// TimescaleReader is implemented by adapters for databases that may have
+// the TimescaleDB extension. TimescaleVersion returns its installed version,
+// or "" when it is not installed; ListHypertables is only called when it is.
+type TimescaleReader interface {
+	TimescaleVersion(ctx context.Context, db *sql.DB) (string, error)
+	ListHypertables(ctx context.Context, db *sql.DB) (map[string]*HypertableInfo, error)
+}
+
+// VirtualTableLister is implemented by adapters for databases with virtual
+// tables, which schema resources describe along with the shadow tables that
+// hold their data.
+type VirtualTableLister interface {
+	VirtualTables(ctx context.Context, db *sql.DB) ([]VirtualTableInfo, error)
+}
+
+// TimeParamBinder is implemented by adapters whose database needs dates and
+// timestamps in query params in another form than a time.Time. BindTime
+// returns the value to bind for t, which is a date at midnight UTC when
+// dateOnly.
+type TimeParamBinder interface {
+	BindTime(t time.Time, dateOnly bool) any
+}
+
+// FileDatabase is implemented by adapters for databases kept in a local
+// file. DatabaseFile returns the path of the file dsn opens, or "" when it
+// opens none, so the result cache can refuse to overwrite it.
+type FileDatabase interface {
+	DatabaseFile(dsn string) string
+}
+
+// TiDBTableReader is implemented by adapters for TiDB and databases that
+// store tables as it does; schema resources describe each table's storage.
+type TiDBTableReader interface {
+	TiDBTables(ctx context.Context, db *sql.DB, databaseName string) (map[string]*TiDBTableInfo, error)
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = map[string]func() DBAdapter{
+		"mysql":       func() DBAdapter { return &MySQLAdapter{} },
+		"postgres":    func() DBAdapter { return &PostgresAdapter{Driver: PostgresDriver} },
+		"postgresql":  func() DBAdapter { return &PostgresAdapter{Driver: PostgresDriver} },
+		"cockroach":   func() DBAdapter { return &CockroachAdapter{PostgresAdapter{Driver: PostgresDriver}} },
+		"cockroachdb": func() DBAdapter { return &CockroachAdapter{PostgresAdapter{Driver: PostgresDriver}} },
+		"sqlite":      func() DBAdapter { return &SQLiteAdapter{} },
+		"sqlite3":     func() DBAdapter { return &SQLiteAdapter{} },
+		"tidb":        func() DBAdapter { return &TiDBAdapter{} },
+		"duckdb":      func() DBAdapter { return &DuckDBAdapter{} },
+	}
+)
+
+// RegisterAdapter makes an adapter available under name (case-insensitive)
+// for MCP_DB_DRIVER and Config.Driver, replacing any existing registration.
+// factory is called once per server to create its adapter. Call it from an
+// init function (or an adapter plugin's, see MCP_ADAPTER_PLUGINS) so the
+// adapter is available before the driver is selected.
+func RegisterAdapter(name string, factory func() DBAdapter) {
+	if name == "" || factory == nil {
+		panic("mcpsqldb: RegisterAdapter requires a name and factory")
+	}
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	adapters[strings.ToLower(name)] = factory
+}
+
+// newAdapter returns a fresh adapter registered under name.
+func newAdapter(name string) (DBAdapter, error) {
+	adaptersMu.RLock()
+	factory, ok := adapters[strings.ToLower(name)]
+	adaptersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver: %s (supported: %s)", strings.ToLower(name), adapterNames())
+	}
+	return factory(), nil
+}
+
+// adapterNames lists the registered adapter names for error messages.
+func adapterNames() string {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// tableSet collects TableInfo by name while catalog rows are scanned by
+// DescribeSchema implementations.
+type tableSet struct {
+	byName map[string]*TableInfo
+	fkKeys map[string]int
+}
+
+func newTableSet() *tableSet {
+	return &tableSet{byName: map[string]*TableInfo{}, fkKeys: map[string]int{}}
+}
+
+func (t *tableSet) get(name string) *TableInfo {
+	table, ok := t.byName[name]
+	if !ok {
+		table = &TableInfo{Name: name, Rows: -1}
+		t.byName[name] = table
+	}
+	return table
+}
+
+// addForeignKeyColumn appends one column pair of the named constraint,
+// creating the foreign key on its first column.
+func (t *tableSet) addForeignKeyColumn(table, constraint, column, refTable, refColumn string) {
+	info := t.get(table)
+	key := table + "\x00" + constraint
+	i, ok := t.fkKeys[key]
+	if !ok {
+		i = len(info.ForeignKeys)
+		t.fkKeys[key] = i
+		info.ForeignKeys = append(info.ForeignKeys, ForeignKey{RefTable: refTable})
+	}
+	fk := &info.ForeignKeys[i]
+	fk.Columns = append(fk.Columns, column)
+	fk.RefColumns = append(fk.RefColumns, refColumn)
+}
+
+// list returns the tables sorted by name.
+func (t *tableSet) list() []TableInfo {
+	tables := make([]TableInfo, 0, len(t.byName))
+	for _, table := range t.byName {
+		tables = append(tables, *table)
+	}
+	sort.Slice(tables, func(i, j int) bool { return tables[i].Name < tables[j].Name })
+	return tables
+}
+
+// appendIndexColumn adds the next key column of an index to indexes, which
+// ListIndexes implementations scan ordered by table, index and position.
+func appendIndexColumn(indexes []IndexInfo, table, name string, unique bool, column string) []IndexInfo {
+	if n := len(indexes); n == 0 || indexes[n-1].Table != table || indexes[n-1].Name != name {
+		indexes = append(indexes, IndexInfo{Table: table, Name: name, Unique: unique})
+	}
+	index := &indexes[len(indexes)-1]
+	index.Columns = append(index.Columns, column)
+	return indexes
+}
+
+// scanSettings scans a single row of setting values into a map keyed by
+// names, in column order. NULL values are left out.
+func scanSettings(row *sql.Row, names ...string) (map[string]string, error) {
+	values := make([]sql.NullString, len(names))
+	dest := make([]any, len(names))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	settings := make(map[string]string, len(names))
+	for i, name := range names {
+		if values[i].Valid {
+			settings[name] = values[i].String
+		}
+	}
+	return settings, nil
+}
+
+// addCollation counts n columns of table using collation.
+func (s *DatabaseSettings) addCollation(table, collation string, n int) {
+	if s.Collations == nil {
+		s.Collations = map[string]map[string]int{}
+	}
+	if s.Collations[table] == nil {
+		s.Collations[table] = map[string]int{}
+	}
+	s.Collations[table][collation] += n
+}
+
+// scanEach runs query and calls scan for every row.
+func scanEach(ctx context.Context, db *sql.DB, query string, args []any, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
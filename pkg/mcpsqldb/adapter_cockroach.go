@@ -0,0 +1,359 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CockroachAdapter implements DBAdapter for CockroachDB, which speaks the
+// PostgreSQL wire protocol and connects like PostgreSQL (the MCP_PG_* env
+// vars and MCP_PG_DRIVER apply), but has its own catalog details, EXPLAIN
+// output and internal functions.
+type CockroachAdapter struct {
+	PostgresAdapter
+}
+
+func (a *CockroachAdapter) ServerName() string { return "cockroachdb-readonly-mcp-server" }
+func (a *CockroachAdapter) URIScheme() string  { return "cockroachdb" }
+
+// FormatDSN defaults to CockroachDB's SQL port.
+func (a *CockroachAdapter) FormatDSN(p ConnParams) (string, error) {
+	if p.Port == "" {
+		p.Port = "26257"
+	}
+	return a.PostgresAdapter.FormatDSN(p)
+}
+
+// ReadOnlyStatements sets the session variable directly, as CockroachDB
+// applies SET SESSION CHARACTERISTICS only to isolation levels.
+func (a *CockroachAdapter) ReadOnlyStatements() []string {
+	return []string{"SET default_transaction_read_only = on"}
+}
+
+// WriteProbe creates a regular table, since temporary tables are
+// experimental and disabled by default. Schema changes are transactional,
+// so the rollback drops it.
+func (a *CockroachAdapter) WriteProbe(name string) (string, string) {
+	return "CREATE TABLE " + a.QuoteIdentifier(name) + " (id INT)", ""
+}
+
+// ListTablesQuery leaves out sequences, which CockroachDB lists in
+// information_schema.tables.
+func (a *CockroachAdapter) ListTablesQuery(databaseName string) (string, []any) {
+	return `SELECT table_name FROM information_schema.tables
+		WHERE table_schema = 'public' AND table_catalog = $1 AND table_type IN ('BASE TABLE', 'VIEW')`,
+		[]any{databaseName}
+}
+
+// ReadSchemaQuery leaves out hidden columns, such as the rowid CockroachDB
+// adds to tables without a primary key.
+func (a *CockroachAdapter) ReadSchemaQuery(databaseName, tableName string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable, column_default, collation_name
+		FROM information_schema.columns
+		WHERE table_catalog = $1 AND table_schema = 'public' AND table_name = $2 AND is_hidden = 'NO'
+		ORDER BY ordinal_position`, []any{databaseName, tableName}
+}
+
+// DescribeSchema reads pg_catalog like PostgreSQL, then drops the hidden
+// columns, which pg_attribute lists like any other.
+func (a *CockroachAdapter) DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error) {
+	tables, err := a.PostgresAdapter.DescribeSchema(ctx, db, databaseName)
+	if err != nil {
+		return nil, err
+	}
+	hidden := map[[2]string]bool{}
+	err = scanEach(ctx, db, `SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_catalog = $1 AND table_schema = 'public' AND is_hidden = 'YES'`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table, column string
+			if err := rows.Scan(&table, &column); err != nil {
+				return err
+			}
+			hidden[[2]string{table, column}] = true
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hidden columns: %w", err)
+	}
+	for i := range tables {
+		columns := tables[i].Columns[:0]
+		for _, col := range tables[i].Columns {
+			if !hidden[[2]string{tables[i].Name, col.Name}] {
+				columns = append(columns, col)
+			}
+		}
+		tables[i].Columns = columns
+	}
+	return tables, nil
+}
+
+// SampleQuery shuffles the whole table: CockroachDB has no TABLESAMPLE.
+func (a *CockroachAdapter) SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error) {
+	return fmt.Sprintf("SELECT * FROM %s ORDER BY random() LIMIT %d", a.QuoteIdentifier(table), limit), nil
+}
+
+// AsOfSampleQuery reads the table AS OF SYSTEM TIME, within the cluster's
+// garbage collection window.
+func (a *CockroachAdapter) AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error) {
+	return fmt.Sprintf("SELECT * FROM %s AS OF SYSTEM TIME %s ORDER BY random() LIMIT %d",
+		a.QuoteIdentifier(table), a.QuoteString(at.Format("2006-01-02 15:04:05.999999-07:00")), limit), nil
+}
+
+// ExplainAccess reads the table accesses of ExplainPlan.
+func (a *CockroachAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	nodes, err := a.ExplainPlan(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	var accesses []PlanAccess
+	var walk func(nodes []PlanNode)
+	walk = func(nodes []PlanNode) {
+		for _, node := range nodes {
+			if node.Table != "" {
+				accesses = append(accesses, PlanAccess{Table: node.Table, Index: node.Index, FullScan: node.Access != PlanIndexLookup})
+			}
+			walk(node.Children)
+		}
+	}
+	walk(nodes)
+	return accesses, nil
+}
+
+// ExplainPlan parses the tree EXPLAIN prints, as CockroachDB has no JSON
+// format.
+func (a *CockroachAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	lines, err := cockroachExplain(ctx, db, "EXPLAIN "+query)
+	if err != nil {
+		return nil, err
+	}
+	return parseCockroachPlan(lines), nil
+}
+
+// EstimateCost reads the cost of the root expression of EXPLAIN (OPT,
+// VERBOSE), the first the optimizer prints.
+func (a *CockroachAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	lines, err := cockroachExplain(ctx, db, "EXPLAIN (OPT, VERBOSE) "+query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return parseCockroachCost(lines)
+}
+
+// cockroachExplain runs an EXPLAIN with args and returns its lines of text.
+func cockroachExplain(ctx context.Context, db *sql.DB, explain string, args ...any) ([]string, error) {
+	var lines []string
+	err := scanEach(ctx, db, explain, args, func(rows *sql.Rows) error {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return err
+		}
+		lines = append(lines, line)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return lines, nil
+}
+
+// cockroachPlanNode matches a node line of EXPLAIN, e.g. "└── • scan".
+var cockroachPlanNode = regexp.MustCompile(`^([\s│├└─]*)• (.+)$`)
+
+// cockroachPlanRows matches the estimate leading an "estimated row count".
+var cockroachPlanRows = regexp.MustCompile(`^[\d,]+`)
+
+// parseCockroachPlan converts EXPLAIN's tree into PlanNodes. Each node is a
+// "• operation" line followed by its "key: value" attributes; a node's
+// children are the nodes after it printed further right. Scans name their
+// index as "table@index", and reading the primary index in full is a scan
+// of the table itself.
+func parseCockroachPlan(lines []string) []PlanNode {
+	type entry struct {
+		depth int
+		node  *PlanNode
+	}
+	var roots []*PlanNode
+	var stack []entry
+	var current *PlanNode
+	var details []string
+	var spans string
+
+	finish := func() {
+		if current == nil {
+			return
+		}
+		if current.Table != "" {
+			primary := current.Index == "primary" || current.Index == current.Table+"_pkey"
+			switch {
+			case spans == "FULL SCAN" && primary:
+				current.Index, current.Access = "", PlanFullScan
+			case spans == "FULL SCAN":
+				current.Access = PlanFullIndexScan
+			default:
+				current.Access = PlanIndexLookup
+			}
+		}
+		current.Detail = strings.Join(details, "; ")
+		details, spans = nil, ""
+	}
+
+	for _, line := range lines {
+		if m := cockroachPlanNode.FindStringSubmatch(line); m != nil {
+			finish()
+			depth := len([]rune(m[1]))
+			for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+				stack = stack[:len(stack)-1]
+			}
+			node := PlanNode{Operation: strings.TrimSpace(m[2])}
+			if len(stack) == 0 {
+				current = &node
+				roots = append(roots, current)
+			} else {
+				// Earlier siblings are complete, so moving them is safe
+				parent := stack[len(stack)-1].node
+				parent.Children = append(parent.Children, node)
+				current = &parent.Children[len(parent.Children)-1]
+			}
+			stack = append(stack, entry{depth: depth, node: current})
+			continue
+		}
+		attr := strings.TrimSpace(strings.TrimLeft(line, " │├└─"))
+		if current == nil || attr == "" {
+			// Lines before the first node describe the whole plan
+			continue
+		}
+		key, value, ok := strings.Cut(attr, ": ")
+		switch {
+		case ok && key == "table":
+			current.Table, current.Index, _ = strings.Cut(value, "@")
+		case ok && key == "estimated row count":
+			if n, err := strconv.ParseFloat(strings.ReplaceAll(cockroachPlanRows.FindString(value), ",", ""), 64); err == nil {
+				current.EstimatedRows = floatPtr(n)
+			}
+		case ok && key == "spans":
+			spans = value
+			details = append(details, attr)
+		default:
+			details = append(details, attr)
+		}
+	}
+	finish()
+
+	nodes := make([]PlanNode, 0, len(roots))
+	for _, root := range roots {
+		nodes = append(nodes, *root)
+	}
+	return nodes
+}
+
+// cockroachPlanCost matches a cost line of EXPLAIN (OPT, VERBOSE).
+var cockroachPlanCost = regexp.MustCompile(`\bcost: ([\d.e+-]+)`)
+
+// parseCockroachCost returns the first cost of an EXPLAIN (OPT, VERBOSE).
+func parseCockroachCost(lines []string) (float64, error) {
+	for _, line := range lines {
+		if m := cockroachPlanCost.FindStringSubmatch(line); m != nil {
+			return strconv.ParseFloat(m[1], 64)
+		}
+	}
+	return 0, fmt.Errorf("plan has no cost")
+}
+
+// ActiveQueries reads crdb_internal.cluster_queries, which covers every
+// node of the cluster and shows other users' queries only to accounts with
+// the VIEWACTIVITY privilege.
+func (a *CockroachAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	var queries []ActiveQuery
+	err := scanEach(ctx, db, `SELECT query_id, user_name, phase,
+			EXTRACT(EPOCH FROM now() - start)::FLOAT8, query, user_name = current_user
+		FROM crdb_internal.cluster_queries
+		WHERE session_id <> current_setting('session_id')
+		ORDER BY start`, nil,
+		func(rows *sql.Rows) error {
+			var q ActiveQuery
+			if err := rows.Scan(&q.ID, &q.User, &q.State, &q.Seconds, &q.Query, &q.Own); err != nil {
+				return err
+			}
+			queries = append(queries, q)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read crdb_internal.cluster_queries: %w", err)
+	}
+	return queries, nil
+}
+
+// cockroachStatementOrder maps statement orders to the columns of the
+// TopQueries query.
+var cockroachStatementOrder = map[string]string{
+	StatementOrderTotalLatency: "total_ms",
+	StatementOrderAvgLatency:   "mean_ms",
+	StatementOrderCalls:        "calls",
+}
+
+// TopQueries reads crdb_internal.statement_statistics, which holds the
+// statistics of every node of the cluster in intervals, adding up each
+// statement's intervals. Reading it needs the VIEWACTIVITY privilege, and
+// statements keep their text, so allStats is true.
+func (a *CockroachAdapter) TopQueries(ctx context.Context, db *sql.DB, orderBy string, limit int) (queries []TopQuery, allStats bool, err error) {
+	column, ok := cockroachStatementOrder[orderBy]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown order %q", orderBy)
+	}
+	err = scanEach(ctx, db, `SELECT query, calls, total_ms, total_ms / GREATEST(calls, 1)::FLOAT8 AS mean_ms, rows
+		FROM (
+			SELECT metadata->>'query' AS query,
+				sum((statistics->'statistics'->>'cnt')::INT8) AS calls,
+				sum((statistics->'statistics'->>'cnt')::FLOAT8 * (statistics->'statistics'->'svcLat'->>'mean')::FLOAT8) * 1000 AS total_ms,
+				sum((statistics->'statistics'->>'cnt')::FLOAT8 * (statistics->'statistics'->'numRows'->>'mean')::FLOAT8)::INT8 AS rows
+			FROM crdb_internal.statement_statistics
+			WHERE metadata->>'db' = current_database()
+			GROUP BY metadata->>'query'
+		) AS s
+		ORDER BY `+column+` DESC
+		LIMIT $1`, []any{limit},
+		func(rows *sql.Rows) error {
+			var q TopQuery
+			if err := rows.Scan(&q.Query, &q.Calls, &q.TotalTimeMs, &q.MeanTimeMs, &q.Rows); err != nil {
+				return err
+			}
+			queries = append(queries, q)
+			return nil
+		})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read crdb_internal.statement_statistics: %w", err)
+	}
+	return queries, true, nil
+}
+
+// ValidateQuery applies the PostgreSQL rules, then blocks the
+// crdb_internal functions, which can crash nodes, rewrite descriptors or
+// reset statistics, SHOW BACKUP, which reads external storage, and
+// statement bundles, which EXPLAIN ANALYZE (DEBUG) writes to the cluster.
+func (a *CockroachAdapter) ValidateQuery(sqlQuery string) error {
+	if err := a.PostgresAdapter.ValidateQuery(sqlQuery); err != nil {
+		return err
+	}
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	forbiddenPatterns := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bcrdb_internal\s*\.\s*\w+\s*\(`, "crdb_internal functions"},
+		{`(?i)\bSHOW\s+BACKUPS?\b`, "SHOW BACKUP"},
+		{`(?i)\bEXPLAIN\s+ANALY[SZ]E\s*\([^)]*\bDEBUG\b`, "EXPLAIN ANALYZE (DEBUG)"},
+	}
+	for _, fp := range forbiddenPatterns {
+		re := regexp.MustCompile(fp.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden pattern: %s", fp.desc)
+		}
+	}
+	return nil
+}
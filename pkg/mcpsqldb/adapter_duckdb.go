@@ -0,0 +1,730 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DuckDBAllowedDirs confines DuckDB databases, and the files their queries
+// read with read_parquet, read_csv and the like, to these directories; empty
+// allows any database path but no other files (overridable via
+// MCP_DUCKDB_ALLOWED_DIRS env var, comma-separated)
+var DuckDBAllowedDirs []string
+
+// DuckDBAdapter implements DBAdapter for DuckDB database files. The driver
+// needs cgo and is only built in with the duckdb build tag.
+type DuckDBAdapter struct{}
+
+func (a *DuckDBAdapter) DriverName() string { return "duckdb" }
+func (a *DuckDBAdapter) ServerName() string { return "duckdb-readonly-mcp-server" }
+func (a *DuckDBAdapter) URIScheme() string  { return "duckdb" }
+
+func (a *DuckDBAdapter) Remote() bool { return false }
+
+func (a *DuckDBAdapter) BuildDSN() (string, error) {
+	dbPath := os.Getenv("MCP_DUCKDB_PATH")
+	if dbPath == "" {
+		return "", fmt.Errorf("missing required environment variable: MCP_DUCKDB_PATH")
+	}
+	return a.FormatDSN(ConnParams{Database: dbPath})
+}
+
+// ResolveDSN confines the database file to MCP_DUCKDB_ALLOWED_DIRS, pins it
+// to its resolved path and sets the configuration DuckDB applies when it
+// opens the database.
+func (a *DuckDBAdapter) ResolveDSN(dsn string) (string, error) {
+	if !slices.Contains(sql.Drivers(), a.DriverName()) {
+		return "", fmt.Errorf("this build has no DuckDB driver; rebuild with -tags duckdb (requires cgo)")
+	}
+	return sandboxDuckDBDSN(dsn, DuckDBAllowedDirs)
+}
+
+// sandboxDuckDBDSN checks the database file named by dsn and returns the DSN
+// rewritten to the file's resolved path, with read-only access, no external
+// files outside allowedDirs, and the configuration locked so no SET can
+// change either.
+func sandboxDuckDBDSN(dsn string, allowedDirs []string) (string, error) {
+	path, query, _ := strings.Cut(dsn, "?")
+	resolved, err := resolveDatabaseFile(path, allowedDirs, "DuckDB", "MCP_DUCKDB_ALLOWED_DIRS")
+	if err != nil {
+		return "", err
+	}
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid DuckDB DSN parameters: %w", err)
+	}
+	params.Set("access_mode", "read_only")
+	params.Set("enable_external_access", "false")
+	params.Del("allowed_directories")
+	params.Del("allowed_paths")
+	if len(allowedDirs) > 0 {
+		dirs := make([]string, 0, len(allowedDirs))
+		for _, dir := range allowedDirs {
+			abs, err := filepath.Abs(dir)
+			if err != nil {
+				return "", fmt.Errorf("invalid MCP_DUCKDB_ALLOWED_DIRS entry %q: %w", dir, err)
+			}
+			dirs = append(dirs, "'"+strings.ReplaceAll(abs+string(filepath.Separator), "'", "''")+"'")
+		}
+		params.Set("allowed_directories", "["+strings.Join(dirs, ", ")+"]")
+	}
+	params.Set("lock_configuration", "true")
+	return resolved + "?" + params.Encode(), nil
+}
+
+// FormatDSN treats Database as the file path; the other parameters do not
+// apply to DuckDB.
+func (a *DuckDBAdapter) FormatDSN(p ConnParams) (string, error) {
+	dbPath := p.Database
+	if dbPath == "" {
+		return "", fmt.Errorf("missing DuckDB database path")
+	}
+	if !strings.Contains(dbPath, "?") {
+		return dbPath + "?access_mode=read_only", nil
+	}
+	if !strings.Contains(dbPath, "access_mode=") {
+		return dbPath + "&access_mode=read_only", nil
+	}
+	return dbPath, nil
+}
+
+func (a *DuckDBAdapter) ConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error) {
+	return nil, fmt.Errorf("DuckDB databases are local files and cannot use a network dialer")
+}
+
+func (a *DuckDBAdapter) WithAccessToken(dsn, token string) (string, error) {
+	return "", fmt.Errorf("DuckDB databases are local files and do not use access tokens")
+}
+
+// WithConnectionLabel leaves dsn unchanged: a DuckDB file has no server to
+// show connections to.
+func (a *DuckDBAdapter) WithConnectionLabel(dsn, label string) (string, error) { return dsn, nil }
+
+func (a *DuckDBAdapter) TLSVerified(dsn string) bool { return true }
+
+// DatabaseName returns the file name without its extension, which is the
+// name DuckDB gives the database's catalog.
+func (a *DuckDBAdapter) DatabaseName(dsn string) string {
+	path, _, _ := strings.Cut(dsn, "?")
+	name := filepath.Base(path)
+	return strings.TrimSuffix(name, filepath.Ext(name))
+}
+
+func (a *DuckDBAdapter) DatabaseFile(dsn string) string {
+	path, _, _ := strings.Cut(dsn, "?")
+	return path
+}
+
+// ReadOnlyStatements is empty: read-only access is configured when the
+// database is opened (see ResolveDSN) and locked, so no session can SET it.
+func (a *DuckDBAdapter) ReadOnlyStatements() []string {
+	return nil
+}
+
+func (a *DuckDBAdapter) ReadOnlyCheckQuery() string {
+	return "SELECT current_setting('access_mode') = 'read_only'"
+}
+
+// WriteProbe creates a table in the database file itself, since DuckDB
+// allows temporary tables in read-only databases. DDL is transactional, so
+// the rollback drops it.
+func (a *DuckDBAdapter) WriteProbe(name string) (string, string) {
+	return "CREATE TABLE " + a.QuoteIdentifier(name) + " (id INTEGER)", ""
+}
+
+// SnapshotTxOptions sets no options: every DuckDB transaction reads a single
+// snapshot, and the driver refuses read-only transactions, which a database
+// opened read-only needs no more than it gets.
+func (a *DuckDBAdapter) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{}
+}
+
+// ReadOnlyTxOptions sets no options: the driver refuses read-only
+// transactions, and the database is opened read-only instead.
+func (a *DuckDBAdapter) ReadOnlyTxOptions() *sql.TxOptions {
+	return nil
+}
+
+// SearchPathStatement is unsupported: the configuration is locked, so SET
+// search_path is refused.
+func (a *DuckDBAdapter) SearchPathStatement(schema string) (string, error) {
+	return "", ErrSearchPathUnsupported
+}
+
+// ListTablesQuery lists the tables and views of the main schema from
+// DuckDB's own catalog functions; databaseName is the file's catalog, which
+// current_database() already names.
+func (a *DuckDBAdapter) ListTablesQuery(databaseName string) (string, []any) {
+	return `SELECT table_name FROM duckdb_tables()
+		WHERE database_name = current_database() AND schema_name = 'main' AND NOT internal
+		UNION ALL
+		SELECT view_name FROM duckdb_views()
+		WHERE database_name = current_database() AND schema_name = 'main' AND NOT internal
+		ORDER BY 1`, nil
+}
+
+func (a *DuckDBAdapter) ReadSchemaQuery(databaseName, tableName string) (string, []any) {
+	return `SELECT c.column_name, c.data_type, c.is_nullable, c.column_default, c.comment,
+			COALESCE(list_contains(k.constraint_column_names, c.column_name), false)
+		FROM duckdb_columns() c
+		LEFT JOIN duckdb_constraints() k ON k.database_name = c.database_name
+			AND k.schema_name = c.schema_name AND k.table_name = c.table_name
+			AND k.constraint_type = 'PRIMARY KEY'
+		WHERE c.database_name = current_database() AND c.schema_name = 'main' AND c.table_name = ?
+		ORDER BY c.column_index`, []any{tableName}
+}
+
+func (a *DuckDBAdapter) ScanSchemaRow(rows *sql.Rows) (map[string]any, error) {
+	var name, dataType string
+	var nullable, pk bool
+	var colDefault, comment sql.NullString
+
+	if err := rows.Scan(&name, &dataType, &nullable, &colDefault, &comment, &pk); err != nil {
+		return nil, err
+	}
+
+	isNullable := "YES"
+	if !nullable {
+		isNullable = "NO"
+	}
+
+	col := map[string]any{
+		"column_name": name,
+		"data_type":   dataType,
+		"is_nullable": isNullable,
+	}
+	if pk {
+		col["column_key"] = "PRI"
+	}
+	if colDefault.Valid {
+		col["column_default"] = colDefault.String
+	}
+	if comment.Valid && comment.String != "" {
+		col["comment"] = comment.String
+	}
+	return col, nil
+}
+
+// DecodeValue returns decimals as JSON numbers with their exact digits and
+// UUIDs as text; the driver scans both into its own types.
+func (a *DuckDBAdapter) DecodeValue(databaseType string, val any) any {
+	stringer, ok := val.(fmt.Stringer)
+	if !ok {
+		return val
+	}
+	switch {
+	case strings.HasPrefix(databaseType, "DECIMAL"):
+		return json.Number(stringer.String())
+	case databaseType == "UUID":
+		return stringer.String()
+	}
+	return val
+}
+
+func (a *DuckDBAdapter) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (a *DuckDBAdapter) QuoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func (a *DuckDBAdapter) ValuesQuery(columns []string, rows [][]string) string {
+	return valuesQuery(a, columns, rows)
+}
+
+func (a *DuckDBAdapter) FoldIdentifier(name string) string {
+	return name
+}
+
+// IdentifierCase reports DuckDB's fixed rules: names keep the case they were
+// created with but match regardless of it, quoted or not.
+func (a *DuckDBAdapter) IdentifierCase(ctx context.Context, db *sql.DB) (*IdentifierCase, error) {
+	return &IdentifierCase{
+		UnquotedFolding: "none",
+		Note:            "Table and column names are case-insensitive, quoted or not",
+	}, nil
+}
+
+// TimeBucket uses date_trunc, whose weeks start on Monday.
+func (a *DuckDBAdapter) TimeBucket(column, unit string) (string, error) {
+	if !isTimeBucketUnit(unit) {
+		return "", fmt.Errorf("unknown time bucket %q", unit)
+	}
+	return fmt.Sprintf("date_trunc('%s', %s)", unit, column), nil
+}
+
+// EpochSeconds uses epoch, which accepts dates and timestamps.
+func (a *DuckDBAdapter) EpochSeconds(column string) string {
+	return fmt.Sprintf("epoch(%s)", column)
+}
+
+// JSONExtract uses json_extract_string, which returns strings unquoted.
+func (a *DuckDBAdapter) JSONExtract(column string, keys []string) string {
+	return fmt.Sprintf("json_extract_string(%s, %s)", column, a.QuoteString(jsonPath(keys)))
+}
+
+// SampleQuery uses reservoir sampling, which reads the table once and keeps
+// exactly limit rows without sorting it.
+func (a *DuckDBAdapter) SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error) {
+	return fmt.Sprintf("SELECT * FROM %s USING SAMPLE reservoir(%d ROWS)", a.QuoteIdentifier(table), limit), nil
+}
+
+// AsOfSampleQuery is unsupported: DuckDB keeps no row history.
+func (a *DuckDBAdapter) AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error) {
+	return "", ErrAsOfUnsupported
+}
+
+// DescribeSchema reads DuckDB's catalog functions. Views are included with
+// their columns but no row estimate.
+func (a *DuckDBAdapter) DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error) {
+	tables := newTableSet()
+
+	err := scanEach(ctx, db, `SELECT table_name, estimated_size, COALESCE(comment, '')
+		FROM duckdb_tables()
+		WHERE database_name = current_database() AND schema_name = 'main' AND NOT internal`, nil,
+		func(rows *sql.Rows) error {
+			var name, comment string
+			var estimate int64
+			if err := rows.Scan(&name, &estimate, &comment); err != nil {
+				return err
+			}
+			info := tables.get(name)
+			info.Rows = estimate
+			info.Comment = comment
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT c.table_name, c.column_name, c.data_type, COALESCE(c.comment, ''),
+			COALESCE(list_contains(k.constraint_column_names, c.column_name), false)
+		FROM duckdb_columns() c
+		LEFT JOIN duckdb_constraints() k ON k.database_name = c.database_name
+			AND k.schema_name = c.schema_name AND k.table_name = c.table_name
+			AND k.constraint_type = 'PRIMARY KEY'
+		WHERE c.database_name = current_database() AND c.schema_name = 'main' AND NOT c.internal
+		ORDER BY c.table_name, c.column_index`, nil,
+		func(rows *sql.Rows) error {
+			var table string
+			var col ColumnInfo
+			if err := rows.Scan(&table, &col.Name, &col.Type, &col.Comment, &col.PrimaryKey); err != nil {
+				return err
+			}
+			info := tables.get(table)
+			info.Columns = append(info.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	// The parallel unnests pair each column with the one it references
+	err = scanEach(ctx, db, `SELECT table_name, constraint_index,
+			unnest(constraint_column_names), referenced_table, unnest(referenced_column_names)
+		FROM duckdb_constraints()
+		WHERE database_name = current_database() AND schema_name = 'main' AND constraint_type = 'FOREIGN KEY'
+		ORDER BY table_name, constraint_index`, nil,
+		func(rows *sql.Rows) error {
+			var table, id, column, refTable, refColumn string
+			if err := rows.Scan(&table, &id, &column, &refTable, &refColumn); err != nil {
+				return err
+			}
+			tables.addForeignKeyColumn(table, id, column, refTable, refColumn)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	return tables.list(), nil
+}
+
+// duckdbViewPrefix matches the CREATE VIEW statement DuckDB stores up to
+// the view's SELECT.
+var duckdbViewPrefix = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:OR\s+REPLACE\s+)?(?:TEMP\s+|TEMPORARY\s+)?VIEW\s+.*?\bAS\s+`)
+
+// ViewDefinitions reads the CREATE VIEW statements of duckdb_views(),
+// keeping only each view's SELECT.
+func (a *DuckDBAdapter) ViewDefinitions(ctx context.Context, db *sql.DB, databaseName string) (map[string]string, error) {
+	views := map[string]string{}
+	err := scanEach(ctx, db, `SELECT view_name, sql FROM duckdb_views()
+		WHERE database_name = current_database() AND schema_name = 'main' AND NOT internal`, nil,
+		func(rows *sql.Rows) error {
+			var name, definition string
+			if err := rows.Scan(&name, &definition); err != nil {
+				return err
+			}
+			definition = duckdbViewPrefix.ReplaceAllString(definition, "")
+			views[name] = strings.TrimSuffix(strings.TrimSpace(definition), ";")
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view definitions: %w", err)
+	}
+	return views, nil
+}
+
+// duckdbPlanNode is an operator of DuckDB's JSON query plan.
+type duckdbPlanNode struct {
+	Name      string           `json:"name"`
+	Children  []duckdbPlanNode `json:"children"`
+	ExtraInfo json.RawMessage  `json:"extra_info"`
+}
+
+// ExplainAccess parses EXPLAIN (FORMAT JSON). Sequential scans read the
+// whole table; index scans name the ART index they look up.
+func (a *DuckDBAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	var accesses []PlanAccess
+	var walk func(nodes []duckdbPlanNode)
+	walk = func(nodes []duckdbPlanNode) {
+		for _, node := range nodes {
+			var extra struct {
+				Table string `json:"Table"`
+				Index string `json:"Index"`
+			}
+			// Older versions give extra_info as text, with no table to report
+			_ = json.Unmarshal(node.ExtraInfo, &extra)
+			switch name := strings.TrimSpace(node.Name); {
+			case extra.Table == "":
+			case name == "SEQ_SCAN":
+				accesses = append(accesses, PlanAccess{Table: extra.Table, FullScan: true})
+			case name == "INDEX_SCAN":
+				accesses = append(accesses, PlanAccess{Table: extra.Table, Index: extra.Index})
+			}
+			walk(node.Children)
+		}
+	}
+
+	err := scanEach(ctx, db, "EXPLAIN (FORMAT JSON) "+query, nil, func(rows *sql.Rows) error {
+		var key, plan string
+		if err := rows.Scan(&key, &plan); err != nil {
+			return err
+		}
+		var nodes []duckdbPlanNode
+		if err := json.Unmarshal([]byte(plan), &nodes); err != nil {
+			return fmt.Errorf("unexpected plan format: %w", err)
+		}
+		walk(nodes)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return accesses, nil
+}
+
+// ExplainPlan parses EXPLAIN (FORMAT JSON). Newer versions give extra_info
+// as an object with the table, index and estimated cardinality; older ones
+// as text, kept as the detail.
+func (a *DuckDBAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	var convert func(nodes []duckdbPlanNode) []PlanNode
+	convert = func(nodes []duckdbPlanNode) []PlanNode {
+		var result []PlanNode
+		for _, node := range nodes {
+			step := PlanNode{Operation: strings.TrimSpace(node.Name), Children: convert(node.Children)}
+			var extra map[string]any
+			var text string
+			if json.Unmarshal(node.ExtraInfo, &extra) == nil {
+				step.Table, _ = extra["Table"].(string)
+				step.Index, _ = extra["Index"].(string)
+				if rows, ok := extra["Estimated Cardinality"].(string); ok {
+					if n, err := strconv.ParseFloat(strings.TrimPrefix(rows, "~"), 64); err == nil {
+						step.EstimatedRows = floatPtr(n)
+					}
+				}
+				if filters, ok := extra["Filters"].(string); ok {
+					step.Detail = filters
+				}
+			} else if json.Unmarshal(node.ExtraInfo, &text) == nil {
+				step.Detail = strings.TrimSpace(text)
+			}
+			switch {
+			case step.Table == "":
+			case step.Operation == "SEQ_SCAN":
+				step.Access = PlanFullScan
+			case step.Operation == "INDEX_SCAN":
+				step.Access = PlanIndexLookup
+			}
+			result = append(result, step)
+		}
+		return result
+	}
+
+	var plan []PlanNode
+	err := scanEach(ctx, db, "EXPLAIN (FORMAT JSON) "+query, nil, func(rows *sql.Rows) error {
+		var key, planJSON string
+		if err := rows.Scan(&key, &planJSON); err != nil {
+			return err
+		}
+		var nodes []duckdbPlanNode
+		if err := json.Unmarshal([]byte(planJSON), &nodes); err != nil {
+			return fmt.Errorf("unexpected plan format: %w", err)
+		}
+		plan = append(plan, convert(nodes)...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return plan, nil
+}
+
+// EstimateCost is unsupported: DuckDB's plans estimate cardinalities, not
+// costs.
+func (a *DuckDBAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	return 0, ErrCostUnsupported
+}
+
+// duckdbIndexColumn matches an index key expression that is a plain column.
+var duckdbIndexColumn = regexp.MustCompile(`^"?(\w+)"?$`)
+
+// ListIndexes reads duckdb_indexes() and the primary key and unique
+// constraints, which DuckDB enforces with indexes it does not list there.
+func (a *DuckDBAdapter) ListIndexes(ctx context.Context, db *sql.DB, databaseName string) ([]IndexInfo, error) {
+	var indexes []IndexInfo
+	err := scanEach(ctx, db, `SELECT table_name, index_name, is_unique, key FROM (
+			SELECT table_name, index_name, is_unique,
+				unnest(CAST(expressions AS VARCHAR[])) AS key,
+				unnest(generate_subscripts(CAST(expressions AS VARCHAR[]), 1)) AS pos
+			FROM duckdb_indexes()
+			WHERE database_name = current_database() AND schema_name = 'main'
+			UNION ALL
+			SELECT table_name, constraint_name, true,
+				unnest(constraint_column_names), unnest(generate_subscripts(constraint_column_names, 1))
+			FROM duckdb_constraints()
+			WHERE database_name = current_database() AND schema_name = 'main'
+				AND constraint_type IN ('PRIMARY KEY', 'UNIQUE')
+		)
+		ORDER BY table_name, index_name, pos`, nil,
+		func(rows *sql.Rows) error {
+			var table, name, key string
+			var unique bool
+			if err := rows.Scan(&table, &name, &unique, &key); err != nil {
+				return err
+			}
+			column := ""
+			if m := duckdbIndexColumn.FindStringSubmatch(strings.TrimSpace(key)); m != nil {
+				column = m[1]
+			}
+			indexes = appendIndexColumn(indexes, table, name, unique, column)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// ListSequences reads duckdb_sequences(). DuckDB does not record which
+// column a sequence numbers.
+func (a *DuckDBAdapter) ListSequences(ctx context.Context, db *sql.DB, databaseName string) ([]SequenceInfo, error) {
+	var sequences []SequenceInfo
+	err := scanEach(ctx, db, `SELECT sequence_name, last_value, increment_by, max_value
+		FROM duckdb_sequences()
+		WHERE database_name = current_database() AND schema_name = 'main'
+		ORDER BY sequence_name`, nil,
+		func(rows *sql.Rows) error {
+			var seq SequenceInfo
+			var last sql.NullInt64
+			var increment, maxValue int64
+			if err := rows.Scan(&seq.Name, &last, &increment, &maxValue); err != nil {
+				return err
+			}
+			seq.MaxValue = uint64(max(maxValue, 0))
+			if last.Valid {
+				next := last.Int64 + increment
+				seq.LastValue, seq.NextValue = &last.Int64, &next
+			}
+			sequences = append(sequences, seq)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sequences: %w", err)
+	}
+	return sequences, nil
+}
+
+// DescribeSettings reads duckdb_settings(). DuckDB compares text byte by
+// byte unless a column or default_collation names a collation; column
+// collations are not in the catalog, so none are counted.
+func (a *DuckDBAdapter) DescribeSettings(ctx context.Context, db *sql.DB, databaseName string) (*DatabaseSettings, error) {
+	settings, err := scanSettings(db.QueryRowContext(ctx, `SELECT version(),
+			(SELECT value FROM duckdb_settings() WHERE name = 'default_collation'),
+			(SELECT value FROM duckdb_settings() WHERE name = 'TimeZone'),
+			(SELECT value FROM duckdb_settings() WHERE name = 'search_path')`),
+		"version", "default_collation", "timezone", "search_path")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	if settings["default_collation"] == "" {
+		settings["default_collation"] = "BINARY"
+	}
+	return &DatabaseSettings{Settings: settings}, nil
+}
+
+// duckdbVariableNames maps variable names to DuckDB's settings.
+var duckdbVariableNames = map[string]string{
+	"time_zone": "TimeZone",
+}
+
+// ReadVariables reads version() and the named settings of duckdb_settings().
+func (a *DuckDBAdapter) ReadVariables(ctx context.Context, db *sql.DB, names []string) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		var value sql.NullString
+		var err error
+		if name == "version" {
+			err = db.QueryRowContext(ctx, "SELECT version()").Scan(&value)
+		} else {
+			setting := name
+			if mapped, ok := duckdbVariableNames[name]; ok {
+				setting = mapped
+			}
+			err = db.QueryRowContext(ctx, "SELECT value FROM duckdb_settings() WHERE lower(name) = lower(?)", setting).Scan(&value)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if value.Valid {
+			values[name] = value.String
+		}
+	}
+	return values, nil
+}
+
+// TopStatements is unsupported.
+func (a *DuckDBAdapter) TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error) {
+	return nil, ErrStatementStatsUnsupported
+}
+
+// ActiveQueries is unsupported: DuckDB runs in this process and has no
+// server to ask.
+func (a *DuckDBAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	return nil, ErrActivityUnsupported
+}
+
+// AuditPrivileges reports whether this process could write the database
+// file if the read-only flag were bypassed, and whether queries may read
+// files outside MCP_DUCKDB_ALLOWED_DIRS.
+func (a *DuckDBAdapter) AuditPrivileges(ctx context.Context, db *sql.DB, dsn string) ([]string, error) {
+	var findings []string
+	var external sql.NullString
+	err := db.QueryRowContext(ctx, "SELECT value FROM duckdb_settings() WHERE name = 'enable_external_access'").Scan(&external)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if external.String == "true" {
+		findings = append(findings, "queries may read and write any file this process can (enable_external_access is true)")
+	}
+
+	path, _, _ := strings.Cut(dsn, "?")
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsPermission(err) {
+			return findings, nil
+		}
+		return nil, err
+	}
+	f.Close()
+	return append(findings, fmt.Sprintf("database file %s is writable by this process", path)), nil
+}
+
+// IsTransientError reports false: a read-only DuckDB database has no
+// writers to conflict with.
+func (a *DuckDBAdapter) IsTransientError(err error) bool { return false }
+
+func (a *DuckDBAdapter) ImplicitSchemaReference(cleanedSQL string) string { return "" }
+
+func (a *DuckDBAdapter) ValidateQuery(sqlQuery string) error {
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	if err := validateCommon(sqlQuery, cleaned); err != nil {
+		return err
+	}
+	if err := validateTemporalClauses(cleaned, temporalSyntaxNone); err != nil {
+		return err
+	}
+
+	// DuckDB-specific forbidden functions: running SQL text, and reading
+	// the environment or stored secrets
+	forbiddenFunctions := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bquery\s*\(`, "query()"},
+		{`(?i)\bquery_table\s*\(`, "query_table()"},
+		{`(?i)\bgetenv\s*\(`, "getenv()"},
+		{`(?i)\bduckdb_secrets\s*\(`, "duckdb_secrets()"},
+		{`(?i)\bwhich_secret\s*\(`, "which_secret()"},
+	}
+
+	for _, ff := range forbiddenFunctions {
+		re := regexp.MustCompile(ff.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden function: %s", ff.desc)
+		}
+	}
+
+	// DuckDB-specific dangerous keywords: file export and import, extension
+	// installation, and attaching other databases
+	extraKeywords := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)(?:^|[^a-zA-Z_])COPY(?:[^a-zA-Z_]|$)`, "COPY"},
+		{`(?i)(?:^|[^a-zA-Z_])EXPORT(?:[^a-zA-Z_]|$)`, "EXPORT"},
+		{`(?i)(?:^|[^a-zA-Z_])IMPORT(?:[^a-zA-Z_]|$)`, "IMPORT"},
+		{`(?i)(?:^|[^a-zA-Z_])INSTALL(?:[^a-zA-Z_]|$)`, "INSTALL"},
+		{`(?i)(?:^|[^a-zA-Z_])ATTACH(?:[^a-zA-Z_]|$)`, "ATTACH"},
+		{`(?i)(?:^|[^a-zA-Z_])DETACH(?:[^a-zA-Z_]|$)`, "DETACH"},
+		{`(?i)(?:^|[^a-zA-Z_])PRAGMA(?:[^a-zA-Z_]|$)`, "PRAGMA"},
+		{`(?i)(?:^|[^a-zA-Z_])CALL(?:[^a-zA-Z_]|$)`, "CALL"},
+		{`(?i)(?:^|[^a-zA-Z_])CHECKPOINT(?:[^a-zA-Z_]|$)`, "CHECKPOINT"},
+		{`(?i)(?:^|[^a-zA-Z_])VACUUM(?:[^a-zA-Z_]|$)`, "VACUUM"},
+		{`(?i)(?:^|[^a-zA-Z_])PREPARE(?:[^a-zA-Z_]|$)`, "PREPARE"},
+		{`(?i)(?:^|[^a-zA-Z_])EXECUTE(?:[^a-zA-Z_]|$)`, "EXECUTE"},
+	}
+
+	for _, dk := range extraKeywords {
+		re := regexp.MustCompile(dk.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden keyword: %s", dk.desc)
+		}
+	}
+
+	// Block LOAD statements; load is also a common column name
+	loadPattern := regexp.MustCompile(`(?i)^\s*(?:EXPLAIN\s+(?:ANALYZE\s+)?)?(?:FORCE\s+)?LOAD\b`)
+	if loadPattern.MatchString(cleaned) {
+		return fmt.Errorf("query contains forbidden keyword: LOAD")
+	}
+
+	return nil
+}
+
+// NormalizeRowLimit rewrites what it must: DuckDB has no TOP or LIMIT m, n.
+func (a *DuckDBAdapter) NormalizeRowLimit(sqlQuery string) string {
+	return normalizeRowLimit(sqlQuery, a.RemoveStringsAndComments(sqlQuery), rowLimitSyntax{fetch: true})
+}
+
+// RemoveStringsAndComments strips string literals and comments from SQL
+// for safe keyword detection. DuckDB's parser derives from PostgreSQL's and
+// shares its quoting, including $$ dollar-quoted strings.
+func (a *DuckDBAdapter) RemoveStringsAndComments(sql string) string {
+	return (&PostgresAdapter{}).RemoveStringsAndComments(sql)
+}
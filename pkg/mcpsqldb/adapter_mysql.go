@@ -0,0 +1,1117 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// ShowAllowed lists the MySQL SHOW commands queries may run, e.g. "TABLES"
+// or "CREATE VIEW"; when empty every command not in ShowDenied is allowed
+// (overridable via MCP_SHOW_ALLOW env var, comma-separated)
+var ShowAllowed []string
+
+// ShowDenied lists MySQL SHOW commands that are always rejected: by default
+// those exposing other sessions' statements, accounts, replication and the
+// binary log (overridable via MCP_SHOW_DENY env var; set it empty to allow
+// all)
+var ShowDenied = []string{
+	"GRANTS", "PROCESSLIST", "ENGINE", "BINARY LOGS", "MASTER LOGS", "BINLOG EVENTS", "RELAYLOG EVENTS",
+	"BINARY LOG STATUS", "MASTER STATUS", "REPLICA STATUS", "SLAVE STATUS", "REPLICAS", "SLAVE HOSTS",
+}
+
+// showModifiers are SHOW keywords that do not change which command runs,
+// as in SHOW FULL PROCESSLIST or SHOW GLOBAL STATUS.
+var showModifiers = map[string]bool{"FULL": true, "EXTENDED": true, "GLOBAL": true, "SESSION": true}
+
+// parseShowCommands parses a comma-separated list of SHOW commands.
+func parseShowCommands(spec string) []string {
+	var commands []string
+	for _, entry := range strings.Split(spec, ",") {
+		if entry = strings.Join(strings.Fields(strings.ToUpper(entry)), " "); entry != "" {
+			commands = append(commands, entry)
+		}
+	}
+	return commands
+}
+
+// validateShowCommand checks the command of a SHOW statement in cleanedSQL
+// against ShowAllowed and ShowDenied. A listed command matches statements
+// starting with its words, so "CREATE" covers SHOW CREATE TABLE and VIEW.
+func validateShowCommand(cleanedSQL string) error {
+	words := strings.Fields(strings.ToUpper(cleanedSQL))
+	if len(words) == 0 || words[0] != "SHOW" {
+		return nil
+	}
+	var command []string
+	for _, word := range words[1:] {
+		if !showModifiers[word] {
+			command = append(command, strings.TrimSuffix(word, ";"))
+		}
+	}
+	matches := func(list []string) bool {
+		for _, entry := range list {
+			entryWords := strings.Fields(entry)
+			if len(entryWords) <= len(command) && strings.Join(command[:len(entryWords)], " ") == entry {
+				return true
+			}
+		}
+		return false
+	}
+	name := "SHOW"
+	if len(command) > 0 {
+		name += " " + command[0]
+	}
+	if matches(ShowDenied) || (len(ShowAllowed) > 0 && !matches(ShowAllowed)) {
+		return fmt.Errorf("%s is not allowed", name)
+	}
+	return nil
+}
+
+// MySQLAdapter implements DBAdapter for MySQL databases.
+type MySQLAdapter struct{}
+
+func (a *MySQLAdapter) DriverName() string { return "mysql" }
+func (a *MySQLAdapter) ServerName() string { return "mysql-readonly-mcp-server" }
+func (a *MySQLAdapter) URIScheme() string  { return "mysql" }
+
+func (a *MySQLAdapter) Remote() bool { return true }
+
+func (a *MySQLAdapter) BuildDSN() (string, error) {
+	host := os.Getenv("MCP_MYSQL_HOST")
+	port := os.Getenv("MCP_MYSQL_PORT")
+	db := os.Getenv("MCP_MYSQL_DB")
+	user := os.Getenv("MCP_MYSQL_USER")
+	password := os.Getenv("MCP_MYSQL_PASSWORD")
+	registerSecret(password)
+
+	var missing []string
+	// The Cloud SQL connector dials the instance itself
+	if host == "" && CloudSQLInstance == "" {
+		missing = append(missing, "MCP_MYSQL_HOST")
+	}
+	if port == "" && CloudSQLInstance == "" {
+		missing = append(missing, "MCP_MYSQL_PORT")
+	}
+	if db == "" {
+		missing = append(missing, "MCP_MYSQL_DB")
+	}
+	if user == "" {
+		missing = append(missing, "MCP_MYSQL_USER")
+	}
+	// IAM and Azure AD authentication need no password
+	if password == "" && !CloudSQLIAMAuth && !AzureADAuth {
+		missing = append(missing, "MCP_MYSQL_PASSWORD")
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing required environment variables: %v", missing)
+	}
+
+	return a.FormatDSN(ConnParams{Host: host, Port: port, Database: db, User: user, Password: password})
+}
+
+func (a *MySQLAdapter) ResolveDSN(dsn string) (string, error) { return dsn, nil }
+
+func (a *MySQLAdapter) FormatDSN(p ConnParams) (string, error) {
+	if p.Host == "" {
+		p.Host = "localhost"
+	}
+	if p.Port == "" {
+		p.Port = "3306"
+	}
+	return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s", p.User, p.Password, p.Host, p.Port, p.Database), nil
+}
+
+func (a *MySQLAdapter) ConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DialFunc = dial
+	return mysql.NewConnector(cfg)
+}
+
+// WithAccessToken sends the token with the cleartext password plugin, as
+// Azure Database for MySQL requires, so TLS is enabled unless the DSN already
+// configures it.
+func (a *MySQLAdapter) WithAccessToken(dsn, token string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	cfg.Passwd = token
+	cfg.AllowCleartextPasswords = true
+	if cfg.TLSConfig == "" || cfg.TLSConfig == "false" {
+		cfg.TLSConfig = "true"
+	}
+	return cfg.FormatDSN(), nil
+}
+
+// WithConnectionLabel sets the program_name connection attribute, shown in
+// performance_schema.session_connect_attrs.
+func (a *MySQLAdapter) WithConnectionLabel(dsn, label string) (string, error) {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+	for _, attr := range strings.Split(cfg.ConnectionAttributes, ",") {
+		if strings.HasPrefix(strings.TrimSpace(attr), "program_name:") {
+			return dsn, nil
+		}
+	}
+	attrs := "program_name:" + label
+	if cfg.ConnectionAttributes != "" {
+		attrs = cfg.ConnectionAttributes + "," + attrs
+	}
+	cfg.ConnectionAttributes = attrs
+	return cfg.FormatDSN(), nil
+}
+
+// TLSVerified is false for tls=false, preferred and skip-verify, which all
+// accept an unverified or unencrypted connection.
+func (a *MySQLAdapter) TLSVerified(dsn string) bool {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return false
+	}
+	return cfg.TLS != nil && !cfg.TLS.InsecureSkipVerify
+}
+
+func (a *MySQLAdapter) DatabaseName(dsn string) string {
+	// DSN format: user:password@tcp(host:port)/dbname?params
+	parts := strings.Split(dsn, "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	dbPart := parts[len(parts)-1]
+	if idx := strings.Index(dbPart, "?"); idx != -1 {
+		dbPart = dbPart[:idx]
+	}
+	return dbPart
+}
+
+func (a *MySQLAdapter) ReadOnlyStatements() []string {
+	return []string{"SET SESSION TRANSACTION READ ONLY"}
+}
+
+func (a *MySQLAdapter) ReadOnlyCheckQuery() string {
+	return "SELECT @@SESSION.transaction_read_only"
+}
+
+// WriteProbe creates a regular table: a read-only transaction may still
+// write temporary tables, and DDL commits implicitly, so it is dropped.
+func (a *MySQLAdapter) WriteProbe(name string) (string, string) {
+	return "CREATE TABLE " + a.QuoteIdentifier(name) + " (id INT)", "DROP TABLE IF EXISTS " + a.QuoteIdentifier(name)
+}
+
+// SnapshotTxOptions uses REPEATABLE READ, under which InnoDB takes a
+// consistent snapshot at the transaction's first read.
+func (a *MySQLAdapter) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+// SearchPathStatement is unsupported: USE would outlive the transaction and
+// change the database of the pooled connection for every later statement.
+func (a *MySQLAdapter) SearchPathStatement(schema string) (string, error) {
+	return "", ErrSearchPathUnsupported
+}
+
+func (a *MySQLAdapter) ListTablesQuery(databaseName string) (string, []any) {
+	return `SELECT table_name FROM information_schema.tables WHERE table_schema = ?`,
+		[]any{databaseName}
+}
+
+func (a *MySQLAdapter) ReadSchemaQuery(databaseName, tableName string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable, column_key, column_default, extra,
+			character_set_name, collation_name
+		FROM information_schema.columns
+		WHERE table_schema = ? AND table_name = ?
+		ORDER BY ordinal_position`, []any{databaseName, tableName}
+}
+
+func (a *MySQLAdapter) ScanSchemaRow(rows *sql.Rows) (map[string]any, error) {
+	var colName, dataType, isNullable, colKey string
+	var colDefault, extra, charset, collation sql.NullString
+
+	if err := rows.Scan(&colName, &dataType, &isNullable, &colKey, &colDefault, &extra, &charset, &collation); err != nil {
+		return nil, err
+	}
+
+	col := map[string]any{
+		"column_name": colName,
+		"data_type":   dataType,
+		"is_nullable": isNullable,
+		"column_key":  colKey,
+	}
+	if colDefault.Valid {
+		col["column_default"] = colDefault.String
+	}
+	if extra.Valid && extra.String != "" {
+		col["extra"] = extra.String
+	}
+	// Only text columns have a character set and collation
+	if charset.Valid {
+		col["character_set"] = charset.String
+	}
+	if collation.Valid {
+		col["collation"] = collation.String
+	}
+	return col, nil
+}
+
+func (a *MySQLAdapter) DecodeValue(databaseType string, val any) any {
+	return val
+}
+
+func (a *MySQLAdapter) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// QuoteString writes strings containing backslashes as hex literals, which
+// read the same whether or not NO_BACKSLASH_ESCAPES is set.
+func (a *MySQLAdapter) QuoteString(value string) string {
+	if strings.Contains(value, `\`) {
+		return fmt.Sprintf("CONVERT(X'%x' USING utf8mb4)", value)
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// ValuesQuery chains SELECTs with UNION ALL, as VALUES ROW(...) needs MySQL
+// 8.0.19 and names its columns column_0, column_1, ...
+func (a *MySQLAdapter) ValuesQuery(columns []string, rows [][]string) string {
+	selects := make([]string, len(rows))
+	for i, row := range rows {
+		values := append([]string{}, row...)
+		if i == 0 {
+			for j, col := range columns {
+				values[j] += " AS " + a.QuoteIdentifier(col)
+			}
+		}
+		selects[i] = "SELECT " + strings.Join(values, ", ")
+	}
+	return strings.Join(selects, " UNION ALL ")
+}
+
+func (a *MySQLAdapter) FoldIdentifier(name string) string {
+	return name
+}
+
+// IdentifierCase reads lower_case_table_names: table names map to files, so
+// whether their case matters depends on the server's file system unless the
+// server lowercases them (1) or compares them lowercased (2). Column names
+// never depend on case.
+func (a *MySQLAdapter) IdentifierCase(ctx context.Context, db *sql.DB) (*IdentifierCase, error) {
+	var lowerCaseTableNames int
+	if err := db.QueryRowContext(ctx, "SELECT @@lower_case_table_names").Scan(&lowerCaseTableNames); err != nil {
+		return nil, fmt.Errorf("failed to read lower_case_table_names: %w", err)
+	}
+	ic := &IdentifierCase{UnquotedFolding: "none", TablesCaseSensitive: lowerCaseTableNames == 0}
+	if ic.TablesCaseSensitive {
+		ic.Note = "Table names must match the case they were created with, quoted or not; column names are case-insensitive"
+	} else {
+		ic.Note = "Table and column names are case-insensitive, quoted or not"
+	}
+	return ic, nil
+}
+
+// mysqlTimeBuckets formats a timestamp as the start of each unit.
+var mysqlTimeBuckets = map[string]string{
+	"minute": "DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:00')",
+	"hour":   "DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00')",
+	"day":    "DATE(%s)",
+	"week":   "DATE_SUB(DATE(%[1]s), INTERVAL WEEKDAY(%[1]s) DAY)",
+	"month":  "DATE_FORMAT(%s, '%%Y-%%m-01')",
+	"year":   "DATE_FORMAT(%s, '%%Y-01-01')",
+}
+
+// TimeBucket formats the bucket start, as MySQL has no date_trunc.
+func (a *MySQLAdapter) TimeBucket(column, unit string) (string, error) {
+	format, ok := mysqlTimeBuckets[unit]
+	if !ok {
+		return "", fmt.Errorf("unknown time bucket %q", unit)
+	}
+	return fmt.Sprintf(format, column), nil
+}
+
+// EpochSeconds counts seconds from the epoch with TIMESTAMPDIFF, as
+// UNIX_TIMESTAMP applies the session time zone and returns 0 before 1970.
+func (a *MySQLAdapter) EpochSeconds(column string) string {
+	return fmt.Sprintf("TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', %s)", column)
+}
+
+// JSONExtract uses ->>, which unquotes the extracted value.
+func (a *MySQLAdapter) JSONExtract(column string, keys []string) string {
+	return column + "->>" + a.QuoteString(jsonPath(keys))
+}
+
+// SampleQuery falls back to ORDER BY RAND(), as MySQL has no TABLESAMPLE.
+// For large tables a RAND() pre-filter keeps the sort small, though the
+// table is still scanned.
+func (a *MySQLAdapter) SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error) {
+	var estimate sql.NullInt64
+	err := db.QueryRowContext(ctx, `SELECT table_rows FROM information_schema.tables
+		WHERE table_schema = ? AND table_name = ?`, databaseName, table).Scan(&estimate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to estimate table size: %w", err)
+	}
+
+	query := "SELECT * FROM " + a.QuoteIdentifier(table)
+	if fraction := sampleFraction(limit, estimate.Int64); estimate.Valid && fraction > 0 {
+		query += fmt.Sprintf(" WHERE RAND() < %.6f", fraction)
+	}
+	return query + fmt.Sprintf(" ORDER BY RAND() LIMIT %d", limit), nil
+}
+
+// AsOfSampleQuery reads MariaDB system-versioned tables FOR SYSTEM_TIME AS
+// OF the time, converted to the session time zone the row periods are
+// compared in. MySQL keeps no row history.
+func (a *MySQLAdapter) AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error) {
+	var version string
+	if err := db.QueryRowContext(ctx, "SELECT @@version").Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to read the server version: %w", err)
+	}
+	if !strings.Contains(strings.ToLower(version), "mariadb") {
+		return "", ErrAsOfUnsupported
+	}
+	return fmt.Sprintf("SELECT * FROM %s FOR SYSTEM_TIME AS OF TIMESTAMP CONVERT_TZ(%s, '+00:00', @@session.time_zone) ORDER BY RAND() LIMIT %d",
+		a.QuoteIdentifier(table), a.QuoteString(at.Format("2006-01-02 15:04:05.999999")), limit), nil
+}
+
+func (a *MySQLAdapter) DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error) {
+	tables := newTableSet()
+
+	err := scanEach(ctx, db, `SELECT table_name, COALESCE(table_rows, -1),
+			CASE WHEN table_type = 'VIEW' THEN '' ELSE table_comment END
+		FROM information_schema.tables WHERE table_schema = ?`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var name, comment string
+			var count int64
+			if err := rows.Scan(&name, &count, &comment); err != nil {
+				return err
+			}
+			table := tables.get(name)
+			table.Rows, table.Comment = count, comment
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT table_name, column_name, column_type, column_key, column_comment
+		FROM information_schema.columns WHERE table_schema = ?
+		ORDER BY table_name, ordinal_position`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table string
+			var col ColumnInfo
+			var key string
+			if err := rows.Scan(&table, &col.Name, &col.Type, &key, &col.Comment); err != nil {
+				return err
+			}
+			col.PrimaryKey = key == "PRI"
+			info := tables.get(table)
+			info.Columns = append(info.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT table_name, constraint_name, column_name, referenced_table_name, referenced_column_name
+		FROM information_schema.key_column_usage
+		WHERE table_schema = ? AND referenced_table_name IS NOT NULL
+		ORDER BY table_name, constraint_name, ordinal_position`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table, constraint, column, refTable, refColumn string
+			if err := rows.Scan(&table, &constraint, &column, &refTable, &refColumn); err != nil {
+				return err
+			}
+			tables.addForeignKeyColumn(table, constraint, column, refTable, refColumn)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	return tables.list(), nil
+}
+
+// ViewDefinitions reads information_schema.VIEWS, which shows a view's
+// definition only to accounts with SHOW VIEW on it (or its definer).
+func (a *MySQLAdapter) ViewDefinitions(ctx context.Context, db *sql.DB, databaseName string) (map[string]string, error) {
+	views := map[string]string{}
+	err := scanEach(ctx, db, `SELECT TABLE_NAME, VIEW_DEFINITION FROM information_schema.VIEWS WHERE TABLE_SCHEMA = ?`,
+		[]any{databaseName},
+		func(rows *sql.Rows) error {
+			var name, definition string
+			if err := rows.Scan(&name, &definition); err != nil {
+				return err
+			}
+			views[name] = definition
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view definitions: %w", err)
+	}
+	return views, nil
+}
+
+func (a *MySQLAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query).Scan(&planJSON); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parseMySQLPlan(planJSON)
+}
+
+func (a *MySQLAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query).Scan(&planJSON); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parseMySQLPlanNodes(planJSON)
+}
+
+// EstimateCost reads query_cost from the query block of EXPLAIN FORMAT=JSON.
+func (a *MySQLAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+query, args...).Scan(&planJSON); err != nil {
+		return 0, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parseMySQLCost(planJSON)
+}
+
+// parseMySQLCost extracts the query cost of an EXPLAIN FORMAT=JSON plan,
+// which MySQL reports as a string.
+func parseMySQLCost(planJSON []byte) (float64, error) {
+	var plan struct {
+		QueryBlock struct {
+			CostInfo struct {
+				QueryCost string `json:"query_cost"`
+			} `json:"cost_info"`
+		} `json:"query_block"`
+	}
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	cost, err := strconv.ParseFloat(plan.QueryBlock.CostInfo.QueryCost, 64)
+	if err != nil {
+		return 0, fmt.Errorf("plan has no query cost: %w", err)
+	}
+	return cost, nil
+}
+
+// parseMySQLPlan extracts the table accesses of an EXPLAIN FORMAT=JSON plan,
+// where each is a "table" object nested somewhere under the query block.
+// Access type ALL reads the whole table and "index" the whole index.
+func parseMySQLPlan(planJSON []byte) ([]PlanAccess, error) {
+	var plan any
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	var accesses []PlanAccess
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]any:
+			if table, ok := v["table"].(map[string]any); ok {
+				name, _ := table["table_name"].(string)
+				accessType, _ := table["access_type"].(string)
+				key, _ := table["key"].(string)
+				// <derivedN> and <unionN> are temporary tables
+				if name != "" && !strings.HasPrefix(name, "<") {
+					accesses = append(accesses, PlanAccess{Table: name, Index: key, FullScan: accessType == "ALL" || accessType == "index"})
+				}
+			}
+			// Walk keys in a fixed order so results are deterministic
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				walk(v[key])
+			}
+		}
+	}
+	walk(plan)
+	return accesses, nil
+}
+
+// parseMySQLPlanNodes converts an EXPLAIN FORMAT=JSON plan into PlanNodes.
+// MySQL nests operations by name (query_block, ordering_operation,
+// nested_loop, table, ...), so every object or list of objects in the plan
+// becomes a step named by its key, and tables take their access type.
+func parseMySQLPlanNodes(planJSON []byte) ([]PlanNode, error) {
+	var plan map[string]any
+	if err := json.Unmarshal(planJSON, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	return mysqlPlanChildren(plan), nil
+}
+
+// mysqlPlanChildren converts the steps nested in a plan object, in a fixed
+// order so results are deterministic.
+func mysqlPlanChildren(obj map[string]any) []PlanNode {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var nodes []PlanNode
+	for _, key := range keys {
+		switch v := obj[key].(type) {
+		case map[string]any:
+			if key != "cost_info" {
+				nodes = append(nodes, mysqlPlanNode(key, v))
+			}
+		case []any:
+			// Lists of names, such as used_columns, are not steps
+			node := PlanNode{Operation: key}
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					node.Children = append(node.Children, mysqlPlanChildren(m)...)
+				}
+			}
+			if node.Children != nil {
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	return nodes
+}
+
+// mysqlPlanNode converts the plan object of the step named operation. The
+// flags set on it, such as using_filesort, are its detail.
+func mysqlPlanNode(operation string, obj map[string]any) PlanNode {
+	node := PlanNode{Operation: operation, Children: mysqlPlanChildren(obj)}
+	costInfo, _ := obj["cost_info"].(map[string]any)
+	node.EstimatedCost = mysqlPlanNumber(costInfo["query_cost"])
+	if node.EstimatedCost == nil {
+		node.EstimatedCost = mysqlPlanNumber(costInfo["prefix_cost"])
+	}
+
+	var details []string
+	if cond, ok := obj["attached_condition"].(string); ok {
+		details = append(details, cond)
+	}
+	var flags []string
+	for key, v := range obj {
+		if set, ok := v.(bool); ok && set {
+			flags = append(flags, key)
+		}
+	}
+	sort.Strings(flags)
+	details = append(details, flags...)
+	node.Detail = strings.Join(details, "; ")
+
+	if operation == "table" {
+		if accessType, ok := obj["access_type"].(string); ok {
+			node.Operation = accessType
+		}
+		node.Table, _ = obj["table_name"].(string)
+		node.Index, _ = obj["key"].(string)
+		node.EstimatedRows = mysqlPlanNumber(obj["rows_examined_per_scan"])
+		switch {
+		case node.Operation == "ALL":
+			node.Access = PlanFullScan
+		case node.Operation == "index":
+			node.Access = PlanFullIndexScan
+		case node.Index != "":
+			node.Access = PlanIndexLookup
+		}
+	}
+	return node
+}
+
+// mysqlPlanNumber reads a plan number, which MySQL gives as a string for
+// costs, returning nil when v is neither.
+func mysqlPlanNumber(v any) *float64 {
+	switch v := v.(type) {
+	case float64:
+		return floatPtr(v)
+	case string:
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return floatPtr(f)
+		}
+	}
+	return nil
+}
+
+func (a *MySQLAdapter) ListIndexes(ctx context.Context, db *sql.DB, databaseName string) ([]IndexInfo, error) {
+	var indexes []IndexInfo
+	err := scanEach(ctx, db, `SELECT table_name, index_name, non_unique = 0, COALESCE(column_name, '')
+		FROM information_schema.statistics WHERE table_schema = ?
+		ORDER BY table_name, index_name, seq_in_index`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table, name, column string
+			var unique bool
+			if err := rows.Scan(&table, &name, &unique, &column); err != nil {
+				return err
+			}
+			indexes = appendIndexColumn(indexes, table, name, unique, column)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// ListSequences reports each table's AUTO_INCREMENT counter. MySQL 8 caches
+// the value information_schema shows for information_schema_stats_expiry
+// seconds (a day by default), so it may lag behind recent inserts.
+func (a *MySQLAdapter) ListSequences(ctx context.Context, db *sql.DB, databaseName string) ([]SequenceInfo, error) {
+	var sequences []SequenceInfo
+	err := scanEach(ctx, db, `SELECT t.table_name, c.column_name, c.data_type,
+			c.column_type LIKE '%unsigned%', t.auto_increment
+		FROM information_schema.tables t
+		JOIN information_schema.columns c
+			ON c.table_schema = t.table_schema AND c.table_name = t.table_name
+			AND c.extra LIKE '%auto_increment%'
+		WHERE t.table_schema = ?
+		ORDER BY t.table_name`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table, column, dataType string
+			var unsigned bool
+			var next sql.NullInt64
+			if err := rows.Scan(&table, &column, &dataType, &unsigned, &next); err != nil {
+				return err
+			}
+			seq := SequenceInfo{Name: table, Table: table, Column: column, MaxValue: integerMaxValue(dataType, unsigned)}
+			if next.Valid {
+				seq.NextValue = &next.Int64
+			}
+			sequences = append(sequences, seq)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read auto-increment counters: %w", err)
+	}
+	return sequences, nil
+}
+
+func (a *MySQLAdapter) DescribeSettings(ctx context.Context, db *sql.DB, databaseName string) (*DatabaseSettings, error) {
+	settings, err := scanSettings(db.QueryRowContext(ctx, `SELECT @@version, DATABASE(),
+			@@character_set_server, @@collation_server,
+			@@character_set_database, @@collation_database,
+			@@character_set_connection, @@collation_connection, @@character_set_results,
+			@@time_zone, @@system_time_zone, @@lower_case_table_names`),
+		"version", "database",
+		"character_set_server", "collation_server",
+		"character_set_database", "collation_database",
+		"character_set_connection", "collation_connection", "character_set_results",
+		"time_zone", "system_time_zone", "lower_case_table_names")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	result := &DatabaseSettings{Settings: settings}
+	err = scanEach(ctx, db, `SELECT table_name, collation_name, COUNT(*)
+		FROM information_schema.columns
+		WHERE table_schema = ? AND collation_name IS NOT NULL
+		GROUP BY table_name, collation_name`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var table, collation string
+			var n int
+			if err := rows.Scan(&table, &collation, &n); err != nil {
+				return err
+			}
+			result.addCollation(table, collation, n)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column collations: %w", err)
+	}
+	return result, nil
+}
+
+// mysqlStatementOrder maps statement orders to digest summary columns.
+var mysqlStatementOrder = map[string]string{
+	StatementOrderTotalLatency: "SUM_TIMER_WAIT",
+	StatementOrderAvgLatency:   "AVG_TIMER_WAIT",
+	StatementOrderCalls:        "COUNT_STAR",
+}
+
+// ReadVariables reads each variable as @@name, the session value where the
+// variable has one and the global value otherwise.
+func (a *MySQLAdapter) ReadVariables(ctx context.Context, db *sql.DB, names []string) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		if !variableNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid variable name %q", name)
+		}
+		var value sql.NullString
+		err := db.QueryRowContext(ctx, "SELECT @@"+name).Scan(&value)
+		var myErr *mysql.MySQLError
+		if errors.As(err, &myErr) && myErr.Number == 1193 { // ER_UNKNOWN_SYSTEM_VARIABLE
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		values[name] = value.String
+	}
+	return values, nil
+}
+
+// TopStatements reads performance_schema's digest summary, which needs the
+// SELECT privilege on performance_schema and the statements_digest consumer
+// enabled (the default). Timers are in picoseconds.
+func (a *MySQLAdapter) TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error) {
+	column, ok := mysqlStatementOrder[orderBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown order %q", orderBy)
+	}
+	var stats []StatementStats
+	err := scanEach(ctx, db, `SELECT COALESCE(SCHEMA_NAME, ''), DIGEST_TEXT, COUNT_STAR,
+			SUM_TIMER_WAIT / 1e9, AVG_TIMER_WAIT / 1e9, MAX_TIMER_WAIT / 1e9,
+			SUM_ROWS_EXAMINED, SUM_ROWS_SENT, SUM_NO_INDEX_USED, CAST(LAST_SEEN AS CHAR)
+		FROM performance_schema.events_statements_summary_by_digest
+		WHERE DIGEST_TEXT IS NOT NULL
+		ORDER BY `+column+` DESC
+		LIMIT ?`, []any{limit},
+		func(rows *sql.Rows) error {
+			var st StatementStats
+			if err := rows.Scan(&st.Schema, &st.Digest, &st.Calls, &st.TotalLatencyMs, &st.AvgLatencyMs, &st.MaxLatencyMs,
+				&st.RowsExamined, &st.RowsSent, &st.NoIndexUsed, &st.LastSeen); err != nil {
+				return err
+			}
+			stats = append(stats, st)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read statement statistics: %w", err)
+	}
+	return stats, nil
+}
+
+// ActiveQueries reads the process list, which shows other accounts'
+// threads only with the PROCESS privilege.
+func (a *MySQLAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	var queries []ActiveQuery
+	err := scanEach(ctx, db, `SELECT CAST(ID AS CHAR), USER, COALESCE(DB, ''), COALESCE(STATE, COMMAND), TIME,
+			COALESCE(INFO, ''), USER = SUBSTRING_INDEX(CURRENT_USER(), '@', 1)
+		FROM information_schema.PROCESSLIST
+		WHERE COMMAND NOT IN ('Sleep', 'Daemon', 'Binlog Dump') AND ID <> CONNECTION_ID()
+		ORDER BY TIME DESC`, nil,
+		func(rows *sql.Rows) error {
+			var q ActiveQuery
+			if err := rows.Scan(&q.ID, &q.User, &q.Database, &q.State, &q.Seconds, &q.Query, &q.Own); err != nil {
+				return err
+			}
+			queries = append(queries, q)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read process list: %w", err)
+	}
+	return queries, nil
+}
+
+func (a *MySQLAdapter) AuditPrivileges(ctx context.Context, db *sql.DB, dsn string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SHOW GRANTS")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []string
+	for rows.Next() {
+		var grant string
+		if err := rows.Scan(&grant); err != nil {
+			return nil, err
+		}
+		grants = append(grants, grant)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return mysqlWritePrivileges(grants), nil
+}
+
+// mysqlReadOnlyPrivileges are privileges that cannot modify data or schema.
+var mysqlReadOnlyPrivileges = map[string]bool{
+	"SELECT":             true,
+	"USAGE":              true,
+	"SHOW VIEW":          true,
+	"SHOW DATABASES":     true,
+	"PROCESS":            true,
+	"REPLICATION CLIENT": true,
+}
+
+// mysqlWritePrivileges extracts non-read-only privileges from SHOW GRANTS
+// output, e.g. "GRANT SELECT, INSERT ON `db`.* TO `u`@`%`" yields
+// "INSERT ON `db`.*".
+func mysqlWritePrivileges(grants []string) []string {
+	var findings []string
+	for _, grant := range grants {
+		upper := strings.ToUpper(grant)
+		if !strings.HasPrefix(upper, "GRANT ") {
+			continue
+		}
+		onIdx := strings.Index(upper, " ON ")
+		if onIdx == -1 {
+			continue // role grant, e.g. GRANT `role`@`%` TO ...
+		}
+		toIdx := strings.Index(upper, " TO ")
+		if toIdx == -1 || toIdx < onIdx {
+			toIdx = len(grant)
+		}
+		target := strings.TrimSpace(grant[onIdx+4 : toIdx])
+
+		for _, priv := range splitPrivileges(upper[len("GRANT "):onIdx]) {
+			if !mysqlReadOnlyPrivileges[priv] {
+				findings = append(findings, fmt.Sprintf("%s ON %s", priv, target))
+			}
+		}
+		if strings.Contains(upper, "WITH GRANT OPTION") {
+			findings = append(findings, fmt.Sprintf("GRANT OPTION ON %s", target))
+		}
+	}
+	return findings
+}
+
+// splitPrivileges splits a comma-separated privilege list, dropping
+// column lists such as "UPDATE (col1, col2)".
+func splitPrivileges(list string) []string {
+	var privs []string
+	depth := 0
+	var current strings.Builder
+	for _, r := range list {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			privs = append(privs, strings.TrimSpace(current.String()))
+			current.Reset()
+		case depth == 0:
+			current.WriteRune(r)
+		}
+	}
+	if p := strings.TrimSpace(current.String()); p != "" {
+		privs = append(privs, p)
+	}
+	return privs
+}
+
+func (a *MySQLAdapter) IsTransientError(err error) bool {
+	var myErr *mysql.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	switch myErr.Number {
+	case 1205, // ER_LOCK_WAIT_TIMEOUT
+		1213: // ER_LOCK_DEADLOCK
+		return true
+	}
+	return false
+}
+
+func (a *MySQLAdapter) ImplicitSchemaReference(cleanedSQL string) string { return "" }
+
+func (a *MySQLAdapter) ValidateQuery(sqlQuery string) error {
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	if err := validateCommon(sqlQuery, cleaned); err != nil {
+		return err
+	}
+	if err := validateShowCommand(cleaned); err != nil {
+		return err
+	}
+	if err := validateTemporalClauses(cleaned, temporalSyntaxSystemTime); err != nil {
+		return err
+	}
+
+	// MySQL-specific forbidden patterns
+	forbiddenPatterns := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bINTO\s+OUTFILE\b`, "INTO OUTFILE"},
+		{`(?i)\bINTO\s+DUMPFILE\b`, "INTO DUMPFILE"},
+		{`(?i)\bLOAD_FILE\s*\(`, "LOAD_FILE()"},
+		{`(?i)\bINTO\s+@`, "INTO @variable"},
+	}
+
+	for _, fp := range forbiddenPatterns {
+		re := regexp.MustCompile(fp.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden pattern: %s", fp.desc)
+		}
+	}
+
+	// MySQL-specific DoS functions
+	dosFunctions := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bSLEEP\s*\(`, "SLEEP()"},
+		{`(?i)\bBENCHMARK\s*\(`, "BENCHMARK()"},
+		{`(?i)\bGET_LOCK\s*\(`, "GET_LOCK()"},
+		{`(?i)\bRELEASE_LOCK\s*\(`, "RELEASE_LOCK()"},
+		{`(?i)\bIS_FREE_LOCK\s*\(`, "IS_FREE_LOCK()"},
+		{`(?i)\bIS_USED_LOCK\s*\(`, "IS_USED_LOCK()"},
+		{`(?i)\bWAIT_FOR_EXECUTED_GTID_SET\s*\(`, "WAIT_FOR_EXECUTED_GTID_SET()"},
+		{`(?i)\bWAIT_UNTIL_SQL_THREAD_AFTER_GTIDS\s*\(`, "WAIT_UNTIL_SQL_THREAD_AFTER_GTIDS()"},
+		{`(?i)\bMASTER_POS_WAIT\s*\(`, "MASTER_POS_WAIT()"},
+		{`(?i)\bSOURCE_POS_WAIT\s*\(`, "SOURCE_POS_WAIT()"},
+	}
+
+	for _, df := range dosFunctions {
+		re := regexp.MustCompile(df.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden function: %s", df.desc)
+		}
+	}
+
+	// MySQL-specific dangerous keywords (beyond common set)
+	extraKeywords := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)(?:^|[^a-zA-Z_])CALL(?:[^a-zA-Z_]|$)`, "CALL"},
+		{`(?i)(?:^|[^a-zA-Z_])EXEC(?:[^a-zA-Z_]|$)`, "EXEC"},
+		{`(?i)(?:^|[^a-zA-Z_])EXECUTE(?:[^a-zA-Z_]|$)`, "EXECUTE"},
+		{`(?i)(?:^|[^a-zA-Z_])REPLACE(?:[^a-zA-Z_]|$)`, "REPLACE"},
+		{`(?i)(?:^|[^a-zA-Z_])LOAD(?:[^a-zA-Z_]|$)`, "LOAD"},
+		{`(?i)(?:^|[^a-zA-Z_])HANDLER(?:[^a-zA-Z_]|$)`, "HANDLER"},
+		{`(?i)(?:^|[^a-zA-Z_])RENAME(?:[^a-zA-Z_]|$)`, "RENAME"},
+	}
+
+	for _, dk := range extraKeywords {
+		re := regexp.MustCompile(dk.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden keyword: %s", dk.desc)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeRowLimit rewrites what it must: MySQL has no FETCH FIRST or TOP.
+func (a *MySQLAdapter) NormalizeRowLimit(sqlQuery string) string {
+	return normalizeRowLimit(sqlQuery, a.RemoveStringsAndComments(sqlQuery), rowLimitSyntax{limitComma: true})
+}
+
+// RemoveStringsAndComments strips string literals and comments from SQL
+// for safe keyword detection. MySQL-specific: supports # comments, backtick
+// identifiers, and backslash escaping in strings.
+func (a *MySQLAdapter) RemoveStringsAndComments(sql string) string {
+	var result strings.Builder
+	i := 0
+	n := len(sql)
+
+	for i < n {
+		// Single-line comment starting with --
+		if i+1 < n && sql[i] == '-' && sql[i+1] == '-' {
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Single-line comment starting with # (MySQL-specific)
+		if sql[i] == '#' {
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Multi-line comment /* */
+		if i+1 < n && sql[i] == '/' && sql[i+1] == '*' {
+			i += 2
+			for i+1 < n && !(sql[i] == '*' && sql[i+1] == '/') {
+				i++
+			}
+			i += 2 // Skip */
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Single-quoted string
+		if sql[i] == '\'' {
+			i++
+			for i < n {
+				if sql[i] == '\'' {
+					if i+1 < n && sql[i+1] == '\'' {
+						i += 2 // Escaped quote
+						continue
+					}
+					i++
+					break
+				}
+				if sql[i] == '\\' && i+1 < n {
+					i += 2 // Escaped character (MySQL-specific)
+					continue
+				}
+				i++
+			}
+			result.WriteString("''") // Placeholder for string
+			continue
+		}
+
+		// Double-quoted string (identifier in MySQL with ANSI_QUOTES, or string)
+		if sql[i] == '"' {
+			i++
+			for i < n {
+				if sql[i] == '"' {
+					if i+1 < n && sql[i+1] == '"' {
+						i += 2 // Escaped quote
+						continue
+					}
+					i++
+					break
+				}
+				if sql[i] == '\\' && i+1 < n {
+					i += 2 // Escaped character (MySQL-specific)
+					continue
+				}
+				i++
+			}
+			result.WriteString(`""`) // Placeholder for string
+			continue
+		}
+
+		// Backtick-quoted identifier (MySQL-specific)
+		if sql[i] == '`' {
+			result.WriteByte('`')
+			i++
+			for i < n && sql[i] != '`' {
+				result.WriteByte(sql[i])
+				i++
+			}
+			if i < n {
+				result.WriteByte('`')
+				i++
+			}
+			continue
+		}
+
+		result.WriteByte(sql[i])
+		i++
+	}
+
+	return result.String()
+}
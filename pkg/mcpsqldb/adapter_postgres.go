@@ -0,0 +1,1265 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"github.com/lib/pq"
+)
+
+// Postgres drivers accepted by MCP_PG_DRIVER
+const (
+	PostgresDriverPQ  = "pq"
+	PostgresDriverPGX = "pgx"
+)
+
+// PostgresDriver is the driver new Postgres adapters connect with: lib/pq,
+// or pgx in database/sql mode (overridable via MCP_PG_DRIVER env var)
+var PostgresDriver = PostgresDriverPQ
+
+// PostgresAdapter implements DBAdapter for PostgreSQL databases.
+type PostgresAdapter struct {
+	// Driver is PostgresDriverPQ (also when empty) or PostgresDriverPGX
+	Driver string
+}
+
+func (a *PostgresAdapter) DriverName() string {
+	if a.Driver == PostgresDriverPGX {
+		return "pgx"
+	}
+	return "postgres"
+}
+func (a *PostgresAdapter) ServerName() string { return "postgres-readonly-mcp-server" }
+func (a *PostgresAdapter) URIScheme() string  { return "postgres" }
+
+func (a *PostgresAdapter) Remote() bool { return true }
+
+func (a *PostgresAdapter) BuildDSN() (string, error) {
+	host := os.Getenv("MCP_PG_HOST")
+	port := os.Getenv("MCP_PG_PORT")
+	db := os.Getenv("MCP_PG_DB")
+	user := os.Getenv("MCP_PG_USER")
+	password := os.Getenv("MCP_PG_PASSWORD")
+	registerSecret(password)
+
+	var missing []string
+	// The Cloud SQL connector dials the instance itself
+	if host == "" && CloudSQLInstance == "" {
+		missing = append(missing, "MCP_PG_HOST")
+	}
+	if port == "" && CloudSQLInstance == "" {
+		missing = append(missing, "MCP_PG_PORT")
+	}
+	if db == "" {
+		missing = append(missing, "MCP_PG_DB")
+	}
+	if user == "" {
+		missing = append(missing, "MCP_PG_USER")
+	}
+	// IAM, Kerberos and Azure AD authentication need no password
+	if password == "" && !CloudSQLIAMAuth && !PostgresGSSAPI && !AzureADAuth {
+		missing = append(missing, "MCP_PG_PASSWORD")
+	}
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing required environment variables: %v", missing)
+	}
+
+	return a.FormatDSN(ConnParams{Host: host, Port: port, Database: db, User: user, Password: password})
+}
+
+func (a *PostgresAdapter) ResolveDSN(dsn string) (string, error) { return dsn, nil }
+
+func (a *PostgresAdapter) FormatDSN(p ConnParams) (string, error) {
+	if p.Host == "" {
+		p.Host = "localhost"
+	}
+	if p.Port == "" {
+		p.Port = "5432"
+	}
+	sslmode := os.Getenv("MCP_PG_SSLMODE")
+	if sslmode == "" {
+		sslmode = string(pq.SSLModeRequire)
+	}
+	dsn := fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=%s",
+		url.PathEscape(p.User), url.PathEscape(p.Password), p.Host, p.Port, p.Database, sslmode)
+	if srv := os.Getenv("MCP_PG_KRBSRVNAME"); srv != "" {
+		dsn += "&krbsrvname=" + url.QueryEscape(srv)
+	}
+	return dsn, nil
+}
+
+func (a *PostgresAdapter) ConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error) {
+	if a.Driver == PostgresDriverPGX {
+		return pgxConnectorWithDialer(dsn, dial)
+	}
+	cfg, err := pq.NewConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if CloudSQLInstance != "" {
+		// The Cloud SQL dialer returns an already encrypted connection
+		cfg.SSLMode = pq.SSLModeDisable
+	}
+	connector, err := pq.NewConnectorConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	connector.Dialer(pqDialer(dial))
+	return connector, nil
+}
+
+// pqDialer adapts a DialFunc to the pq.Dialer and pq.DialerContext interfaces.
+type pqDialer DialFunc
+
+func (d pqDialer) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d pqDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d(ctx, network, address)
+}
+
+func (d pqDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+func pgxConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error) {
+	cfg, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DialFunc = pgconn.DialFunc(dial)
+	// Leave name resolution to the dialer, e.g. the far end of an SSH tunnel
+	cfg.LookupFunc = func(ctx context.Context, host string) ([]string, error) { return []string{host}, nil }
+	if CloudSQLInstance != "" {
+		// The Cloud SQL dialer returns an already encrypted connection
+		cfg.TLSConfig = nil
+		cfg.Fallbacks = nil
+	}
+	return stdlib.GetConnector(*cfg), nil
+}
+
+func (a *PostgresAdapter) WithAccessToken(dsn, token string) (string, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+		return "", fmt.Errorf("access token authentication requires a postgres:// URL DSN")
+	}
+	u.User = url.UserPassword(u.User.Username(), token)
+	return u.String(), nil
+}
+
+// applicationNamePattern matches an application_name keyword/value setting.
+var applicationNamePattern = regexp.MustCompile(`(?:^|\s)application_name\s*=`)
+
+// WithConnectionLabel sets application_name, shown in pg_stat_activity and
+// the server log, in URL and keyword/value DSNs alike. PGAPPNAME takes
+// precedence like an application_name in the DSN.
+func (a *PostgresAdapter) WithConnectionLabel(dsn, label string) (string, error) {
+	if os.Getenv("PGAPPNAME") != "" {
+		return dsn, nil
+	}
+	if u, err := url.Parse(dsn); err == nil && (u.Scheme == "postgres" || u.Scheme == "postgresql") {
+		query := u.Query()
+		if query.Has("application_name") {
+			return dsn, nil
+		}
+		query.Set("application_name", label)
+		u.RawQuery = query.Encode()
+		return u.String(), nil
+	}
+	if applicationNamePattern.MatchString(dsn) {
+		return dsn, nil
+	}
+	quoted := strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(label)
+	return strings.TrimSpace(dsn + " application_name='" + quoted + "'"), nil
+}
+
+// TLSVerified accepts only verify-ca and verify-full; require encrypts but
+// trusts any certificate.
+func (a *PostgresAdapter) TLSVerified(dsn string) bool {
+	cfg, err := pq.NewConfig(dsn)
+	if err != nil {
+		return false
+	}
+	return cfg.SSLMode == pq.SSLModeVerifyCA || cfg.SSLMode == pq.SSLModeVerifyFull
+}
+
+func (a *PostgresAdapter) DatabaseName(dsn string) string {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(u.Path, "/")
+}
+
+func (a *PostgresAdapter) ReadOnlyStatements() []string {
+	return []string{"SET SESSION CHARACTERISTICS AS TRANSACTION READ ONLY"}
+}
+
+func (a *PostgresAdapter) ReadOnlyCheckQuery() string {
+	return "SHOW transaction_read_only"
+}
+
+// WriteProbe creates a temporary table, which read-only transactions refuse
+// like any other DDL, and which rolls back with the transaction.
+func (a *PostgresAdapter) WriteProbe(name string) (string, string) {
+	return "CREATE TEMP TABLE " + a.QuoteIdentifier(name) + " (id int)", ""
+}
+
+// SnapshotTxOptions uses REPEATABLE READ, which in PostgreSQL reads a single
+// snapshot taken at the transaction's first statement.
+func (a *PostgresAdapter) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+// SearchPathStatement sets search_path for the rest of the transaction
+// only, so it never outlives the query on a pooled connection.
+func (a *PostgresAdapter) SearchPathStatement(schema string) (string, error) {
+	return "SET LOCAL search_path TO " + a.QuoteIdentifier(schema), nil
+}
+
+func (a *PostgresAdapter) ListTablesQuery(databaseName string) (string, []any) {
+	return `SELECT table_name FROM information_schema.tables WHERE table_schema = 'public' AND table_catalog = $1`,
+		[]any{databaseName}
+}
+
+func (a *PostgresAdapter) ReadSchemaQuery(databaseName, tableName string) (string, []any) {
+	return `SELECT column_name, data_type, is_nullable, column_default, collation_name
+		FROM information_schema.columns
+		WHERE table_catalog = $1 AND table_schema = 'public' AND table_name = $2
+		ORDER BY ordinal_position`, []any{databaseName, tableName}
+}
+
+func (a *PostgresAdapter) ScanSchemaRow(rows *sql.Rows) (map[string]any, error) {
+	var colName, dataType, isNullable string
+	var colDefault, collation sql.NullString
+
+	if err := rows.Scan(&colName, &dataType, &isNullable, &colDefault, &collation); err != nil {
+		return nil, err
+	}
+
+	col := map[string]any{
+		"column_name": colName,
+		"data_type":   dataType,
+		"is_nullable": isNullable,
+	}
+	if colDefault.Valid {
+		col["column_default"] = postgresDefaultLiteral(colDefault.String)
+	}
+	// Set only when the column overrides the database's default collation
+	if collation.Valid {
+		col["collation"] = collation.String
+	}
+	return col, nil
+}
+
+// postgresTypeOIDs names the special types DecodeValue handles by OID, as
+// pgx reports types it has no codec for by number.
+var postgresTypeOIDs = map[string]string{
+	"650": "CIDR", "774": "MACADDR8", "790": "MONEY", "829": "MACADDR",
+	"869": "INET", "1186": "INTERVAL", "1700": "NUMERIC", "2950": "UUID",
+}
+
+// DecodeValue returns numeric and money values as JSON numbers, keeping
+// their exact digits, and uuid, inet, cidr, macaddr and interval values as
+// their text form whichever driver scanned them. NaN and infinite numerics
+// stay strings, as JSON has no numbers for them.
+func (a *PostgresAdapter) DecodeValue(databaseType string, val any) any {
+	if name, ok := postgresTypeOIDs[databaseType]; ok {
+		databaseType = name
+	}
+	switch databaseType {
+	case "NUMERIC":
+		if text, ok := val.(string); ok && postgresNumber.MatchString(text) {
+			return json.Number(text)
+		}
+	case "MONEY":
+		if text, ok := val.(string); ok {
+			if n, ok := parsePostgresMoney(text); ok {
+				return n
+			}
+		}
+	case "UUID", "INET", "CIDR", "MACADDR", "MACADDR8", "INTERVAL":
+		switch v := val.(type) {
+		case [16]byte:
+			return fmt.Sprintf("%x-%x-%x-%x-%x", v[0:4], v[4:6], v[6:8], v[8:10], v[10:])
+		case fmt.Stringer:
+			return v.String()
+		}
+	}
+	return val
+}
+
+// postgresNumber matches a finite numeric's text form.
+var postgresNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?(e[-+]?[0-9]+)?$`)
+
+// parsePostgresMoney converts money text formatted for lc_monetary, e.g.
+// "$1,234.56", "-$5.00", "($5.00)" or "1.234,56 €", to a number. The last
+// separator is taken as the decimal point unless exactly three digits follow
+// it and it is the only kind of separator, as in "¥1,234".
+func parsePostgresMoney(text string) (json.Number, bool) {
+	negative := strings.Contains(text, "-") || strings.HasPrefix(strings.TrimSpace(text), "(")
+	var digits strings.Builder
+	lastSepAt, seps := -1, map[rune]bool{}
+	for _, r := range text {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '.' || r == ',':
+			lastSepAt = digits.Len()
+			seps[r] = true
+		}
+	}
+	number := digits.String()
+	if number == "" {
+		return "", false
+	}
+	if lastSepAt >= 0 {
+		fraction := len(number) - lastSepAt
+		if fraction != 3 || len(seps) > 1 {
+			number = number[:lastSepAt] + "." + number[lastSepAt:]
+		}
+	}
+	if negative {
+		number = "-" + number
+	}
+	return json.Number(number), true
+}
+
+// postgresDefaultCast matches a column default that is a literal cast to one
+// of the types DecodeValue cleans up, e.g. '00000000-...'::uuid.
+var postgresDefaultCast = regexp.MustCompile(`^'((?:[^']|'')*)'::(uuid|inet|cidr|macaddr8?|interval|money|numeric)$`)
+
+// postgresDefaultLiteral unwraps a column default cast by
+// postgresDefaultCast to the bare value; other defaults are unchanged.
+func postgresDefaultLiteral(def string) string {
+	if m := postgresDefaultCast.FindStringSubmatch(def); m != nil {
+		return strings.ReplaceAll(m[1], "''", "'")
+	}
+	return def
+}
+
+func (a *PostgresAdapter) QuoteIdentifier(name string) string {
+	return pq.QuoteIdentifier(name)
+}
+
+// QuoteString writes strings containing backslashes as E'...' literals, which
+// read the same whatever standard_conforming_strings is set to.
+func (a *PostgresAdapter) QuoteString(value string) string {
+	return pq.QuoteLiteral(value)
+}
+
+func (a *PostgresAdapter) ValuesQuery(columns []string, rows [][]string) string {
+	return valuesQuery(a, columns, rows)
+}
+
+func (a *PostgresAdapter) FoldIdentifier(name string) string {
+	return strings.ToLower(name)
+}
+
+// IdentifierCase reports PostgreSQL's fixed rules: unquoted names are
+// lowercased, and quoted names match exactly.
+func (a *PostgresAdapter) IdentifierCase(ctx context.Context, db *sql.DB) (*IdentifierCase, error) {
+	return &IdentifierCase{
+		UnquotedFolding:      "lower",
+		TablesCaseSensitive:  true,
+		ColumnsCaseSensitive: true,
+		Note:                 `Unquoted names are lowercased; double-quote names containing capitals, e.g. "UserId"`,
+	}, nil
+}
+
+// TimeBucket uses date_trunc, whose weeks start on Monday.
+func (a *PostgresAdapter) TimeBucket(column, unit string) (string, error) {
+	if !isTimeBucketUnit(unit) {
+		return "", fmt.Errorf("unknown time bucket %q", unit)
+	}
+	return fmt.Sprintf("date_trunc('%s', %s)", unit, column), nil
+}
+
+// EpochSeconds uses EXTRACT, which reads timestamps without a time zone
+// as UTC.
+func (a *PostgresAdapter) EpochSeconds(column string) string {
+	return fmt.Sprintf("EXTRACT(EPOCH FROM %s)", column)
+}
+
+// JSONExtract chains -> down to the last key, read as text with ->>.
+func (a *PostgresAdapter) JSONExtract(column string, keys []string) string {
+	expr := column
+	for i, key := range keys {
+		op := "->"
+		if i == len(keys)-1 {
+			op = "->>"
+		}
+		expr += op + a.QuoteString(key)
+	}
+	return expr
+}
+
+// SampleQuery reads a random subset of a large table's pages with
+// TABLESAMPLE SYSTEM, shuffling only those rows; small tables, or ones never
+// analyzed, are shuffled in full.
+func (a *PostgresAdapter) SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error) {
+	var estimate int64
+	err := db.QueryRowContext(ctx, `SELECT c.reltuples::bigint FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relname = $1`, table).Scan(&estimate)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("failed to estimate table size: %w", err)
+	}
+
+	query := "SELECT * FROM " + a.QuoteIdentifier(table)
+	if fraction := sampleFraction(limit, estimate); fraction > 0 {
+		query += fmt.Sprintf(" TABLESAMPLE SYSTEM (%.6f)", fraction*100)
+	}
+	return query + fmt.Sprintf(" ORDER BY random() LIMIT %d", limit), nil
+}
+
+// AsOfSampleQuery is unsupported: PostgreSQL keeps no row history.
+func (a *PostgresAdapter) AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error) {
+	return "", ErrAsOfUnsupported
+}
+
+// DescribeSchema reads pg_catalog directly, since information_schema has no
+// comments and cannot pair the columns of multi-column foreign keys.
+func (a *PostgresAdapter) DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error) {
+	tables := newTableSet()
+
+	err := scanEach(ctx, db, `SELECT c.relname, c.reltuples::bigint, COALESCE(obj_description(c.oid, 'pg_class'), '')
+		FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'f')`, nil,
+		func(rows *sql.Rows) error {
+			var name, comment string
+			var count int64
+			if err := rows.Scan(&name, &count, &comment); err != nil {
+				return err
+			}
+			table := tables.get(name)
+			table.Rows, table.Comment = count, comment
+			if count < 0 {
+				// Never analyzed (PostgreSQL 14+)
+				table.Rows = -1
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT c.relname, a.attname, format_type(a.atttypid, a.atttypmod),
+			COALESCE(col_description(c.oid, a.attnum), '')
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+			AND a.attnum > 0 AND NOT a.attisdropped
+		ORDER BY c.relname, a.attnum`, nil,
+		func(rows *sql.Rows) error {
+			var table string
+			var col ColumnInfo
+			if err := rows.Scan(&table, &col.Name, &col.Type, &col.Comment); err != nil {
+				return err
+			}
+			info := tables.get(table)
+			info.Columns = append(info.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT cl.relname, con.conname, con.contype, a.attname,
+			COALESCE(rcl.relname, ''), COALESCE(ra.attname, '')
+		FROM pg_constraint con
+		JOIN pg_class cl ON cl.oid = con.conrelid
+		JOIN pg_namespace n ON n.oid = cl.relnamespace
+		CROSS JOIN LATERAL unnest(con.conkey, con.confkey) WITH ORDINALITY AS k(attnum, refattnum, ord)
+		JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+		LEFT JOIN pg_class rcl ON rcl.oid = con.confrelid
+		LEFT JOIN pg_attribute ra ON ra.attrelid = con.confrelid AND ra.attnum = k.refattnum
+		WHERE n.nspname = 'public' AND con.contype IN ('p', 'f')
+		ORDER BY cl.relname, con.conname, k.ord`, nil,
+		func(rows *sql.Rows) error {
+			var table, constraint, kind, column, refTable, refColumn string
+			if err := rows.Scan(&table, &constraint, &kind, &column, &refTable, &refColumn); err != nil {
+				return err
+			}
+			if kind == "f" {
+				tables.addForeignKeyColumn(table, constraint, column, refTable, refColumn)
+				return nil
+			}
+			info := tables.get(table)
+			for i := range info.Columns {
+				if info.Columns[i].Name == column {
+					info.Columns[i].PrimaryKey = true
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read constraints: %w", err)
+	}
+	return tables.list(), nil
+}
+
+// ViewDefinitions reads the definitions of views and materialized views,
+// as PostgreSQL reconstructs them from the parsed query.
+func (a *PostgresAdapter) ViewDefinitions(ctx context.Context, db *sql.DB, databaseName string) (map[string]string, error) {
+	views := map[string]string{}
+	err := scanEach(ctx, db, `SELECT viewname, definition FROM pg_views WHERE schemaname = 'public'
+		UNION ALL
+		SELECT matviewname, definition FROM pg_matviews WHERE schemaname = 'public'`, nil,
+		func(rows *sql.Rows) error {
+			var name string
+			var definition sql.NullString
+			if err := rows.Scan(&name, &definition); err != nil {
+				return err
+			}
+			views[name] = definition.String
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view definitions: %w", err)
+	}
+	return views, nil
+}
+
+// postgresPlanNode is the part of an EXPLAIN (FORMAT JSON) plan node that
+// ExplainAccess and ExplainPlan read.
+type postgresPlanNode struct {
+	NodeType     string             `json:"Node Type"`
+	RelationName string             `json:"Relation Name"`
+	IndexName    string             `json:"Index Name"`
+	IndexCond    string             `json:"Index Cond"`
+	Filter       string             `json:"Filter"`
+	HashCond     string             `json:"Hash Cond"`
+	MergeCond    string             `json:"Merge Cond"`
+	JoinFilter   string             `json:"Join Filter"`
+	PlanRows     *float64           `json:"Plan Rows"`
+	TotalCost    *float64           `json:"Total Cost"`
+	Plans        []postgresPlanNode `json:"Plans"`
+}
+
+func (a *PostgresAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query).Scan(&planJSON); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parsePostgresPlan(planJSON)
+}
+
+func (a *PostgresAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query).Scan(&planJSON); err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parsePostgresPlanNodes(planJSON)
+}
+
+// EstimateCost reads the Total Cost of the top plan node of EXPLAIN
+// (FORMAT JSON).
+func (a *PostgresAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	var planJSON []byte
+	if err := db.QueryRowContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...).Scan(&planJSON); err != nil {
+		return 0, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return parsePostgresCost(planJSON)
+}
+
+// parsePostgresCost extracts the total cost of an EXPLAIN (FORMAT JSON) plan.
+func parsePostgresCost(planJSON []byte) (float64, error) {
+	var plans []struct {
+		Plan struct {
+			TotalCost *float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal(planJSON, &plans); err != nil {
+		return 0, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(plans) == 0 || plans[0].Plan.TotalCost == nil {
+		return 0, fmt.Errorf("plan has no total cost")
+	}
+	return *plans[0].Plan.TotalCost, nil
+}
+
+// parsePostgresPlan extracts the table accesses of an EXPLAIN (FORMAT JSON)
+// plan. A Bitmap Index Scan is attributed to the table of the Bitmap Heap
+// Scan above it.
+func parsePostgresPlan(planJSON []byte) ([]PlanAccess, error) {
+	var plans []struct {
+		Plan postgresPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(planJSON, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	var accesses []PlanAccess
+	var walk func(node postgresPlanNode, relation string)
+	walk = func(node postgresPlanNode, relation string) {
+		if node.RelationName != "" {
+			relation = node.RelationName
+		}
+		switch node.NodeType {
+		case "Seq Scan":
+			accesses = append(accesses, PlanAccess{Table: relation, FullScan: true})
+		case "Index Scan", "Index Only Scan", "Bitmap Index Scan":
+			accesses = append(accesses, PlanAccess{Table: relation, Index: node.IndexName, FullScan: node.IndexCond == ""})
+		}
+		for _, child := range node.Plans {
+			walk(child, relation)
+		}
+	}
+	for _, plan := range plans {
+		walk(plan.Plan, "")
+	}
+	return accesses, nil
+}
+
+// parsePostgresPlanNodes converts an EXPLAIN (FORMAT JSON) plan into
+// PlanNodes, keeping the conditions of each node as its detail.
+func parsePostgresPlanNodes(planJSON []byte) ([]PlanNode, error) {
+	var plans []struct {
+		Plan postgresPlanNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(planJSON, &plans); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+
+	var convert func(node postgresPlanNode) PlanNode
+	convert = func(node postgresPlanNode) PlanNode {
+		result := PlanNode{
+			Operation:     node.NodeType,
+			Table:         node.RelationName,
+			Index:         node.IndexName,
+			EstimatedRows: node.PlanRows,
+			EstimatedCost: node.TotalCost,
+		}
+		switch node.NodeType {
+		case "Seq Scan":
+			result.Access = PlanFullScan
+		case "Index Scan", "Index Only Scan", "Bitmap Index Scan":
+			result.Access = PlanIndexLookup
+			if node.IndexCond == "" {
+				result.Access = PlanFullIndexScan
+			}
+		}
+		var conditions []string
+		for _, cond := range []struct{ name, value string }{
+			{"Index Cond", node.IndexCond},
+			{"Hash Cond", node.HashCond},
+			{"Merge Cond", node.MergeCond},
+			{"Join Filter", node.JoinFilter},
+			{"Filter", node.Filter},
+		} {
+			if cond.value != "" {
+				conditions = append(conditions, cond.name+": "+cond.value)
+			}
+		}
+		result.Detail = strings.Join(conditions, "; ")
+		for _, child := range node.Plans {
+			result.Children = append(result.Children, convert(child))
+		}
+		return result
+	}
+
+	nodes := make([]PlanNode, 0, len(plans))
+	for _, plan := range plans {
+		nodes = append(nodes, convert(plan.Plan))
+	}
+	return nodes, nil
+}
+
+func (a *PostgresAdapter) ListIndexes(ctx context.Context, db *sql.DB, databaseName string) ([]IndexInfo, error) {
+	var indexes []IndexInfo
+	err := scanEach(ctx, db, `SELECT t.relname, i.relname, ix.indisunique, COALESCE(a.attname, '')
+		FROM pg_index ix
+		JOIN pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_class t ON t.oid = ix.indrelid
+		JOIN pg_namespace n ON n.oid = t.relnamespace
+		CROSS JOIN LATERAL unnest(ix.indkey::int2[]) WITH ORDINALITY AS k(attnum, ord)
+		LEFT JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = k.attnum
+		WHERE n.nspname = 'public' AND k.ord <= ix.indnkeyatts
+		ORDER BY t.relname, i.relname, k.ord`, nil,
+		func(rows *sql.Rows) error {
+			var table, name, column string
+			var unique bool
+			if err := rows.Scan(&table, &name, &unique, &column); err != nil {
+				return err
+			}
+			indexes = appendIndexColumn(indexes, table, name, unique, column)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// ListSequences reports the sequences of the public schema, with the column
+// of the table owning each (serial and identity columns). last_value is NULL
+// for sequences never used or that the account may not read.
+func (a *PostgresAdapter) ListSequences(ctx context.Context, db *sql.DB, databaseName string) ([]SequenceInfo, error) {
+	var sequences []SequenceInfo
+	err := scanEach(ctx, db, `SELECT s.sequencename, COALESCE(t.relname, ''), COALESCE(att.attname, ''),
+			s.last_value, s.increment_by, s.max_value
+		FROM pg_sequences s
+		JOIN pg_namespace n ON n.nspname = s.schemaname
+		JOIN pg_class c ON c.relnamespace = n.oid AND c.relname = s.sequencename
+		LEFT JOIN pg_depend d ON d.classid = 'pg_class'::regclass AND d.objid = c.oid
+			AND d.refclassid = 'pg_class'::regclass AND d.deptype IN ('a', 'i')
+		LEFT JOIN pg_class t ON t.oid = d.refobjid
+		LEFT JOIN pg_attribute att ON att.attrelid = d.refobjid AND att.attnum = d.refobjsubid
+		WHERE s.schemaname = 'public'
+		ORDER BY s.sequencename`, nil,
+		func(rows *sql.Rows) error {
+			var seq SequenceInfo
+			var last sql.NullInt64
+			var increment, maxValue int64
+			if err := rows.Scan(&seq.Name, &seq.Table, &seq.Column, &last, &increment, &maxValue); err != nil {
+				return err
+			}
+			seq.MaxValue = uint64(max(maxValue, 0))
+			if last.Valid {
+				next := last.Int64 + increment
+				seq.LastValue, seq.NextValue = &last.Int64, &next
+			}
+			sequences = append(sequences, seq)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sequences: %w", err)
+	}
+	return sequences, nil
+}
+
+// DescribeSettings reports the database's encoding and locale from
+// pg_database, as the lc_collate setting was removed in PostgreSQL 16.
+// Columns using the database's collation are counted as "default".
+func (a *PostgresAdapter) DescribeSettings(ctx context.Context, db *sql.DB, databaseName string) (*DatabaseSettings, error) {
+	settings, err := scanSettings(db.QueryRowContext(ctx, `SELECT current_setting('server_version'),
+			current_database(), pg_encoding_to_char(d.encoding), current_setting('client_encoding'),
+			d.datcollate, d.datctype, current_setting('lc_numeric'), current_setting('lc_monetary'),
+			current_setting('lc_time'), current_setting('TimeZone'), current_setting('DateStyle'),
+			current_setting('IntervalStyle'), current_setting('search_path'), current_schema()
+		FROM pg_database d WHERE d.datname = current_database()`),
+		"version", "database", "server_encoding", "client_encoding",
+		"lc_collate", "lc_ctype", "lc_numeric", "lc_monetary",
+		"lc_time", "timezone", "datestyle",
+		"intervalstyle", "search_path", "current_schema")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	result := &DatabaseSettings{Settings: settings}
+	err = scanEach(ctx, db, `SELECT c.relname, co.collname, COUNT(*)
+		FROM pg_attribute a
+		JOIN pg_class c ON c.oid = a.attrelid
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		JOIN pg_collation co ON co.oid = a.attcollation
+		WHERE n.nspname = 'public' AND c.relkind IN ('r', 'p', 'v', 'm', 'f')
+			AND a.attnum > 0 AND NOT a.attisdropped
+		GROUP BY c.relname, co.collname`, nil,
+		func(rows *sql.Rows) error {
+			var table, collation string
+			var n int
+			if err := rows.Scan(&table, &collation, &n); err != nil {
+				return err
+			}
+			result.addCollation(table, collation, n)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column collations: %w", err)
+	}
+	return result, nil
+}
+
+// postgresVariableNames maps the variable names understood by every adapter
+// to PostgreSQL's.
+var postgresVariableNames = map[string]string{
+	"version":   "server_version",
+	"time_zone": "TimeZone",
+}
+
+// ReadVariables reads each variable with current_setting, which returns
+// NULL for settings that do not exist.
+func (a *PostgresAdapter) ReadVariables(ctx context.Context, db *sql.DB, names []string) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		setting := name
+		if mapped, ok := postgresVariableNames[name]; ok {
+			setting = mapped
+		}
+		var value sql.NullString
+		if err := db.QueryRowContext(ctx, "SELECT current_setting($1, true)", setting).Scan(&value); err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if value.Valid {
+			values[name] = value.String
+		}
+	}
+	return values, nil
+}
+
+// TopStatements is unsupported: pg_stat_statements is read by the
+// Postgres-specific top_queries tool instead (see TopQueries).
+func (a *PostgresAdapter) TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error) {
+	return nil, ErrStatementStatsUnsupported
+}
+
+// postgresStatementOrder maps statement orders to pg_stat_statements
+// columns, as named since PostgreSQL 13 and before.
+var postgresStatementOrder = map[string][2]string{
+	StatementOrderTotalLatency: {"total_exec_time", "total_time"},
+	StatementOrderAvgLatency:   {"mean_exec_time", "mean_time"},
+	StatementOrderCalls:        {"calls", "calls"},
+}
+
+// ErrNoPgStatStatements is returned by TopQueries when the
+// pg_stat_statements extension is not installed in the database.
+var ErrNoPgStatStatements = errors.New("the pg_stat_statements extension is not installed; " +
+	"add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements")
+
+// TopQueries returns the limit statements of the current database with the
+// highest orderBy from pg_stat_statements. Without pg_read_all_stats, other
+// users' statements are reported with the text "<insufficient privilege>";
+// allStats reports whether the account holds it.
+func (a *PostgresAdapter) TopQueries(ctx context.Context, db *sql.DB, orderBy string, limit int) (queries []TopQuery, allStats bool, err error) {
+	columns, ok := postgresStatementOrder[orderBy]
+	if !ok {
+		return nil, false, fmt.Errorf("unknown order %q", orderBy)
+	}
+	var installed, execTime bool
+	if err := db.QueryRowContext(ctx, `SELECT to_regclass('pg_stat_statements') IS NOT NULL,
+			EXISTS (SELECT 1 FROM pg_attribute WHERE attrelid = to_regclass('pg_stat_statements') AND attname = 'total_exec_time'),
+			pg_has_role(current_user, 'pg_read_all_stats', 'USAGE')`).Scan(&installed, &execTime, &allStats); err != nil {
+		return nil, false, fmt.Errorf("failed to check pg_stat_statements: %w", err)
+	}
+	if !installed {
+		return nil, false, ErrNoPgStatStatements
+	}
+	column, total, mean := columns[1], "total_time", "mean_time"
+	if execTime {
+		column, total, mean = columns[0], "total_exec_time", "mean_exec_time"
+	}
+
+	err = scanEach(ctx, db, `SELECT COALESCE(query, ''), calls, `+total+`, `+mean+`, rows
+		FROM pg_stat_statements
+		WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+		ORDER BY `+column+` DESC
+		LIMIT $1`, []any{limit},
+		func(rows *sql.Rows) error {
+			var q TopQuery
+			if err := rows.Scan(&q.Query, &q.Calls, &q.TotalTimeMs, &q.MeanTimeMs, &q.Rows); err != nil {
+				return err
+			}
+			queries = append(queries, q)
+			return nil
+		})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	return queries, allStats, nil
+}
+
+// ListExtensions returns the extensions installed in the current database
+// (pg_extension), with the version the server would install now from
+// pg_available_extensions.
+func (a *PostgresAdapter) ListExtensions(ctx context.Context, db *sql.DB) ([]ExtensionInfo, error) {
+	var extensions []ExtensionInfo
+	err := scanEach(ctx, db, `SELECT e.extname, e.extversion, n.nspname,
+			COALESCE(av.comment, ''), COALESCE(av.default_version, '')
+		FROM pg_extension e
+		JOIN pg_namespace n ON n.oid = e.extnamespace
+		LEFT JOIN pg_available_extensions av ON av.name = e.extname
+		ORDER BY e.extname`, nil,
+		func(rows *sql.Rows) error {
+			var ext ExtensionInfo
+			if err := rows.Scan(&ext.Name, &ext.Version, &ext.Schema, &ext.Description, &ext.DefaultVersion); err != nil {
+				return err
+			}
+			if ext.DefaultVersion == ext.Version {
+				ext.DefaultVersion = ""
+			}
+			extensions = append(extensions, ext)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read extensions: %w", err)
+	}
+	return extensions, nil
+}
+
+// TimescaleVersion returns the installed version of the timescaledb
+// extension, or "" when it is not installed.
+func (a *PostgresAdapter) TimescaleVersion(ctx context.Context, db *sql.DB) (string, error) {
+	var version string
+	err := db.QueryRowContext(ctx, `SELECT COALESCE((SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'), '')`).Scan(&version)
+	if err != nil {
+		return "", fmt.Errorf("failed to check for TimescaleDB: %w", err)
+	}
+	return version, nil
+}
+
+// ListHypertables returns the TimescaleDB hypertables of the public schema
+// with their partitioning dimensions, from the timescaledb_information views
+// of TimescaleDB 2. Call it only when TimescaleVersion reports the extension.
+func (a *PostgresAdapter) ListHypertables(ctx context.Context, db *sql.DB) (map[string]*HypertableInfo, error) {
+	hypertables := map[string]*HypertableInfo{}
+	err := scanEach(ctx, db, `SELECT hypertable_name, num_chunks, compression_enabled,
+			approximate_row_count(format('%I.%I', hypertable_schema, hypertable_name)::regclass)
+		FROM timescaledb_information.hypertables
+		WHERE hypertable_schema = 'public'`, nil,
+		func(rows *sql.Rows) error {
+			info := &HypertableInfo{Dimensions: []HypertableDimension{}}
+			if err := rows.Scan(&info.Name, &info.Chunks, &info.CompressionEnabled, &info.ApproximateRows); err != nil {
+				return err
+			}
+			hypertables[info.Name] = info
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hypertables: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT hypertable_name, column_name, dimension_type,
+			COALESCE(time_interval::text, integer_interval::text, ''), COALESCE(num_partitions, 0)
+		FROM timescaledb_information.dimensions
+		WHERE hypertable_schema = 'public'
+		ORDER BY hypertable_name, dimension_number`, nil,
+		func(rows *sql.Rows) error {
+			var table string
+			var dim HypertableDimension
+			if err := rows.Scan(&table, &dim.Column, &dim.Kind, &dim.ChunkInterval, &dim.Partitions); err != nil {
+				return err
+			}
+			dim.Kind = strings.ToLower(dim.Kind)
+			if info, ok := hypertables[table]; ok {
+				info.Dimensions = append(info.Dimensions, dim)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hypertable dimensions: %w", err)
+	}
+	return hypertables, nil
+}
+
+// ActiveQueries reads pg_stat_activity, which shows other users' queries
+// only to members of pg_read_all_stats.
+func (a *PostgresAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	var queries []ActiveQuery
+	err := scanEach(ctx, db, `SELECT pid::text, COALESCE(usename, ''), COALESCE(datname, ''),
+			COALESCE(state, '') || COALESCE(' (' || wait_event_type || ': ' || wait_event || ')', ''),
+			COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0)::float8, COALESCE(query, ''), usename = current_user
+		FROM pg_stat_activity
+		WHERE backend_type = 'client backend' AND state <> 'idle' AND pid <> pg_backend_pid()
+		ORDER BY query_start`, nil,
+		func(rows *sql.Rows) error {
+			var q ActiveQuery
+			var own sql.NullBool
+			if err := rows.Scan(&q.ID, &q.User, &q.Database, &q.State, &q.Seconds, &q.Query, &own); err != nil {
+				return err
+			}
+			q.Own = own.Bool
+			queries = append(queries, q)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_activity: %w", err)
+	}
+	return queries, nil
+}
+
+func (a *PostgresAdapter) AuditPrivileges(ctx context.Context, db *sql.DB, dsn string) ([]string, error) {
+	var findings []string
+
+	var super, createDB, createRole bool
+	err := db.QueryRowContext(ctx,
+		`SELECT rolsuper, rolcreatedb, rolcreaterole FROM pg_roles WHERE rolname = current_user`,
+	).Scan(&super, &createDB, &createRole)
+	if err != nil {
+		return nil, err
+	}
+	if super {
+		findings = append(findings, "SUPERUSER")
+	}
+	if createDB {
+		findings = append(findings, "CREATEDB")
+	}
+	if createRole {
+		findings = append(findings, "CREATEROLE")
+	}
+
+	var dbCreate bool
+	if err := db.QueryRowContext(ctx,
+		`SELECT has_database_privilege(current_database(), 'CREATE')`,
+	).Scan(&dbCreate); err != nil {
+		return nil, err
+	}
+	if dbCreate {
+		findings = append(findings, "CREATE on database")
+	}
+
+	schemaRows, err := db.QueryContext(ctx, `SELECT nspname FROM pg_namespace
+		WHERE has_schema_privilege(oid, 'CREATE')
+		AND nspname NOT IN ('pg_catalog', 'information_schema') AND nspname NOT LIKE 'pg\_%'
+		ORDER BY nspname`)
+	if err != nil {
+		return nil, err
+	}
+	defer schemaRows.Close()
+	for schemaRows.Next() {
+		var schema string
+		if err := schemaRows.Scan(&schema); err != nil {
+			return nil, err
+		}
+		findings = append(findings, fmt.Sprintf("CREATE on schema %s", schema))
+	}
+	if err := schemaRows.Err(); err != nil {
+		return nil, err
+	}
+
+	tableRows, err := db.QueryContext(ctx, `SELECT p.priv, count(*)
+		FROM pg_class c
+		JOIN pg_namespace n ON n.oid = c.relnamespace
+		CROSS JOIN (VALUES ('INSERT'), ('UPDATE'), ('DELETE'), ('TRUNCATE')) AS p(priv)
+		WHERE c.relkind IN ('r', 'p', 'v', 'm', 'f')
+		AND n.nspname NOT IN ('pg_catalog', 'information_schema') AND n.nspname NOT LIKE 'pg\_%'
+		AND has_table_privilege(c.oid, p.priv)
+		GROUP BY p.priv
+		ORDER BY p.priv`)
+	if err != nil {
+		return nil, err
+	}
+	defer tableRows.Close()
+	for tableRows.Next() {
+		var priv string
+		var count int
+		if err := tableRows.Scan(&priv, &count); err != nil {
+			return nil, err
+		}
+		findings = append(findings, fmt.Sprintf("%s on %d table(s)", priv, count))
+	}
+	return findings, tableRows.Err()
+}
+
+func (a *PostgresAdapter) IsTransientError(err error) bool {
+	var code string
+	var pqErr *pq.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pqErr):
+		code = string(pqErr.Code)
+	case errors.As(err, &pgErr):
+		code = pgErr.Code
+	default:
+		return false
+	}
+	switch code {
+	case "40001", // serialization_failure
+		"40P01": // deadlock_detected
+		return true
+	}
+	return false
+}
+
+// ImplicitSchemaReference reports pg_catalog for any pg_* identifier that
+// is not a function call, since catalog relations resolve unqualified.
+func (a *PostgresAdapter) ImplicitSchemaReference(cleanedSQL string) string {
+	for _, loc := range identifierPattern.FindAllStringIndex(cleanedSQL, -1) {
+		ident := strings.ToLower(strings.Trim(cleanedSQL[loc[0]:loc[1]], `"`))
+		rest := strings.TrimSpace(cleanedSQL[loc[1]:])
+		if strings.HasPrefix(ident, "pg_") && !strings.HasPrefix(rest, "(") {
+			return "pg_catalog"
+		}
+	}
+	return ""
+}
+
+func (a *PostgresAdapter) ValidateQuery(sqlQuery string) error {
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	if err := validateCommon(sqlQuery, cleaned); err != nil {
+		return err
+	}
+	if err := validateTemporalClauses(cleaned, temporalSyntaxAsOfSysTime); err != nil {
+		return err
+	}
+
+	// PostgreSQL-specific forbidden patterns
+	forbiddenPatterns := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bCOPY\s+.*\bTO\b`, "COPY ... TO"},
+		{`(?i)\bCOPY\s+.*\bFROM\b`, "COPY ... FROM"},
+		{`(?i)\bpg_read_file\s*\(`, "pg_read_file()"},
+		{`(?i)\bpg_read_binary_file\s*\(`, "pg_read_binary_file()"},
+		{`(?i)\bpg_ls_dir\s*\(`, "pg_ls_dir()"},
+		{`(?i)\blo_import\s*\(`, "lo_import()"},
+		{`(?i)\blo_export\s*\(`, "lo_export()"},
+	}
+
+	for _, fp := range forbiddenPatterns {
+		re := regexp.MustCompile(fp.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden pattern: %s", fp.desc)
+		}
+	}
+
+	// PostgreSQL-specific DoS functions
+	dosFunctions := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bpg_sleep\s*\(`, "pg_sleep()"},
+		{`(?i)\bpg_sleep_for\s*\(`, "pg_sleep_for()"},
+		{`(?i)\bpg_sleep_until\s*\(`, "pg_sleep_until()"},
+		{`(?i)\bpg_advisory_lock\s*\(`, "pg_advisory_lock()"},
+		{`(?i)\bpg_advisory_xact_lock\s*\(`, "pg_advisory_xact_lock()"},
+		{`(?i)\bpg_try_advisory_lock\s*\(`, "pg_try_advisory_lock()"},
+	}
+
+	for _, df := range dosFunctions {
+		re := regexp.MustCompile(df.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden function: %s", df.desc)
+		}
+	}
+
+	// PostgreSQL-specific dangerous keywords
+	extraKeywords := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)(?:^|[^a-zA-Z_])CALL(?:[^a-zA-Z_]|$)`, "CALL"},
+		{`(?i)(?:^|[^a-zA-Z_])EXECUTE(?:[^a-zA-Z_]|$)`, "EXECUTE"},
+		{`(?i)(?:^|[^a-zA-Z_])COPY(?:[^a-zA-Z_]|$)`, "COPY"},
+		{`(?i)(?:^|[^a-zA-Z_])LISTEN(?:[^a-zA-Z_]|$)`, "LISTEN"},
+		{`(?i)(?:^|[^a-zA-Z_])NOTIFY(?:[^a-zA-Z_]|$)`, "NOTIFY"},
+		{`(?i)(?:^|[^a-zA-Z_])PREPARE(?:[^a-zA-Z_]|$)`, "PREPARE"},
+		{`(?i)(?:^|[^a-zA-Z_])DEALLOCATE(?:[^a-zA-Z_]|$)`, "DEALLOCATE"},
+		{`(?i)(?:^|[^a-zA-Z_])VACUUM(?:[^a-zA-Z_]|$)`, "VACUUM"},
+		{`(?i)(?:^|[^a-zA-Z_])REINDEX(?:[^a-zA-Z_]|$)`, "REINDEX"},
+		{`(?i)(?:^|[^a-zA-Z_])CLUSTER(?:[^a-zA-Z_]|$)`, "CLUSTER"},
+	}
+
+	for _, dk := range extraKeywords {
+		re := regexp.MustCompile(dk.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden keyword: %s", dk.desc)
+		}
+	}
+
+	return nil
+}
+
+// NormalizeRowLimit rewrites what it must: PostgreSQL has no TOP or LIMIT m, n.
+func (a *PostgresAdapter) NormalizeRowLimit(sqlQuery string) string {
+	return normalizeRowLimit(sqlQuery, a.RemoveStringsAndComments(sqlQuery), rowLimitSyntax{fetch: true})
+}
+
+// RemoveStringsAndComments strips string literals and comments from SQL
+// for safe keyword detection. PostgreSQL-specific: no # comments, no backtick
+// identifiers, handles $$ dollar-quoted strings, no backslash escaping by default.
+func (a *PostgresAdapter) RemoveStringsAndComments(sql string) string {
+	var result strings.Builder
+	i := 0
+	n := len(sql)
+
+	for i < n {
+		// Single-line comment starting with --
+		if i+1 < n && sql[i] == '-' && sql[i+1] == '-' {
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Multi-line comment /* */
+		if i+1 < n && sql[i] == '/' && sql[i+1] == '*' {
+			i += 2
+			for i+1 < n && !(sql[i] == '*' && sql[i+1] == '/') {
+				i++
+			}
+			i += 2 // Skip */
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Dollar-quoted string $tag$...$tag$ or $$...$$
+		if sql[i] == '$' {
+			tagEnd := strings.Index(sql[i+1:], "$")
+			if tagEnd >= 0 {
+				tag := sql[i : i+tagEnd+2] // e.g., "$$" or "$tag$"
+				closeIdx := strings.Index(sql[i+len(tag):], tag)
+				if closeIdx >= 0 {
+					i += len(tag) + closeIdx + len(tag)
+					result.WriteString("''") // Placeholder for string content
+					continue
+				}
+			}
+		}
+
+		// Single-quoted string (no backslash escaping in standard PostgreSQL)
+		if sql[i] == '\'' {
+			i++
+			for i < n {
+				if sql[i] == '\'' {
+					if i+1 < n && sql[i+1] == '\'' {
+						i += 2 // Escaped quote ''
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			result.WriteString("''") // Placeholder for string
+			continue
+		}
+
+		// Double-quoted identifier (PostgreSQL standard identifier quoting)
+		if sql[i] == '"' {
+			result.WriteByte('"')
+			i++
+			for i < n {
+				if sql[i] == '"' {
+					if i+1 < n && sql[i+1] == '"' {
+						result.WriteString(`""`)
+						i += 2
+						continue
+					}
+					result.WriteByte('"')
+					i++
+					break
+				}
+				result.WriteByte(sql[i])
+				i++
+			}
+			continue
+		}
+
+		result.WriteByte(sql[i])
+		i++
+	}
+
+	return result.String()
+}
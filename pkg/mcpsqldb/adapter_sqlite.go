@@ -0,0 +1,809 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLiteAdapter implements DBAdapter for SQLite databases.
+type SQLiteAdapter struct{}
+
+func (a *SQLiteAdapter) DriverName() string { return "sqlite" }
+func (a *SQLiteAdapter) ServerName() string { return "sqlite-readonly-mcp-server" }
+func (a *SQLiteAdapter) URIScheme() string  { return "sqlite" }
+
+func (a *SQLiteAdapter) Remote() bool { return false }
+
+func (a *SQLiteAdapter) BuildDSN() (string, error) {
+	dbPath := os.Getenv("MCP_SQLITE_PATH")
+	if dbPath == "" {
+		return "", fmt.Errorf("missing required environment variable: MCP_SQLITE_PATH")
+	}
+	return a.FormatDSN(ConnParams{Database: dbPath})
+}
+
+// ResolveDSN confines the database file to MCP_SQLITE_ALLOWED_DIRS, pins it
+// to its resolved path and opens it read-only, or immutable on read-only
+// media.
+func (a *SQLiteAdapter) ResolveDSN(dsn string) (string, error) {
+	dsn, err := sandboxSQLiteDSN(dsn, SQLiteAllowedDirs)
+	if err != nil {
+		return "", err
+	}
+	return protectSQLiteFile(dsn)
+}
+
+// FormatDSN treats Database as the file path; the other parameters do not
+// apply to SQLite.
+func (a *SQLiteAdapter) FormatDSN(p ConnParams) (string, error) {
+	dbPath := p.Database
+	if dbPath == "" {
+		return "", fmt.Errorf("missing SQLite database path")
+	}
+	// Enforce read-only mode via DSN parameter
+	return sqliteReadOnlyURI(dbPath), nil
+}
+
+func (a *SQLiteAdapter) ConnectorWithDialer(dsn string, dial DialFunc) (driver.Connector, error) {
+	return nil, fmt.Errorf("SQLite databases are local files and cannot use a network dialer")
+}
+
+func (a *SQLiteAdapter) WithAccessToken(dsn, token string) (string, error) {
+	return "", fmt.Errorf("SQLite databases are local files and do not use access tokens")
+}
+
+// WithConnectionLabel leaves dsn unchanged: a SQLite file has no server to
+// show connections to.
+func (a *SQLiteAdapter) WithConnectionLabel(dsn, label string) (string, error) { return dsn, nil }
+
+func (a *SQLiteAdapter) TLSVerified(dsn string) bool { return true }
+
+func (a *SQLiteAdapter) DatabaseName(dsn string) string {
+	// DSN is a file path or file: URI, possibly with ?mode=ro
+	path := sqliteFilePath(dsn)
+	// Extract just the filename without directory
+	parts := strings.Split(path, "/")
+	name := parts[len(parts)-1]
+	// Remove common extensions for display
+	name = strings.TrimSuffix(name, ".db")
+	name = strings.TrimSuffix(name, ".sqlite")
+	name = strings.TrimSuffix(name, ".sqlite3")
+	return name
+}
+
+func (a *SQLiteAdapter) ReadOnlyStatements() []string {
+	// Read-only is primarily enforced via mode=ro in the file: URI.
+	// PRAGMA query_only provides defense-in-depth.
+	return []string{"PRAGMA query_only = ON"}
+}
+
+func (a *SQLiteAdapter) ReadOnlyCheckQuery() string {
+	return "PRAGMA query_only"
+}
+
+// WriteProbe creates a temporary table, which query_only and read-only
+// files refuse too.
+func (a *SQLiteAdapter) WriteProbe(name string) (string, string) {
+	return "CREATE TEMP TABLE " + a.QuoteIdentifier(name) + " (id INTEGER)", ""
+}
+
+// SnapshotTxOptions needs no isolation level: a SQLite read transaction
+// sees one snapshot until it ends. Outside WAL mode it also blocks writers.
+func (a *SQLiteAdapter) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{ReadOnly: true}
+}
+
+// SearchPathStatement is unsupported: a SQLite file has a single schema.
+func (a *SQLiteAdapter) SearchPathStatement(schema string) (string, error) {
+	return "", ErrSearchPathUnsupported
+}
+
+func (a *SQLiteAdapter) ListTablesQuery(databaseName string) (string, []any) {
+	// SQLite has no information_schema. Use sqlite_master.
+	// databaseName is ignored (SQLite has one DB per file).
+	return `SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`,
+		nil
+}
+
+func (a *SQLiteAdapter) ReadSchemaQuery(databaseName, tableName string) (string, []any) {
+	// PRAGMA table_xinfo cannot use ? placeholders, so we embed the table name safely.
+	// Unlike table_info it includes generated columns and the hidden
+	// columns of virtual tables.
+	return fmt.Sprintf("PRAGMA table_xinfo('%s')", strings.ReplaceAll(tableName, "'", "''")),
+		nil
+}
+
+func (a *SQLiteAdapter) ScanSchemaRow(rows *sql.Rows) (map[string]any, error) {
+	// PRAGMA table_xinfo returns: cid, name, type, notnull, dflt_value, pk, hidden
+	var cid int
+	var name, colType string
+	var notNull, pk, hidden int
+	var dfltValue sql.NullString
+
+	if err := rows.Scan(&cid, &name, &colType, &notNull, &dfltValue, &pk, &hidden); err != nil {
+		return nil, err
+	}
+
+	isNullable := "YES"
+	if notNull == 1 {
+		isNullable = "NO"
+	}
+
+	col := map[string]any{
+		"column_name": name,
+		"data_type":   colType,
+		"is_nullable": isNullable,
+	}
+	if pk > 0 {
+		col["column_key"] = "PRI"
+	}
+	if dfltValue.Valid {
+		col["column_default"] = dfltValue.String
+	}
+	switch hidden {
+	case 1:
+		// A virtual table column SELECT * leaves out, e.g. FTS5's rank
+		col["hidden"] = true
+	case 2:
+		col["extra"] = "VIRTUAL GENERATED"
+	case 3:
+		col["extra"] = "STORED GENERATED"
+	}
+	return col, nil
+}
+
+func (a *SQLiteAdapter) DecodeValue(databaseType string, val any) any {
+	return val
+}
+
+// BindTime binds dates as text, the form SQLite keeps them in, which the
+// driver's own format for a time.Time would not compare equal to.
+func (a *SQLiteAdapter) BindTime(t time.Time, dateOnly bool) any {
+	if dateOnly {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format("2006-01-02 15:04:05.999999999")
+}
+
+func (a *SQLiteAdapter) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (a *SQLiteAdapter) QuoteString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// ValuesQuery uses VALUES, which unlike a compound SELECT has no limit on
+// its number of rows.
+func (a *SQLiteAdapter) ValuesQuery(columns []string, rows [][]string) string {
+	return valuesQuery(a, columns, rows)
+}
+
+func (a *SQLiteAdapter) FoldIdentifier(name string) string {
+	return name
+}
+
+// IdentifierCase reports SQLite's fixed rules: names match regardless of
+// (ASCII) case, quoted or not.
+func (a *SQLiteAdapter) IdentifierCase(ctx context.Context, db *sql.DB) (*IdentifierCase, error) {
+	return &IdentifierCase{
+		UnquotedFolding: "none",
+		Note:            "Table and column names are case-insensitive, quoted or not",
+	}, nil
+}
+
+// sqliteTimeBuckets formats a timestamp (ISO 8601 text, or Julian day or
+// Unix time number) as the start of each unit.
+var sqliteTimeBuckets = map[string]string{
+	"minute": "strftime('%%Y-%%m-%%d %%H:%%M:00', %s)",
+	"hour":   "strftime('%%Y-%%m-%%d %%H:00:00', %s)",
+	"day":    "date(%s)",
+	"week":   "date(%s, '-6 days', 'weekday 1')",
+	"month":  "strftime('%%Y-%%m-01', %s)",
+	"year":   "strftime('%%Y-01-01', %s)",
+}
+
+// TimeBucket formats the bucket start with SQLite's date functions.
+func (a *SQLiteAdapter) TimeBucket(column, unit string) (string, error) {
+	format, ok := sqliteTimeBuckets[unit]
+	if !ok {
+		return "", fmt.Errorf("unknown time bucket %q", unit)
+	}
+	return fmt.Sprintf(format, column), nil
+}
+
+// EpochSeconds uses unixepoch, which reads ISO 8601 text, Julian day and
+// Unix time values alike.
+func (a *SQLiteAdapter) EpochSeconds(column string) string {
+	return fmt.Sprintf("unixepoch(%s)", column)
+}
+
+// JSONExtract uses json_extract, which returns strings unquoted.
+func (a *SQLiteAdapter) JSONExtract(column string, keys []string) string {
+	return fmt.Sprintf("json_extract(%s, %s)", column, a.QuoteString(jsonPath(keys)))
+}
+
+// SampleQuery falls back to ORDER BY RANDOM(), as SQLite has no
+// TABLESAMPLE. The largest rowid (an index lookup) estimates the size, and
+// for large tables a random() pre-filter keeps the sort small.
+func (a *SQLiteAdapter) SampleQuery(ctx context.Context, db *sql.DB, databaseName, table string, limit int) (string, error) {
+	quoted := a.QuoteIdentifier(table)
+	var estimate sql.NullInt64
+	// WITHOUT ROWID tables have no rowid; sample them without an estimate
+	_ = db.QueryRowContext(ctx, "SELECT max(rowid) FROM "+quoted).Scan(&estimate)
+
+	query := "SELECT * FROM " + quoted
+	if fraction := sampleFraction(limit, estimate.Int64); estimate.Valid && fraction > 0 {
+		query += fmt.Sprintf(" WHERE abs(random() %% 1000000) < %d", int64(fraction*1000000))
+	}
+	return query + fmt.Sprintf(" ORDER BY RANDOM() LIMIT %d", limit), nil
+}
+
+// AsOfSampleQuery is unsupported: SQLite keeps no row history.
+func (a *SQLiteAdapter) AsOfSampleQuery(ctx context.Context, db *sql.DB, table string, at time.Time, limit int) (string, error) {
+	return "", ErrAsOfUnsupported
+}
+
+// DescribeSchema uses the pragma table-valued functions; SQLite keeps no
+// comments or row estimates.
+func (a *SQLiteAdapter) DescribeSchema(ctx context.Context, db *sql.DB, databaseName string) ([]TableInfo, error) {
+	tables := newTableSet()
+
+	err := scanEach(ctx, db, `SELECT m.name, p.name, p.type, p.pk
+		FROM sqlite_master m JOIN pragma_table_info(m.name) p
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, p.cid`, nil,
+		func(rows *sql.Rows) error {
+			var table string
+			var col ColumnInfo
+			var pk int
+			if err := rows.Scan(&table, &col.Name, &col.Type, &pk); err != nil {
+				return err
+			}
+			col.PrimaryKey = pk > 0
+			info := tables.get(table)
+			info.Columns = append(info.Columns, col)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT m.name, f.id, f."from", f."table", COALESCE(f."to", '')
+		FROM sqlite_master m JOIN pragma_foreign_key_list(m.name) f
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, f.id, f.seq`, nil,
+		func(rows *sql.Rows) error {
+			var table, id, column, refTable, refColumn string
+			if err := rows.Scan(&table, &id, &column, &refTable, &refColumn); err != nil {
+				return err
+			}
+			tables.addForeignKeyColumn(table, id, column, refTable, refColumn)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read foreign keys: %w", err)
+	}
+	return tables.list(), nil
+}
+
+// sqliteViewPrefix matches the CREATE VIEW statement SQLite stores up to
+// the view's SELECT.
+var sqliteViewPrefix = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP\s+|TEMPORARY\s+)?VIEW\s+(?:IF\s+NOT\s+EXISTS\s+)?.*?\bAS\s+`)
+
+// ViewDefinitions reads the CREATE VIEW statements in sqlite_master,
+// keeping only each view's SELECT.
+func (a *SQLiteAdapter) ViewDefinitions(ctx context.Context, db *sql.DB, databaseName string) (map[string]string, error) {
+	views := map[string]string{}
+	err := scanEach(ctx, db, `SELECT name, sql FROM sqlite_master WHERE type = 'view'`, nil,
+		func(rows *sql.Rows) error {
+			var name, definition string
+			if err := rows.Scan(&name, &definition); err != nil {
+				return err
+			}
+			views[name] = sqliteViewPrefix.ReplaceAllString(definition, "")
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read view definitions: %w", err)
+	}
+	return views, nil
+}
+
+// sqlitePlanAccess matches the EXPLAIN QUERY PLAN detail of a table access,
+// e.g. "SEARCH u USING INDEX idx_email (email=?)" or "SCAN orders".
+var sqlitePlanAccess = regexp.MustCompile(`^(SCAN|SEARCH) (?:TABLE )?(\S+)(?: AS \S+)?(?: USING (?:COVERING )?INDEX (\S+)| USING (?:INTEGER )?(PRIMARY KEY))?`)
+
+// ExplainAccess parses EXPLAIN QUERY PLAN. Rowid and WITHOUT ROWID primary
+// key lookups are reported as the index "PRIMARY KEY".
+func (a *SQLiteAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	var accesses []PlanAccess
+	err := scanEach(ctx, db, "EXPLAIN QUERY PLAN "+query, nil, func(rows *sql.Rows) error {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return err
+		}
+		m := sqlitePlanAccess.FindStringSubmatch(detail)
+		// Subqueries and constant rows are not tables
+		if m == nil || strings.HasPrefix(m[2], "(") || m[2] == "CONSTANT" {
+			return nil
+		}
+		access := PlanAccess{Table: m[2], Index: m[3] + m[4]}
+		access.FullScan = m[1] == "SCAN"
+		accesses = append(accesses, access)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return accesses, nil
+}
+
+// ExplainPlan builds the tree of EXPLAIN QUERY PLAN from each step's
+// parent id. SQLite reports no row or cost estimates.
+func (a *SQLiteAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	type step struct {
+		id, parent int
+		node       PlanNode
+	}
+	var steps []step
+	err := scanEach(ctx, db, "EXPLAIN QUERY PLAN "+query, nil, func(rows *sql.Rows) error {
+		var id, parent, notUsed int
+		var detail string
+		if err := rows.Scan(&id, &parent, &notUsed, &detail); err != nil {
+			return err
+		}
+		steps = append(steps, step{id: id, parent: parent, node: sqlitePlanNode(detail)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+
+	var children func(parent int) []PlanNode
+	children = func(parent int) []PlanNode {
+		var nodes []PlanNode
+		for _, st := range steps {
+			// Steps come in order, so a step's children follow it
+			if st.parent == parent && st.id > parent {
+				st.node.Children = children(st.id)
+				nodes = append(nodes, st.node)
+			}
+		}
+		return nodes
+	}
+	return children(0), nil
+}
+
+// sqlitePlanNode describes an EXPLAIN QUERY PLAN step, naming the table
+// and index of SCAN and SEARCH steps.
+func sqlitePlanNode(detail string) PlanNode {
+	m := sqlitePlanAccess.FindStringSubmatch(detail)
+	if m == nil || strings.HasPrefix(m[2], "(") {
+		return PlanNode{Operation: detail}
+	}
+	node := PlanNode{Operation: m[1], Table: m[2], Index: m[3] + m[4], Detail: detail}
+	switch {
+	case m[1] == "SEARCH":
+		node.Access = PlanIndexLookup
+	case node.Index != "":
+		node.Access = PlanFullIndexScan
+	default:
+		node.Access = PlanFullScan
+	}
+	return node
+}
+
+// EstimateCost is unsupported: EXPLAIN QUERY PLAN does not expose the
+// planner's cost estimates.
+func (a *SQLiteAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	return 0, ErrCostUnsupported
+}
+
+func (a *SQLiteAdapter) ListIndexes(ctx context.Context, db *sql.DB, databaseName string) ([]IndexInfo, error) {
+	var indexes []IndexInfo
+	err := scanEach(ctx, db, `SELECT m.name, il.name, il."unique", il.origin = 'pk' AND t.wr, ii.name
+		FROM sqlite_master m
+		JOIN pragma_table_list t ON t.schema = 'main' AND t.name = m.name
+		JOIN pragma_index_list(m.name) il
+		JOIN pragma_index_info(il.name) ii
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+		ORDER BY m.name, il.name, ii.seqno`, nil,
+		func(rows *sql.Rows) error {
+			var table, name string
+			var unique, withoutRowidPK bool
+			var column sql.NullString
+			if err := rows.Scan(&table, &name, &unique, &withoutRowidPK, &column); err != nil {
+				return err
+			}
+			if withoutRowidPK {
+				// Named as EXPLAIN QUERY PLAN shows it
+				name = "PRIMARY KEY"
+			}
+			indexes = appendIndexColumn(indexes, table, name, unique, column.String)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	return indexes, nil
+}
+
+// ListSequences reports the counters of AUTOINCREMENT tables, which SQLite
+// keeps in sqlite_sequence once the first one is created. Other rowid tables
+// have no counter: a new row gets one more than the largest rowid.
+func (a *SQLiteAdapter) ListSequences(ctx context.Context, db *sql.DB, databaseName string) ([]SequenceInfo, error) {
+	var exists bool
+	if err := db.QueryRowContext(ctx,
+		`SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'sqlite_sequence')`).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to read sequences: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var sequences []SequenceInfo
+	err := scanEach(ctx, db, `SELECT s.name, COALESCE((SELECT p.name FROM pragma_table_info(s.name) p WHERE p.pk = 1), ''), s.seq
+		FROM sqlite_sequence s
+		ORDER BY s.name`, nil,
+		func(rows *sql.Rows) error {
+			var table, column string
+			var last int64
+			if err := rows.Scan(&table, &column, &last); err != nil {
+				return err
+			}
+			next := last + 1
+			sequences = append(sequences, SequenceInfo{
+				Name: table, Table: table, Column: column,
+				LastValue: &last, NextValue: &next, MaxValue: math.MaxInt64,
+			})
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sequences: %w", err)
+	}
+	return sequences, nil
+}
+
+// sqliteVirtualModule matches the module of a CREATE VIRTUAL TABLE statement.
+var sqliteVirtualModule = regexp.MustCompile(`(?is)^\s*CREATE\s+VIRTUAL\s+TABLE\b.*?\bUSING\s+(\w+)`)
+
+// VirtualTables returns the virtual tables of the main database with their
+// shadow tables, the real tables modules such as FTS5 and R*Tree keep their
+// data in. It needs PRAGMA table_list, added in SQLite 3.37.
+func (a *SQLiteAdapter) VirtualTables(ctx context.Context, db *sql.DB) ([]VirtualTableInfo, error) {
+	var tables []VirtualTableInfo
+	var shadows []string
+	err := scanEach(ctx, db, `SELECT l.name, l.type, COALESCE(m.sql, '')
+		FROM pragma_table_list l
+		LEFT JOIN sqlite_master m ON m.type = 'table' AND m.name = l.name
+		WHERE l.schema = 'main' AND l.type IN ('virtual', 'shadow')
+		ORDER BY l.name`, nil,
+		func(rows *sql.Rows) error {
+			var name, kind, ddl string
+			if err := rows.Scan(&name, &kind, &ddl); err != nil {
+				return err
+			}
+			if kind == "shadow" {
+				shadows = append(shadows, name)
+				return nil
+			}
+			info := VirtualTableInfo{Name: name}
+			if m := sqliteVirtualModule.FindStringSubmatch(ddl); m != nil {
+				info.Module = strings.ToLower(m[1])
+			}
+			tables = append(tables, info)
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read virtual tables: %w", err)
+	}
+
+	// A shadow table is named after its virtual table: docs_data for docs
+	for _, shadow := range shadows {
+		owner := -1
+		for i, table := range tables {
+			if strings.HasPrefix(shadow, table.Name+"_") && (owner < 0 || len(table.Name) > len(tables[owner].Name)) {
+				owner = i
+			}
+		}
+		if owner >= 0 {
+			tables[owner].ShadowTables = append(tables[owner].ShadowTables, shadow)
+		}
+	}
+	return tables, nil
+}
+
+// sqliteCollateClause matches a COLLATE clause in a CREATE TABLE statement.
+var sqliteCollateClause = regexp.MustCompile(`(?i)\bCOLLATE\s+["'\x60\[]?(\w+)`)
+
+// DescribeSettings reports SQLite's fixed behavior alongside the encoding.
+// SQLite records column collations only in each table's CREATE statement,
+// so columns are counted from its COLLATE clauses, the rest as BINARY.
+func (a *SQLiteAdapter) DescribeSettings(ctx context.Context, db *sql.DB, databaseName string) (*DatabaseSettings, error) {
+	settings, err := scanSettings(db.QueryRowContext(ctx, `SELECT sqlite_version(), e.encoding,
+			(SELECT group_concat(name, ', ') FROM pragma_database_list)
+		FROM pragma_encoding e`),
+		"version", "encoding", "search_path")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	settings["default_collation"] = "BINARY"
+	settings["like"] = "case-insensitive for ASCII letters only"
+	settings["timezone"] = "UTC ('localtime' uses the server process's time zone)"
+
+	result := &DatabaseSettings{Settings: settings}
+	err = scanEach(ctx, db, `SELECT m.name, m.sql, (SELECT COUNT(*) FROM pragma_table_info(m.name))
+		FROM sqlite_master m
+		WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'`, nil,
+		func(rows *sql.Rows) error {
+			var table, ddl string
+			var columns int
+			if err := rows.Scan(&table, &ddl, &columns); err != nil {
+				return err
+			}
+			for _, m := range sqliteCollateClause.FindAllStringSubmatch(ddl, -1) {
+				result.addCollation(table, strings.ToUpper(m[1]), 1)
+				columns--
+			}
+			if columns > 0 {
+				result.addCollation(table, "BINARY", columns)
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read column collations: %w", err)
+	}
+	return result, nil
+}
+
+// ReadVariables reads only version: SQLite has no session variables, and
+// its pragmas are left to database_settings.
+func (a *SQLiteAdapter) ReadVariables(ctx context.Context, db *sql.DB, names []string) (map[string]string, error) {
+	values := make(map[string]string, len(names))
+	if slices.Contains(names, "version") {
+		var version string
+		if err := db.QueryRowContext(ctx, "SELECT sqlite_version()").Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read version: %w", err)
+		}
+		values["version"] = version
+	}
+	return values, nil
+}
+
+// TopStatements is unsupported.
+func (a *SQLiteAdapter) TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error) {
+	return nil, ErrStatementStatsUnsupported
+}
+
+// ActiveQueries is unsupported: SQLite has no server to ask.
+func (a *SQLiteAdapter) ActiveQueries(ctx context.Context, db *sql.DB) ([]ActiveQuery, error) {
+	return nil, ErrActivityUnsupported
+}
+
+func (a *SQLiteAdapter) AuditPrivileges(ctx context.Context, db *sql.DB, dsn string) ([]string, error) {
+	// SQLite has no accounts; the question is whether this process could
+	// write the database file if the read-only flags were bypassed.
+	path := sqliteFilePath(dsn)
+	if path == "" || path == ":memory:" {
+		return nil, nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	f.Close()
+	return []string{fmt.Sprintf("database file %s is writable by this process", path)}, nil
+}
+
+func (a *SQLiteAdapter) DatabaseFile(dsn string) string {
+	return sqliteFilePath(dsn)
+}
+
+// sqliteFilePath extracts the filesystem path from a SQLite DSN, which may be
+// a bare path or a file: URI, optionally followed by ?query parameters.
+func sqliteFilePath(dsn string) string {
+	path := strings.TrimPrefix(dsn, "file:")
+	if idx := strings.Index(path, "?"); idx != -1 {
+		path = path[:idx]
+	}
+	return path
+}
+
+func (a *SQLiteAdapter) IsTransientError(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// Mask extended result codes down to the primary code
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
+
+func (a *SQLiteAdapter) ImplicitSchemaReference(cleanedSQL string) string { return "" }
+
+func (a *SQLiteAdapter) ValidateQuery(sqlQuery string) error {
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	if err := validateCommon(sqlQuery, cleaned); err != nil {
+		return err
+	}
+	if err := validateTemporalClauses(cleaned, temporalSyntaxNone); err != nil {
+		return err
+	}
+
+	// SQLite-specific forbidden patterns
+	forbiddenPatterns := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bload_extension\s*\(`, "load_extension()"},
+		{`(?i)\bwritefile\s*\(`, "writefile()"},
+		{`(?i)\bedit\s*\(`, "edit()"},
+		{`(?i)\bfts3_tokenizer\s*\(`, "fts3_tokenizer()"},
+	}
+
+	for _, fp := range forbiddenPatterns {
+		re := regexp.MustCompile(fp.pattern)
+		if re.MatchString(sqlQuery) {
+			return fmt.Errorf("query contains forbidden pattern: %s", fp.desc)
+		}
+	}
+
+	// SQLite-specific dangerous keywords
+	extraKeywords := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)(?:^|[^a-zA-Z_])REPLACE(?:[^a-zA-Z_]|$)`, "REPLACE"},
+		{`(?i)(?:^|[^a-zA-Z_])ATTACH(?:[^a-zA-Z_]|$)`, "ATTACH"},
+		{`(?i)(?:^|[^a-zA-Z_])DETACH(?:[^a-zA-Z_]|$)`, "DETACH"},
+		{`(?i)(?:^|[^a-zA-Z_])REINDEX(?:[^a-zA-Z_]|$)`, "REINDEX"},
+		{`(?i)(?:^|[^a-zA-Z_])VACUUM(?:[^a-zA-Z_]|$)`, "VACUUM"},
+	}
+
+	for _, dk := range extraKeywords {
+		re := regexp.MustCompile(dk.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden keyword: %s", dk.desc)
+		}
+	}
+
+	// Block PRAGMA writes (PRAGMA x = value), but allow read PRAGMAs
+	pragmaWritePattern := regexp.MustCompile(`(?i)\bPRAGMA\s+\w+\s*=`)
+	if pragmaWritePattern.MatchString(cleaned) {
+		return fmt.Errorf("PRAGMA writes are not allowed")
+	}
+
+	return nil
+}
+
+// NormalizeRowLimit rewrites what it must: SQLite has no FETCH FIRST or TOP.
+func (a *SQLiteAdapter) NormalizeRowLimit(sqlQuery string) string {
+	return normalizeRowLimit(sqlQuery, a.RemoveStringsAndComments(sqlQuery), rowLimitSyntax{limitComma: true})
+}
+
+// RemoveStringsAndComments strips string literals and comments from SQL
+// for safe keyword detection. SQLite-specific: no # comments, no backslash
+// escaping, supports backtick and [bracket] identifiers.
+func (a *SQLiteAdapter) RemoveStringsAndComments(sql string) string {
+	var result strings.Builder
+	i := 0
+	n := len(sql)
+
+	for i < n {
+		// Single-line comment starting with --
+		if i+1 < n && sql[i] == '-' && sql[i+1] == '-' {
+			for i < n && sql[i] != '\n' {
+				i++
+			}
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Multi-line comment /* */
+		if i+1 < n && sql[i] == '/' && sql[i+1] == '*' {
+			i += 2
+			for i+1 < n && !(sql[i] == '*' && sql[i+1] == '/') {
+				i++
+			}
+			i += 2 // Skip */
+			result.WriteByte(' ')
+			continue
+		}
+
+		// Single-quoted string (no backslash escaping in SQLite)
+		if sql[i] == '\'' {
+			i++
+			for i < n {
+				if sql[i] == '\'' {
+					if i+1 < n && sql[i+1] == '\'' {
+						i += 2 // Escaped quote ''
+						continue
+					}
+					i++
+					break
+				}
+				i++
+			}
+			result.WriteString("''") // Placeholder for string
+			continue
+		}
+
+		// Double-quoted identifier/string
+		if sql[i] == '"' {
+			result.WriteByte('"')
+			i++
+			for i < n {
+				if sql[i] == '"' {
+					if i+1 < n && sql[i+1] == '"' {
+						result.WriteString(`""`)
+						i += 2
+						continue
+					}
+					result.WriteByte('"')
+					i++
+					break
+				}
+				result.WriteByte(sql[i])
+				i++
+			}
+			continue
+		}
+
+		// Backtick-quoted identifier (SQLite compatibility)
+		if sql[i] == '`' {
+			result.WriteByte('`')
+			i++
+			for i < n && sql[i] != '`' {
+				result.WriteByte(sql[i])
+				i++
+			}
+			if i < n {
+				result.WriteByte('`')
+				i++
+			}
+			continue
+		}
+
+		// [bracket]-quoted identifier (SQL Server compatibility in SQLite)
+		if sql[i] == '[' {
+			result.WriteByte('[')
+			i++
+			for i < n && sql[i] != ']' {
+				result.WriteByte(sql[i])
+				i++
+			}
+			if i < n {
+				result.WriteByte(']')
+				i++
+			}
+			continue
+		}
+
+		result.WriteByte(sql[i])
+		i++
+	}
+
+	return result.String()
+}
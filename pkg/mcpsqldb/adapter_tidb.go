@@ -0,0 +1,306 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// TiDBAdapter implements DBAdapter for TiDB, which speaks the MySQL wire
+// protocol and connects like MySQL (the MCP_MYSQL_* env vars apply), but
+// stores tables in TiKV regions and TiFlash replicas and plans queries
+// across both.
+type TiDBAdapter struct {
+	MySQLAdapter
+}
+
+func (a *TiDBAdapter) ServerName() string { return "tidb-readonly-mcp-server" }
+func (a *TiDBAdapter) URIScheme() string  { return "tidb" }
+
+// FormatDSN defaults to TiDB's SQL port.
+func (a *TiDBAdapter) FormatDSN(p ConnParams) (string, error) {
+	if p.Port == "" {
+		p.Port = "4000"
+	}
+	return a.MySQLAdapter.FormatDSN(p)
+}
+
+// ExplainAccess reads the table accesses of ExplainPlan.
+func (a *TiDBAdapter) ExplainAccess(ctx context.Context, db *sql.DB, query string) ([]PlanAccess, error) {
+	nodes, err := a.ExplainPlan(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	var accesses []PlanAccess
+	var walk func(nodes []PlanNode)
+	walk = func(nodes []PlanNode) {
+		for _, node := range nodes {
+			if node.Table != "" && node.Access != "" {
+				accesses = append(accesses, PlanAccess{Table: node.Table, Index: node.Index, FullScan: node.Access != PlanIndexLookup})
+			}
+			walk(node.Children)
+		}
+	}
+	walk(nodes)
+	return accesses, nil
+}
+
+// ExplainPlan parses the operator tree of EXPLAIN FORMAT='verbose', as TiDB
+// has no MySQL-style JSON format.
+func (a *TiDBAdapter) ExplainPlan(ctx context.Context, db *sql.DB, query string) ([]PlanNode, error) {
+	columns, rows, err := tidbExplain(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+	return parseTiDBPlan(columns, rows), nil
+}
+
+// EstimateCost reads the estCost of the root operator.
+func (a *TiDBAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	columns, rows, err := tidbExplain(ctx, db, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	nodes := parseTiDBPlan(columns, rows)
+	if len(nodes) == 0 || nodes[0].EstimatedCost == nil {
+		return 0, fmt.Errorf("plan has no cost")
+	}
+	return *nodes[0].EstimatedCost, nil
+}
+
+// tidbExplain runs EXPLAIN FORMAT='verbose' of query with args and returns
+// its column names and rows as text.
+func tidbExplain(ctx context.Context, db *sql.DB, query string, args ...any) ([]string, [][]string, error) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN FORMAT='verbose' "+query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	var lines [][]string
+	for rows.Next() {
+		values := make([]sql.NullString, len(columns))
+		dest := make([]any, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, nil, fmt.Errorf("failed to explain query: %w", err)
+		}
+		line := make([]string, len(columns))
+		for i, v := range values {
+			line[i] = v.String
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return columns, lines, nil
+}
+
+// tidbPlanID splits an operator id such as "│ └─TableReader_5(Probe)" into
+// its tree prefix, operator name and, for joins' inputs, its side.
+var tidbPlanID = regexp.MustCompile(`^([\s│├└─]*)(.+?)(?:_\d+)?(?:\((Build|Probe)\))?$`)
+
+// tidbPlanAccess maps the operators reading TiKV or TiFlash to how they
+// read their table.
+var tidbPlanAccess = map[string]string{
+	"TableFullScan":  PlanFullScan,
+	"IndexFullScan":  PlanFullIndexScan,
+	"TableRangeScan": PlanIndexLookup,
+	"IndexRangeScan": PlanIndexLookup,
+	"TableRowIDScan": PlanIndexLookup,
+	"PointGet":       PlanIndexLookup,
+	"BatchPointGet":  PlanIndexLookup,
+}
+
+// parseTiDBPlan converts EXPLAIN's rows into PlanNodes. Each row is an
+// operator whose children are the rows after it printed further right.
+// The task column tells where an operator runs (root in TiDB, cop[tikv] or
+// cop[tiflash] in the storage layer, mpp[tiflash] in TiFlash's MPP engine)
+// and leads the node's detail.
+func parseTiDBPlan(columns []string, rows [][]string) []PlanNode {
+	column := map[string]int{}
+	for i, name := range columns {
+		column[strings.ToLower(name)] = i
+	}
+	field := func(row []string, name string) string {
+		if i, ok := column[name]; ok && i < len(row) {
+			return row[i]
+		}
+		return ""
+	}
+
+	type entry struct {
+		depth int
+		node  *PlanNode
+	}
+	var roots []*PlanNode
+	var stack []entry
+	for _, row := range rows {
+		m := tidbPlanID.FindStringSubmatch(field(row, "id"))
+		if m == nil {
+			continue
+		}
+		node := PlanNode{Operation: m[2], Access: tidbPlanAccess[m[2]]}
+		if n, err := strconv.ParseFloat(field(row, "estrows"), 64); err == nil {
+			node.EstimatedRows = floatPtr(n)
+		}
+		if n, err := strconv.ParseFloat(field(row, "estcost"), 64); err == nil {
+			node.EstimatedCost = floatPtr(n)
+		}
+		for _, part := range strings.Split(field(row, "access object"), ",") {
+			key, value, _ := strings.Cut(strings.TrimSpace(part), ":")
+			switch key {
+			case "table":
+				node.Table = value
+			case "index":
+				node.Index, _, _ = strings.Cut(value, "(")
+			}
+		}
+		var details []string
+		if m[3] != "" {
+			details = append(details, strings.ToLower(m[3])+" side")
+		}
+		if task := field(row, "task"); task != "" {
+			details = append(details, "task: "+task)
+		}
+		if info := field(row, "operator info"); info != "" {
+			details = append(details, info)
+		}
+		node.Detail = strings.Join(details, "; ")
+
+		depth := len([]rune(m[1]))
+		for len(stack) > 0 && stack[len(stack)-1].depth >= depth {
+			stack = stack[:len(stack)-1]
+		}
+		var current *PlanNode
+		if len(stack) == 0 {
+			current = &node
+			roots = append(roots, current)
+		} else {
+			// Earlier siblings are complete, so moving them is safe
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, node)
+			current = &parent.Children[len(parent.Children)-1]
+		}
+		stack = append(stack, entry{depth: depth, node: current})
+	}
+
+	nodes := make([]PlanNode, 0, len(roots))
+	for _, root := range roots {
+		nodes = append(nodes, *root)
+	}
+	return nodes
+}
+
+// TiDBTables returns the storage layout of the database's tables: how rows
+// are keyed, the TiKV regions holding them, their TiFlash replicas and
+// partitioning.
+func (a *TiDBAdapter) TiDBTables(ctx context.Context, db *sql.DB, databaseName string) (map[string]*TiDBTableInfo, error) {
+	tables := map[string]*TiDBTableInfo{}
+	err := scanEach(ctx, db, `SELECT table_name, COALESCE(tidb_pk_type, ''), COALESCE(tidb_row_id_sharding_info, '')
+		FROM information_schema.tables WHERE table_schema = ? AND table_type = 'BASE TABLE'`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var t TiDBTableInfo
+			if err := rows.Scan(&t.Name, &t.PKType, &t.RowIDSharding); err != nil {
+				return err
+			}
+			tables[t.Name] = &t
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tables: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT table_name, COUNT(DISTINCT region_id)
+		FROM information_schema.tikv_region_status WHERE db_name = ? AND is_index = 0
+		GROUP BY table_name`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var name string
+			var regions int
+			if err := rows.Scan(&name, &regions); err != nil {
+				return err
+			}
+			if t := tables[name]; t != nil {
+				t.Regions = regions
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TiKV regions: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT table_name, replica_count, available, progress
+		FROM information_schema.tiflash_replica WHERE table_schema = ?`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var name string
+			var replicas int
+			var available bool
+			var progress float64
+			if err := rows.Scan(&name, &replicas, &available, &progress); err != nil {
+				return err
+			}
+			if t := tables[name]; t != nil {
+				t.TiFlashReplicas, t.TiFlashAvailable, t.TiFlashProgress = replicas, available, progress
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TiFlash replicas: %w", err)
+	}
+
+	err = scanEach(ctx, db, `SELECT table_name, partition_method, COALESCE(partition_expression, ''), COUNT(*)
+		FROM information_schema.partitions WHERE table_schema = ? AND partition_name IS NOT NULL
+		GROUP BY table_name, partition_method, partition_expression`, []any{databaseName},
+		func(rows *sql.Rows) error {
+			var name, method, expression string
+			var partitions int
+			if err := rows.Scan(&name, &method, &expression, &partitions); err != nil {
+				return err
+			}
+			if t := tables[name]; t != nil {
+				t.PartitionMethod, t.PartitionExpression, t.Partitions = method, expression, partitions
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read partitions: %w", err)
+	}
+	return tables, nil
+}
+
+// ValidateQuery applies the MySQL rules, then blocks ADMIN statements,
+// which check, repair or cancel jobs on the cluster, SPLIT, which creates
+// TiKV regions, and FLASHBACK, which restores tables or the whole cluster
+// to an earlier state.
+func (a *TiDBAdapter) ValidateQuery(sqlQuery string) error {
+	if err := a.MySQLAdapter.ValidateQuery(sqlQuery); err != nil {
+		return err
+	}
+	cleaned := a.RemoveStringsAndComments(sqlQuery)
+
+	forbiddenPatterns := []struct {
+		pattern string
+		desc    string
+	}{
+		{`(?i)\bADMIN\s+(?:ALTER|CANCEL|CHECK|CHECKSUM|CLEANUP|FLUSH|PAUSE|PLUGINS|RECOVER|RELOAD|REPAIR|RESET|RESUME|SET|SHOW)\b`, "ADMIN"},
+		{`(?i)\bSPLIT\s+(?:PARTITION\s+)?(?:TABLE|REGION)\b`, "SPLIT"},
+		{`(?i)\bFLASHBACK\s+(?:TABLE|DATABASE|SCHEMA|CLUSTER)\b`, "FLASHBACK"},
+	}
+	for _, fp := range forbiddenPatterns {
+		re := regexp.MustCompile(fp.pattern)
+		if re.MatchString(cleaned) {
+			return fmt.Errorf("query contains forbidden pattern: %s", fp.desc)
+		}
+	}
+	return nil
+}
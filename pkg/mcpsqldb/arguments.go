@@ -0,0 +1,153 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"math"
+	"slices"
+)
+
+// MaxRequestBytes limits the size of one JSON-RPC message from the client;
+// the rest of a longer line is discarded unread and the message rejected
+// (overridable via MCP_MAX_REQUEST_BYTES env var)
+var MaxRequestBytes = 4 << 20
+
+// MaxArgumentBytes limits the length of each string argument of a tool call
+// (overridable via MCP_MAX_ARGUMENT_BYTES env var)
+var MaxArgumentBytes = 1 << 20
+
+// toolSchema returns the input schema of the listed tool name.
+func (s *Server) toolSchema(name string) (InputSchema, bool) {
+	tools, _ := s.handleListTools()
+	for _, tool := range tools.Tools {
+		if tool.Name == name {
+			return tool.InputSchema, true
+		}
+	}
+	return InputSchema{}, false
+}
+
+// validateArguments checks a tool call's args against its schema before the
+// handler runs: required arguments are present, no undeclared ones are
+// given, each has its declared type, and strings fit in MaxArgumentBytes.
+func validateArguments(schema InputSchema, args map[string]any) *Error {
+	if err := validateObject("", schema.Properties, schema.Required, args); err != nil {
+		return &Error{Code: InvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// validateObject checks the fields of an object argument at path.
+func validateObject(path string, properties map[string]Property, required []string, fields map[string]any) error {
+	for _, name := range required {
+		if _, ok := fields[name]; !ok {
+			return fmt.Errorf("Missing required '%s' parameter", argumentPath(path, name))
+		}
+	}
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	// Sorted, so the same call always reports the same problem
+	slices.Sort(names)
+	for _, name := range names {
+		property, ok := properties[name]
+		if !ok {
+			return fmt.Errorf("Unknown '%s' parameter", argumentPath(path, name))
+		}
+		if err := validateValue(argumentPath(path, name), property, fields[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateValue checks the argument at path against property, which decoded
+// JSON represents as string, float64, bool, []any or map[string]any.
+func validateValue(path string, property Property, value any) error {
+	ok := true
+	switch property.Type {
+	case "string":
+		var s string
+		if s, ok = value.(string); ok && len(s) > MaxArgumentBytes {
+			return fmt.Errorf("Invalid '%s' parameter: longer than %d bytes", path, MaxArgumentBytes)
+		}
+	case "integer":
+		var n float64
+		n, ok = value.(float64)
+		ok = ok && n == math.Trunc(n)
+	case "number":
+		_, ok = value.(float64)
+	case "boolean":
+		_, ok = value.(bool)
+	case "array":
+		var items []any
+		if items, ok = value.([]any); ok && property.Items != nil {
+			for i, item := range items {
+				if err := validateValue(fmt.Sprintf("%s[%d]", path, i), *property.Items, item); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		var fields map[string]any
+		if fields, ok = value.(map[string]any); ok && property.Properties != nil {
+			return validateObject(path, property.Properties, property.Required, fields)
+		}
+	}
+	if !ok {
+		return fmt.Errorf("Invalid '%s' parameter: expected %s, got %s", path, property.Type, jsonTypeName(value))
+	}
+	return nil
+}
+
+// intArg reads the optional integer argument name, from lo to hi, or def
+// when it is absent.
+func intArg(args map[string]any, name string, def, lo, hi int) (int, *Error) {
+	v, ok := args[name]
+	if !ok {
+		return def, nil
+	}
+	n, ok := v.(float64)
+	if !ok || n != math.Trunc(n) || n < float64(lo) || n > float64(hi) {
+		expected := fmt.Sprintf("an integer from %d to %d", lo, hi)
+		if lo == 1 && hi == math.MaxInt {
+			expected = "a positive integer"
+		}
+		return 0, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Invalid '%s' parameter: expected %s", name, expected),
+		}
+	}
+	return int(n), nil
+}
+
+// argumentPath names the field name of the object argument at path.
+func argumentPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonTypeName names the JSON type of a decoded value for error messages.
+func jsonTypeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
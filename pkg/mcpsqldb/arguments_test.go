@@ -0,0 +1,127 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestValidateArguments(t *testing.T) {
+	defer func(orig int) { MaxArgumentBytes = orig }(MaxArgumentBytes)
+	MaxArgumentBytes = 16
+	schema := InputSchema{
+		Type: "object",
+		Properties: map[string]Property{
+			"sql":   {Type: "string"},
+			"limit": {Type: "integer"},
+			"exact": {Type: "boolean"},
+			"keys":  {Type: "array", Items: &Property{Type: "string"}},
+			"inputs": {Type: "array", Items: &Property{
+				Type:       "object",
+				Properties: map[string]Property{"table": {Type: "string"}},
+				Required:   []string{"table"},
+			}},
+		},
+		Required: []string{"sql"},
+	}
+
+	valid := map[string]any{
+		"sql":    "SELECT 1",
+		"limit":  float64(5),
+		"exact":  true,
+		"keys":   []any{"id"},
+		"inputs": []any{map[string]any{"table": "t"}},
+	}
+	if err := validateArguments(schema, valid); err != nil {
+		t.Errorf("Expected valid arguments to pass, got %v", err.Message)
+	}
+
+	invalid := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{}, "Missing required 'sql' parameter"},
+		{map[string]any{"sql": "SELECT 1", "sqll": "x"}, "Unknown 'sqll' parameter"},
+		{map[string]any{"sql": 1.0}, "Invalid 'sql' parameter: expected string, got integer"},
+		{map[string]any{"sql": nil}, "expected string, got null"},
+		{map[string]any{"sql": "SELECT 1", "limit": 2.5}, "Invalid 'limit' parameter: expected integer, got number"},
+		{map[string]any{"sql": "SELECT 1", "limit": "5"}, "expected integer, got string"},
+		{map[string]any{"sql": "SELECT * FROM users"}, "Invalid 'sql' parameter: longer than 16 bytes"},
+		{map[string]any{"sql": "SELECT 1", "keys": []any{"id", 2.0}}, "Invalid 'keys[1]' parameter"},
+		{map[string]any{"sql": "SELECT 1", "inputs": []any{map[string]any{}}}, "Missing required 'inputs[0].table' parameter"},
+		{map[string]any{"sql": "SELECT 1", "inputs": []any{map[string]any{"table": "t", "x": 1.0}}}, "Unknown 'inputs[0].x' parameter"},
+	}
+	for _, tc := range invalid {
+		err := validateArguments(schema, tc.args)
+		if err == nil || err.Code != InvalidParams || !strings.Contains(err.Message, tc.want) {
+			t.Errorf("Expected %v to fail with %q, got %v", tc.args, tc.want, err)
+		}
+	}
+}
+
+func TestIntArg(t *testing.T) {
+	if n, err := intArg(map[string]any{}, "limit", 7, 1, math.MaxInt); n != 7 || err != nil {
+		t.Errorf("Expected the default, got %d %v", n, err)
+	}
+	if n, err := intArg(map[string]any{"decimals": 0.0}, "decimals", 2, 0, 20); n != 0 || err != nil {
+		t.Errorf("Expected 0, got %d %v", n, err)
+	}
+
+	invalid := []struct {
+		arg    any
+		lo, hi int
+		want   string
+	}{
+		{0.0, 1, math.MaxInt, "Invalid 'n' parameter: expected a positive integer"},
+		{2.5, 1, math.MaxInt, "expected a positive integer"},
+		{"5", 1, math.MaxInt, "expected a positive integer"},
+		{1e300, 1, math.MaxInt, "expected a positive integer"},
+		{21.0, 0, 20, "Invalid 'n' parameter: expected an integer from 0 to 20"},
+	}
+	for _, tc := range invalid {
+		_, err := intArg(map[string]any{"n": tc.arg}, "n", 1, tc.lo, tc.hi)
+		if err == nil || err.Code != InvalidParams || !strings.Contains(err.Message, tc.want) {
+			t.Errorf("Expected %v to fail with %q, got %v", tc.arg, tc.want, err)
+		}
+	}
+}
+
+func TestHandleCallTool_ValidatesArguments(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	params, _ := json.Marshal(CallToolParams{Name: "sample_rows", Arguments: map[string]any{"table": "users", "limt": 2}})
+	if _, _, rpcErr := server.handleCallTool(ctx, params); rpcErr == nil || rpcErr.Code != InvalidParams || rpcErr.Message != "Unknown 'limt' parameter" {
+		t.Errorf("Expected the misspelled argument to be rejected, got %v", rpcErr)
+	}
+
+	params, _ = json.Marshal(CallToolParams{Name: "sample_rows", Arguments: map[string]any{"table": "users", "limit": 2}})
+	if _, result, rpcErr := server.handleCallTool(ctx, params); rpcErr != nil || result.IsError {
+		t.Errorf("Expected valid arguments to run the tool, got %v %v", rpcErr, result)
+	}
+}
+
+func TestHandleMessage_RequestTooLarge(t *testing.T) {
+	defer func(orig int) { MaxRequestBytes = orig }(MaxRequestBytes)
+	MaxRequestBytes = 64
+	server := newTestServer(t)
+
+	line := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT 1"}}}`
+	reader := bufio.NewReaderSize(strings.NewReader(line+"\n"+`{"jsonrpc":"2.0","id":2,"method":"ping"}`+"\n"), 16)
+	read, err := readLine(reader, MaxRequestBytes)
+	if err != nil || len(read) != MaxRequestBytes+1 {
+		t.Fatalf("Expected the line cut to %d bytes, got %d (err %v)", MaxRequestBytes+1, len(read), err)
+	}
+	resp := server.handleMessage([]byte(read))
+	if resp == nil || resp.Error == nil || resp.Error.Code != InvalidRequest {
+		t.Errorf("Expected an Invalid Request error, got %+v", resp)
+	}
+
+	// The rest of the oversized line is skipped
+	if next, err := readLine(reader, MaxRequestBytes); err != nil || !strings.Contains(next, `"id":2`) {
+		t.Errorf("Expected the next message to be read whole, got %q (err %v)", next, err)
+	}
+}
@@ -0,0 +1,86 @@
+package mcpsqldb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// ErrAsOfUnsupported is returned by DBAdapter.AsOfSampleQuery for servers
+// that keep no row history.
+var ErrAsOfUnsupported = errors.New("reading a table as of a past time is not supported by this database")
+
+// Temporal query syntaxes accepted by validateTemporalClauses
+const (
+	temporalSyntaxNone        = iota
+	temporalSyntaxSystemTime  // MariaDB: FROM t FOR SYSTEM_TIME AS OF ...
+	temporalSyntaxAsOfSysTime // CockroachDB: FROM t AS OF SYSTEM TIME ...
+)
+
+var (
+	forSystemTimePattern  = regexp.MustCompile(`(?i)\bFOR\s+SYSTEM_TIME\b`)
+	asOfSystemTimePattern = regexp.MustCompile(`(?i)\bAS\s+OF\s+SYSTEM\s+TIME\b`)
+
+	// systemTimeRangePattern matches what may follow FOR SYSTEM_TIME
+	systemTimeRangePattern = regexp.MustCompile(`(?i)^\s*(?:ALL\b|AS\s+OF\b|BETWEEN\b|FROM\b)`)
+
+	// asOfSystemTimeArgPattern matches what may follow AS OF SYSTEM TIME: a
+	// string literal (emptied by RemoveStringsAndComments), a number, or one
+	// of the timestamp functions CockroachDB accepts there
+	asOfSystemTimeArgPattern = regexp.MustCompile(`(?i)^\s*(?:''|[-+]?\d+(?:\.\d+)?\b|(?:follower_read_timestamp|with_min_timestamp|with_max_staleness)\s*\()`)
+)
+
+// validateTemporalClauses checks the temporal clauses of cleanedSQL against
+// the syntax the database accepts, so a clause of the wrong dialect is
+// rejected with a hint rather than a syntax error.
+func validateTemporalClauses(cleanedSQL string, syntax int) error {
+	for _, loc := range forSystemTimePattern.FindAllStringIndex(cleanedSQL, -1) {
+		if syntax != temporalSyntaxSystemTime {
+			return temporalSyntaxError("FOR SYSTEM_TIME", syntax)
+		}
+		if !systemTimeRangePattern.MatchString(cleanedSQL[loc[1]:]) {
+			return fmt.Errorf("FOR SYSTEM_TIME must be followed by AS OF, BETWEEN, FROM or ALL")
+		}
+	}
+
+	locs := asOfSystemTimePattern.FindAllStringIndex(cleanedSQL, -1)
+	if len(locs) > 0 && syntax != temporalSyntaxAsOfSysTime {
+		return temporalSyntaxError("AS OF SYSTEM TIME", syntax)
+	}
+	if len(locs) > 1 {
+		return fmt.Errorf("AS OF SYSTEM TIME may appear only once, on the outermost SELECT")
+	}
+	for _, loc := range locs {
+		if !asOfSystemTimeArgPattern.MatchString(cleanedSQL[loc[1]:]) {
+			return fmt.Errorf("AS OF SYSTEM TIME must be followed by a timestamp or interval literal, " +
+				"follower_read_timestamp(), with_min_timestamp() or with_max_staleness()")
+		}
+	}
+	return nil
+}
+
+// temporalSyntaxError rejects a temporal clause the database does not
+// accept, naming the one it does.
+func temporalSyntaxError(clause string, syntax int) error {
+	switch syntax {
+	case temporalSyntaxSystemTime:
+		return fmt.Errorf("%s is not supported here; use FOR SYSTEM_TIME AS OF on system-versioned tables", clause)
+	case temporalSyntaxAsOfSysTime:
+		return fmt.Errorf("%s is not supported here; use AS OF SYSTEM TIME", clause)
+	default:
+		return fmt.Errorf("%s is not supported by this database", clause)
+	}
+}
+
+// parseAsOf parses the as_of argument, an RFC 3339 timestamp in the past.
+func parseAsOf(value string, now time.Time) (time.Time, error) {
+	at, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected an RFC 3339 timestamp such as 2024-05-01T12:00:00Z")
+	}
+	if at.After(now) {
+		return time.Time{}, fmt.Errorf("%s is in the future", value)
+	}
+	return at.UTC(), nil
+}
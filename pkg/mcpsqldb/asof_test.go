@@ -0,0 +1,80 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateTemporalClauses(t *testing.T) {
+	tests := []struct {
+		adapter DBAdapter
+		sql     string
+		wantErr string
+	}{
+		{&MySQLAdapter{}, "SELECT * FROM t FOR SYSTEM_TIME AS OF TIMESTAMP '2024-05-01 12:00:00'", ""},
+		{&MySQLAdapter{}, "SELECT * FROM t FOR SYSTEM_TIME BETWEEN '2024-01-01' AND '2024-02-01'", ""},
+		{&MySQLAdapter{}, "SELECT * FROM t FOR SYSTEM_TIME ALL WHERE id = 1", ""},
+		{&MySQLAdapter{}, "SELECT * FROM t FOR SYSTEM_TIME '2024-05-01'", "must be followed by AS OF"},
+		{&MySQLAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME '-10s'", "use FOR SYSTEM_TIME AS OF"},
+		{&PostgresAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME '-10s' WHERE id = 1", ""},
+		{&PostgresAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME follower_read_timestamp()", ""},
+		{&PostgresAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME with_max_staleness('10s') WHERE id = 1", ""},
+		{&PostgresAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME 1714564800000000000", ""},
+		{&PostgresAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME (SELECT max(ts) FROM u)", "must be followed by a timestamp"},
+		{&PostgresAdapter{}, "SELECT * FROM (SELECT * FROM t AS OF SYSTEM TIME '-1s') x AS OF SYSTEM TIME '-1s'", "only once"},
+		{&PostgresAdapter{}, "SELECT * FROM t FOR SYSTEM_TIME AS OF '2024-05-01'", "use AS OF SYSTEM TIME"},
+		{&SQLiteAdapter{}, "SELECT * FROM t AS OF SYSTEM TIME '-10s'", "not supported by this database"},
+		// Inside literals and comments the clauses are only text
+		{&SQLiteAdapter{}, "SELECT 'FOR SYSTEM_TIME' AS note -- AS OF SYSTEM TIME", ""},
+	}
+	for _, tt := range tests {
+		err := tt.adapter.ValidateQuery(tt.sql)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("Expected %s to be allowed, got %v", tt.sql, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("Expected %s to be rejected with %q, got %v", tt.sql, tt.wantErr, err)
+		}
+	}
+}
+
+func TestParseAsOf(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	at, err := parseAsOf("2024-05-01T13:30:00+02:00", now)
+	if err != nil || !at.Equal(time.Date(2024, 5, 1, 11, 30, 0, 0, time.UTC)) || at.Location() != time.UTC {
+		t.Errorf("Expected 11:30 UTC, got %v %v", at, err)
+	}
+	for _, value := range []string{"", "2024-05-01", "2024-05-01 11:00:00", "2024-05-02T00:00:00Z"} {
+		if _, err := parseAsOf(value, now); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestSampleRows_AsOf(t *testing.T) {
+	server := newTestServer(t)
+
+	if _, rpcErr := server.sampleRows(context.Background(), map[string]any{"table": "users", "as_of": "yesterday"}); rpcErr == nil {
+		t.Errorf("Expected invalid params error for a malformed as_of")
+	}
+
+	result, rpcErr := server.sampleRows(context.Background(), map[string]any{"table": "users", "as_of": "2024-05-01T12:00:00Z"})
+	if rpcErr != nil || !result.IsError || !strings.Contains(result.Content[0].Text, ErrAsOfUnsupported.Error()) {
+		t.Errorf("Expected SQLite to report as_of unsupported, got %v %+v", rpcErr, result)
+	}
+}
+
+func TestAsOfSampleQuery_Postgres(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	if _, err := (&PostgresAdapter{}).AsOfSampleQuery(context.Background(), nil, "users", at, 5); !errors.Is(err, ErrAsOfUnsupported) {
+		t.Errorf("Expected PostgreSQL to report as_of unsupported, got %v", err)
+	}
+	query, err := (&CockroachAdapter{}).AsOfSampleQuery(context.Background(), nil, "users", at, 5)
+	if err != nil || !strings.Contains(query, "AS OF SYSTEM TIME '2024-05-01 12:00:00+00:00'") {
+		t.Errorf("Expected CockroachDB to read AS OF SYSTEM TIME, got %q %v", query, err)
+	}
+}
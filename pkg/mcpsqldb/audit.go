@@ -0,0 +1,282 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// AuditLogPath is a file that receives one JSON record per query attempt;
+// empty disables the audit log (overridable via MCP_AUDIT_LOG env var)
+var AuditLogPath string
+
+// AuditChain links each audit record to the previous one by hash so edits,
+// deletions and reordering are detectable (overridable via MCP_AUDIT_CHAIN
+// env var)
+var AuditChain = false
+
+// AuditCheckpointEvery is the number of chained records between checkpoints
+// (overridable via MCP_AUDIT_CHECKPOINT_EVERY env var)
+var AuditCheckpointEvery = 100
+
+// Audit record events and query outcomes
+const (
+	AuditEventQuery      = "query"
+	AuditEventCheckpoint = "checkpoint"
+
+	AuditOutcomeOK       = "ok"
+	AuditOutcomeError    = "error"
+	AuditOutcomeRejected = "rejected"
+)
+
+// maxAuditLineBytes bounds a single record when reading an existing log.
+const maxAuditLineBytes = 16 << 20
+
+// auditHashField precedes the hash that closes every chained record.
+const auditHashField = `,"hash":"`
+
+type requestIDKey struct{}
+
+type auditRecord struct {
+	Seq         int64  `json:"seq"`
+	Time        string `json:"time"`
+	Event       string `json:"event"`
+	SessionID   string `json:"session_id,omitempty"`
+	RequestID   any    `json:"request_id,omitempty"`
+	Principal   string `json:"principal,omitempty"`
+	SQL         string `json:"sql,omitempty"`
+	Params      []any  `json:"params,omitempty"`
+	Digest      string `json:"digest,omitempty"`
+	Fingerprint string `json:"fingerprint,omitempty"`
+	Outcome     string `json:"outcome,omitempty"`
+	Rows        int    `json:"rows,omitempty"`
+	DurationMS  int64  `json:"duration_ms,omitempty"`
+	Error       string `json:"error,omitempty"`
+	PrevHash    string `json:"prev_hash,omitempty"`
+}
+
+// auditLog appends audit records to a file. With chaining, every record
+// carries the previous record's hash and ends with its own,
+//
+//	{...,"prev_hash":"<previous>","hash":"<sha256 of the line up to here + '}'>"}
+//
+// and every AuditCheckpointEvery records a checkpoint record is written and
+// its hash logged, so the head of the chain is also held outside the file.
+// A nil *auditLog discards records.
+type auditLog struct {
+	mu              sync.Mutex
+	f               *os.File
+	chain           bool
+	checkpointEvery int
+	seq             int64
+	prevHash        string
+	sinceCheckpoint int
+}
+
+// newAuditLog opens path for appending. A chained log resumes from the hash
+// of its last record.
+func newAuditLog(path string, chain bool, checkpointEvery int) (*auditLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	a := &auditLog{f: f, chain: chain, checkpointEvery: checkpointEvery}
+
+	last, err := lastLine(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	if len(last) > 0 {
+		body, hash, chained := splitAuditHash(last)
+		var rec auditRecord
+		if err := json.Unmarshal(body, &rec); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("audit log %s ends with a malformed record", path)
+		}
+		if chain && !chained {
+			f.Close()
+			return nil, fmt.Errorf("audit log %s is not hash-chained; use a new file for MCP_AUDIT_CHAIN", path)
+		}
+		a.seq, a.prevHash = rec.Seq, hash
+	}
+	return a, nil
+}
+
+// lastLine returns the final non-empty line of f.
+func lastLine(f *os.File) ([]byte, error) {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64<<10), maxAuditLineBytes)
+	var last []byte
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	return last, scanner.Err()
+}
+
+// splitAuditHash separates a chained line into the hashed body and its hash.
+func splitAuditHash(line []byte) ([]byte, string, bool) {
+	idx := bytes.LastIndex(line, []byte(auditHashField))
+	if idx < 0 || !bytes.HasSuffix(line, []byte(`"}`)) {
+		return line, "", false
+	}
+	body := append(append([]byte{}, line[:idx]...), '}')
+	return body, string(line[idx+len(auditHashField) : len(line)-2]), true
+}
+
+// query records one query attempt, in adapter's dialect and with the params
+// bound to it, made while handling ctx.
+func (a *auditLog) query(ctx context.Context, adapter DBAdapter, sessionID, sqlQuery, outcome string, rows int, duration time.Duration, detail string, params ...any) {
+	if a == nil {
+		return
+	}
+	requestID := ctx.Value(requestIDKey{})
+	digest := queryDigest(adapter, sqlQuery)
+	a.write(auditRecord{
+		Event:       AuditEventQuery,
+		SessionID:   sessionID,
+		RequestID:   requestID,
+		Principal:   principalFrom(ctx),
+		SQL:         recordedSQL(adapter, sqlQuery),
+		Params:      recordedParams(params),
+		Digest:      digest,
+		Fingerprint: queryFingerprint(digest),
+		Outcome:     outcome,
+		Rows:        rows,
+		DurationMS:  duration.Milliseconds(),
+		Error:       redactSecrets(detail),
+	})
+}
+
+func (a *auditLog) write(rec auditRecord) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.append(rec)
+	if a.chain && rec.Event != AuditEventCheckpoint {
+		a.sinceCheckpoint++
+		if a.checkpointEvery > 0 && a.sinceCheckpoint >= a.checkpointEvery {
+			a.checkpoint()
+		}
+	}
+}
+
+// append writes rec as the next record; a.mu must be held.
+func (a *auditLog) append(rec auditRecord) {
+	a.seq++
+	rec.Seq = a.seq
+	rec.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	if a.chain {
+		rec.PrevHash = a.prevHash
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		slog.Error("Failed to encode audit record", "error", err)
+		return
+	}
+	if a.chain {
+		sum := sha256.Sum256(line)
+		a.prevHash = hex.EncodeToString(sum[:])
+		line = append(line[:len(line)-1], auditHashField+a.prevHash+`"}`...)
+	}
+	if _, err := a.f.Write(append(line, '\n')); err != nil {
+		slog.Error("Failed to write audit record", "error", err)
+	}
+}
+
+// checkpoint writes a checkpoint record and logs the chain head; a.mu must
+// be held.
+func (a *auditLog) checkpoint() {
+	a.append(auditRecord{Event: AuditEventCheckpoint})
+	a.sinceCheckpoint = 0
+	slog.Info("Audit checkpoint", "seq", a.seq, "hash", a.prevHash)
+}
+
+// Close writes a final checkpoint for chained logs and closes the file.
+func (a *auditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.chain && a.sinceCheckpoint > 0 {
+		a.checkpoint()
+	}
+	return a.f.Close()
+}
+
+// verifyAuditLog checks that every record in r is chained to its
+// predecessor with a matching hash and consecutive sequence numbers. It
+// returns the number of records and the final hash, which can be compared
+// with the last logged checkpoint to detect truncation.
+func verifyAuditLog(r io.Reader) (int64, string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxAuditLineBytes)
+
+	var count int64
+	var prevHash string
+	var prevSeq int64
+	for line := 1; scanner.Scan(); line++ {
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		body, hash, chained := splitAuditHash(raw)
+		if !chained {
+			return count, prevHash, fmt.Errorf("line %d: record is not hash-chained", line)
+		}
+		var rec auditRecord
+		if err := json.Unmarshal(body, &rec); err != nil {
+			return count, prevHash, fmt.Errorf("line %d: malformed record: %w", line, err)
+		}
+		sum := sha256.Sum256(body)
+		switch {
+		case hex.EncodeToString(sum[:]) != hash:
+			return count, prevHash, fmt.Errorf("line %d: hash mismatch, record was modified", line)
+		case rec.PrevHash != prevHash:
+			return count, prevHash, fmt.Errorf("line %d: previous hash mismatch, records were removed or reordered", line)
+		case count > 0 && rec.Seq != prevSeq+1:
+			return count, prevHash, fmt.Errorf("line %d: sequence %d follows %d", line, rec.Seq, prevSeq)
+		}
+		prevHash, prevSeq = hash, rec.Seq
+		count++
+	}
+	return count, prevHash, scanner.Err()
+}
+
+// runVerifyAudit implements the verify-audit subcommand and returns the
+// process exit code.
+func runVerifyAudit(args []string) int {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "usage: readonly-mcp-server verify-audit <file>")
+		return 2
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Println("invalid:", err)
+		return 1
+	}
+	defer f.Close()
+
+	count, head, err := verifyAuditLog(f)
+	if err != nil {
+		fmt.Println("invalid:", err)
+		return 1
+	}
+	fmt.Printf("valid: %d records, head %s\n", count, head)
+	return 0
+}
@@ -0,0 +1,140 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readAuditRecords(t *testing.T, path string) []auditRecord {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read audit log: %v", err)
+	}
+	var records []auditRecord
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var rec auditRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatalf("Malformed audit line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestAuditLog_RecordsQueries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	server := newTestServer(t)
+	audit, err := newAuditLog(path, false, 0)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	server.audit = audit
+	ctx := context.WithValue(context.Background(), requestIDKey{}, 7)
+
+	server.executeQuery(ctx, map[string]any{"sql": "SELECT * FROM users"})
+	server.executeQuery(ctx, map[string]any{"sql": "DELETE FROM users"})
+	server.executeQuery(ctx, map[string]any{"sql": "SELECT * FROM missing"})
+	audit.Close()
+
+	records := readAuditRecords(t, path)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 audit records, got %d", len(records))
+	}
+	expected := []string{AuditOutcomeOK, AuditOutcomeRejected, AuditOutcomeError}
+	for i, rec := range records {
+		if rec.Outcome != expected[i] || rec.Seq != int64(i+1) || rec.SessionID != server.sessionID {
+			t.Errorf("Record %d: unexpected %+v", i, rec)
+		}
+	}
+	if records[0].Rows != 3 || records[0].RequestID != float64(7) {
+		t.Errorf("Expected 3 rows for request 7, got %+v", records[0])
+	}
+	if records[2].Digest != "SELECT * FROM missing" || records[2].Fingerprint != queryFingerprint(records[2].Digest) {
+		t.Errorf("Expected the query digest and fingerprint, got %+v", records[2])
+	}
+}
+
+func TestAuditLog_ChainVerifiesAndDetectsTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := newAuditLog(path, true, 2)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	ctx := context.Background()
+	for _, sql := range []string{"SELECT 1", "SELECT 2", "SELECT 3"} {
+		audit.query(ctx, &SQLiteAdapter{}, "s1", sql, AuditOutcomeOK, 1, 0, "")
+	}
+	audit.Close()
+
+	// Reopening continues the chain
+	audit, err = newAuditLog(path, true, 2)
+	if err != nil {
+		t.Fatalf("Failed to reopen audit log: %v", err)
+	}
+	audit.query(ctx, &SQLiteAdapter{}, "s2", "SELECT 4", AuditOutcomeOK, 1, 0, "")
+	audit.Close()
+
+	data, _ := os.ReadFile(path)
+	count, head, err := verifyAuditLog(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Expected intact chain, got %v", err)
+	}
+	// 4 queries, a checkpoint after every 2 and one on each close
+	if count != 7 || head == "" {
+		t.Errorf("Expected 7 records, got %d (head %q)", count, head)
+	}
+	var checkpoints int
+	for _, rec := range readAuditRecords(t, path) {
+		if rec.Event == AuditEventCheckpoint {
+			checkpoints++
+		}
+	}
+	if checkpoints != 3 {
+		t.Errorf("Expected 3 checkpoints, got %d", checkpoints)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	tampered := map[string][]string{
+		"edited":  append(append([]string{}, lines[:1]...), append([]string{strings.Replace(lines[1], "SELECT 2", "SELECT 9", 1)}, lines[2:]...)...),
+		"removed": append(append([]string{}, lines[:1]...), lines[2:]...),
+	}
+	for name, l := range tampered {
+		if _, _, err := verifyAuditLog(strings.NewReader(strings.Join(l, "\n"))); err == nil {
+			t.Errorf("%s: expected verification to fail", name)
+		}
+	}
+}
+
+func TestNewAuditLog_RefusesUnchainedFileForChain(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, _ := newAuditLog(path, false, 0)
+	audit.query(context.Background(), &SQLiteAdapter{}, "s1", "SELECT 1", AuditOutcomeOK, 1, 0, "")
+	audit.Close()
+
+	if _, err := newAuditLog(path, true, 0); err == nil {
+		t.Error("Expected chaining onto an unchained log to fail")
+	}
+}
+
+func TestAuditLog_RedactsLiterals(t *testing.T) {
+	defer func(orig bool) { RedactSQLLiterals = orig }(RedactSQLLiterals)
+	RedactSQLLiterals = true
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := newAuditLog(path, false, 0)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	audit.query(context.Background(), &PostgresAdapter{}, "s1", "SELECT * FROM users WHERE email = 'a@b.c' AND id = 42", AuditOutcomeOK, 1, 0, "")
+	audit.Close()
+
+	rec := readAuditRecords(t, path)[0]
+	if rec.SQL != "SELECT * FROM users WHERE email = ? AND id = ?" || strings.Contains(rec.Digest, "a@b.c") {
+		t.Errorf("Expected literals redacted, got %+v", rec)
+	}
+}
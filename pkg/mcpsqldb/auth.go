@@ -0,0 +1,267 @@
+package mcpsqldb
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthTokens are the static bearer tokens the http transport accepts
+// (overridable via MCP_AUTH_TOKENS env var, comma-separated)
+var AuthTokens []string
+
+// OIDCIssuer, when set, makes the http transport accept JWT access tokens
+// signed by this OIDC provider and issued for OIDCAudience (overridable via
+// MCP_OIDC_ISSUER and MCP_OIDC_AUDIENCE env vars)
+var (
+	OIDCIssuer   = ""
+	OIDCAudience = ""
+)
+
+// jwksRefreshInterval rate-limits JWKS re-fetches triggered by unknown key IDs.
+const jwksRefreshInterval = time.Minute
+
+// tokenClockSkew is tolerated when checking exp and nbf.
+const tokenClockSkew = time.Minute
+
+type principalKey struct{}
+
+// principalFrom returns the authenticated caller stored by the auth
+// middleware, or "" for unauthenticated requests.
+func principalFrom(ctx context.Context) string {
+	p, _ := ctx.Value(principalKey{}).(string)
+	return p
+}
+
+// authenticator checks bearer tokens on HTTP requests against static tokens
+// and, when configured, an OIDC provider, following the MCP authorization
+// spec (RFC 6750 bearer tokens, RFC 9728 protected resource metadata).
+type authenticator struct {
+	tokens              []string
+	oidc                *oidcVerifier
+	resourceMetadataURL string
+}
+
+// newAuthenticator returns nil when no tokens or issuer are configured.
+// resourceMetadataURL is advertised to clients in 401 responses. OIDC tokens
+// must be issued for audience, so tokens minted for other services are not
+// accepted here.
+func newAuthenticator(tokens []string, issuer, audience, resourceMetadataURL string) (*authenticator, error) {
+	if len(tokens) == 0 && issuer == "" {
+		return nil, nil
+	}
+	a := &authenticator{tokens: tokens, resourceMetadataURL: resourceMetadataURL}
+	if issuer != "" {
+		if audience == "" {
+			return nil, fmt.Errorf("an OIDC audience is required with an OIDC issuer")
+		}
+		a.oidc = &oidcVerifier{issuer: strings.TrimSuffix(issuer, "/"), audience: audience}
+	}
+	return a, nil
+}
+
+// middleware rejects requests without a valid bearer token and records the
+// caller for the handler.
+func (a *authenticator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			a.challenge(w, "")
+			return
+		}
+
+		principal, err := a.authenticate(r.Context(), token)
+		if err != nil {
+			loggerFrom(r.Context()).Warn("Rejected HTTP request", "remote", r.RemoteAddr, "error", err)
+			a.challenge(w, "invalid_token")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
+
+func (a *authenticator) authenticate(ctx context.Context, token string) (string, error) {
+	for i, t := range a.tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			return fmt.Sprintf("static-token-%d", i+1), nil
+		}
+	}
+	if a.oidc != nil {
+		return a.oidc.verify(ctx, token)
+	}
+	return "", fmt.Errorf("unknown bearer token")
+}
+
+func (a *authenticator) challenge(w http.ResponseWriter, errCode string) {
+	params := []string{}
+	if errCode != "" {
+		params = append(params, fmt.Sprintf("error=%q", errCode))
+	}
+	if a.resourceMetadataURL != "" {
+		params = append(params, fmt.Sprintf("resource_metadata=%q", a.resourceMetadataURL))
+	}
+	header := "Bearer"
+	if len(params) > 0 {
+		header += " " + strings.Join(params, ", ")
+	}
+	w.Header().Set("WWW-Authenticate", header)
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+// protectedResourceMetadata serves the RFC 9728 document pointing clients at
+// the authorization server.
+func (a *authenticator) protectedResourceMetadata(resource string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc := map[string]any{
+			"resource":                 resource,
+			"bearer_methods_supported": []string{"header"},
+		}
+		if a.oidc != nil {
+			doc["authorization_servers"] = []string{a.oidc.issuer}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(doc)
+	})
+}
+
+// oidcVerifier validates RS256/ES256 JWT access tokens against the issuer's
+// published signing keys.
+type oidcVerifier struct {
+	issuer   string
+	audience string
+
+	mu        sync.Mutex
+	keys      map[string]crypto.PublicKey
+	fetchedAt time.Time
+}
+
+// verify checks the token signature and standard claims, returning the
+// principal it authenticates: oidc:<issuer>:<subject>, so an OIDC subject
+// can never pass for a static token or another issuer's subject.
+func (v *oidcVerifier) verify(ctx context.Context, token string) (string, error) {
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(v.issuer),
+		jwt.WithAudience(v.audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(tokenClockSkew),
+	)
+	if err != nil {
+		return "", err
+	}
+	sub, err := parsed.Claims.GetSubject()
+	if err != nil {
+		return "", err
+	}
+	if sub == "" {
+		return "", fmt.Errorf("token has no subject")
+	}
+	return "oidc:" + v.issuer + ":" + sub, nil
+}
+
+// key returns the signing key with the given ID, re-fetching the JWKS (at
+// most once per jwksRefreshInterval) when it is unknown, e.g. after rotation.
+func (v *oidcVerifier) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if key, ok := v.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(v.fetchedAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	keys, err := fetchJWKS(ctx, v.issuer)
+	v.fetchedAt = time.Now()
+	if err != nil {
+		return nil, err
+	}
+	v.keys = keys
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// fetchJWKS discovers the issuer's jwks_uri and loads its RSA and P-256 keys.
+func fetchJWKS(ctx context.Context, issuer string) (map[string]crypto.PublicKey, error) {
+	var discovery struct {
+		Issuer  string `json:"issuer"`
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := getJSON(ctx, issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, issuer)
+	}
+
+	var jwks struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			Kty string `json:"kty"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}
+	if err := getJSON(ctx, discovery.JWKSURI, &jwks); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey)
+	for _, k := range jwks.Keys {
+		switch {
+		case k.Kty == "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil {
+				continue
+			}
+			keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		case k.Kty == "EC" && k.Crv == "P-256":
+			x, errX := base64.RawURLEncoding.DecodeString(k.X)
+			y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+			if errX != nil || errY != nil {
+				continue
+			}
+			keys[k.Kid] = &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		}
+	}
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
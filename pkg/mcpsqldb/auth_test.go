@@ -0,0 +1,181 @@
+package mcpsqldb
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testIssuer is a fake OIDC provider signing RS256 tokens.
+type testIssuer struct {
+	url string
+	key *rsa.PrivateKey
+}
+
+func startTestIssuer(t *testing.T) *testIssuer {
+	t.Helper()
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	iss := &testIssuer{key: key}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	iss.url = srv.URL
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"issuer": iss.url, "jwks_uri": iss.url + "/jwks"})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+			"kid": "k1",
+			"kty": "RSA",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	return iss
+}
+
+func (iss *testIssuer) token(t *testing.T, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "k1"})
+	body, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(body)
+	digest := sha256.Sum256([]byte(signed))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, iss.key, crypto.SHA256, digest[:])
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func authStatus(handler http.Handler, token string) (int, string) {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String() + rec.Header().Get("WWW-Authenticate")
+}
+
+func TestAuthenticator_StaticTokens(t *testing.T) {
+	auth, err := newAuthenticator([]string{"secret-token"}, "", "", "https://mcp.example.com/.well-known/oauth-protected-resource")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handler := auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(principalFrom(r.Context())))
+	}))
+
+	if code, body := authStatus(handler, "secret-token"); code != http.StatusOK || body != "static-token-1" {
+		t.Errorf("Expected valid token to pass, got %d %q", code, body)
+	}
+
+	code, body := authStatus(handler, "")
+	if code != http.StatusUnauthorized || !strings.Contains(body, `resource_metadata="https://mcp.example.com/`) {
+		t.Errorf("Expected 401 with resource metadata challenge, got %d %q", code, body)
+	}
+
+	if code, body := authStatus(handler, "wrong"); code != http.StatusUnauthorized || !strings.Contains(body, `error="invalid_token"`) {
+		t.Errorf("Expected 401 invalid_token, got %d %q", code, body)
+	}
+}
+
+func TestAuthenticator_DisabledWithoutConfig(t *testing.T) {
+	auth, err := newAuthenticator(nil, "", "", "")
+	if auth != nil || err != nil {
+		t.Errorf("Expected no authenticator, got %v (err %v)", auth, err)
+	}
+	if _, err := newAuthenticator(nil, "https://issuer.example.com", "", ""); err == nil {
+		t.Error("Expected error for OIDC issuer without audience")
+	}
+}
+
+func TestAuthenticator_OIDC(t *testing.T) {
+	iss := startTestIssuer(t)
+	auth, err := newAuthenticator(nil, iss.url, "mcp-sql", "")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	handler := auth.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(principalFrom(r.Context())))
+	}))
+
+	now := time.Now().Unix()
+	valid := map[string]any{"iss": iss.url, "sub": "alice", "aud": []string{"other", "mcp-sql"}, "exp": now + 300}
+
+	tests := []struct {
+		name     string
+		mutate   func(map[string]any)
+		expected int
+	}{
+		{"valid", func(map[string]any) {}, http.StatusOK},
+		{"expired", func(c map[string]any) { c["exp"] = now - 3600 }, http.StatusUnauthorized},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "other" }, http.StatusUnauthorized},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, http.StatusUnauthorized},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = now + 3600 }, http.StatusUnauthorized},
+		{"no expiry", func(c map[string]any) { delete(c, "exp") }, http.StatusUnauthorized},
+		{"no subject", func(c map[string]any) { delete(c, "sub") }, http.StatusUnauthorized},
+		{"empty subject", func(c map[string]any) { c["sub"] = "" }, http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			claims := map[string]any{}
+			for k, v := range valid {
+				claims[k] = v
+			}
+			tc.mutate(claims)
+			if code, body := authStatus(handler, iss.token(t, claims)); code != tc.expected {
+				t.Errorf("Expected %d, got %d %q", tc.expected, code, body)
+			}
+		})
+	}
+
+	// Subjects are qualified by their issuer, so one cannot pass for a
+	// static token
+	if code, body := authStatus(handler, iss.token(t, valid)); code != http.StatusOK || body != "oidc:"+iss.url+":alice" {
+		t.Errorf("Expected the issuer-qualified principal, got %d %q", code, body)
+	}
+	valid["sub"] = "static-token-1"
+	if _, body := authStatus(handler, iss.token(t, valid)); body != "oidc:"+iss.url+":static-token-1" {
+		t.Errorf("Expected the subject to be qualified, got %q", body)
+	}
+
+	// A token signed by another key must not verify
+	forged := iss.token(t, valid)
+	parts := strings.Split(forged, ".")
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	sig, _ := rsa.SignPKCS1v15(rand.Reader, other, crypto.SHA256, digest[:])
+	forged = parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(sig)
+	if code, _ := authStatus(handler, forged); code != http.StatusUnauthorized {
+		t.Errorf("Expected forged token to be rejected, got %d", code)
+	}
+}
+
+func TestAuthenticator_ProtectedResourceMetadata(t *testing.T) {
+	auth, _ := newAuthenticator(nil, "https://issuer.example.com/", "mcp-sql", "")
+	rec := httptest.NewRecorder()
+	auth.protectedResourceMetadata("https://mcp.example.com/mcp").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var doc struct {
+		Resource             string   `json:"resource"`
+		AuthorizationServers []string `json:"authorization_servers"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Failed to parse metadata: %v", err)
+	}
+	if doc.Resource != "https://mcp.example.com/mcp" || len(doc.AuthorizationServers) != 1 || doc.AuthorizationServers[0] != "https://issuer.example.com" {
+		t.Errorf("Unexpected metadata %+v", doc)
+	}
+}
@@ -0,0 +1,439 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SecretARN names a Secrets Manager secret holding the DSN or credentials
+// (overridable via MCP_SECRET_ARN env var; a plain secret name also works)
+var SecretARN string
+
+// SSMParameter names an SSM Parameter Store parameter holding the DSN or
+// credentials (overridable via MCP_SSM_PARAMETER env var)
+var SSMParameter string
+
+// SecretRefreshInterval is how often the secret is re-read to pick up
+// rotation (overridable via MCP_SECRET_REFRESH env var, in seconds)
+var SecretRefreshInterval = 5 * time.Minute
+
+// cloudHTTPClient is used for outbound calls to cloud provider APIs,
+// credential endpoints, and identity providers.
+var cloudHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// ecsCredentialsHost serves AWS_CONTAINER_CREDENTIALS_RELATIVE_URI on ECS.
+const ecsCredentialsHost = "http://169.254.170.2"
+
+// awsCredentials are the signing credentials for AWS API requests.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// awsSecretSource reads connection settings from Secrets Manager or SSM
+// Parameter Store.
+type awsSecretSource struct {
+	service string // "secretsmanager" or "ssm"
+	id      string
+	region  string
+}
+
+// newAWSSecretSource returns the configured secret source, or nil if neither
+// MCP_SECRET_ARN nor MCP_SSM_PARAMETER is set.
+func newAWSSecretSource() (*awsSecretSource, error) {
+	var src *awsSecretSource
+	switch {
+	case SecretARN != "" && SSMParameter != "":
+		return nil, fmt.Errorf("MCP_SECRET_ARN and MCP_SSM_PARAMETER are mutually exclusive")
+	case SecretARN != "":
+		src = &awsSecretSource{service: "secretsmanager", id: SecretARN}
+	case SSMParameter != "":
+		src = &awsSecretSource{service: "ssm", id: SSMParameter}
+	default:
+		return nil, nil
+	}
+
+	src.region = awsRegion(src.id)
+	if src.region == "" {
+		return nil, fmt.Errorf("AWS region is unknown: set AWS_REGION or use a full ARN")
+	}
+	return src, nil
+}
+
+// awsRegion returns the configured region, falling back to the region
+// embedded in an ARN (arn:aws:service:region:account:...).
+func awsRegion(id string) string {
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	if r := os.Getenv("AWS_DEFAULT_REGION"); r != "" {
+		return r
+	}
+	if parts := strings.Split(id, ":"); len(parts) >= 6 && parts[0] == "arn" {
+		return parts[3]
+	}
+	return ""
+}
+
+// dsn fetches the secret and converts it to a DSN for adapter.
+func (src *awsSecretSource) dsn(ctx context.Context, adapter DBAdapter) (string, error) {
+	value, err := src.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	return dsnFromSecret(adapter, value)
+}
+
+// fetch returns the current secret string.
+func (src *awsSecretSource) fetch(ctx context.Context) (string, error) {
+	var target string
+	var payload map[string]any
+	switch src.service {
+	case "secretsmanager":
+		target = "secretsmanager.GetSecretValue"
+		payload = map[string]any{"SecretId": src.id}
+	default:
+		target = "AmazonSSM.GetParameter"
+		payload = map[string]any{"Name": src.id, "WithDecryption": true}
+	}
+
+	body, err := src.call(ctx, target, payload)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		SecretString string
+		Parameter    struct{ Value string }
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("failed to parse %s response: %w", target, err)
+	}
+	value := resp.SecretString
+	if src.service == "ssm" {
+		value = resp.Parameter.Value
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s returned no string value for %s", target, src.id)
+	}
+	return value, nil
+}
+
+// call issues a signed JSON 1.1 API request and returns the response body.
+func (src *awsSecretSource) call(ctx context.Context, target string, payload any) ([]byte, error) {
+	creds, err := loadAWSCredentials(ctx, src.region)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, awsEndpoint(src.service, src.region), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	signV4(req, body, creds, src.region, src.service, time.Now())
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", target, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s response: %w", target, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s failed: %s: %s", target, resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return respBody, nil
+}
+
+// watch re-reads the secret every SecretRefreshInterval until ctx is done,
+// calling update whenever the resulting DSN changes. Failures keep the
+// current DSN in use.
+func (src *awsSecretSource) watch(ctx context.Context, adapter DBAdapter, current string, update func(string) error) {
+	ticker := time.NewTicker(SecretRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		fetchCtx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+		dsn, err := src.dsn(fetchCtx, adapter)
+		cancel()
+		if err != nil {
+			slog.Warn("Failed to refresh database credentials, keeping current ones", "source", src.id, "error", err)
+			continue
+		}
+		if dsn == current {
+			continue
+		}
+		if err := update(dsn); err != nil {
+			slog.Warn("Failed to apply rotated database credentials", "source", src.id, "error", err)
+			continue
+		}
+		current = dsn
+		slog.Info("Database credentials rotated", "source", src.id)
+	}
+}
+
+// dsnFromSecret accepts either a plain DSN or a JSON object. JSON may carry a
+// "dsn" field, or the username/password/host/port/dbname fields used by RDS
+// managed secrets (server databases only).
+func dsnFromSecret(adapter DBAdapter, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") {
+		return value, nil
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("failed to parse secret JSON: %w", err)
+	}
+	field := func(name string) string {
+		switch v := fields[name].(type) {
+		case string:
+			return v
+		case float64:
+			return fmt.Sprintf("%d", int64(v))
+		}
+		return ""
+	}
+
+	if dsn := field("dsn"); dsn != "" {
+		return dsn, nil
+	}
+
+	p := ConnParams{
+		Host:     field("host"),
+		Port:     field("port"),
+		Database: field("dbname"),
+		User:     field("username"),
+		Password: field("password"),
+	}
+	registerSecret(p.Password)
+
+	var missing []string
+	for name, v := range map[string]string{"host": p.Host, "dbname": p.Database, "username": p.User, "password": p.Password} {
+		if v == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return "", fmt.Errorf("secret is missing fields: %v", missing)
+	}
+	return adapter.FormatDSN(p)
+}
+
+// awsEndpoint returns the API endpoint for service; AWS_ENDPOINT_URL
+// overrides it (e.g. for LocalStack or VPC endpoints).
+func awsEndpoint(service, region string) string {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		return endpoint
+	}
+	return fmt.Sprintf("https://%s.%s.amazonaws.com/", service, region)
+}
+
+// loadAWSCredentials resolves credentials the way the AWS SDKs do for
+// containers: static environment variables, then the ECS/EKS Pod Identity
+// container endpoint, then an EKS service account web identity token.
+func loadAWSCredentials(ctx context.Context, region string) (awsCredentials, error) {
+	if id := os.Getenv("AWS_ACCESS_KEY_ID"); id != "" {
+		return awsCredentials{
+			AccessKeyID:     id,
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, nil
+	}
+
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); uri != "" {
+		return containerCredentials(ctx, ecsCredentialsHost+uri)
+	}
+	if uri := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI"); uri != "" {
+		return containerCredentials(ctx, uri)
+	}
+
+	if tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"); tokenFile != "" {
+		return webIdentityCredentials(ctx, region, tokenFile, os.Getenv("AWS_ROLE_ARN"))
+	}
+
+	return awsCredentials{}, fmt.Errorf("no AWS credentials found (set AWS_ACCESS_KEY_ID, or run with an ECS task role or EKS service account)")
+}
+
+// containerCredentials fetches task credentials from the ECS or EKS Pod
+// Identity agent.
+func containerCredentials(ctx context.Context, endpoint string) (awsCredentials, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		b, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return awsCredentials{}, fmt.Errorf("failed to read container authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(b))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("container credentials request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, fmt.Errorf("container credentials request failed: %s", resp.Status)
+	}
+
+	var body struct {
+		AccessKeyId     string
+		SecretAccessKey string
+		Token           string
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse container credentials: %w", err)
+	}
+	return awsCredentials{AccessKeyID: body.AccessKeyId, SecretAccessKey: body.SecretAccessKey, SessionToken: body.Token}, nil
+}
+
+// webIdentityCredentials exchanges a service account token for role
+// credentials via STS AssumeRoleWithWebIdentity, which needs no signing.
+func webIdentityCredentials(ctx context.Context, region, tokenFile, roleARN string) (awsCredentials, error) {
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read web identity token: %w", err)
+	}
+	if roleARN == "" {
+		return awsCredentials{}, fmt.Errorf("AWS_WEB_IDENTITY_TOKEN_FILE is set but AWS_ROLE_ARN is not")
+	}
+
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = "readonly-mcp-server"
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, awsEndpoint("sts", region),
+		strings.NewReader(query.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("AssumeRoleWithWebIdentity request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return awsCredentials{}, fmt.Errorf("AssumeRoleWithWebIdentity failed: %s", resp.Status)
+	}
+
+	var body struct {
+		Credentials struct {
+			AccessKeyId     string
+			SecretAccessKey string
+			SessionToken    string
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse AssumeRoleWithWebIdentity response: %w", err)
+	}
+	c := body.Credentials
+	return awsCredentials{AccessKeyID: c.AccessKeyId, SecretAccessKey: c.SecretAccessKey, SessionToken: c.SessionToken}, nil
+}
+
+// signV4 adds AWS Signature Version 4 headers to req. Every header already
+// set on req is signed, along with Host.
+func signV4(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	payloadHash := sha256.Sum256(body)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		strings.ReplaceAll(req.URL.Query().Encode(), "+", "%20"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(requestHash[:])
+
+	key := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,146 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignV4_MatchesAWSExample(t *testing.T) {
+	// Example request from the AWS Signature Version 4 documentation
+	req, _ := http.NewRequest(http.MethodGet, "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+
+	signV4(req, nil, creds, "us-east-1", "iam", time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	expected := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != expected {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestAWSRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	if got := awsRegion("arn:aws:secretsmanager:eu-west-1:123456789012:secret:db-AbCdEf"); got != "eu-west-1" {
+		t.Errorf("Expected region from ARN, got %q", got)
+	}
+	if got := awsRegion("db-secret"); got != "" {
+		t.Errorf("Expected no region for plain name, got %q", got)
+	}
+
+	t.Setenv("AWS_REGION", "us-west-2")
+	if got := awsRegion("arn:aws:secretsmanager:eu-west-1:123456789012:secret:db-AbCdEf"); got != "us-west-2" {
+		t.Errorf("Expected AWS_REGION to take precedence, got %q", got)
+	}
+}
+
+func TestDSNFromSecret(t *testing.T) {
+	t.Setenv("MCP_PG_SSLMODE", "")
+
+	tests := []struct {
+		name     string
+		adapter  DBAdapter
+		secret   string
+		expected string
+	}{
+		{"plain DSN", &MySQLAdapter{}, " user:pw@tcp(db:3306)/app\n", "user:pw@tcp(db:3306)/app"},
+		{"dsn field", &MySQLAdapter{}, `{"dsn":"user:pw@tcp(db:3306)/app"}`, "user:pw@tcp(db:3306)/app"},
+		{"RDS MySQL", &MySQLAdapter{}, `{"username":"ro","password":"s3cret","host":"db","port":3306,"dbname":"app"}`, "ro:s3cret@tcp(db:3306)/app"},
+		{"RDS Postgres default port", &PostgresAdapter{}, `{"username":"ro","password":"s3cret","host":"db","dbname":"app"}`, "postgres://ro:s3cret@db:5432/app?sslmode=require"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := dsnFromSecret(tc.adapter, tc.secret)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	if _, err := dsnFromSecret(&MySQLAdapter{}, `{"username":"ro","host":"db"}`); err == nil {
+		t.Error("Expected error for secret missing fields")
+	}
+}
+
+func TestAWSSecretSource_Fetch(t *testing.T) {
+	var gotTarget, gotAuth string
+	var gotBody map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTarget = r.Header.Get("X-Amz-Target")
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		switch gotTarget {
+		case "secretsmanager.GetSecretValue":
+			w.Write([]byte(`{"SecretString":"user:pw@tcp(db:3306)/app"}`))
+		case "AmazonSSM.GetParameter":
+			w.Write([]byte(`{"Parameter":{"Value":"user:pw@tcp(db:3306)/ssm"}}`))
+		default:
+			http.Error(w, "unknown target", http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ENDPOINT_URL", srv.URL)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "token")
+
+	src := &awsSecretSource{service: "secretsmanager", id: "db-secret", region: "us-east-1"}
+	dsn, err := src.dsn(context.Background(), &MySQLAdapter{})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if dsn != "user:pw@tcp(db:3306)/app" {
+		t.Errorf("Unexpected DSN %q", dsn)
+	}
+	if gotBody["SecretId"] != "db-secret" {
+		t.Errorf("Expected SecretId in request, got %v", gotBody)
+	}
+	if !strings.Contains(gotAuth, "Credential=AKIDEXAMPLE/") || !strings.Contains(gotAuth, "x-amz-security-token") {
+		t.Errorf("Expected signed request with session token, got %q", gotAuth)
+	}
+
+	src = &awsSecretSource{service: "ssm", id: "/app/db", region: "us-east-1"}
+	if dsn, err = src.dsn(context.Background(), &MySQLAdapter{}); err != nil || dsn != "user:pw@tcp(db:3306)/ssm" {
+		t.Errorf("Expected SSM parameter value, got %q (err %v)", dsn, err)
+	}
+	if gotBody["WithDecryption"] != true {
+		t.Errorf("Expected WithDecryption in request, got %v", gotBody)
+	}
+}
+
+func TestLoadAWSCredentials_ContainerEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "pod-token" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"AccessKeyId":"ASIA","SecretAccessKey":"sk","Token":"tok","Expiration":"2030-01-01T00:00:00Z"}`))
+	}))
+	defer srv.Close()
+
+	t.Setenv("AWS_ACCESS_KEY_ID", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "")
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_FULL_URI", srv.URL)
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "pod-token")
+
+	creds, err := loadAWSCredentials(context.Background(), "us-east-1")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if creds.AccessKeyID != "ASIA" || creds.SessionToken != "tok" {
+		t.Errorf("Unexpected credentials %+v", creds)
+	}
+}
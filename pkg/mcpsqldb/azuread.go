@@ -0,0 +1,71 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+)
+
+// AzureADAuth logs in with an Azure AD (Entra ID) access token instead of a
+// database password (overridable via MCP_AZURE_AD_AUTH env var)
+var AzureADAuth = false
+
+// azureTokenRefreshMargin renews tokens this long before they expire, so a
+// token is never handed to a connection attempt that outlives it.
+const azureTokenRefreshMargin = 5 * time.Minute
+
+// azureADScope returns the token scope for the adapter's Azure database
+// service.
+func azureADScope(adapter DBAdapter) (string, error) {
+	switch adapter.DriverName() {
+	case "mysql", "postgres", "pgx":
+		return "https://ossrdbms-aad.database.windows.net/.default", nil
+	}
+	return "", fmt.Errorf("MCP_AZURE_AD_AUTH is not supported for the %s driver", adapter.DriverName())
+}
+
+// azureTokenSource caches access tokens for one scope and refreshes them
+// shortly before they expire.
+type azureTokenSource struct {
+	cred  azcore.TokenCredential
+	scope string
+
+	mu      sync.Mutex
+	current azcore.AccessToken
+}
+
+// newAzureTokenSource uses DefaultAzureCredential, which tries environment
+// credentials (AZURE_TENANT_ID, AZURE_CLIENT_ID, AZURE_CLIENT_SECRET or
+// certificate), workload identity, managed identity and the Azure CLI in turn.
+func newAzureTokenSource(adapter DBAdapter) (*azureTokenSource, error) {
+	scope, err := azureADScope(adapter)
+	if err != nil {
+		return nil, err
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up Azure credentials: %w", err)
+	}
+	return &azureTokenSource{cred: cred, scope: scope}, nil
+}
+
+func (ts *azureTokenSource) token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.current.Token != "" && time.Until(ts.current.ExpiresOn) > azureTokenRefreshMargin {
+		return ts.current.Token, nil
+	}
+	tok, err := ts.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{ts.scope}})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Azure AD access token: %w", err)
+	}
+	registerSecret(tok.Token)
+	ts.current = tok
+	return tok.Token, nil
+}
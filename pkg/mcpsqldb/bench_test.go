@@ -0,0 +1,68 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+var benchQueries = []string{
+	"SELECT id, name FROM users WHERE id = 1",
+	"SELECT u.id, o.total FROM users u JOIN orders o ON o.user_id = u.id WHERE u.name = 'DROP TABLE' -- trailing comment",
+	"SELECT * FROM events WHERE payload::text LIKE '%error%' ORDER BY created_at DESC LIMIT 100",
+}
+
+func BenchmarkValidateQuery(b *testing.B) {
+	adapters := []DBAdapter{&MySQLAdapter{}, &PostgresAdapter{}, &SQLiteAdapter{}}
+	for _, adapter := range adapters {
+		b.Run(adapter.DriverName(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for _, q := range benchQueries {
+					_ = adapter.ValidateQuery(q)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkRemoveStringsAndComments(b *testing.B) {
+	query := strings.Repeat("SELECT 'a''b', \"col\" FROM t /* c */ -- x\n", 50)
+	adapters := []DBAdapter{&MySQLAdapter{}, &PostgresAdapter{}, &SQLiteAdapter{}}
+	for _, adapter := range adapters {
+		b.Run(adapter.DriverName(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = adapter.RemoveStringsAndComments(query)
+			}
+		})
+	}
+}
+
+// BenchmarkExecuteQuery covers row scanning and JSON encoding end to end.
+func BenchmarkExecuteQuery(b *testing.B) {
+	server := newTestServer(b, `WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 1000)
+		INSERT INTO users (name) SELECT 'user-' || i FROM n`)
+
+	args := map[string]any{"sql": "SELECT id, name FROM users"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if result, rpcErr := server.executeQuery(context.Background(), args); rpcErr != nil || result.IsError {
+			b.Fatal("Query failed")
+		}
+	}
+}
+
+func BenchmarkJSONEncodeResults(b *testing.B) {
+	results := make([]map[string]any, 1000)
+	for i := range results {
+		results[i] = map[string]any{"id": int64(i), "name": fmt.Sprintf("user-%d", i), "score": float64(i) / 3}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.MarshalIndent(results, "", "  "); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
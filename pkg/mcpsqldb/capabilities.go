@@ -0,0 +1,24 @@
+package mcpsqldb
+
+// names lists the declared capabilities, for logs and server_status.
+func (c ClientCapabilities) names() []string {
+	names := []string{}
+	if c.Elicitation != nil {
+		names = append(names, "elicitation")
+	}
+	if c.Roots != nil {
+		names = append(names, "roots")
+	}
+	if c.Sampling != nil {
+		names = append(names, "sampling")
+	}
+	return names
+}
+
+// clientSupportsElicitation reports whether the client can be asked for
+// input mid-request (elicitation/create). Optional behaviors built on a
+// client feature must check for it first: clients reject requests for
+// features they did not declare.
+func (s *Server) clientSupportsElicitation() bool {
+	return s.client.Capabilities.Elicitation != nil
+}
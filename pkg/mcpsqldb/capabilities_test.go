@@ -0,0 +1,56 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func initializeWith(t *testing.T, server *Server, params string) *JSONRPCResponse {
+	t.Helper()
+	return server.handleMessage([]byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":` + params + `}`))
+}
+
+func TestInitialize_StoresClientCapabilities(t *testing.T) {
+	server := newTestServer(t)
+
+	response := initializeWith(t, server, `{"protocolVersion":"2025-06-18","clientInfo":{"name":"inspector","version":"0.9"},`+
+		`"capabilities":{"roots":{"listChanged":true},"elicitation":{},"experimental":{"x":{}}}}`)
+	if response.Error != nil {
+		t.Fatalf("Unexpected error: %+v", response.Error)
+	}
+	if !server.clientSupportsElicitation() {
+		t.Error("Expected elicitation to be supported")
+	}
+	if roots := server.client.Capabilities.Roots; roots == nil || !roots.ListChanged {
+		t.Errorf("Expected roots with listChanged, got %+v", roots)
+	}
+	if got := server.client.Capabilities.names(); !reflect.DeepEqual(got, []string{"elicitation", "roots"}) {
+		t.Errorf("Expected elicitation and roots, got %v", got)
+	}
+
+	status, _ := server.serverStatus(context.Background())
+	var report struct {
+		Client map[string]any `json:"client"`
+	}
+	json.Unmarshal([]byte(status.Content[0].Text), &report)
+	if report.Client["name"] != "inspector" || !reflect.DeepEqual(report.Client["capabilities"], []any{"elicitation", "roots"}) {
+		t.Errorf("Expected the client in server_status, got %s", status.Content[0].Text)
+	}
+}
+
+func TestInitialize_WithoutCapabilities(t *testing.T) {
+	server := newTestServer(t)
+
+	if response := initializeWith(t, server, `{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"cli"}}`); response.Error != nil {
+		t.Fatalf("Unexpected error: %+v", response.Error)
+	}
+	if server.clientSupportsElicitation() || len(server.client.Capabilities.names()) != 0 {
+		t.Errorf("Expected no capabilities, got %v", server.client.Capabilities.names())
+	}
+
+	if response := initializeWith(t, server, `{"capabilities":{"roots":true}}`); response.Error == nil || response.Error.Code != InvalidParams {
+		t.Errorf("Expected invalid params for malformed capabilities, got %+v", response.Error)
+	}
+}
@@ -0,0 +1,52 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+)
+
+// SourceCharset is the character set of legacy text stored in the database,
+// e.g. "latin1", "windows-1251" or "shift_jis" (overridable via
+// MCP_SOURCE_CHARSET env var). Text values that are not valid UTF-8 are
+// transcoded from it as rows are scanned; valid UTF-8 passes through.
+var SourceCharset string
+
+// sourceEncoding resolves a SourceCharset name using the WHATWG encoding
+// labels, under which "latin1" is windows-1252 as in MySQL. An empty name
+// returns nil: no transcoding.
+func sourceEncoding(name string) (encoding.Encoding, error) {
+	if name == "" {
+		return nil, nil
+	}
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported MCP_SOURCE_CHARSET %q: %w", name, err)
+	}
+	return enc, nil
+}
+
+// textValue converts a scanned []byte or string to UTF-8 text, decoding it
+// from enc when it is not already valid UTF-8. Other values are returned
+// unchanged.
+func textValue(enc encoding.Encoding, val any) any {
+	var raw string
+	switch v := val.(type) {
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	default:
+		return val
+	}
+	if enc == nil || utf8.ValidString(raw) {
+		return raw
+	}
+	decoded, err := enc.NewDecoder().String(raw)
+	if err != nil {
+		return raw
+	}
+	return decoded
+}
@@ -0,0 +1,84 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestSourceEncoding(t *testing.T) {
+	if enc, err := sourceEncoding(""); enc != nil || err != nil {
+		t.Errorf("Expected no encoding for an empty name, got %v %v", enc, err)
+	}
+	for _, name := range []string{"latin1", "windows-1251", "cp1251", "Shift_JIS", "gbk"} {
+		if enc, err := sourceEncoding(name); enc == nil || err != nil {
+			t.Errorf("Expected %q to be supported, got %v", name, err)
+		}
+	}
+	if _, err := sourceEncoding("klingon"); err == nil || !strings.Contains(err.Error(), "MCP_SOURCE_CHARSET") {
+		t.Errorf("Expected an error naming MCP_SOURCE_CHARSET, got %v", err)
+	}
+}
+
+func TestTextValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		charset string
+		val     any
+		want    any
+	}{
+		{"latin1 bytes", "latin1", []byte("Caf\xe9"), "Café"},
+		{"latin1 string", "latin1", "Caf\xe9", "Café"},
+		{"cp1252 punctuation", "latin1", []byte("\x93quoted\x94"), "“quoted”"},
+		{"windows-1251", "windows-1251", []byte("\xcf\xf0\xe8\xe2\xe5\xf2"), "Привет"},
+		{"valid UTF-8 untouched", "latin1", []byte("Café"), "Café"},
+		{"no charset", "", []byte("Caf\xe9"), "Caf\xe9"},
+		{"non-text", "latin1", int64(7), int64(7)},
+		{"NULL", "latin1", nil, nil},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			enc, err := sourceEncoding(tc.charset)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got := textValue(enc, tc.val); got != tc.want {
+				t.Errorf("Expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestExecuteQuery_TranscodesSourceCharset(t *testing.T) {
+	defer func(orig string) { SourceCharset = orig }(SourceCharset)
+	path := newTestDB(t, "INSERT INTO users (name) VALUES (CAST(X'436166E9' AS TEXT))")
+
+	for _, tc := range []struct {
+		charset string
+		want    string
+	}{
+		{"", "Caf�"},
+		{"latin1", "Café"},
+	} {
+		SourceCharset = tc.charset
+		server := openTestServer(t, path)
+		result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users WHERE id = 4"})
+		var rows []map[string]any
+		if result.IsError || json.Unmarshal([]byte(result.Content[0].Text), &rows) != nil || len(rows) != 1 {
+			t.Fatalf("Expected one row, got %s", result.Content[0].Text)
+		}
+		if rows[0]["name"] != tc.want {
+			t.Errorf("Expected %q with MCP_SOURCE_CHARSET=%q, got %q", tc.want, tc.charset, rows[0]["name"])
+		}
+	}
+}
+
+func TestNewServer_RejectsUnknownCharset(t *testing.T) {
+	defer func(orig string) { SourceCharset = orig }(SourceCharset)
+	SourceCharset = "klingon"
+
+	if _, err := newServer(context.Background(), &SQLiteAdapter{}, newTestDB(t)+"?mode=ro"); err == nil {
+		t.Error("Expected an unknown charset to fail server creation")
+	}
+}
@@ -0,0 +1,186 @@
+package mcpsqldb
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"time"
+)
+
+// rowChecksum is an order-independent digest of a multiset of rows: the sum
+// of their SHA-256 hashes modulo 2^256, as four big-endian words.
+type rowChecksum [4]uint64
+
+// add adds the hash of one row.
+func (c *rowChecksum) add(hash [sha256.Size]byte) {
+	var carry uint64
+	for i := len(c) - 1; i >= 0; i-- {
+		c[i], carry = bits.Add64(c[i], binary.BigEndian.Uint64(hash[i*8:]), carry)
+	}
+}
+
+// sum returns the checksum of the rows under columns, so results whose
+// columns are named or ordered differently do not match.
+func (c *rowChecksum) sum(columns []string) string {
+	h := sha256.New()
+	header, _ := json.Marshal(columns)
+	h.Write(header)
+	for _, word := range c {
+		h.Write(binary.BigEndian.AppendUint64(nil, word))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// checksumReport is the checksum_rows result.
+type checksumReport struct {
+	Rows     int64    `json:"rows"`
+	Checksum string   `json:"checksum"`
+	Columns  []string `json:"columns"`
+	// MaskedColumns are hashed as masked, so their real values do not
+	// affect the checksum
+	MaskedColumns []string `json:"masked_columns,omitempty"`
+}
+
+// checksumRowsTool describes checksum_rows.
+func checksumRowsTool() Tool {
+	return Tool{
+		Name: "checksum_rows",
+		Description: "Compute a row count and a checksum of a table's rows, or of a query's, without returning them, " +
+			"to check whether two databases hold the same data; the checksum does not depend on row order",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"table": {
+					Type:        "string",
+					Description: "The table to checksum",
+				},
+				"sql": {
+					Type:        "string",
+					Description: "A read-only SQL query whose rows to checksum, e.g. to filter the table; give either table or sql",
+				},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// checksumRows counts and hashes every row of a table or query, past the
+// row limit, through the same validation, cost guardrail, masking and audit
+// as the query tool.
+func (s *Server) checksumRows(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	table, _ := args["table"].(string)
+	sqlQuery, _ := args["sql"].(string)
+	if (table == "") == (sqlQuery == "") {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Provide either a 'table' or an 'sql' parameter",
+		}
+	}
+	if table != "" {
+		// Answer as if the table did not exist rather than confirm it is hidden
+		if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+			return &CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Table not found: %s", table)}},
+				IsError: true,
+			}, nil
+		}
+		sqlQuery = "SELECT * FROM " + s.quoteName(table)
+	}
+
+	validated, err := s.validateQuery(sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	if result := s.checkCost(ctx, validated); result != nil {
+		return result, nil
+	}
+	// The rows are not returned, so only the query counts against the quota
+	if qerr := s.quota.allow(time.Now()); qerr != nil {
+		stats.queriesRejected.Add(1)
+		return quotaExceeded(qerr), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	start := time.Now()
+	report, err := s.checksum(ctx, validated, table)
+	if err != nil {
+		s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeError, 0, time.Since(start), err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeOK, int(report.Rows), time.Since(start), "")
+	return statementReportResult(report)
+}
+
+// checksum runs the validated sqlQuery and hashes each row's values, decoded
+// and masked as the query tool returns them, in column order. Masking rules
+// for table apply when it is set.
+func (s *Server) checksum(ctx context.Context, sqlQuery, table string) (*checksumReport, error) {
+	rows, done, err := s.beginQuery(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	types := columnTypeNames(rows)
+	masks := columnMasks(MaskRules, table, columns)
+	report := &checksumReport{Columns: columns}
+	for i, mask := range masks {
+		if mask != "" {
+			report.MaskedColumns = append(report.MaskedColumns, columns[i])
+		}
+	}
+
+	var sum rowChecksum
+	values := make([]any, len(columns))
+	ptrs := make([]any, len(columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+	decoded := make([]any, len(columns))
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("failed to scan row %d: %w", report.Rows+1, err)
+		}
+		for i, val := range values {
+			decoded[i] = s.adapter.DecodeValue(types[i], textValue(s.charset, val))
+			if masks != nil {
+				decoded[i] = maskValue(masks[i], decoded[i])
+			}
+		}
+		encoded, err := json.Marshal(decoded)
+		if err != nil {
+			return nil, err
+		}
+		sum.add(sha256.Sum256(encoded))
+		report.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	report.Checksum = sum.sum(columns)
+	return report, nil
+}
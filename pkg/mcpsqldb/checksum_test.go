@@ -0,0 +1,97 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestChecksumRows_ComparesData(t *testing.T) {
+	base := callToolJSON[checksumReport](t, newTestServer(t), "checksum_rows", map[string]any{"table": "users"})
+	if base.Rows != 3 || len(base.Checksum) != 64 || !reflect.DeepEqual(base.Columns, []string{"id", "name"}) {
+		t.Errorf("Expected 3 rows of id and name with a checksum, got %+v", base)
+	}
+
+	same := callToolJSON[checksumReport](t, newTestServer(t), "checksum_rows", map[string]any{"table": "users"})
+	if same.Checksum != base.Checksum {
+		t.Errorf("Expected identical tables to match, got %s and %s", base.Checksum, same.Checksum)
+	}
+
+	// The same rows inserted in another order
+	reordered := newTestServer(t,
+		"DELETE FROM users",
+		"INSERT INTO users (id, name) VALUES (3, 'carol'), (1, 'alice'), (2, 'bob')",
+	)
+	if got := callToolJSON[checksumReport](t, reordered, "checksum_rows", map[string]any{"table": "users"}); got.Checksum != base.Checksum {
+		t.Errorf("Expected row order not to matter, got %s and %s", base.Checksum, got.Checksum)
+	}
+
+	changed := newTestServer(t, "UPDATE users SET name = 'bobby' WHERE name = 'bob'")
+	if got := callToolJSON[checksumReport](t, changed, "checksum_rows", map[string]any{"table": "users"}); got.Checksum == base.Checksum || got.Rows != 3 {
+		t.Errorf("Expected a changed row to change the checksum, got %+v", got)
+	}
+
+	swapped := callToolJSON[checksumReport](t, newTestServer(t), "checksum_rows", map[string]any{"sql": "SELECT name, id FROM users"})
+	if swapped.Checksum == base.Checksum {
+		t.Error("Expected the column order to change the checksum")
+	}
+}
+
+func TestChecksumRows_Query(t *testing.T) {
+	server := newTestServer(t)
+
+	filtered := callToolJSON[checksumReport](t, server, "checksum_rows", map[string]any{"sql": "SELECT id, name FROM users WHERE id > 1"})
+	if filtered.Rows != 2 {
+		t.Errorf("Expected 2 rows, got %d", filtered.Rows)
+	}
+
+	// Rows past the row limit are still counted
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 1
+	if got := callToolJSON[checksumReport](t, server, "checksum_rows", map[string]any{"table": "users"}); got.Rows != 3 {
+		t.Errorf("Expected every row to be counted, got %d", got.Rows)
+	}
+
+	result, _ := server.callTool(context.Background(), "checksum_rows", map[string]any{"sql": "DELETE FROM users"})
+	if !result.IsError {
+		t.Error("Expected a write to be rejected")
+	}
+}
+
+func TestChecksumRows_Params(t *testing.T) {
+	server := newTestServer(t)
+	for _, args := range []map[string]any{
+		{},
+		{"table": "users", "sql": "SELECT * FROM users"},
+	} {
+		if _, rpcErr := server.callTool(context.Background(), "checksum_rows", args); rpcErr == nil || rpcErr.Code != InvalidParams {
+			t.Errorf("Expected invalid params for %v, got %v", args, rpcErr)
+		}
+	}
+}
+
+func TestChecksumRows_DeniedTable(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"users"}
+
+	result, rpcErr := newTestServer(t).callTool(context.Background(), "checksum_rows", map[string]any{"table": "users"})
+	if rpcErr != nil || !result.IsError || result.Content[0].Text != "Table not found: users" {
+		t.Errorf("Expected the denied table to be reported as missing, got %v %+v", rpcErr, result)
+	}
+}
+
+func TestChecksumRows_Masked(t *testing.T) {
+	defer func(orig []maskRule) { MaskRules = orig }(MaskRules)
+	MaskRules, _ = parseMaskRules("users.name=null")
+
+	base := callToolJSON[checksumReport](t, newTestServer(t), "checksum_rows", map[string]any{"table": "users"})
+	if !reflect.DeepEqual(base.MaskedColumns, []string{"name"}) {
+		t.Errorf("Expected name to be listed as masked, got %v", base.MaskedColumns)
+	}
+
+	// Masked values must not be recoverable by comparing checksums
+	changed := callToolJSON[checksumReport](t, newTestServer(t, "UPDATE users SET name = 'bobby' WHERE name = 'bob'"), "checksum_rows", map[string]any{"table": "users"})
+	if changed.Checksum != base.Checksum {
+		t.Error("Expected a masked column not to affect the checksum")
+	}
+}
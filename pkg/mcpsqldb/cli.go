@@ -0,0 +1,905 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "modernc.org/sqlite"
+)
+
+// selectAdapter returns the adapter named by MCP_DB_DRIVER.
+func selectAdapter() (DBAdapter, error) {
+	driver := os.Getenv("MCP_DB_DRIVER")
+	if driver == "" {
+		return nil, fmt.Errorf("MCP_DB_DRIVER environment variable is required (supported: %s)", adapterNames())
+	}
+	return newAdapter(driver)
+}
+
+// Subcommands accepted as the first argument
+const (
+	CommandServe       = "serve"
+	CommandHealthcheck = "healthcheck"
+	CommandVerifyAudit = "verify-audit"
+	CommandREPL        = "repl"
+	CommandTestConn    = "test-connection"
+	CommandPrintConfig = "print-config"
+)
+
+// parseCommand splits the command line into a subcommand and its remaining
+// arguments. Without a recognized subcommand the server runs normally.
+func parseCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case CommandHealthcheck, CommandVerifyAudit, CommandREPL, CommandTestConn, CommandPrintConfig:
+			return args[0], args[1:]
+		}
+	}
+	return CommandServe, args
+}
+
+func getDSN(ctx context.Context, adapter DBAdapter, secrets *awsSecretSource, args []string) (string, error) {
+	// If DSN provided as argument, use it directly
+	if len(args) >= 1 {
+		return args[0], nil
+	}
+
+	if secrets != nil {
+		ctx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+		defer cancel()
+		return secrets.dsn(ctx, adapter)
+	}
+
+	// Build DSN from environment variables using the adapter
+	return adapter.BuildDSN()
+}
+
+// LoadEnv applies the MCP_* environment variables to the package-level
+// settings (limits, timeouts, quotas and security options). Main calls it at
+// startup; embedders call it before New to configure the server the same way.
+func LoadEnv() {
+	if v := os.Getenv("MCP_QUERY_TIMEOUT"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			slog.Warn("Invalid MCP_QUERY_TIMEOUT, using default", "value", v, "default", QueryTimeout)
+		} else {
+			QueryTimeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_SLOW_QUERY_MS"); v != "" {
+		ms, err := strconv.Atoi(v)
+		if err != nil || ms <= 0 {
+			slog.Warn("Invalid MCP_SLOW_QUERY_MS, slow query logging disabled", "value", v)
+		} else {
+			SlowQueryThreshold = time.Duration(ms) * time.Millisecond
+		}
+	}
+
+	if v := os.Getenv("MCP_KEEPALIVE_INTERVAL"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid MCP_KEEPALIVE_INTERVAL, keepalive pings disabled", "value", v)
+		} else {
+			KeepaliveInterval = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_IDLE_TIMEOUT"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid MCP_IDLE_TIMEOUT, idle sessions kept open", "value", v)
+		} else {
+			IdleTimeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_SUBSCRIPTION_POLL_INTERVAL"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid MCP_SUBSCRIPTION_POLL_INTERVAL, using default", "value", v, "default", SubscriptionPollInterval)
+		} else {
+			SubscriptionPollInterval = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_SUBSCRIPTION_ROW_THRESHOLD"); v != "" {
+		rows, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || rows < 1 {
+			slog.Warn("Invalid MCP_SUBSCRIPTION_ROW_THRESHOLD, using default", "value", v, "default", SubscriptionRowThreshold)
+		} else {
+			SubscriptionRowThreshold = rows
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_RESULT_BYTES"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			slog.Warn("Invalid MCP_MAX_RESULT_BYTES, using default", "value", v, "default", MaxResultBytes)
+		} else {
+			MaxResultBytes = size
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_REQUEST_BYTES"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			slog.Warn("Invalid MCP_MAX_REQUEST_BYTES, using default", "value", v, "default", MaxRequestBytes)
+		} else {
+			MaxRequestBytes = size
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_ARGUMENT_BYTES"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size <= 0 {
+			slog.Warn("Invalid MCP_MAX_ARGUMENT_BYTES, using default", "value", v, "default", MaxArgumentBytes)
+		} else {
+			MaxArgumentBytes = size
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_COLUMNS"); v != "" {
+		columns, err := strconv.Atoi(v)
+		if err != nil || columns < 0 {
+			slog.Warn("Invalid MCP_MAX_COLUMNS, using default", "value", v, "default", MaxResultColumns)
+		} else {
+			MaxResultColumns = columns
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_QUERY_COST"); v != "" {
+		cost, err := strconv.ParseFloat(v, 64)
+		if err != nil || cost < 0 {
+			slog.Warn("Invalid MCP_MAX_QUERY_COST, using default", "value", v, "default", MaxQueryCost)
+		} else {
+			MaxQueryCost = cost
+		}
+	}
+
+	if v := os.Getenv("MCP_FORBIDDEN_QUERY_COST"); v != "" {
+		cost, err := strconv.ParseFloat(v, 64)
+		if err != nil || cost < 0 {
+			slog.Warn("Invalid MCP_FORBIDDEN_QUERY_COST, using default", "value", v, "default", ForbiddenQueryCost)
+		} else {
+			ForbiddenQueryCost = cost
+		}
+	}
+
+	if v := os.Getenv("MCP_QUERY_INSIGHTS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_QUERY_INSIGHTS, using default", "value", v, "default", QueryInsights)
+		} else {
+			QueryInsights = enabled
+		}
+	}
+
+	if v := os.Getenv("MCP_TOP_QUERIES"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_TOP_QUERIES, using default", "value", v, "default", TopQueries)
+		} else {
+			TopQueries = enabled
+		}
+	}
+
+	if v := os.Getenv("MCP_RUNNING_QUERIES"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_RUNNING_QUERIES, using default", "value", v, "default", RunningQueries)
+		} else {
+			RunningQueries = enabled
+		}
+	}
+	if v := os.Getenv("MCP_RUNNING_QUERIES_REDACT"); v != "" {
+		switch mode := strings.ToLower(v); mode {
+		case RedactNone, RedactLiterals, RedactHide:
+			RunningQueriesRedaction = mode
+		default:
+			slog.Warn("Invalid MCP_RUNNING_QUERIES_REDACT, using default", "value", v, "default", RunningQueriesRedaction)
+		}
+	}
+
+	if v := os.Getenv("MCP_IDENTIFIER_QUOTING"); v != "" {
+		switch mode := strings.ToLower(v); mode {
+		case QuoteAlways, QuoteMixedCase:
+			IdentifierQuoting = mode
+		default:
+			slog.Warn("Invalid MCP_IDENTIFIER_QUOTING, using default", "value", v, "default", IdentifierQuoting)
+		}
+	}
+
+	if v := os.Getenv("MCP_NUMBER_LOCALE"); v != "" {
+		if _, ok := lookupNumberLocale(v); ok {
+			NumberLocale = v
+		} else {
+			slog.Warn("Invalid MCP_NUMBER_LOCALE, using default", "value", v, "default", NumberLocale)
+		}
+	}
+
+	if v := os.Getenv("MCP_NUMBER_DECIMALS"); v != "" {
+		decimals, err := strconv.Atoi(v)
+		if err != nil || decimals < 0 || decimals > 20 {
+			slog.Warn("Invalid MCP_NUMBER_DECIMALS, using default", "value", v, "default", NumberDecimals)
+		} else {
+			NumberDecimals = decimals
+		}
+	}
+
+	if v := os.Getenv("MCP_CROSS_SOURCES"); v != "" {
+		CrossQuerySources = map[string]Config{}
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name == "" {
+				continue
+			}
+			prefix := "MCP_SOURCE_" + strings.ToUpper(name) + "_"
+			CrossQuerySources[name] = Config{
+				Driver: os.Getenv(prefix + "DRIVER"),
+				DSN:    os.Getenv(prefix + "DSN"),
+			}
+		}
+	}
+
+	if v := os.Getenv("MCP_CROSS_MAX_ROWS"); v != "" {
+		rows, err := strconv.Atoi(v)
+		if err != nil || rows <= 0 {
+			slog.Warn("Invalid MCP_CROSS_MAX_ROWS, using default", "value", v, "default", CrossQueryMaxRows)
+		} else {
+			CrossQueryMaxRows = rows
+		}
+	}
+
+	if v := os.Getenv("MCP_SAVED_RESULT_MAX_ROWS"); v != "" {
+		rows, err := strconv.Atoi(v)
+		if err != nil || rows <= 0 {
+			slog.Warn("Invalid MCP_SAVED_RESULT_MAX_ROWS, using default", "value", v, "default", SavedResultMaxRows)
+		} else {
+			SavedResultMaxRows = rows
+		}
+	}
+
+	if v := os.Getenv("MCP_RESULT_CACHE"); v != "" {
+		ResultCachePath = v
+	}
+
+	if v := os.Getenv("MCP_RESULT_CACHE_MAX_ROWS"); v != "" {
+		rows, err := strconv.Atoi(v)
+		if err != nil || rows <= 0 {
+			slog.Warn("Invalid MCP_RESULT_CACHE_MAX_ROWS, using default", "value", v, "default", ResultCacheMaxRows)
+		} else {
+			ResultCacheMaxRows = rows
+		}
+	}
+
+	if v := os.Getenv("MCP_QUERY_RETRIES"); v != "" {
+		retries, err := strconv.Atoi(v)
+		if err != nil || retries < 0 {
+			slog.Warn("Invalid MCP_QUERY_RETRIES, using default", "value", v, "default", QueryRetries)
+		} else {
+			QueryRetries = retries
+		}
+	}
+
+	if v := os.Getenv("MCP_WORKERS"); v != "" {
+		workers, err := strconv.Atoi(v)
+		if err != nil || workers <= 0 {
+			slog.Warn("Invalid MCP_WORKERS, using default", "value", v, "default", WorkerCount)
+		} else {
+			WorkerCount = workers
+		}
+	}
+
+	if v := os.Getenv("MCP_QUEUE_DEPTH"); v != "" {
+		depth, err := strconv.Atoi(v)
+		if err != nil || depth < 0 {
+			slog.Warn("Invalid MCP_QUEUE_DEPTH, using default", "value", v, "default", QueueDepth)
+		} else {
+			QueueDepth = depth
+		}
+	}
+
+	if v := strings.ToLower(os.Getenv("MCP_PG_DRIVER")); v != "" {
+		switch v {
+		case PostgresDriverPQ, PostgresDriverPGX:
+			PostgresDriver = v
+		default:
+			slog.Warn("Invalid MCP_PG_DRIVER, using default", "value", v, "default", PostgresDriver)
+		}
+	}
+
+	if v := strings.ToLower(os.Getenv("MCP_QUEUE_POLICY")); v != "" {
+		switch v {
+		case QueuePolicyReject, QueuePolicyWait:
+			QueuePolicy = v
+		default:
+			slog.Warn("Invalid MCP_QUEUE_POLICY, using default", "value", v, "default", QueuePolicy)
+		}
+	}
+
+	PprofAddr = os.Getenv("MCP_PPROF_ADDR")
+
+	if v := strings.ToLower(os.Getenv("MCP_TRANSPORT")); v != "" {
+		switch v {
+		case TransportStdio, TransportHTTP:
+			Transport = v
+		default:
+			slog.Warn("Invalid MCP_TRANSPORT, using default", "value", v, "default", Transport)
+		}
+	}
+	if v := os.Getenv("MCP_HTTP_LISTEN"); v != "" {
+		HTTPListen = v
+	}
+	HTTPPublicURL = os.Getenv("MCP_HTTP_PUBLIC_URL")
+	if v := os.Getenv("MCP_HTTP_ALLOWED_ORIGINS"); v != "" {
+		for _, origin := range strings.Split(v, ",") {
+			if origin = strings.TrimSpace(origin); origin != "" {
+				HTTPAllowedOrigins = append(HTTPAllowedOrigins, origin)
+			}
+		}
+	}
+	if v := os.Getenv("MCP_HTTP_MAX_SESSIONS"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			slog.Warn("Invalid MCP_HTTP_MAX_SESSIONS, using default", "value", v, "default", HTTPMaxSessions)
+		} else {
+			HTTPMaxSessions = limit
+		}
+	}
+	if v := os.Getenv("MCP_SSE_KEEPALIVE_INTERVAL"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid MCP_SSE_KEEPALIVE_INTERVAL, using default", "value", v, "default", SSEKeepaliveInterval)
+		} else {
+			SSEKeepaliveInterval = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_AUTH_TOKENS"); v != "" {
+		for _, token := range strings.Split(v, ",") {
+			if token = strings.TrimSpace(token); token != "" {
+				registerSecret(token)
+				AuthTokens = append(AuthTokens, token)
+			}
+		}
+	}
+	OIDCIssuer = os.Getenv("MCP_OIDC_ISSUER")
+	OIDCAudience = os.Getenv("MCP_OIDC_AUDIENCE")
+
+	if v := os.Getenv("MCP_RATE_LIMIT_QPS"); v != "" {
+		qps, err := strconv.ParseFloat(v, 64)
+		if err != nil || qps < 0 {
+			slog.Warn("Invalid MCP_RATE_LIMIT_QPS, rate limiting disabled", "value", v)
+		} else {
+			RateLimitQPS = qps
+		}
+	}
+	if v := os.Getenv("MCP_RATE_LIMIT_BURST"); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err != nil || burst < 0 {
+			slog.Warn("Invalid MCP_RATE_LIMIT_BURST, using default", "value", v, "default", RateLimitBurst)
+		} else {
+			RateLimitBurst = burst
+		}
+	}
+
+	if v := os.Getenv("MCP_REQUIRE_READONLY_USER"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_REQUIRE_READONLY_USER, using default", "value", v, "default", RequireReadOnlyUser)
+		} else {
+			RequireReadOnlyUser = required
+		}
+	}
+
+	SourceCharset = os.Getenv("MCP_SOURCE_CHARSET")
+
+	if v := os.Getenv("MCP_SNAPSHOT_SESSION"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_SNAPSHOT_SESSION, using default", "value", v, "default", SnapshotSession)
+		} else {
+			SnapshotSession = enabled
+		}
+	}
+
+	if v := os.Getenv("MCP_DEBUG_WIRE"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_DEBUG_WIRE, wire dump disabled", "value", v)
+		} else {
+			DebugWire = enabled
+		}
+	}
+	DebugWireFile = os.Getenv("MCP_DEBUG_WIRE_FILE")
+
+	if v := os.Getenv("MCP_ASYNC_QUERY_TIMEOUT"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			slog.Warn("Invalid MCP_ASYNC_QUERY_TIMEOUT, using default", "value", v, "default", AsyncQueryTimeout)
+		} else {
+			AsyncQueryTimeout = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_RESULT_TTL"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs < 0 {
+			slog.Warn("Invalid MCP_RESULT_TTL, using default", "value", v, "default", ResultTTL)
+		} else {
+			ResultTTL = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_MASK_COLUMNS"); v != "" {
+		rules, err := parseMaskRules(v)
+		if err != nil {
+			// Refuse to run unmasked rather than silently leak the columns
+			slog.Error("Invalid MCP_MASK_COLUMNS", "error", err)
+			os.Exit(1)
+		}
+		MaskRules = rules
+	}
+
+	if v := os.Getenv("MCP_QUERY_TEMPLATES"); v != "" {
+		templates, err := parseQueryTemplates(v, os.Getenv)
+		if err != nil {
+			slog.Error("Invalid MCP_QUERY_TEMPLATES", "error", err)
+			os.Exit(1)
+		}
+		QueryTemplates = templates
+	}
+
+	if v := os.Getenv("MCP_TABLE_ROW_LIMITS"); v != "" {
+		limits, err := parseTableRowLimits(v)
+		if err != nil {
+			slog.Error("Invalid MCP_TABLE_ROW_LIMITS", "error", err)
+			os.Exit(1)
+		}
+		TableRowLimits = limits
+	}
+
+	if v := os.Getenv("MCP_COUNT_TRUNCATED"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_COUNT_TRUNCATED, using default", "value", v, "default", CountTruncatedRows)
+		} else {
+			CountTruncatedRows = enabled
+		}
+	}
+
+	if v, ok := os.LookupEnv("MCP_SESSION_VARIABLES"); ok {
+		names, err := parseSessionVariables(v)
+		if err != nil {
+			slog.Error("Invalid MCP_SESSION_VARIABLES", "error", err)
+			os.Exit(1)
+		}
+		SessionVariables = names
+	}
+
+	if v := os.Getenv("MCP_DENY_TABLES"); v != "" {
+		patterns, err := parseTablePatterns(v)
+		if err != nil {
+			slog.Error("Invalid MCP_DENY_TABLES", "error", err)
+			os.Exit(1)
+		}
+		DeniedTables = patterns
+	}
+
+	if v := os.Getenv("MCP_SHOW_ALLOW"); v != "" {
+		ShowAllowed = parseShowCommands(v)
+	}
+	if v, ok := os.LookupEnv("MCP_SHOW_DENY"); ok {
+		ShowDenied = parseShowCommands(v)
+	}
+
+	SchemaSnapshotDir = os.Getenv("MCP_SCHEMA_SNAPSHOT_DIR")
+
+	if v := os.Getenv("MCP_SQLITE_ALLOWED_DIRS"); v != "" {
+		for _, dir := range strings.Split(v, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				SQLiteAllowedDirs = append(SQLiteAllowedDirs, dir)
+			}
+		}
+	}
+
+	if v := os.Getenv("MCP_FILES"); v != "" {
+		FlatFiles = parseFlatFiles(v)
+	}
+
+	if v := os.Getenv("MCP_SQLITE_REQUIRE_RO_FS"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_SQLITE_REQUIRE_RO_FS, using default", "value", v, "default", SQLiteRequireReadOnlyFS)
+		} else {
+			SQLiteRequireReadOnlyFS = required
+		}
+	}
+
+	if v := os.Getenv("MCP_DUCKDB_ALLOWED_DIRS"); v != "" {
+		for _, dir := range strings.Split(v, ",") {
+			if dir = strings.TrimSpace(dir); dir != "" {
+				DuckDBAllowedDirs = append(DuckDBAllowedDirs, dir)
+			}
+		}
+	}
+
+	if v := os.Getenv("MCP_ADAPTER_PLUGINS"); v != "" {
+		for _, path := range strings.Split(v, ",") {
+			if path = strings.TrimSpace(path); path != "" {
+				AdapterPlugins = append(AdapterPlugins, path)
+			}
+		}
+	}
+
+	if v := os.Getenv("MCP_REQUIRE_TLS"); v != "" {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_REQUIRE_TLS, using default", "value", v, "default", RequireTLS)
+		} else {
+			RequireTLS = required
+		}
+	}
+
+	if v := os.Getenv("MCP_WRITE_PROBE"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_WRITE_PROBE, using default", "value", v, "default", WriteProbe)
+		} else {
+			WriteProbe = enabled
+		}
+	}
+
+	if v := os.Getenv("MCP_REDACT_SQL_LITERALS"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_REDACT_SQL_LITERALS, using default", "value", v, "default", RedactSQLLiterals)
+		} else {
+			RedactSQLLiterals = enabled
+		}
+	}
+
+	AuditLogPath = os.Getenv("MCP_AUDIT_LOG")
+	if v := os.Getenv("MCP_AUDIT_CHAIN"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_AUDIT_CHAIN, using default", "value", v, "default", AuditChain)
+		} else {
+			AuditChain = enabled
+		}
+	}
+	if v := os.Getenv("MCP_AUDIT_CHECKPOINT_EVERY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			slog.Warn("Invalid MCP_AUDIT_CHECKPOINT_EVERY, using default", "value", v, "default", AuditCheckpointEvery)
+		} else {
+			AuditCheckpointEvery = n
+		}
+	}
+
+	if v, ok := os.LookupEnv("MCP_DENY_SCHEMAS"); ok {
+		patterns, err := parseTablePatterns(v)
+		if err != nil {
+			slog.Error("Invalid MCP_DENY_SCHEMAS", "error", err)
+			os.Exit(1)
+		}
+		DeniedSchemas = patterns
+	}
+
+	if v := os.Getenv("MCP_ACTIVE_SCHEMAS"); v != "" {
+		for _, schema := range strings.Split(v, ",") {
+			if schema = strings.TrimSpace(schema); schema == "" {
+				continue
+			}
+			if isSchemaDenied(DeniedSchemas, schema) {
+				slog.Error("Invalid MCP_ACTIVE_SCHEMAS: schema is hidden by MCP_DENY_SCHEMAS", "schema", schema)
+				os.Exit(1)
+			}
+			ActiveSchemas = append(ActiveSchemas, schema)
+		}
+	}
+
+	SecretARN = os.Getenv("MCP_SECRET_ARN")
+	SSMParameter = os.Getenv("MCP_SSM_PARAMETER")
+	if v := os.Getenv("MCP_SECRET_REFRESH"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			slog.Warn("Invalid MCP_SECRET_REFRESH, using default", "value", v, "default", SecretRefreshInterval)
+		} else {
+			SecretRefreshInterval = time.Duration(secs) * time.Second
+		}
+	}
+
+	CloudSQLInstance = os.Getenv("MCP_CLOUDSQL_INSTANCE")
+	if v := os.Getenv("MCP_CLOUDSQL_IAM_AUTH"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_CLOUDSQL_IAM_AUTH, using default", "value", v, "default", CloudSQLIAMAuth)
+		} else {
+			CloudSQLIAMAuth = enabled
+		}
+	}
+	if v := strings.ToLower(os.Getenv("MCP_CLOUDSQL_IP_TYPE")); v != "" {
+		switch v {
+		case CloudSQLIPPublic, CloudSQLIPPrivate:
+			CloudSQLIPType = v
+		default:
+			slog.Warn("Invalid MCP_CLOUDSQL_IP_TYPE, using default", "value", v, "default", CloudSQLIPType)
+		}
+	}
+
+	if v := os.Getenv("MCP_PG_GSSAPI"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_PG_GSSAPI, using default", "value", v, "default", PostgresGSSAPI)
+		} else {
+			PostgresGSSAPI = enabled
+		}
+	}
+	KerberosKeytab = os.Getenv("MCP_KRB5_KEYTAB")
+
+	if v := os.Getenv("MCP_AZURE_AD_AUTH"); v != "" {
+		enabled, err := strconv.ParseBool(v)
+		if err != nil {
+			slog.Warn("Invalid MCP_AZURE_AD_AUTH, using default", "value", v, "default", AzureADAuth)
+		} else {
+			AzureADAuth = enabled
+		}
+	}
+
+	SSHHost = os.Getenv("MCP_SSH_HOST")
+	SSHUser = os.Getenv("MCP_SSH_USER")
+	SSHKeyFile = os.Getenv("MCP_SSH_KEY_FILE")
+	SSHKnownHosts = os.Getenv("MCP_SSH_KNOWN_HOSTS")
+	if v := os.Getenv("MCP_SSH_KEEPALIVE"); v != "" {
+		secs, err := strconv.Atoi(v)
+		if err != nil || secs <= 0 {
+			slog.Warn("Invalid MCP_SSH_KEEPALIVE, using default", "value", v, "default", SSHKeepAlive)
+		} else {
+			SSHKeepAlive = time.Duration(secs) * time.Second
+		}
+	}
+
+	if v := os.Getenv("MCP_QUOTA_QUERIES_PER_MINUTE"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 0 {
+			slog.Warn("Invalid MCP_QUOTA_QUERIES_PER_MINUTE, using default", "value", v, "default", QuotaQueriesPerMinute)
+		} else {
+			QuotaQueriesPerMinute = limit
+		}
+	}
+
+	if v := os.Getenv("MCP_QUOTA_SESSION_ROWS"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			slog.Warn("Invalid MCP_QUOTA_SESSION_ROWS, using default", "value", v, "default", QuotaSessionRows)
+		} else {
+			QuotaSessionRows = limit
+		}
+	}
+
+	if v := os.Getenv("MCP_QUOTA_SESSION_BYTES"); v != "" {
+		limit, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || limit < 0 {
+			slog.Warn("Invalid MCP_QUOTA_SESSION_BYTES, using default", "value", v, "default", QuotaSessionBytes)
+		} else {
+			QuotaSessionBytes = limit
+		}
+	}
+
+	if v := os.Getenv("MCP_MAX_ROWS"); v != "" {
+		rows, err := strconv.Atoi(v)
+		if err != nil || rows <= 0 {
+			slog.Warn("Invalid MCP_MAX_ROWS, using default", "value", v, "default", MaxResultRows)
+		} else {
+			MaxResultRows = rows
+		}
+	}
+}
+
+// Main runs the readonly-mcp-server command using os.Args and the
+// environment, serving on stdin/stdout or, with --transport=http, over HTTP.
+// It exits the process on failure.
+func Main() {
+	setupLogging()
+	LoadEnv()
+
+	command, cmdline := parseCommand(os.Args[1:])
+	args, demo := parseDemoFlag(cmdline)
+	args, printEffective := parseEffectiveConfigFlag(args)
+	args, err := parseTransportFlags(args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if printEffective {
+		os.Exit(runPrintEffectiveConfig(cmdline, args, demo, os.Stdout))
+	}
+	if command == CommandVerifyAudit {
+		// Checking an audit log needs no database
+		os.Exit(runVerifyAudit(args))
+	}
+	if command == CommandPrintConfig {
+		os.Exit(runPrintConfig(args, os.Environ(), os.Stdout, os.Stderr))
+	}
+	if command == CommandHealthcheck && Transport == TransportHTTP {
+		// The running instance checks its own database
+		os.Exit(runHTTPHealthcheck(context.Background(), HTTPListen))
+	}
+
+	var adapter DBAdapter
+	var dsn string
+	var secrets *awsSecretSource
+	var closeDB func()
+	if demo {
+		adapter, dsn, closeDB = demoDatabase()
+	} else if len(FlatFiles) > 0 {
+		adapter, dsn, closeDB = flatFilesDatabase(FlatFiles)
+	} else {
+		adapter, dsn, secrets, closeDB = configuredDatabase(args)
+	}
+	defer closeDB()
+
+	// os.Exit skips deferred calls, so close explicitly before exiting
+	if command == CommandHealthcheck {
+		code := runHealthcheck(context.Background(), adapter, dsn)
+		closeDB()
+		os.Exit(code)
+	}
+	if command == CommandTestConn {
+		code := runTestConnection(context.Background(), os.Stdout, adapter, dsn)
+		closeDB()
+		os.Exit(code)
+	}
+
+	if PprofAddr != "" {
+		startPprof(PprofAddr)
+	}
+
+	// Create context that cancels on interrupt signals
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Handle graceful shutdown
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+
+	go func() {
+		<-sigChan
+		slog.Info("Received shutdown signal")
+		cancel()
+	}()
+
+	server, err := newServer(ctx, adapter, dsn)
+	if err != nil {
+		slog.Error("Failed to create server", "error", err)
+		os.Exit(1)
+	}
+	defer server.Close()
+
+	if secrets != nil {
+		go secrets.watch(ctx, adapter, dsn, server.rotateDSN)
+	}
+
+	if command == CommandREPL {
+		if err := server.runREPL(ctx, os.Stdin, os.Stdout); err != nil && err != context.Canceled {
+			slog.Error("REPL error", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	slog.Info("Server started (read-only mode)", "server", adapter.ServerName(), "version", ServerVersion)
+
+	if Transport == TransportHTTP {
+		if err := server.ListenAndServe(ctx, HTTPListen); err != nil && err != context.Canceled {
+			slog.Error("Server error", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("Server shutdown gracefully")
+		return
+	}
+
+	if err := server.Run(); err != nil {
+		if err == context.Canceled {
+			slog.Info("Server shutdown gracefully")
+		} else if errors.Is(err, ErrIdleTimeout) {
+			slog.Info("Server stopped", "reason", err)
+		} else {
+			slog.Error("Server error", "error", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// configuredDatabase selects the adapter and builds the DSN from the
+// environment (or the DSN argument), setting up any dialer, tunnel or
+// credential source it needs. The returned func closes the SSH tunnel.
+func configuredDatabase(args []string) (DBAdapter, string, *awsSecretSource, func()) {
+	if err := loadAdapterPlugins(AdapterPlugins); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	adapter, err := selectAdapter()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if CloudSQLInstance != "" {
+		if !adapter.Remote() {
+			slog.Error("MCP_CLOUDSQL_INSTANCE requires the mysql or postgres driver")
+			os.Exit(1)
+		}
+		dialer, err := newCloudSQLDialer(CloudSQLInstance, CloudSQLIAMAuth, CloudSQLIPType)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		dbDialer = dialer.DialContext
+	}
+
+	if AzureADAuth {
+		if CloudSQLInstance != "" {
+			slog.Error("MCP_AZURE_AD_AUTH cannot be combined with MCP_CLOUDSQL_INSTANCE")
+			os.Exit(1)
+		}
+		tokens, err := newAzureTokenSource(adapter)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		dbPasswordSource = tokens.token
+	}
+
+	closeTunnel := func() {}
+	if SSHHost != "" {
+		if !adapter.Remote() || CloudSQLInstance != "" {
+			slog.Error("MCP_SSH_HOST requires the mysql or postgres driver and cannot be combined with MCP_CLOUDSQL_INSTANCE")
+			os.Exit(1)
+		}
+		passphrase := os.Getenv("MCP_SSH_KEY_PASSPHRASE")
+		registerSecret(passphrase)
+		tunnel, err := newSSHTunnel(SSHHost, SSHUser, SSHKeyFile, passphrase, SSHKnownHosts)
+		if err != nil {
+			slog.Error(err.Error())
+			os.Exit(1)
+		}
+		closeTunnel = func() { tunnel.Close() }
+		dbDialer = tunnel.DialContext
+	}
+
+	secrets, err := newAWSSecretSource()
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	if len(args) >= 1 {
+		// An explicit DSN argument takes precedence over the secret
+		secrets = nil
+	}
+
+	dsn, err := getDSN(context.Background(), adapter, secrets, args)
+	if err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	if dsn, err = adapter.ResolveDSN(dsn); err != nil {
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+
+	return adapter, dsn, secrets, closeTunnel
+}
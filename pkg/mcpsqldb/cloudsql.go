@@ -0,0 +1,426 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CloudSQLInstance is the instance connection name (project:region:instance)
+// to connect through the Cloud SQL connector (overridable via
+// MCP_CLOUDSQL_INSTANCE env var)
+var CloudSQLInstance string
+
+// CloudSQLIAMAuth logs in as the IAM identity instead of with a database
+// password (overridable via MCP_CLOUDSQL_IAM_AUTH env var)
+var CloudSQLIAMAuth = false
+
+// CloudSQLIPType selects which instance address to dial
+// (overridable via MCP_CLOUDSQL_IP_TYPE env var)
+var CloudSQLIPType = CloudSQLIPPublic
+
+// Instance address types accepted in MCP_CLOUDSQL_IP_TYPE
+const (
+	CloudSQLIPPublic  = "public"
+	CloudSQLIPPrivate = "private"
+)
+
+// cloudSQLPort is the port the Cloud SQL server-side proxy listens on.
+var cloudSQLPort = "3307"
+
+// cloudSQLAdminURL is the Cloud SQL Admin API base URL.
+var cloudSQLAdminURL = "https://sqladmin.googleapis.com"
+
+// cloudSQLRefreshBuffer is how long before expiry the ephemeral client
+// certificate is replaced.
+const cloudSQLRefreshBuffer = 4 * time.Minute
+
+// googleScopes are requested for service account tokens; sqlservice.login is
+// needed for IAM database authentication.
+const googleScopes = "https://www.googleapis.com/auth/cloud-platform https://www.googleapis.com/auth/sqlservice.login"
+
+// cloudSQLDialer opens mutually authenticated TLS connections to a Cloud SQL
+// instance, the same way the Cloud SQL Auth Proxy does, using an ephemeral
+// client certificate issued by the Admin API.
+type cloudSQLDialer struct {
+	project  string
+	region   string
+	instance string
+	iamAuth  bool
+	ipType   string
+	key      *rsa.PrivateKey
+	tokens   googleTokenSource
+
+	mu        sync.Mutex
+	tlsConfig *tls.Config
+	addr      string
+	expires   time.Time
+}
+
+// newCloudSQLDialer parses an instance connection name of the form
+// project:region:instance (project may itself contain a domain prefix).
+func newCloudSQLDialer(connName string, iamAuth bool, ipType string) (*cloudSQLDialer, error) {
+	parts := strings.Split(connName, ":")
+	if len(parts) < 3 || parts[len(parts)-1] == "" || parts[len(parts)-2] == "" {
+		return nil, fmt.Errorf("invalid Cloud SQL instance connection name %q: expected project:region:instance", connName)
+	}
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate Cloud SQL client key: %w", err)
+	}
+
+	return &cloudSQLDialer{
+		project:  strings.Join(parts[:len(parts)-2], ":"),
+		region:   parts[len(parts)-2],
+		instance: parts[len(parts)-1],
+		iamAuth:  iamAuth,
+		ipType:   ipType,
+		key:      key,
+	}, nil
+}
+
+// DialContext ignores the address from the DSN and connects to the instance.
+func (d *cloudSQLDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	cfg, instanceAddr, err := d.connectInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", instanceAddr)
+	if err != nil {
+		return nil, err
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("Cloud SQL TLS handshake failed: %w", err)
+	}
+	return tlsConn, nil
+}
+
+// connectInfo returns the TLS config and address, refreshing the ephemeral
+// certificate when it is close to expiry.
+func (d *cloudSQLDialer) connectInfo(ctx context.Context) (*tls.Config, string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.tlsConfig == nil || time.Until(d.expires) < cloudSQLRefreshBuffer {
+		if err := d.refreshLocked(ctx); err != nil {
+			return nil, "", err
+		}
+	}
+	return d.tlsConfig, d.addr, nil
+}
+
+func (d *cloudSQLDialer) refreshLocked(ctx context.Context) error {
+	token, err := d.tokens.token(ctx)
+	if err != nil {
+		return err
+	}
+	instanceURL := fmt.Sprintf("%s/sql/v1beta4/projects/%s/instances/%s",
+		cloudSQLAdminURL, url.PathEscape(d.project), url.PathEscape(d.instance))
+
+	var settings struct {
+		ServerCACert struct {
+			Cert string `json:"cert"`
+		} `json:"serverCaCert"`
+		IPAddresses []struct {
+			Type      string `json:"type"`
+			IPAddress string `json:"ipAddress"`
+		} `json:"ipAddresses"`
+		DNSName string `json:"dnsName"`
+		Region  string `json:"region"`
+	}
+	if err := googleAPICall(ctx, http.MethodGet, instanceURL+"/connectSettings", token, nil, &settings); err != nil {
+		return fmt.Errorf("failed to get Cloud SQL connect settings: %w", err)
+	}
+	if settings.Region != "" && settings.Region != d.region {
+		return fmt.Errorf("Cloud SQL instance %s is in region %s, not %s", d.instance, settings.Region, d.region)
+	}
+
+	wantType := "PRIMARY"
+	if d.ipType == CloudSQLIPPrivate {
+		wantType = "PRIVATE"
+	}
+	var ip string
+	for _, a := range settings.IPAddresses {
+		if a.Type == wantType {
+			ip = a.IPAddress
+			break
+		}
+	}
+	if ip == "" {
+		return fmt.Errorf("Cloud SQL instance %s has no %s IP address", d.instance, d.ipType)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(settings.ServerCACert.Cert)) {
+		return fmt.Errorf("Cloud SQL returned an invalid server CA certificate")
+	}
+
+	pubDER, err := x509.MarshalPKIXPublicKey(&d.key.PublicKey)
+	if err != nil {
+		return err
+	}
+	certReq := map[string]string{
+		"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: pubDER})),
+	}
+	if d.iamAuth {
+		// Embeds the identity in the certificate so it can log in without a password
+		certReq["access_token"] = token
+	}
+	var ephemeral struct {
+		EphemeralCert struct {
+			Cert string `json:"cert"`
+		} `json:"ephemeralCert"`
+	}
+	if err := googleAPICall(ctx, http.MethodPost, instanceURL+":generateEphemeralCert", token, certReq, &ephemeral); err != nil {
+		return fmt.Errorf("failed to get Cloud SQL client certificate: %w", err)
+	}
+
+	block, _ := pem.Decode([]byte(ephemeral.EphemeralCert.Cert))
+	if block == nil {
+		return fmt.Errorf("Cloud SQL returned an invalid client certificate")
+	}
+	clientCert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("Cloud SQL returned an invalid client certificate: %w", err)
+	}
+
+	d.tlsConfig = &tls.Config{
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{block.Bytes},
+			PrivateKey:  d.key,
+			Leaf:        clientCert,
+		}},
+		MinVersion: tls.VersionTLS12,
+		// Legacy server certificates name the instance only in the CN, which
+		// crypto/tls will not match, so the chain is verified manually.
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifyCloudSQLServer(roots, d.project+":"+d.instance, settings.DNSName),
+	}
+	d.addr = net.JoinHostPort(ip, cloudSQLPort)
+	d.expires = clientCert.NotAfter
+	return nil
+}
+
+// verifyCloudSQLServer checks the server chains to the instance CA and that
+// the certificate names the instance, by CN (project:instance) or DNS name.
+func verifyCloudSQLServer(roots *x509.CertPool, instanceName, dnsName string) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("Cloud SQL server presented no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return err
+			}
+			certs[i] = cert
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates}); err != nil {
+			return err
+		}
+
+		if certs[0].Subject.CommonName == instanceName {
+			return nil
+		}
+		if dnsName != "" && certs[0].VerifyHostname(strings.TrimSuffix(dnsName, ".")) == nil {
+			return nil
+		}
+		return fmt.Errorf("Cloud SQL server certificate does not match instance %s", instanceName)
+	}
+}
+
+// googleAPICall sends a JSON request authorized with token and decodes the
+// JSON response into out.
+func googleAPICall(ctx context.Context, method, endpoint, token string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(respBody)))
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// googleTokenSource caches OAuth2 access tokens from a service account key
+// (GOOGLE_APPLICATION_CREDENTIALS) or the metadata server (GCE, GKE Workload
+// Identity, Cloud Run).
+type googleTokenSource struct {
+	mu      sync.Mutex
+	current string
+	expiry  time.Time
+}
+
+func (ts *googleTokenSource) token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.current != "" && time.Until(ts.expiry) > time.Minute {
+		return ts.current, nil
+	}
+
+	var tok struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	var err error
+	if path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); path != "" {
+		err = serviceAccountToken(ctx, path, &tok)
+	} else {
+		err = metadataToken(ctx, &tok)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get Google access token: %w", err)
+	}
+
+	ts.current = tok.AccessToken
+	ts.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return ts.current, nil
+}
+
+// metadataToken fetches the attached service account's token.
+func metadataToken(ctx context.Context, out any) error {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = "metadata.google.internal"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// serviceAccountToken exchanges a signed JWT from a service account key file
+// for an access token.
+func serviceAccountToken(ctx context.Context, path string, out any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var key struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}
+	if err := json.Unmarshal(data, &key); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if key.Type != "service_account" {
+		return fmt.Errorf("%s is a %q credential; only service account keys are supported", path, key.Type)
+	}
+
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return fmt.Errorf("%s has no PEM private key", path)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return fmt.Errorf("failed to parse private key in %s: %w", path, err)
+	}
+	rsaKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return fmt.Errorf("private key in %s is not RSA", path)
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   key.ClientEmail,
+		"scope": googleScopes,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return err
+	}
+	assertion := unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := cloudHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint returned %s", resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,207 @@
+package mcpsqldb
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA issues certificates for a fake Cloud SQL instance.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test Cloud SQL CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+func (ca *testCA) issue(t *testing.T, cn string, pub any, usage x509.ExtKeyUsage) []byte {
+	t.Helper()
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, pub, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	return der
+}
+
+// startFakeCloudSQL serves the Admin API, the metadata token endpoint, and a
+// TLS listener that requires a client certificate from the CA.
+func startFakeCloudSQL(t *testing.T, serverCN string) {
+	t.Helper()
+	ca := newTestCA(t)
+
+	serverKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	serverCert := tls.Certificate{
+		Certificate: [][]byte{ca.issue(t, serverCN, &serverKey.PublicKey, x509.ExtKeyUsageServerAuth)},
+		PrivateKey:  serverKey,
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("ok"))
+			conn.Close()
+		}
+	}()
+
+	admin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/projects/proj/instances/inst/connectSettings"):
+			json.NewEncoder(w).Encode(map[string]any{
+				"serverCaCert": map[string]string{"cert": ca.pem},
+				"ipAddresses":  []map[string]string{{"type": "PRIMARY", "ipAddress": "127.0.0.1"}},
+				"region":       "us-central1",
+			})
+		case strings.HasSuffix(r.URL.Path, "/projects/proj/instances/inst:generateEphemeralCert"):
+			var req struct {
+				PublicKey string `json:"public_key"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			block, _ := pem.Decode([]byte(req.PublicKey))
+			pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			der := ca.issue(t, "client", pub, x509.ExtKeyUsageClientAuth)
+			json.NewEncoder(w).Encode(map[string]any{
+				"ephemeralCert": map[string]string{"cert": string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(admin.Close)
+
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"access_token":"test-token","expires_in":3600}`))
+	}))
+	t.Cleanup(metadata.Close)
+
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(metadata.URL, "http://"))
+
+	originalAdmin, originalPort := cloudSQLAdminURL, cloudSQLPort
+	cloudSQLAdminURL = admin.URL
+	_, cloudSQLPort, _ = net.SplitHostPort(listener.Addr().String())
+	t.Cleanup(func() { cloudSQLAdminURL, cloudSQLPort = originalAdmin, originalPort })
+}
+
+func TestNewCloudSQLDialer_ParsesConnectionName(t *testing.T) {
+	d, err := newCloudSQLDialer("example.com:proj:us-central1:inst", false, CloudSQLIPPublic)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if d.project != "example.com:proj" || d.region != "us-central1" || d.instance != "inst" {
+		t.Errorf("Unexpected parse: project=%q region=%q instance=%q", d.project, d.region, d.instance)
+	}
+
+	for _, bad := range []string{"inst", "proj:inst", "proj:us-central1:"} {
+		if _, err := newCloudSQLDialer(bad, false, CloudSQLIPPublic); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestCloudSQLDialer_ConnectsWithEphemeralCert(t *testing.T) {
+	startFakeCloudSQL(t, "proj:inst")
+
+	d, err := newCloudSQLDialer("proj:us-central1:inst", false, CloudSQLIPPublic)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := d.DialContext(ctx, "tcp", "ignored:3306")
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close()
+
+	got, _ := io.ReadAll(conn)
+	if string(got) != "ok" {
+		t.Errorf("Expected server greeting, got %q", got)
+	}
+}
+
+func TestCloudSQLDialer_RejectsWrongServer(t *testing.T) {
+	startFakeCloudSQL(t, "proj:other-instance")
+
+	d, _ := newCloudSQLDialer("proj:us-central1:inst", false, CloudSQLIPPublic)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if conn, err := d.DialContext(ctx, "tcp", "ignored:3306"); err == nil {
+		conn.Close()
+		t.Error("Expected certificate for another instance to be rejected")
+	}
+}
+
+func TestCloudSQLDialer_MissingPrivateIP(t *testing.T) {
+	startFakeCloudSQL(t, "proj:inst")
+
+	d, _ := newCloudSQLDialer("proj:us-central1:inst", false, CloudSQLIPPrivate)
+	if _, err := d.DialContext(context.Background(), "tcp", "ignored:3306"); err == nil || !strings.Contains(err.Error(), "private") {
+		t.Errorf("Expected missing private IP error, got %v", err)
+	}
+}
@@ -0,0 +1,134 @@
+package mcpsqldb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCockroachValidateQuery(t *testing.T) {
+	adapter := &CockroachAdapter{}
+	allowedQueries := []string{
+		"SELECT * FROM users AS OF SYSTEM TIME '-10s'",
+		"SELECT * FROM crdb_internal.node_statement_statistics",
+		"SHOW TABLES",
+		"SHOW COLUMNS FROM users",
+		"EXPLAIN ANALYZE SELECT * FROM users",
+		"SELECT 'crdb_internal.force_panic(1)' AS note",
+	}
+	for _, query := range allowedQueries {
+		if err := adapter.ValidateQuery(query); err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", query, err)
+		}
+	}
+
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"SELECT crdb_internal.force_panic('boom')", "crdb_internal functions"},
+		{"SELECT crdb_internal . unsafe_upsert_descriptor(1, NULL)", "crdb_internal functions"},
+		{"SELECT crdb_internal.reset_sql_stats()", "crdb_internal functions"},
+		{"SHOW BACKUP 's3://bucket/backup?AUTH=implicit'", "SHOW BACKUP"},
+		{"EXPLAIN ANALYZE (DEBUG) SELECT * FROM users", "EXPLAIN ANALYZE (DEBUG)"},
+		{"SELECT pg_sleep(100)", "pg_sleep()"},
+		{"INSERT INTO users VALUES (1)", "INSERT"},
+	}
+	for _, tc := range blockedQueries {
+		if err := adapter.ValidateQuery(tc.query); err == nil {
+			t.Errorf("Expected %q to be blocked for %s", tc.query, tc.shouldBlock)
+		}
+	}
+}
+
+func TestParseCockroachPlan(t *testing.T) {
+	lines := []string{
+		"distribution: local",
+		"vectorized: true",
+		"",
+		"• hash join",
+		"│ estimated row count: 1,200",
+		"│ equality: (id) = (user_id)",
+		"│",
+		"├── • scan",
+		"│     estimated row count: 3 (100% of the table; stats collected 2 minutes ago)",
+		"│     table: users@users_pkey",
+		"│     spans: FULL SCAN",
+		"│",
+		"└── • filter",
+		"    │ filter: total > 10",
+		"    │",
+		"    └── • scan",
+		"          table: orders@orders_user_idx",
+		"          spans: [/1 - /5]",
+	}
+
+	nodes := parseCockroachPlan(lines)
+	if len(nodes) != 1 || nodes[0].Operation != "hash join" || len(nodes[0].Children) != 2 {
+		t.Fatalf("Expected a hash join with two children, got %+v", nodes)
+	}
+	join := nodes[0]
+	if join.EstimatedRows == nil || *join.EstimatedRows != 1200 || join.Detail != "equality: (id) = (user_id)" {
+		t.Errorf("Expected the join's estimate and condition, got %+v", join)
+	}
+	users := join.Children[0]
+	if users.Table != "users" || users.Index != "" || users.Access != PlanFullScan || *users.EstimatedRows != 3 {
+		t.Errorf("Expected a full scan of users, got %+v", users)
+	}
+	filter := join.Children[1]
+	if filter.Operation != "filter" || filter.Detail != "filter: total > 10" || len(filter.Children) != 1 {
+		t.Fatalf("Expected a filter over the orders scan, got %+v", filter)
+	}
+	orders := filter.Children[0]
+	if orders.Table != "orders" || orders.Index != "orders_user_idx" || orders.Access != PlanIndexLookup {
+		t.Errorf("Expected an index lookup of orders, got %+v", orders)
+	}
+}
+
+func TestParseCockroachCost(t *testing.T) {
+	lines := []string{
+		"project",
+		" ├── columns: name:2",
+		" ├── stats: [rows=3]",
+		" ├── cost: 24.1350000",
+		" └── scan users",
+		"      └── cost: 23.1",
+	}
+	if cost, err := parseCockroachCost(lines); err != nil || cost != 24.135 {
+		t.Errorf("Expected the root cost 24.135, got %v (err %v)", cost, err)
+	}
+	if _, err := parseCockroachCost([]string{"project"}); err == nil {
+		t.Error("Expected an error for a plan without cost")
+	}
+}
+
+func TestCockroachAdapter_Catalog(t *testing.T) {
+	adapter := &CockroachAdapter{}
+
+	if query, _ := adapter.ListTablesQuery("app"); !strings.Contains(query, "table_type IN ('BASE TABLE', 'VIEW')") {
+		t.Errorf("Expected sequences to be left out of the tables, got %s", query)
+	}
+	if query, _ := adapter.ReadSchemaQuery("app", "users"); !strings.Contains(query, "is_hidden = 'NO'") {
+		t.Errorf("Expected hidden columns to be left out of the schema, got %s", query)
+	}
+	if dsn, _ := adapter.FormatDSN(ConnParams{Host: "crdb", Database: "app", User: "reader", Password: "pw"}); !strings.Contains(dsn, "@crdb:26257/app") {
+		t.Errorf("Expected the CockroachDB port by default, got %s", dsn)
+	}
+	if query, _ := adapter.SampleQuery(nil, nil, "app", "users", 5); strings.Contains(query, "TABLESAMPLE") {
+		t.Errorf("Expected no TABLESAMPLE, got %s", query)
+	}
+}
+
+func TestNewAdapter_Cockroach(t *testing.T) {
+	for _, name := range []string{"cockroach", "CockroachDB"} {
+		adapter, err := newAdapter(name)
+		if err != nil {
+			t.Fatalf("Expected %s to be registered, got %v", name, err)
+		}
+		if _, ok := adapter.(*CockroachAdapter); !ok {
+			t.Errorf("Expected a CockroachAdapter for %s, got %T", name, adapter)
+		}
+		if adapter.DriverName() != "postgres" || adapter.URIScheme() != "cockroachdb" {
+			t.Errorf("Expected the postgres driver and cockroachdb URIs, got %s and %s", adapter.DriverName(), adapter.URIScheme())
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaxResultColumns caps the columns returned by SELECT * queries; 0 returns
+// every column (overridable via MCP_MAX_COLUMNS env var). Queries naming
+// their columns are never capped.
+var MaxResultColumns = 0
+
+// selectStarPattern matches a * or table.* in a select list, but not
+// COUNT(*) or multiplication.
+var selectStarPattern = regexp.MustCompile(`(?i)(?:\bSELECT\s+(?:DISTINCT\s+|ALL\s+)?|,\s*)(?:[\w"` + "`" + `\[\]]+\s*\.\s*)?\*`)
+
+// columnsOmitted is the notice appended to a capped result, in a row of its
+// own under the "_columns_omitted" key.
+type columnsOmitted struct {
+	Message string   `json:"message"`
+	Shown   int      `json:"shown"`
+	Total   int      `json:"total"`
+	Columns []string `json:"columns"`
+}
+
+// cappedColumns returns how many of columns to return for sqlQuery, and a
+// notice listing the rest when any are omitted.
+func (s *Server) cappedColumns(sqlQuery string, columns []string) (int, *columnsOmitted) {
+	if MaxResultColumns <= 0 || len(columns) <= MaxResultColumns ||
+		!selectStarPattern.MatchString(s.adapter.RemoveStringsAndComments(sqlQuery)) {
+		return len(columns), nil
+	}
+	return MaxResultColumns, &columnsOmitted{
+		Message: fmt.Sprintf("SELECT * returned %d columns; showing the first %d. Name the columns to see the others",
+			len(columns), MaxResultColumns),
+		Shown:   MaxResultColumns,
+		Total:   len(columns),
+		Columns: columns[MaxResultColumns:],
+	}
+}
+
+// resultNotice returns the message of a notice row (the truncation warning
+// or the omitted columns) appended to a query result.
+func resultNotice(row map[string]any) (string, bool) {
+	if len(row) != 1 {
+		return "", false
+	}
+	if msg, ok := row["_warning"].(string); ok {
+		return msg, true
+	}
+	if notice, ok := row["_columns_omitted"].(map[string]any); ok {
+		msg, _ := notice["message"].(string)
+		return msg, true
+	}
+	return "", false
+}
@@ -0,0 +1,96 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newWideTestServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE wide (c1 INTEGER, c2 TEXT, c3 TEXT, c4 TEXT, c5 TEXT)",
+		"INSERT INTO wide VALUES (1, 'b', 'c', 'd', 'e'), (2, 'f', 'g', 'h', 'i')",
+	)
+}
+
+func queryRows(t *testing.T, server *Server, query string) []map[string]any {
+	t.Helper()
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": query})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows for %q, got %v %+v", query, rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	return rows
+}
+
+func TestMaxResultColumns_CapsSelectStar(t *testing.T) {
+	defer func(orig int) { MaxResultColumns = orig }(MaxResultColumns)
+	MaxResultColumns = 2
+	server := newWideTestServer(t)
+
+	for _, query := range []string{"SELECT * FROM wide ORDER BY c1", "SELECT w.* FROM wide w ORDER BY c1"} {
+		rows := queryRows(t, server, query)
+		if len(rows) != 3 {
+			t.Fatalf("Expected 2 rows and a notice for %q, got %v", query, rows)
+		}
+		if want := map[string]any{"c1": float64(1), "c2": "b"}; !reflect.DeepEqual(rows[0], want) {
+			t.Errorf("Expected the first 2 columns %v, got %v", want, rows[0])
+		}
+		notice, _ := rows[2]["_columns_omitted"].(map[string]any)
+		if notice["shown"] != float64(2) || notice["total"] != float64(5) ||
+			!reflect.DeepEqual(notice["columns"], []any{"c3", "c4", "c5"}) {
+			t.Errorf("Expected a notice listing c3-c5, got %v", rows[2])
+		}
+	}
+}
+
+func TestMaxResultColumns_NamedColumnsNotCapped(t *testing.T) {
+	defer func(orig int) { MaxResultColumns = orig }(MaxResultColumns)
+	MaxResultColumns = 2
+	server := newWideTestServer(t)
+
+	for _, query := range []string{
+		"SELECT c1, c2, c3, c4, c5 FROM wide",
+		"SELECT c1, c2 * 2, '*', c3, COUNT(*) FROM wide /* * */ GROUP BY c1",
+		"SELECT * FROM users",
+	} {
+		for _, row := range queryRows(t, server, query) {
+			if _, ok := row["_columns_omitted"]; ok {
+				t.Errorf("Expected %q not to be capped, got %v", query, row)
+			}
+		}
+	}
+
+	MaxResultColumns = 0
+	if rows := queryRows(t, server, "SELECT * FROM wide"); len(rows) != 2 || len(rows[0]) != 5 {
+		t.Errorf("Expected every column without a cap, got %v", rows)
+	}
+}
+
+func TestMaxResultColumns_NoticeIsNotARow(t *testing.T) {
+	defer func(orig int) { MaxResultColumns = orig }(MaxResultColumns)
+	MaxResultColumns = 2
+	server := newWideTestServer(t)
+
+	result, _ := server.callTool(context.Background(), "compare_queries", map[string]any{
+		"before_sql":  "SELECT * FROM wide",
+		"after_sql":   "SELECT * FROM wide",
+		"key_columns": []any{"c1"},
+	})
+	var diff queryDiff
+	json.Unmarshal([]byte(result.Content[0].Text), &diff)
+	if diff.Unchanged != 2 || len(diff.Warnings) != 2 || !strings.Contains(diff.Warnings[0], "Name the columns") {
+		t.Errorf("Expected the notice as a warning, got %s", result.Content[0].Text)
+	}
+
+	out := runTestREPL(t, server, "SELECT * FROM wide\n")
+	if !strings.Contains(out, "(2 rows)") || !strings.Contains(out, "Warning: SELECT * returned 5 columns") {
+		t.Errorf("Expected the notice as a REPL warning, got:\n%s", out)
+	}
+}
@@ -0,0 +1,240 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// rowChange is a row present in both results whose non-key columns differ.
+type rowChange struct {
+	Key     map[string]any            `json:"key"`
+	Changes map[string]map[string]any `json:"changes"`
+}
+
+// queryDiff is the compare_queries result.
+type queryDiff struct {
+	KeyColumns []string         `json:"key_columns"`
+	Added      []map[string]any `json:"added"`
+	Removed    []map[string]any `json:"removed"`
+	Changed    []rowChange      `json:"changed"`
+	Unchanged  int              `json:"unchanged"`
+	Warnings   []string         `json:"warnings,omitempty"`
+}
+
+// compareQueries runs two read-only queries and reports rows added, removed
+// and changed in the second relative to the first, matching rows by the
+// given key columns. Both queries go through the same validation, limits,
+// masking and audit as the query tool.
+func (s *Server) compareQueries(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	beforeSQL, _ := args["before_sql"].(string)
+	afterSQL, _ := args["after_sql"].(string)
+	if beforeSQL == "" || afterSQL == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'before_sql' or 'after_sql' parameter",
+		}
+	}
+	keyColumns, ok := stringList(args["key_columns"])
+	if !ok || len(keyColumns) == 0 {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'key_columns' parameter: expected a non-empty array of column names",
+		}
+	}
+
+	params := []string{"before_sql", "after_sql"}
+	queries := []string{beforeSQL, afterSQL}
+	for i, param := range params {
+		validated, err := s.validateQuery(queries[i])
+		if err != nil {
+			stats.queriesRejected.Add(1)
+			s.audit.query(ctx, s.adapter, s.sessionID, queries[i], AuditOutcomeRejected, 0, 0, err.Error())
+			return withErrorKind(&CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected (%s): %v", param, err)}},
+				IsError: true,
+			}, ErrorKindValidationRejected), nil
+		}
+		queries[i] = validated
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	diff := queryDiff{KeyColumns: keyColumns}
+	var results [2][]map[string]any
+	for i, param := range params {
+		result := s.runQuery(ctx, queries[i])
+		if result.IsError {
+			return &CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("%s failed: %s", param, result.Content[0].Text)}},
+				IsError: true,
+				Meta:    result.Meta,
+			}, nil
+		}
+		rows, warning, err := decodeRows(result.Content[0].Text)
+		if err != nil {
+			return nil, &Error{
+				Code:    InternalError,
+				Message: fmt.Sprintf("Failed to decode %s result: %v", param, err),
+			}
+		}
+		if warning != "" {
+			diff.Warnings = append(diff.Warnings, fmt.Sprintf("%s: %s; the diff may be incomplete", param, warning))
+		}
+		results[i] = rows
+	}
+
+	if err := diffRows(&diff, results[0], results[1]); err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Cannot compare results: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+
+	resultJSON, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(resultJSON)}},
+	}, nil
+}
+
+// decodeRows parses a query tool result, separating out the truncation
+// and omitted column notice rows. Numbers are kept as json.Number so values compare exactly.
+func decodeRows(text string) ([]map[string]any, string, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var rows []map[string]any
+	if err := decoder.Decode(&rows); err != nil {
+		return nil, "", err
+	}
+	var warnings []string
+	for n := len(rows); n > 0; n-- {
+		msg, ok := resultNotice(rows[n-1])
+		if !ok {
+			break
+		}
+		warnings = append([]string{msg}, warnings...)
+		rows = rows[:n-1]
+	}
+	return rows, strings.Join(warnings, "; "), nil
+}
+
+// diffRows fills diff by matching before and after rows on diff.KeyColumns,
+// keeping each result's row order.
+func diffRows(diff *queryDiff, before, after []map[string]any) error {
+	beforeByKey, err := indexRows("before_sql", before, diff.KeyColumns)
+	if err != nil {
+		return err
+	}
+	afterByKey, err := indexRows("after_sql", after, diff.KeyColumns)
+	if err != nil {
+		return err
+	}
+
+	diff.Added, diff.Removed, diff.Changed = []map[string]any{}, []map[string]any{}, []rowChange{}
+	for _, row := range after {
+		old, ok := beforeByKey[rowKey(row, diff.KeyColumns)]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		if changes := changedColumns(old, row, diff.KeyColumns); len(changes) > 0 {
+			key := map[string]any{}
+			for _, col := range diff.KeyColumns {
+				key[col] = row[col]
+			}
+			diff.Changed = append(diff.Changed, rowChange{Key: key, Changes: changes})
+		} else {
+			diff.Unchanged++
+		}
+	}
+	for _, row := range before {
+		if _, ok := afterByKey[rowKey(row, diff.KeyColumns)]; !ok {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return nil
+}
+
+func indexRows(param string, rows []map[string]any, keyColumns []string) (map[string]map[string]any, error) {
+	index := make(map[string]map[string]any, len(rows))
+	for _, row := range rows {
+		for _, col := range keyColumns {
+			if _, ok := row[col]; !ok {
+				return nil, fmt.Errorf("key column %q is not in the %s result", col, param)
+			}
+		}
+		key := rowKey(row, keyColumns)
+		if _, dup := index[key]; dup {
+			return nil, fmt.Errorf("key columns %s are not unique in the %s result (duplicate key %s)",
+				strings.Join(keyColumns, ", "), param, key)
+		}
+		index[key] = row
+	}
+	return index, nil
+}
+
+// rowKey encodes the key column values of row as a comparable string.
+func rowKey(row map[string]any, keyColumns []string) string {
+	values := make([]any, len(keyColumns))
+	for i, col := range keyColumns {
+		values[i] = row[col]
+	}
+	key, _ := json.Marshal(values)
+	return string(key)
+}
+
+// changedColumns returns the before and after values of every non-key
+// column that differs between the two rows.
+func changedColumns(before, after map[string]any, keyColumns []string) map[string]map[string]any {
+	columns := map[string]bool{}
+	for col := range before {
+		columns[col] = true
+	}
+	for col := range after {
+		columns[col] = true
+	}
+	for _, col := range keyColumns {
+		delete(columns, col)
+	}
+
+	names := make([]string, 0, len(columns))
+	for col := range columns {
+		names = append(names, col)
+	}
+	sort.Strings(names)
+
+	changes := map[string]map[string]any{}
+	for _, col := range names {
+		if !reflect.DeepEqual(before[col], after[col]) {
+			changes[col] = map[string]any{"before": before[col], "after": after[col]}
+		}
+	}
+	return changes
+}
+
+// stringList converts a JSON array argument to strings.
+func stringList(v any) ([]string, bool) {
+	items, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		s, ok := item.(string)
+		if !ok || s == "" {
+			return nil, false
+		}
+		list = append(list, s)
+	}
+	return list, true
+}
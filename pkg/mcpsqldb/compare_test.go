@@ -0,0 +1,86 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCompareQueries(t *testing.T) {
+	server := newDemoServer(t)
+
+	result, rpcErr := server.callTool(context.Background(), "compare_queries", map[string]any{
+		"before_sql":  "SELECT id, status FROM orders WHERE id <= 4",
+		"after_sql":   "SELECT id, CASE WHEN id = 2 THEN 'returned' ELSE status END AS status FROM orders WHERE id BETWEEN 2 AND 5",
+		"key_columns": []any{"id"},
+	})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected a diff, got %v %+v", rpcErr, result)
+	}
+
+	var diff queryDiff
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &diff); err != nil {
+		t.Fatalf("Failed to parse diff: %v", err)
+	}
+	if len(diff.Added) != 1 || diff.Added[0]["id"] != float64(5) {
+		t.Errorf("Expected order 5 added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0]["id"] != float64(1) {
+		t.Errorf("Expected order 1 removed, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0].Key["id"] != float64(2) ||
+		diff.Changed[0].Changes["status"]["before"] != "delivered" || diff.Changed[0].Changes["status"]["after"] != "returned" {
+		t.Errorf("Expected order 2 status change, got %+v", diff.Changed)
+	}
+	if diff.Unchanged != 2 {
+		t.Errorf("Expected 2 unchanged rows, got %d", diff.Unchanged)
+	}
+}
+
+func TestCompareQueries_Errors(t *testing.T) {
+	server := newDemoServer(t)
+
+	tests := []struct {
+		name    string
+		args    map[string]any
+		wantRPC bool
+		want    string
+	}{
+		{"missing key columns", map[string]any{"before_sql": "SELECT 1", "after_sql": "SELECT 1"}, true, "key_columns"},
+		{"rejected query", map[string]any{"before_sql": "SELECT id FROM orders", "after_sql": "DELETE FROM orders", "key_columns": []any{"id"}}, false, "Query rejected (after_sql)"},
+		{"unknown key", map[string]any{"before_sql": "SELECT id FROM orders", "after_sql": "SELECT id FROM orders", "key_columns": []any{"sku"}}, false, `key column "sku" is not in the before_sql result`},
+		{"duplicate key", map[string]any{"before_sql": "SELECT customer_id FROM orders", "after_sql": "SELECT customer_id FROM orders", "key_columns": []any{"customer_id"}}, false, "not unique in the before_sql result"},
+		{"query error", map[string]any{"before_sql": "SELECT id FROM missing", "after_sql": "SELECT id FROM orders", "key_columns": []any{"id"}}, false, "before_sql failed"},
+	}
+	for _, tc := range tests {
+		result, rpcErr := server.callTool(context.Background(), "compare_queries", tc.args)
+		if tc.wantRPC {
+			if rpcErr == nil || !strings.Contains(rpcErr.Message, tc.want) {
+				t.Errorf("%s: expected RPC error containing %q, got %v", tc.name, tc.want, rpcErr)
+			}
+			continue
+		}
+		if rpcErr != nil || !result.IsError || !strings.Contains(result.Content[0].Text, tc.want) {
+			t.Errorf("%s: expected tool error containing %q, got %v %+v", tc.name, tc.want, rpcErr, result)
+		}
+	}
+}
+
+func TestCompareQueries_WarnsOnTruncation(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 3
+
+	server := newDemoServer(t)
+	result, _ := server.callTool(context.Background(), "compare_queries", map[string]any{
+		"before_sql":  "SELECT id FROM orders",
+		"after_sql":   "SELECT id FROM orders",
+		"key_columns": []any{"id"},
+	})
+
+	var diff queryDiff
+	json.Unmarshal([]byte(result.Content[0].Text), &diff)
+	if diff.Unchanged != 3 || len(diff.Warnings) != 2 || !strings.Contains(diff.Warnings[0], "may be incomplete") {
+		t.Errorf("Expected truncation warnings, got %+v", diff)
+	}
+}
@@ -0,0 +1,206 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+)
+
+// readOnlyConnector wraps a driver.Connector and runs the adapter's read-only
+// statements on every connection it opens, so no pool member ever serves a
+// query without session-level read-only enforcement. The underlying connector
+// can be swapped with setDSN when credentials rotate.
+type readOnlyConnector struct {
+	mu         sync.RWMutex
+	connector  driver.Connector
+	adapter    DBAdapter
+	drv        driver.Driver
+	statements []string
+	// label names the connections to the database, see connectionLabel
+	label string
+}
+
+// dbDialer, when set, replaces the drivers' own network dialing (e.g. for
+// the Cloud SQL connector).
+var dbDialer DialFunc
+
+// dbPasswordSource, when set, supplies a short-lived access token that is
+// used as the password for each new connection (e.g. Azure AD).
+var dbPasswordSource func(ctx context.Context) (string, error)
+
+// RequireTLS refuses DSNs whose connections would not verify the server's
+// certificate (overridable via MCP_REQUIRE_TLS env var)
+var RequireTLS = false
+
+// connectionLabel is the name a session's connections show to the database,
+// so DBAs can tell MCP queries apart in their own monitoring.
+func connectionLabel(sessionID string) string {
+	return "mcp-readonly-sql/" + ServerVersion + "/" + sessionID
+}
+
+// newReadOnlyConnector builds a connector for dsn using the adapter's
+// registered driver, naming its connections label unless it is empty.
+func newReadOnlyConnector(adapter DBAdapter, dsn, label string) (*readOnlyConnector, error) {
+	// sql.Open does not connect; it is only used to look up the registered driver.
+	probe, err := sql.Open(adapter.DriverName(), dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := probe.Driver()
+	probe.Close()
+
+	c := &readOnlyConnector{adapter: adapter, drv: drv, statements: adapter.ReadOnlyStatements(), label: label}
+	if err := c.setDSN(dsn); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// setDSN makes new connections use dsn. Connections already in the pool keep
+// their original session until they are recycled.
+func (c *readOnlyConnector) setDSN(dsn string) error {
+	if c.label != "" {
+		var err error
+		if dsn, err = c.adapter.WithConnectionLabel(dsn, c.label); err != nil {
+			return err
+		}
+	}
+	if err := c.checkTLS(dsn); err != nil {
+		return err
+	}
+
+	var connector driver.Connector
+	if dbPasswordSource != nil {
+		// Check the DSN now rather than on every connect
+		if _, err := c.adapter.WithAccessToken(dsn, ""); err != nil {
+			return err
+		}
+		connector = &tokenConnector{dsn: dsn, parent: c}
+	} else {
+		var err error
+		if connector, err = c.open(dsn); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	c.connector = connector
+	c.mu.Unlock()
+	return nil
+}
+
+// checkTLS enforces RequireTLS against the DSN connections will actually
+// use. The Cloud SQL dialer always verifies the instance certificate.
+func (c *readOnlyConnector) checkTLS(dsn string) error {
+	if !RequireTLS || CloudSQLInstance != "" {
+		return nil
+	}
+	effective := dsn
+	if dbPasswordSource != nil {
+		var err error
+		if effective, err = c.adapter.WithAccessToken(dsn, ""); err != nil {
+			return err
+		}
+	}
+	if !c.adapter.TLSVerified(effective) {
+		return fmt.Errorf("MCP_REQUIRE_TLS is set but the %s connection does not verify the server certificate (use %s)",
+			c.adapter.DriverName(), verifiedTLSHint[c.adapter.DriverName()])
+	}
+	return nil
+}
+
+// verifiedTLSHint names the DSN setting that enables certificate
+// verification for each driver.
+var verifiedTLSHint = map[string]string{
+	"mysql":    "tls=true",
+	"postgres": "sslmode=verify-full or verify-ca",
+	"pgx":      "sslmode=verify-full or verify-ca",
+}
+
+// open builds a driver connector for dsn.
+func (c *readOnlyConnector) open(dsn string) (driver.Connector, error) {
+	if dbDialer != nil {
+		return c.adapter.ConnectorWithDialer(dsn, dbDialer)
+	}
+	if dc, ok := c.drv.(driver.DriverContext); ok {
+		return dc.OpenConnector(dsn)
+	}
+	return &dsnConnector{dsn: dsn, drv: c.drv}, nil
+}
+
+func (c *readOnlyConnector) Driver() driver.Driver { return c.drv }
+
+func (c *readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	c.mu.RLock()
+	connector := c.connector
+	c.mu.RUnlock()
+
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		conn.Close()
+		return nil, fmt.Errorf("driver connection does not support ExecContext")
+	}
+
+	for _, stmt := range c.statements {
+		if _, err := execer.ExecContext(ctx, stmt, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to enforce read-only mode: %w", err)
+		}
+	}
+	return conn, nil
+}
+
+// dsnConnector adapts a driver that does not implement driver.DriverContext.
+type dsnConnector struct {
+	dsn string
+	drv driver.Driver
+}
+
+func (c *dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return c.drv.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver { return c.drv }
+
+// tokenConnector fetches a fresh access token for every connection, so
+// tokens expiring during the pool's lifetime are never reused.
+type tokenConnector struct {
+	dsn    string
+	parent *readOnlyConnector
+}
+
+func (c *tokenConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := dbPasswordSource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dsn, err := c.parent.adapter.WithAccessToken(c.dsn, token)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := c.parent.open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+func (c *tokenConnector) Driver() driver.Driver { return c.parent.drv }
+
+// openReadOnlyDB opens a connection pool whose every connection has the
+// adapter's read-only statements applied before it is handed out, and is
+// named label unless it is empty.
+func openReadOnlyDB(adapter DBAdapter, dsn, label string) (*sql.DB, error) {
+	connector, err := newReadOnlyConnector(adapter, dsn, label)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(connector), nil
+}
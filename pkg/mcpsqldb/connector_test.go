@@ -0,0 +1,142 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadOnlyConnector_AllPoolMembersRejectWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open setup database: %v", err)
+	}
+	if _, err := setup.Exec("CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	setup.Close()
+
+	// Deliberately omit ?mode=ro so only the connector hook protects the pool
+	db, err := openReadOnlyDB(&SQLiteAdapter{}, path, "")
+	if err != nil {
+		t.Fatalf("Failed to open read-only database: %v", err)
+	}
+	defer db.Close()
+
+	const poolSize = 4
+	db.SetMaxOpenConns(poolSize)
+
+	ctx := context.Background()
+	conns := make([]*sql.Conn, 0, poolSize)
+	for i := 0; i < poolSize; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to acquire connection %d: %v", i, err)
+		}
+		defer conn.Close()
+		conns = append(conns, conn)
+	}
+
+	for i, conn := range conns {
+		if _, err := conn.ExecContext(ctx, "INSERT INTO users (name) VALUES ('x')"); err == nil {
+			t.Errorf("Expected write to fail on pooled connection %d", i)
+		}
+	}
+}
+
+func TestReadOnlyConnector_SetDSNAffectsNewConnections(t *testing.T) {
+	first := newTestDB(t)
+	second := newTestDB(t, "CREATE TABLE rotated (id INTEGER)")
+
+	connector, err := newReadOnlyConnector(&SQLiteAdapter{}, first, "")
+	if err != nil {
+		t.Fatalf("Failed to create connector: %v", err)
+	}
+	db := sql.OpenDB(connector)
+	defer db.Close()
+	// Without idle connections every query opens a fresh one
+	db.SetMaxIdleConns(0)
+
+	if _, err := db.Exec("SELECT * FROM rotated"); err == nil {
+		t.Fatal("Expected rotated table to be missing before setDSN")
+	}
+	if err := connector.setDSN(second); err != nil {
+		t.Fatalf("Failed to set DSN: %v", err)
+	}
+	if _, err := db.Exec("SELECT * FROM rotated"); err != nil {
+		t.Errorf("Expected new connections to use the new DSN: %v", err)
+	}
+}
+
+func TestReadOnlyConnector_RequireTLS(t *testing.T) {
+	defer func(orig bool) { RequireTLS = orig }(RequireTLS)
+	RequireTLS = true
+
+	tests := []struct {
+		adapter  DBAdapter
+		dsn      string
+		verified bool
+	}{
+		{&PostgresAdapter{}, "postgres://ro:pw@db:5432/app?sslmode=verify-full", true},
+		{&PostgresAdapter{}, "postgres://ro:pw@db:5432/app?sslmode=verify-ca", true},
+		{&PostgresAdapter{}, "postgres://ro:pw@db:5432/app?sslmode=require", false},
+		{&PostgresAdapter{}, "postgres://ro:pw@db:5432/app?sslmode=disable", false},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app?tls=true", true},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app?tls=skip-verify", false},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app?tls=preferred", false},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app", false},
+	}
+	for _, tc := range tests {
+		_, err := newReadOnlyConnector(tc.adapter, tc.dsn, "")
+		if tc.verified && err != nil {
+			t.Errorf("%s: expected verified TLS to be accepted, got %v", tc.dsn, err)
+		}
+		if !tc.verified && (err == nil || !strings.Contains(err.Error(), "MCP_REQUIRE_TLS")) {
+			t.Errorf("%s: expected MCP_REQUIRE_TLS error, got %v", tc.dsn, err)
+		}
+	}
+
+	// SQLite files are local and have no connection to verify
+	if _, err := newReadOnlyConnector(&SQLiteAdapter{}, newTestDB(t), ""); err != nil {
+		t.Errorf("Expected SQLite to be unaffected, got %v", err)
+	}
+}
+
+func TestWithConnectionLabel(t *testing.T) {
+	t.Setenv("PGAPPNAME", "")
+	label := connectionLabel("0123abcd")
+	if label != "mcp-readonly-sql/"+ServerVersion+"/0123abcd" {
+		t.Errorf("Expected the label to name the version and session, got %q", label)
+	}
+
+	tests := []struct {
+		adapter DBAdapter
+		dsn     string
+		want    string
+	}{
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app", "ro:pw@tcp(db:3306)/app?connectionAttributes=program_name%3Amcp%2Fv1%2Fs1"},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app?connectionAttributes=team:data",
+			"ro:pw@tcp(db:3306)/app?connectionAttributes=team%3Adata%2Cprogram_name%3Amcp%2Fv1%2Fs1"},
+		{&MySQLAdapter{}, "ro:pw@tcp(db:3306)/app?connectionAttributes=program_name:bi", "ro:pw@tcp(db:3306)/app?connectionAttributes=program_name:bi"},
+		{&PostgresAdapter{}, "postgres://ro@db:5432/app?sslmode=require", "postgres://ro@db:5432/app?application_name=mcp%2Fv1%2Fs1&sslmode=require"},
+		{&PostgresAdapter{}, "postgres://ro@db/app?application_name=bi", "postgres://ro@db/app?application_name=bi"},
+		{&PostgresAdapter{}, "host=db user=ro dbname=app", "host=db user=ro dbname=app application_name='mcp/v1/s1'"},
+		{&PostgresAdapter{}, "host=db application_name = bi", "host=db application_name = bi"},
+		{&SQLiteAdapter{}, "file:app.db?mode=ro", "file:app.db?mode=ro"},
+	}
+	for _, tt := range tests {
+		got, err := tt.adapter.WithConnectionLabel(tt.dsn, "mcp/v1/s1")
+		if err != nil || got != tt.want {
+			t.Errorf("Expected %s labeled as %s, got %s %v", tt.dsn, tt.want, got, err)
+		}
+	}
+
+	t.Setenv("PGAPPNAME", "bi")
+	if got, _ := (&PostgresAdapter{}).WithConnectionLabel("postgres://ro@db/app", "mcp/v1/s1"); got != "postgres://ro@db/app" {
+		t.Errorf("Expected PGAPPNAME to take precedence, got %s", got)
+	}
+}
@@ -0,0 +1,131 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// MaxQueryCost is the planner cost estimate above which a query only runs
+// once the user confirms it, asked through elicitation on clients that
+// support it and rejected on others. Units are the database's own (e.g.
+// PostgreSQL page fetches); 0 disables the check (overridable via
+// MCP_MAX_QUERY_COST env var)
+var MaxQueryCost float64
+
+// ForbiddenQueryCost is the estimate above which a query is rejected
+// without asking; 0 disables it (overridable via MCP_FORBIDDEN_QUERY_COST
+// env var)
+var ForbiddenQueryCost float64
+
+// ConfirmationTimeout bounds the wait for the user to confirm an expensive
+// query.
+const ConfirmationTimeout = 5 * time.Minute
+
+// ErrCostUnsupported is returned by DBAdapter.EstimateCost for databases
+// without a cost model; the cost guardrail lets their queries through.
+var ErrCostUnsupported = errors.New("query cost estimates are not supported")
+
+// selectPattern matches a SELECT, including one that saved results were
+// added to as a WITH clause.
+var selectPattern = regexp.MustCompile(`(?i)^\s*(?:SELECT|WITH)\b`)
+
+// checkCost applies the cost guardrail to an already validated query, planned
+// with args bound to its placeholders, returning the tool error to report
+// when it must not run. Only SELECTs are planned: other allowed statements do
+// not scan data.
+func (s *Server) checkCost(ctx context.Context, sqlQuery string, args ...any) *CallToolResult {
+	if MaxQueryCost <= 0 && ForbiddenQueryCost <= 0 {
+		return nil
+	}
+	if !selectPattern.MatchString(sqlQuery) {
+		return nil
+	}
+
+	cost, err := s.estimateCost(ctx, sqlQuery, args...)
+	if errors.Is(err, ErrCostUnsupported) {
+		return nil
+	}
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err))
+	}
+
+	var reason string
+	switch {
+	case ForbiddenQueryCost > 0 && cost > ForbiddenQueryCost:
+		reason = fmt.Sprintf("estimated cost %.0f exceeds the limit of %.0f; add filters or aggregate to make it cheaper", cost, ForbiddenQueryCost)
+	case MaxQueryCost > 0 && cost > MaxQueryCost:
+		if !s.clientSupportsElicitation() || s.out == nil {
+			reason = fmt.Sprintf("estimated cost %.0f exceeds %.0f and needs the user's confirmation, which this client cannot ask for", cost, MaxQueryCost)
+			break
+		}
+		confirmed, err := s.confirmQuery(ctx, sqlQuery, cost)
+		switch {
+		case err != nil:
+			reason = fmt.Sprintf("estimated cost %.0f exceeds %.0f and confirmation failed: %v", cost, MaxQueryCost, err)
+		case !confirmed:
+			reason = fmt.Sprintf("estimated cost %.0f exceeds %.0f and the user declined to run it", cost, MaxQueryCost)
+		default:
+			loggerFrom(ctx).Info("Expensive query confirmed by user", "cost", cost)
+		}
+	}
+	if reason == "" {
+		return nil
+	}
+
+	stats.queriesRejected.Add(1)
+	s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, reason, args...)
+	return withErrorKind(&CallToolResult{
+		Content: []Content{{Type: "text", Text: "Query rejected: " + reason}},
+		IsError: true,
+	}, ErrorKindTooExpensive)
+}
+
+// estimateCost plans sqlQuery with args on a worker, within QueryTimeout.
+func (s *Server) estimateCost(ctx context.Context, sqlQuery string, args ...any) (float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer s.workers.release()
+	return s.adapter.EstimateCost(ctx, s.db, sqlQuery, args...)
+}
+
+// confirmQuery asks the user through elicitation whether to run an
+// expensive query, reporting whether they accepted.
+func (s *Server) confirmQuery(ctx context.Context, sqlQuery string, cost float64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, ConfirmationTimeout)
+	defer cancel()
+
+	raw, err := s.requestClient(ctx, "elicitation/create", ElicitRequestParams{
+		Message: fmt.Sprintf("The database estimates this query as expensive (cost %.0f, confirmation required above %.0f). Run it anyway?\n\n%s",
+			cost, MaxQueryCost, sqlQuery),
+		RequestedSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"confirm": {
+					Type:        "boolean",
+					Description: "Run the query",
+				},
+			},
+			Required: []string{"confirm"},
+		},
+	})
+	if err != nil {
+		return false, err
+	}
+	var result ElicitResult
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return false, fmt.Errorf("invalid elicitation result: %w", err)
+	}
+	confirm, _ := result.Content["confirm"].(bool)
+	return result.Action == "accept" && confirm, nil
+}
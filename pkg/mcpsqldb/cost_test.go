@@ -0,0 +1,170 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fixedCostAdapter plans every query at a fixed cost.
+type fixedCostAdapter struct {
+	*SQLiteAdapter
+	cost float64
+}
+
+func (a *fixedCostAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	return a.cost, nil
+}
+
+// newCostServer creates a test server whose queries are estimated at cost.
+func newCostServer(t *testing.T, cost float64) *Server {
+	t.Helper()
+	server := newTestServer(t)
+	server.adapter = &fixedCostAdapter{SQLiteAdapter: &SQLiteAdapter{}, cost: cost}
+	return server
+}
+
+func TestParseCost(t *testing.T) {
+	mysql, err := parseMySQLCost([]byte(`{"query_block": {"select_id": 1, "cost_info": {"query_cost": "1234.50"}, "table": {}}}`))
+	if err != nil || mysql != 1234.5 {
+		t.Errorf("Expected MySQL cost 1234.5, got %v (%v)", mysql, err)
+	}
+	postgres, err := parsePostgresCost([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Startup Cost": 0.00, "Total Cost": 431.00}}]`))
+	if err != nil || postgres != 431 {
+		t.Errorf("Expected PostgreSQL cost 431, got %v (%v)", postgres, err)
+	}
+	if _, err := parsePostgresCost([]byte(`[]`)); err == nil {
+		t.Error("Expected an error for a plan without cost")
+	}
+}
+
+func TestCheckCost(t *testing.T) {
+	defer func(max, forbidden float64) { MaxQueryCost, ForbiddenQueryCost = max, forbidden }(MaxQueryCost, ForbiddenQueryCost)
+	MaxQueryCost, ForbiddenQueryCost = 100, 1000
+
+	tests := []struct {
+		name string
+		cost float64
+		sql  string
+		want string
+	}{
+		{"cheap", 50, "SELECT * FROM users", ""},
+		{"expensive without elicitation", 500, "SELECT * FROM users", ErrorKindTooExpensive},
+		{"forbidden", 5000, "SELECT * FROM users", ErrorKindTooExpensive},
+		{"not a select", 5000, "EXPLAIN QUERY PLAN SELECT * FROM users", ""},
+	}
+	for _, tc := range tests {
+		if tc.want == "" {
+			result, _ := newCostServer(t, tc.cost).executeQuery(context.Background(), map[string]any{"sql": tc.sql})
+			if result == nil || result.IsError {
+				t.Errorf("%s: expected the query to run, got %+v", tc.name, result)
+			}
+			continue
+		}
+		if kind, _ := toolErrorKind(t, newCostServer(t, tc.cost), "query", map[string]any{"sql": tc.sql}); kind != tc.want {
+			t.Errorf("%s: expected kind %q, got %q", tc.name, tc.want, kind)
+		}
+	}
+
+	// SQLite has no cost model, so its queries are never held back
+	MaxQueryCost = 0.001
+	if result, _ := newTestServer(t).executeQuery(context.Background(), map[string]any{"sql": "SELECT * FROM users"}); result == nil || result.IsError {
+		t.Errorf("Expected the query to run without a cost estimate, got %+v", result)
+	}
+}
+
+func TestCheckCost_SavedResults(t *testing.T) {
+	defer func(orig float64) { ForbiddenQueryCost = orig }(ForbiddenQueryCost)
+	server := newCostServer(t, 5000)
+	ctx := context.Background()
+	if result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT id FROM users", "save_as": "x"}); result.IsError {
+		t.Fatalf("Expected the result to be saved, got %+v", result)
+	}
+
+	// The query is planned as it runs, with the saved result added as a
+	// WITH clause
+	ForbiddenQueryCost = 1000
+	if kind, _ := toolErrorKind(t, server, "query", map[string]any{"sql": "SELECT COUNT(*) FROM users JOIN x ON x.id = users.id"}); kind != ErrorKindTooExpensive {
+		t.Errorf("Expected a query using a saved result to be checked, got kind %q", kind)
+	}
+}
+
+func TestCountQuery_SavedResults(t *testing.T) {
+	if got := countQuery("WITH x AS (SELECT 1 AS id) SELECT id FROM x"); !strings.HasPrefix(got, "SELECT COUNT(*) FROM (") {
+		t.Errorf("Expected a query using a saved result to be counted, got %q", got)
+	}
+	if got := countQuery("SHOW TABLES"); got != "" {
+		t.Errorf("Expected SHOW not to be counted, got %q", got)
+	}
+}
+
+// elicitQuery initializes an elicitation-capable session, calls query and
+// returns the elicitation request the server sends.
+func elicitQuery(t *testing.T, server *Server) (io.Writer, *bufio.Scanner, ElicitRequestParams, string) {
+	t.Helper()
+	in, out, _ := serveAsync(t, server)
+	io.WriteString(in, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{"elicitation":{}},"clientInfo":{"name":"test"}}}`+"\n")
+	if !out.Scan() {
+		t.Fatal("Expected an initialize response")
+	}
+	io.WriteString(in, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT name FROM users"}}}`+"\n")
+	if !out.Scan() {
+		t.Fatal("Expected an elicitation request")
+	}
+	var request struct {
+		ID     string              `json:"id"`
+		Method string              `json:"method"`
+		Params ElicitRequestParams `json:"params"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &request); err != nil || request.Method != "elicitation/create" {
+		t.Fatalf("Expected elicitation/create, got %s", out.Text())
+	}
+	return in, out, request.Params, request.ID
+}
+
+func TestCheckCost_ElicitsConfirmation(t *testing.T) {
+	defer func(orig float64) { MaxQueryCost = orig }(MaxQueryCost)
+	MaxQueryCost = 100
+
+	tests := []struct {
+		name   string
+		answer string
+		want   bool
+	}{
+		{"accepted", `{"action":"accept","content":{"confirm":true}}`, true},
+		{"unchecked", `{"action":"accept","content":{"confirm":false}}`, false},
+		{"declined", `{"action":"decline"}`, false},
+	}
+	for _, tc := range tests {
+		in, out, params, id := elicitQuery(t, newCostServer(t, 500))
+		if !strings.Contains(params.Message, "SELECT name FROM users") || params.RequestedSchema.Properties["confirm"].Type != "boolean" {
+			t.Errorf("%s: expected the query and a confirm field, got %+v", tc.name, params)
+		}
+
+		// Requests arriving while the user decides are still answered
+		io.WriteString(in, `{"jsonrpc":"2.0","id":3,"method":"ping"}`+"\n")
+		if !out.Scan() || !strings.Contains(out.Text(), `"id":3`) {
+			t.Fatalf("%s: expected the ping response, got %s", tc.name, out.Text())
+		}
+
+		io.WriteString(in, `{"jsonrpc":"2.0","id":"`+id+`","result":`+tc.answer+`}`+"\n")
+		if !out.Scan() {
+			t.Fatalf("%s: expected the query response", tc.name)
+		}
+		var response struct {
+			ID     float64        `json:"id"`
+			Result CallToolResult `json:"result"`
+		}
+		json.Unmarshal(out.Bytes(), &response)
+		if response.ID != 2 || response.Result.IsError == tc.want {
+			t.Errorf("%s: expected success %v, got %s", tc.name, tc.want, out.Text())
+		}
+		if !tc.want && (response.Result.Meta == nil || response.Result.Meta.Error.Kind != ErrorKindTooExpensive) {
+			t.Errorf("%s: expected too_expensive, got %s", tc.name, out.Text())
+		}
+	}
+}
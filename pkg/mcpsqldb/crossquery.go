@@ -0,0 +1,380 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// CrossQuerySources configures the additional databases the cross_query
+// tool reads, keyed by source name. The tool is offered only when at least
+// one is configured (overridable via MCP_CROSS_SOURCES env var, listing the
+// names, with MCP_SOURCE_<NAME>_DRIVER and MCP_SOURCE_<NAME>_DSN for each)
+var CrossQuerySources map[string]Config
+
+// CrossQueryMaxRows caps the rows each cross_query input may load
+// (overridable via MCP_CROSS_MAX_ROWS env var)
+var CrossQueryMaxRows = 10000
+
+// crossPrimarySource names the server's own database in cross_query inputs.
+const crossPrimarySource = "primary"
+
+// crossNamePattern matches valid source and input table names.
+var crossNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// crossSource is a read-only connection to a cross_query source.
+type crossSource struct {
+	adapter DBAdapter
+	db      *sql.DB
+}
+
+// crossSources maps source names to their connections.
+type crossSources map[string]*crossSource
+
+// openCrossSources connects to every configured source the way New connects
+// to the primary database, privilege audit included.
+func openCrossSources(ctx context.Context, configs map[string]Config, label string) (crossSources, error) {
+	sources := crossSources{}
+	for name, cfg := range configs {
+		if !crossNamePattern.MatchString(name) || strings.EqualFold(name, crossPrimarySource) {
+			sources.Close()
+			return nil, fmt.Errorf("invalid cross_query source name %q", name)
+		}
+		adapter, err := newAdapter(cfg.Driver)
+		if err == nil && cfg.DSN == "" {
+			err = fmt.Errorf("no DSN configured")
+		}
+		dsn := cfg.DSN
+		if err == nil {
+			dsn, err = adapter.ResolveDSN(dsn)
+		}
+		var db *sql.DB
+		if err == nil {
+			db, _, err = openDB(ctx, adapter, dsn, label)
+		}
+		if err != nil {
+			sources.Close()
+			return nil, fmt.Errorf("cross_query source %q: %w", name, err)
+		}
+		sources[name] = &crossSource{adapter: adapter, db: db}
+	}
+	return sources, nil
+}
+
+// Close closes every source connection.
+func (sources crossSources) Close() {
+	for _, source := range sources {
+		source.db.Close()
+	}
+}
+
+// crossInput is a query whose rows cross_query loads into a table.
+type crossInput struct {
+	Table  string
+	Source string
+	SQL    string
+}
+
+// crossQueryTool describes cross_query, listed only when sources are
+// configured.
+func (s *Server) crossQueryTool() Tool {
+	names := []string{crossPrimarySource}
+	for name := range s.sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return Tool{
+		Name: "cross_query",
+		Description: "Join data across databases: run read-only queries on the configured sources (" + strings.Join(names, ", ") +
+			"), load each result into a table of a temporary in-memory SQLite database, and run a final SQLite SELECT over those tables",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"inputs": {
+					Type:        "array",
+					Description: "The queries to load, each into its own table",
+					Items: &Property{
+						Type: "object",
+						Properties: map[string]Property{
+							"table": {
+								Type:        "string",
+								Description: "Name of the table to load the rows into",
+							},
+							"source": {
+								Type:        "string",
+								Description: "Source to run the query on",
+							},
+							"sql": {
+								Type:        "string",
+								Description: "Read-only query in the source's SQL dialect; keep its result small",
+							},
+						},
+						Required: []string{"table", "source", "sql"},
+					},
+				},
+				"sql": {
+					Type:        "string",
+					Description: "SQLite SELECT over the loaded tables",
+				},
+			},
+			Required: []string{"inputs", "sql"},
+		},
+	}
+}
+
+// parseCrossInputs reads and checks the cross_query inputs argument.
+func (s *Server) parseCrossInputs(args map[string]any) ([]crossInput, error) {
+	raw, ok := args["inputs"].([]any)
+	if !ok || len(raw) == 0 {
+		return nil, fmt.Errorf("Missing or invalid 'inputs' parameter")
+	}
+	seen := map[string]bool{}
+	inputs := make([]crossInput, 0, len(raw))
+	for i, item := range raw {
+		fields, _ := item.(map[string]any)
+		table, _ := fields["table"].(string)
+		source, _ := fields["source"].(string)
+		query, _ := fields["sql"].(string)
+		switch {
+		case !crossNamePattern.MatchString(table):
+			return nil, fmt.Errorf("inputs[%d]: 'table' must be a plain identifier", i)
+		case seen[strings.ToLower(table)]:
+			return nil, fmt.Errorf("inputs[%d]: duplicate table %q", i, table)
+		case source != crossPrimarySource && s.sources[source] == nil:
+			return nil, fmt.Errorf("inputs[%d]: unknown source %q", i, source)
+		case query == "":
+			return nil, fmt.Errorf("inputs[%d]: missing 'sql'", i)
+		}
+		seen[strings.ToLower(table)] = true
+		inputs = append(inputs, crossInput{Table: table, Source: source, SQL: query})
+	}
+	return inputs, nil
+}
+
+// crossQuery loads each input's rows into an in-memory SQLite database and
+// runs the final query there. Sources are only ever read; every input is
+// validated like a query tool call against its own source.
+func (s *Server) crossQuery(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	if len(s.sources) == 0 {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "cross_query requires sources configured with MCP_CROSS_SOURCES"}},
+			IsError: true,
+		}, nil
+	}
+	finalSQL, ok := args["sql"].(string)
+	if !ok || finalSQL == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'sql' parameter",
+		}
+	}
+	inputs, err := s.parseCrossInputs(args)
+	if err != nil {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: err.Error(),
+		}
+	}
+
+	for i, in := range inputs {
+		validated, err := validateQueryFor(s.crossAdapter(in.Source), in.SQL)
+		if err != nil {
+			stats.queriesRejected.Add(1)
+			s.audit.query(ctx, s.crossAdapter(in.Source), s.sessionID, in.SQL, AuditOutcomeRejected, 0, 0, err.Error())
+			return withErrorKind(&CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected (input %q): %v", in.Table, err)}},
+				IsError: true,
+			}, ErrorKindValidationRejected), nil
+		}
+		inputs[i].SQL = validated
+	}
+	validated, err := validateQueryFor(&SQLiteAdapter{}, finalSQL)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	finalSQL = validated
+
+	if err := s.quota.allow(time.Now()); err != nil {
+		stats.queriesRejected.Add(1)
+		loggerFrom(ctx).Warn("Query quota exceeded", "error", err)
+		return quotaExceeded(err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	// Each connection to ":memory:" is a separate database, so keep one
+	mem, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		return nil, &Error{
+			Code:    InternalError,
+			Message: err.Error(),
+		}
+	}
+	defer mem.Close()
+	mem.SetMaxOpenConns(1)
+
+	for _, in := range inputs {
+		if err := s.loadCrossInput(ctx, mem, in); err != nil {
+			return withErrorKind(&CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error (input %q): %v", in.Table, err)}},
+				IsError: true,
+			}, errorKind(ctx, err)), nil
+		}
+	}
+
+	if _, err := mem.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return nil, &Error{
+			Code:    InternalError,
+			Message: err.Error(),
+		}
+	}
+	rows, err := mem.QueryContext(ctx, finalSQL)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	defer rows.Close()
+
+	result, rowCount := s.formatRows(ctx, rows, finalSQL)
+	stats.recordResult(result)
+	if !result.IsError {
+		s.quota.record(rowCount, len(result.Content[0].Text))
+	}
+	return result, nil
+}
+
+// crossAdapter returns the adapter of the named source.
+func (s *Server) crossAdapter(source string) DBAdapter {
+	if source == crossPrimarySource {
+		return s.adapter
+	}
+	return s.sources[source].adapter
+}
+
+// loadCrossInput runs in.SQL on its source and copies the rows, masked and
+// transcoded as the query tool would return them, into a new table of mem.
+func (s *Server) loadCrossInput(ctx context.Context, mem *sql.DB, in crossInput) (err error) {
+	start := time.Now()
+	rowCount := 0
+	defer func() {
+		outcome, detail := AuditOutcomeOK, ""
+		if err != nil {
+			outcome, detail = AuditOutcomeError, err.Error()
+		}
+		s.audit.query(ctx, s.crossAdapter(in.Source), s.sessionID, in.SQL, outcome, rowCount, time.Since(start), detail)
+	}()
+
+	adapter := s.crossAdapter(in.Source)
+	var rows *sql.Rows
+	if in.Source == crossPrimarySource {
+		var done func()
+		if rows, done, err = s.beginQuery(ctx, in.SQL); err != nil {
+			return err
+		}
+		defer done()
+	} else if rows, err = s.sources[in.Source].db.QueryContext(ctx, in.SQL); err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	tx, err := mem.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, rowCount, err = s.copyRows(ctx, tx, in.Table, rows, adapter, CrossQueryMaxRows, "filter or aggregate the input at its source"); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// copyRows creates table in tx and inserts rows into it, decoded by the
+// adapter they came from and masked as the query tool would return them. It
+// returns the columns and the number of rows copied, failing with advice on
+// more than maxRows.
+func (s *Server) copyRows(ctx context.Context, tx *sql.Tx, table string, rows *sql.Rows, adapter DBAdapter, maxRows int, advice string) ([]string, int, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, 0, err
+	}
+	types := columnTypeNames(rows)
+	sqlite := &SQLiteAdapter{}
+	quoted := make([]string, len(columns))
+	for i, col := range columns {
+		quoted[i] = sqlite.QuoteIdentifier(col)
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s)",
+		sqlite.QuoteIdentifier(table), strings.Join(quoted, ", "))); err != nil {
+		return nil, 0, err
+	}
+
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s VALUES (%s)",
+		sqlite.QuoteIdentifier(table), strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")))
+	if err != nil {
+		return nil, 0, err
+	}
+	defer insert.Close()
+
+	masks := columnMasks(MaskRules, "", columns)
+	values := make([]any, len(columns))
+	valuePtrs := make([]any, len(columns))
+	for i := range values {
+		valuePtrs[i] = &values[i]
+	}
+	rowCount := 0
+	for rows.Next() {
+		if rowCount >= maxRows {
+			return nil, rowCount, fmt.Errorf("more than %d rows; %s", maxRows, advice)
+		}
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, rowCount, err
+		}
+		for i := range values {
+			values[i] = sqliteValue(adapter.DecodeValue(types[i], textValue(s.charset, values[i])))
+			if masks != nil {
+				values[i] = maskValue(masks[i], values[i])
+			}
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return nil, rowCount, err
+		}
+		rowCount++
+	}
+	if err := rows.Err(); err != nil {
+		return nil, rowCount, err
+	}
+	return columns, rowCount, nil
+}
+
+// sqliteValue stores decoded exact numbers as SQLite numbers rather than
+// text, so they compare and sort numerically in the final query.
+func sqliteValue(val any) any {
+	n, ok := val.(json.Number)
+	if !ok {
+		return val
+	}
+	if i, err := n.Int64(); err == nil {
+		return i
+	}
+	if f, err := n.Float64(); err == nil {
+		return f
+	}
+	return string(n)
+}
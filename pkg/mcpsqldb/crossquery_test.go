@@ -0,0 +1,120 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newCrossTestServer serves the users test database with a "crm" source
+// holding orders of those users.
+func newCrossTestServer(t *testing.T) *Server {
+	t.Helper()
+	defer func(orig map[string]Config) { CrossQuerySources = orig }(CrossQuerySources)
+	crm := newTestDB(t,
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER, total INTEGER)",
+		"INSERT INTO orders (user_id, total) VALUES (1, 10), (1, 5), (3, 7)",
+	)
+	CrossQuerySources = map[string]Config{"crm": {Driver: "sqlite", DSN: crm + "?mode=ro"}}
+	return newTestServer(t)
+}
+
+func crossQueryArgs(final string, inputs ...[3]string) map[string]any {
+	var list []any
+	for _, in := range inputs {
+		list = append(list, map[string]any{"table": in[0], "source": in[1], "sql": in[2]})
+	}
+	return map[string]any{"inputs": list, "sql": final}
+}
+
+func TestCrossQuery_JoinsSources(t *testing.T) {
+	server := newCrossTestServer(t)
+
+	tools, _ := server.handleListTools()
+	listed := false
+	for _, tool := range tools.Tools {
+		listed = listed || tool.Name == "cross_query"
+	}
+	if !listed {
+		t.Error("Expected cross_query to be listed with sources configured")
+	}
+
+	result, rpcErr := server.callTool(context.Background(), "cross_query", crossQueryArgs(
+		"SELECT u.name, SUM(o.total) AS total FROM u JOIN o ON o.user_id = u.id GROUP BY u.name ORDER BY u.name",
+		[3]string{"u", "primary", "SELECT id, name FROM users"},
+		[3]string{"o", "crm", "SELECT user_id, total FROM orders"},
+	))
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected joined rows, got %v %+v", rpcErr, result)
+	}
+	var rows []map[string]any
+	json.Unmarshal([]byte(result.Content[0].Text), &rows)
+	want := []map[string]any{{"name": "alice", "total": float64(15)}, {"name": "carol", "total": float64(7)}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Expected %v, got %v", want, rows)
+	}
+}
+
+func TestCrossQuery_Errors(t *testing.T) {
+	defer func(orig int) { CrossQueryMaxRows = orig }(CrossQueryMaxRows)
+	CrossQueryMaxRows = 2
+	server := newCrossTestServer(t)
+	users := [3]string{"u", "primary", "SELECT id, name FROM users WHERE id = 1"}
+
+	for _, args := range []map[string]any{
+		{"sql": "SELECT 1"},
+		crossQueryArgs("", users),
+		crossQueryArgs("SELECT 1", [3]string{"u", "billing", "SELECT 1"}),
+		crossQueryArgs("SELECT 1", users, [3]string{"U", "crm", "SELECT 1"}),
+		crossQueryArgs("SELECT 1", [3]string{"u v", "crm", "SELECT 1"}),
+	} {
+		if _, rpcErr := server.callTool(context.Background(), "cross_query", args); rpcErr == nil {
+			t.Errorf("Expected invalid params error for %v", args)
+		}
+	}
+
+	tests := []struct {
+		args map[string]any
+		want string
+	}{
+		{crossQueryArgs("SELECT * FROM u", [3]string{"u", "crm", "DELETE FROM orders"}), `Query rejected (input "u")`},
+		{crossQueryArgs("DELETE FROM u", users), "Query rejected"},
+		{crossQueryArgs("SELECT * FROM u", [3]string{"u", "crm", "SELECT * FROM orders"}), "more than 2 rows"},
+		{crossQueryArgs("SELECT * FROM missing", users), "Query error"},
+	}
+	for _, tc := range tests {
+		result, _ := server.callTool(context.Background(), "cross_query", tc.args)
+		if result == nil || !result.IsError || !strings.Contains(result.Content[0].Text, tc.want) {
+			t.Errorf("Expected tool error containing %q, got %+v", tc.want, result)
+		}
+	}
+}
+
+func TestCrossQuery_NotOfferedWithoutSources(t *testing.T) {
+	server := newTestServer(t)
+
+	tools, _ := server.handleListTools()
+	for _, tool := range tools.Tools {
+		if tool.Name == "cross_query" {
+			t.Error("Expected cross_query not to be listed without sources")
+		}
+	}
+	result, _ := server.callTool(context.Background(), "cross_query", crossQueryArgs("SELECT 1"))
+	if !result.IsError {
+		t.Errorf("Expected an error without sources, got %s", result.Content[0].Text)
+	}
+}
+
+func TestOpenCrossSources_Errors(t *testing.T) {
+	for name, cfg := range map[string]Config{
+		"primary": {Driver: "sqlite", DSN: newTestDB(t)},
+		"crm":     {Driver: "sqlite"},
+		"billing": {Driver: "oracle", DSN: "x"},
+	} {
+		if _, err := openCrossSources(context.Background(), map[string]Config{name: cfg}, ""); err == nil {
+			t.Errorf("Expected source %q %+v to be refused", name, cfg)
+		}
+	}
+}
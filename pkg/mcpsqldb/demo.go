@@ -0,0 +1,116 @@
+package mcpsqldb
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// DemoFlag serves a temporary sample database instead of the configured one
+const DemoFlag = "--demo"
+
+// demoStatements create the sample database served by --demo.
+var demoStatements = []string{
+	`CREATE TABLE customers (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		email TEXT NOT NULL UNIQUE,
+		country TEXT NOT NULL,
+		created_at TEXT NOT NULL
+	)`,
+	`CREATE TABLE products (
+		id INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		category TEXT NOT NULL,
+		price_cents INTEGER NOT NULL
+	)`,
+	`CREATE TABLE orders (
+		id INTEGER PRIMARY KEY,
+		customer_id INTEGER NOT NULL REFERENCES customers(id),
+		product_id INTEGER NOT NULL REFERENCES products(id),
+		quantity INTEGER NOT NULL,
+		status TEXT NOT NULL,
+		ordered_at TEXT NOT NULL
+	)`,
+	`INSERT INTO customers (name, email, country, created_at) VALUES
+		('Ada Lovelace', 'ada@example.com', 'GB', '2024-01-15'),
+		('Grace Hopper', 'grace@example.com', 'US', '2024-02-03'),
+		('Alan Turing', 'alan@example.com', 'GB', '2024-03-22'),
+		('Katherine Johnson', 'katherine@example.com', 'US', '2024-04-09'),
+		('Edsger Dijkstra', 'edsger@example.com', 'NL', '2024-05-30')`,
+	`INSERT INTO products (name, category, price_cents) VALUES
+		('Mechanical Keyboard', 'hardware', 12900),
+		('USB-C Hub', 'hardware', 4500),
+		('Standing Desk', 'furniture', 54900),
+		('Monitor Arm', 'furniture', 8900),
+		('Noise-Cancelling Headphones', 'audio', 29900),
+		('Webcam', 'hardware', 7900)`,
+	`INSERT INTO orders (customer_id, product_id, quantity, status, ordered_at) VALUES
+		(1, 1, 1, 'delivered', '2024-06-01'),
+		(1, 2, 2, 'delivered', '2024-06-01'),
+		(2, 3, 1, 'shipped', '2024-06-14'),
+		(3, 5, 1, 'delivered', '2024-06-20'),
+		(3, 6, 1, 'cancelled', '2024-06-21'),
+		(4, 4, 2, 'pending', '2024-07-02'),
+		(5, 1, 1, 'shipped', '2024-07-05'),
+		(2, 5, 1, 'pending', '2024-07-09')`,
+}
+
+// parseDemoFlag removes DemoFlag from args, reporting whether it was present.
+func parseDemoFlag(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	demo := false
+	for _, arg := range args {
+		if arg == DemoFlag {
+			demo = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, demo
+}
+
+// createDemoDatabase writes the sample database into dir and returns the
+// read-only DSN to serve it with.
+func createDemoDatabase(dir string) (string, error) {
+	path := filepath.Join(dir, "demo.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create demo database: %w", err)
+	}
+	defer db.Close()
+
+	for _, stmt := range demoStatements {
+		if _, err := db.Exec(stmt); err != nil {
+			return "", fmt.Errorf("failed to seed demo database: %w", err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		return "", fmt.Errorf("failed to write demo database: %w", err)
+	}
+	// A read-only file also satisfies the privilege audit
+	if err := os.Chmod(path, 0o444); err != nil {
+		return "", fmt.Errorf("failed to protect demo database: %w", err)
+	}
+	return "file:" + path + "?mode=ro", nil
+}
+
+// demoDatabase creates the sample database in a temporary directory. The
+// returned func removes it.
+func demoDatabase() (DBAdapter, string, func()) {
+	dir, err := os.MkdirTemp("", "readonly-mcp-demo-")
+	if err != nil {
+		slog.Error("Failed to create demo directory", "error", err)
+		os.Exit(1)
+	}
+	dsn, err := createDemoDatabase(dir)
+	if err != nil {
+		os.RemoveAll(dir)
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Serving demo database", "path", filepath.Join(dir, "demo.db"))
+	return &SQLiteAdapter{}, dsn, func() { os.RemoveAll(dir) }
+}
@@ -0,0 +1,60 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseDemoFlag(t *testing.T) {
+	args, demo := parseDemoFlag([]string{"--demo"})
+	if !demo || len(args) != 0 {
+		t.Errorf("Expected demo with no args, got %v, %v", demo, args)
+	}
+
+	args, demo = parseDemoFlag([]string{"/data/app.db"})
+	if demo || !reflect.DeepEqual(args, []string{"/data/app.db"}) {
+		t.Errorf("Expected args unchanged without the flag, got %v, %v", demo, args)
+	}
+}
+
+func TestDemoDatabase_ServesSampleSchema(t *testing.T) {
+	dsn, err := createDemoDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("createDemoDatabase failed: %v", err)
+	}
+	server, err := newServer(context.Background(), &SQLiteAdapter{}, dsn)
+	if err != nil {
+		t.Fatalf("Failed to serve demo database: %v", err)
+	}
+	defer server.Close()
+
+	resources, rpcErr := server.handleListResources(context.Background())
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if len(resources.Resources) != 3 {
+		t.Errorf("Expected 3 table resources, got %d", len(resources.Resources))
+	}
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": `
+		SELECT c.name, SUM(o.quantity * p.price_cents) AS spent_cents
+		FROM orders o JOIN customers c ON c.id = o.customer_id JOIN products p ON p.id = o.product_id
+		WHERE o.status != 'cancelled' GROUP BY c.name ORDER BY spent_cents DESC LIMIT 1`})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected demo query to succeed, got %v %+v", rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(rows) != 1 || rows[0]["name"] != "Grace Hopper" {
+		t.Errorf("Expected Grace Hopper as top customer, got %v", rows)
+	}
+
+	rejected, _ := server.executeQuery(context.Background(), map[string]any{"sql": "DELETE FROM orders"})
+	if !rejected.IsError {
+		t.Error("Expected writes to the demo database to be rejected")
+	}
+}
@@ -0,0 +1,133 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DeniedTables are lower-cased glob patterns parsed from MCP_DENY_TABLES.
+// Matching tables are hidden from resource listings and schema reads, and
+// queries that reference them are rejected.
+var DeniedTables []string
+
+// DeniedSchemas are lower-cased glob patterns for server-internal schemas
+// (overridable via MCP_DENY_SCHEMAS env var; set it empty to allow all).
+// Queries that reference them are rejected and their tables are never
+// exposed as resources.
+var DeniedSchemas = []string{"mysql", "sys", "performance_schema", "pg_catalog"}
+
+// identifierPattern matches quoted ("x", `x`, [x]) and bare identifiers in SQL
+// that has already had strings and comments removed.
+var identifierPattern = regexp.MustCompile("\"([^\"]+)\"|`([^`]+)`|\\[([^\\]]+)\\]|([A-Za-z_][A-Za-z0-9_$]*)")
+
+// qualifierPattern matches an identifier used as a qualifier ("schema." in
+// schema.table), capturing it as identifierPattern does.
+var qualifierPattern = regexp.MustCompile(identifierPattern.String() + `\s*\.`)
+
+// showSourcePattern matches the schema named in SHOW ... FROM/IN <schema>.
+var showSourcePattern = regexp.MustCompile(`(?i)\b(?:FROM|IN)\s+(?:` + identifierPattern.String() + `)`)
+
+// parseTablePatterns parses a comma-separated list of table name globs
+// (e.g. "payroll,secret_*").
+func parseTablePatterns(spec string) ([]string, error) {
+	var patterns []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if _, err := path.Match(entry, ""); err != nil {
+			return nil, fmt.Errorf("invalid table pattern %q: %w", entry, err)
+		}
+		patterns = append(patterns, strings.ToLower(entry))
+	}
+	return patterns, nil
+}
+
+// isTableDenied reports whether table matches any of the patterns.
+func isTableDenied(patterns []string, table string) bool {
+	table = strings.ToLower(table)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, table); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// deniedTableReference returns the first identifier in cleanedSQL that
+// matches a denied pattern, or "" if there is none. Every identifier is
+// checked, so a column sharing a denied table's name is also rejected;
+// over-blocking is preferred to parsing each dialect's FROM clauses.
+func deniedTableReference(patterns []string, cleanedSQL string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	for _, m := range identifierPattern.FindAllStringSubmatch(cleanedSQL, -1) {
+		for _, ident := range m[1:] {
+			if ident != "" && isTableDenied(patterns, ident) {
+				return ident
+			}
+		}
+	}
+	return ""
+}
+
+// isSchemaDenied reports whether schema matches any of the patterns.
+func isSchemaDenied(patterns []string, schema string) bool {
+	return isTableDenied(patterns, schema)
+}
+
+// deniedSchemaReference returns the first denied schema referenced in
+// cleanedSQL, as a qualifier or as the source of a SHOW statement, or "".
+func deniedSchemaReference(patterns []string, cleanedSQL string) string {
+	if len(patterns) == 0 {
+		return ""
+	}
+	matches := qualifierPattern.FindAllStringSubmatch(cleanedSQL, -1)
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(cleanedSQL)), "SHOW") {
+		matches = append(matches, showSourcePattern.FindAllStringSubmatch(cleanedSQL, -1)...)
+	}
+	for _, m := range matches {
+		for _, ident := range m[1:] {
+			if ident != "" && isSchemaDenied(patterns, ident) {
+				return ident
+			}
+		}
+	}
+	return ""
+}
+
+// validateQuery applies the adapter's read-only validation followed by the
+// table and schema denylists, returning the query to run: sqlQuery with
+// row limiting clauses of other dialects rewritten for the adapter and the
+// saved results it references defined.
+func (s *Server) validateQuery(sqlQuery string) (string, error) {
+	validated, err := validateQueryFor(s.adapter, sqlQuery)
+	if err != nil {
+		return "", err
+	}
+	return s.saved.expand(s.adapter, validated), nil
+}
+
+// validateQueryFor validates sqlQuery as validateQuery does, for a query run
+// through adapter.
+func validateQueryFor(adapter DBAdapter, sqlQuery string) (string, error) {
+	sqlQuery = adapter.NormalizeRowLimit(sqlQuery)
+	if err := adapter.ValidateQuery(sqlQuery); err != nil {
+		return "", err
+	}
+	cleaned := adapter.RemoveStringsAndComments(sqlQuery)
+	if deniedTableReference(DeniedTables, cleaned) != "" {
+		return "", fmt.Errorf("query references a restricted table")
+	}
+	if deniedSchemaReference(DeniedSchemas, cleaned) != "" {
+		return "", fmt.Errorf("query references a restricted schema")
+	}
+	if schema := adapter.ImplicitSchemaReference(cleaned); schema != "" && isSchemaDenied(DeniedSchemas, schema) {
+		return "", fmt.Errorf("query references a restricted schema")
+	}
+	return sqlQuery, nil
+}
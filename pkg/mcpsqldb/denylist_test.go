@@ -0,0 +1,145 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseTablePatterns(t *testing.T) {
+	patterns, err := parseTablePatterns(" Payroll, secret_*,,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(patterns) != 2 || patterns[0] != "payroll" || patterns[1] != "secret_*" {
+		t.Errorf("Expected [payroll secret_*], got %v", patterns)
+	}
+
+	if _, err := parseTablePatterns("users,[bad"); err == nil {
+		t.Error("Expected error for malformed pattern")
+	}
+}
+
+func TestDeniedTableReference(t *testing.T) {
+	patterns := []string{"payroll", "secret_*"}
+
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT * FROM users", ""},
+		{"SELECT * FROM payroll", "payroll"},
+		{"SELECT * FROM PAYROLL", "PAYROLL"},
+		{"SELECT * FROM users JOIN secret_keys k ON k.id = users.id", "secret_keys"},
+		{`SELECT * FROM "Payroll"`, "Payroll"},
+		{"SELECT * FROM `secret_tokens`", "secret_tokens"},
+		{"SELECT * FROM [payroll]", "payroll"},
+		{"SELECT * FROM payroll_summary", ""},
+	}
+	for _, tc := range tests {
+		if got := deniedTableReference(patterns, tc.sql); got != tc.expected {
+			t.Errorf("deniedTableReference(%q) = %q, expected %q", tc.sql, got, tc.expected)
+		}
+	}
+}
+
+func TestDeniedTables_HiddenAndRejected(t *testing.T) {
+	server := newTestServer(t,
+		"CREATE TABLE payroll (id INTEGER PRIMARY KEY, salary INTEGER)",
+	)
+
+	original := DeniedTables
+	DeniedTables = []string{"payroll"}
+	defer func() { DeniedTables = original }()
+
+	ctx := context.Background()
+
+	list, rpcErr := server.handleListResources(ctx)
+	if rpcErr != nil {
+		t.Fatalf("Unexpected error: %v", rpcErr.Message)
+	}
+	if len(list.Resources) != 1 || !strings.Contains(list.Resources[0].URI, "/users/") {
+		t.Errorf("Expected only the users table to be listed, got %+v", list.Resources)
+	}
+
+	uri := server.adapter.URIScheme() + "://" + server.databaseName + "/payroll/schema"
+	params, _ := json.Marshal(ReadResourceParams{URI: uri})
+	if _, rpcErr := server.handleReadResource(ctx, params); rpcErr == nil {
+		t.Error("Expected schema read of denied table to fail")
+	}
+
+	// A string literal naming the table is not a reference to it
+	result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT 'payroll' AS label"})
+	if result.IsError {
+		t.Errorf("Expected literal mention to be allowed, got %q", result.Content[0].Text)
+	}
+
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT salary FROM payroll"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "restricted table") {
+		t.Errorf("Expected query on denied table to be rejected, got %q", result.Content[0].Text)
+	}
+}
+
+func TestDeniedSchemaReference(t *testing.T) {
+	tests := []struct {
+		sql      string
+		expected string
+	}{
+		{"SELECT * FROM users", ""},
+		{"SELECT * FROM mysql.user", "mysql"},
+		{"SELECT * FROM `performance_schema` . `threads`", "performance_schema"},
+		{"SELECT u.name FROM users u", ""},
+		{"SHOW TABLES FROM sys", "sys"},
+		{"SHOW COLUMNS FROM users IN mysql", "mysql"},
+		{"SELECT * FROM information_schema.tables", ""},
+		{"SELECT * FROM pg_catalog.pg_authid", "pg_catalog"},
+	}
+	for _, tc := range tests {
+		if got := deniedSchemaReference(DeniedSchemas, tc.sql); got != tc.expected {
+			t.Errorf("deniedSchemaReference(%q) = %q, expected %q", tc.sql, got, tc.expected)
+		}
+	}
+}
+
+func TestPostgresImplicitSchemaReference(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	tests := map[string]string{
+		"SELECT * FROM pg_shadow":                                     "pg_catalog",
+		`SELECT * FROM "pg_authid"`:                                   "pg_catalog",
+		"SELECT pg_size_pretty(pg_database_size(current_database()))": "",
+		"SELECT * FROM users":                                         "",
+	}
+	for sql, expected := range tests {
+		if got := adapter.ImplicitSchemaReference(sql); got != expected {
+			t.Errorf("ImplicitSchemaReference(%q) = %q, expected %q", sql, got, expected)
+		}
+	}
+	if got := (&MySQLAdapter{}).ImplicitSchemaReference("SELECT * FROM pg_shadow"); got != "" {
+		t.Errorf("Expected no implicit schema for MySQL, got %q", got)
+	}
+}
+
+func TestDeniedSchemas_HiddenAndRejected(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	params, _ := json.Marshal(ReadResourceParams{URI: server.adapter.URIScheme() + "://mysql/user/schema"})
+	if _, rpcErr := server.handleReadResource(ctx, params); rpcErr == nil {
+		t.Error("Expected schema read in a denied schema to fail")
+	}
+
+	result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM main.users"})
+	if result.IsError {
+		t.Fatalf("Expected query on allowed schema to succeed, got %q", result.Content[0].Text)
+	}
+
+	original := DeniedSchemas
+	DeniedSchemas = []string{"main"}
+	defer func() { DeniedSchemas = original }()
+
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM main.users"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "restricted schema") {
+		t.Errorf("Expected query on denied schema to be rejected, got %q", result.Content[0].Text)
+	}
+}
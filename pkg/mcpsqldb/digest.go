@@ -0,0 +1,29 @@
+package mcpsqldb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+// placeholderListPattern matches a parenthesized list of placeholders, as
+// redactLiterals leaves IN lists and VALUES rows.
+var placeholderListPattern = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)+\s*\)`)
+
+// queryDigest returns the shape of sqlQuery: without comments, with literals
+// replaced by ?, lists of them collapsed to (?) and whitespace collapsed, so
+// runs of the same query with different values, or lists of different
+// lengths, share a digest.
+func queryDigest(adapter DBAdapter, sqlQuery string) string {
+	digest := placeholderListPattern.ReplaceAllString(redactLiterals(adapter, sqlQuery), "(?)")
+	digest = strings.Join(strings.Fields(digest), " ")
+	return strings.TrimSpace(strings.TrimSuffix(digest, ";"))
+}
+
+// queryFingerprint returns a short hash of a query digest, to group records
+// by query shape.
+func queryFingerprint(digest string) string {
+	sum := sha256.Sum256([]byte(digest))
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,27 @@
+package mcpsqldb
+
+import "testing"
+
+func TestQueryDigest(t *testing.T) {
+	tests := []struct {
+		adapter DBAdapter
+		query   string
+		want    string
+	}{
+		{&PostgresAdapter{}, "SELECT *\n  FROM users\tWHERE id = 7;", "SELECT * FROM users WHERE id = ?"},
+		{&PostgresAdapter{}, "SELECT * FROM users WHERE name = 'bob' -- lookup", "SELECT * FROM users WHERE name = ?"},
+		{&MySQLAdapter{}, "SELECT * FROM t2 WHERE id IN (1, 2, 3) # note", "SELECT * FROM t2 WHERE id IN (?)"},
+		{&SQLiteAdapter{}, "SELECT * FROM t2 WHERE id IN ( 4 ,5 )", "SELECT * FROM t2 WHERE id IN (?)"},
+	}
+	for _, tt := range tests {
+		if got := queryDigest(tt.adapter, tt.query); got != tt.want {
+			t.Errorf("Expected %q for %q, got %q", tt.want, tt.query, got)
+		}
+	}
+
+	a := queryFingerprint(queryDigest(&SQLiteAdapter{}, "SELECT * FROM users WHERE id IN (1, 2)"))
+	b := queryFingerprint(queryDigest(&SQLiteAdapter{}, "SELECT * FROM orders WHERE id IN (1, 2)"))
+	if a != queryFingerprint(queryDigest(&SQLiteAdapter{}, "SELECT  * FROM users WHERE id IN (9)")) || a == b || len(a) != 16 {
+		t.Errorf("Expected equal fingerprints for the same shape only, got %q and %q", a, b)
+	}
+}
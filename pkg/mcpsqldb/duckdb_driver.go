@@ -0,0 +1,7 @@
+//go:build duckdb
+
+package mcpsqldb
+
+// The DuckDB driver links DuckDB's C++ library through cgo, so it is only
+// built in on request: go build -tags duckdb
+import _ "github.com/marcboeker/go-duckdb/v2"
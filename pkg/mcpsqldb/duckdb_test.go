@@ -0,0 +1,163 @@
+package mcpsqldb
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDuckDBValidateQuery_AllowedQueries(t *testing.T) {
+	adapter := &DuckDBAdapter{}
+	allowedQueries := []string{
+		"SELECT * FROM users",
+		"SELECT * FROM read_parquet('/data/events/*.parquet') LIMIT 10",
+		"SELECT count(*) FROM read_csv('/data/sales.csv')",
+		"SELECT * FROM users USING SAMPLE reservoir(10 ROWS)",
+		"SELECT load, export_date FROM servers",
+		"SELECT * FROM duckdb_tables()",
+		"SELECT * FROM pragma_table_info('users')",
+		"SELECT * FROM users WHERE note = 'COPY users TO ''/tmp/x.csv'''",
+		"SELECT $$INSTALL httpfs$$ AS text",
+		"DESCRIBE users",
+		"SHOW TABLES",
+		"EXPLAIN SELECT * FROM users",
+	}
+
+	for _, query := range allowedQueries {
+		t.Run(query, func(t *testing.T) {
+			if err := adapter.ValidateQuery(query); err != nil {
+				t.Errorf("Expected query to be allowed, but got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDuckDBValidateQuery_BlockedQueries(t *testing.T) {
+	adapter := &DuckDBAdapter{}
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"INSERT INTO users VALUES (1, 'test')", "INSERT"},
+		{"CREATE TABLE t AS SELECT * FROM read_parquet('x.parquet')", "CREATE"},
+		{"SET enable_external_access = true", "SET"},
+		{"SELECT 1; INSTALL httpfs", "multiple statements"},
+		{"COPY users TO '/tmp/users.csv'", "COPY"},
+		{"EXPLAIN COPY users TO '/tmp/users.csv'", "COPY"},
+		{"EXPORT DATABASE '/tmp/dump'", "EXPORT"},
+		{"IMPORT DATABASE '/tmp/dump'", "IMPORT"},
+		{"INSTALL httpfs", "INSTALL"},
+		{"EXPLAIN INSTALL httpfs", "INSTALL"},
+		{"LOAD httpfs", "LOAD"},
+		{"EXPLAIN LOAD httpfs", "LOAD"},
+		{"EXPLAIN ATTACH '/tmp/other.duckdb' AS other", "ATTACH"},
+		{"EXPLAIN PRAGMA enable_profiling", "PRAGMA"},
+		{"EXPLAIN CALL pragma_version()", "CALL"},
+		{"EXPLAIN CHECKPOINT", "CHECKPOINT"},
+		{"SELECT * FROM query('DROP TABLE users')", "query()"},
+		{"SELECT * FROM query_table('users')", "query_table()"},
+		{"SELECT getenv('AWS_SECRET_ACCESS_KEY')", "getenv()"},
+		{"SELECT * FROM duckdb_secrets()", "duckdb_secrets()"},
+		{"SELECT * FROM users FOR SYSTEM_TIME AS OF '2024-01-01'", "FOR SYSTEM_TIME"},
+	}
+
+	for _, tc := range blockedQueries {
+		t.Run(tc.query, func(t *testing.T) {
+			if err := adapter.ValidateQuery(tc.query); err == nil {
+				t.Errorf("Expected query to be blocked for %s, but it was allowed", tc.shouldBlock)
+			}
+		})
+	}
+}
+
+func TestDuckDBResolveDSN_RequiresDriver(t *testing.T) {
+	if slices.Contains(sql.Drivers(), "duckdb") {
+		t.Skip("built with the DuckDB driver")
+	}
+	_, err := (&DuckDBAdapter{}).ResolveDSN("/data/analytics.duckdb")
+	if err == nil || !strings.Contains(err.Error(), "-tags duckdb") {
+		t.Errorf("Expected an error naming the duckdb build tag, got %v", err)
+	}
+}
+
+func TestSandboxDuckDBDSN(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	db := filepath.Join(allowed, "analytics.duckdb")
+	secret := filepath.Join(outside, "secret.duckdb")
+	os.WriteFile(db, nil, 0600)
+	os.WriteFile(secret, nil, 0600)
+	resolvedDB, _ := filepath.EvalSymlinks(db)
+
+	got, err := sandboxDuckDBDSN(db+"?access_mode=read_write&threads=4&allowed_paths=/etc/passwd", []string{allowed})
+	if err != nil {
+		t.Fatalf("Expected allowed path to be accepted, got %v", err)
+	}
+	path, query, _ := strings.Cut(got, "?")
+	params, _ := url.ParseQuery(query)
+	if path != resolvedDB {
+		t.Errorf("Expected resolved path %s, got %s", resolvedDB, path)
+	}
+	want := map[string]string{
+		"access_mode":            "read_only",
+		"enable_external_access": "false",
+		"lock_configuration":     "true",
+		"threads":                "4",
+		"allowed_paths":          "",
+	}
+	for name, value := range want {
+		if params.Get(name) != value {
+			t.Errorf("Expected %s=%q, got %q", name, value, params.Get(name))
+		}
+	}
+	if dirs := params.Get("allowed_directories"); !strings.Contains(dirs, "'"+allowed+string(filepath.Separator)+"'") {
+		t.Errorf("Expected allowed_directories to list %s, got %q", allowed, dirs)
+	}
+
+	if _, err := sandboxDuckDBDSN(secret, []string{allowed}); err == nil || !strings.Contains(err.Error(), "MCP_DUCKDB_ALLOWED_DIRS") {
+		t.Errorf("Expected a path outside the allowed dirs to be rejected, got %v", err)
+	}
+
+	// Without allowed dirs any file opens, but queries may read no other files
+	got, err = sandboxDuckDBDSN(secret, nil)
+	if err != nil || strings.Contains(got, "allowed_directories") || !strings.Contains(got, "enable_external_access=false") {
+		t.Errorf("Expected unrestricted path without allowed directories, got %q (err %v)", got, err)
+	}
+}
+
+func TestDuckDBAdapter_Dialect(t *testing.T) {
+	adapter := &DuckDBAdapter{}
+
+	if dsn, _ := adapter.FormatDSN(ConnParams{Database: "/data/a.duckdb"}); dsn != "/data/a.duckdb?access_mode=read_only" {
+		t.Errorf("Expected read-only DSN, got %s", dsn)
+	}
+	if name := adapter.DatabaseName("/data/analytics.duckdb?access_mode=read_only"); name != "analytics" {
+		t.Errorf("Expected database name analytics, got %s", name)
+	}
+	if expr := adapter.JSONExtract(`"payload"`, []string{"user", "id"}); expr != `json_extract_string("payload", '$.user.id')` {
+		t.Errorf("Expected json_extract_string expression, got %s", expr)
+	}
+	if expr, _ := adapter.TimeBucket(`"ts"`, "week"); expr != `date_trunc('week', "ts")` {
+		t.Errorf("Expected date_trunc expression, got %s", expr)
+	}
+	if query, _ := adapter.SampleQuery(nil, nil, "analytics", "events", 50); query != `SELECT * FROM "events" USING SAMPLE reservoir(50 ROWS)` {
+		t.Errorf("Expected reservoir sample, got %s", query)
+	}
+	if got := adapter.NormalizeRowLimit("SELECT TOP 5 * FROM events"); got != "SELECT * FROM events LIMIT 5" {
+		t.Errorf("Expected TOP to become LIMIT, got %s", got)
+	}
+}
+
+func TestNewAdapter_DuckDB(t *testing.T) {
+	adapter, err := newAdapter("DuckDB")
+	if err != nil {
+		t.Fatalf("Expected duckdb to be registered, got %v", err)
+	}
+	if _, ok := adapter.(*DuckDBAdapter); !ok {
+		t.Errorf("Expected a DuckDBAdapter, got %T", adapter)
+	}
+}
@@ -0,0 +1,354 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EffectiveConfigFlag makes the server print the configuration it would
+// run with, resolved from flags, environment and defaults, and exit
+// without connecting to the database
+const EffectiveConfigFlag = "--print-effective-config"
+
+// Where a setting's value came from, as reported by EffectiveConfigFlag
+const (
+	settingFromDefault     = "default"
+	settingFromArgument    = "argument"
+	settingFromEnvironment = "environment"
+)
+
+// effectiveConfig is the document EffectiveConfigFlag prints. Credentials
+// are redacted; settings with several sources say which one won in their
+// *_from field, naming the flag or env var.
+type effectiveConfig struct {
+	Database  effectiveDatabase  `json:"database"`
+	Transport effectiveTransport `json:"transport"`
+	Limits    effectiveLimits    `json:"limits"`
+	Access    effectiveAccess    `json:"access"`
+	Features  effectiveFeatures  `json:"features"`
+	Tools     []string           `json:"tools"`
+	// Problems are the errors the server would stop at before connecting
+	Problems []string `json:"problems,omitempty"`
+}
+
+type effectiveDatabase struct {
+	Driver              string `json:"driver"`
+	DriverFrom          string `json:"driver_from"`
+	DSN                 string `json:"dsn,omitempty"`
+	DSNFrom             string `json:"dsn_from"`
+	SSHHost             string `json:"ssh_host,omitempty"`
+	CloudSQLInstance    string `json:"cloudsql_instance,omitempty"`
+	RequireTLS          bool   `json:"require_tls"`
+	RequireReadOnlyUser bool   `json:"require_readonly_user"`
+	WriteProbe          bool   `json:"write_probe"`
+}
+
+type effectiveTransport struct {
+	Mode     string         `json:"mode"`
+	ModeFrom string         `json:"mode_from"`
+	HTTP     *effectiveHTTP `json:"http,omitempty"`
+}
+
+type effectiveHTTP struct {
+	Listen               string   `json:"listen"`
+	ListenFrom           string   `json:"listen_from"`
+	PublicURL            string   `json:"public_url,omitempty"`
+	AllowedOrigins       []string `json:"allowed_origins"`
+	MaxSessions          int      `json:"max_sessions"`
+	AuthTokens           int      `json:"auth_tokens"`
+	OIDCIssuer           string   `json:"oidc_issuer,omitempty"`
+	OIDCAudience         string   `json:"oidc_audience,omitempty"`
+	RateLimitQPS         float64  `json:"rate_limit_qps"`
+	RateLimitBurst       int      `json:"rate_limit_burst"`
+	SSEKeepaliveInterval string   `json:"sse_keepalive_interval"`
+}
+
+type effectiveLimits struct {
+	QueryTimeout          string  `json:"query_timeout"`
+	AsyncQueryTimeout     string  `json:"async_query_timeout"`
+	MaxRows               int     `json:"max_rows"`
+	MaxResultBytes        int     `json:"max_result_bytes"`
+	MaxColumns            int     `json:"max_columns"`
+	MaxRequestBytes       int     `json:"max_request_bytes"`
+	MaxArgumentBytes      int     `json:"max_argument_bytes"`
+	MaxQueryCost          float64 `json:"max_query_cost"`
+	ForbiddenQueryCost    float64 `json:"forbidden_query_cost"`
+	Workers               int     `json:"workers"`
+	QueueDepth            int     `json:"queue_depth"`
+	QueuePolicy           string  `json:"queue_policy"`
+	QueryRetries          int     `json:"query_retries"`
+	QuotaQueriesPerMinute int     `json:"quota_queries_per_minute"`
+	QuotaSessionRows      int64   `json:"quota_session_rows"`
+	QuotaSessionBytes     int64   `json:"quota_session_bytes"`
+	KeepaliveInterval     string  `json:"keepalive_interval"`
+	IdleTimeout           string  `json:"idle_timeout"`
+	CrossQueryMaxRows     int     `json:"cross_query_max_rows"`
+	SavedResultMaxRows    int     `json:"saved_result_max_rows"`
+	ResultCacheMaxRows    int     `json:"result_cache_max_rows"`
+}
+
+type effectiveAccess struct {
+	DeniedTables      []string `json:"denied_tables"`
+	DeniedSchemas     []string `json:"denied_schemas"`
+	ActiveSchemas     []string `json:"active_schemas"`
+	ShowAllowed       []string `json:"show_allowed"`
+	ShowDenied        []string `json:"show_denied"`
+	MaskedColumns     []string `json:"masked_columns"`
+	TableRowLimits    []string `json:"table_row_limits"`
+	SessionVariables  []string `json:"session_variables"`
+	SQLiteAllowedDirs []string `json:"sqlite_allowed_dirs"`
+	DuckDBAllowedDirs []string `json:"duckdb_allowed_dirs"`
+	RedactSQLLiterals bool     `json:"redact_sql_literals"`
+}
+
+type effectiveFeatures struct {
+	AuditLog          string                     `json:"audit_log,omitempty"`
+	AuditChain        bool                       `json:"audit_chain"`
+	ResultCache       string                     `json:"result_cache,omitempty"`
+	CrossSources      map[string]effectiveSource `json:"cross_sources,omitempty"`
+	SnapshotSession   bool                       `json:"snapshot_session"`
+	SchemaSnapshotDir string                     `json:"schema_snapshot_dir,omitempty"`
+	DebugWire         bool                       `json:"debug_wire"`
+}
+
+type effectiveSource struct {
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+// parseEffectiveConfigFlag removes EffectiveConfigFlag from args, reporting
+// whether it was present.
+func parseEffectiveConfigFlag(args []string) ([]string, bool) {
+	rest := make([]string, 0, len(args))
+	found := false
+	for _, arg := range args {
+		if arg == EffectiveConfigFlag {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return rest, found
+}
+
+// runPrintEffectiveConfig writes the effective configuration as JSON to
+// out. cmdline is the command line as given, to tell which flags were set;
+// args are the arguments left once the flags were applied. It returns the
+// process exit code, 1 when the configuration has problems.
+func runPrintEffectiveConfig(cmdline, args []string, demo bool, out io.Writer) int {
+	config := effectiveConfiguration(cmdline, args, demo)
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		fmt.Fprintf(out, "Failed to encode configuration: %v\n", err)
+		return 1
+	}
+	fmt.Fprintln(out, string(data))
+	if len(config.Problems) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// effectiveConfiguration resolves the configuration the server would run
+// with, the way Main does but without connecting to anything or creating
+// files.
+func effectiveConfiguration(cmdline, args []string, demo bool) *effectiveConfig {
+	config := &effectiveConfig{}
+	adapter := config.resolveDatabase(args, demo)
+
+	config.Transport = effectiveTransport{Mode: Transport, ModeFrom: settingSource(cmdline, TransportFlag, "MCP_TRANSPORT")}
+	if Transport == TransportHTTP {
+		config.Transport.HTTP = &effectiveHTTP{
+			Listen:               HTTPListen,
+			ListenFrom:           settingSource(cmdline, ListenFlag, "MCP_HTTP_LISTEN"),
+			PublicURL:            HTTPPublicURL,
+			AllowedOrigins:       nonNil(HTTPAllowedOrigins),
+			MaxSessions:          HTTPMaxSessions,
+			AuthTokens:           len(AuthTokens),
+			OIDCIssuer:           OIDCIssuer,
+			OIDCAudience:         OIDCAudience,
+			RateLimitQPS:         RateLimitQPS,
+			RateLimitBurst:       RateLimitBurst,
+			SSEKeepaliveInterval: SSEKeepaliveInterval.String(),
+		}
+		if OIDCIssuer != "" && OIDCAudience == "" {
+			config.Problems = append(config.Problems, "MCP_OIDC_AUDIENCE is required with MCP_OIDC_ISSUER")
+		}
+	}
+
+	config.Limits = effectiveLimits{
+		QueryTimeout:          QueryTimeout.String(),
+		AsyncQueryTimeout:     AsyncQueryTimeout.String(),
+		MaxRows:               MaxResultRows,
+		MaxResultBytes:        MaxResultBytes,
+		MaxColumns:            MaxResultColumns,
+		MaxRequestBytes:       MaxRequestBytes,
+		MaxArgumentBytes:      MaxArgumentBytes,
+		MaxQueryCost:          MaxQueryCost,
+		ForbiddenQueryCost:    ForbiddenQueryCost,
+		Workers:               WorkerCount,
+		QueueDepth:            QueueDepth,
+		QueuePolicy:           QueuePolicy,
+		QueryRetries:          QueryRetries,
+		QuotaQueriesPerMinute: QuotaQueriesPerMinute,
+		QuotaSessionRows:      QuotaSessionRows,
+		QuotaSessionBytes:     QuotaSessionBytes,
+		KeepaliveInterval:     durationSetting(KeepaliveInterval),
+		IdleTimeout:           durationSetting(IdleTimeout),
+		CrossQueryMaxRows:     CrossQueryMaxRows,
+		SavedResultMaxRows:    SavedResultMaxRows,
+		ResultCacheMaxRows:    ResultCacheMaxRows,
+	}
+
+	masked := []string{}
+	for _, r := range MaskRules {
+		masked = append(masked, fmt.Sprintf("%s.%s=%s", r.table, r.column, r.strategy))
+	}
+	rowLimits := []string{}
+	for _, l := range TableRowLimits {
+		rowLimits = append(rowLimits, fmt.Sprintf("%s=%d", l.pattern, l.rows))
+	}
+	config.Access = effectiveAccess{
+		DeniedTables:      nonNil(DeniedTables),
+		DeniedSchemas:     nonNil(DeniedSchemas),
+		ActiveSchemas:     nonNil(ActiveSchemas),
+		ShowAllowed:       nonNil(ShowAllowed),
+		ShowDenied:        nonNil(ShowDenied),
+		MaskedColumns:     masked,
+		TableRowLimits:    rowLimits,
+		SessionVariables:  nonNil(SessionVariables),
+		SQLiteAllowedDirs: nonNil(SQLiteAllowedDirs),
+		DuckDBAllowedDirs: nonNil(DuckDBAllowedDirs),
+		RedactSQLLiterals: RedactSQLLiterals,
+	}
+
+	config.Features = effectiveFeatures{
+		AuditLog:          AuditLogPath,
+		AuditChain:        AuditChain,
+		ResultCache:       ResultCachePath,
+		SnapshotSession:   SnapshotSession,
+		SchemaSnapshotDir: SchemaSnapshotDir,
+		DebugWire:         DebugWire,
+	}
+	sources := crossSources{}
+	for name, source := range CrossQuerySources {
+		if config.Features.CrossSources == nil {
+			config.Features.CrossSources = map[string]effectiveSource{}
+		}
+		config.Features.CrossSources[name] = effectiveSource{Driver: source.Driver, DSN: redactSecrets(source.DSN)}
+		sources[name] = nil
+	}
+
+	// List the tools as a server configured like this would
+	s := &Server{adapter: adapter, sources: sources}
+	if ResultCachePath != "" {
+		s.cache = &resultCache{path: ResultCachePath}
+	}
+	list, _ := s.handleListTools()
+	for _, tool := range list.Tools {
+		config.Tools = append(config.Tools, tool.Name)
+	}
+	sort.Strings(config.Tools)
+	return config
+}
+
+// resolveDatabase fills in the database the server would serve, in the
+// order Main picks it, and returns its adapter, or nil with a problem
+// recorded when there is none. The DSN is built but not resolved, which
+// would open SQLite and DuckDB files.
+func (c *effectiveConfig) resolveDatabase(args []string, demo bool) DBAdapter {
+	db := &c.Database
+	db.SSHHost, db.CloudSQLInstance = SSHHost, CloudSQLInstance
+	db.RequireTLS, db.RequireReadOnlyUser, db.WriteProbe = RequireTLS, RequireReadOnlyUser, WriteProbe
+
+	switch {
+	case demo:
+		db.Driver, db.DriverFrom, db.DSNFrom = "sqlite", DemoFlag, DemoFlag
+		return &SQLiteAdapter{}
+	case len(FlatFiles) > 0:
+		db.Driver, db.DriverFrom, db.DSNFrom = "sqlite", "MCP_FILES", "MCP_FILES"
+		for _, path := range FlatFiles {
+			if format, err := flatFileFormat(path); err != nil {
+				c.Problems = append(c.Problems, err.Error())
+			} else if format == "parquet" {
+				db.Driver = "duckdb"
+			}
+		}
+		return newAdapterOrNil(db.Driver)
+	}
+
+	if err := loadAdapterPlugins(AdapterPlugins); err != nil {
+		c.Problems = append(c.Problems, err.Error())
+	}
+	db.DriverFrom = "MCP_DB_DRIVER"
+	adapter, err := selectAdapter()
+	if err != nil {
+		c.Problems = append(c.Problems, err.Error())
+		db.DSNFrom = settingFromDefault
+		return nil
+	}
+	db.Driver = strings.ToLower(os.Getenv("MCP_DB_DRIVER"))
+
+	secrets, err := newAWSSecretSource()
+	switch {
+	case len(args) >= 1:
+		db.DSN, db.DSNFrom = redactSecrets(args[0]), settingFromArgument
+	case err != nil:
+		c.Problems = append(c.Problems, err.Error())
+		db.DSNFrom = settingFromEnvironment
+	case secrets != nil && SecretARN != "":
+		db.DSNFrom = "MCP_SECRET_ARN"
+	case secrets != nil:
+		db.DSNFrom = "MCP_SSM_PARAMETER"
+	default:
+		db.DSNFrom = settingFromEnvironment
+		dsn, err := adapter.BuildDSN()
+		if err != nil {
+			c.Problems = append(c.Problems, err.Error())
+		}
+		db.DSN = redactSecrets(dsn)
+	}
+	return adapter
+}
+
+// newAdapterOrNil returns the named adapter, or nil when it is not built in.
+func newAdapterOrNil(name string) DBAdapter {
+	adapter, _ := newAdapter(name)
+	return adapter
+}
+
+// settingSource names where a setting that flag and env both set came
+// from: the flag when cmdline has it, as --flag value or --flag=value, else
+// env when set, else the default.
+func settingSource(cmdline []string, flag, env string) string {
+	for _, arg := range cmdline {
+		if arg == flag || strings.HasPrefix(arg, flag+"=") {
+			return flag
+		}
+	}
+	if os.Getenv(env) != "" {
+		return env
+	}
+	return settingFromDefault
+}
+
+// durationSetting formats a duration setting where 0 means disabled.
+func durationSetting(d time.Duration) string {
+	if d <= 0 {
+		return "disabled"
+	}
+	return d.String()
+}
+
+// nonNil returns list, or an empty list for nil, so it encodes as [].
+func nonNil(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
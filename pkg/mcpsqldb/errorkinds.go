@@ -0,0 +1,54 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+)
+
+// newErrorInfo describes an error of kind. Timeouts, lost connections,
+// quotas and rate limits clear up on their own; rejected and oversized
+// queries must change.
+func newErrorInfo(kind string) *ErrorInfo {
+	switch kind {
+	case ErrorKindTimeout, ErrorKindConnectionLost, ErrorKindQuotaExceeded, ErrorKindRateLimited:
+		return &ErrorInfo{Kind: kind, Retryable: true}
+	default:
+		return &ErrorInfo{Kind: kind}
+	}
+}
+
+// errorKind classifies a database error returned under ctx, or returns ""
+// for failures outside the taxonomy such as syntax errors. Drivers report a
+// cancelled statement in their own words, so ctx decides timeouts.
+func errorKind(ctx context.Context, err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return ErrorKindTimeout
+	case isConnectionError(err):
+		return ErrorKindConnectionLost
+	default:
+		return ""
+	}
+}
+
+// withErrorKind classifies a tool error result as kind; an empty kind leaves
+// it unclassified.
+func withErrorKind(result *CallToolResult, kind string) *CallToolResult {
+	if kind != "" {
+		result.Meta = &ResultMeta{Error: newErrorInfo(kind)}
+	}
+	return result
+}
+
+// internalError is the RPC error for a database operation that failed with
+// err, classified in Data when errorKind recognizes it.
+func internalError(ctx context.Context, message string, err error) *Error {
+	rpcErr := &Error{
+		Code:    InternalError,
+		Message: message,
+	}
+	if kind := errorKind(ctx, err); kind != "" {
+		rpcErr.Data = newErrorInfo(kind)
+	}
+	return rpcErr
+}
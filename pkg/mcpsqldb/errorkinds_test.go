@@ -0,0 +1,113 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestErrorKind(t *testing.T) {
+	expired, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+		want string
+	}{
+		{"deadline", context.Background(), fmt.Errorf("query: %w", context.DeadlineExceeded), ErrorKindTimeout},
+		{"driver cancellation", expired, fmt.Errorf("pq: canceling statement due to user request"), ErrorKindTimeout},
+		{"bad connection", context.Background(), driver.ErrBadConn, ErrorKindConnectionLost},
+		{"reset", context.Background(), fmt.Errorf("read tcp: connection reset by peer"), ErrorKindConnectionLost},
+		{"syntax", context.Background(), fmt.Errorf("near \"SELEC\": syntax error"), ""},
+	}
+	for _, tc := range tests {
+		if got := errorKind(tc.ctx, tc.err); got != tc.want {
+			t.Errorf("%s: expected %q, got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+// toolErrorKind calls a tool and returns the kind in the error's _meta as
+// serialized on the wire.
+func toolErrorKind(t *testing.T, server *Server, name string, args map[string]any) (string, bool) {
+	t.Helper()
+	result, rpcErr := server.callTool(context.Background(), name, args)
+	if rpcErr != nil || !result.IsError {
+		t.Fatalf("Expected a tool error from %s, got %v %+v", name, rpcErr, result)
+	}
+	data, _ := json.Marshal(result)
+	var wire struct {
+		Meta *struct {
+			Error *ErrorInfo `json:"error"`
+		} `json:"_meta"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if wire.Meta == nil || wire.Meta.Error == nil {
+		return "", false
+	}
+	return wire.Meta.Error.Kind, wire.Meta.Error.Retryable
+}
+
+func TestToolErrors_CarryKind(t *testing.T) {
+	server := newTestServer(t)
+
+	if kind, retryable := toolErrorKind(t, server, "query", map[string]any{"sql": "DELETE FROM users"}); kind != ErrorKindValidationRejected || retryable {
+		t.Errorf("Expected a non-retryable %s, got %q %v", ErrorKindValidationRejected, kind, retryable)
+	}
+	if kind, _ := toolErrorKind(t, server, "compare_queries", map[string]any{
+		"before_sql": "SELECT id FROM users", "after_sql": "DELETE FROM users", "key_columns": []any{"id"},
+	}); kind != ErrorKindValidationRejected {
+		t.Errorf("Expected %s from compare_queries, got %q", ErrorKindValidationRejected, kind)
+	}
+	if kind, ok := toolErrorKind(t, server, "query", map[string]any{"sql": "SELECT * FROM missing"}); ok {
+		t.Errorf("Expected an unclassified error for a missing table, got %q", kind)
+	}
+
+	defer func(orig int) { MaxResultBytes = orig }(MaxResultBytes)
+	MaxResultBytes = 10
+	if kind, _ := toolErrorKind(t, server, "query", map[string]any{"sql": "SELECT * FROM users"}); kind != ErrorKindTooLarge {
+		t.Errorf("Expected %s, got %q", ErrorKindTooLarge, kind)
+	}
+	MaxResultBytes = 64 << 20
+
+	server.quota = newSessionQuota(0, 1, 0)
+	toolErrorKind(t, server, "query", map[string]any{"sql": "SELECT * FROM missing"})
+	server.quota.record(1, 0)
+	if kind, retryable := toolErrorKind(t, server, "query", map[string]any{"sql": "SELECT 1"}); kind != ErrorKindQuotaExceeded || !retryable {
+		t.Errorf("Expected a retryable %s, got %q %v", ErrorKindQuotaExceeded, kind, retryable)
+	}
+}
+
+func TestToolErrors_Timeout(t *testing.T) {
+	defer func(orig time.Duration) { QueryTimeout = orig }(QueryTimeout)
+	QueryTimeout = 50 * time.Millisecond
+	server := newTestServer(t)
+
+	kind, retryable := toolErrorKind(t, server, "query", map[string]any{
+		"sql": "SELECT COUNT(*) FROM (WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT i FROM n)",
+	})
+	if kind != ErrorKindTimeout || !retryable {
+		t.Errorf("Expected a retryable %s, got %q %v", ErrorKindTimeout, kind, retryable)
+	}
+}
+
+func TestInternalError_Data(t *testing.T) {
+	rpcErr := internalError(context.Background(), "Failed to list tables", driver.ErrBadConn)
+	if info, ok := rpcErr.Data.(*ErrorInfo); !ok || info.Kind != ErrorKindConnectionLost || !info.Retryable {
+		t.Errorf("Expected connection_lost data, got %+v", rpcErr.Data)
+	}
+	if rpcErr := internalError(context.Background(), "x", fmt.Errorf("permission denied")); rpcErr.Data != nil {
+		t.Errorf("Expected no data for an unclassified error, got %+v", rpcErr.Data)
+	}
+	if !strings.Contains(internalError(context.Background(), "Failed to list tables", driver.ErrBadConn).Message, "list tables") {
+		t.Error("Expected the message to be kept")
+	}
+}
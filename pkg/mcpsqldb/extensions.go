@@ -0,0 +1,82 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// ExtensionInfo is an extension installed in a PostgreSQL database.
+type ExtensionInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	// Schema is where the extension's objects live, e.g. where to find
+	// PostGIS's functions when it is not on the search_path
+	Schema      string `json:"schema"`
+	Description string `json:"description,omitempty"`
+	// DefaultVersion is the version the server would install now, when it
+	// differs from Version
+	DefaultVersion string `json:"default_version,omitempty"`
+}
+
+// extensionsResourceURI returns the URI of the database's extension list.
+func (s *Server) extensionsResourceURI() string {
+	return fmt.Sprintf("%s://%s/extensions", s.adapter.URIScheme(), s.databaseName)
+}
+
+// extensionsResources lists the extension inventory on databases with
+// extensions, so the assistant can check whether features such as PostGIS,
+// pg_trgm or TimescaleDB are available before writing queries that need them.
+func (s *Server) extensionsResources() []Resource {
+	if _, ok := s.adapter.(ExtensionLister); !ok || s.databaseName == "" || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return nil
+	}
+	return []Resource{{
+		URI:         s.extensionsResourceURI(),
+		Name:        fmt.Sprintf("Extensions installed in '%s'", s.databaseName),
+		Description: "Installed extensions (pg_extension) with their versions and schemas",
+		MimeType:    "application/json",
+	}}
+}
+
+// readExtensionsResource serves the extension list for a URI of the form
+// <scheme>://<database>/extensions. ok is false for other URIs.
+func (s *Server) readExtensionsResource(ctx context.Context, uri string) (result *ReadResourceResult, rpcErr *Error, ok bool) {
+	lister, hasExtensions := s.adapter.(ExtensionLister)
+	if !hasExtensions || uri != s.extensionsResourceURI() || s.databaseName == "" {
+		return nil, nil, false
+	}
+	if isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Database not found: %s", s.databaseName),
+		}, true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err), true
+	}
+	defer s.workers.release()
+
+	extensions, err := lister.ListExtensions(ctx, s.db)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to list extensions: %v", err), err), true
+	}
+	if extensions == nil {
+		extensions = []ExtensionInfo{}
+	}
+
+	data, err := json.MarshalIndent(extensions, "", "  ")
+	if err != nil {
+		return nil, &Error{
+			Code:    InternalError,
+			Message: fmt.Sprintf("Failed to marshal extensions: %v", err),
+		}, true
+	}
+	return &ReadResourceResult{
+		Contents: []ResourceContent{{URI: uri, MimeType: "application/json", Text: string(data)}},
+	}, nil, true
+}
@@ -0,0 +1,73 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+)
+
+func TestExtensionsResources(t *testing.T) {
+	server := &Server{adapter: &PostgresAdapter{}, databaseName: "app"}
+	resources := server.extensionsResources()
+	if len(resources) != 1 || resources[0].URI != "postgres://app/extensions" {
+		t.Errorf("Expected the extensions resource for PostgreSQL, got %+v", resources)
+	}
+
+	defer func(orig []string) { DeniedSchemas = orig }(DeniedSchemas)
+	DeniedSchemas = []string{"app"}
+	if resources := server.extensionsResources(); len(resources) != 0 {
+		t.Errorf("Expected no extensions resource for a denied database, got %+v", resources)
+	}
+}
+
+func TestExtensionsResources_SQLite(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	list, rpcErr := server.handleListResources(ctx)
+	if rpcErr != nil {
+		t.Fatalf("Failed to list resources: %v", rpcErr.Message)
+	}
+	for _, r := range list.Resources {
+		if r.URI == server.extensionsResourceURI() {
+			t.Errorf("Expected no extensions resource for SQLite, got %+v", r)
+		}
+	}
+
+	params, _ := json.Marshal(ReadResourceParams{URI: server.extensionsResourceURI()})
+	if _, rpcErr := server.handleReadResource(ctx, params); rpcErr == nil {
+		t.Error("Expected reading extensions on SQLite to fail")
+	}
+}
+
+// extensionsAdapter lists fixed extensions, as an adapter plugin for a
+// database with extensions would.
+type extensionsAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *extensionsAdapter) ListExtensions(ctx context.Context, db *sql.DB) ([]ExtensionInfo, error) {
+	return []ExtensionInfo{{Name: "fts", Version: "1.0", Schema: "main"}}, nil
+}
+
+func TestExtensionsResources_AdapterCapability(t *testing.T) {
+	server := newTestServer(t)
+	server.adapter = &extensionsAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	if resources := server.extensionsResources(); len(resources) != 1 {
+		t.Fatalf("Expected the extensions resource for an adapter listing extensions, got %+v", resources)
+	}
+
+	params, _ := json.Marshal(ReadResourceParams{URI: server.extensionsResourceURI()})
+	result, rpcErr := server.handleReadResource(context.Background(), params)
+	if rpcErr != nil {
+		t.Fatalf("Failed to read extensions: %v", rpcErr.Message)
+	}
+	var extensions []ExtensionInfo
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &extensions); err != nil {
+		t.Fatalf("Failed to parse extensions: %v", err)
+	}
+	if len(extensions) != 1 || extensions[0].Name != "fts" {
+		t.Errorf("Expected the adapter's extensions, got %+v", extensions)
+	}
+}
@@ -0,0 +1,427 @@
+package mcpsqldb
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"modernc.org/sqlite/vtab"
+)
+
+// FlatFiles are the CSV, TSV and Parquet files served as read-only tables
+// instead of the configured database, keyed by table name (overridable via
+// MCP_FILES env var, comma-separated paths or table=path pairs)
+var FlatFiles map[string]string
+
+// flatFileModuleName is the SQLite virtual table module serving CSV files.
+const flatFileModuleName = "csvfile"
+
+// flatFileSniffRows is how many rows decide a CSV column's type.
+const flatFileSniffRows = 100
+
+// parseFlatFiles parses MCP_FILES. A bare path is served as the table named
+// after the file without its extension.
+func parseFlatFiles(v string) map[string]string {
+	files := map[string]string{}
+	for _, entry := range strings.Split(v, ",") {
+		if entry = strings.TrimSpace(entry); entry == "" {
+			continue
+		}
+		table, path, ok := strings.Cut(entry, "=")
+		if !ok {
+			path = entry
+			table = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		}
+		files[strings.TrimSpace(table)] = strings.TrimSpace(path)
+	}
+	return files
+}
+
+// flatFileFormat returns the format of path by its extension: csv, tsv or
+// parquet.
+func flatFileFormat(path string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".csv", ".tsv", ".parquet":
+		return ext[1:], nil
+	default:
+		return "", fmt.Errorf("file %q is not a .csv, .tsv or .parquet file", path)
+	}
+}
+
+// createFlatFileCatalog writes a database into dir defining a table for
+// each file and returns the adapter and read-only DSN to serve it with.
+// CSV and TSV files are read by SQLite virtual tables; Parquet needs DuckDB,
+// so any Parquet file makes every table a DuckDB view instead.
+func createFlatFileCatalog(dir string, files map[string]string) (DBAdapter, string, error) {
+	tables := make([]string, 0, len(files))
+	for table := range files {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	resolved := make(map[string]string, len(files))
+	formats := make(map[string]string, len(files))
+	parquet := false
+	for _, table := range tables {
+		if !crossNamePattern.MatchString(table) {
+			return nil, "", fmt.Errorf("invalid table name %q for %s in MCP_FILES (use table=path)", table, files[table])
+		}
+		format, err := flatFileFormat(files[table])
+		if err != nil {
+			return nil, "", err
+		}
+		path, err := resolveDatabaseFile(files[table], nil, strings.ToUpper(format), "")
+		if err != nil {
+			return nil, "", err
+		}
+		resolved[table], formats[table] = path, format
+		parquet = parquet || format == "parquet"
+	}
+
+	if parquet {
+		return createDuckDBFileCatalog(filepath.Join(dir, "files.duckdb"), tables, resolved, formats)
+	}
+	return createSQLiteFileCatalog(filepath.Join(dir, "files.db"), tables, resolved)
+}
+
+// createSQLiteFileCatalog defines a csvfile virtual table per file in a new
+// SQLite database at path. Only the tables' definitions are stored: every
+// connection reads the files themselves.
+func createSQLiteFileCatalog(path string, tables []string, files map[string]string) (DBAdapter, string, error) {
+	if err := registerFlatFileModule(); err != nil {
+		return nil, "", err
+	}
+	for _, table := range tables {
+		flatFiles.allow(files[table])
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file catalog: %w", err)
+	}
+	defer db.Close()
+
+	adapter := &SQLiteAdapter{}
+	for _, table := range tables {
+		stmt := fmt.Sprintf("CREATE VIRTUAL TABLE %s USING %s(%s)",
+			adapter.QuoteIdentifier(table), flatFileModuleName, adapter.QuoteString(files[table]))
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, "", fmt.Errorf("failed to serve %s: %w", files[table], err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to write file catalog: %w", err)
+	}
+	if err := os.Chmod(path, 0o444); err != nil {
+		return nil, "", fmt.Errorf("failed to protect file catalog: %w", err)
+	}
+	return adapter, "file:" + path + "?mode=ro", nil
+}
+
+// createDuckDBFileCatalog defines a view per file in a new DuckDB database
+// at path. The DSN only lets DuckDB read the files themselves, so queries
+// cannot open any other file with read_csv or read_parquet.
+func createDuckDBFileCatalog(path string, tables []string, files, formats map[string]string) (DBAdapter, string, error) {
+	adapter := &DuckDBAdapter{}
+	if !slices.Contains(sql.Drivers(), adapter.DriverName()) {
+		return nil, "", fmt.Errorf("serving Parquet files needs DuckDB; rebuild with -tags duckdb (requires cgo)")
+	}
+
+	db, err := sql.Open(adapter.DriverName(), path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create file catalog: %w", err)
+	}
+	defer db.Close()
+
+	readers := map[string]string{
+		"csv":     "read_csv(%s)",
+		"tsv":     "read_csv(%s, delim = '\\t')",
+		"parquet": "read_parquet(%s)",
+	}
+	allowed := make([]string, 0, len(tables))
+	for _, table := range tables {
+		source := fmt.Sprintf(readers[formats[table]], adapter.QuoteString(files[table]))
+		stmt := fmt.Sprintf("CREATE VIEW %s AS SELECT * FROM %s", adapter.QuoteIdentifier(table), source)
+		if _, err := db.Exec(stmt); err != nil {
+			return nil, "", fmt.Errorf("failed to serve %s: %w", files[table], err)
+		}
+		allowed = append(allowed, adapter.QuoteString(files[table]))
+	}
+	if err := db.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to write file catalog: %w", err)
+	}
+	if err := os.Chmod(path, 0o444); err != nil {
+		return nil, "", fmt.Errorf("failed to protect file catalog: %w", err)
+	}
+
+	params := url.Values{}
+	params.Set("access_mode", "read_only")
+	params.Set("enable_external_access", "false")
+	params.Set("allowed_paths", "["+strings.Join(allowed, ", ")+"]")
+	params.Set("lock_configuration", "true")
+	return adapter, path + "?" + params.Encode(), nil
+}
+
+// flatFilesDatabase serves files from a catalog in a temporary directory.
+// The returned func removes it.
+func flatFilesDatabase(files map[string]string) (DBAdapter, string, func()) {
+	dir, err := os.MkdirTemp("", "readonly-mcp-files-")
+	if err != nil {
+		slog.Error("Failed to create file catalog directory", "error", err)
+		os.Exit(1)
+	}
+	adapter, dsn, err := createFlatFileCatalog(dir, files)
+	if err != nil {
+		os.RemoveAll(dir)
+		slog.Error(err.Error())
+		os.Exit(1)
+	}
+	slog.Info("Serving files", "tables", len(files), "driver", adapter.DriverName())
+	return adapter, dsn, func() { os.RemoveAll(dir) }
+}
+
+// flatFileModule serves CSV and TSV files as read-only SQLite virtual
+// tables. It opens only the files allow was called with, so a database
+// defining its own csvfile tables cannot read any other file.
+type flatFileModule struct {
+	mu      sync.Mutex
+	allowed map[string]bool
+}
+
+var (
+	flatFiles           = &flatFileModule{allowed: map[string]bool{}}
+	flatFileRegistered  sync.Once
+	flatFileRegisterErr error
+)
+
+// registerFlatFileModule makes the csvfile module available to SQLite
+// connections opened from now on.
+func registerFlatFileModule() error {
+	flatFileRegistered.Do(func() {
+		flatFileRegisterErr = vtab.RegisterModule(nil, flatFileModuleName, flatFiles)
+	})
+	return flatFileRegisterErr
+}
+
+// allow lets csvfile tables read the file at path.
+func (m *flatFileModule) allow(path string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.allowed[path] = true
+}
+
+func (m *flatFileModule) Create(ctx vtab.Context, args []string) (vtab.Table, error) {
+	return m.Connect(ctx, args)
+}
+
+// Connect declares the table's columns from the file's header, typed by
+// its first rows. args are the module, database and table names followed
+// by the quoted file path.
+func (m *flatFileModule) Connect(ctx vtab.Context, args []string) (vtab.Table, error) {
+	if len(args) != 4 {
+		return nil, fmt.Errorf("%s takes the file path as its only argument", flatFileModuleName)
+	}
+	path := strings.TrimSpace(args[3])
+	if len(path) < 2 || path[0] != '\'' || path[len(path)-1] != '\'' {
+		return nil, fmt.Errorf("%s file path must be a quoted string", flatFileModuleName)
+	}
+	path = strings.ReplaceAll(path[1:len(path)-1], "''", "'")
+
+	m.mu.Lock()
+	allowed := m.allowed[path]
+	m.mu.Unlock()
+	if !allowed {
+		return nil, fmt.Errorf("%s is not a file configured in MCP_FILES", path)
+	}
+
+	table := &flatFileTable{path: path, comma: ','}
+	if strings.EqualFold(filepath.Ext(path), ".tsv") {
+		table.comma = '\t'
+	}
+	header, err := table.sniff()
+	if err != nil {
+		return nil, err
+	}
+	adapter := &SQLiteAdapter{}
+	columns := make([]string, len(header))
+	for i, name := range header {
+		columns[i] = adapter.QuoteIdentifier(name) + " " + table.types[i]
+	}
+	if err := ctx.Declare("CREATE TABLE x(" + strings.Join(columns, ", ") + ")"); err != nil {
+		return nil, fmt.Errorf("failed to declare columns of %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// flatFileTable is a CSV file with the SQLite type of each column.
+type flatFileTable struct {
+	path  string
+	comma rune
+	types []string
+}
+
+// open returns a reader over the file's records, header included.
+func (t *flatFileTable) open() (*os.File, *csv.Reader, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, nil, err
+	}
+	reader := csv.NewReader(f)
+	reader.Comma = t.comma
+	reader.FieldsPerRecord = -1
+	return f, reader, nil
+}
+
+// sniff reads the header and sets each column's type to INTEGER or REAL
+// when every value in the first rows parses as one, and TEXT otherwise.
+// Unnamed columns are named by their position.
+func (t *flatFileTable) sniff() ([]string, error) {
+	f, reader, err := t.open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%s has no header row", t.path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", t.path, err)
+	}
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i, name := range header {
+		if strings.TrimSpace(name) == "" {
+			header[i] = "column" + strconv.Itoa(i+1)
+		}
+	}
+
+	t.types = make([]string, len(header))
+	for i := range t.types {
+		t.types[i] = "INTEGER"
+	}
+	seen := make([]bool, len(header))
+	for range flatFileSniffRows {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", t.path, err)
+		}
+		for i := range min(len(record), len(header)) {
+			if record[i] == "" {
+				continue
+			}
+			seen[i] = true
+			if t.types[i] == "INTEGER" {
+				if _, err := strconv.ParseInt(record[i], 10, 64); err != nil {
+					t.types[i] = "REAL"
+				}
+			}
+			if t.types[i] == "REAL" {
+				if _, err := strconv.ParseFloat(record[i], 64); err != nil {
+					t.types[i] = "TEXT"
+				}
+			}
+		}
+	}
+	for i := range t.types {
+		if !seen[i] {
+			t.types[i] = "TEXT"
+		}
+	}
+	return header, nil
+}
+
+// BestIndex leaves every constraint to SQLite: the file is always read in
+// full.
+func (t *flatFileTable) BestIndex(info *vtab.IndexInfo) error { return nil }
+
+func (t *flatFileTable) Open() (vtab.Cursor, error) { return &flatFileCursor{table: t}, nil }
+
+func (t *flatFileTable) Disconnect() error { return nil }
+
+func (t *flatFileTable) Destroy() error { return nil }
+
+// flatFileCursor reads a CSV file's rows in order.
+type flatFileCursor struct {
+	table  *flatFileTable
+	file   *os.File
+	reader *csv.Reader
+	record []string
+	row    int64
+	eof    bool
+}
+
+// Filter starts a scan from the first row after the header.
+func (c *flatFileCursor) Filter(idxNum int, idxStr string, vals []vtab.Value) error {
+	c.Close()
+	file, reader, err := c.table.open()
+	if err != nil {
+		return err
+	}
+	c.file, c.reader, c.row = file, reader, 0
+	if _, err := reader.Read(); err != nil && err != io.EOF {
+		return err
+	}
+	return c.Next()
+}
+
+func (c *flatFileCursor) Next() error {
+	record, err := c.reader.Read()
+	if errors.Is(err, io.EOF) {
+		c.eof, c.record = true, nil
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", c.table.path, err)
+	}
+	c.eof, c.record = false, record
+	c.row++
+	return nil
+}
+
+func (c *flatFileCursor) Eof() bool { return c.eof }
+
+// Column converts the field to its column's type. Empty and missing fields
+// are NULL, and values that do not parse as the type stay text.
+func (c *flatFileCursor) Column(col int) (vtab.Value, error) {
+	if col >= len(c.record) || c.record[col] == "" {
+		return nil, nil
+	}
+	field := c.record[col]
+	switch c.table.types[col] {
+	case "INTEGER":
+		if v, err := strconv.ParseInt(field, 10, 64); err == nil {
+			return v, nil
+		}
+	case "REAL":
+		if v, err := strconv.ParseFloat(field, 64); err == nil {
+			return v, nil
+		}
+	}
+	return field, nil
+}
+
+func (c *flatFileCursor) Rowid() (int64, error) { return c.row, nil }
+
+func (c *flatFileCursor) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
@@ -0,0 +1,129 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFlatFiles(t *testing.T) {
+	files := parseFlatFiles(" /data/orders.csv, regions = /data/regions-2024.tsv,,")
+	want := map[string]string{"orders": "/data/orders.csv", "regions": "/data/regions-2024.tsv"}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+}
+
+// writeFlatFile writes content to name in dir and returns its path.
+func writeFlatFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return path
+}
+
+func TestFlatFiles_ServesCSV(t *testing.T) {
+	dir := t.TempDir()
+	orders := writeFlatFile(t, dir, "orders.csv", "\ufeffid,customer,amount,note\n1,ada,12.5,\n2,grace,30,\"rush, gift\"\n3,ada,7.25,late\n")
+	regions := writeFlatFile(t, dir, "regions-2024.tsv", "code\tname\nGB\tUnited Kingdom\nUS\tUnited States\n")
+
+	adapter, dsn, err := createFlatFileCatalog(t.TempDir(), map[string]string{"orders": orders, "regions": regions})
+	if err != nil {
+		t.Fatalf("createFlatFileCatalog failed: %v", err)
+	}
+	server, err := newServer(context.Background(), adapter, dsn)
+	if err != nil {
+		t.Fatalf("Failed to serve files: %v", err)
+	}
+	defer server.Close()
+	ctx := context.Background()
+
+	result, rpcErr := server.executeQuery(ctx, map[string]any{"sql": `
+		SELECT customer, SUM(amount) AS total, COUNT(note) AS notes FROM orders
+		WHERE amount > 8 GROUP BY customer ORDER BY total DESC`})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected the CSV query to succeed, got %v %+v", rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["customer"] != "grace" || rows[0]["total"] != 30.0 || rows[1]["notes"] != 0.0 {
+		t.Errorf("Expected amounts compared as numbers and empty notes as NULL, got %v", rows)
+	}
+
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM regions WHERE code = 'US'"})
+	if result.IsError || !strings.Contains(result.Content[0].Text, "United States") {
+		t.Errorf("Expected the TSV table to be queryable, got %s", result.Content[0].Text)
+	}
+
+	tables, err := server.adapter.DescribeSchema(ctx, server.db, server.databaseName)
+	if err != nil {
+		t.Fatalf("DescribeSchema failed: %v", err)
+	}
+	var types []string
+	for _, table := range tables {
+		if table.Name == "orders" {
+			for _, column := range table.Columns {
+				types = append(types, column.Name+" "+column.Type)
+			}
+		}
+	}
+	if want := []string{"id INTEGER", "customer TEXT", "amount REAL", "note TEXT"}; !reflect.DeepEqual(types, want) {
+		t.Errorf("Expected sniffed columns %v, got %v", want, types)
+	}
+
+	resources, rpcErr := server.handleListResources(ctx)
+	if rpcErr != nil || len(resources.Resources) != 2 || !strings.Contains(resources.Resources[0].Description, "CSV file") {
+		t.Errorf("Expected both files listed as CSV tables, got %v %+v", rpcErr, resources)
+	}
+
+	for _, stmt := range []string{"DELETE FROM orders", "INSERT INTO orders (id) VALUES (4)"} {
+		if result, _ := server.executeQuery(ctx, map[string]any{"sql": stmt}); !result.IsError {
+			t.Errorf("Expected %q to be rejected", stmt)
+		}
+	}
+}
+
+func TestFlatFiles_Rejected(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := writeFlatFile(t, dir, "orders.csv", "id\n1\n")
+	cases := []struct {
+		files map[string]string
+		want  string
+	}{
+		{map[string]string{"orders-2024": csvPath}, "invalid table name"},
+		{map[string]string{"notes": writeFlatFile(t, dir, "notes.txt", "x\n")}, "not a .csv, .tsv or .parquet file"},
+		{map[string]string{"missing": filepath.Join(dir, "missing.csv")}, "cannot be opened"},
+		{map[string]string{"empty": writeFlatFile(t, dir, "empty.csv", "")}, "has no header row"},
+	}
+	for _, tc := range cases {
+		if _, _, err := createFlatFileCatalog(t.TempDir(), tc.files); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Expected %v to fail with %q, got %v", tc.files, tc.want, err)
+		}
+	}
+}
+
+func TestFlatFileModule_OnlyOpensConfiguredFiles(t *testing.T) {
+	if err := registerFlatFileModule(); err != nil {
+		t.Fatalf("Failed to register module: %v", err)
+	}
+	secret := writeFlatFile(t, t.TempDir(), "secret.csv", "token\nhunter2\n")
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "evil.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	_, err = db.Exec("CREATE VIRTUAL TABLE leak USING csvfile('" + secret + "')")
+	if err == nil || !strings.Contains(err.Error(), "not a file configured in MCP_FILES") {
+		t.Errorf("Expected an unlisted file to be refused, got %v", err)
+	}
+}
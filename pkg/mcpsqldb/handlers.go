@@ -0,0 +1,856 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *Server) handleInitialize(ctx context.Context, params json.RawMessage) (*InitializeResult, *Error) {
+	var initParams InitializeParams
+	if params != nil {
+		if err := json.Unmarshal(params, &initParams); err != nil {
+			return nil, &Error{
+				Code:    InvalidParams,
+				Message: "Invalid initialize parameters",
+				Data:    err.Error(),
+			}
+		}
+	}
+
+	s.initialized = true
+	s.client = initParams
+	loggerFrom(ctx).Info("Client initialized", "client", initParams.ClientInfo.Name,
+		"client_version", initParams.ClientInfo.Version, "capabilities", initParams.Capabilities.names())
+
+	return &InitializeResult{
+		ProtocolVersion: ProtocolVersion,
+		Capabilities: ServerCapabilities{
+			Tools:     &ToolsCapability{},
+			Resources: &ResourcesCapability{Subscribe: SubscriptionPollInterval > 0},
+		},
+		ServerInfo: ServerInfo{
+			Name:    s.adapter.ServerName(),
+			Version: ServerVersion,
+		},
+	}, nil
+}
+
+func (s *Server) handleListTools() (*ListToolsResult, *Error) {
+	result := &ListToolsResult{
+		Tools: []Tool{
+			{
+				Name:        "query",
+				Description: "Execute a read-only SQL query (SELECT, SHOW, DESCRIBE, EXPLAIN only)",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"sql": {
+							Type:        "string",
+							Description: "The SQL query to execute (SELECT, SHOW, DESCRIBE, or EXPLAIN)",
+						},
+						"save_as": {
+							Type: "string",
+							Description: "Save the result under this name for the rest of the session; later queries can read it " +
+								"like a table (it is added to them as a WITH clause, nothing is written to the database)",
+						},
+						"format": {
+							Type: "string",
+							Description: "Result format: json (default), or markdown or csv for a table to paste into documents, " +
+								"with columns in name order",
+						},
+						"number_locale": {
+							Type:        "string",
+							Description: "Locale to group digits and write decimal marks for in markdown and csv results, e.g. en, de or fr-CH",
+						},
+						"decimals": {
+							Type:        "integer",
+							Description: "Round fractional numbers to this many decimal places in markdown and csv results",
+						},
+						"include_total_count": {
+							Type: "boolean",
+							Description: "Also count the rows the query matches without its LIMIT/OFFSET, for paging through them " +
+								"(runs a SELECT COUNT(*) of the query)",
+						},
+						"params": queryParamsProperty,
+					},
+					Required: []string{"sql"},
+				},
+			},
+			{
+				Name:        "submit_query",
+				Description: "Start a long-running read-only SQL query in the background and return a job ID to poll with get_query_result",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"sql": {
+							Type:        "string",
+							Description: "The SQL query to execute (SELECT, SHOW, DESCRIBE, or EXPLAIN)",
+						},
+						"params": queryParamsProperty,
+					},
+					Required: []string{"sql"},
+				},
+			},
+			{
+				Name:        "get_query_result",
+				Description: "Get the status of a job started with submit_query, or its result once finished",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"job_id": {
+							Type:        "string",
+							Description: "The job ID returned by submit_query",
+						},
+					},
+					Required: []string{"job_id"},
+				},
+			},
+			{
+				Name:        "sample_rows",
+				Description: "Get a random sample of a table's rows; large tables are sampled natively (e.g. TABLESAMPLE) instead of being scanned",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"table": {
+							Type:        "string",
+							Description: "The table to sample",
+						},
+						"limit": {
+							Type:        "integer",
+							Description: fmt.Sprintf("Number of rows to return (default %d)", DefaultSampleRows),
+						},
+						"as_of": {
+							Type: "string",
+							Description: "Sample the rows as they were at this RFC 3339 timestamp, e.g. 2024-05-01T12:00:00Z " +
+								"(CockroachDB, and MariaDB system-versioned tables)",
+						},
+					},
+					Required: []string{"table"},
+				},
+			},
+			{
+				Name:        "compare_queries",
+				Description: "Run two read-only SQL queries and report the rows added, removed and changed in the second result, matching rows by key columns",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"before_sql": {
+							Type:        "string",
+							Description: "The query producing the baseline rows",
+						},
+						"after_sql": {
+							Type:        "string",
+							Description: "The query producing the rows to compare against the baseline",
+						},
+						"key_columns": {
+							Type:        "array",
+							Description: "Columns, present in both results, that identify a row",
+							Items:       &Property{Type: "string"},
+						},
+					},
+					Required: []string{"before_sql", "after_sql", "key_columns"},
+				},
+			},
+			{
+				Name:        "explain_index_usage",
+				Description: "Plan a SELECT without running it and report which indexes it uses, which tables and indexes it scans in full, and which indexes on the columns it mentions the planner ignored",
+				InputSchema: InputSchema{
+					Type: "object",
+					Properties: map[string]Property{
+						"sql": {
+							Type:        "string",
+							Description: "The SELECT query to analyze",
+						},
+					},
+					Required: []string{"sql"},
+				},
+			},
+			{
+				Name:        "database_settings",
+				Description: "Report the character sets, collations, time zone and schema search path in effect, and which collations each table's columns use, to explain surprising sorting and comparison results",
+				InputSchema: InputSchema{
+					Type:       "object",
+					Properties: map[string]Property{},
+					Required:   []string{},
+				},
+			},
+			viewLineageTool(),
+			listSequencesTool(),
+			sessionVariablesTool(),
+			aggregateTimeseriesTool(),
+			snapshotSchemaTool(),
+			schemaDiffTool(),
+			checksumRowsTool(),
+			columnHistogramTool(),
+			inferJSONSchemaTool(),
+			{
+				Name:        "summarize_schema",
+				Description: "Get a plain-text overview of every table: columns, primary keys, approximate row counts, comments and foreign key relationships",
+				InputSchema: InputSchema{
+					Type:       "object",
+					Properties: map[string]Property{},
+					Required:   []string{},
+				},
+			},
+			{
+				Name:        "server_status",
+				Description: "Report database connectivity and connection pool statistics",
+				InputSchema: InputSchema{
+					Type:       "object",
+					Properties: map[string]Property{},
+					Required:   []string{},
+				},
+			},
+		},
+	}
+	if len(s.sources) > 0 {
+		result.Tools = append(result.Tools, s.crossQueryTool())
+	}
+	if s.cache != nil {
+		result.Tools = append(result.Tools, resultCacheTools()...)
+	}
+	if QueryInsights {
+		result.Tools = append(result.Tools, queryInsightsTool())
+	}
+	if RunningQueries {
+		result.Tools = append(result.Tools, runningQueriesTool())
+	}
+	if len(ActiveSchemas) > 0 {
+		result.Tools = append(result.Tools, setActiveSchemaTool())
+	}
+	if _, ok := s.adapter.(TopQueryReader); ok && TopQueries {
+		result.Tools = append(result.Tools, topQueriesTool())
+	}
+	result.Tools = append(result.Tools, templateTools(result.Tools)...)
+	return result, nil
+}
+
+// handleCallTool dispatches a tool call. The returned context carries the
+// request logger enriched with the tool name.
+func (s *Server) handleCallTool(ctx context.Context, params json.RawMessage) (context.Context, *CallToolResult, *Error) {
+	var callParams CallToolParams
+	if err := json.Unmarshal(params, &callParams); err != nil {
+		return ctx, nil, &Error{
+			Code:    InvalidParams,
+			Message: "Invalid parameters",
+			Data:    err.Error(),
+		}
+	}
+
+	ctx = withLogger(ctx, loggerFrom(ctx).With("tool", callParams.Name))
+	if schema, ok := s.toolSchema(callParams.Name); ok {
+		if rpcErr := validateArguments(schema, callParams.Arguments); rpcErr != nil {
+			return ctx, nil, rpcErr
+		}
+	}
+	result, rpcErr := s.callTool(ctx, callParams.Name, callParams.Arguments)
+	return ctx, result, rpcErr
+}
+
+func (s *Server) callTool(ctx context.Context, name string, args map[string]any) (*CallToolResult, *Error) {
+	switch name {
+	case "query":
+		return s.executeQuery(ctx, args)
+	case "submit_query":
+		return s.submitQuery(ctx, args)
+	case "get_query_result":
+		return s.getQueryResult(args)
+	case "server_status":
+		return s.serverStatus(ctx)
+	case "sample_rows":
+		return s.sampleRows(ctx, args)
+	case "compare_queries":
+		return s.compareQueries(ctx, args)
+	case "aggregate_timeseries":
+		return s.aggregateTimeseries(ctx, args)
+	case "summarize_schema":
+		return s.summarizeSchema(ctx)
+	case "snapshot_schema":
+		return s.snapshotSchema(ctx, args)
+	case "schema_diff":
+		return s.schemaDiff(ctx, args)
+	case "checksum_rows":
+		return s.checksumRows(ctx, args)
+	case "column_histogram":
+		return s.columnHistogram(ctx, args)
+	case "infer_json_schema":
+		return s.inferJSONSchema(ctx, args)
+	case "explain_index_usage":
+		return s.explainIndexUsage(ctx, args)
+	case "database_settings":
+		return s.databaseSettings(ctx)
+	case "view_lineage":
+		return s.viewLineage(ctx, args)
+	case "list_sequences":
+		return s.listSequences(ctx)
+	case "session_variables":
+		return s.sessionVariables(ctx, args)
+	case "cross_query":
+		return s.crossQuery(ctx, args)
+	case "cache_result":
+		return s.cacheResult(ctx, args)
+	case "query_cache":
+		return s.queryCache(ctx, args)
+	case "query_insights":
+		return s.queryInsights(ctx, args)
+	case "top_queries":
+		return s.topQueries(ctx, args)
+	case "running_queries":
+		return s.runningQueries(ctx)
+	case "set_active_schema":
+		return s.setActiveSchema(ctx, args)
+	default:
+		if t := findQueryTemplate(name); t != nil {
+			return s.runQueryTemplate(ctx, t, args)
+		}
+		return nil, &Error{
+			Code:    MethodNotFound,
+			Message: fmt.Sprintf("Unknown tool: %s", name),
+		}
+	}
+}
+
+func (s *Server) executeQuery(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	sqlQuery, ok := args["sql"].(string)
+	if !ok || sqlQuery == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'sql' parameter",
+		}
+	}
+	saveAs, _ := args["save_as"].(string)
+	if _, ok := args["save_as"]; ok && !crossNamePattern.MatchString(saveAs) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Invalid 'save_as' parameter: expected a plain identifier",
+		}
+	}
+	format, nf, rpcErr := outputOptions(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	includeTotal, _ := args["include_total_count"].(bool)
+	params, rpcErr := queryParams(s.adapter, args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	normalized := s.adapter.NormalizeRowLimit(sqlQuery)
+	rewritten := normalized != sqlQuery
+
+	// Validate query is read-only, touches no denied tables and has a
+	// placeholder for each param
+	validated, err := s.validateQuery(sqlQuery)
+	if err == nil {
+		err = s.checkParams(validated, params)
+	}
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error(), params...)
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	sqlQuery = validated
+
+	// Expensive queries may need the user's confirmation first
+	if result := s.checkCost(ctx, sqlQuery, params...); result != nil {
+		return result, nil
+	}
+
+	// Execute query with timeout
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	result := s.runQuery(ctx, sqlQuery, params...)
+	if rewritten && !result.IsError {
+		withWarning(result, WarningKindRowLimitRewritten, "The row limit clause was rewritten into the database's own syntax: "+normalized)
+	}
+	if saveAs != "" && !result.IsError {
+		if err := s.saved.save(saveAs, result.Content[0].Text); err != nil {
+			result.Content = append(result.Content, Content{Type: "text", Text: fmt.Sprintf("Result not saved as %s: %v", saveAs, err)})
+		}
+	}
+	if includeTotal && !result.IsError {
+		s.addTotalCount(ctx, sqlQuery, result, params...)
+	}
+	// A plan is a tree, not rows, and is always returned as JSON
+	if _, plan := explainTarget(sqlQuery); format != OutputFormatJSON && !plan && !result.IsError {
+		text, warning, err := renderRows(result.Content[0].Text, format, nf)
+		if err != nil {
+			return &CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to format results: %v", err)}},
+				IsError: true,
+			}, nil
+		}
+		content := []Content{{Type: "text", Text: text}}
+		if warning != "" {
+			content = append(content, Content{Type: "text", Text: "Warning: " + warning})
+		}
+		result.Content = append(content, result.Content[1:]...)
+	}
+	return result, nil
+}
+
+// runQuery executes an already validated query under ctx, with params bound
+// to its placeholders, and formats the rows (or the failure) as a tool
+// result.
+func (s *Server) runQuery(ctx context.Context, sqlQuery string, params ...any) (result *CallToolResult) {
+	start := time.Now()
+	rowCount := 0
+	outcome := AuditOutcomeRejected
+	defer func() {
+		detail := ""
+		if result.IsError {
+			detail = result.Content[0].Text
+		}
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, outcome, rowCount, time.Since(start), detail, params...)
+	}()
+
+	if err := s.quota.allow(time.Now()); err != nil {
+		stats.queriesRejected.Add(1)
+		loggerFrom(ctx).Warn("Query quota exceeded", "error", err)
+		return quotaExceeded(err)
+	}
+
+	queuedAt := time.Now()
+	err := s.workers.acquire(ctx)
+	wait := time.Since(queuedAt)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		return withQueueWait(withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), wait)
+	}
+
+	started := time.Now()
+	var set *rowSet
+	func() {
+		defer s.workers.release()
+		if target, ok := explainTarget(sqlQuery); ok {
+			result, rowCount = s.fetchPlan(ctx, target)
+		} else {
+			set, result = s.fetchRows(ctx, sqlQuery, params...)
+		}
+	}()
+	elapsed := time.Since(started)
+	if set != nil {
+		// Counted once the worker is released, as the count is planned and
+		// run on a worker of its own
+		if set.truncatedAt > 0 && CountTruncatedRows {
+			s.countTruncated(ctx, sqlQuery, set, params...)
+		}
+		result, rowCount = set.result()
+	}
+	if s.logIfSlow(ctx, sqlQuery, elapsed) && !result.IsError {
+		withWarning(result, WarningKindSlowQuery, fmt.Sprintf("The query took %s, over the slow query threshold of %s",
+			elapsed.Round(time.Millisecond), SlowQueryThreshold))
+	}
+	withQueueWait(result, wait)
+	stats.recordResult(result)
+	outcome = AuditOutcomeError
+	if !result.IsError {
+		outcome = AuditOutcomeOK
+		s.quota.record(rowCount, len(result.Content[0].Text))
+	}
+	return result
+}
+
+// fetchRows runs sqlQuery with params and reads its rows, returning the tool
+// error to report when it fails.
+func (s *Server) fetchRows(ctx context.Context, sqlQuery string, params ...any) (*rowSet, *CallToolResult) {
+	rows, done, err := s.beginQuery(ctx, sqlQuery, params...)
+	if err != nil {
+		return nil, withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err))
+	}
+	defer done()
+	defer rows.Close()
+	return s.readRows(ctx, rows, sqlQuery)
+}
+
+// formatRows reads the rows of sqlQuery into a JSON tool result, also
+// returning the number of rows in it.
+func (s *Server) formatRows(ctx context.Context, rows *sql.Rows, sqlQuery string) (*CallToolResult, int) {
+	set, failed := s.readRows(ctx, rows, sqlQuery)
+	if failed != nil {
+		return failed, 0
+	}
+	return set.result()
+}
+
+// rowSet holds the rows read for a query result before they are formatted.
+type rowSet struct {
+	rows    []map[string]any
+	omitted *columnsOmitted
+	masked  []string
+
+	// truncatedAt is the row limit the rows were cut at, or 0 when complete
+	truncatedAt int
+	limitedBy   string
+	// total is the query's full row count, when it was counted
+	total   int64
+	counted bool
+	// uncounted says why the total is unknown, when the count was refused
+	uncounted string
+}
+
+// readRows reads the rows of sqlQuery up to its row limit, returning the
+// tool error to report when they cannot be read.
+func (s *Server) readRows(ctx context.Context, rows *sql.Rows, sqlQuery string) (*rowSet, *CallToolResult) {
+	// Get column names
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to get columns: %v", err)}},
+			IsError: true,
+		}
+	}
+	types := columnTypeNames(rows)
+
+	// Source tables are unknown for ad-hoc queries; masking matches on column name
+	masks := columnMasks(MaskRules, "", columns)
+	shown, omitted := s.cappedColumns(sqlQuery, columns)
+	maxRows, limitedBy := queryMaxRows(s.adapter.RemoveStringsAndComments(sqlQuery))
+	set := &rowSet{omitted: omitted, limitedBy: limitedBy}
+	for i, mask := range masks[:min(shown, len(masks))] {
+		if mask != "" {
+			set.masked = append(set.masked, columns[i])
+		}
+	}
+
+	// Fetch rows with limit, tracking approximate memory used by the result
+	resultBytes := 0
+	for rows.Next() {
+		if len(set.rows) >= maxRows {
+			set.truncatedAt = maxRows
+			break
+		}
+
+		values := make([]any, len(columns))
+		valuePtrs := make([]any, len(columns))
+		for i := range values {
+			valuePtrs[i] = &values[i]
+		}
+
+		if err := rows.Scan(valuePtrs...); err != nil {
+			return nil, &CallToolResult{
+				Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to scan row %d: %v", len(set.rows)+1, err)}},
+				IsError: true,
+			}
+		}
+
+		row := make(map[string]any)
+		for i, col := range columns[:shown] {
+			val := values[i]
+			// Convert []byte to string for JSON serialization, repairing
+			// legacy non-UTF-8 text, then decode database-specific types
+			row[col] = s.adapter.DecodeValue(types[i], textValue(s.charset, val))
+			if masks != nil {
+				row[col] = maskValue(masks[i], row[col])
+			}
+			resultBytes += len(col) + estimateValueSize(val)
+		}
+
+		if resultBytes > MaxResultBytes {
+			return nil, resultTooLarge(len(set.rows) + 1)
+		}
+
+		set.rows = append(set.rows, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Row iteration error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err))
+	}
+	return set, nil
+}
+
+// result formats the rows as a JSON tool result, with the truncation warning
+// and omitted columns notice as rows of their own, also returning the number
+// of rows in it.
+func (set *rowSet) result() (*CallToolResult, int) {
+	results := set.rows
+	meta := &ResultMeta{}
+	if set.truncatedAt > 0 {
+		warning := fmt.Sprintf("Result truncated at %d rows", set.truncatedAt)
+		if set.counted {
+			warning = fmt.Sprintf("Result truncated at %d of %d rows", set.truncatedAt, set.total)
+		}
+		if set.limitedBy != "" {
+			warning += fmt.Sprintf(" (the row limit for %s)", set.limitedBy)
+		}
+		if set.uncounted != "" {
+			warning += fmt.Sprintf("; total unknown, %s", set.uncounted)
+		}
+		results = append(results, map[string]any{"_warning": warning})
+		meta.Truncation = &TruncationInfo{Shown: set.truncatedAt, Counted: set.counted, TotalRows: set.total}
+		meta.Warnings = append(meta.Warnings, ResultWarning{Kind: WarningKindTruncated, Message: warning})
+	}
+	if set.omitted != nil {
+		results = append(results, map[string]any{"_columns_omitted": set.omitted})
+		meta.Warnings = append(meta.Warnings, ResultWarning{Kind: WarningKindColumnsOmitted, Message: set.omitted.Message})
+	}
+	if len(meta.Warnings) == 0 {
+		meta = nil
+	}
+
+	// Format result as JSON
+	resultJSON, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, 0
+	}
+
+	result := &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(resultJSON)}},
+		Meta:    meta,
+	}
+	if len(set.masked) > 0 {
+		withWarning(result, WarningKindColumnsMasked, "Values of masked columns were replaced: "+strings.Join(set.masked, ", "))
+	}
+	return result, len(set.rows)
+}
+
+func (s *Server) handleListResources(ctx context.Context) (*ListResourcesResult, *Error) {
+	if s.databaseName == "" {
+		return &ListResourcesResult{Resources: append([]Resource{}, s.jobResources()...)}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	// Read before the listing, which holds a connection until it is done
+	var description string
+	if ic := s.identifierCase(ctx); ic != nil {
+		description = ic.Note
+	}
+	hypertables := s.hypertables(ctx)
+	virtualTables := s.virtualTableNotes(ctx)
+	tidbTables := s.tidbTableNotes(ctx)
+	query, args := s.adapter.ListTablesQuery(s.databaseName)
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to list tables: %v", err), err)
+	}
+	defer rows.Close()
+
+	scheme := s.adapter.URIScheme()
+	var resources []Resource
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			loggerFrom(ctx).Warn("Failed to scan table name", "error", err)
+			continue
+		}
+		if isTableDenied(DeniedTables, tableName) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+			continue
+		}
+		tableDescription := description
+		if h := hypertables[tableName]; h != nil {
+			tableDescription = strings.TrimSpace(h.describe() + "\n" + description)
+		} else if note := virtualTables[tableName]; note != "" {
+			tableDescription = strings.TrimSpace(note + "\n" + description)
+		} else if note := tidbTables[tableName]; note != "" {
+			tableDescription = strings.TrimSpace(note + "\n" + description)
+		}
+		resources = append(resources, Resource{
+			URI:         fmt.Sprintf("%s://%s/%s/schema", scheme, s.databaseName, tableName),
+			Name:        fmt.Sprintf("Schema for table '%s'", tableName),
+			Description: tableDescription,
+			MimeType:    "application/json",
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Error iterating tables: %v", err), err)
+	}
+
+	resources = append(resources, s.extensionsResources()...)
+	resources = append(resources, s.jobResources()...)
+
+	return &ListResourcesResult{Resources: resources}, nil
+}
+
+func (s *Server) handleReadResource(ctx context.Context, params json.RawMessage) (*ReadResourceResult, *Error) {
+	var readParams ReadResourceParams
+	if err := json.Unmarshal(params, &readParams); err != nil {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Invalid parameters",
+			Data:    err.Error(),
+		}
+	}
+
+	uri := readParams.URI
+	if result, rpcErr, ok := s.readJobResource(uri); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.readExtensionsResource(ctx, uri); ok {
+		return result, rpcErr
+	}
+	if result, rpcErr, ok := s.readStatsResource(ctx, uri); ok {
+		return result, rpcErr
+	}
+
+	// Parse URI: scheme://dbname/tablename/schema
+	prefix := s.adapter.URIScheme() + "://"
+
+	if !strings.HasPrefix(uri, prefix) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Invalid resource URI: must start with %s", prefix),
+		}
+	}
+
+	parts := strings.Split(strings.TrimPrefix(uri, prefix), "/")
+	if len(parts) < 3 || parts[2] != "schema" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Invalid resource URI format: expected %sdbname/tablename/schema", prefix),
+		}
+	}
+
+	dbName := parts[0]
+	tableName := parts[1]
+
+	// Answer as if the table did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, tableName) || isSchemaDenied(DeniedSchemas, dbName) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Table not found: %s", tableName),
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	query, queryArgs := s.adapter.ReadSchemaQuery(dbName, tableName)
+	rows, err := s.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to get schema: %v", err), err)
+	}
+	defer rows.Close()
+
+	var columns []map[string]any
+	for rows.Next() {
+		col, err := s.adapter.ScanSchemaRow(rows)
+		if err != nil {
+			loggerFrom(ctx).Warn("Failed to scan column info", "table", tableName, "error", err)
+			continue
+		}
+		columns = append(columns, col)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Error reading schema: %v", err), err)
+	}
+	rows.Close()
+	if dbName == s.databaseName {
+		if h := s.hypertables(ctx)[tableName]; h != nil {
+			h.annotateColumns(columns)
+		}
+	}
+
+	schemaJSON, err := json.MarshalIndent(columns, "", "  ")
+	if err != nil {
+		return nil, &Error{
+			Code:    InternalError,
+			Message: fmt.Sprintf("Failed to marshal schema: %v", err),
+		}
+	}
+
+	return &ReadResourceResult{
+		Contents: []ResourceContent{
+			{
+				URI:      uri,
+				MimeType: "application/json",
+				Text:     string(schemaJSON),
+			},
+		},
+	}, nil
+}
+
+// logIfSlow logs queries that ran longer than SlowQueryThreshold at warn
+// level, with string literals and comments stripped from the SQL, and
+// reports whether it did.
+func (s *Server) logIfSlow(ctx context.Context, sqlQuery string, elapsed time.Duration) bool {
+	if SlowQueryThreshold <= 0 || elapsed < SlowQueryThreshold {
+		return false
+	}
+	stats.slowQueries.Add(1)
+	digest := queryDigest(s.adapter, sqlQuery)
+	loggerFrom(ctx).Warn("Slow query",
+		"duration_ms", elapsed.Milliseconds(),
+		"threshold_ms", SlowQueryThreshold.Milliseconds(),
+		"sql", digest,
+		"fingerprint", queryFingerprint(digest))
+	return true
+}
+
+// estimateValueSize approximates the memory a scanned value occupies once
+// converted for JSON serialization.
+func estimateValueSize(val any) int {
+	switch v := val.(type) {
+	case nil:
+		return 0
+	case []byte:
+		return len(v)
+	case string:
+		return len(v)
+	case time.Time:
+		return 24
+	default:
+		return 8
+	}
+}
+
+// columnTypeNames returns the database type name of each result column, or
+// empty names when the driver does not report them.
+func columnTypeNames(rows *sql.Rows) []string {
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		columns, _ := rows.Columns()
+		return make([]string, len(columns))
+	}
+	names := make([]string, len(columnTypes))
+	for i, ct := range columnTypes {
+		names[i] = ct.DatabaseTypeName()
+	}
+	return names
+}
+
+// resultTooLarge builds the error returned when a result exceeds MaxResultBytes.
+func resultTooLarge(rowsScanned int) *CallToolResult {
+	payload, _ := json.Marshal(map[string]any{
+		"error":        "result_too_large",
+		"message":      fmt.Sprintf("Result exceeded %d bytes; narrow the column list or add a LIMIT", MaxResultBytes),
+		"limit_bytes":  MaxResultBytes,
+		"rows_scanned": rowsScanned,
+	})
+	return withErrorKind(&CallToolResult{
+		Content: []Content{{Type: "text", Text: string(payload)}},
+		IsError: true,
+	}, ErrorKindTooLarge)
+}
@@ -0,0 +1,181 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestDB creates a SQLite database file with a small users table, runs
+// any extra setup statements, and returns its path.
+func newTestDB(t testing.TB, extra ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open setup database: %v", err)
+	}
+	defer setup.Close()
+
+	stmts := append([]string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"INSERT INTO users (name) VALUES ('alice'), ('bob'), ('carol')",
+	}, extra...)
+	for _, stmt := range stmts {
+		if _, err := setup.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed database: %v", err)
+		}
+	}
+	return path
+}
+
+// newTestServer creates a read-only Server over a fresh test database.
+func newTestServer(t testing.TB, extra ...string) *Server {
+	t.Helper()
+	return openTestServer(t, newTestDB(t, extra...))
+}
+
+func openTestServer(t testing.TB, path string) *Server {
+	t.Helper()
+	server, err := newServer(context.Background(), &SQLiteAdapter{}, path+"?mode=ro")
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// callToolJSON calls the tool name with args and decodes its JSON result,
+// failing the test if the call fails.
+func callToolJSON[T any](t *testing.T, server *Server, name string, args map[string]any) T {
+	t.Helper()
+	result, rpcErr := server.callTool(context.Background(), name, args)
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected %s to succeed, got %v %+v", name, rpcErr, result)
+	}
+	var report T
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("Failed to parse %s result: %v", name, err)
+	}
+	return report
+}
+
+func TestExecuteQuery_ReturnsRows(t *testing.T) {
+	server := newTestServer(t)
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id, name FROM users ORDER BY id"})
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %s", result.Content[0].Text)
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	if rows[0]["name"] != "alice" {
+		t.Errorf("Expected first row name 'alice', got %v", rows[0]["name"])
+	}
+}
+
+func TestServerStatus_ReportsPool(t *testing.T) {
+	server := newTestServer(t)
+
+	result, rpcErr := server.serverStatus(context.Background())
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+
+	var status map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &status); err != nil {
+		t.Fatalf("Failed to parse status: %v", err)
+	}
+	if status["connected"] != true {
+		t.Errorf("Expected connected=true, got %v", status["connected"])
+	}
+	if _, ok := status["pool"].(map[string]any); !ok {
+		t.Errorf("Expected pool statistics, got %v", status["pool"])
+	}
+}
+
+func TestExecuteQuery_ResultTooLarge(t *testing.T) {
+	server := newTestServer(t)
+
+	original := MaxResultBytes
+	MaxResultBytes = 16
+	defer func() { MaxResultBytes = original }()
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id, name FROM users"})
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if !result.IsError {
+		t.Fatal("Expected result to be rejected as too large")
+	}
+
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &payload); err != nil {
+		t.Fatalf("Expected structured error, got %q", result.Content[0].Text)
+	}
+	if payload["error"] != "result_too_large" {
+		t.Errorf("Expected result_too_large, got %v", payload["error"])
+	}
+}
+
+func TestExecuteQuery_LogsSlowQueriesRedacted(t *testing.T) {
+	server := newTestServer(t)
+
+	originalThreshold := SlowQueryThreshold
+	SlowQueryThreshold = time.Nanosecond
+	defer func() { SlowQueryThreshold = originalThreshold }()
+
+	var buf bytes.Buffer
+	originalLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(originalLogger)
+
+	if _, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id FROM users WHERE name = 'secret-name'"}); rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+
+	if got := stats.slowQueries.Load(); got < 1 {
+		t.Errorf("Expected slow query to be counted, got %d", got)
+	}
+	if !strings.Contains(buf.String(), "Slow query") {
+		t.Errorf("Expected slow query log, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "secret-name") {
+		t.Errorf("Expected string literal to be redacted, got %q", buf.String())
+	}
+}
+
+func TestExecuteQuery_CountsOutcomes(t *testing.T) {
+	server := newTestServer(t)
+	before := stats.snapshot()
+
+	server.executeQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users"})
+	server.executeQuery(context.Background(), map[string]any{"sql": "DROP TABLE users"})
+	server.executeQuery(context.Background(), map[string]any{"sql": "SELECT missing_column FROM users"})
+
+	after := stats.snapshot()
+	for key, want := range map[string]int64{"queries_ok": 1, "queries_rejected": 1, "queries_errored": 1} {
+		if got := after[key].(int64) - before[key].(int64); got != want {
+			t.Errorf("Expected %s to increase by %d, got %d", key, want, got)
+		}
+	}
+	if after["bytes_returned"].(int64) <= before["bytes_returned"].(int64) {
+		t.Error("Expected bytes_returned to increase")
+	}
+}
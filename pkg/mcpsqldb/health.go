@@ -0,0 +1,220 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// QueryRetries is overridable via MCP_QUERY_RETRIES env var
+var QueryRetries = 2
+
+// Reconnect backoff: delays double from ReconnectBaseDelay up to ReconnectMaxDelay
+const (
+	ReconnectAttempts  = 5
+	ReconnectBaseDelay = 200 * time.Millisecond
+	ReconnectMaxDelay  = 5 * time.Second
+	RetryBaseDelay     = 100 * time.Millisecond
+)
+
+// connectionErrorMarkers are driver error fragments that indicate a dead
+// connection (server restart, failover, network drop) rather than a bad query.
+var connectionErrorMarkers = []string{
+	"bad connection",
+	"invalid connection",
+	"broken pipe",
+	"connection reset",
+	"connection refused",
+	"server closed the connection",
+	"unexpected eof",
+	"use of closed network connection",
+}
+
+// isConnectionError reports whether err indicates the database connection
+// was lost and the operation may succeed on a fresh connection.
+func isConnectionError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, marker := range connectionErrorMarkers {
+		if strings.Contains(msg, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// reconnect pings the database with exponential backoff until the pool hands
+// out a healthy connection. Dead connections are discarded by database/sql
+// when the driver reports them, so a successful ping means the pool recovered.
+func (s *Server) reconnect(ctx context.Context) error {
+	delay := ReconnectBaseDelay
+	var err error
+	for attempt := 1; attempt <= ReconnectAttempts; attempt++ {
+		pingCtx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+		err = s.db.PingContext(pingCtx)
+		cancel()
+		if err == nil {
+			stats.reconnects.Add(1)
+			loggerFrom(ctx).Info("Reconnected to database", "attempts", attempt)
+			return nil
+		}
+
+		loggerFrom(ctx).Warn("Reconnect attempt failed", "attempt", attempt, "max_attempts", ReconnectAttempts, "error", err)
+		if attempt == ReconnectAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, ReconnectMaxDelay)
+	}
+	return fmt.Errorf("database unreachable after %d attempts: %w", ReconnectAttempts, err)
+}
+
+// beginQuery runs sqlQuery in a read-only transaction: its own, or the
+// session's snapshot with SnapshotSession. The returned func ends the query
+// and must be called once the rows are closed. Dead connections trigger a
+// reconnect and transient errors a short backoff, each followed by a retry,
+// up to QueryRetries times, so momentary replica hiccups do not surface as
+// tool errors.
+func (s *Server) beginQuery(ctx context.Context, sqlQuery string, args ...any) (*sql.Rows, func(), error) {
+	delay := RetryBaseDelay
+	for attempt := 0; ; attempt++ {
+		var rows *sql.Rows
+		var done func()
+		var err error
+		if s.snapshot != nil {
+			rows, done, err = s.querySnapshot(ctx, sqlQuery, args...)
+		} else {
+			rows, done, err = s.queryReadOnly(ctx, sqlQuery, args...)
+		}
+		if err == nil || attempt >= QueryRetries {
+			return rows, done, err
+		}
+
+		switch {
+		case isConnectionError(err):
+			loggerFrom(ctx).Warn("Lost database connection", "error", err)
+			if rerr := s.reconnect(ctx); rerr != nil {
+				return nil, nil, rerr
+			}
+		case s.adapter.IsTransientError(err):
+			loggerFrom(ctx).Warn("Transient error, retrying", "retry", attempt+1, "max_retries", QueryRetries, "error", err)
+			select {
+			case <-ctx.Done():
+				return nil, nil, ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		default:
+			return nil, nil, err
+		}
+	}
+}
+
+// queryReadOnly runs sqlQuery in a READ ONLY transaction of its own, as
+// defense-in-depth beyond validation and session settings; the returned
+// func rolls it back.
+func (s *Server) queryReadOnly(ctx context.Context, sqlQuery string, args ...any) (*sql.Rows, func(), error) {
+	opts := &sql.TxOptions{ReadOnly: true}
+	if optioner, ok := s.adapter.(ReadOnlyTxOptioner); ok {
+		opts = optioner.ReadOnlyTxOptions()
+	}
+	tx, err := s.db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin read-only transaction: %w", err)
+	}
+
+	if err := s.applyActiveSchema(ctx, tx); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	rows, err := tx.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+	return rows, func() { tx.Rollback() }, nil
+}
+
+// serverStatus reports connectivity and connection pool statistics.
+func (s *Server) serverStatus(ctx context.Context) (*CallToolResult, *Error) {
+	ctx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer cancel()
+
+	status := map[string]any{
+		"server":   s.adapter.ServerName(),
+		"version":  ServerVersion,
+		"driver":   s.adapter.DriverName(),
+		"database": s.databaseName,
+		"session":  s.sessionID,
+	}
+
+	if err := s.db.PingContext(ctx); err != nil {
+		status["connected"] = false
+		status["error"] = redactSecrets(err.Error())
+	} else {
+		status["connected"] = true
+	}
+
+	pool := s.db.Stats()
+	status["pool"] = map[string]any{
+		"max_open_connections": pool.MaxOpenConnections,
+		"open_connections":     pool.OpenConnections,
+		"in_use":               pool.InUse,
+		"idle":                 pool.Idle,
+		"wait_count":           pool.WaitCount,
+		"wait_duration_ms":     pool.WaitDuration.Milliseconds(),
+		"max_idle_closed":      pool.MaxIdleClosed,
+		"max_idle_time_closed": pool.MaxIdleTimeClosed,
+		"max_lifetime_closed":  pool.MaxLifetimeClosed,
+	}
+
+	if ic := s.identifierCase(ctx); ic != nil {
+		status["identifiers"] = ic
+	}
+	status["saved_results"] = s.saved.list()
+	status["workers"] = s.workers.stats()
+	status["quota"] = s.quota.snapshot()
+	if active := s.activeSchema.Load(); active != nil {
+		status["active_schema"] = active.name
+	}
+	if s.initialized {
+		status["client"] = map[string]any{
+			"name":             s.client.ClientInfo.Name,
+			"version":          s.client.ClientInfo.Version,
+			"protocol_version": s.client.ProtocolVersion,
+			"capabilities":     s.client.Capabilities.names(),
+		}
+	}
+	if s.snapshot != nil {
+		status["snapshot"] = s.snapshot.status(time.Now())
+	}
+	status["stats"] = stats.snapshot()
+
+	statusJSON, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal status: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(statusJSON)}},
+	}, nil
+}
@@ -0,0 +1,67 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{nil, false},
+		{driver.ErrBadConn, true},
+		{fmt.Errorf("wrapped: %w", driver.ErrBadConn), true},
+		{errors.New("invalid connection"), true},
+		{errors.New("write tcp 127.0.0.1:3306: write: broken pipe"), true},
+		{errors.New("read: connection reset by peer"), true},
+		{errors.New("pq: server closed the connection unexpectedly"), true},
+		{errors.New("no such table: users"), false},
+		{errors.New("syntax error at or near \"FORM\""), false},
+	}
+
+	for _, tc := range tests {
+		name := "nil"
+		if tc.err != nil {
+			name = tc.err.Error()
+		}
+		t.Run(name, func(t *testing.T) {
+			if got := isConnectionError(tc.err); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// txOptionsAdapter begins queries with its own transaction options, as an
+// adapter whose driver refuses read-only transactions would.
+type txOptionsAdapter struct {
+	*SQLiteAdapter
+	calls int
+}
+
+func (a *txOptionsAdapter) ReadOnlyTxOptions() *sql.TxOptions {
+	a.calls++
+	return nil
+}
+
+func TestQueryReadOnly_AdapterTxOptions(t *testing.T) {
+	server := newTestServer(t)
+	adapter := &txOptionsAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	server.adapter = adapter
+
+	rows, done, err := server.queryReadOnly(context.Background(), "SELECT name FROM users")
+	if err != nil {
+		t.Fatalf("Expected the query to run, got %v", err)
+	}
+	rows.Close()
+	done()
+	if adapter.calls != 1 {
+		t.Errorf("Expected the adapter's transaction options to be used once, got %d", adapter.calls)
+	}
+}
@@ -0,0 +1,97 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// runHealthcheck connects to the database with the same read-only settings
+// the server uses and runs a trivial query. It returns the process exit code
+// (0 healthy, 1 unhealthy) so it can back Docker HEALTHCHECK and Kubernetes
+// exec probes.
+func runHealthcheck(ctx context.Context, adapter DBAdapter, dsn string) int {
+	if err := checkDatabase(ctx, adapter, dsn); err != nil {
+		fmt.Println(redactSecrets(fmt.Sprintf("unhealthy: %v", err)))
+		return 1
+	}
+	fmt.Println("healthy")
+	return 0
+}
+
+func checkDatabase(ctx context.Context, adapter DBAdapter, dsn string) error {
+	db, err := openReadOnlyDB(adapter, dsn, connectionLabel("healthcheck"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	var one int
+	if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("probe query failed: %w", err)
+	}
+	return nil
+}
+
+// runHTTPHealthcheck asks the http transport instance listening on listen
+// for its health, which includes its database connection, and returns the
+// process exit code as runHealthcheck does.
+func runHTTPHealthcheck(ctx context.Context, listen string) int {
+	url, err := healthURL(listen)
+	if err == nil {
+		err = checkHTTPHealth(ctx, url)
+	}
+	if err != nil {
+		fmt.Println(redactSecrets(fmt.Sprintf("unhealthy: %v", err)))
+		return 1
+	}
+	fmt.Println("healthy")
+	return 0
+}
+
+// healthURL returns the URL of the health endpoint of an instance listening
+// on listen, reached over loopback when it listens on every interface.
+func healthURL(listen string) (string, error) {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", fmt.Errorf("invalid listen address %q: %w", listen, err)
+	}
+	switch host {
+	case "", "0.0.0.0":
+		host = "127.0.0.1"
+	case "::":
+		host = "::1"
+	}
+	return "http://" + net.JoinHostPort(host, port) + httpHealthPath, nil
+}
+
+// checkHTTPHealth gets url and fails unless it answers 200 OK.
+func checkHTTPHealth(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s answered %d: %s", url, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package mcpsqldb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckDatabase_Healthy(t *testing.T) {
+	path := newTestDB(t)
+	if err := checkDatabase(context.Background(), &SQLiteAdapter{}, path+"?mode=ro"); err != nil {
+		t.Errorf("Expected healthy database, got %v", err)
+	}
+}
+
+func TestCheckDatabase_Unreachable(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "no-such-dir", "missing.db")
+	if err := checkDatabase(context.Background(), &SQLiteAdapter{}, missing+"?mode=ro"); err == nil {
+		t.Error("Expected missing database to be reported unhealthy")
+	}
+}
+
+func TestHealthURL(t *testing.T) {
+	tests := map[string]string{
+		":8080":          "http://127.0.0.1:8080/healthz",
+		"0.0.0.0:8080":   "http://127.0.0.1:8080/healthz",
+		"[::]:8080":      "http://[::1]:8080/healthz",
+		"10.0.0.5:9000":  "http://10.0.0.5:9000/healthz",
+		"localhost:8080": "http://localhost:8080/healthz",
+	}
+	for listen, want := range tests {
+		if got, err := healthURL(listen); err != nil || got != want {
+			t.Errorf("healthURL(%q) = %q, %v; expected %q", listen, got, err, want)
+		}
+	}
+	if _, err := healthURL("8080"); err == nil {
+		t.Error("Expected a listen address without a port to be rejected")
+	}
+}
+
+func TestCheckHTTPHealth(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	if err := checkHTTPHealth(context.Background(), ts.URL+httpHealthPath); err != nil {
+		t.Errorf("Expected the instance to be healthy, got %v", err)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unhealthy: database is down", http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	if err := checkHTTPHealth(context.Background(), failing.URL+httpHealthPath); err == nil || !strings.Contains(err.Error(), "503") || !strings.Contains(err.Error(), "database is down") {
+		t.Errorf("Expected the failing instance to be reported, got %v", err)
+	}
+
+	failing.Close()
+	if err := checkHTTPHealth(context.Background(), failing.URL+httpHealthPath); err == nil {
+		t.Error("Expected an instance that is not running to be reported")
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		args        []string
+		wantCommand string
+		wantArgs    int
+	}{
+		{nil, CommandServe, 0},
+		{[]string{"user:pw@tcp(db:3306)/app"}, CommandServe, 1},
+		{[]string{"healthcheck"}, CommandHealthcheck, 0},
+		{[]string{"healthcheck", "/data/app.db"}, CommandHealthcheck, 1},
+		{[]string{"repl"}, CommandREPL, 0},
+		{[]string{"test-connection", "/data/app.db"}, CommandTestConn, 1},
+	}
+
+	for _, tc := range tests {
+		command, args := parseCommand(tc.args)
+		if command != tc.wantCommand || len(args) != tc.wantArgs {
+			t.Errorf("parseCommand(%v) = %q, %v", tc.args, command, args)
+		}
+	}
+}
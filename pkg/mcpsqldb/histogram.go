@@ -0,0 +1,277 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultHistogramBuckets is how many buckets column_histogram splits a
+	// column's range into unless asked
+	DefaultHistogramBuckets = 10
+
+	// maxHistogramBuckets caps the buckets argument
+	maxHistogramBuckets = 100
+)
+
+var (
+	// numericColumnType matches the numeric types of every supported
+	// database, e.g. integer, bigint unsigned, decimal(10,2), double precision
+	numericColumnType = regexp.MustCompile(`^(?:u?(?:tiny|small|medium|big|huge)?int(?:eger)?\d*|decimal|numeric|number|real|float\d*|double|money|(?:small|big)?serial)\b`)
+
+	// temporalColumnType matches date and timestamp types, but not time of
+	// day or intervals, which have no place on a calendar
+	temporalColumnType = regexp.MustCompile(`^(?:date|datetime\d?|timestamp|timestamptz|smalldatetime)\b`)
+)
+
+// histogramBucket counts the values from From up to To; the last bucket
+// includes To.
+type histogramBucket struct {
+	From  any   `json:"from"`
+	To    any   `json:"to"`
+	Count int64 `json:"count"`
+}
+
+// histogramReport is the column_histogram result. Bounds of date and
+// timestamp columns are RFC 3339 timestamps in UTC.
+type histogramReport struct {
+	Table  string `json:"table"`
+	Column string `json:"column"`
+	// Values counts the column's non-NULL values
+	Values  int64             `json:"values"`
+	Min     any               `json:"min"`
+	Max     any               `json:"max"`
+	Buckets []histogramBucket `json:"buckets"`
+}
+
+// columnHistogramTool describes column_histogram.
+func columnHistogramTool() Tool {
+	return Tool{
+		Name: "column_histogram",
+		Description: "Report the minimum, maximum and a histogram of a numeric, date or timestamp column: " +
+			"its range split into equal-width buckets with the number of values in each, computed in one aggregate query",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"table": {
+					Type:        "string",
+					Description: "The table holding the column",
+				},
+				"column": {
+					Type:        "string",
+					Description: "The numeric, date or timestamp column",
+				},
+				"buckets": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Number of equal-width buckets (default %d, at most %d)", DefaultHistogramBuckets, maxHistogramBuckets),
+				},
+			},
+			Required: []string{"table", "column"},
+		},
+	}
+}
+
+// columnHistogram buckets a column's values in the database, so only the
+// counts are read back, through the same validation, cost guardrail and
+// audit as the query tool.
+func (s *Server) columnHistogram(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	table, _ := args["table"].(string)
+	column, _ := args["column"].(string)
+	switch {
+	case table == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'table' parameter"}
+	case column == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'column' parameter"}
+	}
+	buckets, rpcErr := intArg(args, "buckets", DefaultHistogramBuckets, 1, math.MaxInt)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	buckets = min(buckets, maxHistogramBuckets)
+
+	// Answer as if the table did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Table not found: %s", table)}},
+			IsError: true,
+		}, nil
+	}
+	// The distribution of masked values is as private as the values
+	if masks := columnMasks(MaskRules, table, []string{column}); masks != nil && masks[0] != "" {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Column %s is masked; its distribution cannot be reported", column)}},
+			IsError: true,
+		}, nil
+	}
+
+	columns, err := s.tableColumns(ctx, s.resolveName(table))
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to get schema: %v", err), err)
+	}
+	if len(columns) == 0 {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Table not found: %s", table)}},
+			IsError: true,
+		}, nil
+	}
+	name, dataType, ok := histogramColumn(columns, s.resolveName(column))
+	if !ok {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Column not found in %s: %s", table, column)}},
+			IsError: true,
+		}, nil
+	}
+	dataType = strings.ToLower(strings.TrimSpace(dataType))
+	temporal := temporalColumnType.MatchString(dataType)
+	if !temporal && !numericColumnType.MatchString(dataType) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Column %s is %s; only numeric, date and timestamp columns have a histogram", column, dataType)}},
+			IsError: true,
+		}, nil
+	}
+
+	value := s.adapter.QuoteIdentifier(name)
+	if temporal {
+		value = s.adapter.EpochSeconds(value)
+	}
+	sqlQuery := histogramQuery(s.quoteName(table), value, buckets)
+
+	// The generated query is checked like any other, as defense in depth
+	validated, err := s.validateQuery(sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	if result := s.checkCost(ctx, validated); result != nil {
+		return result, nil
+	}
+	if qerr := s.quota.allow(time.Now()); qerr != nil {
+		stats.queriesRejected.Add(1)
+		return quotaExceeded(qerr), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	start := time.Now()
+	report, err := s.histogram(ctx, validated, buckets, temporal)
+	if err != nil {
+		s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeError, 0, time.Since(start), err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeOK, len(report.Buckets), time.Since(start), "")
+	report.Table, report.Column = table, column
+	return statementReportResult(report)
+}
+
+// histogramColumn returns the name and type of the column spelled name.
+func histogramColumn(columns []map[string]any, name string) (string, string, bool) {
+	names := make([]string, 0, len(columns))
+	for _, col := range columns {
+		n, _ := col["column_name"].(string)
+		names = append(names, n)
+	}
+	match, ok := matchColumnName(names, name)
+	if !ok {
+		return "", "", false
+	}
+	for _, col := range columns {
+		if col["column_name"] == match {
+			dataType, _ := col["data_type"].(string)
+			return match, dataType, true
+		}
+	}
+	return "", "", false
+}
+
+// histogramQuery returns SQL counting the non-NULL values of the numeric
+// expression value in table per equal-width bucket, numbered from 0, with
+// the range they split. The maximum falls in the last bucket, and a column
+// holding a single value in the first.
+func histogramQuery(table, value string, buckets int) string {
+	return fmt.Sprintf(`SELECT r.lo, r.hi,
+	CASE WHEN r.hi = r.lo THEN 0 WHEN d.v >= r.hi THEN %[3]d
+		ELSE FLOOR((d.v - r.lo) * %[4]d.0 / (r.hi - r.lo)) END AS bucket,
+	COUNT(*) AS count
+FROM (SELECT %[2]s AS v FROM %[1]s WHERE %[2]s IS NOT NULL) d
+CROSS JOIN (SELECT MIN(%[2]s) AS lo, MAX(%[2]s) AS hi FROM %[1]s) r
+GROUP BY 1, 2, 3
+ORDER BY 3`, table, value, buckets-1, buckets)
+}
+
+// histogram runs the validated histogramQuery and builds the report, with
+// every bucket listed, empty or not.
+func (s *Server) histogram(ctx context.Context, sqlQuery string, buckets int, temporal bool) (*histogramReport, error) {
+	rows, done, err := s.beginQuery(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	report := &histogramReport{Buckets: []histogramBucket{}}
+	counts := make([]int64, buckets)
+	var lo, hi float64
+	for rows.Next() {
+		var rangeLo, rangeHi, bucket sql.NullFloat64
+		var count int64
+		if err := rows.Scan(&rangeLo, &rangeHi, &bucket, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan bucket: %w", err)
+		}
+		if !bucket.Valid {
+			continue
+		}
+		lo, hi = rangeLo.Float64, rangeHi.Float64
+		i := max(0, min(int(bucket.Float64), buckets-1))
+		counts[i] += count
+		report.Values += count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if report.Values == 0 {
+		return report, nil
+	}
+
+	bound := func(v float64) any {
+		if temporal {
+			sec := int64(v)
+			return time.Unix(sec, int64((v-float64(sec))*1e9)).UTC().Format(time.RFC3339)
+		}
+		return v
+	}
+	report.Min, report.Max = bound(lo), bound(hi)
+	if hi == lo {
+		buckets = 1
+	}
+	width := (hi - lo) / float64(buckets)
+	for i := 0; i < buckets; i++ {
+		to := hi
+		if i < buckets-1 {
+			to = lo + width*float64(i+1)
+		}
+		report.Buckets = append(report.Buckets, histogramBucket{
+			From:  bound(lo + width*float64(i)),
+			To:    bound(to),
+			Count: counts[i],
+		})
+	}
+	return report, nil
+}
@@ -0,0 +1,158 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestEpochSeconds(t *testing.T) {
+	tests := []struct {
+		adapter DBAdapter
+		want    string
+	}{
+		{&MySQLAdapter{}, "TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', `ts`)"},
+		{&PostgresAdapter{}, `EXTRACT(EPOCH FROM "ts")`},
+		{&SQLiteAdapter{}, `unixepoch("ts")`},
+	}
+	for _, tc := range tests {
+		if got := tc.adapter.EpochSeconds(tc.adapter.QuoteIdentifier("ts")); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.adapter.DriverName(), tc.want, got)
+		}
+	}
+}
+
+func newHistogramServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, amount REAL, placed_at TIMESTAMP, note TEXT)",
+		`INSERT INTO orders (amount, placed_at, note) VALUES
+			(0, '2024-01-01 00:00:00', 'a'),
+			(5, '2024-01-03 00:00:00', 'b'),
+			(9.5, '2024-01-04 12:00:00', 'c'),
+			(25, '2024-01-05 00:00:00', 'd'),
+			(100, '2024-01-11 00:00:00', 'e'),
+			(NULL, NULL, 'f')`)
+}
+
+// bucketCounts returns the count of each of report's buckets.
+func bucketCounts(report histogramReport) []int64 {
+	var counts []int64
+	for _, b := range report.Buckets {
+		counts = append(counts, b.Count)
+	}
+	return counts
+}
+
+func TestColumnHistogram_Numeric(t *testing.T) {
+	server := newHistogramServer(t)
+
+	report := callToolJSON[histogramReport](t, server, "column_histogram", map[string]any{"table": "orders", "column": "amount", "buckets": float64(4)})
+	if report.Values != 5 || report.Min != 0.0 || report.Max != 100.0 {
+		t.Errorf("Expected 5 values from 0 to 100, got %+v", report)
+	}
+	// The maximum falls in the last bucket
+	if got := bucketCounts(report); !reflect.DeepEqual(got, []int64{3, 1, 0, 1}) {
+		t.Errorf("Expected counts [3 1 0 1], got %v", got)
+	}
+	if b := report.Buckets[1]; b.From != 25.0 || b.To != 50.0 {
+		t.Errorf("Expected the second bucket to span 25 to 50, got %+v", b)
+	}
+
+	report = callToolJSON[histogramReport](t, server, "column_histogram", map[string]any{"table": "orders", "column": "AMOUNT"})
+	if len(report.Buckets) != DefaultHistogramBuckets || report.Buckets[0].Count != 3 {
+		t.Errorf("Expected %d buckets by default, got %+v", DefaultHistogramBuckets, report.Buckets)
+	}
+}
+
+func TestColumnHistogram_Timestamp(t *testing.T) {
+	server := newHistogramServer(t)
+
+	report := callToolJSON[histogramReport](t, server, "column_histogram", map[string]any{"table": "orders", "column": "placed_at", "buckets": float64(5)})
+	if report.Min != "2024-01-01T00:00:00Z" || report.Max != "2024-01-11T00:00:00Z" {
+		t.Errorf("Expected the range as UTC timestamps, got %v to %v", report.Min, report.Max)
+	}
+	if got := bucketCounts(report); !reflect.DeepEqual(got, []int64{1, 2, 1, 0, 1}) {
+		t.Errorf("Expected counts [1 2 1 0 1], got %v", got)
+	}
+	if b := report.Buckets[1]; b.From != "2024-01-03T00:00:00Z" || b.To != "2024-01-05T00:00:00Z" {
+		t.Errorf("Expected two-day buckets, got %+v", b)
+	}
+}
+
+func TestColumnHistogram_SingleValue(t *testing.T) {
+	server := newTestServer(t)
+
+	report := callToolJSON[histogramReport](t, server, "column_histogram", map[string]any{"table": "users", "column": "id", "buckets": float64(3)})
+	if report.Values != 3 || len(report.Buckets) != 3 {
+		t.Fatalf("Expected 3 ids in 3 buckets, got %+v", report)
+	}
+
+	single := newTestServer(t, "DELETE FROM users WHERE id > 1")
+	report = callToolJSON[histogramReport](t, single, "column_histogram", map[string]any{"table": "users", "column": "id"})
+	if len(report.Buckets) != 1 || report.Buckets[0].Count != 1 || report.Min != report.Max {
+		t.Errorf("Expected a single value in one bucket, got %+v", report)
+	}
+
+	empty := newTestServer(t, "DELETE FROM users")
+	report = callToolJSON[histogramReport](t, empty, "column_histogram", map[string]any{"table": "users", "column": "id"})
+	if report.Values != 0 || len(report.Buckets) != 0 || report.Min != nil {
+		t.Errorf("Expected no buckets for an empty table, got %+v", report)
+	}
+}
+
+func TestColumnHistogram_Refused(t *testing.T) {
+	server := newHistogramServer(t)
+	ctx := context.Background()
+
+	defer func(tables []string, rules []maskRule) { DeniedTables, MaskRules = tables, rules }(DeniedTables, MaskRules)
+	cases := []struct {
+		args map[string]any
+		want string
+	}{
+		{map[string]any{"table": "orders", "column": "note"}, "Column note is text; only numeric, date and timestamp columns have a histogram"},
+		{map[string]any{"table": "orders", "column": "missing"}, "Column not found in orders: missing"},
+		{map[string]any{"table": "missing", "column": "amount"}, "Table not found: missing"},
+	}
+	for _, tc := range cases {
+		result, _ := server.callTool(ctx, "column_histogram", tc.args)
+		if !result.IsError || result.Content[0].Text != tc.want {
+			t.Errorf("Expected %v to fail with %q, got %+v", tc.args, tc.want, result)
+		}
+	}
+
+	MaskRules, _ = parseMaskRules("orders.amount=hash")
+	result, _ := server.callTool(ctx, "column_histogram", map[string]any{"table": "orders", "column": "amount"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "is masked") {
+		t.Errorf("Expected a masked column to be refused, got %+v", result)
+	}
+
+	DeniedTables = []string{"orders"}
+	result, _ = server.callTool(ctx, "column_histogram", map[string]any{"table": "orders", "column": "placed_at"})
+	if !result.IsError || result.Content[0].Text != "Table not found: orders" {
+		t.Errorf("Expected the denied table to be reported as missing, got %+v", result)
+	}
+
+	if _, rpcErr := server.callTool(ctx, "column_histogram", map[string]any{"table": "orders", "column": "amount", "buckets": float64(0)}); rpcErr == nil {
+		t.Error("Expected an invalid bucket count to be rejected")
+	}
+}
+
+func TestNumericColumnType(t *testing.T) {
+	for _, typ := range []string{"integer", "bigint unsigned", "decimal(10,2)", "double precision", "int8", "numeric", "float"} {
+		if !numericColumnType.MatchString(typ) {
+			t.Errorf("Expected %s to be numeric", typ)
+		}
+	}
+	for _, typ := range []string{"interval", "point", "text", "time without time zone"} {
+		if numericColumnType.MatchString(typ) || temporalColumnType.MatchString(typ) {
+			t.Errorf("Expected %s to have no histogram", typ)
+		}
+	}
+	for _, typ := range []string{"date", "datetime", "timestamp with time zone", "timestamptz"} {
+		if !temporalColumnType.MatchString(typ) {
+			t.Errorf("Expected %s to be temporal", typ)
+		}
+	}
+}
@@ -0,0 +1,529 @@
+package mcpsqldb
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Transports accepted by --transport and MCP_TRANSPORT
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// Command line flags selecting the transport
+const (
+	TransportFlag = "--transport"
+	ListenFlag    = "--listen"
+)
+
+// Paths served by the http transport
+const (
+	HTTPEndpoint          = "/mcp"
+	httpHealthPath        = "/healthz"
+	protectedResourcePath = "/.well-known/oauth-protected-resource"
+)
+
+// sessionHeader carries the session ID assigned on initialize, which the
+// client sends with every later request.
+const sessionHeader = "Mcp-Session-Id"
+
+// Transport is how clients connect: stdio serves one session on stdin and
+// stdout, http serves MCP Streamable HTTP sessions, and those of the legacy
+// HTTP+SSE transport, on HTTPListen (overridable via MCP_TRANSPORT env var
+// or --transport)
+var Transport = TransportStdio
+
+// HTTPListen is the address the http transport listens on (overridable via
+// MCP_HTTP_LISTEN env var or --listen)
+var HTTPListen = "127.0.0.1:8080"
+
+// HTTPPublicURL is the URL clients reach the server at, e.g.
+// https://mcp.example.com; with auth enabled it is advertised as the
+// protected resource so clients can find the authorization server
+// (overridable via MCP_HTTP_PUBLIC_URL env var)
+var HTTPPublicURL = ""
+
+// HTTPAllowedOrigins are the browser origins allowed to call the http
+// transport; requests from any other Origin are refused (overridable via
+// MCP_HTTP_ALLOWED_ORIGINS env var, comma-separated)
+var HTTPAllowedOrigins []string
+
+// HTTPMaxSessions caps the open sessions of the http transport (overridable
+// via MCP_HTTP_MAX_SESSIONS env var)
+var HTTPMaxSessions = 100
+
+// parseTransportFlags removes TransportFlag and ListenFlag from args,
+// written as --flag=value or --flag value, and applies them to Transport
+// and HTTPListen.
+func parseTransportFlags(args []string) ([]string, error) {
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(args[i], "=")
+		if name != TransportFlag && name != ListenFlag {
+			rest = append(rest, args[i])
+			continue
+		}
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, fmt.Errorf("%s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case TransportFlag:
+			if value != TransportStdio && value != TransportHTTP {
+				return nil, fmt.Errorf("unknown transport %q (supported: %s, %s)", value, TransportStdio, TransportHTTP)
+			}
+			Transport = value
+		case ListenFlag:
+			HTTPListen = value
+		}
+	}
+	return rest, nil
+}
+
+// ListenAndServe serves MCP Streamable HTTP, and the legacy HTTP+SSE
+// transport, on addr until ctx is cancelled or the server is shut down, returning the context's error then. Each
+// client that initializes gets a session of its own (see newSession) over
+// the server's database connections. Requests are authenticated and rate
+// limited as configured by AuthTokens, OIDCIssuer and RateLimitQPS.
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	handler, authenticated, err := s.httpHandler()
+	if err != nil {
+		return err
+	}
+	if !authenticated && !isLoopbackAddr(addr) {
+		slog.Warn("HTTP transport is reachable from other hosts without authentication; set MCP_AUTH_TOKENS or MCP_OIDC_ISSUER",
+			"listen", addr)
+	}
+
+	srv := &http.Server{Addr: addr, Handler: handler, ReadHeaderTimeout: ConnectionTimeout}
+	stop := context.AfterFunc(ctx, s.Shutdown)
+	defer stop()
+	go func() {
+		<-s.ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), ConnectionTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("HTTP transport listening", "url", "http://"+addr+HTTPEndpoint, "sse_url", "http://"+addr+SSEEndpoint)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		s.Shutdown()
+		return err
+	}
+	return s.ctx.Err()
+}
+
+// isLoopbackAddr reports whether addr listens on the loopback interface
+// only.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// httpHandler returns the handler of the http transport and whether it
+// authenticates requests. Sessions idle past IdleTimeout are closed in the
+// background until the server shuts down.
+func (s *Server) httpHandler() (http.Handler, bool, error) {
+	publicURL := strings.TrimSuffix(HTTPPublicURL, "/")
+	metadataURL := ""
+	if publicURL != "" {
+		metadataURL = publicURL + protectedResourcePath
+	}
+	auth, err := newAuthenticator(AuthTokens, OIDCIssuer, OIDCAudience, metadataURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	t := &httpTransport{base: s, sessions: map[string]*httpSession{}}
+	limiter := newRateLimiter(RateLimitQPS, RateLimitBurst)
+	protect := func(endpoint http.Handler) http.Handler {
+		// Inside auth, so callers are limited by identity
+		if limiter != nil {
+			endpoint = limiter.middleware(endpoint)
+		}
+		if auth != nil {
+			endpoint = auth.middleware(endpoint)
+		}
+		return checkOrigin(endpoint)
+	}
+
+	mux := http.NewServeMux()
+	if auth != nil && publicURL != "" {
+		mux.Handle("GET "+protectedResourcePath, auth.protectedResourceMetadata(publicURL+HTTPEndpoint))
+	}
+	mux.Handle(HTTPEndpoint, protect(t))
+	mux.Handle("GET "+SSEEndpoint, protect(http.HandlerFunc(t.legacyStream)))
+	mux.Handle("POST "+SSEMessagesPath, protect(http.HandlerFunc(t.legacyPost)))
+	// Probes carry no credentials, and the answer reveals no data
+	mux.HandleFunc("GET "+httpHealthPath, t.health)
+
+	go t.closeIdleSessions()
+	return mux, auth != nil, nil
+}
+
+// checkOrigin refuses requests from browser origins not listed in
+// HTTPAllowedOrigins, so a web page cannot reach a server on the user's
+// machine, e.g. by DNS rebinding. Clients other than browsers send no
+// Origin.
+func checkOrigin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if origin := r.Header.Get("Origin"); origin != "" && !slices.Contains(HTTPAllowedOrigins, origin) {
+			loggerFrom(r.Context()).Warn("Refused HTTP request from another origin", "origin", origin, "remote", r.RemoteAddr)
+			http.Error(w, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// httpTransport serves the sessions of the MCP Streamable HTTP transport.
+// Clients POST each JSON-RPC message and get its response as the reply,
+// GET a server-sent event stream for notifications, and DELETE their
+// session when done.
+type httpTransport struct {
+	base *Server
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession is one client session of the http transport.
+type httpSession struct {
+	server *Server
+	// principal is the authenticated caller that opened the session, the
+	// only one it answers
+	principal string
+	// mu serializes the session's messages, which the stdio transport
+	// handles one at a time
+	mu sync.Mutex
+	// lastActive is when the client last sent a message or was last
+	// answered, in Unix nanoseconds
+	lastActive atomic.Int64
+	// inflight counts the messages being handled; a busy session is never
+	// idle, however long its queries run
+	inflight atomic.Int32
+	// streaming is set while the client holds the event stream open
+	streaming atomic.Bool
+	// events carries the responses of a legacy HTTP+SSE session to its
+	// event stream; it is nil for Streamable HTTP sessions
+	events chan []byte
+}
+
+func (t *httpTransport) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		t.post(w, r)
+	case http.MethodGet:
+		t.stream(w, r)
+	case http.MethodDelete:
+		if session := t.lookup(w, r); session != nil {
+			t.close(session, "Client ended session")
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// post handles one JSON-RPC message. An initialize without a session ID
+// opens a session; every other message must name an open one.
+func (t *httpTransport) post(w http.ResponseWriter, r *http.Request) {
+	data, ok := readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	var msg struct {
+		Method string `json:"method"`
+	}
+	json.Unmarshal(data, &msg)
+
+	var session *httpSession
+	opened := false
+	if msg.Method == "initialize" && r.Header.Get(sessionHeader) == "" {
+		if session = t.open(principalFrom(r.Context()), false); session == nil {
+			writeHTTPError(w, http.StatusServiceUnavailable, InternalError, fmt.Sprintf("Too many sessions (at most %d)", HTTPMaxSessions))
+			return
+		}
+		opened = true
+	} else if session = t.lookup(w, r); session == nil {
+		return
+	}
+	server := session.server
+	response := session.handle(data)
+
+	if opened && (response == nil || response.Error != nil) {
+		t.close(session, "Initialize failed")
+		opened = false
+	}
+	if response == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	if opened {
+		w.Header().Set(sessionHeader, server.sessionID)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	server.writeMessage(w, response)
+}
+
+// readMessage reads the JSON-RPC message in the request body, or writes the
+// error and returns false when it cannot be read or exceeds MaxRequestBytes.
+func readMessage(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, int64(MaxRequestBytes)))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeHTTPError(w, http.StatusRequestEntityTooLarge, InvalidRequest, fmt.Sprintf("Request exceeds %d bytes", MaxRequestBytes))
+			return nil, false
+		}
+		http.Error(w, "failed to read request", http.StatusBadRequest)
+		return nil, false
+	}
+	return data, true
+}
+
+// handle handles one message of the session's client, after those it sent
+// before, and returns the response, if any.
+func (session *httpSession) handle(data []byte) *JSONRPCResponse {
+	session.inflight.Add(1)
+	defer func() {
+		// The idle timeout counts from the response, as the stdio
+		// transport's does
+		session.lastActive.Store(time.Now().UnixNano())
+		session.inflight.Add(-1)
+	}()
+	session.lastActive.Store(time.Now().UnixNano())
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	if session.server.wire != nil {
+		session.server.wire.dump(WireInbound, data)
+	}
+	return session.server.handleMessage(data)
+}
+
+// stream holds a server-sent event stream open for the session's resource
+// update notifications. Keepalive comments are written every
+// KeepaliveInterval; a failed write ends the stream, not the session.
+func (t *httpTransport) stream(w http.ResponseWriter, r *http.Request) {
+	session := t.lookup(w, r)
+	if session == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	if !session.streaming.CompareAndSwap(false, true) {
+		http.Error(w, "the session already has an event stream open", http.StatusConflict)
+		return
+	}
+	defer func() {
+		// The client was connected until now
+		session.lastActive.Store(time.Now().UnixNano())
+		session.streaming.Store(false)
+	}()
+
+	events := openEventStream(w, flusher)
+	ticker, pings := newKeepaliveTicker()
+	if ticker != nil {
+		defer ticker.Stop()
+	}
+	serveEvents(r.Context(), session, events, pings)
+}
+
+// openEventStream answers the request with an event stream and returns
+// its writer.
+func openEventStream(w http.ResponseWriter, flusher http.Flusher) *sseWriter {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+// serveEvents writes the session's events to its stream until ctx ends, the
+// session ends or a write fails: the responses of a legacy session,
+// resource update notifications, and a keepalive comment on each tick of
+// pings.
+func serveEvents(ctx context.Context, session *httpSession, events *sseWriter, pings <-chan time.Time) {
+	server := session.server
+	pollTicker, polls := newSubscriptionTicker()
+	if pollTicker != nil {
+		defer pollTicker.Stop()
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-server.ctx.Done():
+			return
+		case msg := <-session.events:
+			if _, err := events.Write(msg); err != nil {
+				return
+			}
+		case <-pings:
+			if _, err := io.WriteString(events.w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			events.flusher.Flush()
+		case <-polls:
+			session.mu.Lock()
+			err := server.pollSubscriptions(server.ctx, events)
+			session.mu.Unlock()
+			if err != nil {
+				loggerFrom(server.ctx).Info("Event stream closed", "session_id", server.sessionID, "error", err)
+				return
+			}
+		}
+	}
+}
+
+// sseWriter writes each JSON-RPC message written to it, one line per
+// Write as writeMessage produces them, as a server-sent event.
+type sseWriter struct {
+	w       io.Writer
+	flusher http.Flusher
+}
+
+func (e *sseWriter) Write(p []byte) (int, error) {
+	if _, err := fmt.Fprintf(e.w, "event: message\ndata: %s\n\n", strings.TrimRight(string(p), "\n")); err != nil {
+		return 0, err
+	}
+	e.flusher.Flush()
+	return len(p), nil
+}
+
+// open starts a session for principal, of the legacy HTTP+SSE transport if
+// legacy is set, or returns nil when HTTPMaxSessions are open.
+func (t *httpTransport) open(principal string, legacy bool) *httpSession {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.sessions) >= HTTPMaxSessions {
+		return nil
+	}
+	// The session ID is all that stands between callers without auth, so
+	// it is long and random
+	session := &httpSession{server: t.base.newSession(rand.Text()), principal: principal}
+	if legacy {
+		session.events = make(chan []byte, sseEventBuffer)
+	}
+	session.lastActive.Store(time.Now().UnixNano())
+	t.sessions[session.server.sessionID] = session
+	// Sessions share the base server's pool, whose connections are labeled
+	// with its session ID rather than theirs
+	slog.Info("HTTP session opened", "session_id", session.server.sessionID, "sessions", len(t.sessions), "connection_label", connectionLabel(t.base.sessionID))
+	return session
+}
+
+// lookup returns the session named by the request's session header, or
+// writes the error and returns nil when there is none. A session is only
+// found by the caller that opened it.
+func (t *httpTransport) lookup(w http.ResponseWriter, r *http.Request) *httpSession {
+	id := r.Header.Get(sessionHeader)
+	if id == "" {
+		writeHTTPError(w, http.StatusBadRequest, InvalidRequest, "Missing "+sessionHeader+" header; send initialize first")
+		return nil
+	}
+	return t.find(w, r, id)
+}
+
+// find returns the session with the given id, or writes the error and
+// returns nil when the caller has none by that id.
+func (t *httpTransport) find(w http.ResponseWriter, r *http.Request, id string) *httpSession {
+	t.mu.Lock()
+	session := t.sessions[id]
+	t.mu.Unlock()
+	if session == nil || session.principal != principalFrom(r.Context()) {
+		writeHTTPError(w, http.StatusNotFound, InvalidRequest, "Session not found; send initialize to start a new one")
+		return nil
+	}
+	return session
+}
+
+// close ends session, cancelling its queries and jobs and rolling back its
+// snapshot.
+func (t *httpTransport) close(session *httpSession, reason string) {
+	t.mu.Lock()
+	delete(t.sessions, session.server.sessionID)
+	remaining := len(t.sessions)
+	t.mu.Unlock()
+	session.server.Shutdown()
+	slog.Info("HTTP session closed", "session_id", session.server.sessionID, "reason", reason, "sessions", remaining)
+}
+
+// closeIdleSessions closes sessions that sent nothing for IdleTimeout, are
+// handling no message and hold no event stream open, until the server shuts
+// down.
+func (t *httpTransport) closeIdleSessions() {
+	if IdleTimeout <= 0 {
+		return
+	}
+	ticker := time.NewTicker(max(IdleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.base.ctx.Done():
+			return
+		case now := <-ticker.C:
+			t.mu.Lock()
+			var idle []*httpSession
+			for _, session := range t.sessions {
+				if !session.streaming.Load() && session.inflight.Load() == 0 && now.Sub(time.Unix(0, session.lastActive.Load())) >= IdleTimeout {
+					idle = append(idle, session)
+				}
+			}
+			t.mu.Unlock()
+			for _, session := range idle {
+				t.close(session, "idle timeout")
+			}
+		}
+	}
+}
+
+// health answers liveness probes by pinging the database.
+func (t *httpTransport) health(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), ConnectionTimeout)
+	defer cancel()
+	if err := t.base.db.PingContext(ctx); err != nil {
+		http.Error(w, redactSecrets(fmt.Sprintf("unhealthy: %v", err)), http.StatusServiceUnavailable)
+		return
+	}
+	fmt.Fprintln(w, "healthy")
+}
+
+// writeHTTPError answers a request the transport refused with status and a
+// JSON-RPC error.
+func writeHTTPError(w http.ResponseWriter, status, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(JSONRPCResponse{
+		JSONRPC: "2.0",
+		Error:   &Error{Code: code, Message: message},
+	})
+}
@@ -0,0 +1,294 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const initializeMessage = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test"}}}`
+
+// newHTTPTestServer serves a test database over the http transport.
+func newHTTPTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := newTestServer(t)
+	handler, _, err := server.httpHandler()
+	if err != nil {
+		t.Fatalf("Failed to create HTTP handler: %v", err)
+	}
+	ts := httptest.NewServer(handler)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// postMessage POSTs a JSON-RPC message to ts in session, with the headers
+// in header, and returns the response with its body read.
+func postMessage(t *testing.T, ts *httptest.Server, session, message string, header ...string) (*http.Response, string) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, ts.URL+HTTPEndpoint, strings.NewReader(message))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if session != "" {
+		req.Header.Set(sessionHeader, session)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp, string(body)
+}
+
+// initializeSession opens a session on ts and returns its ID.
+func initializeSession(t *testing.T, ts *httptest.Server, header ...string) string {
+	t.Helper()
+	resp, body := postMessage(t, ts, "", initializeMessage, header...)
+	session := resp.Header.Get(sessionHeader)
+	if resp.StatusCode != http.StatusOK || session == "" || !strings.Contains(body, `"protocolVersion"`) {
+		t.Fatalf("Expected initialize to open a session, got %d %q %s", resp.StatusCode, session, body)
+	}
+	return session
+}
+
+func TestParseTransportFlags(t *testing.T) {
+	defer func(transport, listen string) { Transport, HTTPListen = transport, listen }(Transport, HTTPListen)
+
+	rest, err := parseTransportFlags([]string{"--transport=http", "--listen", ":9090", "user:pw@/db"})
+	if err != nil || Transport != TransportHTTP || HTTPListen != ":9090" || !reflect.DeepEqual(rest, []string{"user:pw@/db"}) {
+		t.Errorf("Expected http on :9090 with the DSN left, got %s %s %v %v", Transport, HTTPListen, rest, err)
+	}
+	for _, args := range [][]string{{"--transport=sse"}, {"--listen"}} {
+		if _, err := parseTransportFlags(args); err == nil {
+			t.Errorf("Expected %v to be rejected", args)
+		}
+	}
+}
+
+func TestHTTPTransport_Session(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	session := initializeSession(t, ts)
+
+	resp, _ := postMessage(t, ts, session, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected a notification to be accepted, got %d", resp.StatusCode)
+	}
+
+	resp, body := postMessage(t, ts, session, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT name FROM users ORDER BY id"}}}`)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/json" || !strings.Contains(body, "alice") {
+		t.Errorf("Expected the query result, got %d %s", resp.StatusCode, body)
+	}
+
+	resp, _ = postMessage(t, ts, "", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a request without a session to fail, got %d", resp.StatusCode)
+	}
+	resp, _ = postMessage(t, ts, "unknown", `{"jsonrpc":"2.0","id":3,"method":"tools/list"}`)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected an unknown session to be not found, got %d", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, ts.URL+HTTPEndpoint, nil)
+	req.Header.Set(sessionHeader, session)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("Expected the session to be deleted, got %v %v", resp, err)
+	}
+	resp, _ = postMessage(t, ts, session, `{"jsonrpc":"2.0","id":4,"method":"ping"}`)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the deleted session to be gone, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransport_SessionsAreIndependent(t *testing.T) {
+	defer func(orig int) { QuotaQueriesPerMinute = orig }(QuotaQueriesPerMinute)
+	QuotaQueriesPerMinute = 1
+	ts := newHTTPTestServer(t)
+	first, second := initializeSession(t, ts), initializeSession(t, ts)
+	if first == second {
+		t.Fatal("Expected each initialize to open its own session")
+	}
+
+	query := `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT 1"}}}`
+	postMessage(t, ts, first, query)
+	if _, body := postMessage(t, ts, first, query); !strings.Contains(body, "quota_exceeded") {
+		t.Errorf("Expected the first session's quota to be spent, got %s", body)
+	}
+	if _, body := postMessage(t, ts, second, query); strings.Contains(body, "quota_exceeded") {
+		t.Errorf("Expected the second session to have its own quota, got %s", body)
+	}
+}
+
+func TestHTTPTransport_Auth(t *testing.T) {
+	defer func(tokens []string) { AuthTokens = tokens }(AuthTokens)
+	AuthTokens = []string{"token-one", "token-two"}
+	ts := newHTTPTestServer(t)
+
+	if resp, _ := postMessage(t, ts, "", initializeMessage); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a request without a token to be refused, got %d", resp.StatusCode)
+	}
+	session := initializeSession(t, ts, "Authorization", "Bearer token-one")
+	ping := `{"jsonrpc":"2.0","id":2,"method":"ping"}`
+	if resp, _ := postMessage(t, ts, session, ping, "Authorization", "Bearer token-one"); resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the session's caller to be answered, got %d", resp.StatusCode)
+	}
+	if resp, _ := postMessage(t, ts, session, ping, "Authorization", "Bearer token-two"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected another caller not to find the session, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransport_RateLimit(t *testing.T) {
+	defer func(qps float64, burst int) { RateLimitQPS, RateLimitBurst = qps, burst }(RateLimitQPS, RateLimitBurst)
+	RateLimitQPS, RateLimitBurst = 0.01, 1
+	ts := newHTTPTestServer(t)
+
+	session := initializeSession(t, ts)
+	resp, body := postMessage(t, ts, session, `{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+	if resp.StatusCode != http.StatusTooManyRequests || !strings.Contains(body, ErrorKindRateLimited) {
+		t.Errorf("Expected the second request to be rate limited, got %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestHTTPTransport_Refused(t *testing.T) {
+	defer func(origins []string, maxBytes int) { HTTPAllowedOrigins, MaxRequestBytes = origins, maxBytes }(HTTPAllowedOrigins, MaxRequestBytes)
+	HTTPAllowedOrigins = []string{"https://app.example.com"}
+	ts := newHTTPTestServer(t)
+
+	if resp, _ := postMessage(t, ts, "", initializeMessage, "Origin", "http://evil.example.com"); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected another origin to be refused, got %d", resp.StatusCode)
+	}
+	initializeSession(t, ts, "Origin", "https://app.example.com")
+
+	MaxRequestBytes = 64
+	resp, body := postMessage(t, ts, "", initializeMessage)
+	if resp.StatusCode != http.StatusRequestEntityTooLarge || !strings.Contains(body, "Request exceeds 64 bytes") {
+		t.Errorf("Expected an oversized request to be refused, got %d %s", resp.StatusCode, body)
+	}
+
+	req, _ := http.NewRequest(http.MethodPut, ts.URL+HTTPEndpoint, nil)
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected PUT to be refused, got %v %v", resp, err)
+	}
+}
+
+func TestHTTPTransport_MaxSessions(t *testing.T) {
+	defer func(orig int) { HTTPMaxSessions = orig }(HTTPMaxSessions)
+	HTTPMaxSessions = 1
+	ts := newHTTPTestServer(t)
+
+	initializeSession(t, ts)
+	if resp, _ := postMessage(t, ts, "", initializeMessage); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a second session to be refused, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransport_IdleTimeout(t *testing.T) {
+	defer func(orig time.Duration) { IdleTimeout = orig }(IdleTimeout)
+	IdleTimeout = 50 * time.Millisecond
+	ts := newHTTPTestServer(t)
+
+	session := initializeSession(t, ts)
+	time.Sleep(200 * time.Millisecond)
+	if resp, _ := postMessage(t, ts, session, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected the idle session to be closed, got %d", resp.StatusCode)
+	}
+}
+
+func TestHTTPTransport_IdleTimeoutSparesBusySessions(t *testing.T) {
+	defer func(orig time.Duration) { IdleTimeout = orig }(IdleTimeout)
+	IdleTimeout = 50 * time.Millisecond
+	transport := &httpTransport{base: newTestServer(t), sessions: map[string]*httpSession{}}
+	go transport.closeIdleSessions()
+	session := transport.open("", false)
+	isOpen := func() bool {
+		transport.mu.Lock()
+		defer transport.mu.Unlock()
+		return transport.sessions[session.server.sessionID] != nil
+	}
+
+	// A message held up past the idle timeout, as a long query would be
+	session.mu.Lock()
+	done := make(chan *JSONRPCResponse)
+	go func() { done <- session.handle([]byte(`{"jsonrpc":"2.0","id":1,"method":"ping"}`)) }()
+	time.Sleep(200 * time.Millisecond)
+	if !isOpen() {
+		t.Fatal("Expected a session handling a message to be kept open")
+	}
+	session.mu.Unlock()
+	if response := <-done; response == nil || response.Error != nil {
+		t.Errorf("Expected the held message to be answered, got %+v", response)
+	}
+
+	// The timeout counts from the response
+	time.Sleep(10 * time.Millisecond)
+	if !isOpen() {
+		t.Error("Expected the session to be kept open just after its response")
+	}
+	time.Sleep(200 * time.Millisecond)
+	if isOpen() {
+		t.Error("Expected the session to be closed once idle")
+	}
+}
+
+func TestHTTPTransport_EventStream(t *testing.T) {
+	defer func(orig time.Duration) { KeepaliveInterval = orig }(KeepaliveInterval)
+	KeepaliveInterval = 20 * time.Millisecond
+	ts := newHTTPTestServer(t)
+	session := initializeSession(t, ts)
+
+	get := func() *http.Response {
+		req, _ := http.NewRequest(http.MethodGet, ts.URL+HTTPEndpoint, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		req.Header.Set(sessionHeader, session)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		return resp
+	}
+	resp := get()
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil || line != ": keepalive\n" {
+		t.Errorf("Expected a keepalive comment, got %q %v", line, err)
+	}
+
+	second := get()
+	second.Body.Close()
+	if second.StatusCode != http.StatusConflict {
+		t.Errorf("Expected a second stream to be refused, got %d", second.StatusCode)
+	}
+}
+
+func TestHTTPTransport_Health(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	resp, err := http.Get(ts.URL + httpHealthPath)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "healthy\n" {
+		t.Errorf("Expected a healthy database, got %d %s", resp.StatusCode, body)
+	}
+}
+
+func TestSSEWriter(t *testing.T) {
+	rec := httptest.NewRecorder()
+	server := newTestServer(t)
+	server.writeMessage(&sseWriter{w: rec, flusher: rec}, &JSONRPCNotification{JSONRPC: "2.0", Method: "notifications/resources/updated"})
+	want := "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/resources/updated\"}\n\n"
+	if got := rec.Body.String(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
@@ -0,0 +1,49 @@
+package mcpsqldb
+
+import (
+	"context"
+	"strings"
+)
+
+// Quoting modes for the table and column names tools put in generated SQL
+const (
+	QuoteAlways    = "always"
+	QuoteMixedCase = "mixed-case"
+)
+
+// IdentifierQuoting is how tools quote the table and column names they are
+// given: exactly as written, or, for mixed-case, exactly only when the name
+// mixes upper and lower case and otherwise folded the way the database
+// folds unquoted names, so USERS finds users on PostgreSQL (overridable
+// via MCP_IDENTIFIER_QUOTING env var)
+var IdentifierQuoting = QuoteAlways
+
+// isMixedCase reports whether name has both upper and lower case letters.
+func isMixedCase(name string) bool {
+	return strings.ToLower(name) != name && strings.ToUpper(name) != name
+}
+
+// quoteName quotes a table or column name given to a tool for use in
+// generated SQL, according to IdentifierQuoting.
+func (s *Server) quoteName(name string) string {
+	return s.adapter.QuoteIdentifier(s.resolveName(name))
+}
+
+// resolveName returns name as generated SQL should look it up.
+func (s *Server) resolveName(name string) string {
+	if IdentifierQuoting == QuoteMixedCase && !isMixedCase(name) {
+		return s.adapter.FoldIdentifier(name)
+	}
+	return name
+}
+
+// identifierCase reports the database's identifier case rules, or nil when
+// they cannot be read.
+func (s *Server) identifierCase(ctx context.Context) *IdentifierCase {
+	ic, err := s.adapter.IdentifierCase(ctx, s.db)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to read identifier case rules", "error", err)
+		return nil
+	}
+	return ic
+}
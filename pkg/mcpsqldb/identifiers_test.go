@@ -0,0 +1,70 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestQuoteName(t *testing.T) {
+	defer func(orig string) { IdentifierQuoting = orig }(IdentifierQuoting)
+	server := &Server{adapter: &PostgresAdapter{}}
+
+	tests := []struct {
+		mode     string
+		name     string
+		expected string
+	}{
+		{QuoteAlways, "USERS", `"USERS"`},
+		{QuoteAlways, "UserAccounts", `"UserAccounts"`},
+		{QuoteMixedCase, "USERS", `"users"`},
+		{QuoteMixedCase, "order", `"order"`},
+		{QuoteMixedCase, "UserAccounts", `"UserAccounts"`},
+		{QuoteMixedCase, "user_2FA", `"user_2FA"`},
+	}
+	for _, tt := range tests {
+		IdentifierQuoting = tt.mode
+		if got := server.quoteName(tt.name); got != tt.expected {
+			t.Errorf("Expected %s for %q in %s mode, got %s", tt.expected, tt.name, tt.mode, got)
+		}
+	}
+
+	// Databases that do not fold names get them as given
+	IdentifierQuoting = QuoteMixedCase
+	server.adapter = &SQLiteAdapter{}
+	if got := server.quoteName("USERS"); got != `"USERS"` {
+		t.Errorf(`Expected "USERS" on SQLite, got %s`, got)
+	}
+}
+
+func TestServerStatus_ReportsIdentifierCase(t *testing.T) {
+	server := newTestServer(t)
+
+	result, _ := server.serverStatus(context.Background())
+	var status struct {
+		Identifiers *IdentifierCase `json:"identifiers"`
+	}
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &status); err != nil {
+		t.Fatalf("Failed to parse status: %v", err)
+	}
+	if status.Identifiers == nil || status.Identifiers.UnquotedFolding != "none" || status.Identifiers.TablesCaseSensitive {
+		t.Errorf("Expected SQLite's case-insensitive rules, got %+v", status.Identifiers)
+	}
+}
+
+func TestListResources_DescribesIdentifierCase(t *testing.T) {
+	server := newTestServer(t)
+
+	list, rpcErr := server.handleListResources(context.Background())
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if len(list.Resources) == 0 {
+		t.Fatal("Expected schema resources")
+	}
+	for _, r := range list.Resources {
+		if r.Description == "" {
+			t.Errorf("Expected %s to describe identifier case rules", r.URI)
+		}
+	}
+}
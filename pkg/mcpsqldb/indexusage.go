@@ -0,0 +1,183 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tableReferencePattern matches "FROM table" and "JOIN schema.table",
+// capturing the table (group 5) as identifierPattern does (groups 6-9).
+var tableReferencePattern = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+(?:` + identifierPattern.String() + `\s*\.\s*)?(` +
+	identifierPattern.String() + `)`)
+
+// aliasPattern matches the alias, if any, following a table reference. A
+// keyword may match too, which is harmless as no plan names a table by it.
+var aliasPattern = regexp.MustCompile(`^\s+(?i:AS\s+)?(?:` + identifierPattern.String() + `)`)
+
+// indexUse names an index of a table.
+type indexUse struct {
+	Table string `json:"table"`
+	Index string `json:"index"`
+}
+
+// ignoredIndex is an index whose leading column the query mentions but the
+// plan does not read.
+type ignoredIndex struct {
+	Table   string   `json:"table"`
+	Index   string   `json:"index"`
+	Columns []string `json:"columns"`
+	Unique  bool     `json:"unique"`
+}
+
+// indexUsage is the explain_index_usage result.
+type indexUsage struct {
+	IndexesUsed    []indexUse     `json:"indexes_used"`
+	FullTableScans []string       `json:"full_table_scans"`
+	FullIndexScans []indexUse     `json:"full_index_scans"`
+	IgnoredIndexes []ignoredIndex `json:"ignored_indexes"`
+}
+
+// explainIndexUsage plans a query without running it and reports the
+// indexes it uses, the tables and indexes it reads in full, and indexes on
+// the tables it reads that lead with a column it mentions yet go unused,
+// typically because the column is wrapped in a function or cast.
+func (s *Server) explainIndexUsage(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	sqlQuery, ok := args["sql"].(string)
+	if !ok || sqlQuery == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'sql' parameter",
+		}
+	}
+
+	// The query is planned, not run, but it is checked like any other
+	validated, err := s.validateQuery(sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	sqlQuery = validated
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	accesses, err := s.adapter.ExplainAccess(ctx, s.db, sqlQuery)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	indexes, err := s.adapter.ListIndexes(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	cleaned := s.adapter.RemoveStringsAndComments(sqlQuery)
+	resultJSON, err := json.MarshalIndent(analyzeIndexUsage(cleaned, accesses, indexes), "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(resultJSON)}},
+	}, nil
+}
+
+// analyzeIndexUsage compares the plan of cleanedSQL with the indexes of the
+// tables it reads. Tables the plan names by alias are resolved to the table
+// through the query's FROM and JOIN clauses.
+func analyzeIndexUsage(cleanedSQL string, accesses []PlanAccess, indexes []IndexInfo) indexUsage {
+	aliases := map[string]string{}
+	for _, loc := range tableReferencePattern.FindAllStringSubmatchIndex(cleanedSQL, -1) {
+		m := aliasPattern.FindStringSubmatch(cleanedSQL[loc[1]:])
+		if m == nil {
+			continue
+		}
+		// Group 5 includes any quotes; its alternatives (6-9) do not
+		var table string
+		for i := 6; i <= 9 && table == ""; i++ {
+			if loc[2*i] >= 0 {
+				table = cleanedSQL[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		aliases[strings.ToLower(firstIdentifier(m[1:]))] = table
+	}
+	mentioned := map[string]bool{}
+	for _, m := range identifierPattern.FindAllStringSubmatch(cleanedSQL, -1) {
+		mentioned[strings.ToLower(firstIdentifier(m[1:]))] = true
+	}
+
+	usage := indexUsage{
+		IndexesUsed:    []indexUse{},
+		FullTableScans: []string{},
+		FullIndexScans: []indexUse{},
+		IgnoredIndexes: []ignoredIndex{},
+	}
+	planned := map[string]bool{}
+	read := map[indexUse]bool{}
+	for _, access := range accesses {
+		table := access.Table
+		if name, ok := aliases[strings.ToLower(table)]; ok {
+			table = name
+		}
+		planned[strings.ToLower(table)] = true
+		use := indexUse{Table: table, Index: access.Index}
+		key := indexUse{Table: strings.ToLower(table), Index: access.Index}
+		if read[key] {
+			continue
+		}
+		read[key] = true
+		switch {
+		case access.Index == "":
+			usage.FullTableScans = append(usage.FullTableScans, table)
+		case access.FullScan:
+			usage.FullIndexScans = append(usage.FullIndexScans, use)
+		default:
+			usage.IndexesUsed = append(usage.IndexesUsed, use)
+		}
+	}
+
+	for _, index := range indexes {
+		if !planned[strings.ToLower(index.Table)] || read[indexUse{Table: strings.ToLower(index.Table), Index: index.Name}] {
+			continue
+		}
+		if len(index.Columns) > 0 && index.Columns[0] != "" && mentioned[strings.ToLower(index.Columns[0])] {
+			usage.IgnoredIndexes = append(usage.IgnoredIndexes, ignoredIndex{
+				Table:   index.Table,
+				Index:   index.Name,
+				Columns: index.Columns,
+				Unique:  index.Unique,
+			})
+		}
+	}
+	return usage
+}
+
+// firstIdentifier returns the identifier captured by one of
+// identifierPattern's alternatives.
+func firstIdentifier(groups []string) string {
+	for _, g := range groups {
+		if g != "" {
+			return g
+		}
+	}
+	return ""
+}
@@ -0,0 +1,133 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newIndexUsageServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"ALTER TABLE users ADD COLUMN email TEXT",
+		"CREATE UNIQUE INDEX idx_users_email ON users (email)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER NOT NULL, total INTEGER)",
+		"CREATE INDEX idx_orders_user ON orders (user_id)",
+		"CREATE INDEX idx_orders_total_expr ON orders (total * 2)",
+		"CREATE TABLE kv (k TEXT PRIMARY KEY, v TEXT) WITHOUT ROWID",
+	)
+}
+
+func TestExplainIndexUsage_IndexUsed(t *testing.T) {
+	server := newIndexUsageServer(t)
+
+	usage := callToolJSON[indexUsage](t, server, "explain_index_usage", map[string]any{"sql": "SELECT * FROM users u WHERE u.email = 'a@example.com'"})
+	if want := []indexUse{{Table: "users", Index: "idx_users_email"}}; !reflect.DeepEqual(usage.IndexesUsed, want) {
+		t.Errorf("Expected indexes used %v, got %v", want, usage.IndexesUsed)
+	}
+	if len(usage.FullTableScans) != 0 || len(usage.IgnoredIndexes) != 0 {
+		t.Errorf("Expected no scans or ignored indexes, got %+v", usage)
+	}
+}
+
+func TestExplainIndexUsage_IgnoredIndex(t *testing.T) {
+	server := newIndexUsageServer(t)
+
+	usage := callToolJSON[indexUsage](t, server, "explain_index_usage", map[string]any{"sql": "SELECT * FROM users WHERE lower(email) = 'a@example.com'"})
+	if want := []string{"users"}; !reflect.DeepEqual(usage.FullTableScans, want) {
+		t.Errorf("Expected full table scans %v, got %v", want, usage.FullTableScans)
+	}
+	want := []ignoredIndex{{Table: "users", Index: "idx_users_email", Columns: []string{"email"}, Unique: true}}
+	if !reflect.DeepEqual(usage.IgnoredIndexes, want) {
+		t.Errorf("Expected ignored indexes %v, got %v", want, usage.IgnoredIndexes)
+	}
+}
+
+func TestExplainIndexUsage_Join(t *testing.T) {
+	server := newIndexUsageServer(t)
+
+	usage := callToolJSON[indexUsage](t, server, "explain_index_usage", map[string]any{"sql": "SELECT u.name, o.total FROM orders AS o JOIN users u ON u.id = o.user_id WHERE o.total > 10"})
+	if want := []string{"orders"}; !reflect.DeepEqual(usage.FullTableScans, want) {
+		t.Errorf("Expected aliased orders to be scanned, got %v", usage.FullTableScans)
+	}
+	if want := []indexUse{{Table: "users", Index: "PRIMARY KEY"}}; !reflect.DeepEqual(usage.IndexesUsed, want) {
+		t.Errorf("Expected users primary key lookups, got %v", usage.IndexesUsed)
+	}
+	// user_id is mentioned but drives the join from the other side; the
+	// expression index names no columns
+	want := []ignoredIndex{{Table: "orders", Index: "idx_orders_user", Columns: []string{"user_id"}}}
+	if !reflect.DeepEqual(usage.IgnoredIndexes, want) {
+		t.Errorf("Expected ignored indexes %v, got %v", want, usage.IgnoredIndexes)
+	}
+}
+
+func TestExplainIndexUsage_FullIndexScanAndPrimaryKey(t *testing.T) {
+	server := newIndexUsageServer(t)
+
+	usage := callToolJSON[indexUsage](t, server, "explain_index_usage", map[string]any{"sql": "SELECT email FROM users ORDER BY email"})
+	if want := []indexUse{{Table: "users", Index: "idx_users_email"}}; !reflect.DeepEqual(usage.FullIndexScans, want) {
+		t.Errorf("Expected full index scans %v, got %v", want, usage.FullIndexScans)
+	}
+
+	usage = callToolJSON[indexUsage](t, server, "explain_index_usage", map[string]any{"sql": "SELECT v FROM kv WHERE k = 'a'"})
+	if want := []indexUse{{Table: "kv", Index: "PRIMARY KEY"}}; !reflect.DeepEqual(usage.IndexesUsed, want) {
+		t.Errorf("Expected WITHOUT ROWID primary key lookup, got %v", usage.IndexesUsed)
+	}
+	if len(usage.IgnoredIndexes) != 0 {
+		t.Errorf("Expected the used primary key not to be reported as ignored, got %v", usage.IgnoredIndexes)
+	}
+}
+
+func TestExplainIndexUsage_Errors(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"kv"}
+	server := newIndexUsageServer(t)
+
+	if _, rpcErr := server.callTool(context.Background(), "explain_index_usage", map[string]any{}); rpcErr == nil {
+		t.Error("Expected invalid params error for a missing sql parameter")
+	}
+
+	for _, query := range []string{"DELETE FROM users", "SELECT * FROM kv"} {
+		result, _ := server.callTool(context.Background(), "explain_index_usage", map[string]any{"sql": query})
+		if !result.IsError || !strings.HasPrefix(result.Content[0].Text, "Query rejected") {
+			t.Errorf("Expected %q to be rejected, got %+v", query, result)
+		}
+	}
+
+	result, _ := server.callTool(context.Background(), "explain_index_usage", map[string]any{"sql": "SELECT * FROM missing"})
+	if !result.IsError {
+		t.Errorf("Expected a plan error for a missing table, got %s", result.Content[0].Text)
+	}
+}
+
+func TestSQLiteListIndexes(t *testing.T) {
+	server := newIndexUsageServer(t)
+
+	indexes, err := server.adapter.ListIndexes(context.Background(), server.db, server.databaseName)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []IndexInfo{
+		{Table: "kv", Name: "PRIMARY KEY", Columns: []string{"k"}, Unique: true},
+		{Table: "orders", Name: "idx_orders_total_expr", Columns: []string{""}},
+		{Table: "orders", Name: "idx_orders_user", Columns: []string{"user_id"}},
+		{Table: "users", Name: "idx_users_email", Columns: []string{"email"}, Unique: true},
+	}
+	if !reflect.DeepEqual(indexes, want) {
+		t.Errorf("Expected %+v, got %+v", want, indexes)
+	}
+}
+
+func TestAnalyzeIndexUsage_ResolvesQuotedAliases(t *testing.T) {
+	accesses := []PlanAccess{{Table: "o", FullScan: true}, {Table: "o", FullScan: true}}
+	indexes := []IndexInfo{{Table: "Orders", Name: "idx_status", Columns: []string{"status"}}}
+
+	usage := analyzeIndexUsage(`SELECT * FROM app."Orders" o WHERE status = ?`, accesses, indexes)
+	if want := []string{"Orders"}; !reflect.DeepEqual(usage.FullTableScans, want) {
+		t.Errorf("Expected one scan of Orders, got %v", usage.FullTableScans)
+	}
+	if len(usage.IgnoredIndexes) != 1 {
+		t.Errorf("Expected idx_status to be reported as ignored, got %v", usage.IgnoredIndexes)
+	}
+}
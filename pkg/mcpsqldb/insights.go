@@ -0,0 +1,147 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// QueryInsights offers the query_insights tool, which reads the server's
+// statement statistics (overridable via MCP_QUERY_INSIGHTS env var)
+var QueryInsights = false
+
+// DefaultInsightStatements is how many statements query_insights returns
+// unless asked, and MaxInsightStatements the most it returns.
+const (
+	DefaultInsightStatements = 10
+	MaxInsightStatements     = 100
+)
+
+// ErrStatementStatsUnsupported is returned by DBAdapter.TopStatements for
+// databases without statement statistics.
+var ErrStatementStatsUnsupported = errors.New("statement statistics are not supported")
+
+// queryInsightsReport is the query_insights result.
+type queryInsightsReport struct {
+	OrderBy    string           `json:"order_by"`
+	Statements []StatementStats `json:"statements"`
+	// Hidden counts statements left out for naming a denied table or schema
+	Hidden int `json:"hidden,omitempty"`
+}
+
+// queryInsightsTool describes query_insights, listed only when enabled.
+func queryInsightsTool() Tool {
+	return Tool{
+		Name: "query_insights",
+		Description: "List the slowest statements the database server has run, from its statement statistics " +
+			"(MySQL performance_schema), with call counts, latencies and rows examined",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"order_by": {
+					Type: "string",
+					Description: fmt.Sprintf("%s (default), %s or %s",
+						StatementOrderTotalLatency, StatementOrderAvgLatency, StatementOrderCalls),
+				},
+				"limit": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Number of statements to return (default %d, at most %d)", DefaultInsightStatements, MaxInsightStatements),
+				},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// queryInsights reports the server's top statements. The statistics cover
+// every session on the server, so statements naming a denied table or
+// schema are counted but not shown.
+func (s *Server) queryInsights(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	if !QueryInsights {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "query_insights requires MCP_QUERY_INSIGHTS=true"}},
+			IsError: true,
+		}, nil
+	}
+
+	orderBy, limit, rpcErr := statementArgs(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	statements, err := s.adapter.TopStatements(ctx, s.db, orderBy, limit)
+	if errors.Is(err, ErrStatementStatsUnsupported) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("query_insights is not supported for %s", s.adapter.DriverName())}},
+			IsError: true,
+		}, nil
+	}
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report := queryInsightsReport{OrderBy: orderBy, Statements: []StatementStats{}}
+	for _, st := range statements {
+		if isSchemaDenied(DeniedSchemas, st.Schema) || s.statementDenied(st.Digest) {
+			report.Hidden++
+			continue
+		}
+		report.Statements = append(report.Statements, st)
+	}
+	return statementReportResult(report)
+}
+
+// statementArgs reads the order_by and limit arguments shared by
+// query_insights and top_queries.
+func statementArgs(args map[string]any) (string, int, *Error) {
+	orderBy := StatementOrderTotalLatency
+	if v, ok := args["order_by"]; ok {
+		order, _ := v.(string)
+		if order != StatementOrderTotalLatency && order != StatementOrderAvgLatency && order != StatementOrderCalls {
+			return "", 0, &Error{
+				Code:    InvalidParams,
+				Message: fmt.Sprintf("Invalid 'order_by' parameter: expected %s, %s or %s", StatementOrderTotalLatency, StatementOrderAvgLatency, StatementOrderCalls),
+			}
+		}
+		orderBy = order
+	}
+	limit, rpcErr := intArg(args, "limit", DefaultInsightStatements, 1, math.MaxInt)
+	if rpcErr != nil {
+		return "", 0, rpcErr
+	}
+	return orderBy, min(limit, MaxInsightStatements), nil
+}
+
+// statementDenied reports whether a statement from the server's statistics
+// names a denied table or schema.
+func (s *Server) statementDenied(statement string) bool {
+	cleaned := s.adapter.RemoveStringsAndComments(statement)
+	return deniedTableReference(DeniedTables, cleaned) != "" || deniedSchemaReference(DeniedSchemas, cleaned) != ""
+}
+
+// statementReportResult returns a statement statistics report as JSON.
+func statementReportResult(report any) (*CallToolResult, *Error) {
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(jsonData)}},
+	}, nil
+}
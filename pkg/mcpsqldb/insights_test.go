@@ -0,0 +1,84 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// fixedStatsAdapter reports fixed statement statistics.
+type fixedStatsAdapter struct {
+	*SQLiteAdapter
+	stats   []StatementStats
+	orderBy string
+	limit   int
+}
+
+func (a *fixedStatsAdapter) TopStatements(ctx context.Context, db *sql.DB, orderBy string, limit int) ([]StatementStats, error) {
+	a.orderBy, a.limit = orderBy, limit
+	return a.stats, nil
+}
+
+func TestQueryInsights(t *testing.T) {
+	defer func(enabled bool, tables, schemas []string) {
+		QueryInsights, DeniedTables, DeniedSchemas = enabled, tables, schemas
+	}(QueryInsights, DeniedTables, DeniedSchemas)
+	QueryInsights = true
+	DeniedTables = []string{"payroll"}
+	DeniedSchemas = []string{"audit"}
+
+	server := newTestServer(t)
+	adapter := &fixedStatsAdapter{SQLiteAdapter: &SQLiteAdapter{}, stats: []StatementStats{
+		{Schema: "app", Digest: "SELECT * FROM `orders` WHERE `id` = ?", Calls: 10, TotalLatencyMs: 1500},
+		{Schema: "app", Digest: "SELECT SUM ( `salary` ) FROM `payroll`", Calls: 1, TotalLatencyMs: 900},
+		{Schema: "audit", Digest: "SELECT * FROM `events`", Calls: 2, TotalLatencyMs: 800},
+	}}
+	server.adapter = adapter
+
+	result, rpcErr := server.queryInsights(context.Background(), map[string]any{"order_by": "calls", "limit": float64(500)})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Unexpected error: %+v %+v", rpcErr, result)
+	}
+	if adapter.orderBy != StatementOrderCalls || adapter.limit != MaxInsightStatements {
+		t.Errorf("Expected calls order and a capped limit, got %q %d", adapter.orderBy, adapter.limit)
+	}
+	var report queryInsightsReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("Failed to parse report: %v", err)
+	}
+	if len(report.Statements) != 1 || report.Statements[0].Calls != 10 || report.Hidden != 2 {
+		t.Errorf("Expected one visible statement and two hidden, got %s", result.Content[0].Text)
+	}
+
+	if _, rpcErr := server.queryInsights(context.Background(), map[string]any{"order_by": "rows"}); rpcErr == nil || rpcErr.Code != InvalidParams {
+		t.Errorf("Expected invalid params for an unknown order, got %+v", rpcErr)
+	}
+}
+
+func TestQueryInsights_Availability(t *testing.T) {
+	defer func(orig bool) { QueryInsights = orig }(QueryInsights)
+	server := newTestServer(t)
+
+	QueryInsights = false
+	tools, _ := server.handleListTools()
+	for _, tool := range tools.Tools {
+		if tool.Name == "query_insights" {
+			t.Error("Expected query_insights to be hidden unless enabled")
+		}
+	}
+	if result, _ := server.queryInsights(context.Background(), nil); !result.IsError {
+		t.Error("Expected an error while disabled")
+	}
+
+	QueryInsights = true
+	tools, _ = server.handleListTools()
+	if last := tools.Tools[len(tools.Tools)-1]; last.Name != "query_insights" {
+		t.Errorf("Expected query_insights to be listed, got %s", last.Name)
+	}
+	result, _ := server.queryInsights(context.Background(), nil)
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "not supported for sqlite") {
+		t.Errorf("Expected SQLite to be unsupported, got %+v", result)
+	}
+}
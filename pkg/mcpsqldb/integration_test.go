@@ -0,0 +1,239 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// integrationTable is created by each target's fixture and dropped afterwards.
+const integrationTable = "mcp_it_users"
+
+// integrationTarget is a database the end-to-end tests run against. MySQL and
+// Postgres run only when MCP_TEST_MYSQL_DSN / MCP_TEST_POSTGRES_DSN name a
+// database whose user may create and drop the fixture table.
+type integrationTarget struct {
+	name    string
+	adapter DBAdapter
+	dsn     func(t *testing.T) string
+}
+
+var integrationTargets = []integrationTarget{
+	{"sqlite", &SQLiteAdapter{}, func(t *testing.T) string {
+		return filepath.Join(t.TempDir(), "it.db")
+	}},
+	{"mysql", &MySQLAdapter{}, envDSN("MCP_TEST_MYSQL_DSN")},
+	{"postgres", &PostgresAdapter{}, envDSN("MCP_TEST_POSTGRES_DSN")},
+	{"pgx", &PostgresAdapter{Driver: PostgresDriverPGX}, envDSN("MCP_TEST_POSTGRES_DSN")},
+}
+
+func envDSN(name string) func(t *testing.T) string {
+	return func(t *testing.T) string {
+		dsn := os.Getenv(name)
+		if dsn == "" {
+			t.Skipf("%s not set", name)
+		}
+		return dsn
+	}
+}
+
+// openIntegrationServer seeds the fixture table with a writable connection,
+// then starts a read-only server over the same database.
+func openIntegrationServer(t *testing.T, target integrationTarget) *Server {
+	t.Helper()
+	dsn := target.dsn(t)
+
+	setup, err := sql.Open(target.adapter.DriverName(), dsn)
+	if err != nil {
+		t.Fatalf("Failed to open setup connection: %v", err)
+	}
+	t.Cleanup(func() {
+		setup.Exec("DROP TABLE " + integrationTable)
+		setup.Close()
+	})
+	for _, stmt := range []string{
+		"DROP TABLE IF EXISTS " + integrationTable,
+		"CREATE TABLE " + integrationTable + " (id INTEGER PRIMARY KEY, name VARCHAR(50) NOT NULL)",
+		"INSERT INTO " + integrationTable + " (id, name) VALUES (1, 'alice'), (2, 'bob'), (3, 'carol')",
+	} {
+		if _, err := setup.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed %s: %v", target.name, err)
+		}
+	}
+
+	if !target.adapter.Remote() {
+		dsn, _ = target.adapter.FormatDSN(ConnParams{Database: dsn})
+	}
+	server, err := newServer(context.Background(), target.adapter, dsn)
+	if err != nil {
+		t.Fatalf("Failed to create %s server: %v", target.name, err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func TestIntegration_EndToEnd(t *testing.T) {
+	for _, target := range integrationTargets {
+		t.Run(target.name, func(t *testing.T) {
+			server := openIntegrationServer(t, target)
+			ctx := context.Background()
+
+			list, rpcErr := server.handleListResources(ctx)
+			if rpcErr != nil {
+				t.Fatalf("Failed to list resources: %v", rpcErr.Message)
+			}
+			uri := ""
+			for _, r := range list.Resources {
+				if strings.Contains(r.URI, "/"+integrationTable+"/") {
+					uri = r.URI
+					if r.Description == "" {
+						t.Errorf("Expected %s to describe identifier case rules", r.URI)
+					}
+				}
+			}
+			if !strings.HasPrefix(uri, target.adapter.URIScheme()+"://") {
+				t.Fatalf("Expected %s resource for %s, got %+v", target.adapter.URIScheme(), integrationTable, list.Resources)
+			}
+
+			params, _ := json.Marshal(ReadResourceParams{URI: uri})
+			read, rpcErr := server.handleReadResource(ctx, params)
+			if rpcErr != nil {
+				t.Fatalf("Failed to read schema: %v", rpcErr.Message)
+			}
+			var columns []map[string]any
+			json.Unmarshal([]byte(read.Contents[0].Text), &columns)
+			if len(columns) != 2 || columns[0]["column_name"] != "id" || columns[1]["column_name"] != "name" {
+				t.Errorf("Expected id and name columns, got %s", read.Contents[0].Text)
+			}
+
+			result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM " + integrationTable + " ORDER BY id"})
+			var rows []map[string]any
+			if result.IsError || json.Unmarshal([]byte(result.Content[0].Text), &rows) != nil || len(rows) != 3 || rows[0]["name"] != "alice" {
+				t.Errorf("Expected 3 rows starting with alice, got %s", result.Content[0].Text)
+			}
+
+			server.executeQuery(ctx, map[string]any{"sql": "SELECT id, name FROM " + integrationTable + " WHERE id > 1", "save_as": "it_saved"})
+			result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM it_saved ORDER BY id"})
+			if result.IsError || json.Unmarshal([]byte(result.Content[0].Text), &rows) != nil || len(rows) != 2 || rows[0]["name"] != "bob" {
+				t.Errorf("Expected the saved rows starting with bob, got %s", result.Content[0].Text)
+			}
+
+			result, _ = server.executeQuery(ctx, map[string]any{"sql": "DELETE FROM " + integrationTable})
+			if !result.IsError {
+				t.Error("Expected DELETE to be rejected")
+			}
+
+			// The session itself must refuse writes that slip past validation
+			if _, err := server.db.ExecContext(ctx, "INSERT INTO "+integrationTable+" (id, name) VALUES (4, 'mallory')"); err == nil {
+				t.Error("Expected write on a pooled connection to fail")
+			}
+			if refusal, err := probeWrites(ctx, target.adapter, server.db); err != nil || refusal == "" {
+				t.Errorf("Expected the write probe to be refused, got %v", err)
+			}
+
+			sample, _ := server.sampleRows(ctx, map[string]any{"table": integrationTable, "limit": float64(2)})
+			if sample.IsError || json.Unmarshal([]byte(sample.Content[0].Text), &rows) != nil || len(rows) != 2 {
+				t.Errorf("Expected 2 sampled rows, got %s", sample.Content[0].Text)
+			}
+
+			// The fixture table keeps no history on any target
+			past, _ := server.sampleRows(ctx, map[string]any{"table": integrationTable, "as_of": "2024-05-01T12:00:00Z"})
+			if !past.IsError {
+				t.Errorf("Expected sampling the fixture as of a past time to fail, got %s", past.Content[0].Text)
+			}
+
+			// Tiny tables may be planned either way; the plan must name the table
+			explain, _ := server.explainIndexUsage(ctx, map[string]any{"sql": "SELECT name FROM " + integrationTable + " WHERE id = 2"})
+			var usage indexUsage
+			if explain.IsError || json.Unmarshal([]byte(explain.Content[0].Text), &usage) != nil ||
+				len(usage.IndexesUsed)+len(usage.FullTableScans) != 1 {
+				t.Errorf("Expected one access of %s in index usage, got %s", integrationTable, explain.Content[0].Text)
+			}
+
+			settings, _ := server.databaseSettings(ctx)
+			var settingsReport databaseSettingsReport
+			if settings.IsError || json.Unmarshal([]byte(settings.Content[0].Text), &settingsReport) != nil ||
+				settingsReport.Settings["version"] == "" {
+				t.Errorf("Expected database settings with a version, got %s", settings.Content[0].Text)
+			}
+
+			variables, _ := server.sessionVariables(ctx, map[string]any{})
+			var variablesReport sessionVariablesReport
+			if variables.IsError || json.Unmarshal([]byte(variables.Content[0].Text), &variablesReport) != nil ||
+				variablesReport.Variables["version"] == nil || variablesReport.Variables["time_zone"] == nil && target.name != "sqlite" {
+				t.Errorf("Expected session variables with a version and time zone, got %s", variables.Content[0].Text)
+			}
+
+			sequences, _ := server.listSequences(ctx)
+			var sequencesList sequencesReport
+			if sequences.IsError || json.Unmarshal([]byte(sequences.Content[0].Text), &sequencesList) != nil {
+				t.Errorf("Expected a list of sequences, got %s", sequences.Content[0].Text)
+			}
+
+			checksum, _ := server.checksumRows(ctx, map[string]any{"table": integrationTable})
+			var checksumResult checksumReport
+			if checksum.IsError || json.Unmarshal([]byte(checksum.Content[0].Text), &checksumResult) != nil || checksumResult.Rows != 3 {
+				t.Errorf("Expected a checksum of 3 rows, got %s", checksum.Content[0].Text)
+			}
+
+			// The fixture has no JSON column; names are sampled as invalid documents
+			inferred, _ := server.inferJSONSchema(ctx, map[string]any{"table": integrationTable, "column": "name"})
+			var jsonReport jsonSchemaReport
+			if inferred.IsError || json.Unmarshal([]byte(inferred.Content[0].Text), &jsonReport) != nil || jsonReport.InvalidRows != 3 {
+				t.Errorf("Expected 3 sampled rows that are not JSON, got %s", inferred.Content[0].Text)
+			}
+
+			if target.name != "sqlite" {
+				defer func(orig bool) { RunningQueries = orig }(RunningQueries)
+				RunningQueries = true
+				running, _ := server.runningQueries(ctx)
+				var active []ActiveQuery
+				if running.IsError || json.Unmarshal([]byte(running.Content[0].Text), &active) != nil {
+					t.Errorf("Expected a list of running queries, got %s", running.Content[0].Text)
+				}
+			}
+
+			if _, ok := target.adapter.(*PostgresAdapter); ok {
+				result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT 12.50::numeric AS n, '$3.25'::money AS m, " +
+					"'a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11'::uuid AS u, '1 day'::interval AS i, '08:00:2b:01:02:03'::macaddr AS mac"})
+				text := result.Content[0].Text
+				for _, want := range []string{`"n": 12.50`, `"m": 3.25`, `"u": "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"`, `"i": "1 day"`, `"mac": "08:00:2b:01:02:03"`} {
+					if result.IsError || !strings.Contains(text, want) {
+						t.Errorf("Expected %s in decoded special types, got %s", want, text)
+					}
+				}
+
+				// plpgsql is installed in every database
+				params, _ := json.Marshal(ReadResourceParams{URI: server.extensionsResourceURI()})
+				read, rpcErr := server.handleReadResource(ctx, params)
+				if rpcErr != nil || !strings.Contains(read.Contents[0].Text, `"name": "plpgsql"`) {
+					t.Errorf("Expected plpgsql among the extensions, got %+v %+v", rpcErr, read)
+				}
+
+				// pg_stat_statements is optional; without it the tool explains how to install it
+				defer func(orig bool) { TopQueries = orig }(TopQueries)
+				TopQueries = true
+				top, _ := server.topQueries(ctx, map[string]any{"limit": float64(5)})
+				if top.IsError && !strings.Contains(top.Content[0].Text, "pg_stat_statements") {
+					t.Errorf("Expected top queries or a pg_stat_statements hint, got %s", top.Content[0].Text)
+				}
+			}
+
+			summary, _ := server.summarizeSchema(ctx)
+			if summary.IsError || !strings.Contains(summary.Content[0].Text, "\n"+integrationTable) ||
+				!strings.Contains(summary.Content[0].Text, "id ") {
+				t.Errorf("Expected %s in schema summary, got %s", integrationTable, summary.Content[0].Text)
+			}
+
+			status, _ := server.serverStatus(ctx)
+			var report map[string]any
+			if json.Unmarshal([]byte(status.Content[0].Text), &report) != nil || report["connected"] != true {
+				t.Errorf("Expected connected status, got %s", status.Content[0].Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,403 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AsyncQueryTimeout bounds queries started with submit_query
+// (overridable via MCP_ASYNC_QUERY_TIMEOUT)
+var AsyncQueryTimeout = 10 * time.Minute
+
+// ResultTTL is how long a finished job's result is kept; 0 keeps it until
+// evicted (overridable via MCP_RESULT_TTL)
+var ResultTTL = time.Hour
+
+// MaxAsyncJobs is the number of jobs retained; the oldest finished jobs are
+// evicted first once the limit is reached.
+const MaxAsyncJobs = 100
+
+// Job states reported by get_query_result
+const (
+	JobRunning   = "running"
+	JobCompleted = "completed"
+	JobFailed    = "failed"
+)
+
+// queryJob tracks a query started by submit_query.
+type queryJob struct {
+	ID          string
+	SQL         string
+	Status      string
+	SubmittedAt time.Time
+	FinishedAt  time.Time
+	Result      *CallToolResult
+}
+
+// jobStore holds async query jobs; it is safe for concurrent use.
+type jobStore struct {
+	mu     sync.Mutex
+	jobs   map[string]*queryJob
+	order  []string
+	nextID int
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{jobs: make(map[string]*queryJob)}
+}
+
+func (js *jobStore) add(sqlQuery string) (*queryJob, error) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.expireLocked(time.Now())
+	if len(js.order) >= MaxAsyncJobs && !js.evictLocked() {
+		return nil, fmt.Errorf("too many running jobs (limit %d)", MaxAsyncJobs)
+	}
+
+	js.nextID++
+	job := &queryJob{
+		ID:          fmt.Sprintf("job-%d", js.nextID),
+		SQL:         sqlQuery,
+		Status:      JobRunning,
+		SubmittedAt: time.Now(),
+	}
+	js.jobs[job.ID] = job
+	js.order = append(js.order, job.ID)
+	return job, nil
+}
+
+// evictLocked drops the oldest finished job, reporting whether one was found.
+func (js *jobStore) evictLocked() bool {
+	for i, id := range js.order {
+		if js.jobs[id].Status != JobRunning {
+			delete(js.jobs, id)
+			js.order = append(js.order[:i], js.order[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// expireLocked drops the finished jobs whose results are older than
+// ResultTTL.
+func (js *jobStore) expireLocked(now time.Time) {
+	if ResultTTL <= 0 {
+		return
+	}
+	kept := js.order[:0]
+	for _, id := range js.order {
+		job := js.jobs[id]
+		if job.Status != JobRunning && now.Sub(job.FinishedAt) > ResultTTL {
+			delete(js.jobs, id)
+			continue
+		}
+		kept = append(kept, id)
+	}
+	js.order = kept
+}
+
+func (js *jobStore) finish(id string, result *CallToolResult) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	job, ok := js.jobs[id]
+	if !ok {
+		return
+	}
+	job.Result = result
+	job.FinishedAt = time.Now()
+	job.Status = JobCompleted
+	if result.IsError {
+		job.Status = JobFailed
+	}
+}
+
+// get returns a copy of the job so callers can read it without the lock.
+func (js *jobStore) get(id string) (queryJob, bool) {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.expireLocked(time.Now())
+	job, ok := js.jobs[id]
+	if !ok {
+		return queryJob{}, false
+	}
+	return *job, true
+}
+
+func (js *jobStore) list() []queryJob {
+	js.mu.Lock()
+	defer js.mu.Unlock()
+
+	js.expireLocked(time.Now())
+	jobs := make([]queryJob, 0, len(js.order))
+	for _, id := range js.order {
+		jobs = append(jobs, *js.jobs[id])
+	}
+	return jobs
+}
+
+// jobResourceURI returns the resource URI under which a job's result is held.
+func (s *Server) jobResourceURI(id string) string {
+	return fmt.Sprintf("%s://results/%s", s.adapter.URIScheme(), id)
+}
+
+func (s *Server) submitQuery(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	sqlQuery, ok := args["sql"].(string)
+	if !ok || sqlQuery == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'sql' parameter",
+		}
+	}
+
+	params, rpcErr := queryParams(s.adapter, args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	// Reject invalid queries up front rather than in a failed job
+	validated, err := s.validateQuery(sqlQuery)
+	if err == nil {
+		err = s.checkParams(validated, params)
+	}
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error(), params...)
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	sqlQuery = validated
+	if result := s.checkCost(ctx, sqlQuery, params...); result != nil {
+		return result, nil
+	}
+
+	job, err := s.jobs.add(sqlQuery)
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+
+	// The job outlives this request but keeps its logger for correlation
+	jobCtx := withLogger(s.ctx, loggerFrom(ctx).With("job_id", job.ID))
+	go func() {
+		ctx, cancel := context.WithTimeout(jobCtx, AsyncQueryTimeout)
+		defer cancel()
+		s.jobs.finish(job.ID, s.runQuery(ctx, sqlQuery, params...))
+	}()
+
+	return jobStatusResult(map[string]any{
+		"job_id":       job.ID,
+		"status":       job.Status,
+		"resource_uri": s.jobResourceURI(job.ID),
+	}), nil
+}
+
+func (s *Server) getQueryResult(args map[string]any) (*CallToolResult, *Error) {
+	id, ok := args["job_id"].(string)
+	if !ok || id == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'job_id' parameter",
+		}
+	}
+
+	job, ok := s.jobs.get(id)
+	if !ok {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Unknown job: %s", id),
+		}
+	}
+
+	if job.Status == JobRunning {
+		return jobStatusResult(map[string]any{
+			"job_id":     job.ID,
+			"status":     job.Status,
+			"elapsed_ms": time.Since(job.SubmittedAt).Milliseconds(),
+		}), nil
+	}
+	return job.Result, nil
+}
+
+func jobStatusResult(status map[string]any) *CallToolResult {
+	statusJSON, _ := json.MarshalIndent(status, "", "  ")
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(statusJSON)}},
+	}
+}
+
+// jobResources lists finished jobs whose results can be read as resources.
+func (s *Server) jobResources() []Resource {
+	var resources []Resource
+	for _, job := range s.jobs.list() {
+		if job.Status == JobRunning {
+			continue
+		}
+		mimeType := "application/json"
+		description := "Read a range of rows with ?rows=<first>-<last>, e.g. ?rows=0-999"
+		if job.Status == JobFailed {
+			mimeType, description = "text/plain", ""
+		}
+		resources = append(resources, Resource{
+			URI:         s.jobResourceURI(job.ID),
+			Name:        fmt.Sprintf("Result of %s (%s)", job.ID, job.Status),
+			Description: description,
+			MimeType:    mimeType,
+		})
+	}
+	return resources
+}
+
+// resultPage is a range of a job result's rows, read with
+// ?rows=<first>-<last> (0-based, inclusive).
+type resultPage struct {
+	JobID     string            `json:"job_id"`
+	Rows      []json.RawMessage `json:"rows"`
+	First     int               `json:"first"`
+	TotalRows int               `json:"total_rows"`
+	// Next is the URI of the following range of the same size
+	Next string `json:"next,omitempty"`
+	// Notices are the result's truncation and omitted columns notices
+	Notices []string `json:"notices,omitempty"`
+}
+
+// readJobResource serves a job result for a URI of the form
+// scheme://results/<id>[?rows=<first>-<last>], or the older
+// scheme://jobs/<id>/result. ok is false when uri is not a job URI.
+func (s *Server) readJobResource(uri string) (result *ReadResourceResult, rpcErr *Error, ok bool) {
+	path, rawQuery, _ := strings.Cut(uri, "?")
+	scheme := s.adapter.URIScheme()
+	var id string
+	switch {
+	case strings.HasPrefix(path, scheme+"://results/"):
+		id = strings.TrimPrefix(path, scheme+"://results/")
+	case strings.HasPrefix(path, scheme+"://jobs/") && strings.HasSuffix(path, "/result"):
+		id = strings.TrimSuffix(strings.TrimPrefix(path, scheme+"://jobs/"), "/result")
+	default:
+		return nil, nil, false
+	}
+
+	job, found := s.jobs.get(id)
+	if !found {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Unknown or expired job: %s", id),
+		}, true
+	}
+	if job.Status == JobRunning {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Job %s is still running", id),
+		}, true
+	}
+
+	mimeType := "application/json"
+	text := job.Result.Content[0].Text
+	if job.Status == JobFailed {
+		mimeType = "text/plain"
+		text = redactSecrets(text)
+	} else if rawQuery != "" {
+		page, err := pageResult(text, rawQuery)
+		if err != nil {
+			return nil, &Error{
+				Code:    InvalidParams,
+				Message: fmt.Sprintf("Invalid result range: %v", err),
+			}, true
+		}
+		page.JobID = id
+		if page.Next != "" {
+			page.Next = s.jobResourceURI(id) + "?rows=" + page.Next
+		}
+		pageJSON, _ := json.MarshalIndent(page, "", "  ")
+		text = string(pageJSON)
+	}
+
+	return &ReadResourceResult{
+		Contents: []ResourceContent{
+			{
+				URI:      uri,
+				MimeType: mimeType,
+				Text:     text,
+			},
+		},
+	}, nil, true
+}
+
+// pageResult returns the rows of the JSON result text in the range given by
+// the rows parameter of rawQuery. Next is set to the following range when
+// rows remain. Results are kept unchanged until they expire, so a range
+// always returns the same rows.
+func pageResult(text, rawQuery string) (*resultPage, error) {
+	params, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, err
+	}
+	first, last, err := parseRowRange(params.Get("rows"))
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []json.RawMessage
+	if err := json.Unmarshal([]byte(text), &rows); err != nil {
+		return nil, fmt.Errorf("result is not a list of rows")
+	}
+	page := &resultPage{First: first, Rows: []json.RawMessage{}}
+	// Notices are appended after the rows
+	for len(rows) > 0 {
+		var row map[string]any
+		json.Unmarshal(rows[len(rows)-1], &row)
+		msg, ok := resultNotice(row)
+		if !ok {
+			break
+		}
+		page.Notices = append([]string{msg}, page.Notices...)
+		rows = rows[:len(rows)-1]
+	}
+
+	page.TotalRows = len(rows)
+	if len(rows) == 0 {
+		if first > 0 {
+			return nil, fmt.Errorf("rows=%d-%d starts past the end of an empty result", first, last)
+		}
+		return page, nil
+	}
+	if first >= len(rows) {
+		return nil, fmt.Errorf("rows=%d-%d starts past the last row, %d", first, last, len(rows)-1)
+	}
+	// Clamped before any arithmetic, so a last of MaxInt cannot overflow;
+	// the next range then keeps the page size and stays within twice the
+	// row count
+	last = min(last, len(rows)-1)
+	page.Rows = rows[first : last+1]
+	if last+1 < len(rows) {
+		size := last - first + 1
+		page.Next = fmt.Sprintf("%d-%d", last+1, last+size)
+	}
+	return page, nil
+}
+
+// parseRowRange parses a rows parameter of the form <first>-<last>.
+func parseRowRange(spec string) (int, int, error) {
+	from, to, ok := strings.Cut(spec, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected rows=<first>-<last>, got %q", spec)
+	}
+	first, err1 := strconv.Atoi(strings.TrimSpace(from))
+	last, err2 := strconv.Atoi(strings.TrimSpace(to))
+	if err1 != nil || err2 != nil || first < 0 || last < first {
+		return 0, 0, fmt.Errorf("expected rows=<first>-<last> with 0 <= first <= last, got %q", spec)
+	}
+	return first, last, nil
+}
@@ -0,0 +1,194 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForJob polls get_query_result until the job leaves the running state.
+func waitForJob(t *testing.T, server *Server, id string) *CallToolResult {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if job, ok := server.jobs.get(id); ok && job.Status != JobRunning {
+			result, rpcErr := server.getQueryResult(map[string]any{"job_id": id})
+			if rpcErr != nil {
+				t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+			}
+			return result
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("Job %s did not finish", id)
+	return nil
+}
+
+func TestSubmitQuery_CompletesAndExposesResource(t *testing.T) {
+	server := newTestServer(t)
+
+	submitted, rpcErr := server.submitQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users ORDER BY id"})
+	if rpcErr != nil || submitted.IsError {
+		t.Fatalf("Submit failed: %v %v", rpcErr, submitted)
+	}
+
+	var status map[string]any
+	if err := json.Unmarshal([]byte(submitted.Content[0].Text), &status); err != nil {
+		t.Fatalf("Failed to parse submit response: %v", err)
+	}
+	id, _ := status["job_id"].(string)
+	if id == "" {
+		t.Fatalf("Expected job_id in %v", status)
+	}
+
+	result := waitForJob(t, server, id)
+	if result.IsError {
+		t.Fatalf("Expected job to succeed: %s", result.Content[0].Text)
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil || len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %q", result.Content[0].Text)
+	}
+
+	resource, rpcErr := server.handleReadResource(context.Background(), json.RawMessage(`{"uri":"`+status["resource_uri"].(string)+`"}`))
+	if rpcErr != nil {
+		t.Fatalf("Failed to read job resource: %v", rpcErr.Message)
+	}
+	if resource.Contents[0].Text != result.Content[0].Text {
+		t.Error("Expected resource to hold the job result")
+	}
+}
+
+func TestSubmitQuery_RejectsInvalidQuery(t *testing.T) {
+	server := newTestServer(t)
+
+	result, rpcErr := server.submitQuery(context.Background(), map[string]any{"sql": "DELETE FROM users"})
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if !result.IsError {
+		t.Error("Expected write query to be rejected at submission")
+	}
+	if len(server.jobs.list()) != 0 {
+		t.Error("Expected no job to be created for a rejected query")
+	}
+}
+
+func TestGetQueryResult_UnknownJob(t *testing.T) {
+	server := newTestServer(t)
+
+	if _, rpcErr := server.getQueryResult(map[string]any{"job_id": "job-999"}); rpcErr == nil {
+		t.Error("Expected error for unknown job")
+	}
+}
+
+// readResource reads uri, failing the test on an error.
+func readResource(t *testing.T, server *Server, uri string) string {
+	t.Helper()
+	params, _ := json.Marshal(ReadResourceParams{URI: uri})
+	resource, rpcErr := server.handleReadResource(context.Background(), params)
+	if rpcErr != nil {
+		t.Fatalf("Failed to read %s: %v", uri, rpcErr.Message)
+	}
+	return resource.Contents[0].Text
+}
+
+func TestJobResource_RangeReads(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 3
+	server := newTestServer(t, "INSERT INTO users (name) VALUES ('dave'), ('erin')")
+
+	submitted, _ := server.submitQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users ORDER BY id"})
+	var status map[string]any
+	json.Unmarshal([]byte(submitted.Content[0].Text), &status)
+	id := status["job_id"].(string)
+	uri := status["resource_uri"].(string)
+	if uri != "sqlite://results/"+id {
+		t.Errorf("Expected the result under results/, got %s", uri)
+	}
+	waitForJob(t, server, id)
+
+	var page resultPage
+	if err := json.Unmarshal([]byte(readResource(t, server, uri+"?rows=0-1")), &page); err != nil {
+		t.Fatalf("Failed to parse page: %v", err)
+	}
+	if len(page.Rows) != 2 || !strings.Contains(string(page.Rows[0]), "alice") {
+		t.Errorf("Expected the first 2 rows, got %+v", page)
+	}
+	if page.TotalRows != 3 || page.Next != uri+"?rows=2-3" || len(page.Notices) != 1 {
+		t.Errorf("Expected 3 rows in all, a next range and the truncation notice, got %+v", page)
+	}
+
+	var last resultPage
+	json.Unmarshal([]byte(readResource(t, server, page.Next)), &last)
+	if len(last.Rows) != 1 || last.First != 2 || last.Next != "" {
+		t.Errorf("Expected the last row without a next range, got %+v", last)
+	}
+
+	// The older URI still reads the whole result
+	if text := readResource(t, server, "sqlite://jobs/"+id+"/result"); !strings.HasPrefix(text, "[") {
+		t.Errorf("Expected the full result, got %s", text)
+	}
+
+	for _, rows := range []string{"5-2", "3-4", "-1-2", "0-9223372036854775808"} {
+		params, _ := json.Marshal(ReadResourceParams{URI: uri + "?rows=" + rows})
+		if _, rpcErr := server.handleReadResource(context.Background(), params); rpcErr == nil {
+			t.Errorf("Expected range %s to be rejected", rows)
+		}
+	}
+}
+
+func TestPageResult_Bounds(t *testing.T) {
+	text := `[{"n":0},{"n":1},{"n":2}]`
+	cases := []struct {
+		rows  string
+		count int
+		next  string
+	}{
+		{"0-9223372036854775807", 3, ""},
+		{"2-9223372036854775807", 1, ""},
+		{"1-1", 1, "2-2"},
+		{"0-1", 2, "2-3"},
+	}
+	for _, tc := range cases {
+		page, err := pageResult(text, "rows="+tc.rows)
+		if err != nil {
+			t.Errorf("Expected rows=%s to be read, got %v", tc.rows, err)
+			continue
+		}
+		if len(page.Rows) != tc.count || page.Next != tc.next {
+			t.Errorf("Expected rows=%s to return %d rows and next %q, got %d and %q", tc.rows, tc.count, tc.next, len(page.Rows), page.Next)
+		}
+	}
+
+	if page, err := pageResult("[]", "rows=0-9223372036854775807"); err != nil || len(page.Rows) != 0 || page.Next != "" {
+		t.Errorf("Expected an empty page of an empty result, got %+v %v", page, err)
+	}
+	if _, err := pageResult(text, "rows=9223372036854775807-9223372036854775807"); err == nil {
+		t.Error("Expected a range past the last row to be rejected")
+	}
+}
+
+func TestJobStore_ExpiresResults(t *testing.T) {
+	defer func(orig time.Duration) { ResultTTL = orig }(ResultTTL)
+	ResultTTL = time.Minute
+	jobs := newJobStore()
+
+	job, _ := jobs.add("SELECT 1")
+	jobs.finish(job.ID, &CallToolResult{Content: []Content{{Type: "text", Text: "[]"}}})
+	if _, ok := jobs.get(job.ID); !ok {
+		t.Fatal("Expected a fresh result to be kept")
+	}
+
+	jobs.jobs[job.ID].FinishedAt = time.Now().Add(-2 * time.Minute)
+	running, _ := jobs.add("SELECT 2")
+	if _, ok := jobs.get(job.ID); ok {
+		t.Error("Expected the expired result to be dropped")
+	}
+	if _, ok := jobs.get(running.ID); !ok {
+		t.Error("Expected a running job to be kept")
+	}
+}
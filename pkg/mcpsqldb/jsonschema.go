@@ -0,0 +1,361 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultJSONSampleRows is how many rows infer_json_schema reads unless asked
+	DefaultJSONSampleRows = 200
+
+	// DefaultJSONDepth is how deep infer_json_schema follows nested values
+	// unless asked
+	DefaultJSONDepth = 5
+
+	// maxJSONDepth caps the depth argument
+	maxJSONDepth = 10
+
+	// maxJSONKeys caps the distinct keys kept per object, so objects keyed
+	// by data (IDs, dates) do not flood the report
+	maxJSONKeys = 100
+)
+
+// jsonKeyPattern matches object keys usable unquoted in a JSON path.
+var jsonKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPath returns the JSON path of keys as MySQL and SQLite write it,
+// e.g. $.address."zip code".
+func jsonPath(keys []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, key := range keys {
+		b.WriteString(".")
+		if jsonKeyPattern.MatchString(key) {
+			b.WriteString(key)
+		} else {
+			b.WriteString(`"` + strings.ReplaceAll(key, `"`, `\"`) + `"`)
+		}
+	}
+	return b.String()
+}
+
+// jsonNode collects the values seen at one path.
+type jsonNode struct {
+	count int
+	types map[string]int
+	keys  map[string]*jsonNode
+	// keyOrder is the order keys were first seen in
+	keyOrder []string
+	items    *jsonNode
+	// keysTruncated is set when an object had more than maxJSONKeys keys
+	keysTruncated bool
+	// deeper is set when values below maxDepth were not followed
+	deeper bool
+}
+
+func newJSONNode() *jsonNode {
+	return &jsonNode{types: map[string]int{}}
+}
+
+// add records value, decoded with json.Decoder.UseNumber, at depth.
+func (n *jsonNode) add(value any, depth, maxDepth int) {
+	n.count++
+	switch v := value.(type) {
+	case nil:
+		n.types["null"]++
+	case bool:
+		n.types["boolean"]++
+	case string:
+		n.types["string"]++
+	case json.Number:
+		if strings.ContainsAny(v.String(), ".eE") {
+			n.types["number"]++
+		} else {
+			n.types["integer"]++
+		}
+	case map[string]any:
+		n.types["object"]++
+		if depth >= maxDepth {
+			n.deeper = n.deeper || len(v) > 0
+			return
+		}
+		if n.keys == nil {
+			n.keys = map[string]*jsonNode{}
+		}
+		// Visit keys in a stable order, as maps have none
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, ok := n.keys[key]
+			if !ok {
+				if len(n.keys) >= maxJSONKeys {
+					n.keysTruncated = true
+					continue
+				}
+				child = newJSONNode()
+				n.keys[key] = child
+				n.keyOrder = append(n.keyOrder, key)
+			}
+			child.add(v[key], depth+1, maxDepth)
+		}
+	case []any:
+		n.types["array"]++
+		if depth >= maxDepth {
+			n.deeper = n.deeper || len(v) > 0
+			return
+		}
+		if n.items == nil {
+			n.items = newJSONNode()
+		}
+		for _, item := range v {
+			n.items.add(item, depth+1, maxDepth)
+		}
+	}
+}
+
+// jsonPathReport describes the values found at one path.
+type jsonPathReport struct {
+	// Path is the JSON path, with [*] standing for every array element
+	Path string `json:"path"`
+	// Types counts the values of each JSON type (object, array, string,
+	// integer, number, boolean, null)
+	Types map[string]int `json:"types"`
+	// PresentPercent is the share of the enclosing objects having the key
+	PresentPercent *float64 `json:"present_percent,omitempty"`
+	// Expression reads the value as text in the database's dialect; paths
+	// through arrays have none
+	Expression string `json:"expression,omitempty"`
+	// KeysTruncated is set when objects here had more distinct keys than
+	// were kept
+	KeysTruncated bool `json:"keys_truncated,omitempty"`
+	// Deeper is set when nested values here were past the depth limit
+	Deeper bool `json:"deeper,omitempty"`
+}
+
+// jsonSchemaReport is the infer_json_schema result.
+type jsonSchemaReport struct {
+	Table       string           `json:"table"`
+	Column      string           `json:"column"`
+	RowsSampled int              `json:"rows_sampled"`
+	NullRows    int              `json:"null_rows"`
+	InvalidRows int              `json:"invalid_rows"`
+	MaxDepth    int              `json:"max_depth"`
+	Paths       []jsonPathReport `json:"paths"`
+}
+
+// jsonPaths flattens the tree below n into reports, parents first. keys is the
+// object key path to n, or nil once the path has gone through an array.
+func (s *Server) jsonPaths(n *jsonNode, path string, keys []string, column string, parentObjects int, out []jsonPathReport) []jsonPathReport {
+	report := jsonPathReport{Path: path, Types: n.types, KeysTruncated: n.keysTruncated, Deeper: n.deeper}
+	if parentObjects > 0 {
+		percent := float64(int(float64(n.count)/float64(parentObjects)*1000+0.5)) / 10
+		report.PresentPercent = &percent
+	}
+	if len(keys) > 0 {
+		report.Expression = s.adapter.JSONExtract(column, keys)
+	}
+	out = append(out, report)
+
+	for _, key := range n.keyOrder {
+		var childKeys []string
+		if keys != nil {
+			childKeys = append(append([]string{}, keys...), key)
+		}
+		out = s.jsonPaths(n.keys[key], path+jsonPath([]string{key})[1:], childKeys, column, n.types["object"], out)
+	}
+	if n.items != nil {
+		out = s.jsonPaths(n.items, path+"[*]", nil, column, 0, out)
+	}
+	return out
+}
+
+// inferJSONSchemaTool describes infer_json_schema.
+func inferJSONSchemaTool() Tool {
+	return Tool{
+		Name: "infer_json_schema",
+		Description: "Sample a JSON column and report the paths found in its values with their types and how often they occur, " +
+			"each with an expression reading it in this database's SQL, to write JSON paths without trial and error",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"table": {
+					Type:        "string",
+					Description: "The table holding the column",
+				},
+				"column": {
+					Type:        "string",
+					Description: "The JSON, JSONB or text column holding JSON documents",
+				},
+				"sample": {
+					Type:        "integer",
+					Description: fmt.Sprintf("Number of random rows to read (default %d)", DefaultJSONSampleRows),
+				},
+				"max_depth": {
+					Type:        "integer",
+					Description: fmt.Sprintf("How many levels of nesting to follow (default %d, at most %d)", DefaultJSONDepth, maxJSONDepth),
+				},
+			},
+			Required: []string{"table", "column"},
+		},
+	}
+}
+
+// inferJSONSchema samples a JSON column and reports the structure of its
+// values. Only the structure is returned, never the values themselves.
+func (s *Server) inferJSONSchema(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	table, _ := args["table"].(string)
+	column, _ := args["column"].(string)
+	switch {
+	case table == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'table' parameter"}
+	case column == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'column' parameter"}
+	}
+	sample, rpcErr := intArg(args, "sample", DefaultJSONSampleRows, 1, math.MaxInt)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	sample = min(sample, tableMaxRows(table))
+	maxDepth, rpcErr := intArg(args, "max_depth", DefaultJSONDepth, 1, math.MaxInt)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	maxDepth = min(maxDepth, maxJSONDepth)
+
+	// Answer as if the table did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Table not found: %s", table)}},
+			IsError: true,
+		}, nil
+	}
+	// The structure of masked values is as private as the values
+	if masks := columnMasks(MaskRules, table, []string{column}); masks != nil && masks[0] != "" {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Column %s is masked; its structure cannot be inferred", column)}},
+			IsError: true,
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	sampleSQL, err := s.adapter.SampleQuery(ctx, s.db, s.databaseName, s.resolveName(table), sample)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	quoted := s.quoteName(column)
+	sqlQuery := fmt.Sprintf("SELECT sampled.%s FROM (%s) AS sampled", quoted, sampleSQL)
+
+	// The generated query is checked like any other, as defense in depth
+	validated, err := s.validateQuery(sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	if qerr := s.quota.allow(time.Now()); qerr != nil {
+		stats.queriesRejected.Add(1)
+		return quotaExceeded(qerr), nil
+	}
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	start := time.Now()
+	report, err := s.sampleJSON(ctx, validated, quoted, maxDepth)
+	if err != nil {
+		s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeError, 0, time.Since(start), err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeOK, report.RowsSampled, time.Since(start), "")
+	report.Table, report.Column = table, column
+	return statementReportResult(report)
+}
+
+// sampleJSON runs the validated sqlQuery, which selects one JSON column,
+// and builds the report of the values' structure.
+func (s *Server) sampleJSON(ctx context.Context, sqlQuery, column string, maxDepth int) (*jsonSchemaReport, error) {
+	rows, done, err := s.beginQuery(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	report := &jsonSchemaReport{MaxDepth: maxDepth, Paths: []jsonPathReport{}}
+	root := newJSONNode()
+	for rows.Next() {
+		var val any
+		if err := rows.Scan(&val); err != nil {
+			return nil, fmt.Errorf("failed to scan row %d: %w", report.RowsSampled+1, err)
+		}
+		report.RowsSampled++
+
+		value, ok := decodeJSONValue(textValue(s.charset, val))
+		switch {
+		case val == nil:
+			report.NullRows++
+		case !ok:
+			report.InvalidRows++
+		default:
+			root.add(value, 0, maxDepth)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if root.count > 0 {
+		report.Paths = s.jsonPaths(root, "$", []string{}, column, 0, report.Paths)
+	}
+	return report, nil
+}
+
+// decodeJSONValue decodes a scanned JSON document, which drivers return as
+// text or, for some JSON types, already decoded.
+func decodeJSONValue(val any) (any, bool) {
+	var data []byte
+	switch v := val.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	case map[string]any, []any:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil, false
+		}
+		data = encoded
+	default:
+		return nil, false
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var value any
+	if err := dec.Decode(&value); err != nil || dec.More() {
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,96 @@
+package mcpsqldb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestJSONExtract(t *testing.T) {
+	keys := []string{"user", "zip code"}
+	tests := []struct {
+		adapter DBAdapter
+		want    string
+	}{
+		{&MySQLAdapter{}, "`doc`->>'$.user.\"zip code\"'"},
+		{&PostgresAdapter{}, `"doc"->'user'->>'zip code'`},
+		{&SQLiteAdapter{}, `json_extract("doc", '$.user."zip code"')`},
+	}
+	for _, tc := range tests {
+		if got := tc.adapter.JSONExtract(tc.adapter.QuoteIdentifier("doc"), keys); got != tc.want {
+			t.Errorf("%s: expected %s, got %s", tc.adapter.DriverName(), tc.want, got)
+		}
+	}
+	if got := jsonPath([]string{`a"b`, "c"}); got != `$."a\"b".c` {
+		t.Errorf("Expected the quote to be escaped, got %s", got)
+	}
+}
+
+func newJSONServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, payload TEXT)",
+		`INSERT INTO events (payload) VALUES
+			('{"user": {"name": "alice", "age": 30}, "tags": ["a", "b"], "score": 1.5}'),
+			('{"user": {"name": "bob"}, "tags": [], "score": 2}'),
+			('{"user": {"name": "carol", "age": null}, "deep": {"a": {"b": {"c": 1}}}}'),
+			('not json'),
+			(NULL)`)
+}
+
+func TestInferJSONSchema(t *testing.T) {
+	report := callToolJSON[jsonSchemaReport](t, newJSONServer(t), "infer_json_schema", map[string]any{"table": "events", "column": "payload", "max_depth": float64(2)})
+	if report.RowsSampled != 5 || report.NullRows != 1 || report.InvalidRows != 1 {
+		t.Errorf("Expected 5 rows with 1 null and 1 invalid, got %+v", report)
+	}
+
+	paths := map[string]jsonPathReport{}
+	for _, p := range report.Paths {
+		paths[p.Path] = p
+	}
+	if root := paths["$"]; root.Types["object"] != 3 || root.PresentPercent != nil || root.Expression != "" {
+		t.Errorf("Expected 3 objects at the root, got %+v", root)
+	}
+	name := paths["$.user.name"]
+	if name.Types["string"] != 3 || name.PresentPercent == nil || *name.PresentPercent != 100 ||
+		name.Expression != `json_extract("payload", '$.user.name')` {
+		t.Errorf("Expected user.name to be a string in every row, got %+v", name)
+	}
+	age := paths["$.user.age"]
+	if age.Types["integer"] != 1 || age.Types["null"] != 1 || age.PresentPercent == nil || *age.PresentPercent != 66.7 {
+		t.Errorf("Expected user.age in 2 of 3 users, got %+v", age)
+	}
+	if score := paths["$.score"]; score.Types["number"] != 1 || score.Types["integer"] != 1 {
+		t.Errorf("Expected a number and an integer score, got %+v", score)
+	}
+	if items := paths["$.tags[*]"]; items.Types["string"] != 2 || items.Expression != "" {
+		t.Errorf("Expected 2 string tags without an expression, got %+v", items)
+	}
+	if a, ok := paths["$.deep.a"]; !ok || !a.Deeper {
+		t.Errorf("Expected deep.a to be cut off at depth 2, got %+v", a)
+	}
+	if _, ok := paths["$.deep.a.b"]; ok {
+		t.Error("Expected nothing below the depth limit")
+	}
+}
+
+func TestInferJSONSchema_Restricted(t *testing.T) {
+	server := newJSONServer(t)
+	ctx := context.Background()
+
+	defer func(tables []string, rules []maskRule) { DeniedTables, MaskRules = tables, rules }(DeniedTables, MaskRules)
+	MaskRules, _ = parseMaskRules("events.payload=hash")
+	result, _ := server.callTool(ctx, "infer_json_schema", map[string]any{"table": "events", "column": "payload"})
+	if !result.IsError || result.Content[0].Text != "Column payload is masked; its structure cannot be inferred" {
+		t.Errorf("Expected a masked column to be refused, got %+v", result)
+	}
+
+	DeniedTables = []string{"events"}
+	result, _ = server.callTool(ctx, "infer_json_schema", map[string]any{"table": "events", "column": "payload"})
+	if !result.IsError || result.Content[0].Text != "Table not found: events" {
+		t.Errorf("Expected the denied table to be reported as missing, got %+v", result)
+	}
+
+	if _, rpcErr := server.callTool(ctx, "infer_json_schema", map[string]any{"table": "events", "column": "payload", "sample": float64(0)}); rpcErr == nil {
+		t.Error("Expected an invalid sample size to be rejected")
+	}
+}
@@ -0,0 +1,52 @@
+package mcpsqldb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// KeepaliveInterval is how often the server pings the client, so
+// intermediaries keep the session open and a vanished client is noticed
+// when the write fails; 0 disables pings (overridable via
+// MCP_KEEPALIVE_INTERVAL env var, in seconds)
+var KeepaliveInterval time.Duration
+
+// IdleTimeout closes a session, releasing its database connections, after
+// this long without any message from the client; answers to keepalive pings
+// count as activity. 0 disables it (overridable via MCP_IDLE_TIMEOUT env
+// var, in seconds)
+var IdleTimeout time.Duration
+
+// ErrIdleTimeout is returned by Serve when the session was closed by
+// IdleTimeout.
+var ErrIdleTimeout = errors.New("session closed after idle timeout")
+
+// keepalivePing builds the nth keepalive ping request.
+func keepalivePing(n int) *JSONRPCRequest {
+	return &JSONRPCRequest{
+		JSONRPC: "2.0",
+		ID:      fmt.Sprintf("keepalive-%d", n),
+		Method:  "ping",
+	}
+}
+
+// newIdleTimer returns a timer firing after IdleTimeout, or nil when idle
+// sessions are kept open, and the channel to wait on (nil never fires).
+func newIdleTimer() (*time.Timer, <-chan time.Time) {
+	if IdleTimeout <= 0 {
+		return nil, nil
+	}
+	timer := time.NewTimer(IdleTimeout)
+	return timer, timer.C
+}
+
+// newKeepaliveTicker returns a ticker firing every KeepaliveInterval, or nil
+// when pings are disabled, and the channel to wait on (nil never fires).
+func newKeepaliveTicker() (*time.Ticker, <-chan time.Time) {
+	if KeepaliveInterval <= 0 {
+		return nil, nil
+	}
+	ticker := time.NewTicker(KeepaliveInterval)
+	return ticker, ticker.C
+}
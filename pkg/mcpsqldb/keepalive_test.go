@@ -0,0 +1,107 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+// serveAsync runs serve over pipes, returning the client's ends and the
+// channel serve's result arrives on.
+func serveAsync(t *testing.T, server *Server) (*io.PipeWriter, *bufio.Scanner, <-chan error) {
+	t.Helper()
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serve(inR, outW)
+		outW.Close()
+	}()
+	t.Cleanup(func() { inW.Close(); outR.Close() })
+	return inW, bufio.NewScanner(outR), done
+}
+
+func TestServe_IdleTimeoutClosesSession(t *testing.T) {
+	defer func(orig time.Duration) { IdleTimeout = orig }(IdleTimeout)
+	IdleTimeout = 100 * time.Millisecond
+	server := newTestServer(t)
+	in, out, done := serveAsync(t, server)
+
+	// Activity keeps the session open past the timeout
+	for i := 0; i < 5; i++ {
+		io.WriteString(in, `{"jsonrpc":"2.0","id":1,"method":"ping"}`+"\n")
+		if !out.Scan() {
+			t.Fatal("Expected a response while active")
+		}
+		time.Sleep(40 * time.Millisecond)
+	}
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrIdleTimeout) {
+			t.Errorf("Expected ErrIdleTimeout, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the idle session to be closed")
+	}
+	if server.ctx.Err() == nil {
+		t.Error("Expected the server context to be cancelled")
+	}
+}
+
+func TestServe_KeepalivePings(t *testing.T) {
+	defer func(orig time.Duration) { KeepaliveInterval = orig }(KeepaliveInterval)
+	KeepaliveInterval = 10 * time.Millisecond
+	server := newTestServer(t)
+	in, out, _ := serveAsync(t, server)
+
+	if !out.Scan() {
+		t.Fatal("Expected a keepalive ping")
+	}
+	var ping JSONRPCRequest
+	if err := json.Unmarshal(out.Bytes(), &ping); err != nil || ping.Method != "ping" || ping.ID != "keepalive-1" {
+		t.Fatalf("Expected ping keepalive-1, got %s", out.Text())
+	}
+
+	// The client's answer is accepted without a response of its own
+	io.WriteString(in, `{"jsonrpc":"2.0","id":"keepalive-1","result":{}}`+"\n")
+	io.WriteString(in, `{"jsonrpc":"2.0","id":7,"method":"ping"}`+"\n")
+	for out.Scan() {
+		var msg map[string]any
+		json.Unmarshal(out.Bytes(), &msg)
+		if _, isPing := msg["method"]; isPing {
+			continue
+		}
+		if msg["id"] != float64(7) {
+			t.Errorf("Expected only the response to request 7, got %s", out.Text())
+		}
+		break
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func TestServe_KeepaliveWriteFailureEndsSession(t *testing.T) {
+	defer func(orig time.Duration) { KeepaliveInterval = orig }(KeepaliveInterval)
+	KeepaliveInterval = 10 * time.Millisecond
+	server := newTestServer(t)
+
+	inR, inW := io.Pipe()
+	defer inW.Close()
+	done := make(chan error, 1)
+	go func() { done <- server.serve(inR, failingWriter{}) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected a clean exit for a vanished client, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected a failed ping to end the session")
+	}
+}
@@ -0,0 +1,150 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/lib/pq"
+)
+
+// PostgresGSSAPI lets Postgres connect without a password, authenticating
+// with Kerberos instead (overridable via MCP_PG_GSSAPI env var)
+var PostgresGSSAPI = false
+
+// KerberosKeytab logs in as MCP_PG_USER from a keytab instead of using the
+// credential cache (overridable via MCP_KRB5_KEYTAB env var)
+var KerberosKeytab string
+
+func init() {
+	pq.RegisterGSSProvider(func() (pq.GSS, error) { return newKerberosGSS() })
+	pgconn.RegisterGSSProvider(func() (pgconn.GSS, error) {
+		gss, err := newKerberosGSS()
+		if err != nil {
+			return nil, err
+		}
+		return pgxGSS{gss}, nil
+	})
+}
+
+// kerberosGSS implements pq.GSS, and through pgxGSS pgconn.GSS, on top of
+// gokrb5. The drivers only call it when the server requests GSSAPI
+// authentication.
+type kerberosGSS struct {
+	cli *client.Client
+}
+
+// newKerberosGSS logs in with KerberosKeytab when set, otherwise with the
+// ticket cache named by KRB5CCNAME (default /tmp/krb5cc_<uid>), as kinit
+// leaves it. The realm configuration is read from KRB5_CONFIG or
+// /etc/krb5.conf.
+func newKerberosGSS() (*kerberosGSS, error) {
+	cfgPath := os.Getenv("KRB5_CONFIG")
+	if cfgPath == "" {
+		cfgPath = "/etc/krb5.conf"
+	}
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kerberos config %s: %w", cfgPath, err)
+	}
+
+	var cli *client.Client
+	if KerberosKeytab != "" {
+		kt, err := keytab.Load(KerberosKeytab)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load keytab %s: %w", KerberosKeytab, err)
+		}
+		principal, realm := splitPrincipal(os.Getenv("MCP_PG_USER"), cfg.LibDefaults.DefaultRealm)
+		cli = client.NewWithKeytab(principal, realm, kt, cfg, client.DisablePAFXFAST(true))
+	} else {
+		path, err := credentialCachePath()
+		if err != nil {
+			return nil, err
+		}
+		ccache, err := credentials.LoadCCache(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Kerberos credential cache %s (run kinit or set MCP_KRB5_KEYTAB): %w", path, err)
+		}
+		if cli, err = client.NewFromCCache(ccache, cfg, client.DisablePAFXFAST(true)); err != nil {
+			return nil, fmt.Errorf("invalid Kerberos credential cache %s: %w", path, err)
+		}
+	}
+
+	if err := cli.Login(); err != nil {
+		return nil, fmt.Errorf("Kerberos login failed: %w", err)
+	}
+	return &kerberosGSS{cli: cli}, nil
+}
+
+func credentialCachePath() (string, error) {
+	if name := os.Getenv("KRB5CCNAME"); name != "" {
+		kind, path, found := strings.Cut(name, ":")
+		if !found {
+			return name, nil
+		}
+		if kind != "FILE" {
+			return "", fmt.Errorf("unsupported KRB5CCNAME type %q (only FILE caches are supported)", kind)
+		}
+		return path, nil
+	}
+	u, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate Kerberos credential cache: %w", err)
+	}
+	return "/tmp/krb5cc_" + u.Uid, nil
+}
+
+// splitPrincipal splits "user@REALM", falling back to defaultRealm.
+func splitPrincipal(principal, defaultRealm string) (string, string) {
+	if name, realm, found := strings.Cut(principal, "@"); found {
+		return name, realm
+	}
+	return principal, defaultRealm
+}
+
+func (g *kerberosGSS) GetInitToken(host, service string) ([]byte, error) {
+	if g.cli.Config.LibDefaults.DNSCanonicalizeHostname {
+		if cname, err := net.LookupCNAME(host); err == nil {
+			host = strings.TrimSuffix(cname, ".")
+		}
+	}
+	return g.GetInitTokenFromSpn(service + "/" + host)
+}
+
+func (g *kerberosGSS) GetInitTokenFromSpn(spn string) ([]byte, error) {
+	token, err := spnego.SPNEGOClient(g.cli, spn).InitSecContext()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service ticket for %s: %w", spn, err)
+	}
+	return token.Marshal()
+}
+
+// Continue checks the server's final SPNEGO response; Kerberos completes in
+// a single round trip, so no further token is sent.
+func (g *kerberosGSS) Continue(inToken []byte) (bool, []byte, error) {
+	var token spnego.SPNEGOToken
+	if err := token.Unmarshal(inToken); err != nil {
+		return true, nil, fmt.Errorf("invalid GSSAPI response: %w", err)
+	}
+	if state := token.NegTokenResp.State(); state != spnego.NegStateAcceptCompleted {
+		return true, nil, fmt.Errorf("GSSAPI negotiation was not accepted (state %d)", state)
+	}
+	return true, nil, nil
+}
+
+// pgxGSS adapts kerberosGSS to pgconn.GSS, which spells SPN in capitals.
+type pgxGSS struct {
+	*kerberosGSS
+}
+
+func (g pgxGSS) GetInitTokenFromSPN(spn string) ([]byte, error) {
+	return g.GetInitTokenFromSpn(spn)
+}
@@ -0,0 +1,64 @@
+package mcpsqldb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestPostgresBuildDSN_GSSAPIWithoutPassword(t *testing.T) {
+	t.Setenv("MCP_PG_HOST", "db.corp.example.com")
+	t.Setenv("MCP_PG_PORT", "5432")
+	t.Setenv("MCP_PG_DB", "analytics")
+	t.Setenv("MCP_PG_USER", "mcp")
+	t.Setenv("MCP_PG_PASSWORD", "")
+	t.Setenv("MCP_PG_KRBSRVNAME", "pgsvc")
+	t.Setenv("MCP_PG_SSLMODE", "require")
+	adapter := &PostgresAdapter{}
+
+	if _, err := adapter.BuildDSN(); err == nil || !strings.Contains(err.Error(), "MCP_PG_PASSWORD") {
+		t.Errorf("Expected password to be required without GSSAPI, got %v", err)
+	}
+
+	defer func(orig bool) { PostgresGSSAPI = orig }(PostgresGSSAPI)
+	PostgresGSSAPI = true
+	dsn, err := adapter.BuildDSN()
+	if err != nil {
+		t.Fatalf("Expected DSN without password, got %v", err)
+	}
+	cfg, err := pq.NewConfig(dsn)
+	if err != nil {
+		t.Fatalf("Failed to parse DSN %q: %v", dsn, err)
+	}
+	if cfg.KrbSrvname != "pgsvc" || cfg.Password != "" {
+		t.Errorf("Expected krbsrvname pgsvc and no password, got %q / %q", cfg.KrbSrvname, cfg.Password)
+	}
+}
+
+func TestCredentialCachePath(t *testing.T) {
+	tests := map[string]string{
+		"FILE:/run/krb5cc_app": "/run/krb5cc_app",
+		"/tmp/krb5cc_1000":     "/tmp/krb5cc_1000",
+	}
+	for ccname, expected := range tests {
+		t.Setenv("KRB5CCNAME", ccname)
+		if got, err := credentialCachePath(); err != nil || got != expected {
+			t.Errorf("credentialCachePath(%q) = %q, %v; expected %q", ccname, got, err, expected)
+		}
+	}
+
+	t.Setenv("KRB5CCNAME", "KEYRING:persistent:1000")
+	if _, err := credentialCachePath(); err == nil {
+		t.Error("Expected error for unsupported cache type")
+	}
+}
+
+func TestSplitPrincipal(t *testing.T) {
+	if name, realm := splitPrincipal("mcp@CORP.EXAMPLE.COM", "DEFAULT"); name != "mcp" || realm != "CORP.EXAMPLE.COM" {
+		t.Errorf("Expected explicit realm, got %s@%s", name, realm)
+	}
+	if name, realm := splitPrincipal("mcp", "DEFAULT"); name != "mcp" || realm != "DEFAULT" {
+		t.Errorf("Expected default realm, got %s@%s", name, realm)
+	}
+}
@@ -0,0 +1,828 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxViewDepth bounds how deep view_lineage follows views built on views.
+const maxViewDepth = 10
+
+// lineageTokenPattern splits SQL that has had strings and comments removed
+// into identifiers (as identifierPattern matches them), numbers and
+// punctuation.
+var lineageTokenPattern = regexp.MustCompile(identifierPattern.String() + `|[0-9][0-9.]*|::|\S`)
+
+// lineageKeywords are the words view_lineage never takes for a table,
+// alias or column when they appear unquoted.
+var lineageKeywords = map[string]bool{}
+
+func init() {
+	for _, word := range strings.Fields(`ALL AND ANY AS ASC BETWEEN BY CASE CAST CROSS DESC DISTINCT ELSE END
+		EXCEPT EXISTS FALSE FETCH FILTER FIRST FROM FULL GROUP HAVING ILIKE IN INNER INTERSECT INTERVAL IS
+		JOIN LAST LATERAL LEFT LIKE LIMIT MATERIALIZED NATURAL NOT NULL NULLS OFFSET ON ONLY OR ORDER OUTER
+		OVER PARTITION RECURSIVE RIGHT ROW ROWS SELECT SOME STRAIGHT_JOIN THEN TRUE UNION USING VALUES WHEN
+		WHERE WINDOW WITH WITHIN`) {
+		lineageKeywords[word] = true
+	}
+}
+
+// viewLineage is the view_lineage result.
+type viewLineage struct {
+	View string `json:"view"`
+	// Columns traces each output column of the view to the base table
+	// columns it is computed from, through any views it reads
+	Columns []viewColumnLineage `json:"columns"`
+	// Reads lists the tables and views the definition reads directly
+	Reads      []viewRead `json:"reads"`
+	Unresolved []string   `json:"unresolved,omitempty"`
+	Hidden     int        `json:"hidden,omitempty"`
+}
+
+// viewColumnLineage is an output column of a view and its sources, as
+// table.column.
+type viewColumnLineage struct {
+	Name    string   `json:"name"`
+	Sources []string `json:"sources"`
+}
+
+// viewRead is a table or view a view reads, with the columns it reads.
+type viewRead struct {
+	Table   string   `json:"table"`
+	View    bool     `json:"view,omitempty"`
+	Columns []string `json:"columns"`
+}
+
+// viewLineageTool describes view_lineage.
+func viewLineageTool() Tool {
+	return Tool{
+		Name: "view_lineage",
+		Description: "Trace a view back to its data: parse the view's definition and report the tables and columns it reads " +
+			"and, for each of its columns, the base table columns it is computed from, following views built on other views",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"view": {
+					Type:        "string",
+					Description: "Name of the view",
+				},
+			},
+			Required: []string{"view"},
+		},
+	}
+}
+
+// viewLineage reports where the columns of a view come from. Definitions
+// are parsed, not planned, so expressions the parser cannot attribute to a
+// table are listed as unresolved rather than guessed.
+func (s *Server) viewLineage(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	view, _ := args["view"].(string)
+	if view == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'view' parameter",
+		}
+	}
+	notFound := &CallToolResult{
+		Content: []Content{{Type: "text", Text: fmt.Sprintf("View not found: %s", view)}},
+		IsError: true,
+	}
+	// Answer as if the view did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, view) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return notFound, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	definitions, err := s.adapter.ViewDefinitions(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	tables, err := s.adapter.DescribeSchema(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report, ok, err := newLineageAnalyzer(s.adapter, definitions, tables).report(view)
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+	if !ok {
+		return notFound, nil
+	}
+	return statementReportResult(report)
+}
+
+// columnRef is a column of a table or view; column is "*" for all of them.
+type columnRef struct {
+	table  string
+	column string
+}
+
+func (r columnRef) String() string {
+	return r.table + "." + r.column
+}
+
+// outputColumn is a column of a view's result and the columns it is
+// computed from.
+type outputColumn struct {
+	name    string
+	sources []columnRef
+}
+
+// parsedView is what a view's definition reads and returns.
+type parsedView struct {
+	columns    []outputColumn
+	reads      []columnRef
+	unresolved []string
+}
+
+// lineageAnalyzer parses view definitions, resolving unqualified columns
+// against the tables' columns and other views' results.
+type lineageAnalyzer struct {
+	adapter DBAdapter
+	// definitions and names are keyed by lowercased view name
+	definitions map[string]string
+	names       map[string]string
+	// columns maps lowercased table names to their lowercased columns
+	columns map[string]map[string]bool
+	parsed  map[string]*parsedView
+}
+
+func newLineageAnalyzer(adapter DBAdapter, definitions map[string]string, tables []TableInfo) *lineageAnalyzer {
+	a := &lineageAnalyzer{
+		adapter:     adapter,
+		definitions: map[string]string{},
+		names:       map[string]string{},
+		columns:     map[string]map[string]bool{},
+		parsed:      map[string]*parsedView{},
+	}
+	for name, definition := range definitions {
+		a.definitions[strings.ToLower(name)] = definition
+		a.names[strings.ToLower(name)] = name
+	}
+	for _, table := range tables {
+		columns := map[string]bool{}
+		for _, col := range table.Columns {
+			columns[strings.ToLower(col.Name)] = true
+		}
+		a.columns[strings.ToLower(table.Name)] = columns
+	}
+	return a
+}
+
+// isView reports whether name is a view of the database.
+func (a *lineageAnalyzer) isView(name string) bool {
+	_, ok := a.definitions[strings.ToLower(name)]
+	return ok
+}
+
+// hasColumn reports whether table (or view) has the column, and whether
+// its columns are known at all.
+func (a *lineageAnalyzer) hasColumn(table, column string) (has, known bool) {
+	if columns, ok := a.columns[strings.ToLower(table)]; ok && len(columns) > 0 {
+		return columns[strings.ToLower(column)], true
+	}
+	if !a.isView(table) {
+		return false, false
+	}
+	for _, col := range a.parse(table).columns {
+		if col.name == "*" {
+			return false, false
+		}
+		if strings.EqualFold(col.name, column) {
+			has = true
+		}
+	}
+	return has, true
+}
+
+// parse parses a view's definition once. A view reached again while it is
+// being parsed (a cycle) reads as empty.
+func (a *lineageAnalyzer) parse(view string) *parsedView {
+	key := strings.ToLower(view)
+	if pv, ok := a.parsed[key]; ok {
+		return pv
+	}
+	pv := &parsedView{}
+	a.parsed[key] = pv
+
+	tokens := tokenizeSQL(a.adapter.RemoveStringsAndComments(a.definitions[key]))
+	p := &lineageParser{a: a, t: tokens}
+	pv.columns = p.parseQuery(0, len(tokens), nil)
+	pv.reads, pv.unresolved = p.reads, p.unresolved
+	return pv
+}
+
+// trace follows ref through the views it names down to base table columns.
+func (a *lineageAnalyzer) trace(ref columnRef, depth int) []columnRef {
+	if !a.isView(ref.table) || depth >= maxViewDepth {
+		return []columnRef{ref}
+	}
+	var traced []columnRef
+	found := false
+	for _, col := range a.parse(ref.table).columns {
+		if ref.column == "*" || strings.EqualFold(col.name, ref.column) {
+			found = true
+			for _, src := range col.sources {
+				traced = append(traced, a.trace(src, depth+1)...)
+			}
+		}
+	}
+	if found {
+		return traced
+	}
+	// The column may come from a SELECT * of the view
+	for _, col := range a.parse(ref.table).columns {
+		if col.name != "*" {
+			continue
+		}
+		for _, src := range col.sources {
+			if has, known := a.hasColumn(src.table, ref.column); has || !known {
+				traced = append(traced, a.trace(columnRef{table: src.table, column: ref.column}, depth+1)...)
+			}
+		}
+	}
+	if len(traced) == 0 {
+		return []columnRef{ref}
+	}
+	return traced
+}
+
+// report builds the view_lineage result for view, with denied tables left
+// out, or reports false when there is no such view.
+func (a *lineageAnalyzer) report(view string) (*viewLineage, bool, error) {
+	key := strings.ToLower(view)
+	definition, ok := a.definitions[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if strings.TrimSpace(definition) == "" {
+		return nil, true, fmt.Errorf("the definition of view %s is not visible to this account", a.names[key])
+	}
+
+	pv := a.parse(view)
+	report := &viewLineage{
+		View:       a.names[key],
+		Columns:    []viewColumnLineage{},
+		Reads:      []viewRead{},
+		Unresolved: pv.unresolved,
+	}
+	hidden := map[string]bool{}
+	allowed := func(table string) bool {
+		if isTableDenied(DeniedTables, table) {
+			hidden[strings.ToLower(table)] = true
+			return false
+		}
+		return true
+	}
+
+	for _, col := range pv.columns {
+		lineage := viewColumnLineage{Name: col.name, Sources: []string{}}
+		seen := map[string]bool{}
+		for _, src := range col.sources {
+			for _, base := range a.trace(src, 1) {
+				if name := base.String(); allowed(base.table) && !seen[strings.ToLower(name)] {
+					seen[strings.ToLower(name)] = true
+					lineage.Sources = append(lineage.Sources, name)
+				}
+			}
+		}
+		report.Columns = append(report.Columns, lineage)
+	}
+
+	reads := map[string]*viewRead{}
+	for _, ref := range pv.reads {
+		if !allowed(ref.table) {
+			continue
+		}
+		read, ok := reads[strings.ToLower(ref.table)]
+		if !ok {
+			read = &viewRead{Table: ref.table, View: a.isView(ref.table), Columns: []string{}}
+			reads[strings.ToLower(ref.table)] = read
+		}
+		if ref.column != "" && !containsFold(read.Columns, ref.column) {
+			read.Columns = append(read.Columns, ref.column)
+		}
+	}
+	for _, read := range reads {
+		sort.Strings(read.Columns)
+		report.Reads = append(report.Reads, *read)
+	}
+	sort.Slice(report.Reads, func(i, j int) bool { return report.Reads[i].Table < report.Reads[j].Table })
+	report.Hidden = len(hidden)
+	return report, true, nil
+}
+
+// containsFold reports whether list holds s, ignoring case.
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineageToken is an identifier, number or punctuation mark of SQL.
+type lineageToken struct {
+	text   string
+	ident  bool
+	quoted bool
+}
+
+// tokenizeSQL splits cleanedSQL into tokens, unquoting identifiers.
+func tokenizeSQL(cleanedSQL string) []lineageToken {
+	var tokens []lineageToken
+	for _, m := range lineageTokenPattern.FindAllStringSubmatch(cleanedSQL, -1) {
+		if name := firstIdentifier(m[1:5]); name != "" {
+			tokens = append(tokens, lineageToken{text: name, ident: true, quoted: m[4] == ""})
+		} else {
+			tokens = append(tokens, lineageToken{text: m[0]})
+		}
+	}
+	return tokens
+}
+
+// lineageSource is a FROM clause item: a table or view, or a subquery or
+// CTE with its output columns.
+type lineageSource struct {
+	table   string
+	columns []outputColumn
+}
+
+// lineageScope holds the FROM clause items visible to one SELECT, and the
+// CTEs visible to the query it is part of. Subqueries see their parents'.
+type lineageScope struct {
+	parent  *lineageScope
+	sources map[string]*lineageSource
+	list    []*lineageSource
+	ctes    map[string]*lineageSource
+	outputs map[string]bool
+}
+
+func newLineageScope(parent *lineageScope) *lineageScope {
+	return &lineageScope{
+		parent:  parent,
+		sources: map[string]*lineageSource{},
+		ctes:    map[string]*lineageSource{},
+		outputs: map[string]bool{},
+	}
+}
+
+// source returns the FROM clause item named name in scope or its parents.
+func (sc *lineageScope) source(name string) *lineageSource {
+	for ; sc != nil; sc = sc.parent {
+		if src, ok := sc.sources[strings.ToLower(name)]; ok {
+			return src
+		}
+	}
+	return nil
+}
+
+// cte returns the CTE named name in scope or its parents.
+func (sc *lineageScope) cte(name string) *lineageSource {
+	for ; sc != nil; sc = sc.parent {
+		if src, ok := sc.ctes[strings.ToLower(name)]; ok {
+			return src
+		}
+	}
+	return nil
+}
+
+// clauseKeywords end a SELECT's select list or FROM clause.
+var clauseKeywords = []string{"FROM", "WHERE", "GROUP", "HAVING", "WINDOW", "ORDER", "LIMIT", "OFFSET", "FETCH"}
+
+// joinKeywords separate FROM clause items.
+var joinKeywords = map[string]bool{
+	"JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true,
+	"CROSS": true, "NATURAL": true, "STRAIGHT_JOIN": true, "LATERAL": true, "ONLY": true,
+}
+
+// lineageParser parses a view definition scope by scope, resolving each
+// column reference to the table it reads. It follows FROM and JOIN
+// clauses, aliases, subqueries, CTEs and set operations, but not the full
+// grammar of any dialect.
+type lineageParser struct {
+	a          *lineageAnalyzer
+	t          []lineageToken
+	reads      []columnRef
+	unresolved []string
+}
+
+func (p *lineageParser) is(i int, text string) bool {
+	return i >= 0 && i < len(p.t) && !p.t[i].ident && p.t[i].text == text
+}
+
+func (p *lineageParser) isKeyword(i int) bool {
+	return i >= 0 && i < len(p.t) && p.t[i].ident && !p.t[i].quoted && lineageKeywords[strings.ToUpper(p.t[i].text)]
+}
+
+func (p *lineageParser) keyword(i int, words ...string) bool {
+	if !p.isKeyword(i) {
+		return false
+	}
+	for _, word := range words {
+		if strings.EqualFold(p.t[i].text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// isName reports whether token i is an identifier other than a keyword.
+func (p *lineageParser) isName(i int) bool {
+	return i >= 0 && i < len(p.t) && p.t[i].ident && !p.isKeyword(i)
+}
+
+// isSubquery reports whether the parenthesis at i opens a query.
+func (p *lineageParser) isSubquery(i int) bool {
+	return p.is(i, "(") && p.keyword(i+1, "SELECT", "WITH", "VALUES")
+}
+
+// closing returns the index of the parenthesis closing the one at i.
+func (p *lineageParser) closing(i int) int {
+	depth := 0
+	for ; i < len(p.t); i++ {
+		switch {
+		case p.is(i, "("):
+			depth++
+		case p.is(i, ")"):
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.t) - 1
+}
+
+// next returns the index of the first of words outside parentheses
+// between start and end, or end.
+func (p *lineageParser) next(start, end int, words ...string) int {
+	for i := start; i < end; i++ {
+		switch {
+		case p.is(i, "("):
+			i = p.closing(i)
+		case p.keyword(i, words...):
+			return i
+		}
+	}
+	return end
+}
+
+// parseQuery parses the query between tokens start and end: an optional
+// WITH clause, then SELECTs combined by set operations. Each output column
+// has the sources of every branch.
+func (p *lineageParser) parseQuery(start, end int, parent *lineageScope) []outputColumn {
+	scope := newLineageScope(parent)
+	i := start
+	if p.keyword(i, "WITH") {
+		i++
+		if p.keyword(i, "RECURSIVE") {
+			i++
+		}
+		for p.isName(i) {
+			cte := &lineageSource{}
+			scope.ctes[strings.ToLower(p.t[i].text)] = cte
+			i++
+			if p.is(i, "(") {
+				i = p.closing(i) + 1
+			}
+			for p.keyword(i, "AS", "NOT", "MATERIALIZED") {
+				i++
+			}
+			if !p.is(i, "(") {
+				break
+			}
+			closing := p.closing(i)
+			cte.columns = p.parseQuery(i+1, closing, scope)
+			if i = closing + 1; !p.is(i, ",") {
+				break
+			}
+			i++
+		}
+	}
+
+	var columns []outputColumn
+	for i < end {
+		branchEnd := p.next(i, end, "UNION", "INTERSECT", "EXCEPT")
+		branch := p.parseSelect(i, branchEnd, scope)
+		if columns == nil {
+			columns = branch
+		} else {
+			for k := range columns {
+				if k < len(branch) {
+					columns[k].sources = append(columns[k].sources, branch[k].sources...)
+				}
+			}
+		}
+		i = branchEnd + 1
+		for p.keyword(i, "ALL", "DISTINCT") {
+			i++
+		}
+	}
+	return columns
+}
+
+// parseSelect parses one SELECT between start and end and returns its
+// output columns.
+func (p *lineageParser) parseSelect(start, end int, parent *lineageScope) []outputColumn {
+	if p.is(start, "(") {
+		closing := p.closing(start)
+		columns := p.parseQuery(start+1, closing, parent)
+		p.refs(closing+1, end, parent)
+		return columns
+	}
+	if !p.keyword(start, "SELECT") {
+		p.refs(start, end, parent)
+		return nil
+	}
+
+	scope := newLineageScope(parent)
+	i := start + 1
+	for p.keyword(i, "DISTINCT", "ALL") {
+		i++
+	}
+	if p.keyword(i, "ON") && p.is(i+1, "(") {
+		i = p.closing(i+1) + 1
+	}
+	listEnd := p.next(i, end, clauseKeywords...)
+	rest := listEnd
+	if p.keyword(listEnd, "FROM") {
+		rest = p.next(listEnd+1, end, clauseKeywords[1:]...)
+		p.parseFrom(listEnd+1, rest, scope)
+	}
+	columns := p.selectList(i, listEnd, scope)
+	p.refs(rest, end, scope)
+	return columns
+}
+
+// parseFrom adds the FROM clause items between start and end to scope.
+func (p *lineageParser) parseFrom(start, end int, scope *lineageScope) {
+	for i := start; i < end; {
+		switch {
+		case p.is(i, ",") || p.isKeyword(i) && joinKeywords[strings.ToUpper(p.t[i].text)]:
+			i++
+		case p.keyword(i, "ON"):
+			conditionEnd := p.nextJoin(i+1, end)
+			p.refs(i+1, conditionEnd, scope)
+			i = conditionEnd
+		case p.keyword(i, "USING") && p.is(i+1, "("):
+			closing := p.closing(i + 1)
+			p.refs(i+2, closing, scope)
+			i = closing + 1
+		case p.isSubquery(i):
+			closing := p.closing(i)
+			src := &lineageSource{columns: p.parseQuery(i+1, closing, scope)}
+			i = p.addSource(scope, src, "", closing+1)
+		case p.is(i, "("):
+			// MySQL writes joins in parentheses: FROM (a JOIN b ON ...)
+			closing := p.closing(i)
+			p.parseFrom(i+1, closing, scope)
+			i = closing + 1
+		case p.isName(i):
+			first := i
+			for p.is(i+1, ".") && p.isName(i+2) {
+				i += 2
+			}
+			name := p.t[i].text
+			i++
+			if p.is(i, "(") {
+				// A table function
+				i = p.addSource(scope, &lineageSource{}, "", p.closing(i)+1)
+				continue
+			}
+			src := scope.cte(name)
+			if src == nil || i-first > 1 {
+				src = &lineageSource{table: name}
+				p.reads = append(p.reads, columnRef{table: name})
+			}
+			i = p.addSource(scope, src, name, i)
+		default:
+			i++
+		}
+	}
+}
+
+// nextJoin returns the index of the next FROM clause item separator
+// outside parentheses between start and end, or end.
+func (p *lineageParser) nextJoin(start, end int) int {
+	for i := start; i < end; i++ {
+		switch {
+		case p.is(i, "("):
+			i = p.closing(i)
+		case p.is(i, ","), p.isKeyword(i) && joinKeywords[strings.ToUpper(p.t[i].text)]:
+			return i
+		}
+	}
+	return end
+}
+
+// addSource adds src to scope under the alias at i, if any, or name, and
+// returns the index after the alias.
+func (p *lineageParser) addSource(scope *lineageScope, src *lineageSource, name string, i int) int {
+	if p.keyword(i, "AS") {
+		i++
+	}
+	if p.isName(i) {
+		name = p.t[i].text
+		i++
+		if p.is(i, "(") {
+			// Column aliases: AS t(a, b)
+			i = p.closing(i) + 1
+		}
+	}
+	if name != "" {
+		scope.sources[strings.ToLower(name)] = src
+	}
+	scope.list = append(scope.list, src)
+	return i
+}
+
+// selectList parses the select list between start and end.
+func (p *lineageParser) selectList(start, end int, scope *lineageScope) []outputColumn {
+	var columns []outputColumn
+	for i := start; i < end; {
+		itemEnd := i
+		for itemEnd < end && !p.is(itemEnd, ",") {
+			if p.is(itemEnd, "(") {
+				itemEnd = p.closing(itemEnd)
+			}
+			itemEnd++
+		}
+		name, exprEnd := p.outputName(i, itemEnd)
+		if name == "" {
+			name = fmt.Sprintf("column%d", len(columns)+1)
+		}
+		scope.outputs[strings.ToLower(name)] = true
+		columns = append(columns, outputColumn{name: name, sources: p.refs(i, exprEnd, scope)})
+		i = itemEnd + 1
+	}
+	return columns
+}
+
+// outputName returns the name of the select list item from start to end,
+// and where its expression ends (before any alias).
+func (p *lineageParser) outputName(start, end int) (string, int) {
+	switch {
+	case end-start >= 2 && p.keyword(end-2, "AS"):
+		return p.t[end-1].text, end - 2
+	case p.is(end-1, "*"):
+		return "*", end
+	case p.isChain(start, end):
+		return p.t[end-1].text, end
+	case end-start >= 2 && p.isName(end-1) && (p.is(end-2, ")") || p.isName(end-2)):
+		// An alias without AS
+		return p.t[end-1].text, end - 1
+	}
+	return "", end
+}
+
+// isChain reports whether the tokens from start to end are a possibly
+// qualified name, e.g. o.amount.
+func (p *lineageParser) isChain(start, end int) bool {
+	if (end-start)%2 == 0 {
+		return false
+	}
+	for i := start; i < end; i++ {
+		if (i-start)%2 == 0 && !p.isName(i) || (i-start)%2 == 1 && !p.is(i, ".") {
+			return false
+		}
+	}
+	return true
+}
+
+// refs returns the columns referenced between tokens start and end,
+// recording them as read. Qualified references to unknown tables are
+// recorded as unresolved.
+func (p *lineageParser) refs(start, end int, scope *lineageScope) []columnRef {
+	var refs []columnRef
+	for i := start; i < end; i++ {
+		if p.isSubquery(i) {
+			closing := p.closing(i)
+			for _, col := range p.parseQuery(i+1, closing, scope) {
+				refs = append(refs, col.sources...)
+			}
+			i = closing
+			continue
+		}
+		if p.is(i, "*") && (i == start || p.is(i-1, ",")) {
+			refs = append(refs, p.star(scope)...)
+			continue
+		}
+		if !p.isName(i) || p.is(i-1, "::") || p.is(i-1, ".") || p.keyword(i-1, "AS") {
+			continue
+		}
+		first := i
+		for p.is(i+1, ".") && i+2 < end && (p.isName(i+2) || p.is(i+2, "*")) {
+			i += 2
+		}
+		if p.is(i+1, "(") {
+			// A function call
+			continue
+		}
+		column := p.t[i].text
+		if i == first {
+			refs = append(refs, p.unqualified(column, scope)...)
+			continue
+		}
+		qualifier := p.t[i-2].text
+		if src := scope.source(qualifier); src != nil {
+			refs = append(refs, p.columnOf(src, column)...)
+		} else if !containsFold(p.unresolved, qualifier+"."+column) {
+			p.unresolved = append(p.unresolved, qualifier+"."+column)
+		}
+	}
+	for _, ref := range refs {
+		if ref.table != "" {
+			p.reads = append(p.reads, ref)
+		}
+	}
+	return refs
+}
+
+// star returns the columns SELECT * reads from the items of scope.
+func (p *lineageParser) star(scope *lineageScope) []columnRef {
+	var refs []columnRef
+	for _, src := range scope.list {
+		refs = append(refs, p.columnOf(src, "*")...)
+	}
+	return refs
+}
+
+// columnOf returns the table columns behind column of src.
+func (p *lineageParser) columnOf(src *lineageSource, column string) []columnRef {
+	if src.table != "" {
+		return []columnRef{{table: src.table, column: column}}
+	}
+	var refs []columnRef
+	for _, col := range src.columns {
+		if column == "*" || strings.EqualFold(col.name, column) {
+			refs = append(refs, col.sources...)
+		}
+	}
+	if len(refs) > 0 || column == "*" {
+		return refs
+	}
+	// The column may come from a SELECT * of the subquery
+	for _, col := range src.columns {
+		if col.name != "*" {
+			continue
+		}
+		for _, star := range col.sources {
+			if has, known := p.a.hasColumn(star.table, column); has || !known {
+				refs = append(refs, columnRef{table: star.table, column: column})
+			}
+		}
+	}
+	return refs
+}
+
+// unqualified attributes a column named without a table to the nearest
+// scope's items that have it. When the only item is a table whose columns
+// are unknown, the column is taken to be its.
+func (p *lineageParser) unqualified(column string, scope *lineageScope) []columnRef {
+	for sc := scope; sc != nil; sc = sc.parent {
+		var refs []columnRef
+		for _, src := range sc.list {
+			if src.table != "" {
+				if has, _ := p.a.hasColumn(src.table, column); has {
+					refs = append(refs, columnRef{table: src.table, column: column})
+				}
+				continue
+			}
+			for _, col := range src.columns {
+				if strings.EqualFold(col.name, column) {
+					refs = append(refs, col.sources...)
+				}
+			}
+		}
+		if len(refs) > 0 {
+			return refs
+		}
+	}
+	if len(scope.list) == 1 && scope.list[0].table != "" && !scope.outputs[strings.ToLower(column)] {
+		if _, known := p.a.hasColumn(scope.list[0].table, column); !known {
+			return []columnRef{{table: scope.list[0].table, column: column}}
+		}
+	}
+	return nil
+}
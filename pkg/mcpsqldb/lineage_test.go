@@ -0,0 +1,118 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func newLineageTestServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT, region TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER, amount REAL)",
+		`CREATE VIEW customer_totals AS
+			SELECT c.name AS customer, region, SUM(o.amount) AS total, COUNT(*) AS orders
+			FROM customers c JOIN orders o ON o.customer_id = c.id
+			GROUP BY c.name, region`,
+		"CREATE VIEW big_customers AS SELECT customer, total AS spend FROM customer_totals WHERE total > 100",
+	)
+}
+
+func TestViewLineage_TracesColumns(t *testing.T) {
+	server := newLineageTestServer(t)
+
+	report := callToolJSON[viewLineage](t, server, "view_lineage", map[string]any{"view": "customer_totals"})
+	wantColumns := []viewColumnLineage{
+		{Name: "customer", Sources: []string{"customers.name"}},
+		{Name: "region", Sources: []string{"customers.region"}},
+		{Name: "total", Sources: []string{"orders.amount"}},
+		{Name: "orders", Sources: []string{}},
+	}
+	if !reflect.DeepEqual(report.Columns, wantColumns) {
+		t.Errorf("Expected columns %+v, got %+v", wantColumns, report.Columns)
+	}
+	wantReads := []viewRead{
+		{Table: "customers", Columns: []string{"id", "name", "region"}},
+		{Table: "orders", Columns: []string{"amount", "customer_id"}},
+	}
+	if !reflect.DeepEqual(report.Reads, wantReads) {
+		t.Errorf("Expected reads %+v, got %+v", wantReads, report.Reads)
+	}
+
+	// Through the view it is built on, down to the base tables
+	report = callToolJSON[viewLineage](t, server, "view_lineage", map[string]any{"view": "big_customers"})
+	wantColumns = []viewColumnLineage{
+		{Name: "customer", Sources: []string{"customers.name"}},
+		{Name: "spend", Sources: []string{"orders.amount"}},
+	}
+	if !reflect.DeepEqual(report.Columns, wantColumns) {
+		t.Errorf("Expected columns %+v, got %+v", wantColumns, report.Columns)
+	}
+	wantReads = []viewRead{{Table: "customer_totals", View: true, Columns: []string{"customer", "total"}}}
+	if !reflect.DeepEqual(report.Reads, wantReads) {
+		t.Errorf("Expected reads %+v, got %+v", wantReads, report.Reads)
+	}
+}
+
+func TestViewLineage_HidesDeniedTables(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	server := newLineageTestServer(t)
+	DeniedTables = []string{"orders"}
+
+	report := callToolJSON[viewLineage](t, server, "view_lineage", map[string]any{"view": "big_customers"})
+	if report.Hidden != 1 || len(report.Columns) != 2 || len(report.Columns[1].Sources) != 0 {
+		t.Errorf("Expected the orders source hidden, got %+v", report)
+	}
+
+	DeniedTables = []string{"big_*"}
+	result, _ := server.viewLineage(context.Background(), map[string]any{"view": "big_customers"})
+	if !result.IsError {
+		t.Error("Expected a denied view to be reported as not found")
+	}
+}
+
+func TestViewLineage_DialectDefinitions(t *testing.T) {
+	tables := []TableInfo{
+		{Name: "orders", Columns: []ColumnInfo{{Name: "id"}, {Name: "customer_id"}, {Name: "amount"}}},
+		{Name: "customers", Columns: []ColumnInfo{{Name: "id"}, {Name: "name"}}},
+	}
+	tests := []struct {
+		name       string
+		adapter    DBAdapter
+		definition string
+	}{
+		{"mysql", &MySQLAdapter{}, "select `c`.`name` AS `name`,sum(`o`.`amount`) AS `total` from (`shop`.`orders` `o` " +
+			"join `shop`.`customers` `c` on((`c`.`id` = `o`.`customer_id`))) group by `c`.`name`"},
+		{"postgres", &PostgresAdapter{}, " SELECT c.name,\n    sum(o.amount)::numeric AS total\n   FROM orders o\n" +
+			"     JOIN customers c ON c.id = o.customer_id\n  GROUP BY c.name;"},
+		{"subquery and cte", &SQLiteAdapter{}, "WITH recent AS (SELECT * FROM orders WHERE id > 10) " +
+			"SELECT t.name, t.total FROM (SELECT c.name, SUM(r.amount) AS total FROM customers c, recent r " +
+			"WHERE r.customer_id = c.id GROUP BY c.name) t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analyzer := newLineageAnalyzer(tt.adapter, map[string]string{"v": tt.definition}, tables)
+			report, ok, err := analyzer.report("v")
+			if !ok || err != nil {
+				t.Fatalf("Expected a report, got %v %v", ok, err)
+			}
+			if len(report.Columns) != 2 || report.Columns[0].Name != "name" || report.Columns[1].Name != "total" {
+				t.Fatalf("Expected name and total columns, got %+v", report.Columns)
+			}
+			if !reflect.DeepEqual(report.Columns[0].Sources, []string{"customers.name"}) {
+				t.Errorf("Expected name from customers.name, got %v", report.Columns[0].Sources)
+			}
+			if !reflect.DeepEqual(report.Columns[1].Sources, []string{"orders.amount"}) {
+				t.Errorf("Expected total from orders.amount, got %v", report.Columns[1].Sources)
+			}
+			tablesRead := map[string]bool{}
+			for _, read := range report.Reads {
+				tablesRead[read.Table] = true
+			}
+			if !tablesRead["orders"] || !tablesRead["customers"] {
+				t.Errorf("Expected orders and customers read, got %+v", report.Reads)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Log output formats accepted by MCP_LOG_FORMAT
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// setupLogging installs the default slog logger configured from MCP_LOG_LEVEL
+// (debug, info, warn, error) and MCP_LOG_FORMAT (text, json). Logs always go
+// to stderr because stdout carries the JSON-RPC stream.
+func setupLogging() {
+	logger, err := newLogger(os.Stderr, os.Getenv("MCP_LOG_LEVEL"), os.Getenv("MCP_LOG_FORMAT"))
+	slog.SetDefault(logger)
+	if err != nil {
+		slog.Warn("Invalid logging configuration, using defaults", "error", err)
+	}
+}
+
+// newLogger builds a logger for the given level and format, falling back to
+// info/text (and reporting why) for unrecognized values.
+func newLogger(w io.Writer, level, format string) (*slog.Logger, error) {
+	var errs []string
+
+	var lvl slog.Level
+	if level != "" {
+		if err := lvl.UnmarshalText([]byte(level)); err != nil {
+			errs = append(errs, fmt.Sprintf("MCP_LOG_LEVEL=%q", level))
+			lvl = slog.LevelInfo
+		}
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case "", LogFormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case LogFormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		errs = append(errs, fmt.Sprintf("MCP_LOG_FORMAT=%q", format))
+		handler = slog.NewTextHandler(w, opts)
+	}
+
+	logger := slog.New(&redactingHandler{Handler: handler}).With("component", "mcp-server")
+	if len(errs) > 0 {
+		return logger, fmt.Errorf("unsupported %s", strings.Join(errs, ", "))
+	}
+	return logger, nil
+}
+
+type loggerKey struct{}
+
+// withLogger returns a context carrying a request-scoped logger.
+func withLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// loggerFrom returns the request-scoped logger in ctx, or the default logger.
+func loggerFrom(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
@@ -0,0 +1,57 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger_JSONFormat(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "debug", "json")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	logger.Debug("Request handled", "request_id", 7, "method", "ping", "duration_ms", 3)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected JSON log line, got %q", buf.String())
+	}
+	for _, field := range []string{"request_id", "method", "duration_ms"} {
+		if _, ok := entry[field]; !ok {
+			t.Errorf("Expected field %q in %v", field, entry)
+		}
+	}
+}
+
+func TestNewLogger_LevelFilters(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "warn", "text")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	if strings.Contains(buf.String(), "hidden") {
+		t.Error("Info message should be filtered at warn level")
+	}
+	if !strings.Contains(buf.String(), "shown") {
+		t.Error("Warn message should be logged at warn level")
+	}
+}
+
+func TestNewLogger_InvalidConfigFallsBack(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := newLogger(&buf, "loud", "xml")
+	if err == nil {
+		t.Error("Expected error for invalid level and format")
+	}
+	if logger == nil {
+		t.Fatal("Expected fallback logger")
+	}
+}
@@ -0,0 +1,161 @@
+package mcpsqldb
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Masking strategies accepted in MCP_MASK_COLUMNS
+const (
+	MaskNull    = "null"    // replace the value with null
+	MaskHash    = "hash"    // replace the value with a stable SHA-256 digest
+	MaskPartial = "partial" // keep the last 4 characters, e.g. ***-**-1234
+)
+
+// partialVisibleChars is how many trailing characters MaskPartial keeps.
+const partialVisibleChars = 4
+
+// MaskRules are parsed from MCP_MASK_COLUMNS. They are applied to result
+// columns by name, not to the SQL: a query that aliases a masked column, wraps
+// it in an expression or filters on it still sees the raw value, so they guard
+// against accidental disclosure only and are no substitute for column
+// privileges.
+var MaskRules []maskRule
+
+// maskRule masks columns matching a table.column glob pattern.
+type maskRule struct {
+	table    string
+	column   string
+	strategy string
+}
+
+// parseMaskRules parses a comma-separated list of table.column=strategy
+// entries, where table and column are glob patterns (e.g. "users.ssn=partial,
+// *.password=null"). Matching is case-insensitive.
+func parseMaskRules(spec string) ([]maskRule, error) {
+	var rules []maskRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		target, strategy, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mask rule %q: expected table.column=strategy", entry)
+		}
+		table, column, ok := strings.Cut(strings.TrimSpace(target), ".")
+		if !ok || table == "" || column == "" {
+			return nil, fmt.Errorf("invalid mask rule %q: expected table.column=strategy", entry)
+		}
+
+		strategy = strings.ToLower(strings.TrimSpace(strategy))
+		switch strategy {
+		case MaskNull, MaskHash, MaskPartial:
+		default:
+			return nil, fmt.Errorf("invalid mask strategy %q in rule %q (supported: null, hash, partial)", strategy, entry)
+		}
+
+		for _, pattern := range []string{table, column} {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid pattern %q in mask rule %q: %w", pattern, entry, err)
+			}
+		}
+
+		rules = append(rules, maskRule{
+			table:    strings.ToLower(table),
+			column:   strings.ToLower(column),
+			strategy: strategy,
+		})
+	}
+	return rules, nil
+}
+
+// maskStrategy returns the strategy for a column, or "" if it is not masked.
+// An empty table means the source table is unknown (ad-hoc query results),
+// in which case rules match on the column alone so values are over-masked
+// rather than leaked.
+func maskStrategy(rules []maskRule, table, column string) string {
+	table, column = strings.ToLower(table), strings.ToLower(column)
+	for _, rule := range rules {
+		if ok, _ := path.Match(rule.column, column); !ok {
+			continue
+		}
+		if table == "" {
+			return rule.strategy
+		}
+		if ok, _ := path.Match(rule.table, table); ok {
+			return rule.strategy
+		}
+	}
+	return ""
+}
+
+// columnMasks resolves the strategy for each result column.
+func columnMasks(rules []maskRule, table string, columns []string) []string {
+	if len(rules) == 0 {
+		return nil
+	}
+	masks := make([]string, len(columns))
+	for i, col := range columns {
+		masks[i] = maskStrategy(rules, table, col)
+	}
+	return masks
+}
+
+// maskValue applies strategy to a scanned (and []byte-converted) value.
+func maskValue(strategy string, val any) any {
+	if val == nil || strategy == "" {
+		return val
+	}
+
+	text := fmt.Sprint(val)
+	switch strategy {
+	case MaskNull:
+		return nil
+	case MaskHash:
+		sum := sha256.Sum256([]byte(text))
+		return "sha256:" + hex.EncodeToString(sum[:])
+	case MaskPartial:
+		return maskPartial(text)
+	}
+	return val
+}
+
+// maskPartial hides all but the last few letters and digits while keeping
+// separators, so "123-45-6789" becomes "***-**-6789". Values too short to
+// hide anything are masked entirely.
+func maskPartial(text string) string {
+	runes := []rune(text)
+	total := 0
+	for _, r := range runes {
+		if isMaskable(r) {
+			total++
+		}
+	}
+
+	keep := partialVisibleChars
+	if total <= keep {
+		keep = 0
+	}
+
+	seen := 0
+	for i := len(runes) - 1; i >= 0; i-- {
+		if !isMaskable(runes[i]) {
+			continue
+		}
+		if seen < keep {
+			seen++
+			continue
+		}
+		runes[i] = '*'
+	}
+	return string(runes)
+}
+
+func isMaskable(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127
+}
@@ -0,0 +1,93 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestParseMaskRules(t *testing.T) {
+	rules, err := parseMaskRules("users.ssn=partial, *.password=null,Customers.Email=HASH")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(rules) != 3 {
+		t.Fatalf("Expected 3 rules, got %d", len(rules))
+	}
+	if rules[2].table != "customers" || rules[2].strategy != MaskHash {
+		t.Errorf("Expected case-insensitive rule, got %+v", rules[2])
+	}
+
+	for _, bad := range []string{"ssn=null", "users.ssn", "users.ssn=scramble", "users.[=null"} {
+		if _, err := parseMaskRules(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestMaskStrategy(t *testing.T) {
+	rules, _ := parseMaskRules("users.ssn=partial,*.password=null")
+
+	tests := []struct {
+		table, column, expected string
+	}{
+		{"users", "ssn", MaskPartial},
+		{"orders", "ssn", ""},
+		{"", "ssn", MaskPartial}, // unknown table matches on column alone
+		{"accounts", "PASSWORD", MaskNull},
+		{"users", "name", ""},
+	}
+	for _, tc := range tests {
+		if got := maskStrategy(rules, tc.table, tc.column); got != tc.expected {
+			t.Errorf("maskStrategy(%q, %q) = %q, expected %q", tc.table, tc.column, got, tc.expected)
+		}
+	}
+}
+
+func TestMaskValue(t *testing.T) {
+	tests := []struct {
+		strategy string
+		input    any
+		expected any
+	}{
+		{MaskNull, "secret", nil},
+		{MaskPartial, "123-45-6789", "***-**-6789"},
+		{MaskPartial, "4111111111111111", "************1111"},
+		{MaskPartial, "abc", "***"},
+		{MaskPartial, nil, nil},
+		{"", "visible", "visible"},
+	}
+	for _, tc := range tests {
+		if got := maskValue(tc.strategy, tc.input); got != tc.expected {
+			t.Errorf("maskValue(%q, %v) = %v, expected %v", tc.strategy, tc.input, got, tc.expected)
+		}
+	}
+
+	hashed := maskValue(MaskHash, "alice@example.com").(string)
+	if !strings.HasPrefix(hashed, "sha256:") || strings.Contains(hashed, "alice") {
+		t.Errorf("Expected SHA-256 digest, got %q", hashed)
+	}
+	if maskValue(MaskHash, "alice@example.com") != hashed {
+		t.Error("Expected hash to be stable")
+	}
+}
+
+func TestExecuteQuery_AppliesMasking(t *testing.T) {
+	server := newTestServer(t)
+
+	original := MaskRules
+	MaskRules, _ = parseMaskRules("users.name=partial")
+	defer func() { MaskRules = original }()
+
+	result, _ := server.executeQuery(t.Context(), map[string]any{"sql": "SELECT id, name FROM users ORDER BY id"})
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if rows[0]["name"] != "*lice" {
+		t.Errorf("Expected masked name, got %v", rows[0]["name"])
+	}
+	if rows[0]["id"] != float64(1) {
+		t.Errorf("Expected id to be unmasked, got %v", rows[0]["id"])
+	}
+}
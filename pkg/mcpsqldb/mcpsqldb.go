@@ -0,0 +1,66 @@
+// Package mcpsqldb implements a read-only Model Context Protocol server for
+// SQL databases. It can run as the readonly-mcp-server command (see Main) or
+// be embedded in another Go program:
+//
+//	mcpsqldb.LoadEnv() // optional: apply MCP_* settings
+//	server, err := mcpsqldb.New(mcpsqldb.Config{Driver: "postgres", DSN: dsn})
+//	if err != nil {
+//		return err
+//	}
+//	defer server.Close()
+//	return server.Serve(ctx, clientToServer, serverToClient)
+//
+// To serve remote clients over MCP Streamable HTTP instead, call
+// server.ListenAndServe(ctx, addr); each client gets a session of its own.
+//
+// Limits and security settings (QueryTimeout, MaxResultRows, DeniedTables and
+// the like) are package-level variables shared by every Server; set them
+// before calling New.
+package mcpsqldb
+
+import (
+	"context"
+	"io"
+)
+
+// Config selects the database a Server connects to.
+type Config struct {
+	// Driver names a registered adapter: "mysql", "postgres" or "sqlite",
+	// or any name added with RegisterAdapter.
+	Driver string
+
+	// DSN is the connection string. When empty it is built by the adapter
+	// from the same environment variables the command uses (MCP_DB_HOST etc.).
+	DSN string
+}
+
+// New connects to the database described by cfg and returns a Server ready
+// to Serve. The connection is verified and the account's privileges audited
+// before New returns.
+func New(cfg Config) (*Server, error) {
+	adapter, err := newAdapter(cfg.Driver)
+	if err != nil {
+		return nil, err
+	}
+
+	dsn := cfg.DSN
+	if dsn == "" {
+		if dsn, err = adapter.BuildDSN(); err != nil {
+			return nil, err
+		}
+	}
+	if dsn, err = adapter.ResolveDSN(dsn); err != nil {
+		return nil, err
+	}
+	return newServer(context.Background(), adapter, dsn)
+}
+
+// Serve handles newline-delimited JSON-RPC messages read from r, writing
+// responses to w, until r reaches EOF (returning nil), ctx is cancelled or
+// the server is shut down. A Server serves a single session; call Close when
+// done with it.
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	stop := context.AfterFunc(ctx, s.Shutdown)
+	defer stop()
+	return s.serve(r, w)
+}
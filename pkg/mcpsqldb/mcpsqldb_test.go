@@ -0,0 +1,103 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNew_ServesQueries(t *testing.T) {
+	server, err := New(Config{Driver: "SQLite", DSN: newTestDB(t) + "?mode=ro"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer server.Close()
+
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(context.Background(), inR, outW) }()
+
+	go io.WriteString(inW, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT COUNT(*) AS n FROM users"}}}`+"\n")
+	line, err := bufio.NewReader(outR).ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+
+	var resp struct {
+		Result CallToolResult `json:"result"`
+	}
+	if err := json.Unmarshal(line, &resp); err != nil {
+		t.Fatalf("Failed to parse response %q: %v", line, err)
+	}
+	if resp.Result.IsError || !strings.Contains(resp.Result.Content[0].Text, `"n": 3`) {
+		t.Errorf("Expected a count of 3, got %+v", resp.Result)
+	}
+
+	inW.Close()
+	if err := <-done; err != nil {
+		t.Errorf("Expected clean exit at EOF, got %v", err)
+	}
+}
+
+func TestNew_UnknownDriver(t *testing.T) {
+	_, err := New(Config{Driver: "oracle", DSN: "x"})
+	if err == nil || !strings.Contains(err.Error(), "unsupported database driver: oracle") {
+		t.Errorf("Expected unsupported driver error, got %v", err)
+	}
+}
+
+func TestServe_StopsOnContextCancel(t *testing.T) {
+	server := newTestServer(t)
+
+	r, w := io.Pipe()
+	defer w.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Serve(ctx, r, io.Discard) }()
+
+	cancel()
+	select {
+	case err := <-done:
+		if err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Serve did not return after the context was cancelled")
+	}
+}
+
+// renamedSQLite is a third-party adapter stand-in for registration tests.
+type renamedSQLite struct{ SQLiteAdapter }
+
+func (renamedSQLite) ServerName() string { return "renamed-sqlite" }
+
+func TestRegisterAdapter(t *testing.T) {
+	RegisterAdapter("Custom", func() DBAdapter { return &renamedSQLite{} })
+	defer func() {
+		adaptersMu.Lock()
+		delete(adapters, "custom")
+		adaptersMu.Unlock()
+	}()
+
+	adapter, err := newAdapter("custom")
+	if err != nil {
+		t.Fatalf("Expected registered adapter, got %v", err)
+	}
+	if adapter.ServerName() != "renamed-sqlite" {
+		t.Errorf("Expected renamed-sqlite, got %s", adapter.ServerName())
+	}
+	if !strings.Contains(adapterNames(), "custom") {
+		t.Errorf("Expected custom in supported names, got %s", adapterNames())
+	}
+
+	server, err := New(Config{Driver: "custom", DSN: newTestDB(t) + "?mode=ro"})
+	if err != nil {
+		t.Fatalf("New with registered adapter failed: %v", err)
+	}
+	server.Close()
+}
@@ -0,0 +1,330 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestMySQLValidateQuery_AllowedQueries(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	allowedQueries := []string{
+		"SELECT * FROM users",
+		"SELECT id, name FROM users WHERE id = 1",
+		"select * from users", // lowercase
+		"SHOW TABLES",
+		"SHOW DATABASES",
+		"DESCRIBE users",
+		"DESC users",
+		"EXPLAIN SELECT * FROM users",
+		"SELECT * FROM settings", // 'settings' contains 'set' but should be allowed
+		"SELECT * FROM user_settings WHERE setting_name = 'theme'",
+		"SELECT created_at FROM orders",  // 'created' contains 'create'
+		"SELECT updated_at FROM products", // 'updated' contains 'update'
+		"SELECT deleted FROM items",       // 'deleted' contains 'delete'
+		"SELECT * FROM users WHERE name = 'DROP TABLE users'", // keyword in string literal
+	}
+
+	for _, query := range allowedQueries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err != nil {
+				t.Errorf("Expected query to be allowed, but got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLValidateQuery_BlockedQueries(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"INSERT INTO users VALUES (1, 'test')", "INSERT"},
+		{"UPDATE users SET name = 'test'", "UPDATE"},
+		{"DELETE FROM users", "DELETE"},
+		{"DROP TABLE users", "DROP"},
+		{"CREATE TABLE test (id INT)", "CREATE"},
+		{"ALTER TABLE users ADD COLUMN age INT", "ALTER"},
+		{"TRUNCATE TABLE users", "TRUNCATE"},
+		{"GRANT ALL ON *.* TO 'user'", "GRANT"},
+		{"REVOKE ALL ON *.* FROM 'user'", "REVOKE"},
+		{"CALL some_procedure()", "CALL"},
+		{"EXECUTE some_statement", "EXECUTE"},
+		{"SET @var = 1", "SET"},
+		{"SELECT * INTO OUTFILE '/tmp/data.txt' FROM users", "INTO OUTFILE"},
+		{"SELECT * INTO DUMPFILE '/tmp/data.bin' FROM users", "INTO DUMPFILE"},
+		{"SELECT LOAD_FILE('/etc/passwd')", "LOAD_FILE"},
+		{"SELECT SLEEP(10)", "SLEEP"},
+		{"SELECT BENCHMARK(1000000, SHA1('test'))", "BENCHMARK"},
+		{"SELECT GET_LOCK('lock', 10)", "GET_LOCK"},
+		{"SELECT 1; DROP TABLE users", "multiple statements"},
+		{"SELECT 1; -- comment\nDROP TABLE users", "multiple statements"},
+		{"LOAD DATA INFILE '/tmp/data.txt' INTO TABLE users", "LOAD"},
+		{"REPLACE INTO users VALUES (1, 'test')", "REPLACE"},
+		{"HANDLER users OPEN", "HANDLER"},
+		{"RENAME TABLE users TO users_old", "RENAME"},
+	}
+
+	for _, tc := range blockedQueries {
+		t.Run(tc.query, func(t *testing.T) {
+			err := adapter.ValidateQuery(tc.query)
+			if err == nil {
+				t.Errorf("Expected query to be blocked for %s, but it was allowed", tc.shouldBlock)
+			}
+		})
+	}
+}
+
+func TestMySQLValidateQuery_EmptyQuery(t *testing.T) {
+	adapter := &MySQLAdapter{}
+
+	err := adapter.ValidateQuery("")
+	if err == nil {
+		t.Error("Expected empty query to be rejected")
+	}
+
+	err = adapter.ValidateQuery("   ")
+	if err == nil {
+		t.Error("Expected whitespace-only query to be rejected")
+	}
+}
+
+func TestMySQLValidateQuery_CommentInjection(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	queries := []string{
+		"SELECT 1 -- ; DROP TABLE users",
+		"SELECT 1 /* ; DROP TABLE users */",
+		"SELECT 1 # ; DROP TABLE users",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			// These should be allowed as the dangerous part is in a comment
+			if err != nil && strings.Contains(err.Error(), "multiple statements") {
+				t.Errorf("False positive on comment: %v", err)
+			}
+		})
+	}
+}
+
+func TestMySQLValidateQuery_ShowCommands(t *testing.T) {
+	defer func(allowed, denied []string) { ShowAllowed, ShowDenied = allowed, denied }(ShowAllowed, ShowDenied)
+	adapter := &MySQLAdapter{}
+
+	// Administration views are denied by default, modifiers included
+	for _, query := range []string{"SHOW GRANTS", "show full processlist", "SHOW BINARY LOGS;", "SHOW ENGINE INNODB STATUS"} {
+		if err := adapter.ValidateQuery(query); err == nil || !strings.Contains(err.Error(), "is not allowed") {
+			t.Errorf("Expected %q to be rejected, got %v", query, err)
+		}
+	}
+	if err := adapter.ValidateQuery("SHOW FULL COLUMNS FROM users"); err != nil {
+		t.Errorf("Expected SHOW FULL COLUMNS to be allowed, got %v", err)
+	}
+
+	ShowAllowed = parseShowCommands("tables, columns,  index")
+	ShowDenied = nil
+	tests := []struct {
+		query   string
+		allowed bool
+	}{
+		{"SHOW TABLES", true},
+		{"SHOW FULL TABLES FROM app LIKE 'user%'", true},
+		{"SHOW COLUMNS FROM users", true},
+		{"SHOW INDEX FROM users", true},
+		{"SHOW DATABASES", false},
+		{"SHOW GRANTS", false},
+		{"SHOW", false},
+		{"SELECT * FROM users", true},
+	}
+	for _, tc := range tests {
+		if err := adapter.ValidateQuery(tc.query); (err == nil) != tc.allowed {
+			t.Errorf("%q: expected allowed %v, got %v", tc.query, tc.allowed, err)
+		}
+	}
+}
+
+func TestMySQLRemoveStringsAndComments(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			input:    "SELECT * FROM users WHERE name = 'DROP TABLE'",
+			expected: "SELECT * FROM users WHERE name = ''",
+		},
+		{
+			input:    "SELECT * FROM users -- comment",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			input:    "SELECT * FROM users /* comment */",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			input:    "SELECT * FROM `table_name`",
+			expected: "SELECT * FROM `table_name`",
+		},
+		{
+			input:    "SELECT * FROM users # comment",
+			expected: "SELECT * FROM users  ",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.input, func(t *testing.T) {
+			result := adapter.RemoveStringsAndComments(tc.input)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestMySQLIsTransientError(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, true},
+		{&mysql.MySQLError{Number: 1205, Message: "Lock wait timeout exceeded"}, true},
+		{fmt.Errorf("wrapped: %w", &mysql.MySQLError{Number: 1213}), true},
+		{&mysql.MySQLError{Number: 1064, Message: "syntax error"}, false},
+		{errors.New("Deadlock found"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			if got := adapter.IsTransientError(tc.err); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMySQLWritePrivileges(t *testing.T) {
+	tests := []struct {
+		name     string
+		grants   []string
+		expected []string
+	}{
+		{
+			name:     "read-only account",
+			grants:   []string{"GRANT USAGE ON *.* TO `ro`@`%`", "GRANT SELECT, SHOW VIEW ON `app`.* TO `ro`@`%`"},
+			expected: nil,
+		},
+		{
+			name:     "write privileges",
+			grants:   []string{"GRANT SELECT, INSERT, UPDATE ON `app`.* TO `rw`@`%`"},
+			expected: []string{"INSERT ON `app`.*", "UPDATE ON `app`.*"},
+		},
+		{
+			name:     "all privileges with grant option",
+			grants:   []string{"GRANT ALL PRIVILEGES ON *.* TO `root`@`localhost` WITH GRANT OPTION"},
+			expected: []string{"ALL PRIVILEGES ON *.*", "GRANT OPTION ON *.*"},
+		},
+		{
+			name:     "column-level privileges",
+			grants:   []string{"GRANT SELECT (id, name), UPDATE (name) ON `app`.`users` TO `u`@`%`"},
+			expected: []string{"UPDATE ON `app`.`users`"},
+		},
+		{
+			name:     "role grant ignored",
+			grants:   []string{"GRANT `reader`@`%` TO `u`@`%`"},
+			expected: nil,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := mysqlWritePrivileges(tc.grants)
+			if strings.Join(got, "|") != strings.Join(tc.expected, "|") {
+				t.Errorf("Expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestMySQLConnectorWithDialer_UsesDialer(t *testing.T) {
+	errDial := errors.New("dial intercepted")
+	var dialed string
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = addr
+		return nil, errDial
+	}
+
+	connector, err := (&MySQLAdapter{}).ConnectorWithDialer("ro@tcp(db.internal:3306)/app", dial)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := connector.Connect(context.Background()); !errors.Is(err, errDial) {
+		t.Errorf("Expected dial error, got %v", err)
+	}
+	if !strings.HasPrefix(dialed, "db.internal") {
+		t.Errorf("Expected dialer to be called for db.internal, got %q", dialed)
+	}
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	planJSON := `{"query_block": {"select_id": 1, "ordering_operation": {"nested_loop": [
+		{"table": {"table_name": "o", "access_type": "ALL", "possible_keys": ["idx_user"]}},
+		{"table": {"table_name": "u", "access_type": "eq_ref", "key": "PRIMARY"}},
+		{"table": {"table_name": "<derived2>", "access_type": "ALL"}},
+		{"table": {"table_name": "p", "access_type": "index", "key": "idx_name"}}
+	]}}}`
+
+	accesses, err := parseMySQLPlan([]byte(planJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []PlanAccess{
+		{Table: "o", FullScan: true},
+		{Table: "u", Index: "PRIMARY"},
+		{Table: "p", Index: "idx_name", FullScan: true},
+	}
+	if fmt.Sprint(accesses) != fmt.Sprint(want) {
+		t.Errorf("Expected %+v, got %+v", want, accesses)
+	}
+}
+
+func TestParseMySQLPlanNodes(t *testing.T) {
+	planJSON := `{"query_block": {"select_id": 1, "cost_info": {"query_cost": "12.50"},
+		"ordering_operation": {"using_filesort": true, "nested_loop": [
+			{"table": {"table_name": "o", "access_type": "ALL", "rows_examined_per_scan": 40,
+				"possible_keys": ["idx_user"], "attached_condition": "(o.total > 10)", "cost_info": {"prefix_cost": "4.25"}}},
+			{"table": {"table_name": "u", "access_type": "eq_ref", "key": "PRIMARY", "rows_examined_per_scan": 1}}
+		]}}}`
+
+	nodes, err := parseMySQLPlanNodes([]byte(planJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].Operation != "query_block" || nodes[0].EstimatedCost == nil || *nodes[0].EstimatedCost != 12.5 {
+		t.Fatalf("Expected a query block costing 12.5, got %+v", nodes)
+	}
+	ordering := nodes[0].Children[0]
+	if ordering.Operation != "ordering_operation" || ordering.Detail != "using_filesort" {
+		t.Errorf("Expected an ordering operation using filesort, got %+v", ordering)
+	}
+	tables := ordering.Children[0].Children
+	if len(tables) != 2 {
+		t.Fatalf("Expected two tables in the nested loop, got %+v", ordering.Children)
+	}
+	o, u := tables[0], tables[1]
+	if o.Operation != "ALL" || o.Table != "o" || o.Access != PlanFullScan || *o.EstimatedRows != 40 ||
+		*o.EstimatedCost != 4.25 || o.Detail != "(o.total > 10)" || o.Children != nil {
+		t.Errorf("Expected a full scan of o, got %+v", o)
+	}
+	if u.Operation != "eq_ref" || u.Index != "PRIMARY" || u.Access != PlanIndexLookup {
+		t.Errorf("Expected a primary key lookup of u, got %+v", u)
+	}
+}
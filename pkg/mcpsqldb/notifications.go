@@ -0,0 +1,25 @@
+package mcpsqldb
+
+import (
+	"log/slog"
+)
+
+// handleNotification handles a message without an id. JSON-RPC forbids
+// answering notifications, even unknown ones or ones that fail, so nothing
+// is returned.
+func (s *Server) handleNotification(req *JSONRPCRequest) {
+	logger := slog.Default().With("session_id", s.sessionID, "method", req.Method)
+
+	switch req.Method {
+	case "notifications/initialized", "initialized":
+		// The client finished initializing; nothing is held back until then
+	case "notifications/cancelled":
+		// Requests run to completion, bounded by QueryTimeout
+	case "notifications/roots/list_changed":
+		// Roots restrict file access; the server reads no client files, so a
+		// changed list needs no action
+		logger.Debug("Client roots changed")
+	default:
+		logger.Debug("Ignoring unknown notification")
+	}
+}
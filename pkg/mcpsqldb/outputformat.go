@@ -0,0 +1,248 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Result formats accepted by the query tool's format parameter
+const (
+	OutputFormatJSON     = "json"
+	OutputFormatMarkdown = "markdown"
+	OutputFormatCSV      = "csv"
+)
+
+// NumberLocale is the locale whose digit grouping and decimal mark numbers
+// are written with in markdown and CSV results, e.g. "en" or "de-CH"; empty
+// writes them as the database returns them (overridable via
+// MCP_NUMBER_LOCALE env var)
+var NumberLocale = ""
+
+// NumberDecimals rounds fractional numbers in markdown and CSV results to
+// this many decimal places; -1 keeps them as the database returns them
+// (overridable via MCP_NUMBER_DECIMALS env var)
+var NumberDecimals = -1
+
+// numberSeparators are a locale's digit group separator and decimal mark.
+type numberSeparators struct {
+	group   string
+	decimal string
+}
+
+// numberLocales maps lowercased locale tags, and their languages, to their
+// separators. French and Nordic locales group with a no-break space.
+var numberLocales = map[string]numberSeparators{
+	"en":    {",", "."},
+	"ja":    {",", "."},
+	"zh":    {",", "."},
+	"ko":    {",", "."},
+	"de":    {".", ","},
+	"de-ch": {"'", "."},
+	"es":    {".", ","},
+	"it":    {".", ","},
+	"it-ch": {"'", "."},
+	"nl":    {".", ","},
+	"pt":    {".", ","},
+	"tr":    {".", ","},
+	"id":    {".", ","},
+	"fr":    {"\u00a0", ","},
+	"fr-ch": {"\u00a0", "."},
+	"ru":    {"\u00a0", ","},
+	"pl":    {"\u00a0", ","},
+	"cs":    {"\u00a0", ","},
+	"sv":    {"\u00a0", ","},
+	"nb":    {"\u00a0", ","},
+	"fi":    {"\u00a0", ","},
+	"da":    {".", ","},
+}
+
+// numberFormat writes numbers for markdown and CSV results.
+type numberFormat struct {
+	separators numberSeparators
+	// decimals is -1 to keep fractional digits as they are
+	decimals int
+}
+
+// lookupNumberLocale returns the separators of locale, trying the whole
+// tag (e.g. "de-CH" or "de_CH.UTF-8") before its language. An empty locale
+// leaves numbers ungrouped.
+func lookupNumberLocale(locale string) (numberSeparators, bool) {
+	if locale == "" {
+		return numberSeparators{decimal: "."}, true
+	}
+	tag := strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	tag, _, _ = strings.Cut(tag, ".")
+	if sep, ok := numberLocales[tag]; ok {
+		return sep, true
+	}
+	lang, _, _ := strings.Cut(tag, "-")
+	sep, ok := numberLocales[lang]
+	return sep, ok
+}
+
+// format writes n with the locale's separators, rounding a fractional
+// number to f.decimals places. Integers are never rounded, and numbers in
+// exponent form are only rounded.
+func (f numberFormat) format(n json.Number) string {
+	text := n.String()
+	fractional := strings.ContainsAny(text, ".eE")
+	if fractional && f.decimals >= 0 {
+		if v, err := strconv.ParseFloat(text, 64); err == nil {
+			text = strconv.FormatFloat(v, 'f', f.decimals, 64)
+		}
+	}
+	if strings.ContainsAny(text, "eE") {
+		return text
+	}
+
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(text, ".")
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, digit := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(f.separators.group)
+		}
+		b.WriteRune(digit)
+	}
+	if hasFrac {
+		b.WriteString(f.separators.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// renderRows converts a JSON query tool result to a markdown table or CSV.
+// Columns are in name order, as in the JSON result; the truncation and
+// omitted column notices are returned separately, as they are not rows.
+func renderRows(text, format string, nf numberFormat) (string, string, error) {
+	rows, warning, err := decodeRows(text)
+	if err != nil {
+		return "", "", err
+	}
+	seen := map[string]bool{}
+	var columns []string
+	for _, row := range rows {
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	if format == OutputFormatCSV {
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if len(columns) > 0 {
+			w.Write(columns)
+		}
+		for _, row := range rows {
+			record := make([]string, len(columns))
+			for i, col := range columns {
+				if row[col] != nil {
+					record[i] = nf.cell(row[col])
+				}
+			}
+			w.Write(record)
+		}
+		w.Flush()
+		return buf.String(), warning, w.Error()
+	}
+
+	if len(columns) == 0 {
+		return "(0 rows)", warning, nil
+	}
+	var b strings.Builder
+	b.WriteString("| " + strings.Join(markdownCells(columns), " | ") + " |\n")
+	b.WriteString("|" + strings.Repeat(" --- |", len(columns)) + "\n")
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, col := range columns {
+			cells[i] = "NULL"
+			if row[col] != nil {
+				cells[i] = nf.cell(row[col])
+			}
+		}
+		b.WriteString("| " + strings.Join(markdownCells(cells), " | ") + " |\n")
+	}
+	return b.String(), warning, nil
+}
+
+// cell writes a non-NULL result value as text; nested JSON values become
+// their JSON text.
+func (f numberFormat) cell(val any) string {
+	switch v := val.(type) {
+	case json.Number:
+		return f.format(v)
+	case string:
+		return v
+	default:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+}
+
+// markdownCells escapes the characters that would end a markdown table
+// cell or row.
+func markdownCells(cells []string) []string {
+	escaped := make([]string, len(cells))
+	replacer := strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+	for i, cell := range cells {
+		escaped[i] = replacer.Replace(cell)
+	}
+	return escaped
+}
+
+// outputOptions reads the query tool's format, number_locale and decimals
+// parameters; the number options only apply to markdown and CSV.
+func outputOptions(args map[string]any) (string, numberFormat, *Error) {
+	format := OutputFormatJSON
+	if v, ok := args["format"]; ok {
+		s, _ := v.(string)
+		switch format = strings.ToLower(s); format {
+		case OutputFormatJSON, OutputFormatMarkdown, OutputFormatCSV:
+		default:
+			return "", numberFormat{}, &Error{
+				Code:    InvalidParams,
+				Message: fmt.Sprintf("Invalid 'format' parameter: expected %s, %s or %s", OutputFormatJSON, OutputFormatMarkdown, OutputFormatCSV),
+			}
+		}
+	}
+
+	_, hasLocale := args["number_locale"]
+	_, hasDecimals := args["decimals"]
+	if format == OutputFormatJSON && (hasLocale || hasDecimals) {
+		return "", numberFormat{}, &Error{
+			Code:    InvalidParams,
+			Message: "The 'number_locale' and 'decimals' parameters only apply to the markdown and csv formats",
+		}
+	}
+
+	locale := NumberLocale
+	if v, ok := args["number_locale"]; ok {
+		locale, _ = v.(string)
+	}
+	separators, ok := lookupNumberLocale(locale)
+	if !ok {
+		return "", numberFormat{}, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Invalid 'number_locale' parameter: unsupported locale %q", locale),
+		}
+	}
+
+	decimals, rpcErr := intArg(args, "decimals", NumberDecimals, 0, 20)
+	if rpcErr != nil {
+		return "", numberFormat{}, rpcErr
+	}
+	return format, numberFormat{separators: separators, decimals: decimals}, nil
+}
@@ -0,0 +1,98 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestNumberFormat(t *testing.T) {
+	tests := []struct {
+		locale   string
+		decimals int
+		number   string
+		expected string
+	}{
+		{"", -1, "1234567.891", "1234567.891"},
+		{"en", -1, "1234567.891", "1,234,567.891"},
+		{"en-US", 2, "1234567.891", "1,234,567.89"},
+		{"de_DE.UTF-8", 1, "-1234567.891", "-1.234.567,9"},
+		{"de-CH", -1, "1234.5", "1'234.5"},
+		{"fr", -1, "1234", "1\u00a0234"},
+		{"en", 2, "123", "123"},
+		{"en", 0, "999.5", "1,000"},
+		{"en", -1, "1.5e+21", "1.5e+21"},
+		{"en", -1, "-123", "-123"},
+	}
+	for _, tt := range tests {
+		separators, ok := lookupNumberLocale(tt.locale)
+		if !ok {
+			t.Fatalf("Expected locale %q to be supported", tt.locale)
+		}
+		nf := numberFormat{separators: separators, decimals: tt.decimals}
+		if got := nf.format(json.Number(tt.number)); got != tt.expected {
+			t.Errorf("Expected %s for %s in %q with %d decimals, got %s", tt.expected, tt.number, tt.locale, tt.decimals, got)
+		}
+	}
+
+	if _, ok := lookupNumberLocale("xx"); ok {
+		t.Error("Expected an unknown locale to be rejected")
+	}
+}
+
+func TestExecuteQuery_Formats(t *testing.T) {
+	server := newTestServer(t, "CREATE TABLE sales (region TEXT, total REAL, note TEXT)",
+		"INSERT INTO sales VALUES ('north', 1234567.891, 'a|b'), ('south', 1500, NULL)")
+
+	tests := []struct {
+		args     map[string]any
+		expected string
+	}{
+		{map[string]any{"format": "markdown", "number_locale": "en", "decimals": float64(2)},
+			"| note | region | total |\n| --- | --- | --- |\n| a\\|b | north | 1,234,567.89 |\n| NULL | south | 1,500 |\n"},
+		{map[string]any{"format": "csv", "number_locale": "de"},
+			"note,region,total\na|b,north,\"1.234.567,891\"\n,south,1.500\n"},
+	}
+	for _, tt := range tests {
+		tt.args["sql"] = "SELECT region, total, note FROM sales ORDER BY region"
+		result, rpcErr := server.executeQuery(context.Background(), tt.args)
+		if rpcErr != nil || result.IsError {
+			t.Fatalf("Expected rows, got %v %+v", rpcErr, result)
+		}
+		if got := result.Content[0].Text; got != tt.expected {
+			t.Errorf("Expected %s result:\n%s\ngot:\n%s", tt.args["format"], tt.expected, got)
+		}
+	}
+}
+
+func TestExecuteQuery_FormatNotices(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 1
+	server := newTestServer(t)
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id FROM users ORDER BY id", "format": "csv"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows, got %v %+v", rpcErr, result)
+	}
+	if result.Content[0].Text != "id\n1\n" {
+		t.Errorf("Expected one CSV row, got %q", result.Content[0].Text)
+	}
+	if len(result.Content) != 2 || result.Content[1].Text != "Warning: Result truncated at 1 rows" {
+		t.Errorf("Expected the truncation notice separately, got %+v", result.Content)
+	}
+}
+
+func TestExecuteQuery_InvalidFormatOptions(t *testing.T) {
+	server := newTestServer(t)
+	for _, args := range []map[string]any{
+		{"format": "xml"},
+		{"format": "markdown", "number_locale": "xx"},
+		{"format": "csv", "decimals": float64(-1)},
+		{"decimals": float64(2)},
+	} {
+		args["sql"] = "SELECT 1"
+		if _, rpcErr := server.executeQuery(context.Background(), args); rpcErr == nil || rpcErr.Code != InvalidParams {
+			t.Errorf("Expected invalid params for %v, got %v", args, rpcErr)
+		}
+	}
+}
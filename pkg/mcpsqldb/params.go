@@ -0,0 +1,290 @@
+package mcpsqldb
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type hints a query parameter may carry, as {"type": ..., "value": ...}.
+// Each converts the value to the Go type its driver binds as that SQL type,
+// so e.g. a timestamp is compared as one and can use an index, rather than
+// as text.
+const (
+	ParamTypeString    = "string"
+	ParamTypeInteger   = "integer"
+	ParamTypeNumber    = "number"
+	ParamTypeNumeric   = "numeric"
+	ParamTypeBoolean   = "boolean"
+	ParamTypeTimestamp = "timestamp"
+	ParamTypeDate      = "date"
+	ParamTypeUUID      = "uuid"
+	ParamTypeJSON      = "json"
+)
+
+// paramTypes lists the type hints in the order they are documented.
+var paramTypes = []string{
+	ParamTypeString, ParamTypeInteger, ParamTypeNumber, ParamTypeNumeric, ParamTypeBoolean,
+	ParamTypeTimestamp, ParamTypeDate, ParamTypeUUID, ParamTypeJSON,
+}
+
+// timestampLayouts are the accepted forms of a timestamp parameter; those
+// without a zone are taken as UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	time.DateOnly,
+}
+
+var (
+	numericPattern = regexp.MustCompile(`^[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?$`)
+	uuidPattern    = regexp.MustCompile(`^\{?([0-9a-fA-F]{8})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{4})-?([0-9a-fA-F]{12})\}?$`)
+	// numberedPlaceholderPattern matches $1, $2, ... but not identifiers
+	// containing $
+	numberedPlaceholderPattern = regexp.MustCompile(`(?:^|[^\w$])\$(\d+)`)
+)
+
+// dateParam is a date parameter. It binds as the time.Time of its midnight,
+// UTC, but is told apart from a timestamp where dates are text.
+type dateParam struct{ time.Time }
+
+func (d dateParam) Value() (driver.Value, error) { return d.Time, nil }
+
+// queryParamsProperty describes the params argument of the query tools.
+var queryParamsProperty = Property{
+	Type: "array",
+	Description: "Values bound to the query's placeholders in order ($1, $2, ... on PostgreSQL and CockroachDB, ? elsewhere), " +
+		"instead of writing them into the SQL. A string, number, boolean or null is bound as is; " +
+		`{"type": ..., "value": ...} binds the value as that type, one of ` + strings.Join(paramTypes, ", ") +
+		`, e.g. {"type": "timestamp", "value": "2024-01-01T00:00:00Z"}`,
+}
+
+// queryParams reads the params argument of a query tool into the values to
+// bind through adapter, or returns the error to report when one is invalid.
+func queryParams(adapter DBAdapter, args map[string]any) ([]any, *Error) {
+	raw, ok := args["params"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	items, ok := raw.([]any)
+	if !ok {
+		return nil, &Error{Code: InvalidParams, Message: "Invalid 'params' parameter: expected an array"}
+	}
+	params := make([]any, len(items))
+	for i, item := range items {
+		value, err := queryParam(item)
+		if err != nil {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Invalid 'params[%d]' parameter: %v", i, err)}
+		}
+		params[i] = value
+		if binder, ok := adapter.(TimeParamBinder); ok {
+			switch v := value.(type) {
+			case time.Time:
+				params[i] = binder.BindTime(v, false)
+			case dateParam:
+				params[i] = binder.BindTime(v.Time, true)
+			}
+		}
+	}
+	return params, nil
+}
+
+// queryParam converts one params item, a JSON value or a typed value, to the
+// value to bind.
+func queryParam(item any) (any, error) {
+	typed, ok := item.(map[string]any)
+	if !ok {
+		switch v := item.(type) {
+		case nil, string, bool:
+			return v, nil
+		case float64:
+			// Whole numbers bind as integers, so they compare exactly
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return int64(v), nil
+			}
+			return v, nil
+		default:
+			return nil, fmt.Errorf("expected a string, number, boolean, null or typed value, got %s", jsonValueText(item))
+		}
+	}
+
+	paramType, _ := typed["type"].(string)
+	value, hasValue := typed["value"]
+	for key := range typed {
+		if key != "type" && key != "value" {
+			return nil, fmt.Errorf("unknown field %q; a typed value has only type and value", key)
+		}
+	}
+	if !hasValue {
+		return nil, fmt.Errorf("missing value")
+	}
+	if value == nil && paramType != ParamTypeJSON {
+		// A typed NULL; drivers bind nil the same whatever its type
+		return nil, nil
+	}
+	switch paramType {
+	case ParamTypeString:
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+	case ParamTypeInteger:
+		switch v := value.(type) {
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+				return int64(v), nil
+			}
+		case string:
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return n, nil
+			}
+		}
+	case ParamTypeNumber:
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return f, nil
+			}
+		}
+	case ParamTypeNumeric:
+		// Bound as its decimal text, so no digits are lost to a float
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case string:
+			if v = strings.TrimSpace(v); numericPattern.MatchString(v) {
+				return v, nil
+			}
+		}
+	case ParamTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+	case ParamTypeTimestamp:
+		if s, ok := value.(string); ok {
+			for _, layout := range timestampLayouts {
+				if t, err := time.Parse(layout, strings.TrimSpace(s)); err == nil {
+					return t.UTC(), nil
+				}
+			}
+		}
+	case ParamTypeDate:
+		if s, ok := value.(string); ok {
+			if t, err := time.Parse(time.DateOnly, strings.TrimSpace(s)); err == nil {
+				return dateParam{t}, nil
+			}
+		}
+	case ParamTypeUUID:
+		if s, ok := value.(string); ok {
+			if m := uuidPattern.FindStringSubmatch(strings.TrimSpace(s)); m != nil {
+				return strings.ToLower(strings.Join(m[1:], "-")), nil
+			}
+		}
+	case ParamTypeJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+	case "":
+		return nil, fmt.Errorf("missing type")
+	default:
+		return nil, fmt.Errorf("unknown type %q, expected one of %s", paramType, strings.Join(paramTypes, ", "))
+	}
+	return nil, fmt.Errorf("%s is not a valid %s", jsonValueText(value), paramType)
+}
+
+// jsonValueText writes a decoded JSON value for error messages.
+func jsonValueText(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// queryPlaceholders returns the parameter placeholders of cleaned, a query
+// with its strings and comments removed: the numbered ones ($1, $2, ...) if
+// there are any, otherwise each ?. PostgreSQL uses ? for JSON operators, so
+// it only counts when no placeholder is numbered.
+func queryPlaceholders(cleaned string) []string {
+	if matches := numberedPlaceholderPattern.FindAllStringSubmatch(cleaned, -1); len(matches) > 0 {
+		placeholders := make([]string, len(matches))
+		for i, m := range matches {
+			placeholders[i] = "$" + m[1]
+		}
+		return placeholders
+	}
+	placeholders := make([]string, strings.Count(cleaned, "?"))
+	for i := range placeholders {
+		placeholders[i] = "?"
+	}
+	return placeholders
+}
+
+// placeholderParams returns how many values placeholders take: the highest
+// number of numbered placeholders, which may repeat, or one per ?.
+func placeholderParams(placeholders []string) int {
+	n := 0
+	for _, p := range placeholders {
+		if p == "?" {
+			n++
+		} else if i, err := strconv.Atoi(p[1:]); err == nil {
+			n = max(n, i)
+		}
+	}
+	return n
+}
+
+// checkParams checks that params fit the placeholders of the validated
+// sqlQuery. Queries without params are not checked, so a ? that is an
+// operator still runs.
+func (s *Server) checkParams(sqlQuery string, params []any) error {
+	if len(params) == 0 {
+		return nil
+	}
+	if _, ok := explainTarget(sqlQuery); ok {
+		return fmt.Errorf("params cannot be bound to EXPLAIN; run the query with its values written in")
+	}
+	want := placeholderParams(queryPlaceholders(s.adapter.RemoveStringsAndComments(sqlQuery)))
+	if want != len(params) {
+		return fmt.Errorf("the query has placeholders for %d params, but %d were given", want, len(params))
+	}
+	return nil
+}
+
+// recordedParams returns params as they may be recorded in the audit log:
+// scrubbed of secrets and, with RedactSQLLiterals, each replaced with ? like
+// the query's literals.
+func recordedParams(params []any) []any {
+	if len(params) == 0 {
+		return nil
+	}
+	recorded := make([]any, len(params))
+	for i, p := range params {
+		switch v := p.(type) {
+		case nil:
+		case string:
+			recorded[i] = redactSecrets(v)
+		default:
+			recorded[i] = p
+		}
+		if RedactSQLLiterals && p != nil {
+			recorded[i] = "?"
+		}
+	}
+	return recorded
+}
@@ -0,0 +1,242 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueryParam(t *testing.T) {
+	midnight := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		item any
+		want any
+	}{
+		{"bob", "bob"},
+		{2.0, int64(2)},
+		{2.5, 2.5},
+		{true, true},
+		{nil, nil},
+		{map[string]any{"type": "string", "value": "42"}, "42"},
+		{map[string]any{"type": "integer", "value": "42"}, int64(42)},
+		{map[string]any{"type": "number", "value": "1.5"}, 1.5},
+		{map[string]any{"type": "numeric", "value": "12345678901234567890.01"}, "12345678901234567890.01"},
+		{map[string]any{"type": "boolean", "value": "false"}, false},
+		{map[string]any{"type": "timestamp", "value": "2024-01-01T02:00:00+02:00"}, midnight},
+		{map[string]any{"type": "timestamp", "value": "2024-01-01 00:00:00"}, midnight},
+		{map[string]any{"type": "date", "value": "2024-01-01"}, dateParam{midnight}},
+		{map[string]any{"type": "uuid", "value": "{A0EEBC99-9C0B-4EF8-BB6D-6BB9BD380A11}"}, "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{map[string]any{"type": "json", "value": map[string]any{"a": 1.0}}, `{"a":1}`},
+		{map[string]any{"type": "timestamp", "value": nil}, nil},
+	}
+	for _, tc := range tests {
+		got, err := queryParam(tc.item)
+		if err != nil || !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Expected %v to bind as %#v, got %#v %v", tc.item, tc.want, got, err)
+		}
+	}
+
+	for _, item := range []any{
+		[]any{1.0},
+		map[string]any{"value": "x"},
+		map[string]any{"type": "integer"},
+		map[string]any{"type": "integer", "value": 1.5},
+		map[string]any{"type": "numeric", "value": "1e"},
+		map[string]any{"type": "date", "value": "2024-13-01"},
+		map[string]any{"type": "uuid", "value": "not-a-uuid"},
+		map[string]any{"type": "interval", "value": "1 day"},
+		map[string]any{"type": "string", "value": "x", "cast": "text"},
+	} {
+		if got, err := queryParam(item); err == nil {
+			t.Errorf("Expected %v to be rejected, got %#v", item, got)
+		}
+	}
+}
+
+func TestQueryParams_SQLiteDatesAreText(t *testing.T) {
+	args := map[string]any{"params": []any{
+		map[string]any{"type": "timestamp", "value": "2024-01-01T10:30:00Z"},
+		map[string]any{"type": "date", "value": "2024-01-01"},
+	}}
+	params, rpcErr := queryParams(&SQLiteAdapter{}, args)
+	if rpcErr != nil || !reflect.DeepEqual(params, []any{"2024-01-01 10:30:00", "2024-01-01"}) {
+		t.Errorf("Expected dates as SQLite text, got %#v %v", params, rpcErr)
+	}
+	// Adapters wrapping SQLite's bind dates the same way
+	params, _ = queryParams(&argsCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}, args)
+	if !reflect.DeepEqual(params, []any{"2024-01-01 10:30:00", "2024-01-01"}) {
+		t.Errorf("Expected dates as SQLite text for a wrapping adapter, got %#v", params)
+	}
+	params, _ = queryParams(&PostgresAdapter{}, args)
+	if _, ok := params[0].(time.Time); !ok {
+		t.Errorf("Expected a time.Time elsewhere, got %#v", params[0])
+	}
+
+	if _, rpcErr := queryParams(&SQLiteAdapter{}, map[string]any{"params": []any{map[string]any{"type": "date", "value": 1.0}}}); rpcErr == nil || !strings.Contains(rpcErr.Message, "params[0]") {
+		t.Errorf("Expected the invalid param to be named, got %v", rpcErr)
+	}
+}
+
+func TestQueryPlaceholders(t *testing.T) {
+	tests := []struct {
+		sql  string
+		want int
+	}{
+		{"SELECT * FROM t WHERE a = ? AND b = ?", 2},
+		{"SELECT * FROM t WHERE a = $1 OR b = $1 OR c = $2", 2},
+		{"SELECT * FROM t WHERE doc ? 'key' AND a = $1", 1},
+		{"SELECT price$1 FROM t", 0},
+	}
+	for _, tc := range tests {
+		if got := placeholderParams(queryPlaceholders(tc.sql)); got != tc.want {
+			t.Errorf("Expected %d params for %s, got %d", tc.want, tc.sql, got)
+		}
+	}
+}
+
+// newParamsServer serves a test database with a table of events keyed by
+// their time.
+func newParamsServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, at TEXT NOT NULL, day TEXT NOT NULL)",
+		"INSERT INTO events (at, day) VALUES ('2024-01-01 09:00:00', '2024-01-01'), ('2024-01-02 09:00:00', '2024-01-02'), ('2024-01-03 09:00:00', '2024-01-03')",
+	)
+}
+
+func TestExecuteQuery_Params(t *testing.T) {
+	server := newParamsServer(t)
+	ctx := context.Background()
+
+	rows := queryRowsArgs(t, server, map[string]any{
+		"sql": "SELECT id FROM events WHERE at >= ? AND day < ?",
+		"params": []any{
+			map[string]any{"type": "timestamp", "value": "2024-01-02T00:00:00Z"},
+			map[string]any{"type": "date", "value": "2024-01-03"},
+		},
+	})
+	if len(rows) != 1 || fmt.Sprint(rows[0]["id"]) != "2" {
+		t.Errorf("Expected event 2, got %v", rows)
+	}
+	if rows := queryRowsArgs(t, server, map[string]any{"sql": "SELECT name FROM users WHERE id = ?", "params": []any{2.0}}); len(rows) != 1 || rows[0]["name"] != "bob" {
+		t.Errorf("Expected bob, got %v", rows)
+	}
+
+	for _, args := range []map[string]any{
+		{"sql": "SELECT name FROM users WHERE id = ?", "params": []any{1.0, 2.0}},
+		{"sql": "SELECT name FROM users", "params": []any{1.0}},
+		{"sql": "EXPLAIN QUERY PLAN SELECT name FROM users WHERE id = ?", "params": []any{1.0}},
+	} {
+		result, rpcErr := server.executeQuery(ctx, args)
+		if rpcErr != nil || !result.IsError || !strings.HasPrefix(result.Content[0].Text, "Query rejected") {
+			t.Errorf("Expected %v to be rejected, got %v %+v", args, rpcErr, result)
+		}
+	}
+}
+
+func TestExecuteQuery_ParamsCounted(t *testing.T) {
+	defer func(rows int, count bool) { MaxResultRows, CountTruncatedRows = rows, count }(MaxResultRows, CountTruncatedRows)
+	MaxResultRows, CountTruncatedRows = 1, true
+	server := newParamsServer(t)
+	ctx := context.Background()
+
+	result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT id FROM events WHERE day > ?", "params": []any{"2024-01-01"}})
+	if result.IsError || result.Meta == nil || result.Meta.Truncation == nil || !result.Meta.Truncation.Counted || result.Meta.Truncation.TotalRows != 2 {
+		t.Errorf("Expected the truncated result to be counted with the params, got %+v", result)
+	}
+
+	MaxResultRows = 100
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT id FROM events WHERE day > ? LIMIT 1", "params": []any{"2024-01-01"}, "include_total_count": true})
+	if result.IsError || result.Meta == nil || result.Meta.TotalCount == nil || *result.Meta.TotalCount != 2 {
+		t.Errorf("Expected a total count of 2, got %+v", result)
+	}
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT id FROM events WHERE day > ? LIMIT ?", "params": []any{"2024-01-01", 1.0}, "include_total_count": true})
+	if result.IsError || !strings.Contains(result.Content[len(result.Content)-1].Text, "row limit is a parameter") {
+		t.Errorf("Expected the count to be skipped for a parameter limit, got %+v", result)
+	}
+}
+
+// argsCostAdapter records the args each query is planned with.
+type argsCostAdapter struct {
+	*SQLiteAdapter
+	args [][]any
+}
+
+func (a *argsCostAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	a.args = append(a.args, args)
+	return 1, nil
+}
+
+func TestExecuteQuery_ParamsPlanned(t *testing.T) {
+	defer func(orig float64) { MaxQueryCost = orig }(MaxQueryCost)
+	MaxQueryCost = 100
+	server := newTestServer(t)
+	adapter := &argsCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	server.adapter = adapter
+
+	result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users WHERE id > ? LIMIT 1", "params": []any{1.0}, "include_total_count": true})
+	if result.IsError {
+		t.Fatalf("Expected rows, got %+v", result)
+	}
+	// The query, then its COUNT companion
+	if !reflect.DeepEqual(adapter.args, [][]any{{int64(1)}, {int64(1)}}) {
+		t.Errorf("Expected both plans to bind the param, got %v", adapter.args)
+	}
+}
+
+func TestSubmitQuery_Params(t *testing.T) {
+	server := newTestServer(t)
+	submitted, rpcErr := server.submitQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users WHERE id = ?", "params": []any{3.0}})
+	if rpcErr != nil || submitted.IsError {
+		t.Fatalf("Expected the job to start, got %v %+v", rpcErr, submitted)
+	}
+	var status map[string]any
+	json.Unmarshal([]byte(submitted.Content[0].Text), &status)
+	if result := waitForJob(t, server, status["job_id"].(string)); !strings.Contains(result.Content[0].Text, "carol") {
+		t.Errorf("Expected carol, got %s", result.Content[0].Text)
+	}
+
+	result, _ := server.submitQuery(context.Background(), map[string]any{"sql": "SELECT name FROM users WHERE id = ?"})
+	if result.IsError {
+		t.Errorf("Expected a query without params not to be checked, got %+v", result)
+	}
+}
+
+func TestAuditLog_RecordsParams(t *testing.T) {
+	defer func(orig bool) { RedactSQLLiterals = orig }(RedactSQLLiterals)
+	path := filepath.Join(t.TempDir(), "audit.log")
+	server := newTestServer(t)
+	audit, err := newAuditLog(path, false, 0)
+	if err != nil {
+		t.Fatalf("Failed to open audit log: %v", err)
+	}
+	server.audit = audit
+	ctx := context.Background()
+
+	args := map[string]any{"sql": "SELECT name FROM users WHERE id = ? OR name = ?", "params": []any{2.0, "carol"}}
+	server.executeQuery(ctx, args)
+	RedactSQLLiterals = true
+	server.executeQuery(ctx, args)
+	server.executeQuery(ctx, map[string]any{"sql": "SELECT name FROM users", "params": []any{1.0}})
+	audit.Close()
+
+	records := readAuditRecords(t, path)
+	if len(records) != 3 {
+		t.Fatalf("Expected 3 audit records, got %d", len(records))
+	}
+	if !reflect.DeepEqual(records[0].Params, []any{2.0, "carol"}) {
+		t.Errorf("Expected the params to be recorded, got %v", records[0].Params)
+	}
+	if !reflect.DeepEqual(records[1].Params, []any{"?", "?"}) {
+		t.Errorf("Expected the params to be redacted like literals, got %v", records[1].Params)
+	}
+	if records[2].Outcome != AuditOutcomeRejected || len(records[2].Params) != 1 {
+		t.Errorf("Expected the rejected query with its params, got %+v", records[2])
+	}
+}
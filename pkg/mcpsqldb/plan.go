@@ -0,0 +1,66 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// explainPattern matches an EXPLAIN of a SELECT whose plan the query tool
+// returns as PlanNodes: plain, with only a FORMAT option (MySQL's
+// FORMAT=JSON, PostgreSQL's (FORMAT JSON)), or SQLite's QUERY PLAN. Other
+// options, such as ANALYZE, leave the database's own output as it is.
+var explainPattern = regexp.MustCompile(`(?is)^\s*EXPLAIN\s+(?:QUERY\s+PLAN\s+|FORMAT\s*=\s*\w+\s+|\(\s*FORMAT\s+\w+\s*\)\s*)?((?:SELECT|WITH)\b.*)$`)
+
+// explainTarget returns the statement an EXPLAIN plans, reporting false
+// when query is not an EXPLAIN whose plan is normalized.
+func explainTarget(query string) (string, bool) {
+	m := explainPattern.FindStringSubmatch(query)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// queryPlan is the query tool's result for an EXPLAIN.
+type queryPlan struct {
+	Plan []PlanNode `json:"plan"`
+}
+
+// fetchPlan plans query, the statement of an EXPLAIN, and returns the plan
+// as a result, with its number of steps.
+func (s *Server) fetchPlan(ctx context.Context, query string) (*CallToolResult, int) {
+	nodes, err := s.adapter.ExplainPlan(ctx, s.db, query)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), 0
+	}
+	if nodes == nil {
+		nodes = []PlanNode{}
+	}
+	resultJSON, err := json.MarshalIndent(queryPlan{Plan: nodes}, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, 0
+	}
+	return &CallToolResult{Content: []Content{{Type: "text", Text: string(resultJSON)}}}, countPlanNodes(nodes)
+}
+
+// countPlanNodes returns the number of steps in nodes and their children.
+func countPlanNodes(nodes []PlanNode) int {
+	n := len(nodes)
+	for _, node := range nodes {
+		n += countPlanNodes(node.Children)
+	}
+	return n
+}
+
+// floatPtr returns a pointer to v, for optional estimates.
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,94 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestExplainTarget(t *testing.T) {
+	tests := []struct {
+		query  string
+		target string
+		ok     bool
+	}{
+		{"EXPLAIN SELECT * FROM users", "SELECT * FROM users", true},
+		{"  explain query plan\nSELECT 1", "SELECT 1", true},
+		{"EXPLAIN FORMAT=JSON SELECT 1", "SELECT 1", true},
+		{"EXPLAIN FORMAT = TREE WITH t AS (SELECT 1) SELECT * FROM t", "WITH t AS (SELECT 1) SELECT * FROM t", true},
+		{"EXPLAIN (FORMAT JSON) SELECT 1", "SELECT 1", true},
+		{"EXPLAIN ANALYZE SELECT 1", "", false},
+		{"EXPLAIN (ANALYZE, FORMAT JSON) SELECT 1", "", false},
+		{"EXPLAIN users", "", false},
+		{"EXPLAIN SELECTED", "", false},
+		{"SELECT 'EXPLAIN SELECT 1'", "", false},
+	}
+	for _, tt := range tests {
+		target, ok := explainTarget(tt.query)
+		if ok != tt.ok || target != tt.target {
+			t.Errorf("explainTarget(%q) = %q, %v; expected %q, %v", tt.query, target, ok, tt.target, tt.ok)
+		}
+	}
+}
+
+func TestExecuteQuery_ExplainReturnsPlan(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, rpcErr := server.executeQuery(ctx, map[string]any{
+		"sql":    "EXPLAIN QUERY PLAN SELECT * FROM users WHERE id IN (SELECT id FROM users WHERE name = 'bob')",
+		"format": "csv",
+	})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Unexpected error: %v %v", rpcErr, result)
+	}
+	var plan queryPlan
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &plan); err != nil {
+		t.Fatalf("Expected a JSON plan whatever the format, got %s", result.Content[0].Text)
+	}
+
+	var lookup, scan *PlanNode
+	var walk func(nodes []PlanNode)
+	walk = func(nodes []PlanNode) {
+		for i := range nodes {
+			switch nodes[i].Operation {
+			case "SEARCH":
+				lookup = &nodes[i]
+			case "SCAN":
+				scan = &nodes[i]
+			}
+			walk(nodes[i].Children)
+		}
+	}
+	walk(plan.Plan)
+	if lookup == nil || lookup.Table != "users" || lookup.Index != "PRIMARY KEY" || lookup.Access != PlanIndexLookup {
+		t.Errorf("Expected a primary key lookup of users, got %s", result.Content[0].Text)
+	}
+	if scan == nil || scan.Access != PlanFullScan {
+		t.Errorf("Expected the subquery to scan users, got %s", result.Content[0].Text)
+	}
+	if len(plan.Plan) == len(flattenPlan(plan.Plan)) {
+		t.Errorf("Expected the subquery's scan nested under it, got %s", result.Content[0].Text)
+	}
+}
+
+func TestExecuteQuery_ExplainStillValidated(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"secrets"}
+	server := newTestServer(t, "CREATE TABLE secrets (id INTEGER)")
+
+	result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "EXPLAIN SELECT * FROM secrets"})
+	if !result.IsError {
+		t.Errorf("Expected EXPLAIN of a denied table to be rejected, got %s", result.Content[0].Text)
+	}
+}
+
+// flattenPlan returns every step of nodes, parents first.
+func flattenPlan(nodes []PlanNode) []PlanNode {
+	var all []PlanNode
+	for _, node := range nodes {
+		all = append(all, node)
+		all = append(all, flattenPlan(node.Children)...)
+	}
+	return all
+}
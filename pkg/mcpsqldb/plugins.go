@@ -0,0 +1,25 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"log/slog"
+	"plugin"
+)
+
+// AdapterPlugins lists Go plugin files (built with -buildmode=plugin) loaded
+// at startup (overridable via MCP_ADAPTER_PLUGINS, comma-separated)
+var AdapterPlugins []string
+
+// loadAdapterPlugins opens each plugin, running its init functions, which
+// are expected to call RegisterAdapter. A plugin must be built against the
+// same mcpsqldb version and Go toolchain as the server, and loading requires
+// a cgo-enabled build on Linux, FreeBSD or macOS.
+func loadAdapterPlugins(paths []string) error {
+	for _, path := range paths {
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("failed to load adapter plugin %s: %w", path, err)
+		}
+		slog.Info("Loaded adapter plugin", "path", path)
+	}
+	return nil
+}
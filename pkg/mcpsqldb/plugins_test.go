@@ -0,0 +1,19 @@
+package mcpsqldb
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadAdapterPlugins(t *testing.T) {
+	if err := loadAdapterPlugins(nil); err != nil {
+		t.Errorf("Expected no error without plugins, got %v", err)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.so")
+	err := loadAdapterPlugins([]string{missing})
+	if err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Expected load error naming %s, got %v", missing, err)
+	}
+}
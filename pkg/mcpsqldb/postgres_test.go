@@ -0,0 +1,366 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+func TestPostgresValidateQuery_AllowedQueries(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	allowedQueries := []string{
+		"SELECT * FROM users",
+		"SELECT id, name FROM users WHERE id = 1",
+		"select * from users",
+		"SHOW server_version",
+		"DESCRIBE users",
+		"DESC users",
+		"EXPLAIN SELECT * FROM users",
+		"EXPLAIN ANALYZE SELECT * FROM users",
+		"SELECT * FROM settings",
+		"SELECT * FROM user_settings WHERE setting_name = 'theme'",
+		"SELECT created_at FROM orders",
+		"SELECT updated_at FROM products",
+		"SELECT deleted FROM items",
+		"SELECT * FROM users WHERE name = 'DROP TABLE users'", // keyword in string literal
+	}
+
+	for _, query := range allowedQueries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err != nil {
+				t.Errorf("Expected query to be allowed, but got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresValidateQuery_BlockedQueries(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"INSERT INTO users VALUES (1, 'test')", "INSERT"},
+		{"UPDATE users SET name = 'test'", "UPDATE"},
+		{"DELETE FROM users", "DELETE"},
+		{"DROP TABLE users", "DROP"},
+		{"CREATE TABLE test (id INT)", "CREATE"},
+		{"ALTER TABLE users ADD COLUMN age INT", "ALTER"},
+		{"TRUNCATE TABLE users", "TRUNCATE"},
+		{"GRANT ALL ON *.* TO 'user'", "GRANT"},
+		{"REVOKE ALL ON *.* FROM 'user'", "REVOKE"},
+		{"SET @var = 1", "SET"},
+		{"SELECT 1; DROP TABLE users", "multiple statements"},
+		// PostgreSQL-specific blocked queries
+		{"SELECT pg_sleep(10)", "pg_sleep"},
+		{"SELECT pg_sleep_for('5 seconds')", "pg_sleep_for"},
+		{"SELECT pg_sleep_until('2025-01-01')", "pg_sleep_until"},
+		{"SELECT pg_advisory_lock(1)", "pg_advisory_lock"},
+		{"SELECT pg_advisory_xact_lock(1)", "pg_advisory_xact_lock"},
+		{"SELECT pg_try_advisory_lock(1)", "pg_try_advisory_lock"},
+		{"SELECT pg_read_file('/etc/passwd')", "pg_read_file"},
+		{"SELECT pg_read_binary_file('/etc/passwd')", "pg_read_binary_file"},
+		{"SELECT pg_ls_dir('/tmp')", "pg_ls_dir"},
+		{"SELECT lo_import('/etc/passwd')", "lo_import"},
+		{"SELECT lo_export(12345, '/tmp/out')", "lo_export"},
+		{"COPY users TO '/tmp/data.csv'", "COPY TO"},
+		{"COPY users FROM '/tmp/data.csv'", "COPY FROM"},
+		{"CALL some_procedure()", "CALL"},
+		{"EXECUTE some_statement", "EXECUTE"},
+		{"LISTEN channel", "LISTEN"},
+		{"NOTIFY channel", "NOTIFY"},
+		{"PREPARE stmt AS SELECT 1", "PREPARE"},
+		{"DEALLOCATE stmt", "DEALLOCATE"},
+		{"VACUUM users", "VACUUM"},
+		{"REINDEX TABLE users", "REINDEX"},
+		{"CLUSTER users", "CLUSTER"},
+	}
+
+	for _, tc := range blockedQueries {
+		t.Run(tc.query, func(t *testing.T) {
+			err := adapter.ValidateQuery(tc.query)
+			if err == nil {
+				t.Errorf("Expected query to be blocked for %s, but it was allowed", tc.shouldBlock)
+			}
+		})
+	}
+}
+
+func TestPostgresValidateQuery_EmptyQuery(t *testing.T) {
+	adapter := &PostgresAdapter{}
+
+	err := adapter.ValidateQuery("")
+	if err == nil {
+		t.Error("Expected empty query to be rejected")
+	}
+
+	err = adapter.ValidateQuery("   ")
+	if err == nil {
+		t.Error("Expected whitespace-only query to be rejected")
+	}
+}
+
+func TestPostgresValidateQuery_CommentInjection(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	queries := []string{
+		"SELECT 1 -- ; DROP TABLE users",
+		"SELECT 1 /* ; DROP TABLE users */",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err != nil && strings.Contains(err.Error(), "multiple statements") {
+				t.Errorf("False positive on comment: %v", err)
+			}
+		})
+	}
+}
+
+func TestPostgresRemoveStringsAndComments(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "single-quoted string stripped",
+			input:    "SELECT * FROM users WHERE name = 'DROP TABLE'",
+			expected: "SELECT * FROM users WHERE name = ''",
+		},
+		{
+			name:     "-- comment stripped",
+			input:    "SELECT * FROM users -- comment",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			name:     "/* */ comment stripped",
+			input:    "SELECT * FROM users /* comment */",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			name:     "double-quoted identifier preserved",
+			input:    `SELECT * FROM "table_name"`,
+			expected: `SELECT * FROM "table_name"`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := adapter.RemoveStringsAndComments(tc.input)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestPostgresRemoveStringsAndComments_DollarQuoting(t *testing.T) {
+	adapter := &PostgresAdapter{}
+
+	// $$ dollar-quoted string should be stripped
+	input := "SELECT * FROM t WHERE body = $$DROP TABLE users$$"
+	result := adapter.RemoveStringsAndComments(input)
+	if strings.Contains(result, "DROP") {
+		t.Errorf("Dollar-quoted string content was not stripped: %s", result)
+	}
+
+	// $tag$ tagged dollar-quoted string should be stripped
+	input = "SELECT * FROM t WHERE body = $tag$DROP TABLE users$tag$"
+	result = adapter.RemoveStringsAndComments(input)
+	if strings.Contains(result, "DROP") {
+		t.Errorf("Tagged dollar-quoted string content was not stripped: %s", result)
+	}
+}
+
+func TestPostgresRemoveStringsAndComments_NoHash(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	// # is NOT a comment in PostgreSQL
+	input := "SELECT # FROM users"
+	result := adapter.RemoveStringsAndComments(input)
+	if !strings.Contains(result, "#") {
+		t.Errorf("# should not be treated as a comment in PostgreSQL: %s", result)
+	}
+}
+
+func TestPostgresIsTransientError(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{&pq.Error{Code: "40001"}, true},
+		{&pq.Error{Code: "40P01"}, true},
+		{fmt.Errorf("wrapped: %w", &pq.Error{Code: "40P01"}), true},
+		{&pq.Error{Code: "42601"}, false}, // syntax_error
+		{&pgconn.PgError{Code: "40001"}, true},
+		{fmt.Errorf("wrapped: %w", &pgconn.PgError{Code: "40P01"}), true},
+		{&pgconn.PgError{Code: "42601"}, false},
+		{errors.New("deadlock"), false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.err.Error(), func(t *testing.T) {
+			if got := adapter.IsTransientError(tc.err); got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestPostgresConnectorWithDialer_UsesDialer(t *testing.T) {
+	for _, driver := range []string{PostgresDriverPQ, PostgresDriverPGX} {
+		t.Run(driver, func(t *testing.T) {
+			errDial := errors.New("dial intercepted")
+			var dialed string
+			dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialed = addr
+				return nil, errDial
+			}
+
+			connector, err := (&PostgresAdapter{Driver: driver}).ConnectorWithDialer("postgres://ro@db.internal:5432/app", dial)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if _, err := connector.Connect(context.Background()); !errors.Is(err, errDial) {
+				t.Errorf("Expected dial error, got %v", err)
+			}
+			if !strings.HasPrefix(dialed, "db.internal") {
+				t.Errorf("Expected dialer to be called for db.internal, got %q", dialed)
+			}
+		})
+	}
+}
+
+func TestPostgresDriver_SelectsPGX(t *testing.T) {
+	defer func(orig string) { PostgresDriver = orig }(PostgresDriver)
+
+	for driver, want := range map[string]string{PostgresDriverPQ: "postgres", PostgresDriverPGX: "pgx"} {
+		PostgresDriver = driver
+		adapter, err := newAdapter("postgresql")
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got := adapter.DriverName(); got != want {
+			t.Errorf("Expected driver %q for MCP_PG_DRIVER=%s, got %q", want, driver, got)
+		}
+		// The database/sql driver must be registered under that name
+		if _, err := newReadOnlyConnector(adapter, "postgres://ro@db.internal:5432/app", ""); err != nil {
+			t.Errorf("Expected a connector for %s, got %v", driver, err)
+		}
+	}
+}
+
+func TestParsePostgresPlan(t *testing.T) {
+	planJSON := `[{"Plan": {"Node Type": "Hash Join", "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "orders", "Alias": "o"},
+		{"Node Type": "Hash", "Plans": [
+			{"Node Type": "Bitmap Heap Scan", "Relation Name": "users", "Alias": "u", "Plans": [
+				{"Node Type": "Bitmap Index Scan", "Index Name": "users_email_idx", "Index Cond": "(email = 'a'::text)"}
+			]}
+		]},
+		{"Node Type": "Index Only Scan", "Relation Name": "items", "Index Name": "items_pkey"}
+	]}}]`
+
+	accesses, err := parsePostgresPlan([]byte(planJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []PlanAccess{
+		{Table: "orders", FullScan: true},
+		{Table: "users", Index: "users_email_idx"},
+		{Table: "items", Index: "items_pkey", FullScan: true},
+	}
+	if fmt.Sprint(accesses) != fmt.Sprint(want) {
+		t.Errorf("Expected %+v, got %+v", want, accesses)
+	}
+}
+
+func TestParsePostgresPlanNodes(t *testing.T) {
+	planJSON := `[{"Plan": {"Node Type": "Hash Join", "Plan Rows": 120, "Total Cost": 35.5,
+		"Hash Cond": "(o.user_id = u.id)", "Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "orders", "Filter": "(total > 10)", "Plan Rows": 400},
+		{"Node Type": "Hash", "Plans": [
+			{"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_email_idx", "Index Cond": "(email = 'a'::text)"}
+		]}
+	]}}]`
+
+	nodes, err := parsePostgresPlanNodes([]byte(planJSON))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("Expected one plan, got %+v", nodes)
+	}
+	join := nodes[0]
+	if join.Operation != "Hash Join" || *join.EstimatedRows != 120 || *join.EstimatedCost != 35.5 || join.Detail != "Hash Cond: (o.user_id = u.id)" {
+		t.Errorf("Expected the hash join with its estimates, got %+v", join)
+	}
+	scan := join.Children[0]
+	if scan.Table != "orders" || scan.Access != PlanFullScan || scan.Detail != "Filter: (total > 10)" || scan.EstimatedCost != nil {
+		t.Errorf("Expected a full scan of orders, got %+v", scan)
+	}
+	lookup := join.Children[1].Children[0]
+	if lookup.Table != "users" || lookup.Index != "users_email_idx" || lookup.Access != PlanIndexLookup {
+		t.Errorf("Expected an index lookup of users, got %+v", lookup)
+	}
+}
+
+func TestPostgresDecodeValue(t *testing.T) {
+	adapter := &PostgresAdapter{}
+	mac, _ := net.ParseMAC("08:00:2b:01:02:03")
+
+	tests := []struct {
+		name     string
+		typeName string
+		val      any
+		want     any
+	}{
+		{"numeric", "NUMERIC", "12345678901234567890.0100", json.Number("12345678901234567890.0100")},
+		{"numeric by OID", "1700", "-3.5", json.Number("-3.5")},
+		{"numeric NaN", "NUMERIC", "NaN", "NaN"},
+		{"money", "MONEY", "$1,234.56", json.Number("1234.56")},
+		{"negative money", "MONEY", "-$5.00", json.Number("-5.00")},
+		{"parenthesized money", "790", "($5.00)", json.Number("-5.00")},
+		{"euro money", "MONEY", "1.234,56 €", json.Number("1234.56")},
+		{"yen money", "MONEY", "¥1,234", json.Number("1234")},
+		{"uuid text", "UUID", "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11", "a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{"uuid bytes", "UUID", [16]byte{0xa0, 0xee, 0xbc, 0x99, 0x9c, 0x0b, 0x4e, 0xf8, 0xbb, 0x6d, 0x6b, 0xb9, 0xbd, 0x38, 0x0a, 0x11},
+			"a0eebc99-9c0b-4ef8-bb6d-6bb9bd380a11"},
+		{"macaddr", "MACADDR", mac, "08:00:2b:01:02:03"},
+		{"inet", "869", "192.168.0.1/24", "192.168.0.1/24"},
+		{"interval", "INTERVAL", "1 day 02:00:00", "1 day 02:00:00"},
+		{"other types untouched", "TEXT", "12.5", "12.5"},
+		{"NULL", "NUMERIC", nil, nil},
+	}
+	for _, tc := range tests {
+		got := adapter.DecodeValue(tc.typeName, tc.val)
+		if got != tc.want {
+			t.Errorf("%s: expected %#v, got %#v", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestPostgresDefaultLiteral(t *testing.T) {
+	tests := map[string]string{
+		"'00000000-0000-0000-0000-000000000000'::uuid": "00000000-0000-0000-0000-000000000000",
+		"'1 day'::interval":                            "1 day",
+		"'it''s'::inet":                                "it's",
+		"gen_random_uuid()":                            "gen_random_uuid()",
+		"'draft'::character varying":                   "'draft'::character varying",
+	}
+	for def, want := range tests {
+		if got := postgresDefaultLiteral(def); got != want {
+			t.Errorf("Expected %q for %q, got %q", want, def, got)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package mcpsqldb
+
+import (
+	"expvar"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+)
+
+// PprofAddr enables the net/http/pprof listener when set via MCP_PPROF_ADDR
+var PprofAddr = ""
+
+// startPprof serves profiling endpoints on addr in the background. The
+// handlers are mounted on a dedicated mux so they are never exposed by any
+// other HTTP listener.
+func startPprof(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+
+	go func() {
+		slog.Info("pprof listening", "url", "http://"+addr+"/debug/pprof/")
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("pprof listener stopped", "error", err)
+		}
+	}()
+}
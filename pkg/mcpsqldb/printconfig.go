@@ -0,0 +1,164 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Client formats understood by print-config
+const (
+	ClientClaudeDesktop = "claude-desktop"
+	ClientCursor        = "cursor"
+	ClientGeneric       = "generic"
+)
+
+// clientConfigTargets describes where each client reads its configuration
+// and how it references an environment variable instead of inlining its
+// value. Claude Desktop cannot expand variables, so it gets a placeholder.
+var clientConfigTargets = []struct {
+	client    string
+	title     string
+	reference func(name string) string
+}{
+	{ClientClaudeDesktop, "Claude Desktop (claude_desktop_config.json)", func(name string) string { return "<" + name + ">" }},
+	{ClientCursor, "Cursor (.cursor/mcp.json)", func(name string) string { return "${env:" + name + "}" }},
+	{ClientGeneric, "Generic MCP client, e.g. Claude Code (.mcp.json)", func(name string) string { return "${" + name + "}" }},
+}
+
+// clientConfigExtraEnv lists the non-MCP_ variables the server reads that a
+// client configuration should carry over.
+var clientConfigExtraEnv = []string{
+	"AWS_ACCESS_KEY_ID", "AWS_DEFAULT_REGION", "AWS_REGION", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+	"GOOGLE_APPLICATION_CREDENTIALS", "KRB5CCNAME", "KRB5_CONFIG",
+}
+
+// secretEnvSuffixes mark variables whose values are referenced, never
+// written into a generated configuration.
+var secretEnvSuffixes = []string{"_PASSWORD", "_DSN", "_PASSPHRASE", "_TOKEN", "_TOKENS", "_ACCESS_KEY_ID", "_SECRET_ACCESS_KEY"}
+
+func isSecretEnv(name string) bool {
+	for _, suffix := range secretEnvSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+type clientServerConfig struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command"`
+	Args    []string          `json:"args"`
+	Env     map[string]string `json:"env,omitempty"`
+}
+
+type clientConfig struct {
+	MCPServers map[string]clientServerConfig `json:"mcpServers"`
+}
+
+// runPrintConfig writes MCP client configuration snippets that launch this
+// binary with the current environment's settings. With a client name in
+// args only that client's JSON is printed, so it can be redirected to a
+// file; otherwise every format is printed under a heading. It returns the
+// process exit code.
+func runPrintConfig(args []string, environ []string, out, errOut io.Writer) int {
+	command, err := os.Executable()
+	if err != nil {
+		fmt.Fprintf(errOut, "Failed to locate executable: %v\n", err)
+		return 1
+	}
+	if resolved, err := filepath.EvalSymlinks(command); err == nil {
+		command = resolved
+	}
+
+	only := ""
+	if len(args) > 0 {
+		only = args[0]
+	}
+
+	found := false
+	for _, target := range clientConfigTargets {
+		if only != "" && target.client != only {
+			continue
+		}
+		found = true
+		config := buildClientConfig(target.client, command, environ, target.reference)
+		data, err := json.MarshalIndent(config, "", "  ")
+		if err != nil {
+			fmt.Fprintf(errOut, "Failed to encode configuration: %v\n", err)
+			return 1
+		}
+		if only == "" {
+			fmt.Fprintf(out, "%s:\n", target.title)
+		}
+		fmt.Fprintln(out, string(data))
+		if only == "" {
+			fmt.Fprintln(out)
+		}
+	}
+	if !found {
+		fmt.Fprintf(errOut, "Unknown client %q (supported: %s, %s, %s)\n", only, ClientClaudeDesktop, ClientCursor, ClientGeneric)
+		return 1
+	}
+
+	if secrets := secretEnvNames(environ); len(secrets) > 0 {
+		fmt.Fprintf(errOut, "Secrets are referenced, not included: export %s where the client runs "+
+			"(Claude Desktop cannot expand variables; replace its <...> placeholders).\n", strings.Join(secrets, ", "))
+	}
+	return 0
+}
+
+// buildClientConfig returns the mcpServers entry for one client, keyed by a
+// name derived from the configured driver.
+func buildClientConfig(client, command string, environ []string, reference func(string) string) clientConfig {
+	env := map[string]string{}
+	for name, value := range clientEnv(environ) {
+		if isSecretEnv(name) {
+			value = reference(name)
+		}
+		env[name] = value
+	}
+
+	server := clientServerConfig{Command: command, Args: []string{}, Env: env}
+	if client == ClientGeneric {
+		server.Type = "stdio"
+	}
+
+	name := "readonly-sql"
+	if driver := strings.ToLower(env["MCP_DB_DRIVER"]); driver != "" {
+		name = "readonly-" + driver
+	}
+	return clientConfig{MCPServers: map[string]clientServerConfig{name: server}}
+}
+
+// clientEnv picks the variables from environ that configure the server.
+func clientEnv(environ []string) map[string]string {
+	env := map[string]string{}
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || value == "" {
+			continue
+		}
+		if strings.HasPrefix(name, "MCP_") || slices.Contains(clientConfigExtraEnv, name) {
+			env[name] = value
+		}
+	}
+	return env
+}
+
+func secretEnvNames(environ []string) []string {
+	var names []string
+	for name := range clientEnv(environ) {
+		if isSecretEnv(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,134 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// QuotaQueriesPerMinute limits queries per session in any 60-second window;
+// 0 disables it (overridable via MCP_QUOTA_QUERIES_PER_MINUTE env var)
+var QuotaQueriesPerMinute = 0
+
+// QuotaSessionRows limits the total rows returned to a session; 0 disables it
+// (overridable via MCP_QUOTA_SESSION_ROWS env var)
+var QuotaSessionRows int64 = 0
+
+// QuotaSessionBytes limits the total result bytes returned to a session;
+// 0 disables it (overridable via MCP_QUOTA_SESSION_BYTES env var)
+var QuotaSessionBytes int64 = 0
+
+// Quota names reported in quota_exceeded errors
+const (
+	QuotaQueries = "queries_per_minute"
+	QuotaRows    = "session_rows"
+	QuotaBytes   = "session_bytes"
+)
+
+const quotaWindow = time.Minute
+
+// quotaError reports which quota a query would exceed.
+type quotaError struct {
+	Quota      string
+	Limit      int64
+	RetryAfter time.Duration // zero when the quota never resets
+}
+
+func (e *quotaError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s quota of %d exceeded; retry in %s", e.Quota, e.Limit, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s quota of %d exhausted for this session", e.Quota, e.Limit)
+}
+
+// sessionQuota tracks one session's usage; it is safe for concurrent use.
+// Row and byte quotas are checked before a query runs, so the query that
+// crosses a limit completes and later ones are refused.
+type sessionQuota struct {
+	queriesPerMinute int
+	maxRows          int64
+	maxBytes         int64
+
+	mu     sync.Mutex
+	recent []time.Time
+	rows   int64
+	bytes  int64
+}
+
+func newSessionQuota(queriesPerMinute int, maxRows, maxBytes int64) *sessionQuota {
+	return &sessionQuota{queriesPerMinute: queriesPerMinute, maxRows: maxRows, maxBytes: maxBytes}
+}
+
+// allow admits a query or reports the quota it would exceed.
+func (q *sessionQuota) allow(now time.Time) *quotaError {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.maxRows > 0 && q.rows >= q.maxRows {
+		return &quotaError{Quota: QuotaRows, Limit: q.maxRows}
+	}
+	if q.maxBytes > 0 && q.bytes >= q.maxBytes {
+		return &quotaError{Quota: QuotaBytes, Limit: q.maxBytes}
+	}
+
+	if q.queriesPerMinute > 0 {
+		cutoff := now.Add(-quotaWindow)
+		i := 0
+		for i < len(q.recent) && !q.recent[i].After(cutoff) {
+			i++
+		}
+		q.recent = q.recent[i:]
+		if len(q.recent) >= q.queriesPerMinute {
+			return &quotaError{
+				Quota:      QuotaQueries,
+				Limit:      int64(q.queriesPerMinute),
+				RetryAfter: q.recent[0].Add(quotaWindow).Sub(now),
+			}
+		}
+		q.recent = append(q.recent, now)
+	}
+	return nil
+}
+
+// record adds a finished query's output to the session totals.
+func (q *sessionQuota) record(rows, bytes int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.rows += int64(rows)
+	q.bytes += int64(bytes)
+}
+
+// snapshot reports usage against each configured limit for server_status.
+func (q *sessionQuota) snapshot() map[string]any {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return map[string]any{
+		"queries_last_minute":    len(q.recent),
+		"queries_per_minute_max": q.queriesPerMinute,
+		"rows_returned":          q.rows,
+		"rows_max":               q.maxRows,
+		"bytes_returned":         q.bytes,
+		"bytes_max":              q.maxBytes,
+	}
+}
+
+// quotaExceeded builds the structured error returned when a quota is hit.
+func quotaExceeded(err *quotaError) *CallToolResult {
+	body := map[string]any{
+		"error":   "quota_exceeded",
+		"quota":   err.Quota,
+		"limit":   err.Limit,
+		"message": err.Error(),
+	}
+	if err.RetryAfter > 0 {
+		body["retry_after_seconds"] = int64(math.Ceil(err.RetryAfter.Seconds()))
+	}
+	payload, _ := json.Marshal(body)
+	return withErrorKind(&CallToolResult{
+		Content: []Content{{Type: "text", Text: string(payload)}},
+		IsError: true,
+	}, ErrorKindQuotaExceeded)
+}
@@ -0,0 +1,67 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSessionQuota_QueriesPerMinute(t *testing.T) {
+	q := newSessionQuota(2, 0, 0)
+	start := time.Now()
+
+	if q.allow(start) != nil || q.allow(start.Add(10*time.Second)) != nil {
+		t.Fatal("Expected first two queries to be allowed")
+	}
+	err := q.allow(start.Add(20 * time.Second))
+	if err == nil || err.Quota != QuotaQueries {
+		t.Fatalf("Expected queries_per_minute error, got %v", err)
+	}
+	if err.RetryAfter != 40*time.Second {
+		t.Errorf("Expected retry after 40s, got %v", err.RetryAfter)
+	}
+
+	// The first query leaves the window after a minute
+	if err := q.allow(start.Add(61 * time.Second)); err != nil {
+		t.Errorf("Expected query to be allowed once the window slides, got %v", err)
+	}
+}
+
+func TestSessionQuota_RowsAndBytes(t *testing.T) {
+	q := newSessionQuota(0, 10, 1000)
+
+	q.record(10, 100)
+	if err := q.allow(time.Now()); err == nil || err.Quota != QuotaRows {
+		t.Errorf("Expected session_rows error, got %v", err)
+	}
+
+	q = newSessionQuota(0, 0, 1000)
+	q.record(1, 1000)
+	if err := q.allow(time.Now()); err == nil || err.Quota != QuotaBytes || err.RetryAfter != 0 {
+		t.Errorf("Expected non-resetting session_bytes error, got %v", err)
+	}
+}
+
+func TestExecuteQuery_QuotaExceeded(t *testing.T) {
+	server := newTestServer(t)
+	server.quota = newSessionQuota(0, 3, 0)
+	ctx := context.Background()
+
+	result, _ := server.executeQuery(ctx, map[string]any{"sql": "SELECT * FROM users"})
+	if result.IsError {
+		t.Fatalf("Expected first query to succeed, got %q", result.Content[0].Text)
+	}
+
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT * FROM users"})
+	if !result.IsError {
+		t.Fatal("Expected quota error once the session row budget is spent")
+	}
+	var payload map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &payload); err != nil {
+		t.Fatalf("Expected structured error, got %q", result.Content[0].Text)
+	}
+	if payload["error"] != "quota_exceeded" || payload["quota"] != QuotaRows {
+		t.Errorf("Unexpected payload %v", payload)
+	}
+}
@@ -0,0 +1,130 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitQPS is the average requests per second each caller of the http
+// transport may send; 0 disables rate limiting (overridable via
+// MCP_RATE_LIMIT_QPS env var)
+var RateLimitQPS float64
+
+// RateLimitBurst is how many requests a caller may send at once before
+// RateLimitQPS applies; 0 allows one second's worth (overridable via
+// MCP_RATE_LIMIT_BURST env var)
+var RateLimitBurst int
+
+// rateLimitPruneInterval is how often buckets that have refilled are dropped,
+// so identities seen once do not accumulate.
+const rateLimitPruneInterval = time.Minute
+
+// rateLimiter applies a token bucket to each caller of a server shared over
+// HTTP, so one misbehaving agent cannot monopolize it. Callers are keyed by
+// the identity the auth middleware recorded, or by remote address without
+// auth. It is safe for concurrent use.
+type rateLimiter struct {
+	qps   float64
+	burst float64
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// newRateLimiter allows each caller qps requests per second on average and
+// bursts of up to burst requests, which defaults to one second's worth. It
+// returns nil when qps is not positive.
+func newRateLimiter(qps float64, burst int) *rateLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	if burst < 1 {
+		burst = int(math.Ceil(qps))
+	}
+	return &rateLimiter{qps: qps, burst: float64(burst), buckets: make(map[string]*tokenBucket)}
+}
+
+// allow takes a token from caller's bucket, or reports how long until one
+// is available.
+func (l *rateLimiter) allow(caller string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastPrune) >= rateLimitPruneInterval {
+		l.prune(now)
+	}
+	b, ok := l.buckets[caller]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, updated: now}
+		l.buckets[caller] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.updated).Seconds()*l.qps)
+	b.updated = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.qps * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// prune drops the buckets that have refilled since their last request,
+// which a new bucket would equal.
+func (l *rateLimiter) prune(now time.Time) {
+	refill := time.Duration(l.burst / l.qps * float64(time.Second))
+	for caller, b := range l.buckets {
+		if now.Sub(b.updated) >= refill {
+			delete(l.buckets, caller)
+		}
+	}
+	l.lastPrune = now
+}
+
+// middleware answers requests over the caller's rate with 429 Too Many
+// Requests, a Retry-After header and a JSON-RPC error classified as
+// rate_limited. It must wrap the handler inside the auth middleware to see
+// the caller's identity.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		caller := principalFrom(r.Context())
+		if caller == "" {
+			caller = r.RemoteAddr
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				caller = host
+			}
+		}
+
+		wait, ok := l.allow(caller, time.Now())
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		seconds := int64(math.Ceil(wait.Seconds()))
+		loggerFrom(r.Context()).Warn("Rate limited HTTP request", "caller", caller, "retry_after", wait)
+
+		info := newErrorInfo(ErrorKindRateLimited)
+		info.RetryAfterSeconds = seconds
+		w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		json.NewEncoder(w).Encode(JSONRPCResponse{
+			JSONRPC: "2.0",
+			Error: &Error{
+				Code:    InternalError,
+				Message: fmt.Sprintf("Rate limit of %g requests per second exceeded; retry in %ds", l.qps, seconds),
+				Data:    info,
+			},
+		})
+	})
+}
@@ -0,0 +1,84 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_TokenBucket(t *testing.T) {
+	limiter := newRateLimiter(2, 3)
+	now := time.Now()
+
+	for i := range 3 {
+		if _, ok := limiter.allow("alice", now); !ok {
+			t.Fatalf("Expected request %d of the burst to be allowed", i+1)
+		}
+	}
+	wait, ok := limiter.allow("alice", now)
+	if ok || wait != 500*time.Millisecond {
+		t.Errorf("Expected to wait 500ms for the next token, got %v %v", wait, ok)
+	}
+	if _, ok := limiter.allow("bob", now); !ok {
+		t.Error("Expected another caller to have their own bucket")
+	}
+	if _, ok := limiter.allow("alice", now.Add(500*time.Millisecond)); !ok {
+		t.Error("Expected a token to be refilled after 500ms")
+	}
+
+	// Buckets that have refilled are dropped
+	limiter.allow("carol", now.Add(time.Hour))
+	if len(limiter.buckets) != 1 {
+		t.Errorf("Expected only carol's bucket after pruning, got %d buckets", len(limiter.buckets))
+	}
+}
+
+func TestNewRateLimiter(t *testing.T) {
+	if newRateLimiter(0, 10) != nil {
+		t.Error("Expected no limiter without a rate")
+	}
+	if limiter := newRateLimiter(2.5, 0); limiter.burst != 3 {
+		t.Errorf("Expected a burst of one second's worth, got %v", limiter.burst)
+	}
+}
+
+func TestRateLimiter_Middleware(t *testing.T) {
+	auth, _ := newAuthenticator([]string{"tok-a", "tok-b"}, "", "", "")
+	limiter := newRateLimiter(0.1, 1)
+	handler := auth.middleware(limiter.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	send := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := send("tok-a"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected the first request to pass, got %d", rec.Code)
+	}
+	rec := send("tok-a")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "10" {
+		t.Errorf("Expected 429 with Retry-After 10, got %d %q", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	var resp struct {
+		Error struct {
+			Data ErrorInfo `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Expected a JSON-RPC error, got %s", rec.Body.String())
+	}
+	if data := resp.Error.Data; data.Kind != ErrorKindRateLimited || !data.Retryable || data.RetryAfterSeconds != 10 {
+		t.Errorf("Expected retryable rate_limited data, got %+v", data)
+	}
+
+	if rec := send("tok-b"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another identity not to be limited, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,316 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+const replHelp = `Enter SQL to run it through the query tool, or a command:
+  \tables                list table resources
+  \describe <table>      show a table's schema resource
+  \read <uri>            read any resource
+  \tools                 list tools
+  \call <tool> [json]    call a tool with JSON arguments
+  \status                call server_status
+  \json                  toggle raw JSON output
+  \help                  show this help
+  \quit                  exit
+`
+
+// repl drives the server's request handlers from an interactive prompt, so
+// configuration, validation, limits and masking behave exactly as they would
+// for an MCP client.
+type repl struct {
+	server *Server
+	out    io.Writer
+	nextID int
+	raw    bool
+}
+
+// runREPL reads commands from in, printing results to out, until EOF,
+// \quit or ctx is cancelled.
+func (s *Server) runREPL(ctx context.Context, in io.Reader, out io.Writer) error {
+	r := &repl{server: s, out: out}
+	fmt.Fprintf(out, "Connected to %s database %q (read-only). Type \\help for commands.\n",
+		s.adapter.ServerName(), s.databaseName)
+
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			select {
+			case lines <- scanner.Text():
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		fmt.Fprint(out, "sql> ")
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(out)
+			return ctx.Err()
+		case err := <-readErr:
+			fmt.Fprintln(out)
+			return err
+		case line := <-lines:
+			line = strings.TrimSpace(line)
+			if line != "" && !r.exec(line) {
+				return nil
+			}
+		}
+	}
+}
+
+// exec runs one input line, returning false when the user asked to quit.
+func (r *repl) exec(line string) bool {
+	if !strings.HasPrefix(line, `\`) {
+		r.callTool("query", map[string]any{"sql": line})
+		return true
+	}
+
+	command, arg, _ := strings.Cut(line[1:], " ")
+	arg = strings.TrimSpace(arg)
+	switch command {
+	case "q", "quit", "exit":
+		return false
+	case "h", "help", "?":
+		fmt.Fprint(r.out, replHelp)
+	case "dt", "tables":
+		r.listTables()
+	case "d", "describe":
+		if arg == "" {
+			fmt.Fprintln(r.out, `Usage: \describe <table>`)
+			break
+		}
+		r.readResource(fmt.Sprintf("%s://%s/%s/schema", r.server.adapter.URIScheme(), r.server.databaseName, arg))
+	case "read":
+		if arg == "" {
+			fmt.Fprintln(r.out, `Usage: \read <uri>`)
+			break
+		}
+		r.readResource(arg)
+	case "tools":
+		r.listTools()
+	case "call":
+		name, rawArgs, _ := strings.Cut(arg, " ")
+		args := map[string]any{}
+		if rawArgs = strings.TrimSpace(rawArgs); rawArgs != "" {
+			if err := json.Unmarshal([]byte(rawArgs), &args); err != nil {
+				fmt.Fprintf(r.out, "Invalid JSON arguments: %v\n", err)
+				break
+			}
+		}
+		if name == "" {
+			fmt.Fprintln(r.out, `Usage: \call <tool> [json]`)
+			break
+		}
+		r.callTool(name, args)
+	case "status":
+		r.callTool("server_status", map[string]any{})
+	case "json":
+		r.raw = !r.raw
+		if r.raw {
+			fmt.Fprintln(r.out, "Raw JSON output on")
+		} else {
+			fmt.Fprintln(r.out, "Raw JSON output off")
+		}
+	default:
+		fmt.Fprintf(r.out, "Unknown command \\%s; type \\help for commands\n", command)
+	}
+	return true
+}
+
+// request sends method through the same path as a JSON-RPC message, printing
+// protocol errors and returning the raw result on success.
+func (r *repl) request(method string, params any) (json.RawMessage, bool) {
+	r.nextID++
+	req := &JSONRPCRequest{JSONRPC: "2.0", ID: r.nextID, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			fmt.Fprintf(r.out, "Error: %v\n", err)
+			return nil, false
+		}
+		req.Params = data
+	}
+
+	resp := r.server.handleRequest(req)
+	if resp.Error != nil {
+		fmt.Fprintf(r.out, "Error %d: %s\n", resp.Error.Code, resp.Error.Message)
+		if resp.Error.Data != nil {
+			fmt.Fprintf(r.out, "  %v\n", resp.Error.Data)
+		}
+		return nil, false
+	}
+	data, err := json.Marshal(resp.Result)
+	if err != nil {
+		fmt.Fprintf(r.out, "Error: %v\n", err)
+		return nil, false
+	}
+	if r.raw {
+		r.printJSON(data)
+		return nil, false
+	}
+	return data, true
+}
+
+func (r *repl) callTool(name string, args map[string]any) {
+	data, ok := r.request("tools/call", CallToolParams{Name: name, Arguments: args})
+	if !ok {
+		return
+	}
+	var result CallToolResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		r.printJSON(data)
+		return
+	}
+	for _, content := range result.Content {
+		if result.IsError {
+			fmt.Fprintf(r.out, "Error: %s\n", content.Text)
+		} else {
+			r.printText(content.Text)
+		}
+	}
+}
+
+func (r *repl) listTables() {
+	data, ok := r.request("resources/list", nil)
+	if !ok {
+		return
+	}
+	var result ListResourcesResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		r.printJSON(data)
+		return
+	}
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURI")
+	for _, res := range result.Resources {
+		fmt.Fprintf(w, "%s\t%s\n", res.Name, res.URI)
+	}
+	w.Flush()
+	fmt.Fprintf(r.out, "(%d resources)\n", len(result.Resources))
+}
+
+func (r *repl) readResource(uri string) {
+	data, ok := r.request("resources/read", ReadResourceParams{URI: uri})
+	if !ok {
+		return
+	}
+	var result ReadResourceResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		r.printJSON(data)
+		return
+	}
+	for _, content := range result.Contents {
+		r.printText(content.Text)
+	}
+}
+
+func (r *repl) listTools() {
+	data, ok := r.request("tools/list", nil)
+	if !ok {
+		return
+	}
+	var result ListToolsResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		r.printJSON(data)
+		return
+	}
+	w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	for _, tool := range result.Tools {
+		fmt.Fprintf(w, "%s\t%s\n", tool.Name, tool.Description)
+	}
+	w.Flush()
+}
+
+// printText renders a JSON array of objects as an aligned table and any
+// other text as indented JSON or verbatim.
+func (r *repl) printText(text string) {
+	// UseNumber keeps large integers from being printed in float notation
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var rows []map[string]any
+	if err := decoder.Decode(&rows); err == nil && !decoder.More() {
+		r.printRows(rows)
+		return
+	}
+	r.printJSON([]byte(text))
+}
+
+func (r *repl) printJSON(data []byte) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		fmt.Fprintln(r.out, string(data))
+		return
+	}
+	fmt.Fprintln(r.out, pretty.String())
+}
+
+// printRows prints rows as a table. Columns are sorted by name because the
+// tool's JSON objects do not preserve the query's column order; the
+// truncation and omitted column notice rows are printed as notes instead.
+func (r *repl) printRows(rows []map[string]any) {
+	var warnings []string
+	seen := map[string]bool{}
+	var columns []string
+	data := rows[:0:0]
+	for _, row := range rows {
+		if msg, ok := resultNotice(row); ok {
+			warnings = append(warnings, msg)
+			continue
+		}
+		for col := range row {
+			if !seen[col] {
+				seen[col] = true
+				columns = append(columns, col)
+			}
+		}
+		data = append(data, row)
+	}
+	sort.Strings(columns)
+
+	if len(columns) > 0 {
+		w := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(columns, "\t"))
+		for _, row := range data {
+			cells := make([]string, len(columns))
+			for i, col := range columns {
+				cells[i] = formatCell(row[col])
+			}
+			fmt.Fprintln(w, strings.Join(cells, "\t"))
+		}
+		w.Flush()
+	}
+	fmt.Fprintf(r.out, "(%d rows)\n", len(data))
+	for _, warning := range warnings {
+		fmt.Fprintf(r.out, "Warning: %s\n", warning)
+	}
+}
+
+func formatCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return strings.NewReplacer("\t", `\t`, "\n", `\n`).Replace(v)
+	case map[string]any, []any:
+		data, _ := json.Marshal(v)
+		return string(data)
+	default:
+		return fmt.Sprint(v)
+	}
+}
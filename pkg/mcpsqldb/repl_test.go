@@ -0,0 +1,85 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func runTestREPL(t *testing.T, server *Server, input string) string {
+	t.Helper()
+	var out bytes.Buffer
+	if err := server.runREPL(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("runREPL failed: %v", err)
+	}
+	return out.String()
+}
+
+func TestREPL_QueryPrintsTable(t *testing.T) {
+	out := runTestREPL(t, newTestServer(t), "SELECT id, name FROM users WHERE id <= 2 ORDER BY id\n")
+
+	for _, want := range []string{"id  name", "1   alice", "2   bob", "(2 rows)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestREPL_RejectedQuery(t *testing.T) {
+	out := runTestREPL(t, newTestServer(t), "DELETE FROM users\n")
+
+	if !strings.Contains(out, "Error: Query rejected") {
+		t.Errorf("Expected rejection to be shown, got:\n%s", out)
+	}
+}
+
+func TestREPL_Commands(t *testing.T) {
+	out := runTestREPL(t, newTestServer(t), "\\tables\n\\describe users\n\\tools\n\\bogus\n\\quit\nSELECT 'after quit'\n")
+
+	for _, want := range []string{
+		"sqlite://",
+		"Schema for table 'users'",
+		"(1 resources)",
+		"server_status",
+		"Unknown command \\bogus",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "after quit") {
+		t.Errorf("Expected input after \\quit to be ignored, got:\n%s", out)
+	}
+}
+
+func TestREPL_CallAndRawJSON(t *testing.T) {
+	out := runTestREPL(t, newTestServer(t), "\\json\n\\call query {\"sql\": \"SELECT 12345678901 AS big\"}\n\\call query {bad\n")
+
+	if !strings.Contains(out, "Raw JSON output on") {
+		t.Errorf("Expected raw mode toggle, got:\n%s", out)
+	}
+	if !strings.Contains(out, `"content"`) || !strings.Contains(out, "12345678901") {
+		t.Errorf("Expected raw tool result, got:\n%s", out)
+	}
+	if !strings.Contains(out, "Invalid JSON arguments") {
+		t.Errorf("Expected invalid JSON to be reported, got:\n%s", out)
+	}
+}
+
+func TestFormatCell(t *testing.T) {
+	tests := []struct {
+		in   any
+		want string
+	}{
+		{nil, "NULL"},
+		{"a\tb\nc", `a\tb\nc`},
+		{[]any{"x"}, `["x"]`},
+		{true, "true"},
+	}
+	for _, tt := range tests {
+		if got := formatCell(tt.in); got != tt.want {
+			t.Errorf("Expected formatCell(%v) = %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
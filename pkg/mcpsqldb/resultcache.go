@@ -0,0 +1,279 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ResultCachePath is the SQLite file cache_result writes query results to
+// and query_cache reads them from; empty disables both tools (overridable
+// via MCP_RESULT_CACHE env var)
+var ResultCachePath string
+
+// ResultCacheMaxRows caps the rows cache_result may store per result
+// (overridable via MCP_RESULT_CACHE_MAX_ROWS env var)
+var ResultCacheMaxRows = 100000
+
+// resultCacheCatalog is the cache's table of contents, one row per cached
+// result.
+const resultCacheCatalog = "cache_catalog"
+
+// resultCache is the SQLite database holding cached query results. It is
+// written only by cache_result, through its own connection, and queried
+// through a read-only one, so query_cache cannot change it. It is kept
+// apart from the source database and outlives the server, so results are
+// shared by sessions and across restarts.
+type resultCache struct {
+	path   string
+	writer *sql.DB
+	reader *sql.DB
+}
+
+// openResultCache opens, creating if needed, the cache database at path,
+// refusing the file of the source database itself.
+func openResultCache(path, sourcePath string) (*resultCache, error) {
+	if sourcePath != "" {
+		cacheInfo, cacheErr := os.Stat(path)
+		sourceInfo, sourceErr := os.Stat(sourcePath)
+		if cacheErr == nil && sourceErr == nil && os.SameFile(cacheInfo, sourceInfo) {
+			return nil, fmt.Errorf("result cache %s is the source database; use a separate file", path)
+		}
+	}
+
+	// One writer connection serializes cache_result calls; WAL lets the
+	// reader run alongside it
+	writer, err := sql.Open("sqlite", "file:"+path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result cache: %w", err)
+	}
+	writer.SetMaxOpenConns(1)
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectionTimeout)
+	defer cancel()
+	if _, err := writer.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS `+resultCacheCatalog+` (
+		name TEXT PRIMARY KEY,
+		sql TEXT NOT NULL,
+		rows INTEGER NOT NULL,
+		cached_at TEXT NOT NULL,
+		session_id TEXT NOT NULL
+	)`); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open result cache: %w", err)
+	}
+	// Cached rows are as private as the source's
+	if err := os.Chmod(path, 0o600); err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open result cache: %w", err)
+	}
+
+	reader, err := sql.Open("sqlite", sqliteReadOnlyURI(path)+"&_pragma=busy_timeout(5000)&_pragma=query_only(1)")
+	if err != nil {
+		writer.Close()
+		return nil, fmt.Errorf("failed to open result cache: %w", err)
+	}
+	return &resultCache{path: path, writer: writer, reader: reader}, nil
+}
+
+// Close closes both connections.
+func (c *resultCache) Close() {
+	if c == nil {
+		return
+	}
+	c.reader.Close()
+	c.writer.Close()
+}
+
+// cacheReport is the cache_result result.
+type cacheReport struct {
+	Name     string   `json:"name"`
+	Rows     int      `json:"rows"`
+	Columns  []string `json:"columns"`
+	CachedAt string   `json:"cached_at"`
+}
+
+// resultCacheTools describes cache_result and query_cache, listed only when
+// a cache is configured.
+func resultCacheTools() []Tool {
+	return []Tool{
+		{
+			Name: "cache_result",
+			Description: "Run a read-only query and store its result as a table of the local result cache, " +
+				"replacing any result cached under the same name, so it can be analyzed with query_cache without querying the database again",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"name": {
+						Type:        "string",
+						Description: "Name of the cache table to store the result in",
+					},
+					"sql": {
+						Type:        "string",
+						Description: "The read-only query whose result to cache",
+					},
+				},
+				Required: []string{"name", "sql"},
+			},
+		},
+		{
+			Name: "query_cache",
+			Description: "Run a read-only SQLite query over the local result cache: the tables stored with cache_result " +
+				"and " + resultCacheCatalog + ", listing each with the query that produced it, its row count and when it was cached",
+			InputSchema: InputSchema{
+				Type: "object",
+				Properties: map[string]Property{
+					"sql": {
+						Type:        "string",
+						Description: "SQLite SELECT over the cached tables",
+					},
+				},
+				Required: []string{"sql"},
+			},
+		},
+	}
+}
+
+// cacheResult runs a query, through the same validation, cost guardrail,
+// quota and audit as the query tool, and stores its rows in the result
+// cache in one transaction, so a failed copy leaves any earlier result under
+// the name in place.
+func (s *Server) cacheResult(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	if s.cache == nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "cache_result requires a result cache configured with MCP_RESULT_CACHE"}},
+			IsError: true,
+		}, nil
+	}
+	name, _ := args["name"].(string)
+	sqlQuery, _ := args["sql"].(string)
+	switch {
+	case !crossNamePattern.MatchString(name):
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'name' parameter: expected a letter or underscore followed by letters, digits or underscores"}
+	case strings.EqualFold(name, resultCacheCatalog) || strings.HasPrefix(strings.ToLower(name), "sqlite_"):
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Invalid 'name' parameter: %s is reserved", name)}
+	case sqlQuery == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'sql' parameter"}
+	}
+
+	validated, err := s.validateQuery(sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	if result := s.checkCost(ctx, validated); result != nil {
+		return result, nil
+	}
+	if qerr := s.quota.allow(time.Now()); qerr != nil {
+		stats.queriesRejected.Add(1)
+		return quotaExceeded(qerr), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	start := time.Now()
+	report, err := s.storeResult(ctx, name, validated)
+	if err != nil {
+		s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeError, 0, time.Since(start), err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	s.audit.query(ctx, s.adapter, s.sessionID, validated, AuditOutcomeOK, report.Rows, time.Since(start), "")
+	return statementReportResult(report)
+}
+
+// storeResult runs the validated query and replaces the cache table name
+// with its rows, recording it in the catalog.
+func (s *Server) storeResult(ctx context.Context, name, sqlQuery string) (*cacheReport, error) {
+	rows, done, err := s.beginQuery(ctx, sqlQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+	defer rows.Close()
+
+	tx, err := s.cache.writer.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	table := (&SQLiteAdapter{}).QuoteIdentifier(name)
+	if _, err := tx.ExecContext(ctx, "DROP TABLE IF EXISTS "+table); err != nil {
+		return nil, err
+	}
+	columns, rowCount, err := s.copyRows(ctx, tx, name, rows, s.adapter, ResultCacheMaxRows, "filter or aggregate the query")
+	if err != nil {
+		return nil, err
+	}
+	cachedAt := time.Now().UTC().Format(time.RFC3339)
+	if _, err := tx.ExecContext(ctx, "INSERT OR REPLACE INTO "+resultCacheCatalog+" (name, sql, rows, cached_at, session_id) VALUES (?, ?, ?, ?, ?)",
+		name, sqlQuery, rowCount, cachedAt, s.sessionID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &cacheReport{Name: name, Rows: rowCount, Columns: columns, CachedAt: cachedAt}, nil
+}
+
+// queryCache runs a SQLite query over the result cache's read-only
+// connection. Cached rows were masked when stored, and the deny lists apply
+// to the cache tables' names as to the source's.
+func (s *Server) queryCache(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	if s.cache == nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "query_cache requires a result cache configured with MCP_RESULT_CACHE"}},
+			IsError: true,
+		}, nil
+	}
+	sqlQuery, ok := args["sql"].(string)
+	if !ok || sqlQuery == "" {
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'sql' parameter"}
+	}
+	validated, err := validateQueryFor(&SQLiteAdapter{}, sqlQuery)
+	if err != nil {
+		stats.queriesRejected.Add(1)
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+	if err := s.quota.allow(time.Now()); err != nil {
+		stats.queriesRejected.Add(1)
+		loggerFrom(ctx).Warn("Query quota exceeded", "error", err)
+		return quotaExceeded(err), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	rows, err := s.cache.reader.QueryContext(ctx, validated)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	defer rows.Close()
+
+	result, rowCount := s.formatRows(ctx, rows, validated)
+	stats.recordResult(result)
+	if !result.IsError {
+		s.quota.record(rowCount, len(result.Content[0].Text))
+	}
+	return result, nil
+}
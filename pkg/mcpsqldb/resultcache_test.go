@@ -0,0 +1,170 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newCacheServer returns a test server whose result cache is the file
+// cachePath.
+func newCacheServer(t *testing.T, cachePath string) *Server {
+	t.Helper()
+	defer func(path string) { ResultCachePath = path }(ResultCachePath)
+	ResultCachePath = cachePath
+	return newTestServer(t)
+}
+
+// queryCacheRows runs query_cache and returns its rows.
+func queryCacheRows(t *testing.T, server *Server, sqlQuery string) []map[string]any {
+	t.Helper()
+	result, rpcErr := server.callTool(context.Background(), "query_cache", map[string]any{"sql": sqlQuery})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows from %s, got %v %+v", sqlQuery, rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	return rows
+}
+
+func TestResultCache_StoreAndQuery(t *testing.T) {
+	cachePath := filepath.Join(t.TempDir(), "cache.db")
+	server := newCacheServer(t, cachePath)
+	ctx := context.Background()
+
+	result, rpcErr := server.callTool(ctx, "cache_result", map[string]any{"name": "names", "sql": "SELECT id, name FROM users WHERE id > 1"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected the result to be cached, got %v %+v", rpcErr, result)
+	}
+	var report cacheReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if report.Name != "names" || report.Rows != 2 || strings.Join(report.Columns, ",") != "id,name" {
+		t.Errorf("Expected 2 rows of id and name, got %+v", report)
+	}
+
+	rows := queryCacheRows(t, server, "SELECT name FROM names WHERE id = 2")
+	if len(rows) != 1 || rows[0]["name"] != "bob" {
+		t.Errorf("Expected bob, got %v", rows)
+	}
+	rows = queryCacheRows(t, server, "SELECT name, sql, rows FROM cache_catalog")
+	if len(rows) != 1 || rows[0]["name"] != "names" || rows[0]["rows"] != 2.0 {
+		t.Errorf("Expected the catalog to list names, got %v", rows)
+	}
+	if info, err := os.Stat(cachePath); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the cache file to be private, got %v %v", info, err)
+	}
+
+	// Caching again replaces the result
+	if result, _ := server.callTool(ctx, "cache_result", map[string]any{"name": "names", "sql": "SELECT name FROM users"}); result.IsError {
+		t.Fatalf("Expected the result to be replaced, got %+v", result)
+	}
+	if rows := queryCacheRows(t, server, "SELECT COUNT(*) AS n FROM names"); rows[0]["n"] != 3.0 {
+		t.Errorf("Expected 3 rows after replacing, got %v", rows)
+	}
+
+	// Results outlive the server
+	server.Close()
+	other := newCacheServer(t, cachePath)
+	if rows := queryCacheRows(t, other, "SELECT rows FROM cache_catalog WHERE name = 'names'"); len(rows) != 1 || rows[0]["rows"] != 3.0 {
+		t.Errorf("Expected the cached result to persist, got %v", rows)
+	}
+}
+
+func TestResultCache_ReadOnly(t *testing.T) {
+	server := newCacheServer(t, filepath.Join(t.TempDir(), "cache.db"))
+	ctx := context.Background()
+
+	for _, sqlQuery := range []string{"DELETE FROM cache_catalog", "DROP TABLE cache_catalog", "CREATE TABLE t (x)"} {
+		result, _ := server.callTool(ctx, "query_cache", map[string]any{"sql": sqlQuery})
+		if !result.IsError {
+			t.Errorf("Expected %s to be rejected, got %+v", sqlQuery, result)
+		}
+	}
+	// The connection refuses writes the validator lets through
+	if _, err := server.cache.reader.ExecContext(ctx, "DELETE FROM cache_catalog"); err == nil {
+		t.Error("Expected the cache reader to refuse writes")
+	}
+}
+
+func TestResultCache_Refused(t *testing.T) {
+	server := newCacheServer(t, filepath.Join(t.TempDir(), "cache.db"))
+	ctx := context.Background()
+
+	for _, name := range []string{"", "1st", "cache_catalog", "sqlite_stat1", "a-b"} {
+		if _, rpcErr := server.callTool(ctx, "cache_result", map[string]any{"name": name, "sql": "SELECT 1"}); rpcErr == nil {
+			t.Errorf("Expected name %q to be rejected", name)
+		}
+	}
+
+	result, _ := server.callTool(ctx, "cache_result", map[string]any{"name": "x", "sql": "DELETE FROM users"})
+	if !result.IsError || !strings.HasPrefix(result.Content[0].Text, "Query rejected") {
+		t.Errorf("Expected a write to be rejected, got %+v", result)
+	}
+
+	defer func(rows int) { ResultCacheMaxRows = rows }(ResultCacheMaxRows)
+	ResultCacheMaxRows = 2
+	result, _ = server.callTool(ctx, "cache_result", map[string]any{"name": "x", "sql": "SELECT * FROM users"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "more than 2 rows") {
+		t.Errorf("Expected an oversized result to fail, got %+v", result)
+	}
+	if rows := queryCacheRows(t, server, "SELECT name FROM sqlite_master WHERE name = 'x'"); len(rows) != 0 {
+		t.Errorf("Expected a failed result to leave no table, got %v", rows)
+	}
+}
+
+func TestResultCache_Masked(t *testing.T) {
+	server := newCacheServer(t, filepath.Join(t.TempDir(), "cache.db"))
+
+	defer func(rules []maskRule) { MaskRules = rules }(MaskRules)
+	MaskRules, _ = parseMaskRules("*.name=hash")
+	if result, _ := server.callTool(context.Background(), "cache_result", map[string]any{"name": "u", "sql": "SELECT name FROM users"}); result.IsError {
+		t.Fatalf("Expected the result to be cached, got %+v", result)
+	}
+	MaskRules = nil
+	for _, row := range queryCacheRows(t, server, "SELECT name FROM u") {
+		if row["name"] == "alice" || row["name"] == "bob" || row["name"] == "carol" {
+			t.Errorf("Expected names to be stored masked, got %v", row)
+		}
+	}
+}
+
+func TestResultCache_Disabled(t *testing.T) {
+	server := newTestServer(t)
+
+	list, _ := server.handleListTools()
+	for _, tool := range list.Tools {
+		if tool.Name == "cache_result" || tool.Name == "query_cache" {
+			t.Errorf("Expected %s to be offered only with a cache", tool.Name)
+		}
+	}
+	result, _ := server.callTool(context.Background(), "query_cache", map[string]any{"sql": "SELECT 1"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "MCP_RESULT_CACHE") {
+		t.Errorf("Expected query_cache to require a cache, got %+v", result)
+	}
+}
+
+func TestNewServer_RefusesSourceAsCache(t *testing.T) {
+	defer func(orig string) { ResultCachePath = orig }(ResultCachePath)
+	source := newTestDB(t)
+	ResultCachePath = source
+
+	// An adapter wrapping SQLite's knows its database file too
+	adapter := &fixedCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	if _, err := newServer(context.Background(), adapter, source+"?mode=ro"); err == nil || !strings.Contains(err.Error(), "is the source database") {
+		t.Errorf("Expected the source database to be refused as the cache, got %v", err)
+	}
+}
+
+func TestOpenResultCache_RefusesSource(t *testing.T) {
+	source := newTestDB(t)
+	if _, err := openResultCache(source, source); err == nil || !strings.Contains(err.Error(), "is the source database") {
+		t.Errorf("Expected the source database to be refused as the cache, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// CountTruncatedRows runs a COUNT(*) of a query whose result was truncated,
+// so the truncation notice can give the full row count. The count reruns the
+// query, so it is off by default (overridable via MCP_COUNT_TRUNCATED env var)
+var CountTruncatedRows = false
+
+// countQuery wraps the SELECT sqlQuery in a COUNT(*) of its rows, or returns
+// "" for other statements.
+func countQuery(sqlQuery string) string {
+	query := strings.TrimRight(strings.TrimSpace(sqlQuery), "; \t\r\n")
+	if !selectPattern.MatchString(query) {
+		return ""
+	}
+	// The query goes on lines of its own so a trailing comment cannot
+	// swallow the closing parenthesis
+	return "SELECT COUNT(*) FROM (\n" + query + "\n) AS mcp_count"
+}
+
+// countRows runs a query built by countQuery, with the args of the query it
+// counts, and returns the count.
+func (s *Server) countRows(ctx context.Context, countSQL string, args ...any) (int64, error) {
+	rows, done, err := s.beginQuery(ctx, countSQL, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+	defer rows.Close()
+
+	var total int64
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, errors.New("the count returned no rows")
+	}
+	if err := rows.Scan(&total); err != nil {
+		return 0, err
+	}
+	return total, nil
+}
+
+// countTruncated counts the rows of the truncated SELECT sqlQuery, run with
+// args, into set. Other statements, and counts that fail, leave set
+// uncounted. The count is a query of its own, run on a worker of its own and
+// subject to the cost guardrail; one it rejects leaves the total unknown,
+// saying why. The caller must not hold a worker.
+func (s *Server) countTruncated(ctx context.Context, sqlQuery string, set *rowSet, args ...any) {
+	countSQL := countQuery(sqlQuery)
+	if countSQL == "" {
+		return
+	}
+	if rejected := s.checkCost(ctx, countSQL, args...); rejected != nil {
+		set.uncounted = "count " + strings.TrimPrefix(rejected.Content[0].Text, "Query ")
+		return
+	}
+	if err := s.workers.acquire(ctx); err != nil {
+		loggerFrom(ctx).Warn("Failed to count truncated result", "error", err)
+		return
+	}
+	defer s.workers.release()
+	total, err := s.countRows(ctx, countSQL, args...)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to count truncated result", "error", err)
+		return
+	}
+	set.total, set.counted = total, true
+}
+
+// addTotalCount adds to result the number of rows sqlQuery, run with args,
+// matches without its outermost row limit, for clients paging through them,
+// or a notice saying why it was not counted.
+func (s *Server) addTotalCount(ctx context.Context, sqlQuery string, result *CallToolResult, args ...any) {
+	total, err := s.totalCount(ctx, sqlQuery, args...)
+	if err != nil {
+		result.Content = append(result.Content, Content{Type: "text", Text: fmt.Sprintf("Total count not computed: %v", err)})
+		return
+	}
+	if result.Meta == nil {
+		result.Meta = &ResultMeta{}
+	}
+	result.Meta.TotalCount = &total
+	result.Content = append(result.Content, Content{Type: "text", Text: fmt.Sprintf("Total count: %d rows", total)})
+}
+
+// totalCount counts the rows of sqlQuery, run with args, without its row
+// limit. The count is a query of its own, so it is subject to the cost
+// guardrail.
+func (s *Server) totalCount(ctx context.Context, sqlQuery string, args ...any) (int64, error) {
+	cleaned := s.adapter.RemoveStringsAndComments(sqlQuery)
+	unlimited := withoutRowLimit(sqlQuery, cleaned)
+	countSQL := countQuery(unlimited)
+	if countSQL == "" {
+		return 0, errors.New("only SELECT queries can be counted")
+	}
+	// The args would no longer line up with the placeholders left
+	if len(args) > 0 && len(queryPlaceholders(s.adapter.RemoveStringsAndComments(unlimited))) != len(queryPlaceholders(cleaned)) {
+		return 0, errors.New("the row limit is a parameter, so the query cannot be counted without it")
+	}
+	if rejected := s.checkCost(ctx, countSQL, args...); rejected != nil {
+		return 0, errors.New(rejected.Content[0].Text)
+	}
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer s.workers.release()
+	return s.countRows(ctx, countSQL, args...)
+}
@@ -0,0 +1,149 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func truncatedQuery(t *testing.T, server *Server, query string) (string, *TruncationInfo) {
+	t.Helper()
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": query})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows for %q, got %v %+v", query, rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if result.Meta == nil || result.Meta.Truncation == nil {
+		t.Fatalf("Expected truncation metadata for %q, got %+v", query, result.Meta)
+	}
+	warning, _ := rows[len(rows)-1]["_warning"].(string)
+	return warning, result.Meta.Truncation
+}
+
+func TestCountTruncatedRows(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	defer func(orig bool) { CountTruncatedRows = orig }(CountTruncatedRows)
+	MaxResultRows = 2
+	server := newTestServer(t)
+
+	warning, info := truncatedQuery(t, server, "SELECT id FROM users")
+	if warning != "Result truncated at 2 rows" || *info != (TruncationInfo{Shown: 2}) {
+		t.Errorf("Expected an uncounted truncation, got %q %+v", warning, info)
+	}
+
+	CountTruncatedRows = true
+	for _, query := range []string{
+		"SELECT id FROM users",
+		"SELECT id FROM users;",
+		"SELECT id FROM users -- all of them",
+	} {
+		warning, info := truncatedQuery(t, server, query)
+		if warning != "Result truncated at 2 of 3 rows" || *info != (TruncationInfo{Shown: 2, Counted: true, TotalRows: 3}) {
+			t.Errorf("Expected 2 of 3 rows counted for %q, got %q %+v", query, warning, info)
+		}
+	}
+
+	result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id FROM users LIMIT 2"})
+	if result.Meta != nil {
+		t.Errorf("Expected no truncation metadata for a complete result, got %+v", result.Meta)
+	}
+}
+
+func TestCountTruncatedRows_CostChecked(t *testing.T) {
+	defer func(rows int, count bool, cost float64) {
+		MaxResultRows, CountTruncatedRows, ForbiddenQueryCost = rows, count, cost
+	}(MaxResultRows, CountTruncatedRows, ForbiddenQueryCost)
+	MaxResultRows, CountTruncatedRows, ForbiddenQueryCost = 2, true, 100
+	server := newTestServer(t)
+	adapter := &argsCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	server.adapter = adapter
+
+	// The query is planned, then its count
+	if warning, info := truncatedQuery(t, server, "SELECT id FROM users"); warning != "Result truncated at 2 of 3 rows" || !info.Counted || len(adapter.args) != 2 {
+		t.Errorf("Expected the count to be planned and run, got %q %+v after %d plans", warning, info, len(adapter.args))
+	}
+
+	// Too expensive to count, though the rows are cheap enough to show
+	server.adapter = &countCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	warning, info := truncatedQuery(t, server, "SELECT id FROM users")
+	if !strings.HasPrefix(warning, "Result truncated at 2 rows; total unknown, count rejected: estimated cost 5000 exceeds") || *info != (TruncationInfo{Shown: 2}) {
+		t.Errorf("Expected the count to be rejected, got %q %+v", warning, info)
+	}
+}
+
+func TestCountTruncatedRows_OneWorker(t *testing.T) {
+	defer func(rows int, count bool, cost float64, workers int, timeout time.Duration) {
+		MaxResultRows, CountTruncatedRows, MaxQueryCost, WorkerCount, QueryTimeout = rows, count, cost, workers, timeout
+	}(MaxResultRows, CountTruncatedRows, MaxQueryCost, WorkerCount, QueryTimeout)
+	MaxResultRows, CountTruncatedRows, MaxQueryCost, WorkerCount, QueryTimeout = 2, true, 100, 1, 2*time.Second
+	server := newCostServer(t, 1)
+
+	// The count is planned and run once the query's worker is released
+	started := time.Now()
+	if warning, info := truncatedQuery(t, server, "SELECT id FROM users"); warning != "Result truncated at 2 of 3 rows" || !info.Counted {
+		t.Errorf("Expected the count with a single worker, got %q %+v", warning, info)
+	}
+	if elapsed := time.Since(started); elapsed >= QueryTimeout {
+		t.Errorf("Expected the count not to wait for a worker, took %s", elapsed)
+	}
+}
+
+func TestIncludeTotalCount(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, rpcErr := server.executeQuery(ctx, map[string]any{
+		"sql":                 "SELECT id FROM users ORDER BY id LIMIT 1 OFFSET 1",
+		"include_total_count": true,
+	})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows, got %v %+v", rpcErr, result)
+	}
+	if result.Meta == nil || result.Meta.TotalCount == nil || *result.Meta.TotalCount != 3 {
+		t.Errorf("Expected a total count of 3, got %+v", result.Meta)
+	}
+	if len(result.Content) != 2 || result.Content[1].Text != "Total count: 3 rows" {
+		t.Errorf("Expected the total count as a second content item, got %+v", result.Content)
+	}
+
+	result, _ = server.executeQuery(ctx, map[string]any{"sql": "SELECT id FROM users LIMIT 1"})
+	if result.Meta != nil || len(result.Content) != 1 {
+		t.Errorf("Expected no total count unless asked for, got %+v", result)
+	}
+}
+
+// countCostAdapter plans COUNT(*) queries as expensive and others as cheap.
+type countCostAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *countCostAdapter) EstimateCost(ctx context.Context, db *sql.DB, query string, args ...any) (float64, error) {
+	if strings.Contains(query, "COUNT(*)") {
+		return 5000, nil
+	}
+	return 1, nil
+}
+
+func TestIncludeTotalCount_CostGuardrail(t *testing.T) {
+	defer func(orig float64) { ForbiddenQueryCost = orig }(ForbiddenQueryCost)
+	server := newTestServer(t)
+	server.adapter = &countCostAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	ForbiddenQueryCost = 1000
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{
+		"sql":                 "SELECT id FROM users LIMIT 1",
+		"include_total_count": true,
+	})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows, got %v %+v", rpcErr, result)
+	}
+	if result.Meta != nil || len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "Total count not computed") {
+		t.Errorf("Expected the count refused by the cost guardrail, got %+v", result)
+	}
+}
@@ -0,0 +1,74 @@
+package mcpsqldb
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Row limiting clauses of other dialects, matched at the start or end of
+// a statement so subqueries are left alone.
+var (
+	// topPattern matches SQL Server's SELECT [DISTINCT] TOP n / TOP (n)
+	topPattern = regexp.MustCompile(`(?is)^(\s*SELECT\s+(?:DISTINCT\s+)?)TOP\s*(?:\(\s*([0-9]+)\s*\)|([0-9]+))\s+`)
+	// fetchPattern matches the standard [OFFSET m ROWS] FETCH FIRST|NEXT n ROWS ONLY
+	fetchPattern = regexp.MustCompile(`(?is)\s+(?:OFFSET\s+([0-9]+)\s+ROWS?\s+)?FETCH\s+(?:FIRST|NEXT)\s+([0-9]+)\s+ROWS?\s+ONLY\s*;?\s*$`)
+	// limitCommaPattern matches MySQL's LIMIT offset, count
+	limitCommaPattern = regexp.MustCompile(`(?is)\s+LIMIT\s+([0-9]+)\s*,\s*([0-9]+)\s*;?\s*$`)
+	// pagingPattern matches the row limiting clause ending a statement once
+	// normalized: LIMIT n [OFFSET m], LIMIT m, n, OFFSET m [LIMIT n] or
+	// [OFFSET m ROWS] FETCH FIRST n ROWS ONLY, where n and m may also be
+	// placeholders
+	pagingPattern = regexp.MustCompile(strings.ReplaceAll(`(?is)\s+(?:LIMIT\s+#(?:\s*,\s*#|\s+OFFSET\s+#)?|OFFSET\s+#(?:\s+ROWS?)?(?:\s+LIMIT\s+#|\s+FETCH\s+(?:FIRST|NEXT)\s+#\s+ROWS?\s+ONLY)?|FETCH\s+(?:FIRST|NEXT)\s+#\s+ROWS?\s+ONLY)\s*;?\s*$`,
+		"#", `(?:[0-9]+|\?|\$[0-9]+)`))
+	// trailingSemicolon matches a statement's optional terminator
+	trailingSemicolon = regexp.MustCompile(`\s*;?\s*$`)
+)
+
+// rowLimitSyntax lists the row limiting forms a dialect accepts natively,
+// beyond LIMIT n OFFSET m, which every supported database accepts.
+type rowLimitSyntax struct {
+	fetch      bool
+	limitComma bool
+}
+
+// normalizeRowLimit rewrites the TOP, FETCH FIRST and LIMIT m, n forms that
+// syntax lacks into LIMIT n OFFSET m, so queries written for another
+// database run unchanged. cleanedSQL (sqlQuery without strings and comments)
+// must match too, so text inside a literal or comment is never rewritten.
+func normalizeRowLimit(sqlQuery, cleanedSQL string, syntax rowLimitSyntax) string {
+	var limit, offset string
+	if m := topPattern.FindStringSubmatch(sqlQuery); m != nil && topPattern.MatchString(cleanedSQL) {
+		limit = m[2] + m[3]
+		sqlQuery = m[1] + sqlQuery[len(m[0]):]
+	}
+	if !syntax.fetch && limit == "" {
+		if m := fetchPattern.FindStringSubmatch(sqlQuery); m != nil && fetchPattern.MatchString(cleanedSQL) {
+			offset, limit = m[1], m[2]
+			sqlQuery = sqlQuery[:len(sqlQuery)-len(m[0])]
+		}
+	}
+	if !syntax.limitComma && limit == "" {
+		if m := limitCommaPattern.FindStringSubmatch(sqlQuery); m != nil && limitCommaPattern.MatchString(cleanedSQL) {
+			offset, limit = m[1], m[2]
+			sqlQuery = sqlQuery[:len(sqlQuery)-len(m[0])]
+		}
+	}
+	if limit == "" {
+		return sqlQuery
+	}
+
+	sqlQuery = trailingSemicolon.ReplaceAllString(sqlQuery, "") + " LIMIT " + limit
+	if offset != "" && offset != "0" {
+		sqlQuery += " OFFSET " + offset
+	}
+	return strings.TrimSpace(sqlQuery)
+}
+
+// withoutRowLimit removes the row limiting clause ending sqlQuery, leaving
+// those of subqueries. As in normalizeRowLimit, cleanedSQL must match too.
+func withoutRowLimit(sqlQuery, cleanedSQL string) string {
+	if loc := pagingPattern.FindStringIndex(sqlQuery); loc != nil && pagingPattern.MatchString(cleanedSQL) {
+		return sqlQuery[:loc[0]]
+	}
+	return sqlQuery
+}
@@ -0,0 +1,63 @@
+package mcpsqldb
+
+import "testing"
+
+func TestNormalizeRowLimit(t *testing.T) {
+	tests := []struct {
+		name     string
+		adapter  DBAdapter
+		query    string
+		expected string
+	}{
+		{"top", &SQLiteAdapter{}, "SELECT TOP 5 * FROM users", "SELECT * FROM users LIMIT 5"},
+		{"top parenthesized", &MySQLAdapter{}, "select distinct top (5) name from users;", "select distinct name from users LIMIT 5"},
+		{"fetch first", &MySQLAdapter{}, "SELECT * FROM users ORDER BY id FETCH FIRST 10 ROWS ONLY", "SELECT * FROM users ORDER BY id LIMIT 10"},
+		{"offset fetch", &SQLiteAdapter{}, "SELECT * FROM users ORDER BY id OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY;", "SELECT * FROM users ORDER BY id LIMIT 10 OFFSET 20"},
+		{"fetch native to postgres", &PostgresAdapter{}, "SELECT * FROM users FETCH FIRST 10 ROWS ONLY", "SELECT * FROM users FETCH FIRST 10 ROWS ONLY"},
+		{"limit comma on postgres", &PostgresAdapter{}, "SELECT * FROM users LIMIT 20, 10", "SELECT * FROM users LIMIT 10 OFFSET 20"},
+		{"limit comma native to mysql", &MySQLAdapter{}, "SELECT * FROM users LIMIT 20, 10", "SELECT * FROM users LIMIT 20, 10"},
+		{"plain limit", &PostgresAdapter{}, "SELECT * FROM users LIMIT 10 OFFSET 20", "SELECT * FROM users LIMIT 10 OFFSET 20"},
+		{"subquery untouched", &SQLiteAdapter{}, "SELECT * FROM (SELECT TOP 5 * FROM users) u", "SELECT * FROM (SELECT TOP 5 * FROM users) u"},
+		{"inside literal", &SQLiteAdapter{}, "SELECT * FROM users WHERE name = ' FETCH FIRST 1 ROWS ONLY'", "SELECT * FROM users WHERE name = ' FETCH FIRST 1 ROWS ONLY'"},
+		{"inside comment", &PostgresAdapter{}, "SELECT * FROM users -- LIMIT 1, 2", "SELECT * FROM users -- LIMIT 1, 2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.adapter.NormalizeRowLimit(tt.query); got != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestWithoutRowLimit(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{"SELECT * FROM users ORDER BY id LIMIT 10", "SELECT * FROM users ORDER BY id"},
+		{"SELECT * FROM users LIMIT 10 OFFSET 20;", "SELECT * FROM users"},
+		{"SELECT * FROM users LIMIT 20, 10", "SELECT * FROM users"},
+		{"SELECT * FROM users OFFSET 20 LIMIT 10", "SELECT * FROM users"},
+		{"SELECT * FROM users OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", "SELECT * FROM users"},
+		{"SELECT * FROM users FETCH FIRST 10 ROWS ONLY", "SELECT * FROM users"},
+		{"SELECT * FROM (SELECT * FROM users LIMIT 5) u", "SELECT * FROM (SELECT * FROM users LIMIT 5) u"},
+		{"SELECT * FROM users WHERE name = ' LIMIT 1'", "SELECT * FROM users WHERE name = ' LIMIT 1'"},
+	}
+
+	adapter := &PostgresAdapter{}
+	for _, tt := range tests {
+		if got := withoutRowLimit(tt.query, adapter.RemoveStringsAndComments(tt.query)); got != tt.expected {
+			t.Errorf("Expected %q, got %q", tt.expected, got)
+		}
+	}
+}
+
+func TestExecuteQuery_RewritesTop(t *testing.T) {
+	server := newTestServer(t)
+	rows := queryRows(t, server, "SELECT TOP 2 id FROM users ORDER BY id")
+	if len(rows) != 2 {
+		t.Errorf("Expected 2 rows, got %v", rows)
+	}
+}
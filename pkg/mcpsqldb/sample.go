@@ -0,0 +1,107 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+)
+
+// DefaultSampleRows is how many rows sample_rows returns unless asked
+const DefaultSampleRows = 10
+
+const (
+	// sampleScanThreshold is the estimated table size above which
+	// SampleQuery avoids shuffling the whole table
+	sampleScanThreshold = 100_000
+
+	// sampleOversample compensates for sampling methods that return fewer
+	// rows than their nominal fraction (TABLESAMPLE SYSTEM picks whole pages)
+	sampleOversample = 10
+
+	// minSampleFraction keeps the fraction printable in fixed-point SQL
+	minSampleFraction = 0.000001
+)
+
+// sampleFraction returns the fraction of a table of estimatedRows to sample
+// so that about limit rows remain, or 0 when the table is small (or its size
+// unknown) and should be sampled in full.
+func sampleFraction(limit int, estimatedRows int64) float64 {
+	if estimatedRows <= sampleScanThreshold {
+		return 0
+	}
+	fraction := float64(limit*sampleOversample) / float64(estimatedRows)
+	if fraction >= 1 {
+		return 0
+	}
+	return max(fraction, minSampleFraction)
+}
+
+// sampleRows returns random rows of a table, optionally as they were at a
+// past time, through the same limits, masking and audit as the query tool.
+func (s *Server) sampleRows(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	table, ok := args["table"].(string)
+	if !ok || table == "" {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'table' parameter",
+		}
+	}
+
+	limit, rpcErr := intArg(args, "limit", DefaultSampleRows, 1, math.MaxInt)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	limit = min(limit, tableMaxRows(table))
+
+	var asOf time.Time
+	if v, ok := args["as_of"]; ok {
+		value, _ := v.(string)
+		at, err := parseAsOf(value, time.Now())
+		if err != nil {
+			return nil, &Error{
+				Code:    InvalidParams,
+				Message: fmt.Sprintf("Invalid 'as_of' parameter: %v", err),
+			}
+		}
+		asOf = at
+	}
+
+	// Answer as if the table did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Table not found: %s", table)}},
+			IsError: true,
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	var sqlQuery string
+	var err error
+	if asOf.IsZero() {
+		sqlQuery, err = s.adapter.SampleQuery(ctx, s.db, s.databaseName, s.resolveName(table), limit)
+	} else {
+		sqlQuery, err = s.adapter.AsOfSampleQuery(ctx, s.db, s.resolveName(table), asOf, limit)
+	}
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	loggerFrom(ctx).Debug("Sampling table", "table", table, "sql", recordedSQL(s.adapter, sqlQuery))
+
+	// The generated query is checked like any other, as defense in depth
+	if _, err := s.validateQuery(sqlQuery); err != nil {
+		stats.queriesRejected.Add(1)
+		s.audit.query(ctx, s.adapter, s.sessionID, sqlQuery, AuditOutcomeRejected, 0, 0, err.Error())
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query rejected: %v", err)}},
+			IsError: true,
+		}, ErrorKindValidationRejected), nil
+	}
+
+	return s.runQuery(ctx, sqlQuery), nil
+}
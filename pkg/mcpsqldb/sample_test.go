@@ -0,0 +1,102 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestSampleFraction(t *testing.T) {
+	tests := []struct {
+		limit    int
+		estimate int64
+		want     float64
+	}{
+		{10, -1, 0},
+		{10, 50_000, 0},
+		{10, 1_000_000, 0.0001},
+		{20_000, 150_000, 0},
+		{1, 1_000_000_000_000, minSampleFraction},
+	}
+	for _, tc := range tests {
+		if got := sampleFraction(tc.limit, tc.estimate); got != tc.want {
+			t.Errorf("Expected sampleFraction(%d, %d) = %v, got %v", tc.limit, tc.estimate, tc.want, got)
+		}
+	}
+}
+
+func TestSampleRows(t *testing.T) {
+	server := newDemoServer(t)
+
+	result, rpcErr := server.callTool(context.Background(), "sample_rows", map[string]any{"table": "orders", "limit": float64(3)})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected sample, got %v %+v", rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil || len(rows) != 3 {
+		t.Errorf("Expected 3 rows, got %s", result.Content[0].Text)
+	}
+
+	result, _ = server.callTool(context.Background(), "sample_rows", map[string]any{"table": "products"})
+	if json.Unmarshal([]byte(result.Content[0].Text), &rows); len(rows) != 6 {
+		t.Errorf("Expected all 6 products with the default limit, got %s", result.Content[0].Text)
+	}
+}
+
+func TestSampleRows_Errors(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"customers"}
+	server := newDemoServer(t)
+
+	for _, args := range []map[string]any{{}, {"table": "orders", "limit": float64(0)}, {"table": "orders", "limit": 2.5}} {
+		if _, rpcErr := server.callTool(context.Background(), "sample_rows", args); rpcErr == nil {
+			t.Errorf("Expected invalid params error for %v", args)
+		}
+	}
+
+	result, _ := server.callTool(context.Background(), "sample_rows", map[string]any{"table": "customers"})
+	if !result.IsError || result.Content[0].Text != "Table not found: customers" {
+		t.Errorf("Expected denied table to look missing, got %+v", result)
+	}
+
+	// Identifiers are quoted, so a crafted name is at worst a missing table
+	for _, table := range []string{`orders"; DROP TABLE orders; --`, `orders" WHERE 1=0 UNION SELECT * FROM "customers`} {
+		result, _ = server.callTool(context.Background(), "sample_rows", map[string]any{"table": table})
+		if !result.IsError {
+			t.Errorf("Expected an error for table %q, got %s", table, result.Content[0].Text)
+		}
+	}
+	result, _ = server.callTool(context.Background(), "sample_rows", map[string]any{"table": "orders"})
+	if result.IsError {
+		t.Errorf("Expected orders to be intact, got %s", result.Content[0].Text)
+	}
+}
+
+func TestSQLiteSampleQuery_PrefiltersLargeTables(t *testing.T) {
+	path := newTestDB(t, "INSERT INTO users (id, name) VALUES (5000000, 'zed')")
+	server := openTestServer(t, path)
+
+	query, err := server.adapter.SampleQuery(context.Background(), server.db, server.databaseName, "users", 10)
+	if err != nil {
+		t.Fatalf("SampleQuery failed: %v", err)
+	}
+	if want := `SELECT * FROM "users" WHERE abs(random() % 1000000) < 20 ORDER BY RANDOM() LIMIT 10`; query != want {
+		t.Errorf("Expected %q, got %q", want, query)
+	}
+}
+
+func TestSampleQueries_PassValidation(t *testing.T) {
+	tests := []struct {
+		adapter DBAdapter
+		sql     string
+	}{
+		{&PostgresAdapter{}, `SELECT * FROM "orders" TABLESAMPLE SYSTEM (0.100000) ORDER BY random() LIMIT 10`},
+		{&MySQLAdapter{}, "SELECT * FROM `orders` WHERE RAND() < 0.001000 ORDER BY RAND() LIMIT 10"},
+		{&SQLiteAdapter{}, `SELECT * FROM "orders" WHERE abs(random() % 1000000) < 1000 ORDER BY RANDOM() LIMIT 10`},
+	}
+	for _, tc := range tests {
+		if err := tc.adapter.ValidateQuery(tc.sql); err != nil {
+			t.Errorf("Expected %s sample query to pass validation, got %v", tc.adapter.DriverName(), err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package mcpsqldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SavedResultMaxRows caps the rows of a result saved with the query tool's
+// save_as parameter (overridable via MCP_SAVED_RESULT_MAX_ROWS env var)
+var SavedResultMaxRows = 1000
+
+// MaxSavedResults caps the results a session may keep saved at once.
+const MaxSavedResults = 20
+
+// expandablePattern matches the statements saved results can be added to
+// as common table expressions.
+var expandablePattern = regexp.MustCompile(`(?i)^\s*(?:SELECT\b|\()`)
+
+// savedResult is a query result kept by name for later queries.
+type savedResult struct {
+	name    string
+	columns []string
+	rows    []map[string]any
+	// reference matches the name in a query with strings and comments
+	// removed
+	reference *regexp.Regexp
+}
+
+// savedResults holds a session's saved results, keyed by lowercased name;
+// it is safe for concurrent use.
+type savedResults struct {
+	mu      sync.Mutex
+	results map[string]*savedResult
+}
+
+func newSavedResults() *savedResults {
+	return &savedResults{results: make(map[string]*savedResult)}
+}
+
+// save keeps the rows of a query tool result under name, replacing any
+// result saved under it before.
+func (sr *savedResults) save(name, resultText string) error {
+	rows, warning, err := decodeRows(resultText)
+	if err != nil {
+		return fmt.Errorf("failed to decode result: %w", err)
+	}
+	switch {
+	case warning != "":
+		return fmt.Errorf("the result is incomplete (%s)", warning)
+	case len(rows) == 0:
+		return fmt.Errorf("the result has no rows")
+	case len(rows) > SavedResultMaxRows:
+		return fmt.Errorf("the result has %d rows, more than the %d that can be saved", len(rows), SavedResultMaxRows)
+	}
+
+	// Rows are returned with their columns in name order, so keep that order
+	columns := make([]string, 0, len(rows[0]))
+	for col := range rows[0] {
+		columns = append(columns, col)
+	}
+	sort.Strings(columns)
+
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	key := strings.ToLower(name)
+	if _, ok := sr.results[key]; !ok && len(sr.results) >= MaxSavedResults {
+		return fmt.Errorf("%d results are saved already", MaxSavedResults)
+	}
+	sr.results[key] = &savedResult{
+		name:      name,
+		columns:   columns,
+		rows:      rows,
+		reference: regexp.MustCompile(`(?i)(?:^|[^\w.$])` + regexp.QuoteMeta(name) + `\b`),
+	}
+	return nil
+}
+
+// expand returns sqlQuery with every saved result it references defined as
+// a common table expression, or sqlQuery unchanged when it references none.
+func (sr *savedResults) expand(adapter DBAdapter, sqlQuery string) string {
+	cleaned := adapter.RemoveStringsAndComments(sqlQuery)
+	if !expandablePattern.MatchString(cleaned) {
+		return sqlQuery
+	}
+
+	sr.mu.Lock()
+	var ctes []string
+	for _, result := range sr.results {
+		if result.reference.MatchString(cleaned) {
+			ctes = append(ctes, result.name+" AS ("+result.query(adapter)+")")
+		}
+	}
+	sr.mu.Unlock()
+	if len(ctes) == 0 {
+		return sqlQuery
+	}
+	sort.Strings(ctes)
+
+	return "WITH " + strings.Join(ctes, ", ") + " " + sqlQuery
+}
+
+// savedResultInfo describes a saved result in server_status.
+type savedResultInfo struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+	Rows    int      `json:"rows"`
+}
+
+// list describes the saved results, by name.
+func (sr *savedResults) list() []savedResultInfo {
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	infos := make([]savedResultInfo, 0, len(sr.results))
+	for _, result := range sr.results {
+		infos = append(infos, savedResultInfo{Name: result.name, Columns: result.columns, Rows: len(result.rows)})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// query returns the SELECT producing the saved rows.
+func (r *savedResult) query(adapter DBAdapter) string {
+	rows := make([][]string, len(r.rows))
+	for i, row := range r.rows {
+		rows[i] = make([]string, len(r.columns))
+		for j, col := range r.columns {
+			rows[i][j] = sqlLiteral(adapter, row[col])
+		}
+	}
+	return adapter.ValuesQuery(r.columns, rows)
+}
+
+// sqlLiteral writes a decoded result value as an SQL literal. Nested JSON
+// values become their JSON text.
+func sqlLiteral(adapter DBAdapter, val any) string {
+	switch v := val.(type) {
+	case nil:
+		return "NULL"
+	case json.Number:
+		return v.String()
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	case string:
+		return adapter.QuoteString(v)
+	default:
+		text, _ := json.Marshal(v)
+		return adapter.QuoteString(string(text))
+	}
+}
+
+// valuesQuery selects rows from a VALUES list, naming its columns, which
+// both PostgreSQL and SQLite call column1, column2, ...
+func valuesQuery(adapter DBAdapter, columns []string, rows [][]string) string {
+	aliases := make([]string, len(columns))
+	for i, col := range columns {
+		aliases[i] = fmt.Sprintf("column%d AS %s", i+1, adapter.QuoteIdentifier(col))
+	}
+	values := make([]string, len(rows))
+	for i, row := range rows {
+		values[i] = "(" + strings.Join(row, ", ") + ")"
+	}
+	return fmt.Sprintf("SELECT %s FROM (VALUES %s) AS saved_values", strings.Join(aliases, ", "), strings.Join(values, ", "))
+}
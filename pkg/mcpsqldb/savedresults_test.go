@@ -0,0 +1,90 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSaveAs_ReadableByLaterQueries(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	saved := queryRowsArgs(t, server, map[string]any{
+		"sql":     "SELECT id, name || '''s' AS label FROM users WHERE id <= 2",
+		"save_as": "picked",
+	})
+	if len(saved) != 2 {
+		t.Fatalf("Expected 2 rows, got %v", saved)
+	}
+
+	rows := queryRows(t, server, "SELECT label FROM Picked ORDER BY id DESC")
+	want := []map[string]any{{"label": "bob's"}, {"label": "alice's"}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Expected %v, got %v", want, rows)
+	}
+
+	// Joined with real tables
+	rows = queryRows(t, server, "SELECT COUNT(*) AS n FROM users u JOIN picked p ON p.id = u.id")
+	if len(rows) != 1 || rows[0]["n"] != float64(2) {
+		t.Errorf("Expected a count of 2, got %v", rows)
+	}
+
+	// A name in a string literal is not a reference
+	if got := server.saved.expand(server.adapter, "SELECT 'picked' AS x"); got != "SELECT 'picked' AS x" {
+		t.Errorf("Expected the query unchanged, got %s", got)
+	}
+
+	status, _ := server.serverStatus(ctx)
+	if !strings.Contains(status.Content[0].Text, `"name": "picked"`) {
+		t.Errorf("Expected server_status to list the saved result, got %s", status.Content[0].Text)
+	}
+}
+
+func TestSaveAs_RejectsIncompleteResults(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 1
+	server := newTestServer(t)
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id FROM users", "save_as": "ids"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected rows, got %v %+v", rpcErr, result)
+	}
+	if len(result.Content) != 2 || !strings.Contains(result.Content[1].Text, "not saved") {
+		t.Errorf("Expected a note that the result was not saved, got %+v", result.Content)
+	}
+	if got := server.saved.list(); len(got) != 0 {
+		t.Errorf("Expected nothing saved, got %v", got)
+	}
+
+	_, rpcErr = server.executeQuery(context.Background(), map[string]any{"sql": "SELECT 1", "save_as": "bad name"})
+	if rpcErr == nil || rpcErr.Code != InvalidParams {
+		t.Errorf("Expected invalid params for a name that is not an identifier, got %v", rpcErr)
+	}
+}
+
+func TestMySQLValuesQuery(t *testing.T) {
+	adapter := &MySQLAdapter{}
+	got := adapter.ValuesQuery([]string{"id", "path"}, [][]string{
+		{"1", adapter.QuoteString("it's")},
+		{"2", adapter.QuoteString(`C:\tmp`)},
+	})
+	want := "SELECT 1 AS `id`, 'it''s' AS `path` UNION ALL SELECT 2, CONVERT(X'433a5c746d70' USING utf8mb4)"
+	if got != want {
+		t.Errorf("Expected %s, got %s", want, got)
+	}
+}
+
+func queryRowsArgs(t *testing.T, server *Server, args map[string]any) []map[string]any {
+	t.Helper()
+	result, rpcErr := server.executeQuery(context.Background(), args)
+	if rpcErr != nil || result.IsError || len(result.Content) != 1 {
+		t.Fatalf("Expected rows for %v, got %v %+v", args, rpcErr, result)
+	}
+	rows, _, err := decodeRows(result.Content[0].Text)
+	if err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	return rows
+}
@@ -0,0 +1,354 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaSnapshotDir is a directory snapshot_schema also writes snapshots
+// to, as <name>.json, so schema_diff can compare against them in later
+// sessions; empty keeps them for the session only (overridable via
+// MCP_SCHEMA_SNAPSHOT_DIR env var)
+var SchemaSnapshotDir = ""
+
+// schemaSnapshot is the schema as snapshot_schema recorded it, and the
+// format of snapshot files.
+type schemaSnapshot struct {
+	Database string          `json:"database"`
+	Driver   string          `json:"driver"`
+	TakenAt  time.Time       `json:"taken_at"`
+	Tables   []snapshotTable `json:"tables"`
+}
+
+type snapshotTable struct {
+	Name    string           `json:"name"`
+	Columns []snapshotColumn `json:"columns"`
+	// ForeignKeys are written as "(columns) -> table(columns)"
+	ForeignKeys []string `json:"foreign_keys,omitempty"`
+}
+
+type snapshotColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+}
+
+// schemaSnapshots holds a session's schema snapshots by name; it is safe
+// for concurrent use.
+type schemaSnapshots struct {
+	mu        sync.Mutex
+	snapshots map[string]*schemaSnapshot
+}
+
+func newSchemaSnapshots() *schemaSnapshots {
+	return &schemaSnapshots{snapshots: make(map[string]*schemaSnapshot)}
+}
+
+// save keeps snapshot under name and, with SchemaSnapshotDir set, writes
+// it there, returning the file written.
+func (ss *schemaSnapshots) save(name string, snapshot *schemaSnapshot) (string, error) {
+	ss.mu.Lock()
+	ss.snapshots[name] = snapshot
+	ss.mu.Unlock()
+
+	if SchemaSnapshotDir == "" {
+		return "", nil
+	}
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(SchemaSnapshotDir, name+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o600); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// load returns the snapshot saved under name in this session or, failing
+// that, read from SchemaSnapshotDir. It returns nil if there is none.
+func (ss *schemaSnapshots) load(name string) (*schemaSnapshot, error) {
+	ss.mu.Lock()
+	snapshot := ss.snapshots[name]
+	ss.mu.Unlock()
+	if snapshot != nil || SchemaSnapshotDir == "" {
+		return snapshot, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(SchemaSnapshotDir, name+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	snapshot = &schemaSnapshot{}
+	if err := json.Unmarshal(data, snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot file %s.json: %w", name, err)
+	}
+	return snapshot, nil
+}
+
+// newSchemaSnapshot records tables, which must already be filtered by
+// visibleTables.
+func newSchemaSnapshot(databaseName, driver string, tables []TableInfo) *schemaSnapshot {
+	snapshot := &schemaSnapshot{
+		Database: databaseName,
+		Driver:   driver,
+		TakenAt:  time.Now().UTC().Truncate(time.Second),
+		Tables:   make([]snapshotTable, 0, len(tables)),
+	}
+	for _, table := range tables {
+		st := snapshotTable{Name: table.Name, Columns: make([]snapshotColumn, 0, len(table.Columns))}
+		for _, col := range table.Columns {
+			st.Columns = append(st.Columns, snapshotColumn{Name: col.Name, Type: col.Type, PrimaryKey: col.PrimaryKey})
+		}
+		for _, fk := range table.ForeignKeys {
+			st.ForeignKeys = append(st.ForeignKeys, fmt.Sprintf("(%s) -> %s(%s)",
+				strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", ")))
+		}
+		snapshot.Tables = append(snapshot.Tables, st)
+	}
+	return snapshot
+}
+
+// tableChange lists what changed in a table present in both schemas.
+type tableChange struct {
+	Table          string           `json:"table"`
+	AddedColumns   []snapshotColumn `json:"added_columns,omitempty"`
+	RemovedColumns []snapshotColumn `json:"removed_columns,omitempty"`
+	// ChangedColumns maps each changed column to the before and after
+	// values of its changed attributes
+	ChangedColumns     map[string]map[string]map[string]any `json:"changed_columns,omitempty"`
+	AddedForeignKeys   []string                             `json:"added_foreign_keys,omitempty"`
+	RemovedForeignKeys []string                             `json:"removed_foreign_keys,omitempty"`
+}
+
+// schemaDiff is the schema_diff result.
+type schemaDiff struct {
+	Snapshot      string        `json:"snapshot"`
+	TakenAt       time.Time     `json:"taken_at"`
+	AddedTables   []string      `json:"added_tables"`
+	RemovedTables []string      `json:"removed_tables"`
+	ChangedTables []tableChange `json:"changed_tables"`
+}
+
+// diffSchemas compares the current schema with an earlier one. Tables and
+// columns are matched by name, so a rename shows as a removal and an
+// addition. Tables now denied are left out of both.
+func diffSchemas(before, after *schemaSnapshot) schemaDiff {
+	diff := schemaDiff{AddedTables: []string{}, RemovedTables: []string{}, ChangedTables: []tableChange{}}
+	beforeTables := map[string]snapshotTable{}
+	for _, table := range before.Tables {
+		if !isTableDenied(DeniedTables, table.Name) {
+			beforeTables[table.Name] = table
+		}
+	}
+	afterTables := map[string]bool{}
+	for _, table := range after.Tables {
+		afterTables[table.Name] = true
+		old, ok := beforeTables[table.Name]
+		if !ok {
+			diff.AddedTables = append(diff.AddedTables, table.Name)
+		} else if change := diffTable(old, table); change != nil {
+			diff.ChangedTables = append(diff.ChangedTables, *change)
+		}
+	}
+	for name := range beforeTables {
+		if !afterTables[name] {
+			diff.RemovedTables = append(diff.RemovedTables, name)
+		}
+	}
+	sort.Strings(diff.AddedTables)
+	sort.Strings(diff.RemovedTables)
+	sort.Slice(diff.ChangedTables, func(i, j int) bool { return diff.ChangedTables[i].Table < diff.ChangedTables[j].Table })
+	return diff
+}
+
+// diffTable returns the changes between two versions of a table, or nil if
+// there are none.
+func diffTable(before, after snapshotTable) *tableChange {
+	change := &tableChange{Table: after.Name, ChangedColumns: map[string]map[string]map[string]any{}}
+	beforeColumns := map[string]snapshotColumn{}
+	for _, col := range before.Columns {
+		beforeColumns[col.Name] = col
+	}
+	afterColumns := map[string]bool{}
+	for _, col := range after.Columns {
+		afterColumns[col.Name] = true
+		old, ok := beforeColumns[col.Name]
+		if !ok {
+			change.AddedColumns = append(change.AddedColumns, col)
+			continue
+		}
+		changes := map[string]map[string]any{}
+		if old.Type != col.Type {
+			changes["type"] = map[string]any{"before": old.Type, "after": col.Type}
+		}
+		if old.PrimaryKey != col.PrimaryKey {
+			changes["primary_key"] = map[string]any{"before": old.PrimaryKey, "after": col.PrimaryKey}
+		}
+		if len(changes) > 0 {
+			change.ChangedColumns[col.Name] = changes
+		}
+	}
+	for _, col := range before.Columns {
+		if !afterColumns[col.Name] {
+			change.RemovedColumns = append(change.RemovedColumns, col)
+		}
+	}
+	for _, fk := range after.ForeignKeys {
+		if !slices.Contains(before.ForeignKeys, fk) {
+			change.AddedForeignKeys = append(change.AddedForeignKeys, fk)
+		}
+	}
+	for _, fk := range before.ForeignKeys {
+		if !slices.Contains(after.ForeignKeys, fk) {
+			change.RemovedForeignKeys = append(change.RemovedForeignKeys, fk)
+		}
+	}
+
+	if len(change.AddedColumns) == 0 && len(change.RemovedColumns) == 0 && len(change.ChangedColumns) == 0 &&
+		len(change.AddedForeignKeys) == 0 && len(change.RemovedForeignKeys) == 0 {
+		return nil
+	}
+	return change
+}
+
+// snapshotSchemaTool describes snapshot_schema.
+func snapshotSchemaTool() Tool {
+	return Tool{
+		Name: "snapshot_schema",
+		Description: "Record the current tables, columns and foreign keys under a name, for schema_diff to compare " +
+			"the schema against later",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"name": {
+					Type:        "string",
+					Description: "Name to record the snapshot under, e.g. release_2_3; an existing snapshot of that name is replaced",
+				},
+			},
+			Required: []string{"name"},
+		},
+	}
+}
+
+// schemaDiffTool describes schema_diff.
+func schemaDiffTool() Tool {
+	return Tool{
+		Name: "schema_diff",
+		Description: "Compare the current schema with a snapshot recorded by snapshot_schema and report the tables " +
+			"added and removed, and the columns and foreign keys added, removed and changed in the others",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"snapshot": {
+					Type:        "string",
+					Description: "Name of the snapshot to compare against",
+				},
+			},
+			Required: []string{"snapshot"},
+		},
+	}
+}
+
+// currentSchema describes the visible tables of the database.
+func (s *Server) currentSchema(ctx context.Context) (*schemaSnapshot, *CallToolResult, *Error) {
+	if isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return nil, &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Database %q is hidden by MCP_DENY_SCHEMAS", s.databaseName)}},
+			IsError: true,
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	tables, err := s.adapter.DescribeSchema(ctx, s.db, s.databaseName)
+	if err != nil {
+		return nil, withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to describe schema: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	return newSchemaSnapshot(s.databaseName, s.adapter.DriverName(), visibleTables(tables)), nil, nil
+}
+
+// snapshotSchema records the current schema under a name.
+func (s *Server) snapshotSchema(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	name, _ := args["name"].(string)
+	if !crossNamePattern.MatchString(name) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'name' parameter: expected a plain identifier",
+		}
+	}
+
+	snapshot, result, rpcErr := s.currentSchema(ctx)
+	if snapshot == nil {
+		return result, rpcErr
+	}
+	path, err := s.schemas.save(name, snapshot)
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Snapshot %s kept for this session only; failed to write it: %v", name, err)}},
+			IsError: true,
+		}, nil
+	}
+
+	text := fmt.Sprintf("Recorded schema snapshot %s of %d tables", name, len(snapshot.Tables))
+	if path != "" {
+		text += " in " + path
+	}
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: text}},
+	}, nil
+}
+
+// schemaDiff compares the current schema with a recorded snapshot.
+func (s *Server) schemaDiff(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	name, _ := args["snapshot"].(string)
+	if !crossNamePattern.MatchString(name) {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Missing or invalid 'snapshot' parameter: expected a plain identifier",
+		}
+	}
+
+	before, err := s.schemas.load(name)
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to read snapshot %s: %v", name, err)}},
+			IsError: true,
+		}, nil
+	}
+	if before == nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Schema snapshot not found: %s", name)}},
+			IsError: true,
+		}, nil
+	}
+
+	after, result, rpcErr := s.currentSchema(ctx)
+	if after == nil {
+		return result, rpcErr
+	}
+	diff := diffSchemas(before, after)
+	diff.Snapshot, diff.TakenAt = name, before.TakenAt
+	return statementReportResult(diff)
+}
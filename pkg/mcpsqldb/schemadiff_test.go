@@ -0,0 +1,116 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSchemaDiff_ReportsChanges(t *testing.T) {
+	path := newTestDB(t, "CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER)",
+		"CREATE TABLE legacy (id INTEGER)")
+	server := openTestServer(t, path)
+	ctx := context.Background()
+
+	result, rpcErr := server.snapshotSchema(ctx, map[string]any{"name": "v1"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected a snapshot, got %v %+v", rpcErr, result)
+	}
+	if diff := callToolJSON[schemaDiff](t, server, "schema_diff", map[string]any{"snapshot": "v1"}); len(diff.AddedTables)+len(diff.RemovedTables)+len(diff.ChangedTables) != 0 {
+		t.Errorf("Expected no changes yet, got %+v", diff)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	for _, stmt := range []string{
+		"ALTER TABLE users ADD COLUMN email TEXT",
+		"DROP TABLE orders",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, user_id INTEGER REFERENCES users(id), total REAL)",
+		"DROP TABLE legacy",
+		"CREATE TABLE invoices (id INTEGER PRIMARY KEY)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to change schema: %v", err)
+		}
+	}
+
+	diff := callToolJSON[schemaDiff](t, server, "schema_diff", map[string]any{"snapshot": "v1"})
+	if diff.Snapshot != "v1" || diff.TakenAt.IsZero() {
+		t.Errorf("Expected the snapshot name and time, got %+v", diff)
+	}
+	if !reflect.DeepEqual(diff.AddedTables, []string{"invoices"}) || !reflect.DeepEqual(diff.RemovedTables, []string{"legacy"}) {
+		t.Errorf("Expected invoices added and legacy removed, got %v and %v", diff.AddedTables, diff.RemovedTables)
+	}
+	want := []tableChange{
+		{
+			Table:            "orders",
+			AddedColumns:     []snapshotColumn{{Name: "total", Type: "REAL"}},
+			AddedForeignKeys: []string{"(user_id) -> users(id)"},
+		},
+		{Table: "users", AddedColumns: []snapshotColumn{{Name: "email", Type: "TEXT"}}},
+	}
+	if !reflect.DeepEqual(diff.ChangedTables, want) {
+		t.Errorf("Expected changes %+v, got %+v", want, diff.ChangedTables)
+	}
+}
+
+func TestSchemaDiff_ChangedColumns(t *testing.T) {
+	before := snapshotTable{Name: "t", Columns: []snapshotColumn{{Name: "id", Type: "INT", PrimaryKey: true}, {Name: "code", Type: "TEXT"}}}
+	after := snapshotTable{Name: "t", Columns: []snapshotColumn{{Name: "id", Type: "BIGINT", PrimaryKey: true}, {Name: "code", Type: "TEXT", PrimaryKey: true}}}
+
+	change := diffTable(before, after)
+	want := map[string]map[string]map[string]any{
+		"id":   {"type": {"before": "INT", "after": "BIGINT"}},
+		"code": {"primary_key": {"before": false, "after": true}},
+	}
+	if change == nil || !reflect.DeepEqual(change.ChangedColumns, want) {
+		t.Errorf("Expected changed columns %v, got %+v", want, change)
+	}
+	if change := diffTable(before, before); change != nil {
+		t.Errorf("Expected no change, got %+v", change)
+	}
+}
+
+func TestSchemaDiff_SnapshotFiles(t *testing.T) {
+	defer func(orig string) { SchemaSnapshotDir = orig }(SchemaSnapshotDir)
+	SchemaSnapshotDir = t.TempDir()
+	ctx := context.Background()
+
+	result, _ := newTestServer(t).snapshotSchema(ctx, map[string]any{"name": "release_1"})
+	wantPath := filepath.Join(SchemaSnapshotDir, "release_1.json")
+	if result.IsError || !strings.Contains(result.Content[0].Text, wantPath) {
+		t.Fatalf("Expected the snapshot written to %s, got %+v", wantPath, result)
+	}
+
+	// A later session compares against the file
+	server := newTestServer(t, "CREATE TABLE extra (id INTEGER)")
+	if diff := callToolJSON[schemaDiff](t, server, "schema_diff", map[string]any{"snapshot": "release_1"}); !reflect.DeepEqual(diff.AddedTables, []string{"extra"}) {
+		t.Errorf("Expected extra added, got %+v", diff)
+	}
+
+	result, _ = server.schemaDiff(ctx, map[string]any{"snapshot": "missing"})
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "not found") {
+		t.Errorf("Expected a missing snapshot error, got %+v", result)
+	}
+	if _, rpcErr := server.schemaDiff(ctx, map[string]any{"snapshot": "../etc/passwd"}); rpcErr == nil {
+		t.Error("Expected a path to be rejected as a snapshot name")
+	}
+}
+
+func TestSchemaDiff_HidesDeniedTables(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	server := newTestServer(t, "CREATE TABLE secrets (id INTEGER)")
+	ctx := context.Background()
+
+	server.snapshotSchema(ctx, map[string]any{"name": "v1"})
+	DeniedTables = []string{"secrets"}
+	if diff := callToolJSON[schemaDiff](t, server, "schema_diff", map[string]any{"snapshot": "v1"}); len(diff.RemovedTables) != 0 {
+		t.Errorf("Expected a newly denied table not to be reported, got %+v", diff)
+	}
+}
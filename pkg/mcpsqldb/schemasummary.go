@@ -0,0 +1,144 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// summarizeSchema describes every visible table, its columns and how the
+// tables relate, as plain text a model can read in one go instead of
+// fetching each schema resource.
+func (s *Server) summarizeSchema(ctx context.Context) (*CallToolResult, *Error) {
+	if isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Database %q is hidden by MCP_DENY_SCHEMAS", s.databaseName)}},
+			IsError: true,
+		}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	tables, err := s.adapter.DescribeSchema(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to describe schema: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+	withHypertableRows(tables, s.hypertables(ctx))
+
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: formatSchemaSummary(s.databaseName, s.adapter.DriverName(), visibleTables(tables))}},
+	}, nil
+}
+
+// visibleTables drops denied tables, and foreign keys pointing at them, so
+// the summary reveals nothing resources/list would not.
+func visibleTables(tables []TableInfo) []TableInfo {
+	var visible []TableInfo
+	for _, table := range tables {
+		if isTableDenied(DeniedTables, table.Name) {
+			continue
+		}
+		var fks []ForeignKey
+		for _, fk := range table.ForeignKeys {
+			if !isTableDenied(DeniedTables, fk.RefTable) {
+				fks = append(fks, fk)
+			}
+		}
+		table.ForeignKeys = fks
+		visible = append(visible, table)
+	}
+	return visible
+}
+
+// formatSchemaSummary renders tables, which must be sorted by name, as a
+// deterministic plain-text overview.
+func formatSchemaSummary(databaseName, driver string, tables []TableInfo) string {
+	referencedBy := map[string][]string{}
+	relationships := 0
+	for _, table := range tables {
+		for _, fk := range table.ForeignKeys {
+			relationships++
+			referencedBy[fk.RefTable] = append(referencedBy[fk.RefTable],
+				table.Name+"."+strings.Join(fk.Columns, ","))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Database %q (%s): %d %s, %d %s.\n", databaseName, driver,
+		len(tables), plural(len(tables), "table", "tables"),
+		relationships, plural(relationships, "foreign key", "foreign keys"))
+
+	for _, table := range tables {
+		b.WriteString("\n")
+		b.WriteString(table.Name)
+		if table.Rows >= 0 {
+			fmt.Fprintf(&b, " (~%d %s)", table.Rows, plural(int(table.Rows), "row", "rows"))
+		}
+		if table.Comment != "" {
+			b.WriteString(": " + oneLine(table.Comment))
+		}
+		b.WriteString("\n")
+
+		columns := make([]string, len(table.Columns))
+		var comments []string
+		for i, col := range table.Columns {
+			columns[i] = col.Name + " " + col.Type
+			if col.PrimaryKey {
+				columns[i] += " PK"
+			}
+			if col.Comment != "" {
+				comments = append(comments, col.Name+": "+oneLine(col.Comment))
+			}
+		}
+		fmt.Fprintf(&b, "  columns: %s\n", strings.Join(columns, ", "))
+		if len(comments) > 0 {
+			fmt.Fprintf(&b, "  notes: %s\n", strings.Join(comments, "; "))
+		}
+
+		if len(table.ForeignKeys) > 0 {
+			refs := make([]string, len(table.ForeignKeys))
+			for i, fk := range table.ForeignKeys {
+				refs[i] = formatForeignKey(fk)
+			}
+			sort.Strings(refs)
+			fmt.Fprintf(&b, "  references: %s\n", strings.Join(refs, "; "))
+		}
+		if sources := referencedBy[table.Name]; len(sources) > 0 {
+			sort.Strings(sources)
+			fmt.Fprintf(&b, "  referenced by: %s\n", strings.Join(sources, ", "))
+		}
+	}
+	return b.String()
+}
+
+// formatForeignKey renders fk as "col -> table.col"; a reference to the
+// table's primary key without named columns renders as "col -> table".
+func formatForeignKey(fk ForeignKey) string {
+	target := fk.RefTable
+	if strings.Join(fk.RefColumns, "") != "" {
+		target += "." + strings.Join(fk.RefColumns, ",")
+	}
+	return strings.Join(fk.Columns, ",") + " -> " + target
+}
+
+func plural(n int, singular, pluralForm string) string {
+	if n == 1 {
+		return singular
+	}
+	return pluralForm
+}
+
+// oneLine collapses whitespace so comments cannot break the layout.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
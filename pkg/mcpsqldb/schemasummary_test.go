@@ -0,0 +1,87 @@
+package mcpsqldb
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func newDemoServer(t *testing.T) *Server {
+	t.Helper()
+	dsn, err := createDemoDatabase(t.TempDir())
+	if err != nil {
+		t.Fatalf("createDemoDatabase failed: %v", err)
+	}
+	server, err := newServer(context.Background(), &SQLiteAdapter{}, dsn)
+	if err != nil {
+		t.Fatalf("Failed to create server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func TestSummarizeSchema_SQLite(t *testing.T) {
+	server := newDemoServer(t)
+
+	result, rpcErr := server.callTool(context.Background(), "summarize_schema", nil)
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected summary, got %v %+v", rpcErr, result)
+	}
+
+	want := `Database "demo" (sqlite): 3 tables, 2 foreign keys.
+
+customers
+  columns: id INTEGER PK, name TEXT, email TEXT, country TEXT, created_at TEXT
+  referenced by: orders.customer_id
+
+orders
+  columns: id INTEGER PK, customer_id INTEGER, product_id INTEGER, quantity INTEGER, status TEXT, ordered_at TEXT
+  references: customer_id -> customers.id; product_id -> products.id
+
+products
+  columns: id INTEGER PK, name TEXT, category TEXT, price_cents INTEGER
+  referenced by: orders.product_id
+`
+	if got := result.Content[0].Text; got != want {
+		t.Errorf("Expected summary:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestSummarizeSchema_HidesDeniedTables(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"customers"}
+
+	server := newDemoServer(t)
+	result, _ := server.callTool(context.Background(), "summarize_schema", nil)
+	if strings.Contains(result.Content[0].Text, "customers") {
+		t.Errorf("Expected denied table to be hidden, got:\n%s", result.Content[0].Text)
+	}
+	if !strings.Contains(result.Content[0].Text, "2 tables, 1 foreign key.") {
+		t.Errorf("Expected counts without the denied table, got:\n%s", result.Content[0].Text)
+	}
+}
+
+func TestFormatSchemaSummary_RowsAndComments(t *testing.T) {
+	tables := []TableInfo{{
+		Name:    "accounts",
+		Comment: "Billing\naccounts",
+		Rows:    1,
+		Columns: []ColumnInfo{
+			{Name: "id", Type: "bigint", PrimaryKey: true},
+			{Name: "plan", Type: "text", Comment: "free, pro or team"},
+		},
+		ForeignKeys: []ForeignKey{{Columns: []string{"org_id", "region"}, RefTable: "orgs", RefColumns: []string{"id", "region"}}},
+	}}
+
+	got := formatSchemaSummary("app", "postgres", tables)
+	for _, want := range []string{
+		`Database "app" (postgres): 1 table, 1 foreign key.`,
+		"accounts (~1 row): Billing accounts\n",
+		"  notes: plan: free, pro or team\n",
+		"  references: org_id,region -> orgs.id,region\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected summary to contain %q, got:\n%s", want, got)
+		}
+	}
+}
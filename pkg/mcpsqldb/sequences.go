@@ -0,0 +1,111 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// sequencesReport is the list_sequences result, fullest first.
+type sequencesReport struct {
+	Driver    string           `json:"driver"`
+	Sequences []sequenceReport `json:"sequences"`
+}
+
+// sequenceReport is a sequence with how much of its range is used.
+type sequenceReport struct {
+	SequenceInfo
+	// UsedPercent is the share of the values up to MaxValue handed out, when
+	// the current value is known
+	UsedPercent *float64 `json:"used_percent,omitempty"`
+}
+
+// integerMaxValues maps integer column types to their largest signed value.
+var integerMaxValues = map[string]uint64{
+	"tinyint":   math.MaxInt8,
+	"smallint":  math.MaxInt16,
+	"mediumint": 1<<23 - 1,
+	"int":       math.MaxInt32,
+	"integer":   math.MaxInt32,
+	"bigint":    math.MaxInt64,
+}
+
+// integerMaxValue returns the largest value an integer column of dataType
+// holds, or 0 for other types.
+func integerMaxValue(dataType string, unsigned bool) uint64 {
+	max := integerMaxValues[strings.ToLower(dataType)]
+	if unsigned {
+		return max<<1 | 1
+	}
+	return max
+}
+
+// usedPercent returns the share of the range up to info.MaxValue handed out,
+// judged by the next value, or nil when it is unknown.
+func usedPercent(info SequenceInfo) *float64 {
+	var used int64
+	switch {
+	case info.NextValue != nil:
+		used = *info.NextValue - 1
+	case info.LastValue != nil:
+		used = *info.LastValue
+	default:
+		return nil
+	}
+	if info.MaxValue == 0 {
+		return nil
+	}
+	percent := math.Round(float64(max(used, 0))/float64(info.MaxValue)*100*100) / 100
+	return &percent
+}
+
+// listSequencesTool describes list_sequences.
+func listSequencesTool() Tool {
+	return Tool{
+		Name: "list_sequences",
+		Description: "List the sequences and auto-increment counters of the database's tables with their current value, " +
+			"the largest value they can reach and how much of that range is used, to answer questions about ID exhaustion and growth",
+		InputSchema: InputSchema{
+			Type:       "object",
+			Properties: map[string]Property{},
+			Required:   []string{},
+		},
+	}
+}
+
+// listSequences reports the sequences of the database, those closest to
+// running out first. Sequences of denied tables are left out.
+func (s *Server) listSequences(ctx context.Context) (*CallToolResult, *Error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	sequences, err := s.adapter.ListSequences(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to list sequences: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report := sequencesReport{Driver: s.adapter.DriverName(), Sequences: []sequenceReport{}}
+	if !isSchemaDenied(DeniedSchemas, s.databaseName) {
+		for _, seq := range sequences {
+			if isTableDenied(DeniedTables, seq.Name) || (seq.Table != "" && isTableDenied(DeniedTables, seq.Table)) {
+				continue
+			}
+			report.Sequences = append(report.Sequences, sequenceReport{SequenceInfo: seq, UsedPercent: usedPercent(seq)})
+		}
+	}
+	sort.SliceStable(report.Sequences, func(i, j int) bool {
+		a, b := report.Sequences[i].UsedPercent, report.Sequences[j].UsedPercent
+		return a != nil && (b == nil || *a > *b)
+	})
+	return statementReportResult(report)
+}
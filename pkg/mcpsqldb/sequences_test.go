@@ -0,0 +1,74 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestIntegerMaxValue(t *testing.T) {
+	tests := []struct {
+		dataType string
+		unsigned bool
+		want     uint64
+	}{
+		{"tinyint", false, 127},
+		{"TINYINT", true, 255},
+		{"mediumint", true, 16777215},
+		{"int", false, math.MaxInt32},
+		{"bigint", true, math.MaxUint64},
+		{"varchar", false, 0},
+	}
+	for _, tt := range tests {
+		if got := integerMaxValue(tt.dataType, tt.unsigned); got != tt.want {
+			t.Errorf("Expected %d for %s (unsigned %v), got %d", tt.want, tt.dataType, tt.unsigned, got)
+		}
+	}
+}
+
+func TestListSequences_SQLite(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"secrets"}
+	server := newTestServer(t,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT)",
+		"INSERT INTO events (name) VALUES ('a'), ('b')",
+		"CREATE TABLE secrets (id INTEGER PRIMARY KEY AUTOINCREMENT)",
+		"INSERT INTO secrets DEFAULT VALUES")
+
+	result, rpcErr := server.callTool(context.Background(), "list_sequences", map[string]any{})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected sequences, got %v %+v", rpcErr, result)
+	}
+	var report sequencesReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(report.Sequences) != 1 {
+		t.Fatalf("Expected only the events counter, got %+v", report.Sequences)
+	}
+	seq := report.Sequences[0]
+	if seq.Table != "events" || seq.Column != "id" || seq.LastValue == nil || *seq.LastValue != 2 ||
+		seq.NextValue == nil || *seq.NextValue != 3 || seq.MaxValue != math.MaxInt64 {
+		t.Errorf("Expected events.id at 2 of %d, got %+v", int64(math.MaxInt64), seq)
+	}
+	if seq.UsedPercent == nil || *seq.UsedPercent != 0 {
+		t.Errorf("Expected 0%% used, got %v", seq.UsedPercent)
+	}
+
+	// Without AUTOINCREMENT tables SQLite has no sqlite_sequence table
+	result, _ = newTestServer(t).listSequences(context.Background())
+	if result.IsError || json.Unmarshal([]byte(result.Content[0].Text), &report) != nil || len(report.Sequences) != 0 {
+		t.Errorf("Expected no sequences, got %+v", result)
+	}
+}
+
+func TestUsedPercent(t *testing.T) {
+	next := int64(101)
+	if got := usedPercent(SequenceInfo{NextValue: &next, MaxValue: 127}); got == nil || *got != 78.74 {
+		t.Errorf("Expected 78.74%% used, got %v", got)
+	}
+	if got := usedPercent(SequenceInfo{MaxValue: 127}); got != nil {
+		t.Errorf("Expected no usage without a current value, got %v", *got)
+	}
+}
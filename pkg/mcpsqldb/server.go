@@ -0,0 +1,583 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// Server configuration defaults (QueryTimeout is overridable via MCP_QUERY_TIMEOUT)
+var QueryTimeout = 30 * time.Second
+
+// RequireReadOnlyUser refuses to start when the privilege audit finds write
+// or DDL capabilities (overridable via MCP_REQUIRE_READONLY_USER)
+var RequireReadOnlyUser = false
+
+// SlowQueryThreshold enables slow query logging when positive
+// (overridable via MCP_SLOW_QUERY_MS)
+var SlowQueryThreshold time.Duration
+
+const (
+	ConnectionTimeout  = 10 * time.Second
+	MaxConnectionsIdle = 5
+	MaxConnectionsOpen = 10
+	MaxConnIdleTime    = 5 * time.Minute
+)
+
+// Server handles the MCP protocol for one client session over a
+// newline-delimited JSON-RPC stream (stdio for the command), or serves
+// sessions of its own over HTTP with ListenAndServe
+type Server struct {
+	db           *sql.DB
+	adapter      DBAdapter
+	connector    *readOnlyConnector
+	databaseName string
+	sessionID    string
+	workers      *workerPool
+	jobs         *jobStore
+	saved        *savedResults
+	schemas      *schemaSnapshots
+	quota        *sessionQuota
+	charset      encoding.Encoding
+	snapshot     *snapshotTx
+	sources      crossSources
+	wire         *wireDumper
+	audit        *auditLog
+	cache        *resultCache
+	initialized  bool
+	client       InitializeParams
+	in           <-chan string
+	out          io.Writer
+	requestsSent int
+	ctx          context.Context
+	cancel       context.CancelFunc
+
+	// subscriptions are the stats resources the client subscribed to, by URI
+	subscriptions map[string]*subscription
+	// activeSchema is the schema chosen with set_active_schema, or nil
+	activeSchema atomic.Pointer[activeSchema]
+}
+
+// newServer creates a new MCP server connected to the database via the adapter
+func newServer(ctx context.Context, adapter DBAdapter, dsn string) (*Server, error) {
+	charset, err := sourceEncoding(SourceCharset)
+	if err != nil {
+		return nil, err
+	}
+
+	// Worker pool operations of the session, including its background jobs,
+	// are scheduled as its own, and its connections are labeled with it
+	sessionID := newSessionID()
+
+	db, connector, err := openDB(ctx, adapter, dsn, connectionLabel(sessionID))
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract database name using adapter-specific parsing
+	dbName := adapter.DatabaseName(dsn)
+
+	sources, err := openCrossSources(ctx, CrossQuerySources, connectionLabel(sessionID))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	var wire *wireDumper
+	if DebugWire {
+		if wire, err = newWireDumper(DebugWireFile); err != nil {
+			db.Close()
+			sources.Close()
+			return nil, err
+		}
+	}
+
+	var audit *auditLog
+	if AuditLogPath != "" {
+		if audit, err = newAuditLog(AuditLogPath, AuditChain, AuditCheckpointEvery); err != nil {
+			db.Close()
+			sources.Close()
+			return nil, err
+		}
+	}
+
+	var cache *resultCache
+	if ResultCachePath != "" {
+		var sourcePath string
+		if file, ok := adapter.(FileDatabase); ok {
+			sourcePath = file.DatabaseFile(dsn)
+		}
+		if cache, err = openResultCache(ResultCachePath, sourcePath); err != nil {
+			db.Close()
+			sources.Close()
+			audit.Close()
+			return nil, err
+		}
+	}
+
+	var snapshot *snapshotTx
+	if SnapshotSession {
+		snapshot = newSnapshotTx()
+	}
+
+	serverCtx, serverCancel := context.WithCancel(withSession(ctx, sessionID))
+
+	return &Server{
+		db:           db,
+		connector:    connector,
+		adapter:      adapter,
+		databaseName: dbName,
+		sessionID:    sessionID,
+		workers:      newWorkerPool(WorkerCount, QueueDepth, QueuePolicy),
+		jobs:         newJobStore(),
+		saved:        newSavedResults(),
+		schemas:      newSchemaSnapshots(),
+		quota:        newSessionQuota(QuotaQueriesPerMinute, QuotaSessionRows, QuotaSessionBytes),
+		charset:      charset,
+		snapshot:     snapshot,
+		sources:      sources,
+		wire:         wire,
+		audit:        audit,
+		cache:        cache,
+		ctx:          serverCtx,
+		cancel:       serverCancel,
+	}, nil
+}
+
+// newSession returns a Server for another client session with the given
+// id. It shares s's connection pool, worker pool, cross-query sources, result
+// cache, audit log and wire dump, but has its own jobs, saved results, quota,
+// snapshot and active schema, and a context that ends with s's. Its Shutdown ends only the
+// session; only s may be Closed.
+func (s *Server) newSession(sessionID string) *Server {
+	var snapshot *snapshotTx
+	if SnapshotSession {
+		snapshot = newSnapshotTx()
+	}
+	ctx, cancel := context.WithCancel(withSession(s.ctx, sessionID))
+
+	return &Server{
+		db:           s.db,
+		connector:    s.connector,
+		adapter:      s.adapter,
+		databaseName: s.databaseName,
+		sessionID:    sessionID,
+		workers:      s.workers,
+		jobs:         newJobStore(),
+		saved:        newSavedResults(),
+		schemas:      newSchemaSnapshots(),
+		quota:        newSessionQuota(QuotaQueriesPerMinute, QuotaSessionRows, QuotaSessionBytes),
+		charset:      s.charset,
+		snapshot:     snapshot,
+		sources:      s.sources,
+		wire:         s.wire,
+		audit:        s.audit,
+		cache:        s.cache,
+		ctx:          ctx,
+		cancel:       cancel,
+	}
+}
+
+// openDB opens a read-only connection pool for dsn, its connections named
+// label, verifies the connection, audits the account's privileges and checks
+// that writes are refused.
+func openDB(ctx context.Context, adapter DBAdapter, dsn, label string) (*sql.DB, *readOnlyConnector, error) {
+	// Every pooled connection gets the adapter's read-only session settings
+	connector, err := newReadOnlyConnector(adapter, dsn, label)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db := sql.OpenDB(connector)
+
+	// Configure connection pool
+	db.SetMaxIdleConns(MaxConnectionsIdle)
+	db.SetMaxOpenConns(MaxConnectionsOpen)
+	db.SetConnMaxLifetime(time.Hour)
+	// Recycle idle connections before a firewall or failover silently kills them
+	db.SetConnMaxIdleTime(MaxConnIdleTime)
+
+	// Test connection with timeout
+	pingCtx, pingCancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer pingCancel()
+
+	if err := db.PingContext(pingCtx); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	if err := auditPrivileges(ctx, adapter, db, dsn); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	if err := checkWritesRefused(ctx, adapter, db); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return db, connector, nil
+}
+
+// Run starts the MCP server, reading from stdin and writing to stdout
+func (s *Server) Run() error {
+	return s.serve(os.Stdin, os.Stdout)
+}
+
+// serve processes newline-delimited JSON-RPC messages from r and writes
+// responses to w until input closes, the server context is cancelled or the
+// session idles out. Keepalive pings are written between responses.
+func (s *Server) serve(r io.Reader, w io.Writer) error {
+	lines := make(chan string)
+	readErr := make(chan error, 1)
+	go s.readInput(r, lines, readErr)
+	s.in, s.out = lines, w
+
+	idleTimer, idle := newIdleTimer()
+	if idleTimer != nil {
+		defer idleTimer.Stop()
+	}
+	ticker, pings := newKeepaliveTicker()
+	if ticker != nil {
+		defer ticker.Stop()
+	}
+	pingsSent := 0
+	pollTicker, polls := newSubscriptionTicker()
+	if pollTicker != nil {
+		defer pollTicker.Stop()
+	}
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			// Input closure cancels the context too; report it as a clean exit
+			select {
+			case err := <-readErr:
+				return err
+			default:
+			}
+			return s.ctx.Err()
+		case err := <-readErr:
+			return err
+		case <-idle:
+			slog.Info("Closing idle session", "session_id", s.sessionID, "idle_timeout", IdleTimeout)
+			s.cancel()
+			return ErrIdleTimeout
+		case <-pings:
+			pingsSent++
+			if err := s.writeMessage(w, keepalivePing(pingsSent)); err != nil {
+				slog.Info("Client unreachable, closing session", "session_id", s.sessionID, "error", err)
+				s.cancel()
+				return nil
+			}
+		case <-polls:
+			if err := s.pollSubscriptions(s.ctx, w); err != nil {
+				slog.Info("Client unreachable, closing session", "session_id", s.sessionID, "error", err)
+				s.cancel()
+				return nil
+			}
+		case line := <-lines:
+			s.handleLine(w, line)
+			// Restart after handling, which may have waited on the client
+			if idleTimer != nil {
+				idleTimer.Reset(IdleTimeout)
+			}
+		}
+	}
+}
+
+// handleLine handles one inbound message, writing its response if any.
+func (s *Server) handleLine(w io.Writer, line string) {
+	if s.wire != nil {
+		s.wire.dump(WireInbound, []byte(line))
+	}
+	if response := s.handleMessage([]byte(line)); response != nil {
+		s.writeMessage(w, response)
+	}
+}
+
+// requestClient sends a request to the client and waits for its result.
+// serve is blocked on the calling handler meanwhile, so messages arriving
+// before the response are handled here in turn.
+func (s *Server) requestClient(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	if s.out == nil {
+		return nil, fmt.Errorf("no client connection")
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+	s.requestsSent++
+	id := fmt.Sprintf("server-%d", s.requestsSent)
+	if err := s.writeMessage(s.out, &JSONRPCRequest{JSONRPC: "2.0", ID: id, Method: method, Params: data}); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case line := <-s.in:
+			var response struct {
+				ID     any             `json:"id"`
+				Method string          `json:"method"`
+				Result json.RawMessage `json:"result"`
+				Error  *Error          `json:"error"`
+			}
+			if json.Unmarshal([]byte(line), &response) != nil || response.Method != "" || response.ID != id {
+				s.handleLine(s.out, line)
+				continue
+			}
+			if s.wire != nil {
+				s.wire.dump(WireInbound, []byte(line))
+			}
+			if response.Error != nil {
+				return nil, fmt.Errorf("client rejected %s: %s", method, response.Error.Message)
+			}
+			return response.Result, nil
+		}
+	}
+}
+
+// writeMessage writes msg to w as one line of JSON, returning any write
+// error. Marshal failures are logged and the message dropped.
+func (s *Server) writeMessage(w io.Writer, msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("Failed to marshal message", "error", err)
+		return nil
+	}
+	if s.wire != nil {
+		s.wire.dump(WireOutbound, data)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// readInput feeds non-empty lines from r to lines, one at a time, so it is
+// always waiting on the next read while a request executes. When input closes
+// it cancels the server context, aborting any in-flight query immediately
+// instead of letting it run until QueryTimeout for a client that is gone.
+func (s *Server) readInput(r io.Reader, lines chan<- string, readErr chan<- error) {
+	reader := bufio.NewReader(r)
+	for {
+		line, err := readLine(reader, MaxRequestBytes)
+		if err != nil {
+			if err == io.EOF {
+				readErr <- nil
+			} else {
+				readErr <- fmt.Errorf("failed to read input: %w", err)
+			}
+			s.cancel()
+			return
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		select {
+		case lines <- line:
+		case <-s.ctx.Done():
+			return
+		}
+	}
+}
+
+// readLine reads up to the next newline, keeping at most limit+1 bytes of
+// it so an oversized message is still recognized as one without buffering
+// all of it.
+func readLine(reader *bufio.Reader, limit int) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if room := limit + 1 - len(line); room > 0 {
+			line = append(line, chunk[:min(len(chunk), room)]...)
+		}
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
+func (s *Server) handleMessage(data []byte) *JSONRPCResponse {
+	if len(data) > MaxRequestBytes {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      nil,
+			Error: &Error{
+				Code:    InvalidRequest,
+				Message: fmt.Sprintf("Request exceeds %d bytes", MaxRequestBytes),
+			},
+		}
+	}
+
+	var req JSONRPCRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      nil,
+			Error: &Error{
+				Code:    ParseError,
+				Message: "Parse error",
+				Data:    err.Error(),
+			},
+		}
+	}
+
+	if req.JSONRPC != "2.0" {
+		return &JSONRPCResponse{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error: &Error{
+				Code:    InvalidRequest,
+				Message: "Invalid JSON-RPC version",
+			},
+		}
+	}
+
+	// A response to a server-initiated request, i.e. a keepalive ping; its
+	// arrival already counted as activity
+	if req.Method == "" && req.ID != nil {
+		return nil
+	}
+	if req.ID == nil {
+		s.handleNotification(&req)
+		return nil
+	}
+
+	return s.handleRequest(&req)
+}
+
+func (s *Server) handleRequest(req *JSONRPCRequest) *JSONRPCResponse {
+	var result any
+	var err *Error
+
+	// Every log line emitted while handling this request carries its id
+	logger := slog.Default().With("session_id", s.sessionID, "request_id", req.ID, "method", req.Method)
+	ctx := context.WithValue(withLogger(s.ctx, logger), requestIDKey{}, req.ID)
+
+	start := time.Now()
+	defer func() {
+		attrs := []any{"duration_ms", time.Since(start).Milliseconds()}
+		if err != nil {
+			loggerFrom(ctx).Warn("Request failed", append(attrs, "error_code", err.Code, "error", err.Message)...)
+		} else {
+			loggerFrom(ctx).Debug("Request handled", attrs...)
+		}
+	}()
+
+	switch req.Method {
+	case "initialize":
+		result, err = s.handleInitialize(ctx, req.Params)
+	case "tools/list":
+		result, err = s.handleListTools()
+	case "tools/call":
+		ctx, result, err = s.handleCallTool(ctx, req.Params)
+	case "resources/list":
+		result, err = s.handleListResources(ctx)
+	case "resources/read":
+		result, err = s.handleReadResource(ctx, req.Params)
+	case "resources/subscribe":
+		result, err = s.handleSubscribe(ctx, req.Params)
+	case "resources/unsubscribe":
+		result, err = s.handleUnsubscribe(req.Params)
+	case "ping":
+		result = map[string]any{}
+	default:
+		err = &Error{
+			Code:    MethodNotFound,
+			Message: fmt.Sprintf("Method not found: %s", req.Method),
+		}
+	}
+
+	redactResponse(result, err)
+
+	return &JSONRPCResponse{
+		JSONRPC: "2.0",
+		ID:      req.ID,
+		Result:  result,
+		Error:   err,
+	}
+}
+
+// Shutdown gracefully shuts down the server
+func (s *Server) Shutdown() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+}
+
+// Close releases all resources
+func (s *Server) Close() error {
+	s.Shutdown()
+	if s.wire != nil {
+		s.wire.Close()
+	}
+	s.audit.Close()
+	s.cache.Close()
+	s.sources.Close()
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+// rotateDSN points new pool connections at dsn, e.g. after a credential
+// rotation. Existing connections are used until they are recycled.
+func (s *Server) rotateDSN(dsn string) error {
+	dsn, err := s.adapter.ResolveDSN(dsn)
+	if err != nil {
+		return err
+	}
+	return s.connector.setDSN(dsn)
+}
+
+// newSessionID returns a random identifier correlating all logs of one session.
+func newSessionID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// auditPrivileges checks whether the database account can write, logging a
+// prominent warning, or failing when RequireReadOnlyUser is set, since query
+// validation should not be the only line of defense.
+func auditPrivileges(ctx context.Context, adapter DBAdapter, db *sql.DB, dsn string) error {
+	auditCtx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer cancel()
+
+	findings, err := adapter.AuditPrivileges(auditCtx, db, dsn)
+	if err != nil {
+		if RequireReadOnlyUser {
+			return fmt.Errorf("privilege audit failed and MCP_REQUIRE_READONLY_USER is set: %w", err)
+		}
+		slog.Warn("Could not audit database privileges", "error", err)
+		return nil
+	}
+	if len(findings) == 0 {
+		slog.Info("Privilege audit passed: account is read-only")
+		return nil
+	}
+
+	if RequireReadOnlyUser {
+		return fmt.Errorf("database account has write privileges and MCP_REQUIRE_READONLY_USER is set: %s",
+			strings.Join(findings, "; "))
+	}
+	slog.Warn("SECURITY WARNING: database account has write/DDL privileges; "+
+		"query validation is the only remaining safeguard. Use a read-only account.",
+		"privileges", findings)
+	return nil
+}
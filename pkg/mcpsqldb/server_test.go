@@ -0,0 +1,126 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe_InputClosureCancelsInFlightQuery(t *testing.T) {
+	server := newTestServer(t)
+
+	// Unbounded recursive CTE: only cancellation can stop it before QueryTimeout
+	request := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"query","arguments":{"sql":` +
+		`"SELECT (WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT count(*) FROM n)"}}}`
+
+	pr, pw := io.Pipe()
+	go func() {
+		io.WriteString(pw, request+"\n")
+		pw.Close()
+	}()
+
+	var out bytes.Buffer
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() { done <- server.serve(pr, &out) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected clean exit on input closure, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("In-flight query was not cancelled after input closed")
+	}
+
+	if elapsed := time.Since(start); elapsed >= QueryTimeout {
+		t.Errorf("Query ran until timeout (%v)", elapsed)
+	}
+}
+
+func TestServe_RespondsPerLine(t *testing.T) {
+	server := newTestServer(t)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"ping"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := server.serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 responses, got %d: %q", len(lines), out.String())
+	}
+}
+
+func TestServe_NotificationsGetNoResponse(t *testing.T) {
+	server := newTestServer(t)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","method":"initialized"}`,
+		`{"jsonrpc":"2.0","method":"notifications/roots/list_changed"}`,
+		`{"jsonrpc":"2.0","method":"notifications/unknown","params":{"x":1}}`,
+		`{"jsonrpc":"2.0","method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":1,"method":"no/such/method"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	if err := server.serve(strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"id":1`) || !strings.Contains(lines[0], "Method not found") {
+		t.Errorf("Expected only the method-not-found response to request 1, got %q", out.String())
+	}
+}
+
+func TestHandleRequest_LogsCarryRequestAndTool(t *testing.T) {
+	server := newTestServer(t)
+
+	originalThreshold := SlowQueryThreshold
+	SlowQueryThreshold = time.Nanosecond
+	defer func() { SlowQueryThreshold = originalThreshold }()
+
+	var buf bytes.Buffer
+	originalLogger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, nil)))
+	defer slog.SetDefault(originalLogger)
+
+	server.handleMessage([]byte(`{"jsonrpc":"2.0","id":42,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT 1"}}}`))
+
+	out := buf.String()
+	for _, want := range []string{"Slow query", "request_id=42", "tool=query", "session_id=" + server.sessionID} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in log output %q", want, out)
+		}
+	}
+}
+
+func TestNewMCPServer_RequireReadOnlyUserRefusesWritableAccount(t *testing.T) {
+	// The freshly created test database file is writable by its owner
+	path := newTestDB(t)
+
+	original := RequireReadOnlyUser
+	RequireReadOnlyUser = true
+	defer func() { RequireReadOnlyUser = original }()
+
+	server, err := newServer(context.Background(), &SQLiteAdapter{}, path+"?mode=ro")
+	if err == nil {
+		server.Close()
+		t.Fatal("Expected startup to be refused for a writable database file")
+	}
+	if !strings.Contains(err.Error(), "MCP_REQUIRE_READONLY_USER") {
+		t.Errorf("Expected error to mention MCP_REQUIRE_READONLY_USER, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SessionVariables are the session and server variables the
+// session_variables tool may read. Names are given as the database spells
+// them, except that version and time_zone are understood by every adapter
+// (overridable via MCP_SESSION_VARIABLES env var)
+var SessionVariables = []string{"version", "sql_mode", "time_zone", "search_path", "work_mem"}
+
+// variableNamePattern matches a variable name safe to put in SQL.
+var variableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// parseSessionVariables parses a comma-separated list of variable names.
+func parseSessionVariables(spec string) ([]string, error) {
+	var names []string
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !variableNamePattern.MatchString(entry) {
+			return nil, fmt.Errorf("invalid variable name %q: expected letters, digits and underscores", entry)
+		}
+		names = append(names, strings.ToLower(entry))
+	}
+	return names, nil
+}
+
+// sessionVariablesReport is the session_variables result. Variables the
+// database does not have are null.
+type sessionVariablesReport struct {
+	Driver    string             `json:"driver"`
+	Variables map[string]*string `json:"variables"`
+}
+
+// sessionVariablesTool describes session_variables.
+func sessionVariablesTool() Tool {
+	return Tool{
+		Name: "session_variables",
+		Description: "Read selected session and server variables (" + strings.Join(SessionVariables, ", ") + ") " +
+			"to explain why queries behave differently than expected; other variables cannot be read",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"names": {
+					Type:        "array",
+					Description: "Variables to read (default: all allowed)",
+					Items:       &Property{Type: "string"},
+				},
+			},
+			Required: []string{},
+		},
+	}
+}
+
+// sessionVariables reads the requested variables, which must be listed in
+// SessionVariables.
+func (s *Server) sessionVariables(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	names := SessionVariables
+	if _, ok := args["names"]; ok {
+		requested, ok := stringList(args["names"])
+		if !ok || len(requested) == 0 {
+			return nil, &Error{
+				Code:    InvalidParams,
+				Message: "Invalid 'names' parameter: expected a non-empty array of variable names",
+			}
+		}
+		for i, name := range requested {
+			requested[i] = strings.ToLower(name)
+			if !slices.Contains(SessionVariables, requested[i]) {
+				return nil, &Error{
+					Code:    InvalidParams,
+					Message: fmt.Sprintf("Variable %q may not be read; allowed: %s", name, strings.Join(SessionVariables, ", ")),
+				}
+			}
+		}
+		names = requested
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	values, err := s.adapter.ReadVariables(ctx, s.db, names)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to read variables: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report := sessionVariablesReport{Driver: s.adapter.DriverName(), Variables: map[string]*string{}}
+	for _, name := range names {
+		report.Variables[name] = nil
+		if value, ok := values[name]; ok {
+			report.Variables[name] = &value
+		}
+	}
+	return statementReportResult(report)
+}
@@ -0,0 +1,52 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseSessionVariables(t *testing.T) {
+	names, err := parseSessionVariables(" version, SQL_MODE ,,")
+	if err != nil || !reflect.DeepEqual(names, []string{"version", "sql_mode"}) {
+		t.Errorf("Expected version and sql_mode, got %v (%v)", names, err)
+	}
+	for _, spec := range []string{"version; DROP TABLE x", "@@version", "time zone"} {
+		if _, err := parseSessionVariables(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestSessionVariables_SQLite(t *testing.T) {
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, rpcErr := server.callTool(ctx, "session_variables", map[string]any{})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected variables, got %v %+v", rpcErr, result)
+	}
+	var report sessionVariablesReport
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &report); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if len(report.Variables) != len(SessionVariables) {
+		t.Errorf("Expected every allowed variable, got %v", report.Variables)
+	}
+	if v := report.Variables["version"]; v == nil || *v == "" {
+		t.Errorf("Expected the SQLite version, got %v", v)
+	}
+	if v, ok := report.Variables["work_mem"]; !ok || v != nil {
+		t.Errorf("Expected work_mem to be null, got %v", v)
+	}
+
+	result, _ = server.sessionVariables(ctx, map[string]any{"names": []any{"VERSION"}})
+	var versionOnly sessionVariablesReport
+	if result.IsError || json.Unmarshal([]byte(result.Content[0].Text), &versionOnly) != nil || len(versionOnly.Variables) != 1 {
+		t.Errorf("Expected only version, got %+v", result)
+	}
+	if _, rpcErr := server.sessionVariables(ctx, map[string]any{"names": []any{"secure_file_priv"}}); rpcErr == nil {
+		t.Error("Expected a variable outside the allowlist to be rejected")
+	}
+}
@@ -0,0 +1,77 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// collationUse counts the columns of a table using a collation.
+type collationUse struct {
+	Table     string `json:"table"`
+	Collation string `json:"collation"`
+	Columns   int    `json:"columns"`
+}
+
+// databaseSettingsReport is the database_settings result.
+type databaseSettingsReport struct {
+	Driver          string            `json:"driver"`
+	Settings        map[string]string `json:"settings"`
+	CollationsInUse []collationUse    `json:"collations_in_use"`
+}
+
+// databaseSettings reports the settings that decide how values are sorted,
+// compared and displayed, so surprising results can be explained. Column
+// collations of denied tables are left out.
+func (s *Server) databaseSettings(ctx context.Context) (*CallToolResult, *Error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	settings, err := s.adapter.DescribeSettings(ctx, s.db, s.databaseName)
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to read database settings: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report := databaseSettingsReport{
+		Driver:          s.adapter.DriverName(),
+		Settings:        settings.Settings,
+		CollationsInUse: []collationUse{},
+	}
+	if !isSchemaDenied(DeniedSchemas, s.databaseName) {
+		for table, collations := range settings.Collations {
+			if isTableDenied(DeniedTables, table) {
+				continue
+			}
+			for collation, n := range collations {
+				report.CollationsInUse = append(report.CollationsInUse, collationUse{Table: table, Collation: collation, Columns: n})
+			}
+		}
+	}
+	sort.Slice(report.CollationsInUse, func(i, j int) bool {
+		a, b := report.CollationsInUse[i], report.CollationsInUse[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Collation < b.Collation
+	})
+
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Failed to marshal results: %v", err)}},
+			IsError: true,
+		}, nil
+	}
+	return &CallToolResult{
+		Content: []Content{{Type: "text", Text: string(jsonData)}},
+	}, nil
+}
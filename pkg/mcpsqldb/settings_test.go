@@ -0,0 +1,46 @@
+package mcpsqldb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDatabaseSettings_SQLite(t *testing.T) {
+	server := newTestServer(t,
+		"CREATE TABLE tags (id INTEGER PRIMARY KEY, label TEXT COLLATE NOCASE, slug TEXT COLLATE \"rtrim\")",
+	)
+
+	report := callToolJSON[databaseSettingsReport](t, server, "database_settings", map[string]any{})
+	if report.Driver != "sqlite" {
+		t.Errorf("Expected driver sqlite, got %q", report.Driver)
+	}
+	for key, want := range map[string]string{"encoding": "UTF-8", "default_collation": "BINARY", "search_path": "main"} {
+		if got := report.Settings[key]; got != want {
+			t.Errorf("Expected %s %q, got %q", key, want, got)
+		}
+	}
+	if report.Settings["version"] == "" || report.Settings["timezone"] == "" {
+		t.Errorf("Expected version and timezone settings, got %v", report.Settings)
+	}
+
+	want := []collationUse{
+		{Table: "tags", Collation: "BINARY", Columns: 1},
+		{Table: "tags", Collation: "NOCASE", Columns: 1},
+		{Table: "tags", Collation: "RTRIM", Columns: 1},
+		{Table: "users", Collation: "BINARY", Columns: 2},
+	}
+	if !reflect.DeepEqual(report.CollationsInUse, want) {
+		t.Errorf("Expected collations %+v, got %+v", want, report.CollationsInUse)
+	}
+}
+
+func TestDatabaseSettings_HidesDeniedTables(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"users"}
+	server := newTestServer(t, "CREATE TABLE tags (label TEXT COLLATE NOCASE)")
+
+	report := callToolJSON[databaseSettingsReport](t, server, "database_settings", map[string]any{})
+	if want := []collationUse{{Table: "tags", Collation: "NOCASE", Columns: 1}}; !reflect.DeepEqual(report.CollationsInUse, want) {
+		t.Errorf("Expected only tags collations, got %+v", report.CollationsInUse)
+	}
+}
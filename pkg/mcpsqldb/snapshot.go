@@ -0,0 +1,128 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// SnapshotSession runs every query of a session in one read-only
+// transaction, so multi-step analyses see a single consistent view of the
+// data (overridable via MCP_SNAPSHOT_SESSION env var)
+var SnapshotSession = false
+
+// snapshotSavepoint is set before each query in a snapshot so a failed
+// query can be undone without ending the transaction (Postgres otherwise
+// refuses every later statement in it).
+const snapshotSavepoint = "mcp_query"
+
+// snapshotTx is the long-lived transaction behind a snapshot session. Its
+// connection runs one query at a time, so lock serializes queries until
+// their rows are closed. The transaction is bound to the server's context
+// and rolled back by Shutdown, which also returns conn to the pool.
+type snapshotTx struct {
+	lock chan struct{}
+	conn *sql.Conn
+	tx   *sql.Tx
+	// release stops returning conn to the pool on Shutdown, once it has
+	// been returned already
+	release func() bool
+	// started is when tx began in Unix nanoseconds, or 0 without one; it
+	// is read by server_status without waiting for the lock
+	started atomic.Int64
+}
+
+func newSnapshotTx() *snapshotTx {
+	return &snapshotTx{lock: make(chan struct{}, 1)}
+}
+
+// querySnapshot runs sqlQuery in the session's snapshot transaction,
+// beginning it on first use. The returned func must be called once the rows
+// are closed to let the next query run.
+func (s *Server) querySnapshot(ctx context.Context, sqlQuery string, args ...any) (*sql.Rows, func(), error) {
+	snap := s.snapshot
+	select {
+	case snap.lock <- struct{}{}:
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("waiting for the session snapshot: %w", ctx.Err())
+	}
+	unlock := func() { <-snap.lock }
+
+	if snap.tx == nil {
+		// The connection is held for the whole session, so with the pool
+		// taken by other snapshots waiting for one is bounded by the
+		// request's deadline
+		conn, err := s.db.Conn(ctx)
+		if err != nil {
+			unlock()
+			return nil, nil, fmt.Errorf("failed to get a connection for the snapshot: %w", err)
+		}
+		// Bound to the server's context, not the request's, so the
+		// transaction outlives the query that began it
+		tx, err := conn.BeginTx(s.ctx, s.adapter.SnapshotTxOptions())
+		if err != nil {
+			conn.Close()
+			unlock()
+			return nil, nil, fmt.Errorf("failed to begin snapshot transaction: %w", err)
+		}
+		snap.conn, snap.tx = conn, tx
+		snap.release = context.AfterFunc(s.ctx, func() { conn.Close() })
+		snap.started.Store(time.Now().UnixNano())
+		loggerFrom(ctx).Info("Began session snapshot")
+	}
+
+	if _, err := snap.tx.ExecContext(ctx, "SAVEPOINT "+snapshotSavepoint); err != nil {
+		s.endSnapshot(ctx, err)
+		unlock()
+		return nil, nil, err
+	}
+	// A failed switch is undone like a failed query
+	err := s.applyActiveSchema(ctx, snap.tx)
+	var rows *sql.Rows
+	if err == nil {
+		rows, err = snap.tx.QueryContext(ctx, sqlQuery, args...)
+	}
+	if err != nil {
+		if isConnectionError(err) || errors.Is(err, sql.ErrTxDone) {
+			s.endSnapshot(ctx, err)
+		} else if _, rerr := snap.tx.ExecContext(context.Background(), "ROLLBACK TO SAVEPOINT "+snapshotSavepoint); rerr != nil {
+			s.endSnapshot(ctx, rerr)
+		}
+		unlock()
+		return nil, nil, err
+	}
+	return rows, unlock, nil
+}
+
+// endSnapshot discards a snapshot transaction that can no longer be used;
+// the next query begins a new snapshot. The caller must hold the lock.
+func (s *Server) endSnapshot(ctx context.Context, cause error) {
+	snap := s.snapshot
+	if snap.tx == nil {
+		return
+	}
+	snap.tx.Rollback()
+	if snap.release() {
+		snap.conn.Close()
+	}
+	snap.conn, snap.tx, snap.release = nil, nil, nil
+	snap.started.Store(0)
+	loggerFrom(ctx).Warn("Session snapshot lost, the next query begins a new one", "error", cause)
+}
+
+// status reports whether a snapshot is open and since when.
+func (snap *snapshotTx) status(now time.Time) map[string]any {
+	started := snap.started.Load()
+	if started == 0 {
+		return map[string]any{"active": false}
+	}
+	startedAt := time.Unix(0, started)
+	return map[string]any{
+		"active":      true,
+		"started_at":  startedAt.UTC().Format(time.RFC3339),
+		"age_seconds": int(now.Sub(startedAt).Seconds()),
+	}
+}
@@ -0,0 +1,131 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// countUsers returns the users count the query tool reports.
+func countUsers(t *testing.T, server *Server) string {
+	t.Helper()
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT COUNT(*) AS n FROM users"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected count, got %v %+v", rpcErr, result)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil || len(rows) != 1 {
+		t.Fatalf("Failed to parse count: %s", result.Content[0].Text)
+	}
+	return fmt.Sprint(rows[0]["n"])
+}
+
+// newSnapshotTestServer serves a WAL database, where a reader's snapshot
+// does not block writers, and returns a writable connection to it.
+func newSnapshotTestServer(t *testing.T, snapshot bool) (*Server, *sql.DB) {
+	t.Helper()
+	defer func(orig bool) { SnapshotSession = orig }(SnapshotSession)
+	SnapshotSession = snapshot
+
+	path := newTestDB(t, "PRAGMA journal_mode=WAL")
+	server := openTestServer(t, path)
+	writer, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open writer: %v", err)
+	}
+	t.Cleanup(func() { writer.Close() })
+	return server, writer
+}
+
+func TestSnapshotSession_SeesConsistentData(t *testing.T) {
+	server, writer := newSnapshotTestServer(t, true)
+
+	if got := countUsers(t, server); got != "3" {
+		t.Fatalf("Expected 3 users, got %s", got)
+	}
+	if _, err := writer.Exec("INSERT INTO users (name) VALUES ('dave')"); err != nil {
+		t.Fatalf("Expected the writer not to be blocked: %v", err)
+	}
+	if got := countUsers(t, server); got != "3" {
+		t.Errorf("Expected the snapshot to still show 3 users, got %s", got)
+	}
+
+	// A failed query must not end the snapshot
+	result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT * FROM missing"})
+	if !result.IsError {
+		t.Fatalf("Expected an error for a missing table, got %s", result.Content[0].Text)
+	}
+	if got := countUsers(t, server); got != "3" {
+		t.Errorf("Expected the snapshot to survive a failed query, got %s users", got)
+	}
+
+	status, _ := server.serverStatus(context.Background())
+	var report map[string]any
+	json.Unmarshal([]byte(status.Content[0].Text), &report)
+	if snap, _ := report["snapshot"].(map[string]any); snap["active"] != true || snap["started_at"] == nil {
+		t.Errorf("Expected an active snapshot in the status, got %s", status.Content[0].Text)
+	}
+}
+
+func TestSnapshotSession_Disabled(t *testing.T) {
+	server, writer := newSnapshotTestServer(t, false)
+
+	countUsers(t, server)
+	if _, err := writer.Exec("INSERT INTO users (name) VALUES ('dave')"); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+	if got := countUsers(t, server); got != "4" {
+		t.Errorf("Expected each query to see the latest data, got %s users", got)
+	}
+
+	status, _ := server.serverStatus(context.Background())
+	if strings.Contains(status.Content[0].Text, `"snapshot"`) {
+		t.Errorf("Expected no snapshot in the status, got %s", status.Content[0].Text)
+	}
+}
+
+func TestSnapshotSession_WritesStillRefused(t *testing.T) {
+	server, _ := newSnapshotTestServer(t, true)
+	countUsers(t, server)
+
+	rows, done, err := server.beginQuery(context.Background(), "INSERT INTO users (name) VALUES ('mallory') RETURNING id")
+	if err == nil {
+		rows.Close()
+		done()
+		t.Fatal("Expected a write inside the snapshot transaction to fail")
+	}
+	if got := countUsers(t, server); got != "3" {
+		t.Errorf("Expected 3 users, got %s", got)
+	}
+}
+
+func TestSnapshotSession_PoolExhausted(t *testing.T) {
+	defer func(timeout time.Duration, snapshot bool) { QueryTimeout, SnapshotSession = timeout, snapshot }(QueryTimeout, SnapshotSession)
+	server, _ := newSnapshotTestServer(t, true)
+	server.db.SetMaxOpenConns(1)
+	SnapshotSession = true
+	first, other := server.newSession("first"), server.newSession("other")
+	// The first session's snapshot holds the only connection
+	countUsers(t, first)
+
+	QueryTimeout = 100 * time.Millisecond
+	start := time.Now()
+	result, _ := other.executeQuery(context.Background(), map[string]any{"sql": "SELECT 1"})
+	if !result.IsError || result.Meta == nil || result.Meta.Error == nil || result.Meta.Error.Kind != ErrorKindTimeout {
+		t.Fatalf("Expected a timeout waiting for a connection, got %+v", result)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the wait to end with the query timeout, took %v", elapsed)
+	}
+
+	// Ending the first session returns its connection to the pool
+	QueryTimeout = 5 * time.Second
+	first.Shutdown()
+	if got := countUsers(t, other); got != "3" {
+		t.Errorf("Expected the other session's snapshot to begin, got %s users", got)
+	}
+}
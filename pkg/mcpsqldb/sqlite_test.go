@@ -0,0 +1,357 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteValidateQuery_AllowedQueries(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	allowedQueries := []string{
+		"SELECT * FROM users",
+		"SELECT id, name FROM users WHERE id = 1",
+		"select * from users",
+		"SHOW TABLES",
+		"DESCRIBE users",
+		"DESC users",
+		"EXPLAIN SELECT * FROM users",
+		"SELECT * FROM settings",
+		"SELECT * FROM user_settings WHERE setting_name = 'theme'",
+		"SELECT created_at FROM orders",
+		"SELECT updated_at FROM products",
+		"SELECT deleted FROM items",
+		"SELECT * FROM users WHERE name = 'DROP TABLE users'", // keyword in string literal
+		"SELECT * FROM docs WHERE docs MATCH 'update OR delete' ORDER BY rank",
+		"SELECT title, bm25(docs), highlight(docs, 1, '[', ']') FROM docs WHERE body MATCH 'NEAR(sqlite database)'",
+		"SELECT snippet(docs, 1, '[', ']', '...', 8) FROM docs('replace')",
+	}
+
+	for _, query := range allowedQueries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err != nil {
+				t.Errorf("Expected query to be allowed, but got error: %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteValidateQuery_BlockedQueries(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"INSERT INTO users VALUES (1, 'test')", "INSERT"},
+		{"UPDATE users SET name = 'test'", "UPDATE"},
+		{"DELETE FROM users", "DELETE"},
+		{"DROP TABLE users", "DROP"},
+		{"CREATE TABLE test (id INT)", "CREATE"},
+		{"ALTER TABLE users ADD COLUMN age INT", "ALTER"},
+		{"TRUNCATE TABLE users", "TRUNCATE"},
+		{"GRANT ALL ON *.* TO 'user'", "GRANT"},
+		{"REVOKE ALL ON *.* FROM 'user'", "REVOKE"},
+		{"SET @var = 1", "SET"},
+		{"SELECT 1; DROP TABLE users", "multiple statements"},
+		// SQLite-specific blocked queries
+		{"SELECT load_extension('hack.so')", "load_extension"},
+		{"SELECT writefile('/tmp/data', content)", "writefile"},
+		{"SELECT edit(content)", "edit"},
+		{"SELECT fts3_tokenizer('simple')", "fts3_tokenizer"},
+		{"REPLACE INTO users VALUES (1, 'test')", "REPLACE"},
+		{"ATTACH DATABASE '/tmp/other.db' AS other", "ATTACH"},
+		{"DETACH DATABASE other", "DETACH"},
+		{"REINDEX users", "REINDEX"},
+		{"VACUUM", "VACUUM"},
+	}
+
+	for _, tc := range blockedQueries {
+		t.Run(tc.query, func(t *testing.T) {
+			err := adapter.ValidateQuery(tc.query)
+			if err == nil {
+				t.Errorf("Expected query to be blocked for %s, but it was allowed", tc.shouldBlock)
+			}
+		})
+	}
+}
+
+func TestSQLiteValidateQuery_PragmaWriteBlocked(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	blockedPragmas := []string{
+		"EXPLAIN PRAGMA journal_mode = WAL",
+		"EXPLAIN PRAGMA synchronous = OFF",
+		"EXPLAIN PRAGMA foreign_keys = ON",
+	}
+
+	for _, query := range blockedPragmas {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err == nil {
+				t.Errorf("Expected PRAGMA write to be blocked: %s", query)
+			}
+		})
+	}
+}
+
+func TestSQLiteValidateQuery_EmptyQuery(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+
+	err := adapter.ValidateQuery("")
+	if err == nil {
+		t.Error("Expected empty query to be rejected")
+	}
+
+	err = adapter.ValidateQuery("   ")
+	if err == nil {
+		t.Error("Expected whitespace-only query to be rejected")
+	}
+}
+
+func TestSQLiteValidateQuery_CommentInjection(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	queries := []string{
+		"SELECT 1 -- ; DROP TABLE users",
+		"SELECT 1 /* ; DROP TABLE users */",
+	}
+
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			err := adapter.ValidateQuery(query)
+			if err != nil && strings.Contains(err.Error(), "multiple statements") {
+				t.Errorf("False positive on comment: %v", err)
+			}
+		})
+	}
+}
+
+func TestSQLiteRemoveStringsAndComments(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "single-quoted string stripped",
+			input:    "SELECT * FROM users WHERE name = 'DROP TABLE'",
+			expected: "SELECT * FROM users WHERE name = ''",
+		},
+		{
+			name:     "-- comment stripped",
+			input:    "SELECT * FROM users -- comment",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			name:     "/* */ comment stripped",
+			input:    "SELECT * FROM users /* comment */",
+			expected: "SELECT * FROM users  ",
+		},
+		{
+			name:     "backtick identifier preserved",
+			input:    "SELECT * FROM `table_name`",
+			expected: "SELECT * FROM `table_name`",
+		},
+		{
+			name:     "bracket identifier preserved",
+			input:    "SELECT * FROM [table_name]",
+			expected: "SELECT * FROM [table_name]",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result := adapter.RemoveStringsAndComments(tc.input)
+			if result != tc.expected {
+				t.Errorf("Expected %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestSQLiteRemoveStringsAndComments_NoHash(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	// # is NOT a comment in SQLite
+	input := "SELECT # FROM users"
+	result := adapter.RemoveStringsAndComments(input)
+	if !strings.Contains(result, "#") {
+		t.Errorf("# should not be treated as a comment in SQLite: %s", result)
+	}
+}
+
+func TestSQLiteIsTransientError(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+
+	if adapter.IsTransientError(errors.New("database is locked")) {
+		t.Error("Untyped errors should not be treated as transient")
+	}
+
+	// Provoke SQLITE_BUSY: hold a write lock on one connection while another writes
+	path := filepath.Join(t.TempDir(), "busy.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE t (id INTEGER)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+
+	holder, err := db.Begin()
+	if err != nil {
+		t.Fatalf("Failed to begin transaction: %v", err)
+	}
+	defer holder.Rollback()
+	if _, err := holder.Exec("INSERT INTO t VALUES (1)"); err != nil {
+		t.Fatalf("Failed to take write lock: %v", err)
+	}
+
+	_, err = db.Exec("INSERT INTO t VALUES (2)")
+	if err == nil {
+		t.Fatal("Expected concurrent write to fail with SQLITE_BUSY")
+	}
+	if !adapter.IsTransientError(err) {
+		t.Errorf("Expected SQLITE_BUSY to be transient, got: %v", err)
+	}
+}
+
+func TestSQLiteAuditPrivileges_WritableFile(t *testing.T) {
+	adapter := &SQLiteAdapter{}
+	path := filepath.Join(t.TempDir(), "audit.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if err := db.Ping(); err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	findings, err := adapter.AuditPrivileges(context.Background(), db, "file:"+path+"?mode=ro")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(findings) != 1 || !strings.Contains(findings[0], "writable") {
+		t.Errorf("Expected writable file finding, got %v", findings)
+	}
+}
+
+func TestSQLiteFilePath(t *testing.T) {
+	tests := map[string]string{
+		"/data/app.db":              "/data/app.db",
+		"/data/app.db?mode=ro":      "/data/app.db",
+		"file:/data/app.db?mode=ro": "/data/app.db",
+		"file:relative.db":          "relative.db",
+	}
+	for dsn, expected := range tests {
+		if got := sqliteFilePath(dsn); got != expected {
+			t.Errorf("sqliteFilePath(%q) = %q, expected %q", dsn, got, expected)
+		}
+	}
+}
+
+func TestSandboxSQLiteDSN(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	db := filepath.Join(allowed, "app.db")
+	secret := filepath.Join(outside, "secret.db")
+	os.WriteFile(db, nil, 0600)
+	os.WriteFile(secret, nil, 0600)
+	link := filepath.Join(allowed, "link.db")
+	if err := os.Symlink(secret, link); err != nil {
+		t.Fatalf("Failed to create symlink: %v", err)
+	}
+	resolvedDB, _ := filepath.EvalSymlinks(db)
+
+	// Allowed paths are rewritten to their resolved location
+	got, err := sandboxSQLiteDSN("file:"+db+"?mode=ro", []string{allowed})
+	if err != nil || got != "file:"+resolvedDB+"?mode=ro" {
+		t.Errorf("Expected resolved DSN, got %q (err %v)", got, err)
+	}
+
+	blocked := map[string]string{
+		"outside allowed dirs": secret + "?mode=ro",
+		"symlink escape":       link,
+		"parent traversal":     filepath.Join(allowed, "..", filepath.Base(outside), "secret.db"),
+		"missing file":         filepath.Join(allowed, "missing.db"),
+		"directory":            allowed,
+	}
+	for name, dsn := range blocked {
+		if _, err := sandboxSQLiteDSN(dsn, []string{allowed}); err == nil {
+			t.Errorf("%s: expected %q to be rejected", name, dsn)
+		}
+	}
+
+	// Without MCP_SQLITE_ALLOWED_DIRS any existing file is accepted
+	if _, err := sandboxSQLiteDSN(secret, nil); err != nil {
+		t.Errorf("Expected unrestricted path to be accepted, got %v", err)
+	}
+}
+
+func TestSQLiteFormatDSN_RefusesWrites(t *testing.T) {
+	path := newTestDB(t)
+	dsn, err := (&SQLiteAdapter{}).FormatDSN(ConnParams{Database: path})
+	if err != nil || dsn != "file:"+path+"?mode=ro" {
+		t.Fatalf("Expected a read-only file: URI, got %q (err %v)", dsn, err)
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("CREATE TABLE audit (id INTEGER)"); err == nil {
+		t.Error("Expected mode=ro to refuse writes")
+	}
+}
+
+func TestWithSQLiteParam(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"file:/data/app.db", "file:/data/app.db?immutable=1"},
+		{"file:/data/app.db?mode=ro", "file:/data/app.db?mode=ro&immutable=1"},
+		{"file:/data/app.db?immutable=0", "file:/data/app.db?immutable=0"},
+	}
+	for _, tt := range tests {
+		if got := withSQLiteParam(tt.dsn, "immutable", "1"); got != tt.want {
+			t.Errorf("withSQLiteParam(%q) = %q, expected %q", tt.dsn, got, tt.want)
+		}
+	}
+	if got := sqliteReadOnlyURI("/data/app.db?mode=rw"); got != "file:/data/app.db?mode=rw" {
+		t.Errorf("Expected an explicit mode to be kept, got %q", got)
+	}
+}
+
+func TestProtectSQLiteFile(t *testing.T) {
+	defer func(orig bool) { SQLiteRequireReadOnlyFS = orig }(SQLiteRequireReadOnlyFS)
+	path := newTestDB(t, "PRAGMA journal_mode = WAL")
+
+	// A WAL database on writable storage gets -wal and -shm files even
+	// when opened read-only, unless it is opened immutable
+	created, err := sqliteCreatedFiles("file:"+path+"?mode=ro", path)
+	if err != nil || len(created) != 2 {
+		t.Errorf("Expected -wal and -shm to be created, got %v (err %v)", created, err)
+	}
+	for _, name := range created {
+		os.Remove(name)
+	}
+	if created, err := sqliteCreatedFiles("file:"+path+"?mode=ro&immutable=1", path); err != nil || len(created) != 0 {
+		t.Errorf("Expected no files beside an immutable database, got %v (err %v)", created, err)
+	}
+
+	SQLiteRequireReadOnlyFS = false
+	dsn, err := protectSQLiteFile(path)
+	if err != nil || dsn != "file:"+path+"?mode=ro" {
+		t.Errorf("Expected a writable file to be opened with mode=ro, got %q (err %v)", dsn, err)
+	}
+
+	SQLiteRequireReadOnlyFS = true
+	if _, err := protectSQLiteFile(path); err == nil || !strings.Contains(err.Error(), "MCP_SQLITE_REQUIRE_RO_FS") {
+		t.Errorf("Expected a writable file to be refused, got %v", err)
+	}
+}
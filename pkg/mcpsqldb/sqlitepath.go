@@ -0,0 +1,202 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+)
+
+// SQLiteAllowedDirs restricts SQLite databases to files under these
+// directories; empty allows any path (overridable via MCP_SQLITE_ALLOWED_DIRS
+// env var, comma-separated)
+var SQLiteAllowedDirs []string
+
+// SQLiteRequireReadOnlyFS refuses to start unless the operating system keeps
+// the SQLite database unchanged: the file must not be writable by this
+// process, and opening it must create no -wal or -shm files beside it
+// (overridable via MCP_SQLITE_REQUIRE_RO_FS env var)
+var SQLiteRequireReadOnlyFS = false
+
+// sandboxSQLiteDSN checks the database file named by dsn and returns the DSN
+// rewritten to the file's resolved path, so a symlink swapped after the check
+// cannot redirect the open.
+func sandboxSQLiteDSN(dsn string, allowedDirs []string) (string, error) {
+	path := sqliteFilePath(dsn)
+	resolved, err := resolveDatabaseFile(path, allowedDirs, "SQLite", "MCP_SQLITE_ALLOWED_DIRS")
+	if err != nil {
+		return "", err
+	}
+	prefix := ""
+	if strings.HasPrefix(dsn, "file:") {
+		prefix = "file:"
+	}
+	return prefix + resolved + dsn[len(prefix)+len(path):], nil
+}
+
+// protectSQLiteFile returns dsn as a read-only file: URI, adding immutable=1
+// when the file is on read-only media so SQLite neither locks it nor looks
+// for a journal. It opens the database once to confirm that reading it
+// creates no -wal or -shm files, which a WAL database on writable storage
+// gets even when opened read-only.
+func protectSQLiteFile(dsn string) (string, error) {
+	path := sqliteFilePath(dsn)
+	readOnlyMedia, writable := sqliteFileAccess(path)
+	if writable && SQLiteRequireReadOnlyFS {
+		return "", fmt.Errorf("SQLite database %s is writable by this process and MCP_SQLITE_REQUIRE_RO_FS is set", path)
+	}
+
+	dsn = sqliteReadOnlyURI(dsn)
+	if readOnlyMedia {
+		immutable := withSQLiteParam(dsn, "immutable", "1")
+		_, err := sqliteCreatedFiles(immutable, path)
+		if err == nil {
+			return immutable, nil
+		}
+		slog.Warn("Could not open SQLite database as immutable; falling back to mode=ro", "path", path, "error", err)
+	}
+
+	created, err := sqliteCreatedFiles(dsn, path)
+	if err != nil {
+		return "", fmt.Errorf("SQLite database %q cannot be opened: %w", path, err)
+	}
+	if len(created) == 0 {
+		return dsn, nil
+	}
+	if SQLiteRequireReadOnlyFS {
+		return "", fmt.Errorf("opening SQLite database %s created %s and MCP_SQLITE_REQUIRE_RO_FS is set",
+			path, strings.Join(created, ", "))
+	}
+	slog.Warn("Opening the SQLite database created files beside it; mount it read-only to leave its directory untouched",
+		"path", path, "files", created)
+	return dsn, nil
+}
+
+// sqliteFileAccess reports whether path is on a read-only filesystem and
+// whether this process could open it for writing. A file it may not write
+// because of its permissions is neither.
+func sqliteFileAccess(path string) (readOnlyMedia, writable bool) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return errors.Is(err, syscall.EROFS), false
+	}
+	f.Close()
+	return false, true
+}
+
+// sqliteCreatedFiles opens dsn, reads the schema and returns the -wal and
+// -shm files of path that did not exist before.
+func sqliteCreatedFiles(dsn, path string) ([]string, error) {
+	existed := make(map[string]bool)
+	for _, name := range sqliteSideFiles(path) {
+		existed[name] = true
+	}
+
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), ConnectionTimeout)
+	defer cancel()
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master").Scan(&tables); err != nil {
+		return nil, err
+	}
+
+	// Look while the connection is open: closing the last one may remove them
+	var created []string
+	for _, name := range sqliteSideFiles(path) {
+		if !existed[name] {
+			created = append(created, name)
+		}
+	}
+	return created, nil
+}
+
+// sqliteSideFiles returns the -wal and -shm files that exist beside path.
+func sqliteSideFiles(path string) []string {
+	var names []string
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if _, err := os.Lstat(path + suffix); err == nil {
+			names = append(names, path+suffix)
+		}
+	}
+	return names
+}
+
+// sqliteReadOnlyURI returns dsn as a file: URI with mode=ro, unless it sets
+// a mode of its own. The driver drops the parameters of a DSN without the
+// file: prefix, which would open the database read-write.
+func sqliteReadOnlyURI(dsn string) string {
+	if !strings.HasPrefix(dsn, "file:") {
+		dsn = "file:" + dsn
+	}
+	return withSQLiteParam(dsn, "mode", "ro")
+}
+
+// withSQLiteParam adds key=value to dsn unless it already sets key.
+func withSQLiteParam(dsn, key, value string) string {
+	_, query, found := strings.Cut(dsn, "?")
+	if !found {
+		return dsn + "?" + key + "=" + value
+	}
+	for _, param := range strings.Split(query, "&") {
+		if strings.HasPrefix(param, key+"=") {
+			return dsn
+		}
+	}
+	return dsn + "&" + key + "=" + value
+}
+
+// resolveDatabaseFile rejects ".." components, resolves symlinks and
+// requires the result to be an existing regular file inside one of
+// allowedDirs. kind and dirsVar name the database and its setting in errors.
+func resolveDatabaseFile(path string, allowedDirs []string, kind, dirsVar string) (string, error) {
+	if slices.Contains(strings.FieldsFunc(path, isPathSeparator), "..") {
+		return "", fmt.Errorf("%s path %q must not contain '..'", kind, path)
+	}
+
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s path %q: %w", kind, path, err)
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", fmt.Errorf("%s database %q cannot be opened: %w", kind, path, err)
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("%s database %q cannot be opened: %w", kind, path, err)
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%s database %q is not a regular file", kind, path)
+	}
+
+	if len(allowedDirs) == 0 {
+		return resolved, nil
+	}
+	for _, dir := range allowedDirs {
+		base, err := filepath.Abs(dir)
+		if err != nil {
+			continue
+		}
+		if base, err = filepath.EvalSymlinks(base); err != nil {
+			continue
+		}
+		if rel, err := filepath.Rel(base, resolved); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("%s database %q resolves to %s, outside %s", kind, path, resolved, dirsVar)
+}
+
+func isPathSeparator(r rune) bool {
+	return os.IsPathSeparator(uint8(r))
+}
@@ -0,0 +1,103 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// Paths of the legacy HTTP+SSE transport, served by the http transport
+// beside HTTPEndpoint for clients that predate Streamable HTTP
+const (
+	SSEEndpoint     = "/sse"
+	SSEMessagesPath = "/messages"
+)
+
+// sseSessionParam is the query parameter naming the session a message
+// posted to SSEMessagesPath belongs to.
+const sseSessionParam = "sessionId"
+
+// sseEventBuffer is how many responses a legacy session queues for its
+// event stream before posting blocks.
+const sseEventBuffer = 16
+
+// SSEKeepaliveInterval is how often a keepalive comment is written to the
+// event stream of a legacy HTTP+SSE session, which is the session itself,
+// so proxies do not close it while the client is quiet; 0 disables them
+// (overridable via MCP_SSE_KEEPALIVE_INTERVAL env var, in seconds)
+var SSEKeepaliveInterval = 15 * time.Second
+
+// legacyStream opens a session of the legacy HTTP+SSE transport and holds
+// its event stream open. The first event, endpoint, gives the URL to post
+// the session's messages to; their responses follow as message events. The
+// session ends with the stream.
+func (t *httpTransport) legacyStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	session := t.open(principalFrom(r.Context()), true)
+	if session == nil {
+		writeHTTPError(w, http.StatusServiceUnavailable, InternalError, fmt.Sprintf("Too many sessions (at most %d)", HTTPMaxSessions))
+		return
+	}
+	session.streaming.Store(true)
+	defer t.close(session, "Client disconnected")
+
+	events := openEventStream(w, flusher)
+	endpoint := SSEMessagesPath + "?" + url.Values{sseSessionParam: {session.server.sessionID}}.Encode()
+	if _, err := fmt.Fprintf(w, "event: endpoint\ndata: %s\n\n", endpoint); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var pings <-chan time.Time
+	if SSEKeepaliveInterval > 0 {
+		ticker := time.NewTicker(SSEKeepaliveInterval)
+		defer ticker.Stop()
+		pings = ticker.C
+	}
+	serveEvents(r.Context(), session, events, pings)
+}
+
+// legacyPost handles a message posted to a legacy session. The post is
+// answered with 202 Accepted only once the message has been handled and its
+// response, if any, queued for the session's event stream, so a slow tool
+// call holds the post open and a full stream holds back the client.
+func (t *httpTransport) legacyPost(w http.ResponseWriter, r *http.Request) {
+	id := r.URL.Query().Get(sseSessionParam)
+	if id == "" {
+		writeHTTPError(w, http.StatusBadRequest, InvalidRequest, "Missing "+sseSessionParam+" parameter; connect to "+SSEEndpoint+" first")
+		return
+	}
+	session := t.find(w, r, id)
+	if session == nil {
+		return
+	}
+	if session.events == nil {
+		writeHTTPError(w, http.StatusBadRequest, InvalidRequest, "Session uses Streamable HTTP; post its messages to "+HTTPEndpoint)
+		return
+	}
+	data, ok := readMessage(w, r)
+	if !ok {
+		return
+	}
+
+	response := session.handle(data)
+	if response != nil {
+		var msg bytes.Buffer
+		session.server.writeMessage(&msg, response)
+		select {
+		case session.events <- msg.Bytes():
+		case <-session.server.ctx.Done():
+			writeHTTPError(w, http.StatusNotFound, InvalidRequest, "Session not found; connect to "+SSEEndpoint+" to start a new one")
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
@@ -0,0 +1,185 @@
+package mcpsqldb
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sseEvent is one server-sent event, without its trailing blank line.
+type sseEvent struct {
+	name string
+	data string
+	// comment is set for a comment line, such as a keepalive
+	comment string
+}
+
+// sseStream is the event stream of a legacy session.
+type sseStream struct {
+	resp     *http.Response
+	reader   *bufio.Reader
+	endpoint string
+}
+
+// connectSSE opens a legacy session on ts and reads its endpoint event.
+func connectSSE(t *testing.T, ts *httptest.Server) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+SSEEndpoint, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("GET failed: %v", err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		resp.Body.Close()
+	})
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	stream := &sseStream{resp: resp, reader: bufio.NewReader(resp.Body)}
+	event := stream.next(t)
+	if event.name != "endpoint" || !strings.HasPrefix(event.data, SSEMessagesPath+"?"+sseSessionParam+"=") {
+		t.Fatalf("Expected the endpoint event first, got %+v", event)
+	}
+	stream.endpoint = event.data
+	return stream
+}
+
+// next reads the stream's next event or comment.
+func (s *sseStream) next(t *testing.T) sseEvent {
+	t.Helper()
+	var event sseEvent
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "":
+			return event
+		case strings.HasPrefix(line, ": "):
+			event.comment = strings.TrimPrefix(line, ": ")
+		case strings.HasPrefix(line, "event: "):
+			event.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+// post sends message to the stream's endpoint and returns the status.
+func (s *sseStream) post(t *testing.T, ts *httptest.Server, message string) int {
+	t.Helper()
+	resp, err := http.Post(ts.URL+s.endpoint, "application/json", strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("POST failed: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestSSETransport_Session(t *testing.T) {
+	defer func(orig time.Duration) { SSEKeepaliveInterval = orig }(SSEKeepaliveInterval)
+	SSEKeepaliveInterval = 0
+	ts := newHTTPTestServer(t)
+	stream := connectSSE(t, ts)
+
+	if status := stream.post(t, ts, initializeMessage); status != http.StatusAccepted {
+		t.Fatalf("Expected the message to be accepted, got %d", status)
+	}
+	event := stream.next(t)
+	if event.name != "message" || !strings.Contains(event.data, `"protocolVersion"`) {
+		t.Fatalf("Expected the initialize response on the stream, got %+v", event)
+	}
+
+	// Notifications have no response
+	if status := stream.post(t, ts, `{"jsonrpc":"2.0","method":"notifications/initialized"}`); status != http.StatusAccepted {
+		t.Errorf("Expected the notification to be accepted, got %d", status)
+	}
+	stream.post(t, ts, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"query","arguments":{"sql":"SELECT name FROM users WHERE id = 2"}}}`)
+	if event := stream.next(t); !strings.Contains(event.data, `"id":2`) || !strings.Contains(event.data, "bob") {
+		t.Errorf("Expected the query result on the stream, got %+v", event)
+	}
+}
+
+func TestSSETransport_Keepalive(t *testing.T) {
+	defer func(orig time.Duration) { SSEKeepaliveInterval = orig }(SSEKeepaliveInterval)
+	SSEKeepaliveInterval = 20 * time.Millisecond
+	ts := newHTTPTestServer(t)
+	stream := connectSSE(t, ts)
+
+	if event := stream.next(t); event.comment != "keepalive" {
+		t.Errorf("Expected a keepalive comment, got %+v", event)
+	}
+}
+
+func TestSSETransport_SessionEndsWithStream(t *testing.T) {
+	ts := newHTTPTestServer(t)
+	stream := connectSSE(t, ts)
+	stream.resp.Body.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		status := stream.post(t, ts, `{"jsonrpc":"2.0","id":1,"method":"ping"}`)
+		if status == http.StatusNotFound {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the session to close with its stream, got %d", status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSSETransport_Refused(t *testing.T) {
+	defer func(tokens []string, sessions int) { AuthTokens, HTTPMaxSessions = tokens, sessions }(AuthTokens, HTTPMaxSessions)
+	ts := newHTTPTestServer(t)
+
+	cases := []struct {
+		url    string
+		status int
+	}{
+		{SSEMessagesPath, http.StatusBadRequest},
+		{SSEMessagesPath + "?" + sseSessionParam + "=unknown", http.StatusNotFound},
+		{SSEMessagesPath + "?" + sseSessionParam + "=" + initializeSession(t, ts), http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		resp, err := http.Post(ts.URL+tc.url, "application/json", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+		if err != nil {
+			t.Fatalf("POST failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tc.status {
+			t.Errorf("Expected POST %s to get %d, got %d", tc.url, tc.status, resp.StatusCode)
+		}
+	}
+
+	HTTPMaxSessions = 1
+	resp, err := http.Get(ts.URL + SSEEndpoint)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected a session past the limit to be refused, got %d", resp.StatusCode)
+	}
+
+	AuthTokens = []string{"token-one"}
+	authed := newHTTPTestServer(t)
+	resp, err = http.Get(authed.URL + SSEEndpoint)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a stream without a token to be refused, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,234 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// SSHHost is the bastion (host[:port]) database connections are tunneled
+// through (overridable via MCP_SSH_HOST env var)
+var SSHHost string
+
+// SSHUser is the login on the bastion (overridable via MCP_SSH_USER env var)
+var SSHUser string
+
+// SSHKeyFile is a private key used to log in to the bastion; without it the
+// SSH agent at SSH_AUTH_SOCK is used (overridable via MCP_SSH_KEY_FILE env var)
+var SSHKeyFile string
+
+// SSHKnownHosts verifies the bastion's host key
+// (overridable via MCP_SSH_KNOWN_HOSTS env var; defaults to ~/.ssh/known_hosts)
+var SSHKnownHosts string
+
+// SSHKeepAlive is the interval between keepalive probes on the tunnel
+// (overridable via MCP_SSH_KEEPALIVE env var, in seconds)
+var SSHKeepAlive = 30 * time.Second
+
+// sshTunnel dials database connections through an SSH bastion, reconnecting
+// when the SSH session is lost.
+type sshTunnel struct {
+	addr   string
+	config *ssh.ClientConfig
+
+	mu     sync.Mutex
+	client *ssh.Client
+	agent  net.Conn
+	closed bool
+}
+
+// newSSHTunnel prepares a tunnel to host; the SSH connection itself is
+// established on the first dial.
+func newSSHTunnel(host, user, keyFile, passphrase, knownHostsFile string) (*sshTunnel, error) {
+	if user == "" {
+		return nil, fmt.Errorf("MCP_SSH_USER is required when MCP_SSH_HOST is set")
+	}
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		host = net.JoinHostPort(host, "22")
+	}
+
+	t := &sshTunnel{addr: host}
+
+	var auth []ssh.AuthMethod
+	if keyFile != "" {
+		signer, err := loadSSHKey(keyFile, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	} else if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to SSH agent: %w", err)
+		}
+		t.agent = conn
+		auth = append(auth, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	} else {
+		return nil, fmt.Errorf("no SSH credentials: set MCP_SSH_KEY_FILE or run an SSH agent")
+	}
+
+	if knownHostsFile == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("MCP_SSH_KNOWN_HOSTS is not set and the home directory is unknown: %w", err)
+		}
+		knownHostsFile = filepath.Join(home, ".ssh", "known_hosts")
+	}
+	hostKeyCallback, err := knownhosts.New(knownHostsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load SSH known hosts: %w", err)
+	}
+
+	t.config = &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         ConnectionTimeout,
+	}
+	return t, nil
+}
+
+func loadSSHKey(path, passphrase string) (ssh.Signer, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH key: %w", err)
+	}
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(pemBytes, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(pemBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SSH key %s: %w", path, err)
+	}
+	return signer, nil
+}
+
+// DialContext opens a connection to addr from the bastion. If the SSH
+// session turns out to be dead it is re-established once before giving up.
+func (t *sshTunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	client, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := client.DialContext(ctx, network, addr)
+	if err == nil || t.alive(client) {
+		return conn, err
+	}
+
+	slog.Warn("SSH tunnel lost, reconnecting", "bastion", t.addr, "error", err)
+	t.drop(client)
+	if client, err = t.connect(ctx); err != nil {
+		return nil, err
+	}
+	return client.DialContext(ctx, network, addr)
+}
+
+// connect returns the current SSH client, establishing it if needed.
+func (t *sshTunnel) connect(ctx context.Context) (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return nil, fmt.Errorf("SSH tunnel is closed")
+	}
+	if t.client != nil {
+		return t.client, nil
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach SSH bastion %s: %w", t.addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, t.addr, t.config)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("SSH handshake with %s failed: %w", t.addr, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	t.client = client
+
+	go t.keepAlive(client)
+	slog.Info("SSH tunnel established", "bastion", t.addr)
+	return client, nil
+}
+
+// keepAlive probes client every SSHKeepAlive and drops it once a probe fails
+// or the connection closes, so the next dial reconnects.
+func (t *sshTunnel) keepAlive(client *ssh.Client) {
+	done := make(chan struct{})
+	go func() {
+		client.Wait()
+		close(done)
+	}()
+
+	ticker := time.NewTicker(SSHKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			t.drop(client)
+			return
+		case <-ticker.C:
+			if !t.alive(client) {
+				slog.Warn("SSH keepalive failed", "bastion", t.addr)
+				t.drop(client)
+				return
+			}
+		}
+	}
+}
+
+// alive sends a keepalive request, treating no reply within SSHKeepAlive as
+// a dead connection.
+func (t *sshTunnel) alive(client *ssh.Client) bool {
+	reply := make(chan error, 1)
+	go func() {
+		_, _, err := client.SendRequest("keepalive@openssh.com", true, nil)
+		reply <- err
+	}()
+	select {
+	case err := <-reply:
+		return err == nil
+	case <-time.After(SSHKeepAlive):
+		return false
+	}
+}
+
+// drop closes client and forgets it if it is still the current one.
+func (t *sshTunnel) drop(client *ssh.Client) {
+	t.mu.Lock()
+	if t.client == client {
+		t.client = nil
+	}
+	t.mu.Unlock()
+	client.Close()
+}
+
+// Close shuts down the SSH connection; later dials fail.
+func (t *sshTunnel) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	if t.agent != nil {
+		t.agent.Close()
+	}
+	if t.client != nil {
+		err := t.client.Close()
+		t.client = nil
+		return err
+	}
+	return nil
+}
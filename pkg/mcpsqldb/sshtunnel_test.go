@@ -0,0 +1,207 @@
+package mcpsqldb
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// fakeBastion is a minimal SSH server that forwards direct-tcpip channels.
+type fakeBastion struct {
+	addr    string
+	keyFile string
+	hosts   string
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func startFakeBastion(t *testing.T) *fakeBastion {
+	t.Helper()
+	dir := t.TempDir()
+
+	_, hostPriv, _ := ed25519.GenerateKey(rand.Reader)
+	hostSigner, _ := ssh.NewSignerFromKey(hostPriv)
+	clientPub, clientPriv, _ := ed25519.GenerateKey(rand.Reader)
+	authorized, _ := ssh.NewPublicKey(clientPub)
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) == string(authorized.Marshal()) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("unknown key")
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	b := &fakeBastion{addr: listener.Addr().String()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns = append(b.conns, conn)
+			b.mu.Unlock()
+			go b.serve(conn, config)
+		}
+	}()
+
+	block, _ := ssh.MarshalPrivateKey(clientPriv, "")
+	b.keyFile = filepath.Join(dir, "id_ed25519")
+	os.WriteFile(b.keyFile, pem.EncodeToMemory(block), 0600)
+
+	b.hosts = filepath.Join(dir, "known_hosts")
+	os.WriteFile(b.hosts, []byte(knownhosts.Line([]string{b.addr}, hostSigner.PublicKey())+"\n"), 0600)
+	return b
+}
+
+func (b *fakeBastion) serve(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go func() {
+		for req := range reqs {
+			req.Reply(true, nil) // answer keepalives
+		}
+	}()
+	for newChan := range chans {
+		var target struct {
+			Host       string
+			Port       uint32
+			OriginHost string
+			OriginPort uint32
+		}
+		if newChan.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChan.ExtraData(), &target) != nil {
+			newChan.Reject(ssh.UnknownChannelType, "unsupported")
+			continue
+		}
+		upstream, err := net.Dial("tcp", net.JoinHostPort(target.Host, fmt.Sprint(target.Port)))
+		if err != nil {
+			newChan.Reject(ssh.ConnectionFailed, err.Error())
+			continue
+		}
+		ch, chReqs, _ := newChan.Accept()
+		go ssh.DiscardRequests(chReqs)
+		go func() {
+			defer ch.Close()
+			defer upstream.Close()
+			go io.Copy(upstream, ch)
+			io.Copy(ch, upstream)
+		}()
+	}
+}
+
+// dropConnections simulates the bastion link dying.
+func (b *fakeBastion) dropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, c := range b.conns {
+		c.Close()
+	}
+	b.conns = nil
+}
+
+// startGreeter accepts connections and writes a fixed greeting to each.
+func startGreeter(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("hello"))
+			conn.Close()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+func readGreeting(t *testing.T, tunnel *sshTunnel, target string) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, err := tunnel.DialContext(ctx, "tcp", target)
+	if err != nil {
+		t.Fatalf("Failed to dial through tunnel: %v", err)
+	}
+	defer conn.Close()
+	got, _ := io.ReadAll(conn)
+	if string(got) != "hello" {
+		t.Errorf("Expected greeting through tunnel, got %q", got)
+	}
+}
+
+func TestSSHTunnel_DialsThroughBastionAndReconnects(t *testing.T) {
+	bastion := startFakeBastion(t)
+	target := startGreeter(t)
+
+	tunnel, err := newSSHTunnel(bastion.addr, "mcp", bastion.keyFile, "", bastion.hosts)
+	if err != nil {
+		t.Fatalf("Failed to create tunnel: %v", err)
+	}
+	defer tunnel.Close()
+
+	readGreeting(t, tunnel, target)
+
+	bastion.dropConnections()
+	readGreeting(t, tunnel, target)
+}
+
+func TestSSHTunnel_RejectsUnknownHostKey(t *testing.T) {
+	bastion := startFakeBastion(t)
+
+	// A known_hosts file listing a different key for the bastion
+	_, otherPriv, _ := ed25519.GenerateKey(rand.Reader)
+	otherSigner, _ := ssh.NewSignerFromKey(otherPriv)
+	hosts := filepath.Join(t.TempDir(), "known_hosts")
+	os.WriteFile(hosts, []byte(knownhosts.Line([]string{bastion.addr}, otherSigner.PublicKey())+"\n"), 0600)
+
+	tunnel, err := newSSHTunnel(bastion.addr, "mcp", bastion.keyFile, "", hosts)
+	if err != nil {
+		t.Fatalf("Failed to create tunnel: %v", err)
+	}
+	defer tunnel.Close()
+
+	if _, err := tunnel.DialContext(context.Background(), "tcp", startGreeter(t)); err == nil {
+		t.Error("Expected host key mismatch to be rejected")
+	}
+}
+
+func TestNewSSHTunnel_RequiresCredentials(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := newSSHTunnel("bastion", "mcp", "", "", os.DevNull); err == nil {
+		t.Error("Expected error without key file or agent")
+	}
+	if _, err := newSSHTunnel("bastion", "", "key", "", os.DevNull); err == nil {
+		t.Error("Expected error without user")
+	}
+}
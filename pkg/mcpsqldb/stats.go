@@ -0,0 +1,52 @@
+package mcpsqldb
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// serverStats holds process-wide counters. It lives outside Server so the
+// counts survive the server being rebuilt, e.g. on a configuration reload.
+type serverStats struct {
+	startedAt time.Time
+
+	queriesOK       atomic.Int64
+	queriesRejected atomic.Int64
+	queriesErrored  atomic.Int64
+	bytesReturned   atomic.Int64
+	slowQueries     atomic.Int64
+	reconnects      atomic.Int64
+}
+
+var stats = &serverStats{startedAt: time.Now()}
+
+func init() {
+	// Served at /debug/vars on the MCP_PPROF_ADDR listener
+	expvar.Publish("mcp_stats", expvar.Func(func() any { return stats.snapshot() }))
+}
+
+// recordResult counts a finished query by outcome.
+func (st *serverStats) recordResult(result *CallToolResult) {
+	if result.IsError {
+		st.queriesErrored.Add(1)
+		return
+	}
+	st.queriesOK.Add(1)
+	for _, c := range result.Content {
+		st.bytesReturned.Add(int64(len(c.Text)))
+	}
+}
+
+func (st *serverStats) snapshot() map[string]any {
+	return map[string]any{
+		"started_at":       st.startedAt.UTC().Format(time.RFC3339),
+		"uptime_seconds":   int64(time.Since(st.startedAt).Seconds()),
+		"queries_ok":       st.queriesOK.Load(),
+		"queries_rejected": st.queriesRejected.Load(),
+		"queries_errored":  st.queriesErrored.Load(),
+		"bytes_returned":   st.bytesReturned.Load(),
+		"slow_queries":     st.slowQueries.Load(),
+		"reconnects":       st.reconnects.Load(),
+	}
+}
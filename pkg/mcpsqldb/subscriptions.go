@@ -0,0 +1,213 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SubscriptionPollInterval is how often the tables of subscribed stats
+// resources are recounted; 0 disables resources/subscribe (overridable via
+// MCP_SUBSCRIPTION_POLL_INTERVAL env var, in seconds)
+var SubscriptionPollInterval = 30 * time.Second
+
+// SubscriptionRowThreshold is how far a subscribed table's row count must
+// move from the count last reported before the client is notified
+// (overridable via MCP_SUBSCRIPTION_ROW_THRESHOLD env var)
+var SubscriptionRowThreshold int64 = 1
+
+// maxSubscriptions caps the stats resources one session polls.
+const maxSubscriptions = 20
+
+// tableStats is the content of a table's stats resource.
+type tableStats struct {
+	Table     string    `json:"table"`
+	RowCount  int64     `json:"row_count"`
+	CountedAt time.Time `json:"counted_at"`
+}
+
+// subscription is a stats resource the client subscribed to.
+type subscription struct {
+	table string
+	// rows is the row count the client last learned of
+	rows int64
+}
+
+// statsResourceURI returns the URI of table's stats resource.
+func (s *Server) statsResourceURI(table string) string {
+	return fmt.Sprintf("%s://%s/%s/stats", s.adapter.URIScheme(), s.databaseName, table)
+}
+
+// parseStatsURI returns the table of a stats resource URI of this database,
+// reporting false for other URIs.
+func (s *Server) parseStatsURI(uri string) (string, bool) {
+	rest, ok := strings.CutPrefix(uri, s.adapter.URIScheme()+"://")
+	if !ok {
+		return "", false
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) != 3 || parts[2] != "stats" || parts[0] != s.databaseName || parts[1] == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// countTableRows counts table's rows. The count runs outside any session
+// snapshot, which would hide the very changes a subscriber waits for.
+func (s *Server) countTableRows(ctx context.Context, table string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer s.workers.release()
+
+	rows, done, err := s.queryReadOnly(ctx, "SELECT COUNT(*) FROM "+s.adapter.QuoteIdentifier(table))
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+	defer rows.Close()
+
+	var count int64
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, fmt.Errorf("the count returned no rows")
+	}
+	if err := rows.Scan(&count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// readStatsResource serves a table's stats resource, reporting false when
+// uri is not one.
+func (s *Server) readStatsResource(ctx context.Context, uri string) (*ReadResourceResult, *Error, bool) {
+	table, ok := s.parseStatsURI(uri)
+	if !ok {
+		return nil, nil, false
+	}
+	// Answer as if the table did not exist rather than confirm it is hidden
+	if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Table not found: %s", table)}, true
+	}
+
+	count, err := s.countTableRows(ctx, table)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to count rows: %v", err), err), true
+	}
+	data, err := json.MarshalIndent(tableStats{Table: table, RowCount: count, CountedAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: fmt.Sprintf("Failed to marshal stats: %v", err)}, true
+	}
+	return &ReadResourceResult{
+		Contents: []ResourceContent{{URI: uri, MimeType: "application/json", Text: string(data)}},
+	}, nil, true
+}
+
+// handleSubscribe starts polling a table's stats resource, counting its rows
+// now so later changes are measured from the count the client can read.
+func (s *Server) handleSubscribe(ctx context.Context, params json.RawMessage) (any, *Error) {
+	if SubscriptionPollInterval <= 0 {
+		return nil, &Error{Code: MethodNotFound, Message: "Method not found: resources/subscribe"}
+	}
+	var subParams SubscribeParams
+	if err := json.Unmarshal(params, &subParams); err != nil {
+		return nil, &Error{Code: InvalidParams, Message: "Invalid parameters", Data: err.Error()}
+	}
+
+	uri := subParams.URI
+	table, ok := s.parseStatsURI(uri)
+	if !ok {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Only table stats resources can be subscribed to: expected %s", s.statsResourceURI("tablename")),
+		}
+	}
+	if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+		return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Table not found: %s", table)}
+	}
+	if _, ok := s.subscriptions[uri]; !ok && len(s.subscriptions) >= maxSubscriptions {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: fmt.Sprintf("Too many subscriptions (at most %d); unsubscribe from one first", maxSubscriptions),
+		}
+	}
+
+	count, err := s.countTableRows(ctx, table)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Sprintf("Failed to count rows: %v", err), err)
+	}
+	if s.subscriptions == nil {
+		s.subscriptions = map[string]*subscription{}
+	}
+	s.subscriptions[uri] = &subscription{table: table, rows: count}
+	loggerFrom(ctx).Info("Subscribed to table stats", "table", table, "rows", count)
+	return map[string]any{}, nil
+}
+
+// handleUnsubscribe stops polling a stats resource; unknown URIs are
+// accepted, as the subscription may already be gone.
+func (s *Server) handleUnsubscribe(params json.RawMessage) (any, *Error) {
+	var subParams SubscribeParams
+	if err := json.Unmarshal(params, &subParams); err != nil {
+		return nil, &Error{Code: InvalidParams, Message: "Invalid parameters", Data: err.Error()}
+	}
+	delete(s.subscriptions, subParams.URI)
+	return map[string]any{}, nil
+}
+
+// newSubscriptionTicker returns a ticker firing every
+// SubscriptionPollInterval, or nil when subscriptions are disabled, and the
+// channel to wait on (nil never fires).
+func newSubscriptionTicker() (*time.Ticker, <-chan time.Time) {
+	if SubscriptionPollInterval <= 0 {
+		return nil, nil
+	}
+	ticker := time.NewTicker(SubscriptionPollInterval)
+	return ticker, ticker.C
+}
+
+// pollSubscriptions recounts the rows of every subscribed table and writes
+// a notifications/resources/updated to w for each whose count moved by at
+// least SubscriptionRowThreshold. A failed count is logged and retried on
+// the next poll.
+func (s *Server) pollSubscriptions(ctx context.Context, w io.Writer) error {
+	uris := make([]string, 0, len(s.subscriptions))
+	for uri := range s.subscriptions {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+
+	for _, uri := range uris {
+		sub := s.subscriptions[uri]
+		count, err := s.countTableRows(ctx, sub.table)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			loggerFrom(ctx).Warn("Failed to poll table stats", "table", sub.table, "error", err)
+			continue
+		}
+		if change := count - sub.rows; change < SubscriptionRowThreshold && -change < SubscriptionRowThreshold {
+			continue
+		}
+		sub.rows = count
+		notification := &JSONRPCNotification{
+			JSONRPC: "2.0",
+			Method:  "notifications/resources/updated",
+			Params:  ResourceUpdatedParams{URI: uri},
+		}
+		if err := s.writeMessage(w, notification); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func subscribeParams(uri string) json.RawMessage {
+	params, _ := json.Marshal(SubscribeParams{URI: uri})
+	return params
+}
+
+func TestReadStatsResource_CountsRows(t *testing.T) {
+	server := newTestServer(t)
+
+	uri := server.statsResourceURI("users")
+	result, rpcErr := server.handleReadResource(context.Background(), subscribeParams(uri))
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	var stats tableStats
+	if err := json.Unmarshal([]byte(result.Contents[0].Text), &stats); err != nil || stats.RowCount != 3 || stats.Table != "users" {
+		t.Errorf("Expected 3 rows in users, got %s", result.Contents[0].Text)
+	}
+	if uri != "sqlite://test/users/stats" {
+		t.Errorf("Expected stats URI sqlite://test/users/stats, got %s", uri)
+	}
+}
+
+func TestSubscriptions_NotifyOnRowCountChange(t *testing.T) {
+	defer func(orig int64) { SubscriptionRowThreshold = orig }(SubscriptionRowThreshold)
+	SubscriptionRowThreshold = 2
+
+	path := newTestDB(t)
+	server := openTestServer(t, path)
+	ctx := context.Background()
+
+	uri := server.statsResourceURI("users")
+	if _, rpcErr := server.handleSubscribe(ctx, subscribeParams(uri)); rpcErr != nil {
+		t.Fatalf("Failed to subscribe: %v", rpcErr.Message)
+	}
+
+	writer, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open writer: %v", err)
+	}
+	defer writer.Close()
+
+	var out bytes.Buffer
+	writer.Exec("INSERT INTO users (name) VALUES ('dave')")
+	server.pollSubscriptions(ctx, &out)
+	if out.Len() != 0 {
+		t.Errorf("Expected no notification below the threshold, got %s", out.String())
+	}
+
+	writer.Exec("INSERT INTO users (name) VALUES ('erin')")
+	server.pollSubscriptions(ctx, &out)
+	var notification struct {
+		ID     any                   `json:"id"`
+		Method string                `json:"method"`
+		Params ResourceUpdatedParams `json:"params"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &notification); err != nil ||
+		notification.Method != "notifications/resources/updated" || notification.Params.URI != uri || notification.ID != nil {
+		t.Errorf("Expected a resource updated notification for %s, got %s", uri, out.String())
+	}
+
+	// Changes are measured from the count last reported
+	out.Reset()
+	server.pollSubscriptions(ctx, &out)
+	if out.Len() != 0 {
+		t.Errorf("Expected no notification without further changes, got %s", out.String())
+	}
+
+	server.handleUnsubscribe(subscribeParams(uri))
+	writer.Exec("DELETE FROM users")
+	server.pollSubscriptions(ctx, &out)
+	if out.Len() != 0 {
+		t.Errorf("Expected no notification after unsubscribing, got %s", out.String())
+	}
+}
+
+func TestSubscribe_RejectsInvalidResources(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"secrets"}
+	server := newTestServer(t, "CREATE TABLE secrets (id INTEGER)")
+	ctx := context.Background()
+
+	cases := map[string]string{
+		"schema resource": "sqlite://test/users/schema",
+		"other database":  "sqlite://other/users/stats",
+		"denied table":    "sqlite://test/secrets/stats",
+		"missing table":   "sqlite://test/missing/stats",
+	}
+	for name, uri := range cases {
+		if _, rpcErr := server.handleSubscribe(ctx, subscribeParams(uri)); rpcErr == nil {
+			t.Errorf("%s: expected subscribing to %s to fail", name, uri)
+		}
+	}
+	if len(server.subscriptions) != 0 {
+		t.Errorf("Expected no subscriptions, got %d", len(server.subscriptions))
+	}
+}
+
+func TestSubscribe_LimitsSubscriptions(t *testing.T) {
+	var tables []string
+	for i := range maxSubscriptions + 1 {
+		tables = append(tables, fmt.Sprintf("CREATE TABLE t%d (id INTEGER)", i))
+	}
+	server := newTestServer(t, tables...)
+	ctx := context.Background()
+
+	for i := range maxSubscriptions {
+		if _, rpcErr := server.handleSubscribe(ctx, subscribeParams(server.statsResourceURI(fmt.Sprintf("t%d", i)))); rpcErr != nil {
+			t.Fatalf("Failed to subscribe to t%d: %v", i, rpcErr.Message)
+		}
+	}
+	_, rpcErr := server.handleSubscribe(ctx, subscribeParams(server.statsResourceURI(fmt.Sprintf("t%d", maxSubscriptions))))
+	if rpcErr == nil || !strings.Contains(rpcErr.Message, "Too many subscriptions") {
+		t.Errorf("Expected the subscription limit to be enforced, got %v", rpcErr)
+	}
+	// Subscribing again to the same resource is not a new subscription
+	if _, rpcErr := server.handleSubscribe(ctx, subscribeParams(server.statsResourceURI("t0"))); rpcErr != nil {
+		t.Errorf("Expected resubscribing to succeed, got %v", rpcErr.Message)
+	}
+}
+
+func TestSubscribe_DisabledWithoutPolling(t *testing.T) {
+	defer func(orig time.Duration) { SubscriptionPollInterval = orig }(SubscriptionPollInterval)
+	server := newTestServer(t)
+	ctx := context.Background()
+
+	result, _ := server.handleInitialize(ctx, nil)
+	if !result.Capabilities.Resources.Subscribe {
+		t.Error("Expected the subscribe capability by default")
+	}
+
+	SubscriptionPollInterval = 0
+	result, _ = server.handleInitialize(ctx, nil)
+	if result.Capabilities.Resources.Subscribe {
+		t.Error("Expected no subscribe capability without polling")
+	}
+	if _, rpcErr := server.handleSubscribe(ctx, subscribeParams(server.statsResourceURI("users"))); rpcErr == nil || rpcErr.Code != MethodNotFound {
+		t.Errorf("Expected method not found, got %v", rpcErr)
+	}
+}
@@ -0,0 +1,78 @@
+package mcpsqldb
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// TableRowLimits are parsed from MCP_TABLE_ROW_LIMITS. Queries reading a
+// matching table return at most its number of rows, keeping results from
+// known-huge tables small under a MaxResultRows high enough for the rest.
+var TableRowLimits []tableRowLimit
+
+// tableRowLimit caps the rows of queries reading tables matching a
+// lower-cased glob pattern.
+type tableRowLimit struct {
+	pattern string
+	rows    int
+}
+
+// parseTableRowLimits parses a comma-separated list of table=rows entries,
+// where table may be a glob (e.g. "events=500,audit_*=100").
+func parseTableRowLimits(spec string) ([]tableRowLimit, error) {
+	var limits []tableRowLimit
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		table, rows, ok := strings.Cut(entry, "=")
+		table = strings.TrimSpace(table)
+		if !ok || table == "" {
+			return nil, fmt.Errorf("invalid table row limit %q: expected table=rows", entry)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(rows))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid row count in table row limit %q: expected a positive integer", entry)
+		}
+		if _, err := path.Match(table, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q in table row limit %q: %w", table, entry, err)
+		}
+		limits = append(limits, tableRowLimit{pattern: strings.ToLower(table), rows: n})
+	}
+	return limits, nil
+}
+
+// tableMaxRows returns the row limit for reading table: the lowest of
+// MaxResultRows and the limits matching it.
+func tableMaxRows(table string) int {
+	rows := MaxResultRows
+	table = strings.ToLower(table)
+	for _, limit := range TableRowLimits {
+		if ok, _ := path.Match(limit.pattern, table); ok && limit.rows < rows {
+			rows = limit.rows
+		}
+	}
+	return rows
+}
+
+// queryMaxRows returns the row limit for cleanedSQL and the table that set
+// it, or "" when it is MaxResultRows. As with the table denylist, every
+// identifier is checked, so a column sharing a limited table's name is
+// limited too.
+func queryMaxRows(cleanedSQL string) (int, string) {
+	rows, table := MaxResultRows, ""
+	if len(TableRowLimits) == 0 {
+		return rows, table
+	}
+	for _, m := range identifierPattern.FindAllStringSubmatch(cleanedSQL, -1) {
+		ident := firstIdentifier(m[1:])
+		if n := tableMaxRows(ident); n < rows {
+			rows, table = n, ident
+		}
+	}
+	return rows, table
+}
@@ -0,0 +1,72 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestParseTableRowLimits(t *testing.T) {
+	limits, err := parseTableRowLimits(" Events = 500, audit_* =100,")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	want := []tableRowLimit{{pattern: "events", rows: 500}, {pattern: "audit_*", rows: 100}}
+	if !reflect.DeepEqual(limits, want) {
+		t.Errorf("Expected %+v, got %+v", want, limits)
+	}
+
+	for _, spec := range []string{"events", "events=0", "events=many", "=5", "[x=5"} {
+		if _, err := parseTableRowLimits(spec); err == nil {
+			t.Errorf("Expected an error for %q", spec)
+		}
+	}
+}
+
+func TestQueryMaxRows(t *testing.T) {
+	defer func(orig []tableRowLimit) { TableRowLimits = orig }(TableRowLimits)
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	MaxResultRows = 1000
+	TableRowLimits = []tableRowLimit{{pattern: "events", rows: 500}, {pattern: "audit_*", rows: 100}, {pattern: "countries", rows: 5000}}
+
+	tests := []struct {
+		sql   string
+		rows  int
+		table string
+	}{
+		{"SELECT * FROM users", 1000, ""},
+		{"SELECT * FROM Events", 500, "Events"},
+		{"SELECT * FROM events e JOIN audit_log a ON a.event_id = e.id", 100, "audit_log"},
+		// A limit never raises MaxResultRows
+		{"SELECT * FROM countries", 1000, ""},
+	}
+	for _, tt := range tests {
+		if rows, table := queryMaxRows(tt.sql); rows != tt.rows || table != tt.table {
+			t.Errorf("Expected %d rows limited by %q for %s, got %d by %q", tt.rows, tt.table, tt.sql, rows, table)
+		}
+	}
+}
+
+func TestTableRowLimits_TruncateResults(t *testing.T) {
+	defer func(orig []tableRowLimit) { TableRowLimits = orig }(TableRowLimits)
+	TableRowLimits = []tableRowLimit{{pattern: "users", rows: 2}}
+	server := newTestServer(t)
+
+	rows := queryRows(t, server, "SELECT id FROM users ORDER BY id")
+	if len(rows) != 3 || rows[2]["_warning"] != "Result truncated at 2 rows (the row limit for users)" {
+		t.Errorf("Expected 2 rows and a truncation notice naming users, got %v", rows)
+	}
+	if rows := queryRows(t, server, "SELECT 1 AS a UNION ALL SELECT 2 UNION ALL SELECT 3"); len(rows) != 3 {
+		t.Errorf("Expected other queries to keep the global limit, got %v", rows)
+	}
+
+	result, rpcErr := server.sampleRows(context.Background(), map[string]any{"table": "users", "limit": float64(10)})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected a sample, got %v %+v", rpcErr, result)
+	}
+	var sampled []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &sampled); err != nil || len(sampled) != 2 {
+		t.Errorf("Expected the sample capped at 2 rows without a notice, got %s", result.Content[0].Text)
+	}
+}
@@ -0,0 +1,270 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// QueryTemplates are reports offered as tools of their own, parsed from
+// MCP_QUERY_TEMPLATES and the MCP_TEMPLATE_<NAME>_SQL and
+// MCP_TEMPLATE_<NAME>_DESCRIPTION env vars.
+var QueryTemplates []QueryTemplate
+
+// QueryTemplate is a query whose {{table}} and {{column}} placeholders are
+// filled with identifiers checked against the live schema, never with free
+// text, so one report can be run against any table that has the column.
+type QueryTemplate struct {
+	Name        string
+	Description string
+	SQL         string
+}
+
+// Placeholders a template's SQL may use
+const (
+	templateTable  = "table"
+	templateColumn = "column"
+)
+
+// templatePlaceholder matches a {{name}} placeholder.
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*(\w*)\s*\}\}`)
+
+// templateNamePattern matches template names, which become tool names.
+var templateNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+// parseQueryTemplates reads the templates named in a comma-separated list,
+// looking up each one's SQL and description with getenv.
+func parseQueryTemplates(names string, getenv func(string) string) ([]QueryTemplate, error) {
+	builtin := builtinToolNames()
+	seen := map[string]bool{}
+	var templates []QueryTemplate
+	for _, name := range strings.Split(names, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !templateNamePattern.MatchString(name) {
+			return nil, fmt.Errorf("invalid template name %q: expected lowercase letters, digits and underscores", name)
+		}
+		if builtin[name] {
+			return nil, fmt.Errorf("template %q has the name of a built-in tool", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("template %q is listed twice", name)
+		}
+		seen[name] = true
+
+		prefix := "MCP_TEMPLATE_" + strings.ToUpper(name) + "_"
+		t := QueryTemplate{
+			Name:        name,
+			Description: strings.TrimSpace(getenv(prefix + "DESCRIPTION")),
+			SQL:         strings.TrimSpace(getenv(prefix + "SQL")),
+		}
+		if t.SQL == "" {
+			return nil, fmt.Errorf("template %q has no SQL: set %sSQL", name, prefix)
+		}
+		if _, _, err := t.placeholders(); err != nil {
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+	return templates, nil
+}
+
+// builtinToolNames returns the names of every tool the server may offer, so
+// no template shadows one.
+func builtinToolNames() map[string]bool {
+	s := &Server{}
+	list, _ := s.handleListTools()
+	tools := append(list.Tools, s.crossQueryTool(), queryInsightsTool(), runningQueriesTool(), setActiveSchemaTool(), topQueriesTool())
+	tools = append(tools, resultCacheTools()...)
+
+	names := map[string]bool{}
+	for _, tool := range tools {
+		names[tool.Name] = true
+	}
+	for _, t := range QueryTemplates {
+		delete(names, t.Name)
+	}
+	return names
+}
+
+// placeholders reports which placeholders the template's SQL uses, failing
+// on any other and on a column without its table.
+func (t *QueryTemplate) placeholders() (table, column bool, err error) {
+	for _, match := range templatePlaceholder.FindAllStringSubmatch(t.SQL, -1) {
+		switch match[1] {
+		case templateTable:
+			table = true
+		case templateColumn:
+			column = true
+		default:
+			return false, false, fmt.Errorf("template %q uses unknown placeholder %s: expected {{table}} or {{column}}", t.Name, match[0])
+		}
+	}
+	if column && !table {
+		return false, false, fmt.Errorf("template %q uses {{column}} without {{table}}: the column is checked against the table", t.Name)
+	}
+	return table, column, nil
+}
+
+// tool describes the template as a tool, with a parameter per placeholder.
+func (t *QueryTemplate) tool() Tool {
+	table, column, _ := t.placeholders()
+	description := t.Description
+	if description == "" {
+		description = "Run the configured " + t.Name + " report"
+	}
+
+	properties := map[string]Property{}
+	required := []string{}
+	if table {
+		properties[templateTable] = Property{Type: "string", Description: "Table to run the report on"}
+		required = append(required, templateTable)
+	}
+	if column {
+		properties[templateColumn] = Property{Type: "string", Description: "Column of the table to run the report on"}
+		required = append(required, templateColumn)
+	}
+	return Tool{
+		Name:        t.Name,
+		Description: description,
+		InputSchema: InputSchema{Type: "object", Properties: properties, Required: required},
+	}
+}
+
+// templateTools returns the tools of the query templates not named like a
+// tool in tools.
+func templateTools(tools []Tool) []Tool {
+	listed := map[string]bool{}
+	for _, tool := range tools {
+		listed[tool.Name] = true
+	}
+	var result []Tool
+	for i := range QueryTemplates {
+		if !listed[QueryTemplates[i].Name] {
+			result = append(result, QueryTemplates[i].tool())
+		}
+	}
+	return result
+}
+
+// findQueryTemplate returns the template named name, or nil.
+func findQueryTemplate(name string) *QueryTemplate {
+	for i := range QueryTemplates {
+		if QueryTemplates[i].Name == name {
+			return &QueryTemplates[i]
+		}
+	}
+	return nil
+}
+
+// runQueryTemplate fills the template's placeholders with the table and
+// column given, once the live schema confirms they exist, and runs the
+// result like any other query, so validation, masking, limits and the
+// audit log all apply.
+func (s *Server) runQueryTemplate(ctx context.Context, t *QueryTemplate, args map[string]any) (*CallToolResult, *Error) {
+	usesTable, usesColumn, err := t.placeholders()
+	if err != nil {
+		return nil, &Error{Code: InternalError, Message: err.Error()}
+	}
+	table, _ := args[templateTable].(string)
+	column, _ := args[templateColumn].(string)
+	switch {
+	case usesTable && table == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'table' parameter"}
+	case usesColumn && column == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'column' parameter"}
+	}
+
+	values := map[string]string{}
+	if usesTable {
+		// Answer as if the table did not exist rather than confirm it is hidden
+		if isTableDenied(DeniedTables, table) || isSchemaDenied(DeniedSchemas, s.databaseName) {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Table not found: %s", table)}
+		}
+		columns, err := s.tableColumnNames(ctx, s.resolveName(table))
+		if err != nil {
+			return nil, internalError(ctx, fmt.Sprintf("Failed to get schema: %v", err), err)
+		}
+		if len(columns) == 0 {
+			return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Table not found: %s", table)}
+		}
+		values[templateTable] = s.quoteName(table)
+
+		if usesColumn {
+			name, ok := matchColumnName(columns, s.resolveName(column))
+			if !ok {
+				return nil, &Error{Code: InvalidParams, Message: fmt.Sprintf("Column not found in %s: %s", table, column)}
+			}
+			values[templateColumn] = s.adapter.QuoteIdentifier(name)
+		}
+	}
+
+	sqlQuery := templatePlaceholder.ReplaceAllStringFunc(t.SQL, func(placeholder string) string {
+		return values[templatePlaceholder.FindStringSubmatch(placeholder)[1]]
+	})
+	loggerFrom(ctx).Debug("Running query template", "template", t.Name, "table", table, "column", column)
+	return s.executeQuery(ctx, map[string]any{"sql": sqlQuery})
+}
+
+// tableColumnNames returns the names of table's columns, none when the
+// table does not exist.
+func (s *Server) tableColumnNames(ctx context.Context, table string) ([]string, error) {
+	columns, err := s.tableColumns(ctx, table)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, col := range columns {
+		if name, ok := col["column_name"].(string); ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// tableColumns returns table's columns as the adapter's ScanSchemaRow reads
+// them, none when the table does not exist.
+func (s *Server) tableColumns(ctx context.Context, table string) ([]map[string]any, error) {
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.workers.release()
+
+	query, queryArgs := s.adapter.ReadSchemaQuery(s.databaseName, table)
+	rows, err := s.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var columns []map[string]any
+	for rows.Next() {
+		col, err := s.adapter.ScanSchemaRow(rows)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, col)
+	}
+	return columns, rows.Err()
+}
+
+// matchColumnName returns the column spelled name, falling back to a
+// case-insensitive match for databases whose column names ignore case.
+func matchColumnName(columns []string, name string) (string, bool) {
+	for _, c := range columns {
+		if c == name {
+			return c, true
+		}
+	}
+	for _, c := range columns {
+		if strings.EqualFold(c, name) {
+			return c, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,101 @@
+package mcpsqldb
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func templateEnv(vars map[string]string) func(string) string {
+	return func(name string) string { return vars[name] }
+}
+
+func TestParseQueryTemplates(t *testing.T) {
+	templates, err := parseQueryTemplates("null_counts, row_count", templateEnv(map[string]string{
+		"MCP_TEMPLATE_NULL_COUNTS_SQL":         "SELECT COUNT(*) FROM {{table}} WHERE {{ column }} IS NULL",
+		"MCP_TEMPLATE_NULL_COUNTS_DESCRIPTION": "Count the NULLs of a column",
+		"MCP_TEMPLATE_ROW_COUNT_SQL":           "SELECT COUNT(*) FROM {{table}}",
+	}))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(templates) != 2 || templates[0].Name != "null_counts" || templates[0].Description != "Count the NULLs of a column" {
+		t.Fatalf("Expected two templates, got %+v", templates)
+	}
+
+	invalid := map[string]map[string]string{
+		"Row-Count":   {"MCP_TEMPLATE_ROW-COUNT_SQL": "SELECT 1"},
+		"query":       {"MCP_TEMPLATE_QUERY_SQL": "SELECT 1"},
+		"top_queries": {"MCP_TEMPLATE_TOP_QUERIES_SQL": "SELECT 1"},
+		"missing":     {},
+		"free_text":   {"MCP_TEMPLATE_FREE_TEXT_SQL": "SELECT * FROM {{table}} WHERE {{filter}}"},
+		"orphan":      {"MCP_TEMPLATE_ORPHAN_SQL": "SELECT {{column}} FROM users"},
+		"a,a":         {"MCP_TEMPLATE_A_SQL": "SELECT 1"},
+	}
+	for names, vars := range invalid {
+		if _, err := parseQueryTemplates(names, templateEnv(vars)); err == nil {
+			t.Errorf("Expected templates %q to be rejected", names)
+		}
+	}
+}
+
+func TestQueryTemplate_Tool(t *testing.T) {
+	defer func(orig []QueryTemplate) { QueryTemplates = orig }(QueryTemplates)
+	QueryTemplates = []QueryTemplate{
+		{Name: "null_counts", SQL: "SELECT COUNT(*) FROM {{table}} WHERE {{column}} IS NULL"},
+		{Name: "query", SQL: "SELECT 1"},
+	}
+	server := newTestServer(t)
+
+	tools, _ := server.handleListTools()
+	var found []Tool
+	for _, tool := range tools.Tools {
+		if tool.Name == "null_counts" || tool.Name == "query" {
+			found = append(found, tool)
+		}
+	}
+	if len(found) != 2 || found[1].Name != "null_counts" {
+		t.Fatalf("Expected the template listed after the built-in tools without shadowing them, got %+v", found)
+	}
+	if required := found[1].InputSchema.Required; len(required) != 2 || required[0] != "table" || required[1] != "column" {
+		t.Errorf("Expected table and column to be required, got %v", required)
+	}
+}
+
+func TestRunQueryTemplate(t *testing.T) {
+	defer func(orig []QueryTemplate) { QueryTemplates = orig }(QueryTemplates)
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	QueryTemplates = []QueryTemplate{
+		{Name: "distinct_values", SQL: "SELECT COUNT(DISTINCT {{column}}) AS n FROM {{table}}"},
+	}
+	DeniedTables = []string{"secrets"}
+	server := newTestServer(t, "CREATE TABLE secrets (name TEXT)")
+	ctx := context.Background()
+
+	result, rpcErr := server.callTool(ctx, "distinct_values", map[string]any{"table": "users", "column": "name"})
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if text := result.Content[0].Text; !strings.Contains(text, `"n": 3`) {
+		t.Errorf("Expected 3 distinct names, got %s", text)
+	}
+
+	cases := []struct {
+		name string
+		args map[string]any
+		want string
+	}{
+		{"missing column", map[string]any{"table": "users"}, "'column'"},
+		{"unknown table", map[string]any{"table": "nope", "column": "name"}, "Table not found: nope"},
+		{"denied table", map[string]any{"table": "secrets", "column": "name"}, "Table not found: secrets"},
+		{"unknown column", map[string]any{"table": "users", "column": "password"}, "Column not found in users: password"},
+		{"injected column", map[string]any{"table": "users", "column": "name) FROM users; --"}, "Column not found"},
+		{"injected table", map[string]any{"table": "users WHERE 1=1 --", "column": "name"}, "Table not found"},
+	}
+	for _, tc := range cases {
+		_, rpcErr := server.callTool(ctx, "distinct_values", tc.args)
+		if rpcErr == nil || !strings.Contains(rpcErr.Message, tc.want) {
+			t.Errorf("%s: expected an error containing %q, got %v", tc.name, tc.want, rpcErr)
+		}
+	}
+}
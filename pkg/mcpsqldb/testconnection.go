@@ -0,0 +1,160 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// connectionReport prints one line per check of test-connection and tracks
+// whether any of them failed.
+type connectionReport struct {
+	out    io.Writer
+	failed bool
+}
+
+func (r *connectionReport) ok(check, format string, args ...any) {
+	fmt.Fprintf(r.out, "[ok]   %s: %s\n", check, redactSecrets(fmt.Sprintf(format, args...)))
+}
+
+func (r *connectionReport) warn(check, format string, args ...any) {
+	fmt.Fprintf(r.out, "[warn] %s: %s\n", check, redactSecrets(fmt.Sprintf(format, args...)))
+}
+
+func (r *connectionReport) fail(check string, err error) {
+	r.failed = true
+	fmt.Fprintf(r.out, "[FAIL] %s: %s\n", check, redactSecrets(err.Error()))
+}
+
+// runTestConnection walks through what the server does at startup (connect,
+// enforce read-only, probe a write, audit privileges, list tables) and prints
+// a summary, to answer "is my configuration right?" without an MCP client.
+// It returns the process exit code: 0 when the server would start, 1
+// otherwise. Warnings, such as an account with write privileges, do not fail
+// the check unless the server would refuse to start because of them.
+func runTestConnection(ctx context.Context, out io.Writer, adapter DBAdapter, dsn string) int {
+	report := &connectionReport{out: out}
+	dbName := adapter.DatabaseName(dsn)
+	fmt.Fprintf(out, "Driver:   %s\n", adapter.DriverName())
+	fmt.Fprintf(out, "Database: %s\n", dbName)
+
+	// Local files and the Cloud SQL connector need no TLS settings
+	if adapter.Remote() && CloudSQLInstance == "" {
+		if adapter.TLSVerified(dsn) {
+			report.ok("tls", "server certificate is verified")
+		} else {
+			report.warn("tls", "connection does not verify the server certificate (see MCP_REQUIRE_TLS)")
+		}
+	}
+
+	db, err := openReadOnlyDB(adapter, dsn, connectionLabel("test-connection"))
+	if err != nil {
+		report.fail("connect", err)
+		return report.finish()
+	}
+	defer db.Close()
+
+	start := time.Now()
+	pingCtx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	err = db.PingContext(pingCtx)
+	cancel()
+	if err != nil {
+		report.fail("connect", err)
+		return report.finish()
+	}
+	report.ok("connect", "connected in %s", time.Since(start).Round(time.Millisecond))
+
+	checkCtx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if readOnly, err := sessionReadOnly(checkCtx, adapter, db); err != nil {
+		report.fail("read-only session", err)
+	} else if !readOnly {
+		report.fail("read-only session", fmt.Errorf("session is not read-only after applying %s",
+			strings.Join(adapter.ReadOnlyStatements(), "; ")))
+	} else {
+		report.ok("read-only session", "writes are refused by the database")
+	}
+
+	if !WriteProbe {
+		report.warn("write probe", "skipped (MCP_WRITE_PROBE is false)")
+	} else if refusal, err := probeWrites(checkCtx, adapter, db); err != nil {
+		report.fail("write probe", err)
+	} else {
+		report.ok("write probe", "a test write was refused: %s", refusal)
+	}
+
+	findings, err := adapter.AuditPrivileges(checkCtx, db, dsn)
+	switch {
+	case err != nil && RequireReadOnlyUser:
+		report.fail("privileges", fmt.Errorf("audit failed and MCP_REQUIRE_READONLY_USER is set: %w", err))
+	case err != nil:
+		report.warn("privileges", "could not audit: %v", err)
+	case len(findings) > 0 && RequireReadOnlyUser:
+		report.fail("privileges", fmt.Errorf("account can write and MCP_REQUIRE_READONLY_USER is set: %s",
+			strings.Join(findings, "; ")))
+	case len(findings) > 0:
+		report.warn("privileges", "account can write, use a read-only account: %s", strings.Join(findings, "; "))
+	default:
+		report.ok("privileges", "account is read-only")
+	}
+
+	if visible, hidden, err := countTables(checkCtx, adapter, db, dbName); err != nil {
+		report.fail("tables", err)
+	} else if visible == 0 {
+		report.warn("tables", "no tables visible (%d hidden by MCP_DENY_TABLES/MCP_DENY_SCHEMAS)", hidden)
+	} else {
+		report.ok("tables", "%d visible, %d hidden by MCP_DENY_TABLES/MCP_DENY_SCHEMAS", visible, hidden)
+	}
+
+	return report.finish()
+}
+
+func (r *connectionReport) finish() int {
+	if r.failed {
+		fmt.Fprintln(r.out, "Connection test FAILED")
+		return 1
+	}
+	fmt.Fprintln(r.out, "Connection test passed")
+	return 0
+}
+
+// sessionReadOnly reports whether pooled connections are in read-only mode.
+func sessionReadOnly(ctx context.Context, adapter DBAdapter, db *sql.DB) (bool, error) {
+	var value string
+	if err := db.QueryRowContext(ctx, adapter.ReadOnlyCheckQuery()).Scan(&value); err != nil {
+		return false, fmt.Errorf("failed to check session mode: %w", err)
+	}
+	switch strings.ToLower(value) {
+	case "1", "on", "true":
+		return true, nil
+	}
+	return false, nil
+}
+
+// countTables counts the tables resources/list would show and those the
+// denylists hide.
+func countTables(ctx context.Context, adapter DBAdapter, db *sql.DB, dbName string) (visible, hidden int, err error) {
+	query, args := adapter.ListTablesQuery(dbName)
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to list tables: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var tableName string
+		if err := rows.Scan(&tableName); err != nil {
+			return 0, 0, fmt.Errorf("failed to scan table name: %w", err)
+		}
+		if isTableDenied(DeniedTables, tableName) || isSchemaDenied(DeniedSchemas, dbName) {
+			hidden++
+		} else {
+			visible++
+		}
+	}
+	return visible, hidden, rows.Err()
+}
@@ -0,0 +1,68 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTestConnection_Passes(t *testing.T) {
+	var out bytes.Buffer
+	code := runTestConnection(context.Background(), &out, &SQLiteAdapter{}, newTestDB(t)+"?mode=ro")
+
+	if code != 0 {
+		t.Errorf("Expected exit code 0, got %d:\n%s", code, out.String())
+	}
+	for _, want := range []string{
+		"Driver:   sqlite",
+		"[ok]   connect",
+		"[ok]   read-only session",
+		"[ok]   write probe",
+		"[warn] privileges",
+		"[ok]   tables: 1 visible, 0 hidden",
+		"Connection test passed",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRunTestConnection_HiddenTables(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"users"}
+
+	var out bytes.Buffer
+	runTestConnection(context.Background(), &out, &SQLiteAdapter{}, newTestDB(t)+"?mode=ro")
+
+	if !strings.Contains(out.String(), "[warn] tables: no tables visible (1 hidden") {
+		t.Errorf("Expected hidden table warning, got:\n%s", out.String())
+	}
+}
+
+func TestRunTestConnection_RequireReadOnlyUserFails(t *testing.T) {
+	defer func(orig bool) { RequireReadOnlyUser = orig }(RequireReadOnlyUser)
+	RequireReadOnlyUser = true
+
+	var out bytes.Buffer
+	code := runTestConnection(context.Background(), &out, &SQLiteAdapter{}, newTestDB(t)+"?mode=ro")
+
+	if code != 1 || !strings.Contains(out.String(), "[FAIL] privileges") {
+		t.Errorf("Expected privileges failure with exit code 1, got %d:\n%s", code, out.String())
+	}
+}
+
+func TestRunTestConnection_ConnectFails(t *testing.T) {
+	var out bytes.Buffer
+	dsn := filepath.Join(t.TempDir(), "missing", "app.db") + "?mode=ro"
+	code := runTestConnection(context.Background(), &out, &SQLiteAdapter{}, dsn)
+
+	if code != 1 || !strings.Contains(out.String(), "[FAIL] connect") || !strings.Contains(out.String(), "Connection test FAILED") {
+		t.Errorf("Expected connect failure with exit code 1, got %d:\n%s", code, out.String())
+	}
+	if strings.Contains(out.String(), "read-only session") {
+		t.Errorf("Expected later checks to be skipped, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,84 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TiDBTableInfo is how TiDB stores a table: the key its rows are stored
+// under, the TiKV regions holding them and any TiFlash columnar replica.
+type TiDBTableInfo struct {
+	Name string `json:"name"`
+	// PKType is CLUSTERED when rows are stored in primary key order, or
+	// NONCLUSTERED when they are keyed by a hidden _tidb_rowid
+	PKType string `json:"pk_type,omitempty"`
+	// RowIDSharding is how row IDs are scattered across regions, e.g.
+	// SHARD_BITS=4 or PK_AUTO_RANDOM_BITS=5
+	RowIDSharding string `json:"row_id_sharding,omitempty"`
+	// Regions is the number of TiKV regions holding the table's rows
+	Regions          int     `json:"regions"`
+	TiFlashReplicas  int     `json:"tiflash_replicas"`
+	TiFlashAvailable bool    `json:"tiflash_available"`
+	TiFlashProgress  float64 `json:"tiflash_progress"`
+	// PartitionMethod and PartitionExpression are the table's partitioning,
+	// e.g. RANGE and YEAR(created_at), when it has Partitions
+	PartitionMethod     string `json:"partition_method,omitempty"`
+	PartitionExpression string `json:"partition_expression,omitempty"`
+	Partitions          int    `json:"partitions,omitempty"`
+}
+
+// describe summarizes the table's storage for its schema resource's
+// description.
+func (t *TiDBTableInfo) describe() string {
+	var parts []string
+	switch t.PKType {
+	case "CLUSTERED":
+		parts = append(parts, "clustered primary key (rows stored in primary key order)")
+	case "NONCLUSTERED":
+		parts = append(parts, "rows keyed by a hidden _tidb_rowid")
+	}
+	if t.RowIDSharding != "" && !strings.HasPrefix(t.RowIDSharding, "NOT_SHARDED") {
+		parts = append(parts, "row IDs scattered with "+t.RowIDSharding)
+	}
+	if t.Regions > 0 {
+		parts = append(parts, fmt.Sprintf("%d TiKV regions", t.Regions))
+	}
+	if t.Partitions > 0 {
+		parts = append(parts, fmt.Sprintf("partitioned by %s (%s) into %d partitions; filter on %s to prune them",
+			t.PartitionMethod, t.PartitionExpression, t.Partitions, t.PartitionExpression))
+	}
+	switch {
+	case t.TiFlashReplicas > 0 && t.TiFlashAvailable:
+		parts = append(parts, fmt.Sprintf("%d TiFlash columnar replicas serve analytical scans; "+
+			"force one with /*+ READ_FROM_STORAGE(TIFLASH[%s]) */", t.TiFlashReplicas, t.Name))
+	case t.TiFlashReplicas > 0:
+		parts = append(parts, fmt.Sprintf("TiFlash replica not yet available (%.0f%% synced)", t.TiFlashProgress*100))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return "TiDB table: " + strings.Join(parts, "; ")
+}
+
+// tidbTableNotes returns the storage descriptions of the database's tables
+// by name, or nil when the adapter does not read TiDB storage or they cannot
+// be read.
+func (s *Server) tidbTableNotes(ctx context.Context) map[string]string {
+	reader, ok := s.adapter.(TiDBTableReader)
+	if !ok {
+		return nil
+	}
+	tables, err := reader.TiDBTables(ctx, s.db, s.databaseName)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to read TiDB table storage", "error", err)
+		return nil
+	}
+	notes := map[string]string{}
+	for name, table := range tables {
+		if note := table.describe(); note != "" {
+			notes[name] = note
+		}
+	}
+	return notes
+}
@@ -0,0 +1,131 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestTiDBValidateQuery(t *testing.T) {
+	adapter := &TiDBAdapter{}
+	allowedQueries := []string{
+		"SELECT admin, flashback FROM users",
+		"SELECT * FROM users WHERE note = 'ADMIN CHECK TABLE users'",
+		"SELECT /*+ READ_FROM_STORAGE(TIFLASH[orders]) */ COUNT(*) FROM orders",
+		"SHOW TABLE users REGIONS",
+		"EXPLAIN SELECT * FROM users",
+	}
+	for _, query := range allowedQueries {
+		if err := adapter.ValidateQuery(query); err != nil {
+			t.Errorf("Expected %q to be allowed, got %v", query, err)
+		}
+	}
+
+	blockedQueries := []struct {
+		query       string
+		shouldBlock string
+	}{
+		{"ADMIN CHECK TABLE users", "ADMIN"},
+		{"SHOW TABLES; ADMIN CANCEL DDL JOBS 1", "ADMIN"},
+		{"EXPLAIN ADMIN SHOW DDL JOBS", "ADMIN"},
+		{"EXPLAIN SPLIT TABLE users BETWEEN (0) AND (1000) REGIONS 10", "SPLIT"},
+		{"EXPLAIN FLASHBACK TABLE users TO users_old", "FLASHBACK"},
+		{"SELECT SLEEP(10)", "SLEEP()"},
+		{"INSERT INTO users VALUES (1)", "INSERT"},
+	}
+	for _, tc := range blockedQueries {
+		if err := adapter.ValidateQuery(tc.query); err == nil {
+			t.Errorf("Expected %q to be blocked for %s", tc.query, tc.shouldBlock)
+		}
+	}
+}
+
+func TestParseTiDBPlan(t *testing.T) {
+	columns := []string{"id", "estRows", "estCost", "task", "access object", "operator info"}
+	rows := [][]string{
+		{"HashJoin_8", "12.49", "1520.33", "root", "", "inner join, equal:[eq(app.users.id, app.orders.user_id)]"},
+		{"├─TableReader_11(Build)", "3.00", "60.1", "root", "", "data:TableFullScan_10"},
+		{"│ └─TableFullScan_10", "3.00", "600.5", "cop[tikv]", "table:users", "keep order:false"},
+		{"└─IndexLookUp_14(Probe)", "9.99", "800.2", "root", "", ""},
+		{"  ├─IndexRangeScan_12(Build)", "9.99", "300", "cop[tikv]", "table:orders, index:idx_user(user_id)", "range:[1,5]"},
+		{"  └─TableRowIDScan_13(Probe)", "9.99", "400", "cop[tikv]", "table:orders", "keep order:false"},
+	}
+
+	nodes := parseTiDBPlan(columns, rows)
+	if len(nodes) != 1 || nodes[0].Operation != "HashJoin" || len(nodes[0].Children) != 2 {
+		t.Fatalf("Expected a hash join with two children, got %+v", nodes)
+	}
+	join := nodes[0]
+	if *join.EstimatedCost != 1520.33 || !strings.HasPrefix(join.Detail, "task: root; inner join") {
+		t.Errorf("Expected the join's cost and condition, got %+v", join)
+	}
+	users := join.Children[0].Children[0]
+	if users.Table != "users" || users.Access != PlanFullScan || users.Detail != "task: cop[tikv]; keep order:false" {
+		t.Errorf("Expected a full scan of users in TiKV, got %+v", users)
+	}
+	lookup := join.Children[1]
+	if lookup.Operation != "IndexLookUp" || !strings.HasPrefix(lookup.Detail, "probe side") || len(lookup.Children) != 2 {
+		t.Fatalf("Expected the probe side index lookup, got %+v", lookup)
+	}
+	if scan := lookup.Children[0]; scan.Table != "orders" || scan.Index != "idx_user" || scan.Access != PlanIndexLookup {
+		t.Errorf("Expected a range scan of idx_user, got %+v", scan)
+	}
+}
+
+func TestTiDBTableInfo_Describe(t *testing.T) {
+	table := &TiDBTableInfo{
+		Name: "orders", PKType: "NONCLUSTERED", RowIDSharding: "SHARD_BITS=4", Regions: 12,
+		TiFlashReplicas: 1, TiFlashAvailable: true,
+		PartitionMethod: "RANGE", PartitionExpression: "YEAR(`created_at`)", Partitions: 4,
+	}
+	note := table.describe()
+	for _, want := range []string{"hidden _tidb_rowid", "SHARD_BITS=4", "12 TiKV regions", "RANGE (YEAR(`created_at`)) into 4 partitions", "READ_FROM_STORAGE(TIFLASH[orders])"} {
+		if !strings.Contains(note, want) {
+			t.Errorf("Expected the description to mention %q, got %s", want, note)
+		}
+	}
+
+	syncing := &TiDBTableInfo{Name: "users", PKType: "CLUSTERED", RowIDSharding: "NOT_SHARDED(PK_IS_HANDLE)", TiFlashReplicas: 1, TiFlashProgress: 0.5}
+	if note := syncing.describe(); strings.Contains(note, "NOT_SHARDED") || !strings.Contains(note, "not yet available (50% synced)") {
+		t.Errorf("Expected a syncing replica without sharding, got %s", note)
+	}
+}
+
+func TestNewAdapter_TiDB(t *testing.T) {
+	adapter, err := newAdapter("TiDB")
+	if err != nil {
+		t.Fatalf("Expected tidb to be registered, got %v", err)
+	}
+	if _, ok := adapter.(*TiDBAdapter); !ok {
+		t.Fatalf("Expected a TiDBAdapter, got %T", adapter)
+	}
+	if adapter.DriverName() != "mysql" || adapter.URIScheme() != "tidb" {
+		t.Errorf("Expected the mysql driver and tidb URIs, got %s and %s", adapter.DriverName(), adapter.URIScheme())
+	}
+	if dsn, _ := adapter.FormatDSN(ConnParams{Host: "tidb", Database: "app", User: "reader", Password: "pw"}); dsn != "reader:pw@tcp(tidb:4000)/app" {
+		t.Errorf("Expected the TiDB port by default, got %s", dsn)
+	}
+}
+
+// tidbTablesAdapter reports fixed TiDB storage, as an adapter plugin for a
+// TiDB-compatible database could.
+type tidbTablesAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *tidbTablesAdapter) TiDBTables(ctx context.Context, db *sql.DB, databaseName string) (map[string]*TiDBTableInfo, error) {
+	return map[string]*TiDBTableInfo{"users": {Name: "users", PKType: "CLUSTERED", Regions: 3}}, nil
+}
+
+func TestTiDBTableNotes_AdapterCapability(t *testing.T) {
+	server := newTestServer(t)
+	if notes := server.tidbTableNotes(context.Background()); notes != nil {
+		t.Errorf("Expected no TiDB notes for SQLite, got %v", notes)
+	}
+
+	server.adapter = &tidbTablesAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	if note := server.tidbTableNotes(context.Background())["users"]; !strings.Contains(note, "3 TiKV regions") {
+		t.Errorf("Expected the adapter's table storage, got %q", note)
+	}
+}
@@ -0,0 +1,141 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// HypertableInfo is a TimescaleDB hypertable. Its rows live in chunk tables,
+// so the catalog's row count and size for the table itself are near zero.
+type HypertableInfo struct {
+	Name               string                `json:"name"`
+	Dimensions         []HypertableDimension `json:"dimensions"`
+	Chunks             int                   `json:"chunks"`
+	CompressionEnabled bool                  `json:"compression_enabled"`
+	// ApproximateRows is TimescaleDB's row estimate across the chunks
+	ApproximateRows int64 `json:"approximate_rows"`
+}
+
+// HypertableDimension is a column a hypertable is partitioned on.
+type HypertableDimension struct {
+	Column string `json:"column"`
+	// Kind is "time" for range partitioning or "space" for hash partitioning
+	Kind string `json:"kind"`
+	// ChunkInterval is the range of a time dimension's chunks, e.g. "7 days"
+	ChunkInterval string `json:"chunk_interval,omitempty"`
+	// Partitions is the number of hash partitions of a space dimension
+	Partitions int `json:"partitions,omitempty"`
+}
+
+// describe summarizes the hypertable for its schema resource's description.
+func (h *HypertableInfo) describe() string {
+	var parts []string
+	for _, dim := range h.Dimensions {
+		switch {
+		case dim.ChunkInterval != "":
+			parts = append(parts, fmt.Sprintf("%s on %s in chunks of %s", dim.Kind, dim.Column, dim.ChunkInterval))
+		case dim.Partitions > 0:
+			parts = append(parts, fmt.Sprintf("%s on %s in %d partitions", dim.Kind, dim.Column, dim.Partitions))
+		default:
+			parts = append(parts, fmt.Sprintf("%s on %s", dim.Kind, dim.Column))
+		}
+	}
+	text := fmt.Sprintf("TimescaleDB hypertable partitioned by %s; about %d rows in %d chunks",
+		strings.Join(parts, " and "), h.ApproximateRows, h.Chunks)
+	if h.CompressionEnabled {
+		text += ", compression enabled"
+	}
+	return text + ". Catalog row counts and sizes of the table itself do not include its chunks; " +
+		"bucket by time with time_bucket('15 minutes', column)"
+}
+
+// annotateColumns marks the schema resource's columns the hypertable is
+// partitioned on.
+func (h *HypertableInfo) annotateColumns(columns []map[string]any) {
+	for _, dim := range h.Dimensions {
+		for _, col := range columns {
+			if col["column_name"] != dim.Column {
+				continue
+			}
+			col["hypertable_dimension"] = dim.Kind
+			if dim.ChunkInterval != "" {
+				col["chunk_interval"] = dim.ChunkInterval
+			}
+			if dim.Partitions > 0 {
+				col["partitions"] = dim.Partitions
+			}
+		}
+	}
+}
+
+// withHypertableRows replaces the catalog's row estimates of hypertables,
+// which count only the empty parent table, with TimescaleDB's.
+func withHypertableRows(tables []TableInfo, hypertables map[string]*HypertableInfo) {
+	for i := range tables {
+		if h := hypertables[tables[i].Name]; h != nil {
+			tables[i].Rows = h.ApproximateRows
+		}
+	}
+}
+
+// timescaleVersion returns the installed TimescaleDB version, or "" when
+// the adapter cannot have it, the database has no TimescaleDB or it cannot
+// be asked.
+func (s *Server) timescaleVersion(ctx context.Context) string {
+	reader, ok := s.adapter.(TimescaleReader)
+	if !ok {
+		return ""
+	}
+	version, err := reader.TimescaleVersion(ctx, s.db)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to check for TimescaleDB", "error", err)
+		return ""
+	}
+	return version
+}
+
+// hypertables returns the database's hypertables by name, or nil when it
+// has none or they cannot be read.
+func (s *Server) hypertables(ctx context.Context) map[string]*HypertableInfo {
+	if s.timescaleVersion(ctx) == "" {
+		return nil
+	}
+	hypertables, err := s.adapter.(TimescaleReader).ListHypertables(ctx, s.db)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to read hypertables", "error", err)
+		return nil
+	}
+	return hypertables
+}
+
+// bucketWidthPattern matches an aggregate_timeseries bucket with a count,
+// e.g. "15 minutes".
+var bucketWidthPattern = regexp.MustCompile(`^(\d+)\s*([a-z]+?)s?$`)
+
+// parseBucketWidth splits a bucket such as "15 minutes" or "day" into its
+// count and unit, reporting false when it is neither.
+func parseBucketWidth(bucket string) (int, string, bool) {
+	bucket = strings.ToLower(strings.TrimSpace(bucket))
+	if isTimeBucketUnit(bucket) {
+		return 1, bucket, true
+	}
+	m := bucketWidthPattern.FindStringSubmatch(bucket)
+	if m == nil || !isTimeBucketUnit(m[2]) {
+		return 0, "", false
+	}
+	count, err := strconv.Atoi(m[1])
+	if err != nil || count < 1 {
+		return 0, "", false
+	}
+	return count, m[2], true
+}
+
+// timescaleBucket returns TimescaleDB's time_bucket over column in buckets
+// of count units. Week buckets start on Monday, as time_bucket's default
+// origin is one.
+func timescaleBucket(count int, unit, column string) string {
+	return fmt.Sprintf("time_bucket('%d %s', %s)", count, unit, column)
+}
@@ -0,0 +1,121 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseBucketWidth(t *testing.T) {
+	tests := []struct {
+		bucket string
+		count  int
+		unit   string
+		ok     bool
+	}{
+		{"day", 1, "day", true},
+		{" Week ", 1, "week", true},
+		{"15 minutes", 15, "minute", true},
+		{"1 hour", 1, "hour", true},
+		{"6hours", 6, "hour", true},
+		{"2 months", 2, "month", true},
+		{"0 days", 0, "", false},
+		{"15 fortnights", 0, "", false},
+		{"minutes", 0, "", false},
+		{"1 day'); DROP TABLE x; --", 0, "", false},
+	}
+	for _, tc := range tests {
+		count, unit, ok := parseBucketWidth(tc.bucket)
+		if count != tc.count || unit != tc.unit || ok != tc.ok {
+			t.Errorf("%q: expected %d %q %v, got %d %q %v", tc.bucket, tc.count, tc.unit, tc.ok, count, unit, ok)
+		}
+	}
+
+	if got := timescaleBucket(15, "minute", `"created_at"`); got != `time_bucket('15 minute', "created_at")` {
+		t.Errorf("Expected a time_bucket call, got %s", got)
+	}
+}
+
+func TestHypertableInfo(t *testing.T) {
+	h := &HypertableInfo{
+		Name: "metrics",
+		Dimensions: []HypertableDimension{
+			{Column: "time", Kind: "time", ChunkInterval: "7 days"},
+			{Column: "device_id", Kind: "space", Partitions: 4},
+		},
+		Chunks:             52,
+		CompressionEnabled: true,
+		ApproximateRows:    1000,
+	}
+	desc := h.describe()
+	if !strings.HasPrefix(desc, "TimescaleDB hypertable partitioned by time on time in chunks of 7 days and space on device_id in 4 partitions; about 1000 rows in 52 chunks, compression enabled.") {
+		t.Errorf("Unexpected description: %s", desc)
+	}
+
+	columns := []map[string]any{
+		{"column_name": "time", "data_type": "timestamp with time zone"},
+		{"column_name": "device_id", "data_type": "integer"},
+		{"column_name": "value", "data_type": "double precision"},
+	}
+	h.annotateColumns(columns)
+	want := []map[string]any{
+		{"column_name": "time", "data_type": "timestamp with time zone", "hypertable_dimension": "time", "chunk_interval": "7 days"},
+		{"column_name": "device_id", "data_type": "integer", "hypertable_dimension": "space", "partitions": 4},
+		{"column_name": "value", "data_type": "double precision"},
+	}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("Expected %v, got %v", want, columns)
+	}
+
+	tables := []TableInfo{{Name: "metrics", Rows: 0}, {Name: "devices", Rows: 12}}
+	withHypertableRows(tables, map[string]*HypertableInfo{"metrics": h})
+	if tables[0].Rows != 1000 || tables[1].Rows != 12 {
+		t.Errorf("Expected the hypertable's row estimate, got %+v", tables)
+	}
+}
+
+func TestAggregateTimeseries_BucketWidthNeedsTimescale(t *testing.T) {
+	server := newTimeseriesServer(t)
+	_, rpcErr := server.aggregateTimeseries(context.Background(), map[string]any{
+		"table": "events", "timestamp_column": "created_at", "bucket": "15 minutes",
+	})
+	if rpcErr == nil || rpcErr.Code != InvalidParams || !strings.Contains(rpcErr.Message, "TimescaleDB") {
+		t.Errorf("Expected bucket widths to need TimescaleDB, got %+v", rpcErr)
+	}
+
+	rows := aggregate(t, server, map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "1 month"})
+	if len(rows) != 2 {
+		t.Errorf("Expected 2 monthly buckets, got %v", rows)
+	}
+}
+
+// timescaleAdapter reports a TimescaleDB installation with one hypertable,
+// as an adapter plugin for a PostgreSQL-compatible database could.
+type timescaleAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *timescaleAdapter) TimescaleVersion(ctx context.Context, db *sql.DB) (string, error) {
+	return "2.14.2", nil
+}
+
+func (a *timescaleAdapter) ListHypertables(ctx context.Context, db *sql.DB) (map[string]*HypertableInfo, error) {
+	return map[string]*HypertableInfo{"users": {Name: "users", ApproximateRows: 1000}}, nil
+}
+
+func TestHypertables_AdapterCapability(t *testing.T) {
+	server := newTestServer(t)
+	if hypertables := server.hypertables(context.Background()); hypertables != nil {
+		t.Errorf("Expected no hypertables for SQLite, got %v", hypertables)
+	}
+
+	server.adapter = &timescaleAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+	if version := server.timescaleVersion(context.Background()); version != "2.14.2" {
+		t.Errorf("Expected the adapter's TimescaleDB version, got %q", version)
+	}
+	if h := server.hypertables(context.Background())["users"]; h == nil || h.ApproximateRows != 1000 {
+		t.Errorf("Expected the adapter's hypertables, got %+v", h)
+	}
+}
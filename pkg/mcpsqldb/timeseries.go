@@ -0,0 +1,156 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TimeBucketUnits are the bucket sizes aggregate_timeseries accepts.
+var TimeBucketUnits = []string{"minute", "hour", "day", "week", "month", "year"}
+
+// isTimeBucketUnit reports whether unit is one of TimeBucketUnits.
+func isTimeBucketUnit(unit string) bool {
+	return slices.Contains(TimeBucketUnits, unit)
+}
+
+// timeRangeLayouts are the accepted forms of the from and to arguments.
+var timeRangeLayouts = []string{time.DateOnly, time.DateTime, "2006-01-02T15:04:05", time.RFC3339}
+
+// parseTimeRangeBound parses a from or to argument into a SQL literal.
+// Midnight is written as a bare date, which also compares correctly with
+// ISO 8601 text that separates the time with a T (as SQLite may store it).
+func parseTimeRangeBound(args map[string]any, name string) (string, *Error) {
+	v, ok := args[name]
+	if !ok {
+		return "", nil
+	}
+	text, _ := v.(string)
+	for _, layout := range timeRangeLayouts {
+		t, err := time.Parse(layout, text)
+		if err != nil {
+			continue
+		}
+		if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+			return "'" + t.Format(time.DateOnly) + "'", nil
+		}
+		return "'" + t.Format(time.DateTime) + "'", nil
+	}
+	return "", &Error{
+		Code:    InvalidParams,
+		Message: fmt.Sprintf("Invalid '%s' parameter: expected a date (2006-01-02) or date and time (2006-01-02 15:04:05)", name),
+	}
+}
+
+// aggregateTimeseriesTool describes aggregate_timeseries.
+func aggregateTimeseriesTool() Tool {
+	return Tool{
+		Name: "aggregate_timeseries",
+		Description: "Aggregate a table over time buckets: the server writes the dialect's date truncation SQL, " +
+			"groups rows by bucket of the timestamp column and returns one row per bucket (bucket, value) in time order",
+		InputSchema: InputSchema{
+			Type: "object",
+			Properties: map[string]Property{
+				"table": {
+					Type:        "string",
+					Description: "The table to aggregate",
+				},
+				"timestamp_column": {
+					Type:        "string",
+					Description: "The date or timestamp column to bucket by",
+				},
+				"metric": {
+					Type:        "string",
+					Description: "Aggregate SQL expression computed per bucket, e.g. COUNT(*) or SUM(amount) (default COUNT(*))",
+				},
+				"bucket": {
+					Type: "string",
+					Description: "Bucket size: " + strings.Join(TimeBucketUnits, ", ") + " (weeks start on Monday); " +
+						"on TimescaleDB also a multiple such as '15 minutes', bucketed with time_bucket",
+				},
+				"from": {
+					Type:        "string",
+					Description: "Earliest timestamp included, e.g. 2024-01-01 or 2024-01-01 08:00:00",
+				},
+				"to": {
+					Type:        "string",
+					Description: "Timestamp the range ends before (exclusive)",
+				},
+			},
+			Required: []string{"table", "timestamp_column", "bucket"},
+		},
+	}
+}
+
+// aggregateTimeseries builds the bucketed aggregation for the adapter's
+// dialect and runs it as a query tool call, so it is validated, costed,
+// limited, masked and audited like one.
+func (s *Server) aggregateTimeseries(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	table, _ := args["table"].(string)
+	column, _ := args["timestamp_column"].(string)
+	unit, _ := args["bucket"].(string)
+	metric, _ := args["metric"].(string)
+	switch {
+	case table == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'table' parameter"}
+	case column == "":
+		return nil, &Error{Code: InvalidParams, Message: "Missing or invalid 'timestamp_column' parameter"}
+	}
+	count, unit, ok := parseBucketWidth(unit)
+	if !ok {
+		return nil, &Error{
+			Code:    InvalidParams,
+			Message: "Invalid 'bucket' parameter: expected one of " + strings.Join(TimeBucketUnits, ", "),
+		}
+	}
+	if strings.TrimSpace(metric) == "" {
+		metric = "COUNT(*)"
+	}
+	from, rpcErr := parseTimeRangeBound(args, "from")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	to, rpcErr := parseTimeRangeBound(args, "to")
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	quoted := s.quoteName(column)
+	var bucket string
+	if count > 1 {
+		checkCtx, cancel := context.WithTimeout(ctx, QueryTimeout)
+		defer cancel()
+		if err := s.workers.acquire(checkCtx); err != nil {
+			return nil, internalError(ctx, err.Error(), err)
+		}
+		version := s.timescaleVersion(checkCtx)
+		s.workers.release()
+		if version == "" {
+			return nil, &Error{
+				Code:    InvalidParams,
+				Message: "Invalid 'bucket' parameter: multiples such as '15 minutes' need TimescaleDB; expected one of " + strings.Join(TimeBucketUnits, ", "),
+			}
+		}
+		bucket = timescaleBucket(count, unit, quoted)
+	} else {
+		var err error
+		if bucket, err = s.adapter.TimeBucket(quoted, unit); err != nil {
+			return nil, &Error{Code: InvalidParams, Message: err.Error()}
+		}
+	}
+	conditions := []string{quoted + " IS NOT NULL"}
+	if from != "" {
+		conditions = append(conditions, quoted+" >= "+from)
+	}
+	if to != "" {
+		conditions = append(conditions, quoted+" < "+to)
+	}
+	// Grouping by position avoids repeating the bucket expression
+	sqlQuery := fmt.Sprintf("SELECT %s AS bucket, %s AS value FROM %s WHERE %s GROUP BY 1 ORDER BY 1",
+		bucket, metric, s.quoteName(table), strings.Join(conditions, " AND "))
+	loggerFrom(ctx).Debug("Aggregating time series", "sql", recordedSQL(s.adapter, sqlQuery))
+
+	return s.executeQuery(ctx, map[string]any{"sql": sqlQuery})
+}
@@ -0,0 +1,115 @@
+package mcpsqldb
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func newTimeseriesServer(t *testing.T) *Server {
+	t.Helper()
+	return newTestServer(t,
+		"CREATE TABLE events (id INTEGER PRIMARY KEY, created_at TEXT, amount INTEGER)",
+		`INSERT INTO events (created_at, amount) VALUES
+			('2024-01-01 09:30:00', 5), ('2024-01-01T23:59:59', 7), ('2024-01-03 12:00:00', 1),
+			('2024-01-08 00:00:00', 2), ('2024-02-10 08:00:00', 4), (NULL, 100)`)
+}
+
+func aggregate(t *testing.T, server *Server, args map[string]any) []map[string]any {
+	t.Helper()
+	result, rpcErr := server.aggregateTimeseries(context.Background(), args)
+	if rpcErr != nil {
+		t.Fatalf("Unexpected RPC error: %v", rpcErr.Message)
+	}
+	if result.IsError {
+		t.Fatalf("Unexpected tool error: %s", result.Content[0].Text)
+	}
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	return rows
+}
+
+func TestAggregateTimeseries(t *testing.T) {
+	server := newTimeseriesServer(t)
+
+	days := aggregate(t, server, map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "day",
+		"metric": "SUM(amount)", "from": "2024-01-01", "to": "2024-01-08"})
+	want := []map[string]any{
+		{"bucket": "2024-01-01", "value": float64(12)},
+		{"bucket": "2024-01-03", "value": float64(1)},
+	}
+	if !reflect.DeepEqual(days, want) {
+		t.Errorf("Expected %v, got %v", want, days)
+	}
+
+	// Weeks start on Monday (2024-01-01 and 2024-01-08 are Mondays); NULLs are skipped
+	weeks := aggregate(t, server, map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "week"})
+	var buckets []string
+	for _, row := range weeks {
+		buckets = append(buckets, row["bucket"].(string))
+	}
+	if want := []string{"2024-01-01", "2024-01-08", "2024-02-05"}; !reflect.DeepEqual(buckets, want) {
+		t.Errorf("Expected week buckets %v, got %v", want, buckets)
+	}
+	if weeks[0]["value"] != float64(3) {
+		t.Errorf("Expected COUNT(*) of 3 in the first week, got %v", weeks[0]["value"])
+	}
+}
+
+func TestAggregateTimeseries_Rejects(t *testing.T) {
+	defer func(orig []string) { DeniedTables = orig }(DeniedTables)
+	DeniedTables = []string{"payroll"}
+	server := newTimeseriesServer(t)
+
+	tests := []struct {
+		name string
+		args map[string]any
+	}{
+		{"bucket", map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "fortnight"}},
+		{"date", map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "day", "from": "yesterday"}},
+		{"quoted date", map[string]any{"table": "events", "timestamp_column": "created_at", "bucket": "day", "to": "2024-01-01' OR '1'='1"}},
+	}
+	for _, tc := range tests {
+		if _, rpcErr := server.aggregateTimeseries(context.Background(), tc.args); rpcErr == nil || rpcErr.Code != InvalidParams {
+			t.Errorf("%s: expected invalid params, got %+v", tc.name, rpcErr)
+		}
+	}
+
+	result, _ := server.aggregateTimeseries(context.Background(), map[string]any{"table": "payroll", "timestamp_column": "paid_at", "bucket": "month"})
+	if result == nil || !result.IsError || !strings.Contains(result.Content[0].Text, "restricted table") {
+		t.Errorf("Expected a denied table to be rejected, got %+v", result)
+	}
+	result, _ = server.aggregateTimeseries(context.Background(), map[string]any{"table": "events", "timestamp_column": "created_at",
+		"bucket": "day", "metric": "COUNT(*) FROM events; DELETE FROM events --"})
+	if result == nil || !result.IsError {
+		t.Errorf("Expected a metric smuggling a statement to be rejected, got %+v", result)
+	}
+}
+
+func TestTimeBucket_Dialects(t *testing.T) {
+	tests := []struct {
+		adapter DBAdapter
+		unit    string
+		want    string
+	}{
+		{&PostgresAdapter{}, "week", `date_trunc('week', "ts")`},
+		{&MySQLAdapter{}, "hour", "DATE_FORMAT(`ts`, '%Y-%m-%d %H:00:00')"},
+		{&MySQLAdapter{}, "week", "DATE_SUB(DATE(`ts`), INTERVAL WEEKDAY(`ts`) DAY)"},
+		{&SQLiteAdapter{}, "month", `strftime('%Y-%m-01', "ts")`},
+	}
+	for _, tc := range tests {
+		got, err := tc.adapter.TimeBucket(tc.adapter.QuoteIdentifier("ts"), tc.unit)
+		if err != nil || got != tc.want {
+			t.Errorf("%s %s: expected %s, got %s (%v)", tc.adapter.DriverName(), tc.unit, tc.want, got, err)
+		}
+	}
+	for _, adapter := range []DBAdapter{&PostgresAdapter{}, &MySQLAdapter{}, &SQLiteAdapter{}} {
+		if _, err := adapter.TimeBucket("ts", "decade"); err == nil {
+			t.Errorf("%s: expected an error for an unknown unit", adapter.DriverName())
+		}
+	}
+}
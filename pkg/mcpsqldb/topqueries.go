@@ -0,0 +1,104 @@
+package mcpsqldb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TopQueries offers the top_queries tool on databases with statement
+// statistics, such as PostgreSQL's pg_stat_statements (overridable via
+// MCP_TOP_QUERIES env var)
+var TopQueries = false
+
+// TopQuery is a statement statistics entry as top_queries reports it.
+type TopQuery struct {
+	// Query is the statement with constants replaced by placeholders ($1,
+	// $2, ... on PostgreSQL, _ on CockroachDB)
+	Query       string  `json:"query"`
+	Calls       int64   `json:"calls"`
+	TotalTimeMs float64 `json:"total_time_ms"`
+	MeanTimeMs  float64 `json:"mean_time_ms"`
+	Rows        int64   `json:"rows"`
+}
+
+// pgInsufficientPrivilege is the query text pg_stat_statements shows for
+// other users' statements without pg_read_all_stats.
+const pgInsufficientPrivilege = "<insufficient privilege>"
+
+// topQueriesReport is the top_queries result.
+type topQueriesReport struct {
+	OrderBy string     `json:"order_by"`
+	Queries []TopQuery `json:"queries"`
+	// Hidden counts statements left out for naming a denied table or schema
+	// or, without pg_read_all_stats, for belonging to another user
+	Hidden int    `json:"hidden,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// topQueriesTool describes top_queries, listed only when enabled for an
+// adapter that reads statement statistics.
+func topQueriesTool() Tool {
+	tool := queryInsightsTool()
+	tool.Name = "top_queries"
+	tool.Description = "List the statements this database spends the most time on, from its statement statistics " +
+		"(pg_stat_statements on PostgreSQL), with calls, total and mean execution time, and rows returned"
+	tool.InputSchema.Properties["order_by"] = Property{
+		Type: "string",
+		Description: fmt.Sprintf("%s (default, total time), %s (mean time) or %s",
+			StatementOrderTotalLatency, StatementOrderAvgLatency, StatementOrderCalls),
+	}
+	return tool
+}
+
+// topQueries reports the most expensive statements from the database's
+// statement statistics. Like query_insights, statements naming a denied
+// table or schema are counted but not shown.
+func (s *Server) topQueries(ctx context.Context, args map[string]any) (*CallToolResult, *Error) {
+	reader, ok := s.adapter.(TopQueryReader)
+	if !TopQueries || !ok {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: "top_queries requires PostgreSQL or CockroachDB and MCP_TOP_QUERIES=true"}},
+			IsError: true,
+		}, nil
+	}
+	orderBy, limit, rpcErr := statementArgs(args)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, QueryTimeout)
+	defer cancel()
+
+	if err := s.workers.acquire(ctx); err != nil {
+		return nil, internalError(ctx, err.Error(), err)
+	}
+	defer s.workers.release()
+
+	queries, allStats, err := reader.TopQueries(ctx, s.db, orderBy, limit)
+	if errors.Is(err, ErrNoPgStatStatements) {
+		return &CallToolResult{
+			Content: []Content{{Type: "text", Text: err.Error()}},
+			IsError: true,
+		}, nil
+	}
+	if err != nil {
+		return withErrorKind(&CallToolResult{
+			Content: []Content{{Type: "text", Text: fmt.Sprintf("Query error: %v", err)}},
+			IsError: true,
+		}, errorKind(ctx, err)), nil
+	}
+
+	report := topQueriesReport{OrderBy: orderBy, Queries: []TopQuery{}}
+	if !allStats {
+		report.Note = "The database account lacks pg_read_all_stats, so only its own statements are shown"
+	}
+	for _, q := range queries {
+		if q.Query == pgInsufficientPrivilege || s.statementDenied(q.Query) {
+			report.Hidden++
+			continue
+		}
+		report.Queries = append(report.Queries, q)
+	}
+	return statementReportResult(report)
+}
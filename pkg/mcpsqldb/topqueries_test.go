@@ -0,0 +1,40 @@
+package mcpsqldb
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTopQueries_Availability(t *testing.T) {
+	defer func(orig bool) { TopQueries = orig }(TopQueries)
+	TopQueries = true
+
+	listed := func(server *Server) bool {
+		tools, _ := server.handleListTools()
+		for _, tool := range tools.Tools {
+			if tool.Name == "top_queries" {
+				return true
+			}
+		}
+		return false
+	}
+	if !listed(&Server{adapter: &PostgresAdapter{}}) {
+		t.Error("Expected top_queries to be listed for PostgreSQL")
+	}
+	if !listed(&Server{adapter: &CockroachAdapter{}}) {
+		t.Error("Expected top_queries to be listed for CockroachDB")
+	}
+
+	server := newTestServer(t)
+	if listed(server) {
+		t.Error("Expected top_queries to be hidden for SQLite")
+	}
+	if result, _ := server.topQueries(context.Background(), nil); !result.IsError {
+		t.Error("Expected an error for SQLite")
+	}
+
+	TopQueries = false
+	if listed(&Server{adapter: &PostgresAdapter{}}) {
+		t.Error("Expected top_queries to be hidden unless enabled")
+	}
+}
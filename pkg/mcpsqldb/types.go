@@ -0,0 +1,258 @@
+package mcpsqldb
+
+import "encoding/json"
+
+// Protocol and server version constants
+const (
+	ProtocolVersion = "2024-11-05"
+	ServerVersion   = "1.0.0"
+)
+
+// MCP Error codes
+const (
+	ParseError     = -32700
+	InvalidRequest = -32600
+	MethodNotFound = -32601
+	InvalidParams  = -32602
+	InternalError  = -32603
+)
+
+// Application error kinds, carried in Error.Data and in a tool error's
+// _meta so clients can branch on the failure instead of its message
+const (
+	ErrorKindValidationRejected = "validation_rejected"
+	ErrorKindTimeout            = "timeout"
+	ErrorKindConnectionLost     = "connection_lost"
+	ErrorKindQuotaExceeded      = "quota_exceeded"
+	ErrorKindRateLimited        = "rate_limited"
+	ErrorKindTooLarge           = "too_large"
+	ErrorKindTooExpensive       = "too_expensive"
+)
+
+// Warning kinds, carried in a tool result's _meta for what the server did to
+// a query that succeeded
+const (
+	WarningKindTruncated         = "truncated"
+	WarningKindColumnsOmitted    = "columns_omitted"
+	WarningKindColumnsMasked     = "columns_masked"
+	WarningKindSlowQuery         = "slow_query"
+	WarningKindRowLimitRewritten = "row_limit_rewritten"
+)
+
+// JSON-RPC types
+
+type JSONRPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// JSONRPCNotification is a message that expects no response, such as a
+// resource update sent to the client.
+type JSONRPCNotification struct {
+	JSONRPC string `json:"jsonrpc"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+type JSONRPCResponse struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      any    `json:"id"`
+	Result  any    `json:"result,omitempty"`
+	Error   *Error `json:"error,omitempty"`
+}
+
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+// MCP Protocol types
+
+type InitializeParams struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ClientCapabilities `json:"capabilities"`
+	ClientInfo      ClientInfo         `json:"clientInfo"`
+}
+
+// ClientCapabilities lists the optional features a client declared in
+// initialize; a nil field means the client does not support the feature
+type ClientCapabilities struct {
+	Roots        *RootsCapability       `json:"roots,omitempty"`
+	Sampling     *SamplingCapability    `json:"sampling,omitempty"`
+	Elicitation  *ElicitationCapability `json:"elicitation,omitempty"`
+	Experimental map[string]any         `json:"experimental,omitempty"`
+}
+
+type RootsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type SamplingCapability struct{}
+
+type ElicitationCapability struct{}
+
+// ElicitRequestParams asks the client to collect input from its user
+// (elicitation/create); RequestedSchema may only use flat primitive fields
+type ElicitRequestParams struct {
+	Message         string      `json:"message"`
+	RequestedSchema InputSchema `json:"requestedSchema"`
+}
+
+// ElicitResult is the user's answer to elicitation/create. Action is
+// "accept" (with Content), "decline" or "cancel"
+type ElicitResult struct {
+	Action  string         `json:"action"`
+	Content map[string]any `json:"content,omitempty"`
+}
+
+type ClientInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type ServerInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      ServerInfo         `json:"serverInfo"`
+}
+
+type ServerCapabilities struct {
+	Tools     *ToolsCapability     `json:"tools,omitempty"`
+	Resources *ResourcesCapability `json:"resources,omitempty"`
+}
+
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe,omitempty"`
+	ListChanged bool `json:"listChanged,omitempty"`
+}
+
+// Tool types
+
+type Tool struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InputSchema InputSchema `json:"inputSchema"`
+}
+
+type InputSchema struct {
+	Type       string              `json:"type"`
+	Properties map[string]Property `json:"properties"`
+	Required   []string            `json:"required"`
+}
+
+type Property struct {
+	Type        string    `json:"type"`
+	Description string    `json:"description,omitempty"`
+	Items       *Property `json:"items,omitempty"`
+	// Properties and Required describe the fields of an object
+	Properties map[string]Property `json:"properties,omitempty"`
+	Required   []string            `json:"required,omitempty"`
+}
+
+type ListToolsResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+type CallToolParams struct {
+	Name      string         `json:"name"`
+	Arguments map[string]any `json:"arguments"`
+}
+
+type CallToolResult struct {
+	Content []Content   `json:"content"`
+	IsError bool        `json:"isError,omitempty"`
+	Meta    *ResultMeta `json:"_meta,omitempty"`
+}
+
+type ResultMeta struct {
+	Error *ErrorInfo `json:"error,omitempty"`
+	// QueueWaitMs is how long the query waited for a worker, if it waited
+	QueueWaitMs int64 `json:"queue_wait_ms,omitempty"`
+	// Truncation is set when the result was cut at its row limit
+	Truncation *TruncationInfo `json:"truncation,omitempty"`
+	// TotalCount is the query's row count without its row limit, when
+	// include_total_count was set
+	TotalCount *int64 `json:"total_count,omitempty"`
+	// Warnings lists the non-fatal issues with the result
+	Warnings []ResultWarning `json:"warnings,omitempty"`
+}
+
+// ResultWarning is a non-fatal issue of one of the WarningKind constants
+type ResultWarning struct {
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+// TruncationInfo describes a result cut at its row limit
+type TruncationInfo struct {
+	// Shown is the number of rows returned
+	Shown int `json:"shown"`
+	// Counted reports whether TotalRows was computed with a COUNT(*) of the
+	// query (see MCP_COUNT_TRUNCATED)
+	Counted   bool  `json:"counted"`
+	TotalRows int64 `json:"total_rows,omitempty"`
+}
+
+// ErrorInfo classifies a failed request by one of the ErrorKind constants
+type ErrorInfo struct {
+	Kind string `json:"kind"`
+	// Retryable reports whether the same request may succeed later
+	Retryable bool `json:"retryable"`
+	// RetryAfterSeconds is how long to wait first, when the server knows
+	RetryAfterSeconds int64 `json:"retry_after_seconds,omitempty"`
+}
+
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Resource types
+
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+type ListResourcesResult struct {
+	Resources []Resource `json:"resources"`
+}
+
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// SubscribeParams names the resource of resources/subscribe and
+// resources/unsubscribe.
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams are the params of notifications/resources/updated.
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+type ReadResourceResult struct {
+	Contents []ResourceContent `json:"contents"`
+}
+
+type ResourceContent struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text,omitempty"`
+}
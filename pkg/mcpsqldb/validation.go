@@ -1,4 +1,4 @@
-package main
+package mcpsqldb
 
 import (
 	"fmt"
@@ -9,6 +9,10 @@ import (
 // MaxResultRows is overridable via MCP_MAX_ROWS env var
 var MaxResultRows = 10000
 
+// MaxResultBytes caps the estimated in-memory size of a query result
+// (overridable via MCP_MAX_RESULT_BYTES env var)
+var MaxResultBytes = 64 << 20
+
 // commonDangerousKeywords are DML/DDL keywords blocked by all databases.
 var commonDangerousKeywords = []struct {
 	pattern string
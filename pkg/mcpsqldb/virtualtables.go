@@ -0,0 +1,57 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+)
+
+// VirtualTableInfo is a SQLite virtual table, such as an FTS5 full-text
+// index, whose rows come from a module rather than the table's own storage.
+type VirtualTableInfo struct {
+	Name string `json:"name"`
+	// Module is the module implementing the table, e.g. fts5 or rtree
+	Module string `json:"module"`
+	// ShadowTables are the real tables the module keeps the data in
+	ShadowTables []string `json:"shadow_tables,omitempty"`
+}
+
+// describe explains the virtual table for its schema resource's description.
+func (v *VirtualTableInfo) describe() string {
+	switch v.Module {
+	case "fts5":
+		return fmt.Sprintf("FTS5 full-text table: search with WHERE %s MATCH 'terms' ORDER BY rank; "+
+			"bm25(%s), highlight() and snippet() score and mark the matches", v.Name, v.Name)
+	case "fts3", "fts4":
+		return fmt.Sprintf("%s full-text table: search with WHERE %s MATCH 'terms'; "+
+			"snippet(%s) and offsets(%s) mark the matches", v.Module, v.Name, v.Name, v.Name)
+	case flatFileModuleName:
+		return "Read-only table over a CSV file"
+	case "":
+		return "Virtual table"
+	default:
+		return fmt.Sprintf("Virtual table using the %s module", v.Module)
+	}
+}
+
+// virtualTableNotes returns the descriptions of the database's virtual
+// tables and their shadow tables by table name, or nil when the adapter does
+// not list them or they cannot be read.
+func (s *Server) virtualTableNotes(ctx context.Context) map[string]string {
+	lister, ok := s.adapter.(VirtualTableLister)
+	if !ok {
+		return nil
+	}
+	tables, err := lister.VirtualTables(ctx, s.db)
+	if err != nil {
+		loggerFrom(ctx).Warn("Failed to read virtual tables", "error", err)
+		return nil
+	}
+	notes := map[string]string{}
+	for _, table := range tables {
+		notes[table.Name] = table.describe()
+		for _, shadow := range table.ShadowTables {
+			notes[shadow] = fmt.Sprintf("Shadow table holding internal data of the virtual table %s; query %s instead", table.Name, table.Name)
+		}
+	}
+	return notes
+}
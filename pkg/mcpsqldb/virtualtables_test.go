@@ -0,0 +1,111 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVirtualTables_SQLite(t *testing.T) {
+	server := newTestServer(t,
+		"CREATE VIRTUAL TABLE docs USING fts5(title, body)",
+		"CREATE VIRTUAL TABLE docs_archive USING fts5(body)",
+		"CREATE VIRTUAL TABLE boxes USING rtree(id, minx, maxx)",
+		"INSERT INTO docs VALUES ('intro', 'hello world'), ('outro', 'goodbye')",
+	)
+	ctx := context.Background()
+
+	tables, err := server.adapter.(*SQLiteAdapter).VirtualTables(ctx, server.db)
+	if err != nil {
+		t.Fatalf("Failed to read virtual tables: %v", err)
+	}
+	modules := map[string]string{}
+	for _, table := range tables {
+		modules[table.Name] = table.Module
+		if table.Name == "docs" && !reflect.DeepEqual(table.ShadowTables, []string{"docs_config", "docs_content", "docs_data", "docs_docsize", "docs_idx"}) {
+			t.Errorf("Expected the shadow tables of docs only, got %v", table.ShadowTables)
+		}
+	}
+	if want := map[string]string{"docs": "fts5", "docs_archive": "fts5", "boxes": "rtree"}; !reflect.DeepEqual(modules, want) {
+		t.Errorf("Expected %v, got %v", want, modules)
+	}
+
+	list, rpcErr := server.handleListResources(ctx)
+	if rpcErr != nil {
+		t.Fatalf("Failed to list resources: %v", rpcErr.Message)
+	}
+	descriptions := map[string]string{}
+	for _, r := range list.Resources {
+		descriptions[r.URI] = r.Description
+	}
+	if d := descriptions["sqlite://test/docs/schema"]; !strings.HasPrefix(d, "FTS5 full-text table: search with WHERE docs MATCH") {
+		t.Errorf("Expected docs to be described as an FTS5 table, got %q", d)
+	}
+	if d := descriptions["sqlite://test/docs_archive_data/schema"]; !strings.HasPrefix(d, "Shadow table holding internal data of the virtual table docs_archive;") {
+		t.Errorf("Expected docs_archive_data to be described as a shadow table, got %q", d)
+	}
+	if d := descriptions["sqlite://test/boxes/schema"]; !strings.HasPrefix(d, "Virtual table using the rtree module") {
+		t.Errorf("Expected boxes to be described as an rtree table, got %q", d)
+	}
+
+	params, _ := json.Marshal(ReadResourceParams{URI: "sqlite://test/docs/schema"})
+	read, rpcErr := server.handleReadResource(ctx, params)
+	if rpcErr != nil {
+		t.Fatalf("Failed to read schema: %v", rpcErr.Message)
+	}
+	var columns []map[string]any
+	json.Unmarshal([]byte(read.Contents[0].Text), &columns)
+	var names, hidden []string
+	for _, col := range columns {
+		names = append(names, col["column_name"].(string))
+		if col["hidden"] == true {
+			hidden = append(hidden, col["column_name"].(string))
+		}
+	}
+	if !reflect.DeepEqual(names, []string{"title", "body", "docs", "rank"}) || !reflect.DeepEqual(hidden, []string{"docs", "rank"}) {
+		t.Errorf("Expected title and body with hidden docs and rank, got %s", read.Contents[0].Text)
+	}
+
+	rows := queryRows(t, server, "SELECT title FROM docs WHERE docs MATCH 'hello' ORDER BY rank")
+	if len(rows) != 1 || rows[0]["title"] != "intro" {
+		t.Errorf("Expected the matching document, got %v", rows)
+	}
+}
+
+func TestReadSchema_SQLiteGeneratedColumns(t *testing.T) {
+	server := newTestServer(t, "CREATE TABLE totals (net INTEGER, gross INTEGER GENERATED ALWAYS AS (net * 2) STORED)")
+
+	params, _ := json.Marshal(ReadResourceParams{URI: "sqlite://test/totals/schema"})
+	read, rpcErr := server.handleReadResource(context.Background(), params)
+	if rpcErr != nil {
+		t.Fatalf("Failed to read schema: %v", rpcErr.Message)
+	}
+	var columns []map[string]any
+	json.Unmarshal([]byte(read.Contents[0].Text), &columns)
+	if len(columns) != 2 || columns[1]["extra"] != "STORED GENERATED" {
+		t.Errorf("Expected the generated column, got %s", read.Contents[0].Text)
+	}
+}
+
+// virtualTablesAdapter lists a fixed virtual table, as an adapter plugin for
+// another database with virtual tables would.
+type virtualTablesAdapter struct {
+	*SQLiteAdapter
+}
+
+func (a *virtualTablesAdapter) VirtualTables(ctx context.Context, db *sql.DB) ([]VirtualTableInfo, error) {
+	return []VirtualTableInfo{{Name: "users", Module: "fts5", ShadowTables: []string{"users_data"}}}, nil
+}
+
+func TestVirtualTableNotes_AdapterCapability(t *testing.T) {
+	server := newTestServer(t)
+	server.adapter = &virtualTablesAdapter{SQLiteAdapter: &SQLiteAdapter{}}
+
+	notes := server.virtualTableNotes(context.Background())
+	if !strings.HasPrefix(notes["users"], "FTS5 full-text table") || !strings.HasPrefix(notes["users_data"], "Shadow table") {
+		t.Errorf("Expected notes for the adapter's virtual table, got %v", notes)
+	}
+}
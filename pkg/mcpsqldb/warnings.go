@@ -0,0 +1,12 @@
+package mcpsqldb
+
+// withWarning records a warning of kind in result's metadata and adds it to
+// the content, so the model sees it as well as the client.
+func withWarning(result *CallToolResult, kind, message string) *CallToolResult {
+	if result.Meta == nil {
+		result.Meta = &ResultMeta{}
+	}
+	result.Meta.Warnings = append(result.Meta.Warnings, ResultWarning{Kind: kind, Message: message})
+	result.Content = append(result.Content, Content{Type: "text", Text: "Warning: " + message})
+	return result
+}
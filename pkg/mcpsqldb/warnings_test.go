@@ -0,0 +1,61 @@
+package mcpsqldb
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// warningKinds returns the kinds of result's warnings in order.
+func warningKinds(result *CallToolResult) []string {
+	var kinds []string
+	if result.Meta != nil {
+		for _, w := range result.Meta.Warnings {
+			kinds = append(kinds, w.Kind)
+		}
+	}
+	return kinds
+}
+
+func TestExecuteQuery_Warnings(t *testing.T) {
+	defer func(orig int) { MaxResultRows = orig }(MaxResultRows)
+	defer func(orig []maskRule) { MaskRules = orig }(MaskRules)
+	defer func(orig time.Duration) { SlowQueryThreshold = orig }(SlowQueryThreshold)
+	server := newTestServer(t)
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT id, name FROM users ORDER BY id"})
+	if rpcErr != nil || result.IsError || len(warningKinds(result)) > 0 || len(result.Content) != 1 {
+		t.Fatalf("Expected a result without warnings, got %v %+v", rpcErr, result)
+	}
+
+	MaxResultRows = 2
+	MaskRules, _ = parseMaskRules("users.name=partial")
+	SlowQueryThreshold = time.Nanosecond
+	result, _ = server.executeQuery(context.Background(), map[string]any{"sql": "SELECT TOP 3 id, name FROM users ORDER BY id"})
+	want := []string{WarningKindTruncated, WarningKindColumnsMasked, WarningKindSlowQuery, WarningKindRowLimitRewritten}
+	if got := warningKinds(result); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Expected warnings %v, got %v", want, got)
+	}
+	if msg := result.Meta.Warnings[0].Message; msg != "Result truncated at 2 rows" {
+		t.Errorf("Expected the truncation notice as the warning, got %q", msg)
+	}
+	if msg := result.Meta.Warnings[1].Message; msg != "Values of masked columns were replaced: name" {
+		t.Errorf("Expected the masked column named, got %q", msg)
+	}
+	// Warnings not already in the rows are added to the content for the model
+	if len(result.Content) != 4 || result.Content[3].Text != "Warning: "+result.Meta.Warnings[3].Message {
+		t.Errorf("Expected 3 warnings after the rows, got %+v", result.Content)
+	}
+}
+
+func TestExecuteQuery_ColumnsOmittedWarning(t *testing.T) {
+	defer func(orig int) { MaxResultColumns = orig }(MaxResultColumns)
+	MaxResultColumns = 1
+	server := newTestServer(t)
+
+	result, _ := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT * FROM users"})
+	if got := warningKinds(result); !reflect.DeepEqual(got, []string{WarningKindColumnsOmitted}) {
+		t.Errorf("Expected a columns_omitted warning, got %v", got)
+	}
+}
@@ -0,0 +1,78 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Wire dump settings (overridable via MCP_DEBUG_WIRE and MCP_DEBUG_WIRE_FILE)
+var (
+	DebugWire     = false
+	DebugWireFile = ""
+)
+
+// MaxWireDumpBytes caps how much of a single message is dumped.
+const MaxWireDumpBytes = 64 << 10
+
+// Message directions recorded in wire dumps
+const (
+	WireInbound  = "<--"
+	WireOutbound = "-->"
+)
+
+// wireDumper writes every JSON-RPC message to a debug sink, pretty-printed,
+// size-capped, and with credentials redacted.
+type wireDumper struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// newWireDumper dumps to path (appending) or to stderr when path is empty.
+func newWireDumper(path string) (*wireDumper, error) {
+	if path == "" {
+		return &wireDumper{w: os.Stderr}, nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wire dump file: %w", err)
+	}
+	return &wireDumper{w: f, closer: f}, nil
+}
+
+func (d *wireDumper) dump(direction string, data []byte) {
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, data, "", "  "); err != nil {
+		pretty.Reset()
+		pretty.Write(data)
+	}
+
+	body := pretty.String()
+	if len(body) > MaxWireDumpBytes {
+		body = fmt.Sprintf("%s\n... (truncated %d bytes)", body[:MaxWireDumpBytes], len(body)-MaxWireDumpBytes)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	fmt.Fprintf(d.w, "%s %s %s (%d bytes)\n%s\n",
+		time.Now().Format(time.RFC3339Nano), direction, wireLabel(direction), len(data), redactSecrets(body))
+}
+
+func wireLabel(direction string) string {
+	if direction == WireInbound {
+		return "inbound"
+	}
+	return "outbound"
+}
+
+func (d *wireDumper) Close() error {
+	if d.closer != nil {
+		return d.closer.Close()
+	}
+	return nil
+}
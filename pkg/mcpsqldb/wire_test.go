@@ -0,0 +1,39 @@
+package mcpsqldb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestWireDumper_PrettyPrintsAndRedacts(t *testing.T) {
+	var buf bytes.Buffer
+	d := &wireDumper{w: &buf}
+
+	d.dump(WireInbound, []byte(`{"jsonrpc":"2.0","id":1,"method":"ping","params":{"dsn":"user:s3cretpw@tcp(db:3306)/app"}}`))
+
+	out := buf.String()
+	if !strings.Contains(out, "<-- inbound") {
+		t.Errorf("Expected direction header, got %q", out)
+	}
+	if !strings.Contains(out, "\n  \"method\": \"ping\"") {
+		t.Errorf("Expected pretty-printed JSON, got %q", out)
+	}
+	if strings.Contains(out, "s3cretpw") {
+		t.Errorf("Expected credentials to be redacted, got %q", out)
+	}
+}
+
+func TestWireDumper_CapsSize(t *testing.T) {
+	var buf bytes.Buffer
+	d := &wireDumper{w: &buf}
+
+	d.dump(WireOutbound, []byte(strings.Repeat("x", MaxWireDumpBytes+100)))
+
+	if !strings.Contains(buf.String(), "truncated 100 bytes") {
+		t.Error("Expected oversized message to be truncated")
+	}
+	if buf.Len() > MaxWireDumpBytes+200 {
+		t.Errorf("Dump exceeded cap: %d bytes", buf.Len())
+	}
+}
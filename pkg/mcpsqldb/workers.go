@@ -0,0 +1,183 @@
+package mcpsqldb
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Queue policies applied when all workers are busy
+const (
+	QueuePolicyReject = "reject" // fail fast once the queue is full
+	QueuePolicyWait   = "wait"   // queue without bound until the caller's timeout
+)
+
+// Worker pool defaults (overridable via MCP_WORKERS, MCP_QUEUE_DEPTH, MCP_QUEUE_POLICY)
+var (
+	WorkerCount = MaxConnectionsOpen
+	QueueDepth  = 100
+	QueuePolicy = QueuePolicyReject
+)
+
+// workerPool bounds concurrent database operations so a burst of tool calls
+// cannot exhaust the connection pool or overload the database. When it is
+// shared by several sessions, freed workers go to the waiting sessions in
+// turn, so one session queueing many operations cannot starve the others.
+type workerPool struct {
+	mu       sync.Mutex
+	workers  int
+	maxQueue int64
+	policy   string
+
+	active   int64
+	queued   int64
+	rejected int64
+	// waiting holds each session's waiters in arrival order, and turns the
+	// sessions with waiters in the order they are served
+	waiting map[string][]*poolWaiter
+	turns   []string
+}
+
+// poolWaiter is a queued acquire; ready is closed when it is granted a
+// worker.
+type poolWaiter struct {
+	ready   chan struct{}
+	granted bool
+}
+
+func newWorkerPool(workers, queueDepth int, policy string) *workerPool {
+	return &workerPool{
+		workers:  workers,
+		maxQueue: int64(queueDepth),
+		policy:   policy,
+		waiting:  make(map[string][]*poolWaiter),
+	}
+}
+
+type sessionKey struct{}
+
+// withSession returns a context whose worker pool operations are scheduled
+// as the session's.
+func withSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionKey{}, sessionID)
+}
+
+// acquire blocks until a worker slot is free, the queue limit rejects the
+// caller, or ctx is done. Callers must call release after a nil return.
+func (p *workerPool) acquire(ctx context.Context) error {
+	p.mu.Lock()
+	if p.active < int64(p.workers) && p.queued == 0 {
+		p.active++
+		p.mu.Unlock()
+		return nil
+	}
+	if p.policy == QueuePolicyReject && p.queued >= p.maxQueue {
+		p.rejected++
+		p.mu.Unlock()
+		return fmt.Errorf("server busy: %d operations queued, try again later", p.maxQueue)
+	}
+
+	session, _ := ctx.Value(sessionKey{}).(string)
+	w := &poolWaiter{ready: make(chan struct{})}
+	if len(p.waiting[session]) == 0 {
+		p.turns = append(p.turns, session)
+	}
+	p.waiting[session] = append(p.waiting[session], w)
+	p.queued++
+	p.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if w.granted {
+		// Granted as the context ended; the caller will not release it
+		p.releaseLocked()
+	} else {
+		p.removeLocked(session, w)
+	}
+	p.rejected++
+	return fmt.Errorf("timed out waiting for a worker: %w", ctx.Err())
+}
+
+func (p *workerPool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.releaseLocked()
+}
+
+// releaseLocked hands the freed worker to the first waiter of the session
+// whose turn it is, or frees it.
+func (p *workerPool) releaseLocked() {
+	if len(p.turns) == 0 {
+		p.active--
+		return
+	}
+	session := p.turns[0]
+	p.turns = p.turns[1:]
+	w := p.waiting[session][0]
+	if rest := p.waiting[session][1:]; len(rest) > 0 {
+		p.waiting[session] = rest
+		p.turns = append(p.turns, session)
+	} else {
+		delete(p.waiting, session)
+	}
+	p.queued--
+	w.granted = true
+	close(w.ready)
+}
+
+// removeLocked drops a waiter whose context ended.
+func (p *workerPool) removeLocked(session string, w *poolWaiter) {
+	waiters := p.waiting[session]
+	for i, other := range waiters {
+		if other == w {
+			waiters = append(waiters[:i:i], waiters[i+1:]...)
+			break
+		}
+	}
+	p.queued--
+	if len(waiters) > 0 {
+		p.waiting[session] = waiters
+		return
+	}
+	delete(p.waiting, session)
+	for i, turn := range p.turns {
+		if turn == session {
+			p.turns = append(p.turns[:i:i], p.turns[i+1:]...)
+			break
+		}
+	}
+}
+
+// withQueueWait records in result's metadata how long its query waited for
+// a worker, when the wait reached a millisecond.
+func withQueueWait(result *CallToolResult, wait time.Duration) *CallToolResult {
+	if ms := wait.Milliseconds(); ms > 0 {
+		if result.Meta == nil {
+			result.Meta = &ResultMeta{}
+		}
+		result.Meta.QueueWaitMs = ms
+	}
+	return result
+}
+
+// stats returns queue-depth metrics for the status tool.
+func (p *workerPool) stats() map[string]any {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return map[string]any{
+		"workers":          p.workers,
+		"active":           p.active,
+		"queued":           p.queued,
+		"queue_depth":      p.maxQueue,
+		"policy":           p.policy,
+		"rejected":         p.rejected,
+		"sessions_waiting": len(p.turns),
+	}
+}
@@ -0,0 +1,147 @@
+package mcpsqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool_RejectPolicy(t *testing.T) {
+	pool := newWorkerPool(1, 0, QueuePolicyReject)
+	ctx := context.Background()
+
+	if err := pool.acquire(ctx); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+	if err := pool.acquire(ctx); err == nil {
+		t.Fatal("Expected acquire to be rejected when workers and queue are full")
+	}
+	if got := pool.stats()["rejected"]; got != int64(1) {
+		t.Errorf("Expected 1 rejection, got %v", got)
+	}
+
+	pool.release()
+	if err := pool.acquire(ctx); err != nil {
+		t.Fatalf("Expected acquire to succeed after release: %v", err)
+	}
+	pool.release()
+}
+
+func TestWorkerPool_WaitPolicy(t *testing.T) {
+	pool := newWorkerPool(1, 0, QueuePolicyWait)
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- pool.acquire(context.Background()) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if got := pool.stats()["queued"]; got != int64(1) {
+		t.Errorf("Expected 1 queued operation, got %v", got)
+	}
+
+	pool.release()
+	if err := <-done; err != nil {
+		t.Fatalf("Expected queued acquire to succeed: %v", err)
+	}
+	pool.release()
+}
+
+func TestWorkerPool_WaitTimesOut(t *testing.T) {
+	pool := newWorkerPool(1, 0, QueuePolicyWait)
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+	defer pool.release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.acquire(ctx); err == nil {
+		t.Fatal("Expected acquire to time out")
+	}
+}
+
+func TestWorkerPool_FairAcrossSessions(t *testing.T) {
+	pool := newWorkerPool(1, 10, QueuePolicyReject)
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+
+	served := make(chan string, 4)
+	queue := func(session string) {
+		go func() {
+			if err := pool.acquire(withSession(context.Background(), session)); err == nil {
+				served <- session
+			}
+		}()
+		time.Sleep(10 * time.Millisecond)
+	}
+	// One session queues three operations before another queues its one
+	queue("busy")
+	queue("busy")
+	queue("busy")
+	queue("other")
+	if got := pool.stats()["sessions_waiting"]; got != 2 {
+		t.Errorf("Expected 2 sessions waiting, got %v", got)
+	}
+
+	var order []string
+	for range 4 {
+		pool.release()
+		order = append(order, <-served)
+	}
+	if order[1] != "other" {
+		t.Errorf("Expected the other session to be served second, got %v", order)
+	}
+	pool.release()
+	if got := pool.stats()["active"]; got != int64(0) {
+		t.Errorf("Expected no active workers, got %v", got)
+	}
+}
+
+func TestWorkerPool_TimedOutWaiterLeavesQueue(t *testing.T) {
+	pool := newWorkerPool(1, 10, QueuePolicyReject)
+	if err := pool.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(withSession(context.Background(), "a"), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.acquire(ctx); err == nil {
+		t.Fatal("Expected acquire to time out")
+	}
+	stats := pool.stats()
+	if stats["queued"] != int64(0) || stats["sessions_waiting"] != 0 {
+		t.Errorf("Expected the timed-out waiter removed, got %v", stats)
+	}
+	pool.release()
+	if got := pool.stats()["active"]; got != int64(0) {
+		t.Errorf("Expected no active workers, got %v", got)
+	}
+}
+
+func TestQueueWaitMetadata(t *testing.T) {
+	server := newTestServer(t)
+	server.workers = newWorkerPool(1, 10, QueuePolicyReject)
+	if err := server.workers.acquire(context.Background()); err != nil {
+		t.Fatalf("Expected first acquire to succeed: %v", err)
+	}
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		server.workers.release()
+	}()
+
+	result, rpcErr := server.executeQuery(context.Background(), map[string]any{"sql": "SELECT 1"})
+	if rpcErr != nil || result.IsError {
+		t.Fatalf("Expected a result, got %v %+v", rpcErr, result)
+	}
+	if result.Meta == nil || result.Meta.QueueWaitMs < 20 {
+		t.Errorf("Expected the queue wait in the result metadata, got %+v", result.Meta)
+	}
+
+	result, _ = server.executeQuery(context.Background(), map[string]any{"sql": "SELECT 1"})
+	if result.Meta != nil {
+		t.Errorf("Expected no metadata without a wait, got %+v", result.Meta)
+	}
+}
@@ -0,0 +1,83 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// WriteProbe attempts a harmless write after connecting to confirm the
+// database itself refuses writes, catching read-only session settings that
+// silently failed to apply (overridable via MCP_WRITE_PROBE env var)
+var WriteProbe = true
+
+// errWriteAccepted reports that the database accepted the write probe.
+var errWriteAccepted = errors.New("the database accepted a test write")
+
+// probeWrites attempts the adapter's write probe on a pooled connection in a
+// transaction that is always rolled back. It returns the database's refusal,
+// or an error when the write went through or the probe could not run.
+func probeWrites(ctx context.Context, adapter DBAdapter, db *sql.DB) (string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get a connection: %w", err)
+	}
+	defer conn.Close()
+	// Discard the connection afterwards rather than pool whatever state a
+	// refused write may leave, such as an attached temp schema
+	defer conn.Raw(func(any) error { return driver.ErrBadConn })
+
+	// No ReadOnly option: the session's own settings are what is tested
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin a transaction: %w", err)
+	}
+	name := "mcp_write_probe_" + newSessionID()
+	probe, cleanup := adapter.WriteProbe(name)
+	_, execErr := tx.ExecContext(ctx, probe)
+	tx.Rollback()
+
+	switch {
+	case execErr != nil && ctx.Err() != nil:
+		return "", fmt.Errorf("write probe did not finish: %w", execErr)
+	case execErr != nil:
+		return execErr.Error(), nil
+	}
+	if cleanup != "" {
+		if _, err := conn.ExecContext(ctx, cleanup); err != nil {
+			slog.Warn("Failed to drop write probe table", "table", name, "error", err)
+		}
+	}
+	return "", fmt.Errorf("%w (%s) despite %s", errWriteAccepted, probe, strings.Join(adapter.ReadOnlyStatements(), "; "))
+}
+
+// checkWritesRefused runs the write probe, logging a prominent warning if
+// the database accepted the write, or failing when RequireReadOnlyUser is
+// set, since query validation would then be the only line of defense.
+func checkWritesRefused(ctx context.Context, adapter DBAdapter, db *sql.DB) error {
+	if !WriteProbe {
+		return nil
+	}
+	probeCtx, cancel := context.WithTimeout(ctx, ConnectionTimeout)
+	defer cancel()
+
+	refusal, err := probeWrites(probeCtx, adapter, db)
+	if err == nil {
+		slog.Info("Write probe passed: the database refused a test write", "error", refusal)
+		return nil
+	}
+	if RequireReadOnlyUser {
+		return fmt.Errorf("write probe failed and MCP_REQUIRE_READONLY_USER is set: %w", err)
+	}
+	if !errors.Is(err, errWriteAccepted) {
+		slog.Warn("Could not run write probe", "error", err)
+		return nil
+	}
+	slog.Error("SECURITY WARNING: read-only session enforcement is NOT working; "+
+		"query validation is the only remaining safeguard.", "error", err)
+	return nil
+}
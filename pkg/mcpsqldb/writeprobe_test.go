@@ -0,0 +1,41 @@
+package mcpsqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestProbeWrites_Refused(t *testing.T) {
+	server := newTestServer(t)
+
+	refusal, err := probeWrites(context.Background(), server.adapter, server.db)
+	if err != nil || refusal == "" {
+		t.Errorf("Expected the probe to be refused, got %q %v", refusal, err)
+	}
+}
+
+func TestProbeWrites_Accepted(t *testing.T) {
+	db, err := sql.Open("sqlite", newTestDB(t))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	if _, err := probeWrites(context.Background(), &SQLiteAdapter{}, db); !errors.Is(err, errWriteAccepted) {
+		t.Errorf("Expected the probe to be accepted on a writable connection, got %v", err)
+	}
+
+	defer func(orig bool) { RequireReadOnlyUser = orig }(RequireReadOnlyUser)
+	RequireReadOnlyUser = true
+	if err := checkWritesRefused(context.Background(), &SQLiteAdapter{}, db); err == nil {
+		t.Error("Expected startup to fail with MCP_REQUIRE_READONLY_USER set")
+	}
+
+	defer func(orig bool) { WriteProbe = orig }(WriteProbe)
+	WriteProbe = false
+	if err := checkWritesRefused(context.Background(), &SQLiteAdapter{}, db); err != nil {
+		t.Errorf("Expected no probe with MCP_WRITE_PROBE=false, got %v", err)
+	}
+}
@@ -0,0 +1,333 @@
+// Package mcptest provides an MCP client for tests of servers built with
+// mcpsqldb. The client talks to a Server over in-memory pipes, through the
+// same JSON-RPC transport a real client uses:
+//
+//	func TestReport(t *testing.T) {
+//		client := mcptest.Start(t, mcpsqldb.Config{Driver: "sqlite", DSN: path})
+//		rows, err := client.CallQuery(t.Context(), "SELECT id, name FROM users")
+//		...
+//	}
+//
+// The server answers one request at a time, so a Client's calls are
+// answered in order even when made from several goroutines.
+package mcptest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/shakram02/go-readonly-mcp-sql/pkg/mcpsqldb"
+)
+
+// ClientName is the client name sent in initialize.
+const ClientName = "mcptest"
+
+// Client is an initialized MCP session with a Server.
+type Client struct {
+	// Initialize is the server's answer to initialize
+	Initialize mcpsqldb.InitializeResult
+
+	in         *io.PipeWriter
+	served     chan error
+	readerDone chan struct{}
+
+	mu            sync.Mutex
+	nextID        int
+	pending       map[string]chan message
+	notifications []mcpsqldb.JSONRPCRequest
+	closed        bool
+}
+
+// message is any JSON-RPC message the server writes.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *mcpsqldb.Error `json:"error"`
+}
+
+// RPCError is a JSON-RPC error response to a request.
+type RPCError struct {
+	Method  string
+	Code    int
+	Message string
+	Data    any
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s failed with error %d: %s", e.Method, e.Code, e.Message)
+}
+
+// ToolError is a tool result reporting a failure (isError).
+type ToolError struct {
+	Result *mcpsqldb.CallToolResult
+}
+
+func (e *ToolError) Error() string {
+	if len(e.Result.Content) == 0 {
+		return "tool call failed"
+	}
+	return e.Result.Content[0].Text
+}
+
+// Start connects to the database described by cfg and returns an
+// initialized client of a new Server, closing both when t ends.
+func Start(t testing.TB, cfg mcpsqldb.Config) *Client {
+	t.Helper()
+	server, err := mcpsqldb.New(cfg)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	client, err := NewClient(context.Background(), server)
+	if err != nil {
+		server.Close()
+		t.Fatalf("Failed to initialize client: %v", err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return client
+}
+
+// NewClient starts serving server over pipes and initializes a session. The
+// session ends when ctx is cancelled or the client is closed; closing the
+// server is left to the caller.
+func NewClient(ctx context.Context, server *mcpsqldb.Server) (*Client, error) {
+	clientIn, serverOut := io.Pipe()
+	serverIn, clientOut := io.Pipe()
+
+	c := &Client{
+		in:         clientOut,
+		served:     make(chan error, 1),
+		readerDone: make(chan struct{}),
+		pending:    map[string]chan message{},
+	}
+	go func() {
+		err := server.Serve(ctx, serverIn, serverOut)
+		serverIn.Close()
+		serverOut.Close()
+		c.served <- err
+	}()
+	go c.read(clientIn)
+
+	params := mcpsqldb.InitializeParams{
+		ProtocolVersion: mcpsqldb.ProtocolVersion,
+		ClientInfo:      mcpsqldb.ClientInfo{Name: ClientName, Version: mcpsqldb.ServerVersion},
+	}
+	if err := c.Call(ctx, "initialize", params, &c.Initialize); err != nil {
+		c.Close()
+		return nil, err
+	}
+	if err := c.write(mcpsqldb.JSONRPCRequest{JSONRPC: "2.0", Method: "notifications/initialized"}); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Call sends a request and decodes its result into result, which may be nil.
+// An error response is returned as an *RPCError.
+func (c *Client) Call(ctx context.Context, method string, params, result any) error {
+	req := mcpsqldb.JSONRPCRequest{JSONRPC: "2.0", Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return errors.New("client closed")
+	}
+	c.nextID++
+	req.ID = c.nextID
+	id := fmt.Sprint(c.nextID)
+	reply := make(chan message, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(req); err != nil {
+		return err
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.readerDone:
+		return fmt.Errorf("%s: server closed the session", method)
+	case resp := <-reply:
+		if resp.Error != nil {
+			return &RPCError{Method: method, Code: resp.Error.Code, Message: resp.Error.Message, Data: resp.Error.Data}
+		}
+		if result == nil {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	}
+}
+
+// ListTools returns the server's tools.
+func (c *Client) ListTools(ctx context.Context) ([]mcpsqldb.Tool, error) {
+	var result mcpsqldb.ListToolsResult
+	if err := c.Call(ctx, "tools/list", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Tools, nil
+}
+
+// CallTool calls the named tool. A result reporting a failure is returned,
+// not turned into an error.
+func (c *Client) CallTool(ctx context.Context, name string, args map[string]any) (*mcpsqldb.CallToolResult, error) {
+	if args == nil {
+		args = map[string]any{}
+	}
+	var result mcpsqldb.CallToolResult
+	if err := c.Call(ctx, "tools/call", mcpsqldb.CallToolParams{Name: name, Arguments: args}, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CallQuery runs sql through the query tool and returns its rows, decoded as
+// encoding/json decodes into map[string]any. Notices appended to the rows (a
+// truncation warning or the omitted columns) are left out; they remain in
+// the result's metadata. A failed query is returned as a *ToolError.
+func (c *Client) CallQuery(ctx context.Context, sql string) ([]map[string]any, error) {
+	result, err := c.CallTool(ctx, "query", map[string]any{"sql": sql})
+	if err != nil {
+		return nil, err
+	}
+	if result.IsError {
+		return nil, &ToolError{Result: result}
+	}
+	if len(result.Content) == 0 {
+		return nil, errors.New("query returned no content")
+	}
+
+	var rows []map[string]any
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode rows: %w", err)
+	}
+	kept := rows[:0]
+	for _, row := range rows {
+		if !isNotice(row) {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
+// isNotice reports whether row is a notice the server appended to the rows.
+func isNotice(row map[string]any) bool {
+	if len(row) != 1 {
+		return false
+	}
+	_, warning := row["_warning"]
+	_, omitted := row["_columns_omitted"]
+	return warning || omitted
+}
+
+// ListResources returns the server's resources.
+func (c *Client) ListResources(ctx context.Context) ([]mcpsqldb.Resource, error) {
+	var result mcpsqldb.ListResourcesResult
+	if err := c.Call(ctx, "resources/list", nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Resources, nil
+}
+
+// ReadResource reads the resource at uri.
+func (c *Client) ReadResource(ctx context.Context, uri string) ([]mcpsqldb.ResourceContent, error) {
+	var result mcpsqldb.ReadResourceResult
+	if err := c.Call(ctx, "resources/read", mcpsqldb.ReadResourceParams{URI: uri}, &result); err != nil {
+		return nil, err
+	}
+	return result.Contents, nil
+}
+
+// Notifications returns the notifications the server has sent so far.
+func (c *Client) Notifications() []mcpsqldb.JSONRPCRequest {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]mcpsqldb.JSONRPCRequest(nil), c.notifications...)
+}
+
+// Close ends the session and waits for the server to stop serving it,
+// returning the error Serve returned.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	c.mu.Unlock()
+
+	c.in.Close()
+	return <-c.served
+}
+
+// write sends msg as one line of JSON.
+func (c *Client) write(msg any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.in.Write(append(data, '\n'))
+	return err
+}
+
+// read dispatches the server's messages until its output closes: responses
+// go to the waiting call, pings are answered, other requests are refused
+// and notifications are kept.
+func (c *Client) read(r io.Reader) {
+	defer close(c.readerDone)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		hasID := len(msg.ID) > 0 && string(msg.ID) != "null"
+
+		switch {
+		case msg.Method == "" && hasID:
+			c.mu.Lock()
+			reply, ok := c.pending[string(msg.ID)]
+			c.mu.Unlock()
+			if ok {
+				reply <- msg
+			}
+		case msg.Method == "ping" && hasID:
+			c.respond(msg.ID, map[string]any{}, nil)
+		case hasID:
+			c.respond(msg.ID, nil, &mcpsqldb.Error{Code: mcpsqldb.MethodNotFound, Message: "mcptest does not support " + msg.Method})
+		default:
+			c.mu.Lock()
+			c.notifications = append(c.notifications, mcpsqldb.JSONRPCRequest{JSONRPC: "2.0", Method: msg.Method, Params: msg.Params})
+			c.mu.Unlock()
+		}
+	}
+}
+
+// respond answers a request from the server. Writes run in their own
+// goroutine so the reader never waits on the server.
+func (c *Client) respond(id json.RawMessage, result any, rpcErr *mcpsqldb.Error) {
+	go c.write(mcpsqldb.JSONRPCResponse{JSONRPC: "2.0", ID: id, Result: result, Error: rpcErr})
+}
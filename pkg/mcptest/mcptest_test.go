@@ -0,0 +1,125 @@
+package mcptest
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shakram02/go-readonly-mcp-sql/pkg/mcpsqldb"
+)
+
+// newTestDB creates a SQLite database with a users table of three rows.
+func newTestDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.db")
+	setup, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatalf("Failed to open setup database: %v", err)
+	}
+	defer setup.Close()
+	for _, stmt := range []string{
+		"CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT NOT NULL)",
+		"INSERT INTO users (name) VALUES ('alice'), ('bob'), ('carol')",
+	} {
+		if _, err := setup.Exec(stmt); err != nil {
+			t.Fatalf("Failed to seed database: %v", err)
+		}
+	}
+	return path + "?mode=ro"
+}
+
+func TestClient_CallQuery(t *testing.T) {
+	client := Start(t, mcpsqldb.Config{Driver: "sqlite", DSN: newTestDB(t)})
+	ctx := context.Background()
+
+	if client.Initialize.ServerInfo.Name != "sqlite-readonly-mcp-server" {
+		t.Errorf("Expected the SQLite server to answer initialize, got %+v", client.Initialize)
+	}
+
+	rows, err := client.CallQuery(ctx, "SELECT id, name FROM users ORDER BY id")
+	if err != nil {
+		t.Fatalf("CallQuery failed: %v", err)
+	}
+	if len(rows) != 3 || rows[0]["name"] != "alice" || rows[2]["id"] != float64(3) {
+		t.Errorf("Expected alice, bob and carol, got %v", rows)
+	}
+
+	_, err = client.CallQuery(ctx, "DELETE FROM users")
+	var toolErr *ToolError
+	if !errors.As(err, &toolErr) || toolErr.Result.Meta == nil || toolErr.Result.Meta.Error.Kind != mcpsqldb.ErrorKindValidationRejected {
+		t.Errorf("Expected a rejected query as a ToolError, got %v", err)
+	}
+
+	err = client.Call(ctx, "no/such/method", nil, nil)
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != mcpsqldb.MethodNotFound {
+		t.Errorf("Expected a method not found RPCError, got %v", err)
+	}
+}
+
+func TestClient_DropsNotices(t *testing.T) {
+	defer func(orig int) { mcpsqldb.MaxResultRows = orig }(mcpsqldb.MaxResultRows)
+	mcpsqldb.MaxResultRows = 2
+	client := Start(t, mcpsqldb.Config{Driver: "sqlite", DSN: newTestDB(t)})
+
+	rows, err := client.CallQuery(context.Background(), "SELECT name FROM users ORDER BY id")
+	if err != nil || len(rows) != 2 {
+		t.Errorf("Expected 2 rows without the truncation notice, got %v %v", rows, err)
+	}
+}
+
+func TestClient_ToolsAndResources(t *testing.T) {
+	client := Start(t, mcpsqldb.Config{Driver: "sqlite", DSN: newTestDB(t)})
+	ctx := context.Background()
+
+	tools, err := client.ListTools(ctx)
+	if err != nil || len(tools) == 0 || tools[0].Name != "query" {
+		t.Errorf("Expected the query tool first, got %v %v", tools, err)
+	}
+
+	resources, err := client.ListResources(ctx)
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("Expected the users table resource, got %v %v", resources, err)
+	}
+	contents, err := client.ReadResource(ctx, resources[0].URI)
+	if err != nil || len(contents) != 1 || contents[0].Text == "" {
+		t.Errorf("Expected the users schema, got %v %v", contents, err)
+	}
+}
+
+func TestClient_AnswersKeepalivePings(t *testing.T) {
+	// Restored after the session ends, since the server reads them meanwhile
+	keepalive, idle := mcpsqldb.KeepaliveInterval, mcpsqldb.IdleTimeout
+	t.Cleanup(func() { mcpsqldb.KeepaliveInterval, mcpsqldb.IdleTimeout = keepalive, idle })
+	mcpsqldb.KeepaliveInterval = 5 * time.Millisecond
+	mcpsqldb.IdleTimeout = 50 * time.Millisecond
+	client := Start(t, mcpsqldb.Config{Driver: "sqlite", DSN: newTestDB(t)})
+
+	// Answers to the pings keep the session from idling out
+	time.Sleep(150 * time.Millisecond)
+	if _, err := client.CallQuery(context.Background(), "SELECT 1 AS one"); err != nil {
+		t.Errorf("Expected the session to outlive keepalive pings, got %v", err)
+	}
+}
+
+func TestClient_Close(t *testing.T) {
+	server, err := mcpsqldb.New(mcpsqldb.Config{Driver: "sqlite", DSN: newTestDB(t)})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer server.Close()
+	client, err := NewClient(context.Background(), server)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Expected the session to end cleanly, got %v", err)
+	}
+	if _, err := client.CallQuery(context.Background(), "SELECT 1"); err == nil {
+		t.Errorf("Expected calls on a closed client to fail")
+	}
+}